@@ -261,6 +261,10 @@ func ProcessMessage(ctx context.Context, logger *logrus.Logger, m config.PubSubM
 			if err := workflow.MarkIdempotencySucceeded(tx.WithContext(ctx), m.BusinessId, handlerName, messageId); err != nil {
 				return err
 			}
+			// Queued in the posting transaction, so subscribers only hear about committed journals.
+			if err := models.QueueJournalPostedWebhook(ctx, tx, m); err != nil {
+				return err
+			}
 			return nil
 		}
 	})
@@ -283,6 +287,12 @@ func ProcessWorkflow(tx *gorm.DB, logger *logrus.Logger, msg config.PubSubMessag
 		return workflow.ProcessInventoryAdjustmentValueWorkflow(tx, logger, msg)
 	case string(models.AccountReferenceTypeTransferOrder):
 		return workflow.ProcessTransferOrderWorkflow(tx, logger, msg)
+	case string(models.AccountReferenceTypeAssemblyOrder):
+		return workflow.ProcessAssemblyOrderWorkflow(tx, logger, msg)
+	case string(models.AccountReferenceTypeTransferOrderReceipt):
+		return workflow.ProcessTransferOrderReceiptWorkflow(tx, logger, msg)
+	case string(models.AccountReferenceTypeLandedCost):
+		return workflow.ProcessLandedCostWorkflow(tx, logger, msg)
 	case string(models.AccountReferenceTypeAccountTransfer),
 		string(models.AccountReferenceTypeAccountDeposit),
 		string(models.AccountReferenceTypeOwnerContribution),
@@ -328,6 +338,14 @@ func ProcessWorkflow(tx *gorm.DB, logger *logrus.Logger, msg config.PubSubMessag
 		return workflow.ProcessCustomerAdvanceAppliedWorkflow(tx, logger, msg)
 	case string(models.AccountReferenceTypePosInvoicePayment):
 		return workflow.ProcessPosInvoicePaymentWorkflow(tx, logger, msg)
+	case string(models.AccountReferenceTypeFixedAssetDepreciation):
+		return workflow.ProcessFixedAssetDepreciationWorkflow(tx, logger, msg)
+	case string(models.AccountReferenceTypeFixedAssetDisposal):
+		return workflow.ProcessFixedAssetDisposalWorkflow(tx, logger, msg)
+	case string(models.AccountReferenceTypeTaxReturn):
+		return workflow.ProcessTaxReturnWorkflow(tx, logger, msg)
+	case string(models.AccountReferenceTypeFxRevaluation):
+		return workflow.ProcessFxRevaluationWorkflow(tx, logger, msg)
 	}
 	return nil
 }
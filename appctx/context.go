@@ -23,6 +23,14 @@ var (
 	// ContextKeySkipTenantScope forces tenant scoping to be disabled for the request.
 	// Use sparingly (internal ops only).
 	ContextKeySkipTenantScope = ContextKey("SkipTenantScope")
+
+	// ContextKeyBranchIds holds the branches a restricted user may access ([]int).
+	// It is absent for users who may access every branch.
+	ContextKeyBranchIds = ContextKey("BranchIds")
+
+	// ContextKeySkipBranchScope disables branch scoping for business-wide lookups
+	// made on behalf of a restricted user (sequence numbers, credit exposure).
+	ContextKeySkipBranchScope = ContextKey("SkipBranchScope")
 )
 
 func GetString(ctx context.Context, key ContextKey) (string, bool) {
@@ -40,6 +48,11 @@ func GetInt(ctx context.Context, key ContextKey) (int, bool) {
 	return v, ok
 }
 
+func GetInts(ctx context.Context, key ContextKey) ([]int, bool) {
+	v, ok := ctx.Value(key).([]int)
+	return v, ok
+}
+
 func Set(ctx context.Context, key ContextKey, value any) context.Context {
 	return context.WithValue(ctx, key, value)
 }
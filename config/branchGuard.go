@@ -0,0 +1,193 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/mmdatafocus/books_backend/appctx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var ErrBranchNotPermitted = errors.New("branch is not permitted")
+
+// BranchGuardPlugin narrows queries/updates/deletes to the branches a restricted user may access
+// (appctx.ContextKeyBranchIds), and rejects creating or moving rows into other branches.
+//   - models with a branch_id column are limited to the permitted branches
+//   - branches itself is limited by id
+//   - models with a branches list (accounts, money accounts) are limited to rows open to
+//     every branch or to one of the permitted branches
+//
+// NOTE:
+//   - Like TenantGuardPlugin, this does NOT apply to Raw SQL queries; reports narrow their
+//     branch filter explicitly.
+//   - Restricted users cannot create rows with branch_id 0 (not tied to a branch), as
+//     those would fall outside their own scope.
+//   - Business-wide lookups opt out via appctx.ContextKeySkipBranchScope.
+type BranchGuardPlugin struct{}
+
+func NewBranchGuardPlugin() *BranchGuardPlugin { return &BranchGuardPlugin{} }
+
+func (p *BranchGuardPlugin) Name() string { return "branch_guard" }
+
+func (p *BranchGuardPlugin) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Query().Before("gorm:query").Register("branch_guard:query", branchGuardScopeCallback); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().Before("gorm:row").Register("branch_guard:row", branchGuardScopeCallback); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("branch_guard:update", branchGuardUpdateCallback); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("branch_guard:delete", branchGuardScopeCallback); err != nil {
+		return err
+	}
+	if err := db.Callback().Create().Before("gorm:create").Register("branch_guard:create", branchGuardCreateCallback); err != nil {
+		return err
+	}
+	return nil
+}
+
+// restrictedBranchIds returns the branches the statement is limited to, or false when
+// it is not limited.
+func restrictedBranchIds(db *gorm.DB) ([]int, bool) {
+	if db == nil || db.Statement == nil || db.Statement.Schema == nil {
+		return nil, false
+	}
+	ctx := db.Statement.Context
+	if ctx == nil || shouldBypassTenantScope(ctx) || shouldBypassBranchScope(ctx) {
+		return nil, false
+	}
+	ids, ok := ctx.Value(appctx.ContextKeyBranchIds).([]int)
+	if !ok || len(ids) == 0 {
+		return nil, false
+	}
+	return ids, true
+}
+
+func branchGuardScopeCallback(db *gorm.DB) {
+	ids, ok := restrictedBranchIds(db)
+	if !ok {
+		return
+	}
+	stmt := db.Statement
+	table := stmt.Table
+
+	var expr clause.Expression
+	switch {
+	case table == "branches":
+		expr = clause.IN{Column: clause.Column{Table: table, Name: "id"}, Values: branchValues(ids)}
+	case hasColumn(stmt.Schema, "branch_id"):
+		expr = clause.IN{Column: clause.Column{Table: table, Name: "branch_id"}, Values: branchValues(ids)}
+	case hasColumn(stmt.Schema, "branches") && table != "users":
+		column := stmt.Quote(clause.Column{Table: table, Name: "branches"})
+		conds := []string{column + " IS NULL", column + " = ''"}
+		vars := make([]interface{}, 0, len(ids))
+		for _, id := range ids {
+			conds = append(conds, "FIND_IN_SET(?, "+column+") > 0")
+			vars = append(vars, fmt.Sprint(id))
+		}
+		expr = clause.Expr{SQL: "(" + strings.Join(conds, " OR ") + ")", Vars: vars}
+	default:
+		return
+	}
+	stmt.AddClause(clause.Where{Exprs: []clause.Expression{expr}})
+}
+
+func branchGuardUpdateCallback(db *gorm.DB) {
+	branchGuardScopeCallback(db)
+	ids, ok := restrictedBranchIds(db)
+	if !ok {
+		return
+	}
+	field := db.Statement.Schema.LookUpField("branch_id")
+	if field == nil {
+		return
+	}
+	// the row being updated is already in scope; check where it is being moved to
+	switch dest := db.Statement.Dest.(type) {
+	case map[string]interface{}:
+		for _, key := range []string{field.Name, field.DBName} {
+			if v, found := dest[key]; found {
+				checkBranchValue(db, ids, v)
+			}
+		}
+	default:
+		checkBranchField(db, ids, field, db.Statement.ReflectValue, true)
+	}
+}
+
+func branchGuardCreateCallback(db *gorm.DB) {
+	ids, ok := restrictedBranchIds(db)
+	if !ok {
+		return
+	}
+	field := db.Statement.Schema.LookUpField("branch_id")
+	if field == nil {
+		return
+	}
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			checkBranchField(db, ids, field, rv.Index(i), false)
+		}
+	default:
+		checkBranchField(db, ids, field, rv, false)
+	}
+}
+
+// checkBranchField checks the branch_id of a struct; skipZero leaves an unset branch_id
+// alone, as updates do not write it.
+func checkBranchField(db *gorm.DB, ids []int, field *schema.Field, rv reflect.Value, skipZero bool) {
+	rv = reflect.Indirect(rv)
+	if !rv.IsValid() || rv.Kind() != reflect.Struct {
+		return
+	}
+	v, zero := field.ValueOf(db.Statement.Context, rv)
+	if zero && skipZero {
+		return
+	}
+	checkBranchValue(db, ids, v)
+}
+
+func checkBranchValue(db *gorm.DB, ids []int, v interface{}) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if !rv.IsValid() || !rv.CanInt() {
+		return
+	}
+	branchId := int(rv.Int())
+	for _, id := range ids {
+		if id == branchId {
+			return
+		}
+	}
+	db.AddError(ErrBranchNotPermitted)
+}
+
+func branchValues(ids []int) []interface{} {
+	values := make([]interface{}, len(ids))
+	for i, id := range ids {
+		values[i] = id
+	}
+	return values
+}
+
+func hasColumn(s *schema.Schema, name string) bool {
+	for _, f := range s.Fields {
+		if strings.EqualFold(f.DBName, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func shouldBypassBranchScope(ctx context.Context) bool {
+	v, ok := ctx.Value(appctx.ContextKeySkipBranchScope).(bool)
+	return ok && v
+}
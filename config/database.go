@@ -27,6 +27,11 @@ func GetDB() *gorm.DB {
 	return db
 }
 
+// SetDB replaces the global DB. Tests use it to run handlers against a stubbed connection.
+func SetDB(d *gorm.DB) {
+	db = d
+}
+
 func init() {
 	// Load env from .env
 	godotenv.Load()
@@ -103,6 +108,9 @@ func ConnectDatabaseWithRetry() {
 			if pluginErr := db.Use(NewTenantGuardPlugin()); pluginErr != nil {
 				log.Printf("db connected but failed to install tenant guard plugin: %v", pluginErr)
 			}
+			if pluginErr := db.Use(NewBranchGuardPlugin()); pluginErr != nil {
+				log.Printf("db connected but failed to install branch guard plugin: %v", pluginErr)
+			}
 			log.Printf("connected to database (attempt=%d)", attempt)
 			return
 		}
@@ -124,5 +124,13 @@ func Auth(ctx context.Context, obj interface{}, next graphql.Resolver) (interfac
 	ctx = context.WithValue(ctx, utils.ContextKeyUserName, user.Name)
 	ctx = utils.SetIsAdminInContext(ctx, user.Role == models.UserRoleAdmin)
 
+	// users limited to some branches only see and write rows of those branches
+	ctx, err = models.ScopeContextToUserBranches(ctx, user)
+	if err != nil {
+		return nil, &gqlerror.Error{
+			Message: err.Error(),
+		}
+	}
+
 	return next(ctx)
 }
@@ -10,6 +10,7 @@ require (
 	github.com/bsm/redislock v0.9.4
 	github.com/disintegration/imaging v1.6.2
 	github.com/gin-contrib/cors v1.5.0
+	github.com/go-pdf/fpdf v0.9.0
 	github.com/go-playground/validator/v10 v10.16.0
 	github.com/go-sql-driver/mysql v1.7.1
 	github.com/graph-gophers/dataloader/v7 v7.1.0
@@ -15,6 +15,7 @@ import (
 	"github.com/mmdatafocus/books_backend/middlewares"
 	"github.com/mmdatafocus/books_backend/models"
 	"github.com/mmdatafocus/books_backend/models/reports"
+	"github.com/mmdatafocus/books_backend/pdfrender"
 	"github.com/mmdatafocus/books_backend/utils"
 	"github.com/mmdatafocus/books_backend/workflow"
 	"github.com/shopspring/decimal"
@@ -188,6 +189,46 @@ func (r *allProductVariantResolver) Stocks(ctx context.Context, obj *models.AllP
 	panic(fmt.Errorf("not implemented: Stocks - stocks"))
 }
 
+// Warehouse is the resolver for the warehouse field.
+func (r *assemblyOrderResolver) Warehouse(ctx context.Context, obj *models.AssemblyOrder) (*models.AllWarehouse, error) {
+	return middlewares.GetAllWarehouse(ctx, obj.WarehouseId)
+}
+
+// Product is the resolver for the product field.
+func (r *assemblyOrderResolver) Product(ctx context.Context, obj *models.AssemblyOrder) (*models.AllProduct, error) {
+	return GetAllProduct(ctx, obj.ProductId, obj.ProductType)
+}
+
+// Details is the resolver for the details field.
+func (r *assemblyOrderResolver) Details(ctx context.Context, obj *models.AssemblyOrder) ([]*models.AssemblyOrderDetail, error) {
+	return models.GetAssemblyOrderDetails(ctx, obj.ID)
+}
+
+// Product is the resolver for the product field.
+func (r *assemblyOrderDetailResolver) Product(ctx context.Context, obj *models.AssemblyOrderDetail) (*models.AllProduct, error) {
+	return GetAllProduct(ctx, obj.ProductId, obj.ProductType)
+}
+
+// Account is the resolver for the account field.
+func (r *bankStatementResolver) Account(ctx context.Context, obj *models.BankStatement) (*models.AllAccount, error) {
+	return middlewares.GetAllAccount(ctx, obj.AccountId)
+}
+
+// Currency is the resolver for the currency field.
+func (r *bankStatementResolver) Currency(ctx context.Context, obj *models.BankStatement) (*models.AllCurrency, error) {
+	return middlewares.GetAllCurrency(ctx, obj.CurrencyId)
+}
+
+// Lines is the resolver for the lines field.
+func (r *bankStatementResolver) Lines(ctx context.Context, obj *models.BankStatement, status *models.BankStatementLineStatus) ([]*models.BankStatementLine, error) {
+	return models.GetBankStatementLines(ctx, obj.ID, status)
+}
+
+// Matches is the resolver for the matches field.
+func (r *bankStatementLineResolver) Matches(ctx context.Context, obj *models.BankStatementLine) ([]*models.BankStatementMatch, error) {
+	return middlewares.GetBankStatementMatches(ctx, obj.ID)
+}
+
 // Currency is the resolver for the currency field.
 func (r *bankingAccountResolver) Currency(ctx context.Context, obj *models.BankingAccount) (*models.AllCurrency, error) {
 	return middlewares.GetAllCurrency(ctx, obj.CurrencyId)
@@ -308,11 +349,29 @@ func (r *billDetailResolver) DetailAccount(ctx context.Context, obj *models.Bill
 	return middlewares.GetAllAccount(ctx, obj.DetailAccountId)
 }
 
+// Unit is the resolver for the unit field.
+func (r *billDetailResolver) Unit(ctx context.Context, obj *models.BillDetail) (*models.AllProductUnit, error) {
+	if obj.UnitId == 0 {
+		return nil, nil
+	}
+	return middlewares.GetAllProductUnit(ctx, obj.UnitId)
+}
+
 // DetailTax is the resolver for the detailTax field.
 func (r *billDetailResolver) DetailTax(ctx context.Context, obj *models.BillDetail) (*models.TaxInfo, error) {
 	return middlewares.ResolveTaxInfo(ctx, obj.DetailTaxId, obj.DetailTaxType)
 }
 
+// Product is the resolver for the product field.
+func (r *billOfMaterialsResolver) Product(ctx context.Context, obj *models.BillOfMaterials) (*models.AllProduct, error) {
+	return GetAllProduct(ctx, obj.ProductId, obj.ProductType)
+}
+
+// Product is the resolver for the product field.
+func (r *billOfMaterialsComponentResolver) Product(ctx context.Context, obj *models.BillOfMaterialsComponent) (*models.AllProduct, error) {
+	return GetAllProduct(ctx, obj.ProductId, obj.ProductType)
+}
+
 // PaymentMode is the resolver for the paymentMode field.
 func (r *billPaymentResolver) PaymentMode(ctx context.Context, obj *models.BillPayment) (string, error) {
 	paymentMode, err := middlewares.GetPaymentMode(ctx, obj.PaymentModeId)
@@ -352,6 +411,21 @@ func (r *branchResolver) Township(ctx context.Context, obj *models.Branch) (*mod
 	return middlewares.GetAllTownship(ctx, obj.TownshipId)
 }
 
+// Branch is the resolver for the branch field.
+func (r *budgetResolver) Branch(ctx context.Context, obj *models.Budget) (*models.AllBranch, error) {
+	return middlewares.GetAllBranch(ctx, obj.BranchId)
+}
+
+// Accounts is the resolver for the accounts field.
+func (r *budgetResolver) Accounts(ctx context.Context, obj *models.Budget) ([]*models.BudgetAccount, error) {
+	return models.GetBudgetAccounts(ctx, obj.ID)
+}
+
+// Account is the resolver for the account field.
+func (r *budgetAccountResolver) Account(ctx context.Context, obj *models.BudgetAccount) (*models.AllAccount, error) {
+	return middlewares.GetAllAccount(ctx, obj.AccountId)
+}
+
 // State is the resolver for the state field.
 func (r *businessResolver) State(ctx context.Context, obj *models.Business) (*models.AllState, error) {
 	return middlewares.GetAllState(ctx, obj.StateId)
@@ -432,6 +506,14 @@ func (r *creditNoteDetailResolver) DetailAccount(ctx context.Context, obj *model
 	return middlewares.GetAllAccount(ctx, obj.DetailAccountId)
 }
 
+// Unit is the resolver for the unit field.
+func (r *creditNoteDetailResolver) Unit(ctx context.Context, obj *models.CreditNoteDetail) (*models.AllProductUnit, error) {
+	if obj.UnitId == 0 {
+		return nil, nil
+	}
+	return middlewares.GetAllProductUnit(ctx, obj.UnitId)
+}
+
 // DetailTax is the resolver for the detailTax field.
 func (r *creditNoteDetailResolver) DetailTax(ctx context.Context, obj *models.CreditNoteDetail) (*models.TaxInfo, error) {
 	return middlewares.ResolveTaxInfo(ctx, obj.DetailTaxId, obj.DetailTaxType)
@@ -572,6 +654,14 @@ func (r *customerPaymentResolver) DepositAccount(ctx context.Context, obj *model
 	return middlewares.GetAllAccount(ctx, obj.DepositAccountId)
 }
 
+// WithholdingTax is the resolver for the withholdingTax field.
+func (r *customerPaymentResolver) WithholdingTax(ctx context.Context, obj *models.CustomerPayment) (*models.WithholdingTax, error) {
+	if obj.WithholdingTaxId == 0 {
+		return nil, nil
+	}
+	return models.GetWithholdingTax(ctx, obj.WithholdingTaxId)
+}
+
 // Documents is the resolver for the documents field.
 func (r *customerPaymentResolver) Documents(ctx context.Context, obj *models.CustomerPayment) ([]*models.Document, error) {
 	return middlewares.GetCustomerPaymentDocuments(ctx, obj.ID)
@@ -587,6 +677,61 @@ func (r *customerRefundHistoryResolver) Currency(ctx context.Context, obj *repor
 	return middlewares.GetAllCurrency(ctx, obj.CurrencyId)
 }
 
+// Customer is the resolver for the customer field.
+func (r *estimateResolver) Customer(ctx context.Context, obj *models.Estimate) (*models.Customer, error) {
+	return middlewares.GetCustomer(ctx, obj.CustomerId)
+}
+
+// Branch is the resolver for the branch field.
+func (r *estimateResolver) Branch(ctx context.Context, obj *models.Estimate) (*models.AllBranch, error) {
+	return middlewares.GetAllBranch(ctx, obj.BranchId)
+}
+
+// SalesPerson is the resolver for the salesPerson field.
+func (r *estimateResolver) SalesPerson(ctx context.Context, obj *models.Estimate) (*models.AllSalesPerson, error) {
+	return middlewares.GetAllSalesPerson(ctx, obj.SalesPersonId)
+}
+
+// Currency is the resolver for the currency field.
+func (r *estimateResolver) Currency(ctx context.Context, obj *models.Estimate) (*models.AllCurrency, error) {
+	return middlewares.GetAllCurrency(ctx, obj.CurrencyId)
+}
+
+// Warehouse is the resolver for the warehouse field.
+func (r *estimateResolver) Warehouse(ctx context.Context, obj *models.Estimate) (*models.AllWarehouse, error) {
+	return middlewares.GetAllWarehouse(ctx, obj.WarehouseId)
+}
+
+// EstimateTax is the resolver for the estimateTax field.
+func (r *estimateResolver) EstimateTax(ctx context.Context, obj *models.Estimate) (*models.TaxInfo, error) {
+	return middlewares.ResolveTaxInfo(ctx, obj.EstimateTaxId, obj.EstimateTaxType)
+}
+
+// Documents is the resolver for the documents field.
+func (r *estimateResolver) Documents(ctx context.Context, obj *models.Estimate) ([]*models.Document, error) {
+	return middlewares.GetEstimateDocuments(ctx, obj.ID)
+}
+
+// Details is the resolver for the details field.
+func (r *estimateResolver) Details(ctx context.Context, obj *models.Estimate) ([]*models.EstimateDetail, error) {
+	return middlewares.GetEstimateDetails(ctx, obj.ID)
+}
+
+// Product is the resolver for the product field.
+func (r *estimateDetailResolver) Product(ctx context.Context, obj *models.EstimateDetail) (*models.AllProduct, error) {
+	return GetAllProduct(ctx, obj.ProductId, obj.ProductType)
+}
+
+// DetailAccount is the resolver for the detailAccount field.
+func (r *estimateDetailResolver) DetailAccount(ctx context.Context, obj *models.EstimateDetail) (*models.AllAccount, error) {
+	return middlewares.GetAllAccount(ctx, obj.DetailAccountId)
+}
+
+// DetailTax is the resolver for the detailTax field.
+func (r *estimateDetailResolver) DetailTax(ctx context.Context, obj *models.EstimateDetail) (*models.TaxInfo, error) {
+	return middlewares.ResolveTaxInfo(ctx, obj.DetailTaxId, obj.DetailTaxType)
+}
+
 // ExpenseAccount is the resolver for the expenseAccount field.
 func (r *expenseResolver) ExpenseAccount(ctx context.Context, obj *models.Expense) (*models.AllAccount, error) {
 	return middlewares.GetAllAccount(ctx, obj.ExpenseAccountId)
@@ -657,6 +802,69 @@ func (r *expenseDetailResolver) Customer(ctx context.Context, obj *reports.Expen
 	return middlewares.GetCustomer(ctx, obj.CustomerId)
 }
 
+// Branch is the resolver for the branch field.
+func (r *fixedAssetResolver) Branch(ctx context.Context, obj *models.FixedAsset) (*models.AllBranch, error) {
+	return middlewares.GetAllBranch(ctx, obj.BranchId)
+}
+
+// Category is the resolver for the category field.
+func (r *fixedAssetResolver) Category(ctx context.Context, obj *models.FixedAsset) (*models.FixedAssetCategory, error) {
+	return middlewares.GetFixedAssetCategory(ctx, obj.CategoryId)
+}
+
+// AssetAccount is the resolver for the assetAccount field.
+func (r *fixedAssetResolver) AssetAccount(ctx context.Context, obj *models.FixedAsset) (*models.AllAccount, error) {
+	return middlewares.GetAllAccount(ctx, obj.AssetAccountId)
+}
+
+// AccumulatedDepreciationAccount is the resolver for the accumulatedDepreciationAccount field.
+func (r *fixedAssetResolver) AccumulatedDepreciationAccount(ctx context.Context, obj *models.FixedAsset) (*models.AllAccount, error) {
+	return middlewares.GetAllAccount(ctx, obj.AccumulatedDepreciationAccountId)
+}
+
+// DepreciationExpenseAccount is the resolver for the depreciationExpenseAccount field.
+func (r *fixedAssetResolver) DepreciationExpenseAccount(ctx context.Context, obj *models.FixedAsset) (*models.AllAccount, error) {
+	return middlewares.GetAllAccount(ctx, obj.DepreciationExpenseAccountId)
+}
+
+// DisposalGainLossAccount is the resolver for the disposalGainLossAccount field.
+func (r *fixedAssetResolver) DisposalGainLossAccount(ctx context.Context, obj *models.FixedAsset) (*models.AllAccount, error) {
+	return middlewares.GetAllAccount(ctx, obj.DisposalGainLossAccountId)
+}
+
+// ProceedsAccount is the resolver for the proceedsAccount field.
+func (r *fixedAssetResolver) ProceedsAccount(ctx context.Context, obj *models.FixedAsset) (*models.AllAccount, error) {
+	if obj.ProceedsAccountId == 0 {
+		return nil, nil
+	}
+	return middlewares.GetAllAccount(ctx, obj.ProceedsAccountId)
+}
+
+// Depreciations is the resolver for the depreciations field.
+func (r *fixedAssetResolver) Depreciations(ctx context.Context, obj *models.FixedAsset) ([]*models.FixedAssetDepreciation, error) {
+	return models.GetFixedAssetDepreciations(ctx, obj.ID)
+}
+
+// AssetAccount is the resolver for the assetAccount field.
+func (r *fixedAssetCategoryResolver) AssetAccount(ctx context.Context, obj *models.FixedAssetCategory) (*models.AllAccount, error) {
+	return middlewares.GetAllAccount(ctx, obj.AssetAccountId)
+}
+
+// AccumulatedDepreciationAccount is the resolver for the accumulatedDepreciationAccount field.
+func (r *fixedAssetCategoryResolver) AccumulatedDepreciationAccount(ctx context.Context, obj *models.FixedAssetCategory) (*models.AllAccount, error) {
+	return middlewares.GetAllAccount(ctx, obj.AccumulatedDepreciationAccountId)
+}
+
+// DepreciationExpenseAccount is the resolver for the depreciationExpenseAccount field.
+func (r *fixedAssetCategoryResolver) DepreciationExpenseAccount(ctx context.Context, obj *models.FixedAssetCategory) (*models.AllAccount, error) {
+	return middlewares.GetAllAccount(ctx, obj.DepreciationExpenseAccountId)
+}
+
+// DisposalGainLossAccount is the resolver for the disposalGainLossAccount field.
+func (r *fixedAssetCategoryResolver) DisposalGainLossAccount(ctx context.Context, obj *models.FixedAssetCategory) (*models.AllAccount, error) {
+	return middlewares.GetAllAccount(ctx, obj.DisposalGainLossAccountId)
+}
+
 // Account is the resolver for the account field.
 func (r *inventoryAdjustmentResolver) Account(ctx context.Context, obj *models.InventoryAdjustment) (*models.AllAccount, error) {
 	return middlewares.GetAllAccount(ctx, obj.AccountId)
@@ -697,6 +905,14 @@ func (r *inventoryAdjustmentResolver) UpdatedBy(ctx context.Context, obj *models
 	return middlewares.GetAllUser(ctx, obj.UpdatedBy)
 }
 
+// Unit is the resolver for the unit field.
+func (r *inventoryAdjustmentDetailResolver) Unit(ctx context.Context, obj *models.InventoryAdjustmentDetail) (*models.AllProductUnit, error) {
+	if obj.UnitId == 0 {
+		return nil, nil
+	}
+	return middlewares.GetAllProductUnit(ctx, obj.UnitId)
+}
+
 // Stocks is the resolver for the stocks field.
 func (r *inventoryAdjustmentDetailResolver) Stocks(ctx context.Context, obj *models.InventoryAdjustmentDetail) ([]*models.ProductStock, error) {
 	return models.GetProductStocks(ctx, obj.ProductId, string(obj.ProductType), &obj.InventoryAdjustment.WarehouseId)
@@ -760,6 +976,21 @@ func (r *journalTransactionResolver) Account(ctx context.Context, obj *models.Jo
 	return middlewares.GetAllAccount(ctx, obj.AccountId)
 }
 
+// Charges is the resolver for the charges field.
+func (r *landedCostResolver) Charges(ctx context.Context, obj *models.LandedCost) ([]*models.LandedCostCharge, error) {
+	return models.GetLandedCostCharges(ctx, obj.ID)
+}
+
+// Lines is the resolver for the lines field.
+func (r *landedCostResolver) Lines(ctx context.Context, obj *models.LandedCost) ([]*models.LandedCostLine, error) {
+	return models.GetLandedCostLines(ctx, obj.ID)
+}
+
+// Product is the resolver for the product field.
+func (r *landedCostLineResolver) Product(ctx context.Context, obj *models.LandedCostLine) (*models.AllProduct, error) {
+	return GetAllProduct(ctx, obj.ProductId, obj.ProductType)
+}
+
 // AccountCurrency is the resolver for the accountCurrency field.
 func (r *moneyAccountResolver) AccountCurrency(ctx context.Context, obj *models.MoneyAccount) (*models.AllCurrency, error) {
 	return middlewares.GetAllCurrency(ctx, obj.AccountCurrencyId)
@@ -885,6 +1116,121 @@ func (r *mutationResolver) ToggleActiveBranch(ctx context.Context, id int, isAct
 	return models.ToggleActiveBranch(ctx, id, isActive)
 }
 
+// CreateBudget is the resolver for the createBudget field.
+func (r *mutationResolver) CreateBudget(ctx context.Context, input models.NewBudget) (*models.Budget, error) {
+	return models.CreateBudget(ctx, &input)
+}
+
+// UpdateBudget is the resolver for the updateBudget field.
+func (r *mutationResolver) UpdateBudget(ctx context.Context, id int, input models.NewBudget) (*models.Budget, error) {
+	return models.UpdateBudget(ctx, id, &input)
+}
+
+// DeleteBudget is the resolver for the deleteBudget field.
+func (r *mutationResolver) DeleteBudget(ctx context.Context, id int) (*models.Budget, error) {
+	return models.DeleteBudget(ctx, id)
+}
+
+// ImportBudget is the resolver for the importBudget field.
+func (r *mutationResolver) ImportBudget(ctx context.Context, input models.NewBudgetImport, file graphql.Upload) (*models.Budget, error) {
+	return models.ImportBudget(ctx, &input, file)
+}
+
+// CreateFixedAssetCategory is the resolver for the createFixedAssetCategory field.
+func (r *mutationResolver) CreateFixedAssetCategory(ctx context.Context, input models.NewFixedAssetCategory) (*models.FixedAssetCategory, error) {
+	return models.CreateFixedAssetCategory(ctx, &input)
+}
+
+// UpdateFixedAssetCategory is the resolver for the updateFixedAssetCategory field.
+func (r *mutationResolver) UpdateFixedAssetCategory(ctx context.Context, id int, input models.NewFixedAssetCategory) (*models.FixedAssetCategory, error) {
+	return models.UpdateFixedAssetCategory(ctx, id, &input)
+}
+
+// DeleteFixedAssetCategory is the resolver for the deleteFixedAssetCategory field.
+func (r *mutationResolver) DeleteFixedAssetCategory(ctx context.Context, id int) (*models.FixedAssetCategory, error) {
+	return models.DeleteFixedAssetCategory(ctx, id)
+}
+
+// CreateFixedAsset is the resolver for the createFixedAsset field.
+func (r *mutationResolver) CreateFixedAsset(ctx context.Context, input models.NewFixedAsset) (*models.FixedAsset, error) {
+	return models.CreateFixedAsset(ctx, &input)
+}
+
+// UpdateFixedAsset is the resolver for the updateFixedAsset field.
+func (r *mutationResolver) UpdateFixedAsset(ctx context.Context, id int, input models.NewFixedAsset) (*models.FixedAsset, error) {
+	return models.UpdateFixedAsset(ctx, id, &input)
+}
+
+// DeleteFixedAsset is the resolver for the deleteFixedAsset field.
+func (r *mutationResolver) DeleteFixedAsset(ctx context.Context, id int) (*models.FixedAsset, error) {
+	return models.DeleteFixedAsset(ctx, id)
+}
+
+// RunFixedAssetDepreciation is the resolver for the runFixedAssetDepreciation field.
+func (r *mutationResolver) RunFixedAssetDepreciation(ctx context.Context, periodDate models.MyDateString, usages []*models.NewFixedAssetUsage) ([]*models.FixedAssetDepreciation, error) {
+	return models.RunFixedAssetDepreciation(ctx, periodDate, usages)
+}
+
+// ReverseFixedAssetDepreciation is the resolver for the reverseFixedAssetDepreciation field.
+func (r *mutationResolver) ReverseFixedAssetDepreciation(ctx context.Context, fixedAssetID int) (*models.FixedAssetDepreciation, error) {
+	return models.ReverseFixedAssetDepreciation(ctx, fixedAssetID)
+}
+
+// DisposeFixedAsset is the resolver for the disposeFixedAsset field.
+func (r *mutationResolver) DisposeFixedAsset(ctx context.Context, id int, input models.NewFixedAssetDisposal) (*models.FixedAsset, error) {
+	return models.DisposeFixedAsset(ctx, id, &input)
+}
+
+// CancelFixedAssetDisposal is the resolver for the cancelFixedAssetDisposal field.
+func (r *mutationResolver) CancelFixedAssetDisposal(ctx context.Context, id int) (*models.FixedAsset, error) {
+	return models.CancelFixedAssetDisposal(ctx, id)
+}
+
+// FileTaxReturn is the resolver for the fileTaxReturn field.
+func (r *mutationResolver) FileTaxReturn(ctx context.Context, input models.NewTaxReturn) (*models.TaxReturn, error) {
+	return models.FileTaxReturn(ctx, &input)
+}
+
+// DeleteTaxReturn is the resolver for the deleteTaxReturn field.
+func (r *mutationResolver) DeleteTaxReturn(ctx context.Context, id int) (*models.TaxReturn, error) {
+	return models.DeleteTaxReturn(ctx, id)
+}
+
+// CreateFxRevaluation is the resolver for the createFxRevaluation field.
+func (r *mutationResolver) CreateFxRevaluation(ctx context.Context, input models.NewFxRevaluation) (*models.FxRevaluation, error) {
+	return models.CreateFxRevaluation(ctx, &input)
+}
+
+// DeleteFxRevaluation is the resolver for the deleteFxRevaluation field.
+func (r *mutationResolver) DeleteFxRevaluation(ctx context.Context, id int) (*models.FxRevaluation, error) {
+	return models.DeleteFxRevaluation(ctx, id)
+}
+
+// CreateCurrencyExchange is the resolver for the createCurrencyExchange field.
+func (r *mutationResolver) CreateCurrencyExchange(ctx context.Context, input models.NewCurrencyExchange) (*models.CurrencyExchange, error) {
+	return models.CreateCurrencyExchange(ctx, &input)
+}
+
+// UpdateCurrencyExchange is the resolver for the updateCurrencyExchange field.
+func (r *mutationResolver) UpdateCurrencyExchange(ctx context.Context, id int, input models.NewCurrencyExchange) (*models.CurrencyExchange, error) {
+	return models.UpdateCurrencyExchange(ctx, id, &input)
+}
+
+// DeleteCurrencyExchange is the resolver for the deleteCurrencyExchange field.
+func (r *mutationResolver) DeleteCurrencyExchange(ctx context.Context, id int) (*models.CurrencyExchange, error) {
+	return models.DeleteCurrencyExchange(ctx, id)
+}
+
+// ImportCurrencyExchange is the resolver for the importCurrencyExchange field.
+func (r *mutationResolver) ImportCurrencyExchange(ctx context.Context, file graphql.Upload) ([]*models.CurrencyExchange, error) {
+	return models.ImportCurrencyExchange(ctx, file)
+}
+
+// FetchCurrencyExchange is the resolver for the fetchCurrencyExchange field.
+func (r *mutationResolver) FetchCurrencyExchange(ctx context.Context, date models.MyDateString) ([]*models.CurrencyExchange, error) {
+	return models.FetchCurrencyExchange(ctx, date)
+}
+
 // CreateBusiness is the resolver for the createBusiness field.
 func (r *mutationResolver) CreateBusiness(ctx context.Context, input models.NewBusiness) (*models.Business, error) {
 	return models.CreateBusiness(ctx, &input)
@@ -900,6 +1246,11 @@ func (r *mutationResolver) UpdateTransactionLocking(ctx context.Context, input m
 	return models.UpdateTransactionLocking(ctx, input)
 }
 
+// SwitchInventoryValuationMethod is the resolver for the switchInventoryValuationMethod field.
+func (r *mutationResolver) SwitchInventoryValuationMethod(ctx context.Context, input models.NewInventoryValuationMethod) (*models.Business, error) {
+	return models.SwitchInventoryValuationMethod(ctx, &input)
+}
+
 // UpdateTaxSetting is the resolver for the updateTaxSetting field.
 func (r *mutationResolver) UpdateTaxSetting(ctx context.Context, input models.NewTaxSetting) (*models.Business, error) {
 	return models.UpdateTaxSetting(ctx, &input)
@@ -965,6 +1316,16 @@ func (r *mutationResolver) ToggleActiveCustomer(ctx context.Context, id int, isA
 	return models.ToggleActiveCustomer(ctx, id, isActive)
 }
 
+// SetCreditHoldCustomer is the resolver for the setCreditHoldCustomer field.
+func (r *mutationResolver) SetCreditHoldCustomer(ctx context.Context, id int, reason string) (*models.Customer, error) {
+	return models.SetCreditHoldCustomer(ctx, id, reason)
+}
+
+// ReleaseCreditHoldCustomer is the resolver for the releaseCreditHoldCustomer field.
+func (r *mutationResolver) ReleaseCreditHoldCustomer(ctx context.Context, id int) (*models.Customer, error) {
+	return models.ReleaseCreditHoldCustomer(ctx, id)
+}
+
 // CreateDeliveryMethod is the resolver for the createDeliveryMethod field.
 func (r *mutationResolver) CreateDeliveryMethod(ctx context.Context, input models.NewDeliveryMethod) (*models.DeliveryMethod, error) {
 	return models.CreateDeliveryMethod(ctx, &input)
@@ -1170,6 +1531,36 @@ func (r *mutationResolver) ToggleActiveProductUnit(ctx context.Context, id int,
 	return models.ToggleActiveProductUnit(ctx, id, isActive)
 }
 
+// SetProductUnitConversion is the resolver for the setProductUnitConversion field.
+func (r *mutationResolver) SetProductUnitConversion(ctx context.Context, productType models.ProductType, productID int, input []*models.NewProductUnitConversion) ([]*models.ProductUnitConversion, error) {
+	return models.SetProductUnitConversions(ctx, productType, productID, input)
+}
+
+// SetReorderPoint is the resolver for the setReorderPoint field.
+func (r *mutationResolver) SetReorderPoint(ctx context.Context, productType models.ProductType, productID int, input []*models.NewReorderPoint) ([]*models.ReorderPoint, error) {
+	return models.SetReorderPoints(ctx, productType, productID, input)
+}
+
+// CreatePriceList is the resolver for the createPriceList field.
+func (r *mutationResolver) CreatePriceList(ctx context.Context, input models.NewPriceList) (*models.PriceList, error) {
+	return models.CreatePriceList(ctx, &input)
+}
+
+// UpdatePriceList is the resolver for the updatePriceList field.
+func (r *mutationResolver) UpdatePriceList(ctx context.Context, id int, input models.NewPriceList) (*models.PriceList, error) {
+	return models.UpdatePriceList(ctx, id, &input)
+}
+
+// DeletePriceList is the resolver for the deletePriceList field.
+func (r *mutationResolver) DeletePriceList(ctx context.Context, id int) (*models.PriceList, error) {
+	return models.DeletePriceList(ctx, id)
+}
+
+// ToggleActivePriceList is the resolver for the toggleActivePriceList field.
+func (r *mutationResolver) ToggleActivePriceList(ctx context.Context, id int, isActive bool) (*models.PriceList, error) {
+	return models.ToggleActivePriceList(ctx, id, isActive)
+}
+
 // CreateProductVariant is the resolver for the createProductVariant field.
 func (r *mutationResolver) CreateProductVariant(ctx context.Context, input models.NewProductVariant) (*models.ProductVariant, error) {
 	return models.CreateProductVariant(ctx, &input)
@@ -1280,6 +1671,46 @@ func (r *mutationResolver) CancelSalesOrder(ctx context.Context, id int) (*model
 	return models.UpdateStatusSalesOrder(ctx, id, string(models.SalesOrderStatusCancelled))
 }
 
+// CreateEstimate is the resolver for the createEstimate field.
+func (r *mutationResolver) CreateEstimate(ctx context.Context, input models.NewEstimate) (*models.Estimate, error) {
+	return models.CreateEstimate(ctx, &input)
+}
+
+// UpdateEstimate is the resolver for the updateEstimate field.
+func (r *mutationResolver) UpdateEstimate(ctx context.Context, id int, input models.NewEstimate) (*models.Estimate, error) {
+	return models.UpdateEstimate(ctx, id, &input)
+}
+
+// DeleteEstimate is the resolver for the deleteEstimate field.
+func (r *mutationResolver) DeleteEstimate(ctx context.Context, id int) (*models.Estimate, error) {
+	return models.DeleteEstimate(ctx, id)
+}
+
+// SendEstimate is the resolver for the sendEstimate field.
+func (r *mutationResolver) SendEstimate(ctx context.Context, id int) (*models.Estimate, error) {
+	return models.UpdateStatusEstimate(ctx, id, models.EstimateStatusSent)
+}
+
+// AcceptEstimate is the resolver for the acceptEstimate field.
+func (r *mutationResolver) AcceptEstimate(ctx context.Context, id int) (*models.Estimate, error) {
+	return models.UpdateStatusEstimate(ctx, id, models.EstimateStatusAccepted)
+}
+
+// DeclineEstimate is the resolver for the declineEstimate field.
+func (r *mutationResolver) DeclineEstimate(ctx context.Context, id int) (*models.Estimate, error) {
+	return models.UpdateStatusEstimate(ctx, id, models.EstimateStatusDeclined)
+}
+
+// CreateSalesOrderFromEstimate is the resolver for the createSalesOrderFromEstimate field.
+func (r *mutationResolver) CreateSalesOrderFromEstimate(ctx context.Context, id int) (*models.SalesOrder, error) {
+	return models.ConvertEstimateToSalesOrder(ctx, id)
+}
+
+// CreateSalesInvoiceFromEstimate is the resolver for the createSalesInvoiceFromEstimate field.
+func (r *mutationResolver) CreateSalesInvoiceFromEstimate(ctx context.Context, id int) (*models.SalesInvoice, error) {
+	return models.ConvertEstimateToSalesInvoice(ctx, id)
+}
+
 // CreateSalesInvoice is the resolver for the createSalesInvoice field.
 func (r *mutationResolver) CreateSalesInvoice(ctx context.Context, input models.NewSalesInvoice) (*models.SalesInvoice, error) {
 	return models.CreateSalesInvoice(ctx, &input)
@@ -1315,6 +1746,11 @@ func (r *mutationResolver) CancelWriteOffSalesInvoice(ctx context.Context, id in
 	return models.CancelWriteOffSalesInvoice(ctx, id)
 }
 
+// SendSalesInvoice is the resolver for the sendSalesInvoice field.
+func (r *mutationResolver) SendSalesInvoice(ctx context.Context, id int, input *models.NewDocumentEmail) (*models.EmailMessage, error) {
+	return models.SendSalesInvoice(ctx, id, input)
+}
+
 // CreateCustomerPayment is the resolver for the createCustomerPayment field.
 func (r *mutationResolver) CreateCustomerPayment(ctx context.Context, input models.NewCustomerPayment) (*models.CustomerPayment, error) {
 	return models.CreateCustomerPayment(ctx, &input)
@@ -1330,6 +1766,116 @@ func (r *mutationResolver) DeleteCustomerPayment(ctx context.Context, id int) (*
 	return models.DeleteCustomerPayment(ctx, id)
 }
 
+// SendPaymentReceipt is the resolver for the sendPaymentReceipt field.
+func (r *mutationResolver) SendPaymentReceipt(ctx context.Context, id int, input *models.NewDocumentEmail) (*models.EmailMessage, error) {
+	return models.SendPaymentReceipt(ctx, id, input)
+}
+
+// SendCustomerStatement is the resolver for the sendCustomerStatement field.
+func (r *mutationResolver) SendCustomerStatement(ctx context.Context, customerID int, fromDate models.MyDateString, toDate models.MyDateString, input *models.NewDocumentEmail) (*models.EmailMessage, error) {
+	return models.SendCustomerStatement(ctx, customerID, fromDate, toDate, input)
+}
+
+// UpdateEmailSetting is the resolver for the updateEmailSetting field.
+func (r *mutationResolver) UpdateEmailSetting(ctx context.Context, input models.NewEmailSetting) (*models.EmailSetting, error) {
+	return models.UpdateEmailSetting(ctx, &input)
+}
+
+// UpdateEmailTemplate is the resolver for the updateEmailTemplate field.
+func (r *mutationResolver) UpdateEmailTemplate(ctx context.Context, input models.NewEmailTemplate) (*models.EmailTemplate, error) {
+	return models.UpdateEmailTemplate(ctx, &input)
+}
+
+// RetryEmailMessage is the resolver for the retryEmailMessage field.
+func (r *mutationResolver) RetryEmailMessage(ctx context.Context, id int) (*models.EmailMessage, error) {
+	return models.RetryEmailMessage(ctx, id)
+}
+
+// CreateApprovalPolicy is the resolver for the createApprovalPolicy field.
+func (r *mutationResolver) CreateApprovalPolicy(ctx context.Context, input models.NewApprovalPolicy) (*models.ApprovalPolicy, error) {
+	return models.CreateApprovalPolicy(ctx, &input)
+}
+
+// UpdateApprovalPolicy is the resolver for the updateApprovalPolicy field.
+func (r *mutationResolver) UpdateApprovalPolicy(ctx context.Context, id int, input models.NewApprovalPolicy) (*models.ApprovalPolicy, error) {
+	return models.UpdateApprovalPolicy(ctx, id, &input)
+}
+
+// DeleteApprovalPolicy is the resolver for the deleteApprovalPolicy field.
+func (r *mutationResolver) DeleteApprovalPolicy(ctx context.Context, id int) (*models.ApprovalPolicy, error) {
+	return models.DeleteApprovalPolicy(ctx, id)
+}
+
+// ToggleActiveApprovalPolicy is the resolver for the toggleActiveApprovalPolicy field.
+func (r *mutationResolver) ToggleActiveApprovalPolicy(ctx context.Context, id int, isActive bool) (*models.ApprovalPolicy, error) {
+	return models.ToggleActiveApprovalPolicy(ctx, id, isActive)
+}
+
+// ApproveApprovalRequest is the resolver for the approveApprovalRequest field.
+func (r *mutationResolver) ApproveApprovalRequest(ctx context.Context, id int, comment *string) (*models.ApprovalRequest, error) {
+	return models.ApproveApprovalRequest(ctx, id, comment)
+}
+
+// RejectApprovalRequest is the resolver for the rejectApprovalRequest field.
+func (r *mutationResolver) RejectApprovalRequest(ctx context.Context, id int, comment string) (*models.ApprovalRequest, error) {
+	return models.RejectApprovalRequest(ctx, id, comment)
+}
+
+// CreateWebhookEndpoint is the resolver for the createWebhookEndpoint field.
+func (r *mutationResolver) CreateWebhookEndpoint(ctx context.Context, input models.NewWebhookEndpoint) (*models.WebhookEndpoint, error) {
+	return models.CreateWebhookEndpoint(ctx, &input)
+}
+
+// UpdateWebhookEndpoint is the resolver for the updateWebhookEndpoint field.
+func (r *mutationResolver) UpdateWebhookEndpoint(ctx context.Context, id int, input models.NewWebhookEndpoint) (*models.WebhookEndpoint, error) {
+	return models.UpdateWebhookEndpoint(ctx, id, &input)
+}
+
+// DeleteWebhookEndpoint is the resolver for the deleteWebhookEndpoint field.
+func (r *mutationResolver) DeleteWebhookEndpoint(ctx context.Context, id int) (*models.WebhookEndpoint, error) {
+	return models.DeleteWebhookEndpoint(ctx, id)
+}
+
+// ToggleActiveWebhookEndpoint is the resolver for the toggleActiveWebhookEndpoint field.
+func (r *mutationResolver) ToggleActiveWebhookEndpoint(ctx context.Context, id int, isActive bool) (*models.WebhookEndpoint, error) {
+	return models.ToggleActiveWebhookEndpoint(ctx, id, isActive)
+}
+
+// RotateSecretWebhookEndpoint is the resolver for the rotateSecretWebhookEndpoint field.
+func (r *mutationResolver) RotateSecretWebhookEndpoint(ctx context.Context, id int) (*models.WebhookEndpoint, error) {
+	return models.RotateSecretWebhookEndpoint(ctx, id)
+}
+
+// RedeliverWebhookDelivery is the resolver for the redeliverWebhookDelivery field.
+func (r *mutationResolver) RedeliverWebhookDelivery(ctx context.Context, id int) (*models.WebhookDelivery, error) {
+	return models.RedeliverWebhookDelivery(ctx, id)
+}
+
+// CreatePaymentReminderRule is the resolver for the createPaymentReminderRule field.
+func (r *mutationResolver) CreatePaymentReminderRule(ctx context.Context, input models.NewPaymentReminderRule) (*models.PaymentReminderRule, error) {
+	return models.CreatePaymentReminderRule(ctx, &input)
+}
+
+// UpdatePaymentReminderRule is the resolver for the updatePaymentReminderRule field.
+func (r *mutationResolver) UpdatePaymentReminderRule(ctx context.Context, id int, input models.NewPaymentReminderRule) (*models.PaymentReminderRule, error) {
+	return models.UpdatePaymentReminderRule(ctx, id, &input)
+}
+
+// DeletePaymentReminderRule is the resolver for the deletePaymentReminderRule field.
+func (r *mutationResolver) DeletePaymentReminderRule(ctx context.Context, id int) (*models.PaymentReminderRule, error) {
+	return models.DeletePaymentReminderRule(ctx, id)
+}
+
+// ToggleActivePaymentReminderRule is the resolver for the toggleActivePaymentReminderRule field.
+func (r *mutationResolver) ToggleActivePaymentReminderRule(ctx context.Context, id int, isActive bool) (*models.PaymentReminderRule, error) {
+	return models.ToggleActivePaymentReminderRule(ctx, id, isActive)
+}
+
+// UpdateCreditControlSetting is the resolver for the updateCreditControlSetting field.
+func (r *mutationResolver) UpdateCreditControlSetting(ctx context.Context, input models.NewCreditControlSetting) (*models.CreditControlSetting, error) {
+	return models.UpdateCreditControlSetting(ctx, &input)
+}
+
 // CreateCreditNote is the resolver for the createCreditNote field.
 func (r *mutationResolver) CreateCreditNote(ctx context.Context, input models.NewCreditNote) (*models.CreditNote, error) {
 	return models.CreateCreditNote(ctx, &input)
@@ -1435,6 +1981,21 @@ func (r *mutationResolver) ToggleActiveTax(ctx context.Context, id int, isActive
 	return models.ToggleActiveTax(ctx, id, isActive)
 }
 
+// CreateWithholdingTax is the resolver for the createWithholdingTax field.
+func (r *mutationResolver) CreateWithholdingTax(ctx context.Context, input models.NewWithholdingTax) (*models.WithholdingTax, error) {
+	return models.CreateWithholdingTax(ctx, &input)
+}
+
+// UpdateWithholdingTax is the resolver for the updateWithholdingTax field.
+func (r *mutationResolver) UpdateWithholdingTax(ctx context.Context, id int, input models.NewWithholdingTax) (*models.WithholdingTax, error) {
+	return models.UpdateWithholdingTax(ctx, id, &input)
+}
+
+// DeleteWithholdingTax is the resolver for the deleteWithholdingTax field.
+func (r *mutationResolver) DeleteWithholdingTax(ctx context.Context, id int) (*models.WithholdingTax, error) {
+	return models.DeleteWithholdingTax(ctx, id)
+}
+
 // CreateTaxGroup is the resolver for the createTaxGroup field.
 func (r *mutationResolver) CreateTaxGroup(ctx context.Context, input models.NewTaxGroup) (*models.TaxGroup, error) {
 	return models.CreateTaxGroup(ctx, &input)
@@ -1550,6 +2111,11 @@ func (r *mutationResolver) CreatePurchaseOrder(ctx context.Context, input models
 	return models.CreatePurchaseOrder(ctx, &input)
 }
 
+// GeneratePurchaseOrder is the resolver for the generatePurchaseOrder field.
+func (r *mutationResolver) GeneratePurchaseOrder(ctx context.Context, warehouseID *int, supplierID *int) ([]*models.PurchaseOrder, error) {
+	return models.GeneratePurchaseOrders(ctx, warehouseID, supplierID)
+}
+
 // UpdatePurchaseOrder is the resolver for the updatePurchaseOrder field.
 func (r *mutationResolver) UpdatePurchaseOrder(ctx context.Context, id int, input models.NewPurchaseOrder) (*models.PurchaseOrder, error) {
 	return models.UpdatePurchaseOrder(ctx, id, &input)
@@ -1625,6 +2191,66 @@ func (r *mutationResolver) DeleteRecurringBill(ctx context.Context, id int) (*mo
 	return models.DeleteRecurringBill(ctx, id)
 }
 
+// PauseRecurringBill is the resolver for the pauseRecurringBill field.
+func (r *mutationResolver) PauseRecurringBill(ctx context.Context, id int) (*models.RecurringBill, error) {
+	return models.PauseRecurringBill(ctx, id)
+}
+
+// ResumeRecurringBill is the resolver for the resumeRecurringBill field.
+func (r *mutationResolver) ResumeRecurringBill(ctx context.Context, id int) (*models.RecurringBill, error) {
+	return models.ResumeRecurringBill(ctx, id)
+}
+
+// CreateRecurringInvoice is the resolver for the createRecurringInvoice field.
+func (r *mutationResolver) CreateRecurringInvoice(ctx context.Context, input models.NewRecurringInvoice) (*models.RecurringInvoice, error) {
+	return models.CreateRecurringInvoice(ctx, &input)
+}
+
+// UpdateRecurringInvoice is the resolver for the updateRecurringInvoice field.
+func (r *mutationResolver) UpdateRecurringInvoice(ctx context.Context, id int, input models.NewRecurringInvoice) (*models.RecurringInvoice, error) {
+	return models.UpdateRecurringInvoice(ctx, id, &input)
+}
+
+// DeleteRecurringInvoice is the resolver for the deleteRecurringInvoice field.
+func (r *mutationResolver) DeleteRecurringInvoice(ctx context.Context, id int) (*models.RecurringInvoice, error) {
+	return models.DeleteRecurringInvoice(ctx, id)
+}
+
+// PauseRecurringInvoice is the resolver for the pauseRecurringInvoice field.
+func (r *mutationResolver) PauseRecurringInvoice(ctx context.Context, id int) (*models.RecurringInvoice, error) {
+	return models.PauseRecurringInvoice(ctx, id)
+}
+
+// ResumeRecurringInvoice is the resolver for the resumeRecurringInvoice field.
+func (r *mutationResolver) ResumeRecurringInvoice(ctx context.Context, id int) (*models.RecurringInvoice, error) {
+	return models.ResumeRecurringInvoice(ctx, id)
+}
+
+// CreateRecurringExpense is the resolver for the createRecurringExpense field.
+func (r *mutationResolver) CreateRecurringExpense(ctx context.Context, input models.NewRecurringExpense) (*models.RecurringExpense, error) {
+	return models.CreateRecurringExpense(ctx, &input)
+}
+
+// UpdateRecurringExpense is the resolver for the updateRecurringExpense field.
+func (r *mutationResolver) UpdateRecurringExpense(ctx context.Context, id int, input models.NewRecurringExpense) (*models.RecurringExpense, error) {
+	return models.UpdateRecurringExpense(ctx, id, &input)
+}
+
+// DeleteRecurringExpense is the resolver for the deleteRecurringExpense field.
+func (r *mutationResolver) DeleteRecurringExpense(ctx context.Context, id int) (*models.RecurringExpense, error) {
+	return models.DeleteRecurringExpense(ctx, id)
+}
+
+// PauseRecurringExpense is the resolver for the pauseRecurringExpense field.
+func (r *mutationResolver) PauseRecurringExpense(ctx context.Context, id int) (*models.RecurringExpense, error) {
+	return models.PauseRecurringExpense(ctx, id)
+}
+
+// ResumeRecurringExpense is the resolver for the resumeRecurringExpense field.
+func (r *mutationResolver) ResumeRecurringExpense(ctx context.Context, id int) (*models.RecurringExpense, error) {
+	return models.ResumeRecurringExpense(ctx, id)
+}
+
 // CreateSupplierCredit is the resolver for the createSupplierCredit field.
 func (r *mutationResolver) CreateSupplierCredit(ctx context.Context, input models.NewSupplierCredit) (*models.SupplierCredit, error) {
 	return models.CreateSupplierCredit(ctx, &input)
@@ -1660,6 +2286,91 @@ func (r *mutationResolver) DeleteTransferOrder(ctx context.Context, id int) (*mo
 	return models.DeleteTransferOrder(ctx, id)
 }
 
+// ShipTransferOrder is the resolver for the shipTransferOrder field.
+func (r *mutationResolver) ShipTransferOrder(ctx context.Context, id int) (*models.TransferOrder, error) {
+	return models.ShipTransferOrder(ctx, id)
+}
+
+// CreateTransferOrderReceipt is the resolver for the createTransferOrderReceipt field.
+func (r *mutationResolver) CreateTransferOrderReceipt(ctx context.Context, input models.NewTransferOrderReceipt) (*models.TransferOrderReceipt, error) {
+	return models.CreateTransferOrderReceipt(ctx, &input)
+}
+
+// DeleteTransferOrderReceipt is the resolver for the deleteTransferOrderReceipt field.
+func (r *mutationResolver) DeleteTransferOrderReceipt(ctx context.Context, id int) (*models.TransferOrderReceipt, error) {
+	return models.DeleteTransferOrderReceipt(ctx, id)
+}
+
+// CreateBillOfMaterials is the resolver for the createBillOfMaterials field.
+func (r *mutationResolver) CreateBillOfMaterials(ctx context.Context, input models.NewBillOfMaterials) (*models.BillOfMaterials, error) {
+	return models.CreateBillOfMaterials(ctx, &input)
+}
+
+// UpdateBillOfMaterials is the resolver for the updateBillOfMaterials field.
+func (r *mutationResolver) UpdateBillOfMaterials(ctx context.Context, id int, input models.NewBillOfMaterials) (*models.BillOfMaterials, error) {
+	return models.UpdateBillOfMaterials(ctx, id, &input)
+}
+
+// DeleteBillOfMaterials is the resolver for the deleteBillOfMaterials field.
+func (r *mutationResolver) DeleteBillOfMaterials(ctx context.Context, id int) (*models.BillOfMaterials, error) {
+	return models.DeleteBillOfMaterials(ctx, id)
+}
+
+// CreateAssemblyOrder is the resolver for the createAssemblyOrder field.
+func (r *mutationResolver) CreateAssemblyOrder(ctx context.Context, input models.NewAssemblyOrder) (*models.AssemblyOrder, error) {
+	return models.CreateAssemblyOrder(ctx, &input)
+}
+
+// ConfirmAssemblyOrder is the resolver for the confirmAssemblyOrder field.
+func (r *mutationResolver) ConfirmAssemblyOrder(ctx context.Context, id int) (*models.AssemblyOrder, error) {
+	return models.ConfirmAssemblyOrder(ctx, id)
+}
+
+// DeleteAssemblyOrder is the resolver for the deleteAssemblyOrder field.
+func (r *mutationResolver) DeleteAssemblyOrder(ctx context.Context, id int) (*models.AssemblyOrder, error) {
+	return models.DeleteAssemblyOrder(ctx, id)
+}
+
+// CreateStocktake is the resolver for the createStocktake field.
+func (r *mutationResolver) CreateStocktake(ctx context.Context, input models.NewStocktake) (*models.Stocktake, error) {
+	return models.CreateStocktake(ctx, &input)
+}
+
+// CountStocktake is the resolver for the countStocktake field.
+func (r *mutationResolver) CountStocktake(ctx context.Context, id int, counts []*models.NewStocktakeCount) (*models.Stocktake, error) {
+	return models.CountStocktake(ctx, id, counts)
+}
+
+// RecountStocktake is the resolver for the recountStocktake field.
+func (r *mutationResolver) RecountStocktake(ctx context.Context, id int, lineIds []int) (*models.Stocktake, error) {
+	return models.RecountStocktake(ctx, id, lineIds)
+}
+
+// ApproveStocktake is the resolver for the approveStocktake field.
+func (r *mutationResolver) ApproveStocktake(ctx context.Context, id int) (*models.Stocktake, error) {
+	return models.ApproveStocktake(ctx, id)
+}
+
+// DeleteStocktake is the resolver for the deleteStocktake field.
+func (r *mutationResolver) DeleteStocktake(ctx context.Context, id int) (*models.Stocktake, error) {
+	return models.DeleteStocktake(ctx, id)
+}
+
+// CreateLandedCost is the resolver for the createLandedCost field.
+func (r *mutationResolver) CreateLandedCost(ctx context.Context, input models.NewLandedCost) (*models.LandedCost, error) {
+	return models.CreateLandedCost(ctx, &input)
+}
+
+// ConfirmLandedCost is the resolver for the confirmLandedCost field.
+func (r *mutationResolver) ConfirmLandedCost(ctx context.Context, id int) (*models.LandedCost, error) {
+	return models.ConfirmLandedCost(ctx, id)
+}
+
+// DeleteLandedCost is the resolver for the deleteLandedCost field.
+func (r *mutationResolver) DeleteLandedCost(ctx context.Context, id int) (*models.LandedCost, error) {
+	return models.DeleteLandedCost(ctx, id)
+}
+
 // CreateInventoryAdjustment is the resolver for the createInventoryAdjustment field.
 func (r *mutationResolver) CreateInventoryAdjustment(ctx context.Context, input models.NewInventoryAdjustment) (*models.InventoryAdjustment, error) {
 	// Determinism guard for Value Adjustments:
@@ -1701,6 +2412,56 @@ func (r *mutationResolver) DeleteBankingTransaction(ctx context.Context, id int)
 	return models.DeleteBankingTransaction(ctx, id)
 }
 
+// ImportBankStatement is the resolver for the importBankStatement field.
+func (r *mutationResolver) ImportBankStatement(ctx context.Context, accountID int, file graphql.Upload, format *models.BankStatementFormat) (*models.BankStatement, error) {
+	return models.ImportBankStatement(ctx, accountID, file, format)
+}
+
+// DeleteBankStatement is the resolver for the deleteBankStatement field.
+func (r *mutationResolver) DeleteBankStatement(ctx context.Context, id int) (*models.BankStatement, error) {
+	return models.DeleteBankStatement(ctx, id)
+}
+
+// AutoMatchBankStatement is the resolver for the autoMatchBankStatement field.
+func (r *mutationResolver) AutoMatchBankStatement(ctx context.Context, id int) (int, error) {
+	return models.AutoMatchBankStatement(ctx, id)
+}
+
+// MatchBankStatementLine is the resolver for the matchBankStatementLine field.
+func (r *mutationResolver) MatchBankStatementLine(ctx context.Context, lineID int, bankingTransactionIds []int) (*models.BankStatementLine, error) {
+	return models.MatchBankStatementLine(ctx, lineID, bankingTransactionIds)
+}
+
+// UnmatchBankStatementLine is the resolver for the unmatchBankStatementLine field.
+func (r *mutationResolver) UnmatchBankStatementLine(ctx context.Context, lineID int) (*models.BankStatementLine, error) {
+	return models.UnmatchBankStatementLine(ctx, lineID)
+}
+
+// SplitBankStatementLine is the resolver for the splitBankStatementLine field.
+func (r *mutationResolver) SplitBankStatementLine(ctx context.Context, lineID int, amounts []*decimal.Decimal) ([]*models.BankStatementLine, error) {
+	return models.SplitBankStatementLine(ctx, lineID, amounts)
+}
+
+// CreateExpenseFromBankStatementLine is the resolver for the createExpenseFromBankStatementLine field.
+func (r *mutationResolver) CreateExpenseFromBankStatementLine(ctx context.Context, lineID int, input models.NewBankStatementExpense) (*models.Expense, error) {
+	return models.CreateExpenseFromBankStatementLine(ctx, lineID, &input)
+}
+
+// CreateDepositFromBankStatementLine is the resolver for the createDepositFromBankStatementLine field.
+func (r *mutationResolver) CreateDepositFromBankStatementLine(ctx context.Context, lineID int, input models.NewBankStatementDeposit) (*models.BankingTransaction, error) {
+	return models.CreateDepositFromBankStatementLine(ctx, lineID, &input)
+}
+
+// ReconcileBankStatement is the resolver for the reconcileBankStatement field.
+func (r *mutationResolver) ReconcileBankStatement(ctx context.Context, id int) (*models.BankStatement, error) {
+	return models.ReconcileBankStatement(ctx, id)
+}
+
+// UndoBankStatementReconciliation is the resolver for the undoBankStatementReconciliation field.
+func (r *mutationResolver) UndoBankStatementReconciliation(ctx context.Context, id int) (*models.BankStatement, error) {
+	return models.UndoBankStatementReconciliation(ctx, id)
+}
+
 // CreateSupplierApplyCredit is the resolver for the createSupplierApplyCredit field.
 func (r *mutationResolver) CreateSupplierApplyCredit(ctx context.Context, input models.NewBillApplyToSupplierCredit) ([]*models.SupplierCreditBill, error) {
 	return models.CreateSupplierApplyCredit(ctx, &input)
@@ -1786,6 +2547,24 @@ func (r *paymentReceivedResolver) Currency(ctx context.Context, obj *reports.Pay
 	return middlewares.GetAllCurrency(ctx, obj.CurrencyId)
 }
 
+// LateFeeAccount is the resolver for the lateFeeAccount field.
+func (r *paymentReminderRuleResolver) LateFeeAccount(ctx context.Context, obj *models.PaymentReminderRule) (*models.AllAccount, error) {
+	if obj.LateFeeAccountId == 0 {
+		return nil, nil
+	}
+	return middlewares.GetAllAccount(ctx, obj.LateFeeAccountId)
+}
+
+// Currency is the resolver for the currency field.
+func (r *priceListResolver) Currency(ctx context.Context, obj *models.PriceList) (*models.AllCurrency, error) {
+	return middlewares.GetAllCurrency(ctx, obj.CurrencyId)
+}
+
+// Product is the resolver for the product field.
+func (r *priceListItemResolver) Product(ctx context.Context, obj *models.PriceListItem) (*models.AllProduct, error) {
+	return GetAllProduct(ctx, obj.ProductId, obj.ProductType)
+}
+
 // Category is the resolver for the category field.
 func (r *productResolver) Category(ctx context.Context, obj *models.Product) (*models.AllProductCategory, error) {
 	return middlewares.GetAllProductCategory(ctx, obj.CategoryId)
@@ -1920,6 +2699,11 @@ func (r *productGroupResolver) StockInHand(ctx context.Context, obj *models.Prod
 	return &stock, nil
 }
 
+// ProductUnit is the resolver for the productUnit field.
+func (r *productUnitConversionResolver) ProductUnit(ctx context.Context, obj *models.ProductUnitConversion) (*models.AllProductUnit, error) {
+	return middlewares.GetAllProductUnit(ctx, obj.UnitId)
+}
+
 // SalesAccount is the resolver for the salesAccount field.
 func (r *productVariantResolver) SalesAccount(ctx context.Context, obj *models.ProductVariant) (*models.AllAccount, error) {
 	return middlewares.GetAllAccount(ctx, obj.SalesAccountId)
@@ -2024,6 +2808,14 @@ func (r *purchaseOrderDetailResolver) DetailAccount(ctx context.Context, obj *mo
 	return middlewares.GetAllAccount(ctx, obj.DetailAccountId)
 }
 
+// Unit is the resolver for the unit field.
+func (r *purchaseOrderDetailResolver) Unit(ctx context.Context, obj *models.PurchaseOrderDetail) (*models.AllProductUnit, error) {
+	if obj.UnitId == 0 {
+		return nil, nil
+	}
+	return middlewares.GetAllProductUnit(ctx, obj.UnitId)
+}
+
 // DetailTax is the resolver for the detailTax field.
 func (r *purchaseOrderDetailResolver) DetailTax(ctx context.Context, obj *models.PurchaseOrderDetail) (*models.TaxInfo, error) {
 	return middlewares.ResolveTaxInfo(ctx, obj.DetailTaxId, obj.DetailTaxType)
@@ -2034,6 +2826,101 @@ func (r *queryResolver) GetDocument(ctx context.Context, id int) (*models.Docume
 	return models.GetDocument(ctx, id)
 }
 
+// GetDocumentPDF is the resolver for the getDocumentPdf field.
+func (r *queryResolver) GetDocumentPDF(ctx context.Context, documentType string, id int) (*models.DocumentPdf, error) {
+	return pdfrender.PublishDocument(ctx, documentType, id)
+}
+
+// GetCustomerStatement is the resolver for the getCustomerStatement field.
+func (r *queryResolver) GetCustomerStatement(ctx context.Context, customerID int, fromDate models.MyDateString, toDate models.MyDateString) (*models.CustomerStatement, error) {
+	return models.GetCustomerStatement(ctx, customerID, fromDate, toDate)
+}
+
+// GetEmailSetting is the resolver for the getEmailSetting field.
+func (r *queryResolver) GetEmailSetting(ctx context.Context) (*models.EmailSetting, error) {
+	return models.GetEmailSetting(ctx)
+}
+
+// ListEmailTemplate is the resolver for the listEmailTemplate field.
+func (r *queryResolver) ListEmailTemplate(ctx context.Context) ([]*models.EmailTemplate, error) {
+	return models.ListEmailTemplate(ctx)
+}
+
+// GetEmailMessage is the resolver for the getEmailMessage field.
+func (r *queryResolver) GetEmailMessage(ctx context.Context, id int) (*models.EmailMessage, error) {
+	return models.GetEmailMessage(ctx, id)
+}
+
+// GetApprovalPolicy is the resolver for the getApprovalPolicy field.
+func (r *queryResolver) GetApprovalPolicy(ctx context.Context, id int) (*models.ApprovalPolicy, error) {
+	return models.GetApprovalPolicy(ctx, id)
+}
+
+// ListApprovalPolicy is the resolver for the listApprovalPolicy field.
+func (r *queryResolver) ListApprovalPolicy(ctx context.Context, documentType *models.ApprovalDocumentType) ([]*models.ApprovalPolicy, error) {
+	return models.ListApprovalPolicy(ctx, documentType)
+}
+
+// GetApprovalRequest is the resolver for the getApprovalRequest field.
+func (r *queryResolver) GetApprovalRequest(ctx context.Context, id int) (*models.ApprovalRequest, error) {
+	return models.GetApprovalRequest(ctx, id)
+}
+
+// ListApprovalRequest is the resolver for the listApprovalRequest field.
+func (r *queryResolver) ListApprovalRequest(ctx context.Context, documentType models.ApprovalDocumentType, referenceID int) ([]*models.ApprovalRequest, error) {
+	return models.ListApprovalRequest(ctx, documentType, referenceID)
+}
+
+// GetApprovalInbox is the resolver for the getApprovalInbox field.
+func (r *queryResolver) GetApprovalInbox(ctx context.Context, documentType *models.ApprovalDocumentType) ([]*models.ApprovalRequest, error) {
+	return models.GetApprovalInbox(ctx, documentType)
+}
+
+// GetWebhookEndpoint is the resolver for the getWebhookEndpoint field.
+func (r *queryResolver) GetWebhookEndpoint(ctx context.Context, id int) (*models.WebhookEndpoint, error) {
+	return models.GetWebhookEndpoint(ctx, id)
+}
+
+// ListWebhookEndpoint is the resolver for the listWebhookEndpoint field.
+func (r *queryResolver) ListWebhookEndpoint(ctx context.Context) ([]*models.WebhookEndpoint, error) {
+	return models.ListWebhookEndpoint(ctx)
+}
+
+// GetWebhookDelivery is the resolver for the getWebhookDelivery field.
+func (r *queryResolver) GetWebhookDelivery(ctx context.Context, id int) (*models.WebhookDelivery, error) {
+	return models.GetWebhookDelivery(ctx, id)
+}
+
+// PaginateWebhookDelivery is the resolver for the paginateWebhookDelivery field.
+func (r *queryResolver) PaginateWebhookDelivery(ctx context.Context, limit *int, after *string, endpointID *int, eventType *string, status *models.WebhookDeliveryStatus) (*models.WebhookDeliveriesConnection, error) {
+	return models.PaginateWebhookDelivery(ctx, limit, after, endpointID, eventType, status)
+}
+
+// GetPaymentReminderRule is the resolver for the getPaymentReminderRule field.
+func (r *queryResolver) GetPaymentReminderRule(ctx context.Context, id int) (*models.PaymentReminderRule, error) {
+	return models.GetPaymentReminderRule(ctx, id)
+}
+
+// ListPaymentReminderRule is the resolver for the listPaymentReminderRule field.
+func (r *queryResolver) ListPaymentReminderRule(ctx context.Context) ([]*models.PaymentReminderRule, error) {
+	return models.ListPaymentReminderRule(ctx)
+}
+
+// ListPaymentReminder is the resolver for the listPaymentReminder field.
+func (r *queryResolver) ListPaymentReminder(ctx context.Context, salesInvoiceID int) ([]*models.PaymentReminder, error) {
+	return models.ListPaymentReminder(ctx, salesInvoiceID)
+}
+
+// GetCreditControlSetting is the resolver for the getCreditControlSetting field.
+func (r *queryResolver) GetCreditControlSetting(ctx context.Context) (*models.CreditControlSetting, error) {
+	return models.GetCreditControlSetting(ctx)
+}
+
+// PaginateEmailMessage is the resolver for the paginateEmailMessage field.
+func (r *queryResolver) PaginateEmailMessage(ctx context.Context, limit *int, after *string, referenceType *string, referenceID *int, status *models.EmailMessageStatus) (*models.EmailMessagesConnection, error) {
+	return models.PaginateEmailMessage(ctx, limit, after, referenceType, referenceID, status)
+}
+
 // Account is the resolver for the account field.
 func (r *queryResolver) GetAccount(ctx context.Context, id int) (*models.Account, error) {
 	return models.GetAccount(ctx, id)
@@ -2094,6 +2981,71 @@ func (r *queryResolver) ListBranch(ctx context.Context, name *string) ([]*models
 	return models.GetBranches(ctx, name)
 }
 
+// GetBudget is the resolver for the getBudget field.
+func (r *queryResolver) GetBudget(ctx context.Context, id int) (*models.Budget, error) {
+	return models.GetBudget(ctx, id)
+}
+
+// ListBudget is the resolver for the listBudget field.
+func (r *queryResolver) ListBudget(ctx context.Context, fiscalYear *int, branchID *int) ([]*models.Budget, error) {
+	return models.ListBudget(ctx, fiscalYear, branchID)
+}
+
+// GetFixedAssetCategory is the resolver for the getFixedAssetCategory field.
+func (r *queryResolver) GetFixedAssetCategory(ctx context.Context, id int) (*models.FixedAssetCategory, error) {
+	return models.GetFixedAssetCategory(ctx, id)
+}
+
+// ListFixedAssetCategory is the resolver for the listFixedAssetCategory field.
+func (r *queryResolver) ListFixedAssetCategory(ctx context.Context, name *string) ([]*models.FixedAssetCategory, error) {
+	return models.ListFixedAssetCategory(ctx, name)
+}
+
+// GetFixedAsset is the resolver for the getFixedAsset field.
+func (r *queryResolver) GetFixedAsset(ctx context.Context, id int) (*models.FixedAsset, error) {
+	return models.GetFixedAsset(ctx, id)
+}
+
+// PaginateFixedAsset is the resolver for the paginateFixedAsset field.
+func (r *queryResolver) PaginateFixedAsset(ctx context.Context, limit *int, after *string, categoryID *int, branchID *int, status *models.FixedAssetStatus, name *string) (*models.FixedAssetsConnection, error) {
+	return models.PaginateFixedAsset(ctx, limit, after, categoryID, branchID, status, name)
+}
+
+// GetFixedAssetDepreciationSchedule is the resolver for the getFixedAssetDepreciationSchedule field.
+func (r *queryResolver) GetFixedAssetDepreciationSchedule(ctx context.Context, fixedAssetID int) ([]*models.FixedAssetScheduleRow, error) {
+	return models.GetFixedAssetDepreciationSchedule(ctx, fixedAssetID)
+}
+
+// GetTaxReturn is the resolver for the getTaxReturn field.
+func (r *queryResolver) GetTaxReturn(ctx context.Context, id int) (*models.TaxReturn, error) {
+	return models.GetTaxReturn(ctx, id)
+}
+
+// ListTaxReturn is the resolver for the listTaxReturn field.
+func (r *queryResolver) ListTaxReturn(ctx context.Context) ([]*models.TaxReturn, error) {
+	return models.ListTaxReturn(ctx)
+}
+
+// GetFxRevaluation is the resolver for the getFxRevaluation field.
+func (r *queryResolver) GetFxRevaluation(ctx context.Context, id int) (*models.FxRevaluation, error) {
+	return models.GetFxRevaluation(ctx, id)
+}
+
+// ListFxRevaluation is the resolver for the listFxRevaluation field.
+func (r *queryResolver) ListFxRevaluation(ctx context.Context, branchID *int) ([]*models.FxRevaluation, error) {
+	return models.ListFxRevaluation(ctx, branchID)
+}
+
+// GetCurrencyExchange is the resolver for the getCurrencyExchange field.
+func (r *queryResolver) GetCurrencyExchange(ctx context.Context, id int) (*models.CurrencyExchange, error) {
+	return models.GetCurrencyExchange(ctx, id)
+}
+
+// ListCurrencyExchange is the resolver for the listCurrencyExchange field.
+func (r *queryResolver) ListCurrencyExchange(ctx context.Context, foreignCurrencyID *int, fromDate *models.MyDateString, toDate *models.MyDateString) ([]*models.CurrencyExchange, error) {
+	return models.GetCurrencyExchanges(ctx, foreignCurrencyID, fromDate, toDate)
+}
+
 // ListAllBranch is the resolver for the listAllBranch field.
 func (r *queryResolver) ListAllBranch(ctx context.Context) ([]*models.AllBranch, error) {
 	return models.ListAllBranch(ctx)
@@ -2124,6 +3076,11 @@ func (r *queryResolver) ListTransactionLockingRecord(ctx context.Context, userID
 	return models.GetTransactionLockingRecords(ctx, userID)
 }
 
+// ListInventoryValuationMethodRecord is the resolver for the listInventoryValuationMethodRecord field.
+func (r *queryResolver) ListInventoryValuationMethodRecord(ctx context.Context) ([]*models.InventoryValuationMethodRecord, error) {
+	return models.GetInventoryValuationMethodRecords(ctx)
+}
+
 // Comment is the resolver for the comment field.
 func (r *queryResolver) GetComment(ctx context.Context, id int) (*models.Comment, error) {
 	return models.GetComment(ctx, id)
@@ -2154,6 +3111,11 @@ func (r *queryResolver) GetCustomer(ctx context.Context, id int) (*models.Custom
 	return models.GetCustomer(ctx, id)
 }
 
+// GetCustomerCreditExposure is the resolver for the getCustomerCreditExposure field.
+func (r *queryResolver) GetCustomerCreditExposure(ctx context.Context, customerID int) (*models.CustomerCreditExposure, error) {
+	return models.GetCustomerCreditExposure(ctx, customerID)
+}
+
 // ListCustomer is the resolver for the listCustomer field.
 func (r *queryResolver) ListCustomer(ctx context.Context, name *string) ([]*models.Customer, error) {
 	return models.GetCustomers(ctx, name)
@@ -2334,6 +3296,36 @@ func (r *queryResolver) PaginateProductUnit(ctx context.Context, limit *int, aft
 	return models.PaginateProductUnit(ctx, limit, after, name)
 }
 
+// GetProductUnitConversion is the resolver for the getProductUnitConversion field.
+func (r *queryResolver) GetProductUnitConversion(ctx context.Context, barcode string) (*models.ProductUnitConversion, error) {
+	return models.GetProductUnitConversionByBarcode(ctx, barcode)
+}
+
+// ListProductUnitConversion is the resolver for the listProductUnitConversion field.
+func (r *queryResolver) ListProductUnitConversion(ctx context.Context, productType models.ProductType, productID int) ([]*models.ProductUnitConversion, error) {
+	return models.GetProductUnitConversions(ctx, productType, productID)
+}
+
+// ListReorderPoint is the resolver for the listReorderPoint field.
+func (r *queryResolver) ListReorderPoint(ctx context.Context, productType models.ProductType, productID int) ([]*models.ReorderPoint, error) {
+	return models.GetReorderPoints(ctx, productType, productID)
+}
+
+// GetPriceList is the resolver for the getPriceList field.
+func (r *queryResolver) GetPriceList(ctx context.Context, id int) (*models.PriceList, error) {
+	return models.GetPriceList(ctx, id)
+}
+
+// ListPriceList is the resolver for the listPriceList field.
+func (r *queryResolver) ListPriceList(ctx context.Context, priceListType *models.PriceListType, name *string) ([]*models.PriceList, error) {
+	return models.ListPriceList(ctx, priceListType, name)
+}
+
+// GetEffectivePrice is the resolver for the getEffectivePrice field.
+func (r *queryResolver) GetEffectivePrice(ctx context.Context, priceListType models.PriceListType, contactID int, productType models.ProductType, productID int, unitID *int, qty decimal.Decimal, date models.MyDateString, currencyID *int) (*models.EffectivePrice, error) {
+	return models.GetEffectivePrice(ctx, priceListType, contactID, productType, productID, unitID, qty, date, currencyID)
+}
+
 // GetProductVariant is the resolver for the getProductVariant field.
 func (r *queryResolver) GetProductVariant(ctx context.Context, id int) (*models.ProductVariant, error) {
 	return models.GetProductVariant(ctx, id)
@@ -2404,6 +3396,16 @@ func (r *queryResolver) PaginateSalesOrder(ctx context.Context, limit *int, afte
 	return models.PaginateSalesOrder(ctx, limit, after, orderNumber, referenceNumber, branchID, warehouseID, customerID, status, startOrderDate, endOrderDate, startExpectedShipmentDate, endExpectedShipmentDate)
 }
 
+// GetEstimate is the resolver for the getEstimate field.
+func (r *queryResolver) GetEstimate(ctx context.Context, id int) (*models.Estimate, error) {
+	return models.GetEstimate(ctx, id)
+}
+
+// PaginateEstimate is the resolver for the paginateEstimate field.
+func (r *queryResolver) PaginateEstimate(ctx context.Context, limit *int, after *string, estimateNumber *string, referenceNumber *string, branchID *int, customerID *int, status *models.EstimateStatus, startEstimateDate *models.MyDateString, endEstimateDate *models.MyDateString) (*models.EstimatesConnection, error) {
+	return models.PaginateEstimate(ctx, limit, after, estimateNumber, referenceNumber, branchID, customerID, status, startEstimateDate, endEstimateDate)
+}
+
 // GetSalesInvoice is the resolver for the getSalesInvoice field.
 func (r *queryResolver) GetSalesInvoice(ctx context.Context, id int) (*models.SalesInvoice, error) {
 	return models.GetSalesInvoice(ctx, id)
@@ -2500,6 +3502,16 @@ func (r *queryResolver) ListAllTax(ctx context.Context) ([]*models.AllTax, error
 	return models.ListAllTax(ctx)
 }
 
+// GetWithholdingTax is the resolver for the getWithholdingTax field.
+func (r *queryResolver) GetWithholdingTax(ctx context.Context, id int) (*models.WithholdingTax, error) {
+	return models.GetWithholdingTax(ctx, id)
+}
+
+// ListWithholdingTax is the resolver for the listWithholdingTax field.
+func (r *queryResolver) ListWithholdingTax(ctx context.Context, name *string) ([]*models.WithholdingTax, error) {
+	return models.ListWithholdingTax(ctx, name)
+}
+
 // TaxGroup is the resolver for the taxGroup field.
 func (r *queryResolver) GetTaxGroup(ctx context.Context, id int) (*models.TaxGroup, error) {
 	return models.GetTaxGroup(ctx, id)
@@ -2626,6 +3638,39 @@ func (r *queryResolver) PaginateRecurringBill(ctx context.Context, limit *int, a
 	return models.PaginateRecurringBill(ctx, limit, after, name)
 }
 
+// GetRecurringInvoice is the resolver for the getRecurringInvoice field.
+func (r *queryResolver) GetRecurringInvoice(ctx context.Context, id int) (*models.RecurringInvoice, error) {
+	return models.GetRecurringInvoice(ctx, id)
+}
+
+// PaginateRecurringInvoice is the resolver for the paginateRecurringInvoice field.
+func (r *queryResolver) PaginateRecurringInvoice(ctx context.Context, limit *int, after *string, name *string, customerID *int) (*models.RecurringInvoicesConnection, error) {
+	return models.PaginateRecurringInvoice(ctx, limit, after, name, customerID)
+}
+
+// GetRecurringExpense is the resolver for the getRecurringExpense field.
+func (r *queryResolver) GetRecurringExpense(ctx context.Context, id int) (*models.RecurringExpense, error) {
+	return models.GetRecurringExpense(ctx, id)
+}
+
+// PaginateRecurringExpense is the resolver for the paginateRecurringExpense field.
+func (r *queryResolver) PaginateRecurringExpense(ctx context.Context, limit *int, after *string, name *string) (*models.RecurringExpensesConnection, error) {
+	return models.PaginateRecurringExpense(ctx, limit, after, name)
+}
+
+// PreviewRecurringSchedule is the resolver for the previewRecurringSchedule field.
+func (r *queryResolver) PreviewRecurringSchedule(ctx context.Context, input models.RecurringSchedulePreview, count *int) ([]*time.Time, error) {
+	dates, err := models.PreviewRecurringDates(ctx, &input, *count)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*time.Time, len(dates))
+	for i := range dates {
+		results[i] = &dates[i]
+	}
+	return results, nil
+}
+
 // GetSupplierCredit is the resolver for the getSupplierCredit field.
 func (r *queryResolver) GetSupplierCredit(ctx context.Context, id int) (*models.SupplierCredit, error) {
 	return models.GetSupplierCredit(ctx, id)
@@ -2715,6 +3760,31 @@ func (r *queryResolver) GetProfitAndLossReport(ctx context.Context, fromDate mod
 	return []*models.ProfitAndLossResponse{response}, nil
 }
 
+// GetBudgetVsActualProfitAndLossReport is the resolver for the getBudgetVsActualProfitAndLossReport field.
+func (r *queryResolver) GetBudgetVsActualProfitAndLossReport(ctx context.Context, budgetID int, periodType *models.BudgetPeriodType) (*models.BudgetVsActualResponse, error) {
+	return reports.GetBudgetVsActualProfitAndLossReport(ctx, budgetID, periodType)
+}
+
+// GetBudgetVsActualBalanceSheetReport is the resolver for the getBudgetVsActualBalanceSheetReport field.
+func (r *queryResolver) GetBudgetVsActualBalanceSheetReport(ctx context.Context, budgetID int, periodType *models.BudgetPeriodType) (*models.BudgetVsActualResponse, error) {
+	return reports.GetBudgetVsActualBalanceSheetReport(ctx, budgetID, periodType)
+}
+
+// GetFixedAssetRegisterReport is the resolver for the getFixedAssetRegisterReport field.
+func (r *queryResolver) GetFixedAssetRegisterReport(ctx context.Context, asOfDate models.MyDateString, categoryID *int, branchID *int) ([]*reports.FixedAssetRegisterResponse, error) {
+	return reports.GetFixedAssetRegisterReport(ctx, asOfDate, categoryID, branchID)
+}
+
+// GetTaxSummaryReport is the resolver for the getTaxSummaryReport field.
+func (r *queryResolver) GetTaxSummaryReport(ctx context.Context, fromDate models.MyDateString, toDate models.MyDateString, branchID *int, reportBasis *models.ReportBasis) (*reports.TaxSummaryReportResponse, error) {
+	return reports.GetTaxSummaryReport(ctx, fromDate, toDate, branchID, reportBasis)
+}
+
+// GetTaxSummaryDetailReport is the resolver for the getTaxSummaryDetailReport field.
+func (r *queryResolver) GetTaxSummaryDetailReport(ctx context.Context, fromDate models.MyDateString, toDate models.MyDateString, taxID int, taxType models.TaxType, branchID *int, reportBasis *models.ReportBasis) ([]*models.TaxTransaction, error) {
+	return reports.GetTaxSummaryDetailReport(ctx, fromDate, toDate, taxID, taxType, branchID, reportBasis)
+}
+
 // GetCashFlowReport is the resolver for the getCashFlowReport field.
 func (r *queryResolver) GetCashFlowReport(ctx context.Context, fromDate models.MyDateString, toDate models.MyDateString, reportType string, branchID *int) ([]*reports.CashFlowResponse, error) {
 	return reports.GetCashFlowReport(ctx, fromDate, toDate, reportType, branchID)
@@ -2745,6 +3815,71 @@ func (r *queryResolver) PaginateTransferOrder(ctx context.Context, limit *int, a
 	return models.PaginateTransferOrder(ctx, limit, after, orderNumber, currentStatus)
 }
 
+// ListTransferOrderReceipt is the resolver for the listTransferOrderReceipt field.
+func (r *queryResolver) ListTransferOrderReceipt(ctx context.Context, transferOrderID int) ([]*models.TransferOrderReceipt, error) {
+	return models.ListTransferOrderReceipt(ctx, transferOrderID)
+}
+
+// GetBillOfMaterials is the resolver for the getBillOfMaterials field.
+func (r *queryResolver) GetBillOfMaterials(ctx context.Context, id int) (*models.BillOfMaterials, error) {
+	return models.GetBillOfMaterials(ctx, id)
+}
+
+// ListBillOfMaterials is the resolver for the listBillOfMaterials field.
+func (r *queryResolver) ListBillOfMaterials(ctx context.Context, productID *int, productType *models.ProductType, kitOnSale *bool) ([]*models.BillOfMaterials, error) {
+	return models.ListBillOfMaterials(ctx, productID, productType, kitOnSale)
+}
+
+// GetAssemblyOrder is the resolver for the getAssemblyOrder field.
+func (r *queryResolver) GetAssemblyOrder(ctx context.Context, id int) (*models.AssemblyOrder, error) {
+	return models.GetAssemblyOrder(ctx, id)
+}
+
+// PaginateAssemblyOrder is the resolver for the paginateAssemblyOrder field.
+func (r *queryResolver) PaginateAssemblyOrder(ctx context.Context, limit *int, after *string, orderNumber *string, orderType *models.AssemblyOrderType, currentStatus *models.AssemblyOrderStatus, salesInvoiceID *int) (*models.AssemblyOrdersConnection, error) {
+	return models.PaginateAssemblyOrder(ctx, limit, after, orderNumber, orderType, currentStatus, salesInvoiceID)
+}
+
+// GetStocktake is the resolver for the getStocktake field.
+func (r *queryResolver) GetStocktake(ctx context.Context, id int) (*models.Stocktake, error) {
+	return models.GetStocktake(ctx, id)
+}
+
+// PaginateStocktake is the resolver for the paginateStocktake field.
+func (r *queryResolver) PaginateStocktake(ctx context.Context, limit *int, after *string, warehouseID *int, currentStatus *models.StocktakeStatus) (*models.StocktakesConnection, error) {
+	return models.PaginateStocktake(ctx, limit, after, warehouseID, currentStatus)
+}
+
+// GetAbcClassification is the resolver for the getAbcClassification field.
+func (r *queryResolver) GetAbcClassification(ctx context.Context, warehouseID int) ([]*models.AbcClassificationResponse, error) {
+	return models.GetAbcClassification(ctx, warehouseID)
+}
+
+// GetLandedCost is the resolver for the getLandedCost field.
+func (r *queryResolver) GetLandedCost(ctx context.Context, id int) (*models.LandedCost, error) {
+	return models.GetLandedCost(ctx, id)
+}
+
+// PaginateLandedCost is the resolver for the paginateLandedCost field.
+func (r *queryResolver) PaginateLandedCost(ctx context.Context, limit *int, after *string, landedCostNumber *string, currentStatus *models.LandedCostStatus, billID *int) (*models.LandedCostsConnection, error) {
+	return models.PaginateLandedCost(ctx, limit, after, landedCostNumber, currentStatus, billID)
+}
+
+// GetSerialNumber is the resolver for the getSerialNumber field.
+func (r *queryResolver) GetSerialNumber(ctx context.Context, id int) (*models.SerialNumber, error) {
+	return models.GetSerialNumber(ctx, id)
+}
+
+// PaginateSerialNumber is the resolver for the paginateSerialNumber field.
+func (r *queryResolver) PaginateSerialNumber(ctx context.Context, limit *int, after *string, serialNo *string, productID *int, productType *models.ProductType, warehouseID *int, currentStatus *models.SerialNumberStatus) (*models.SerialNumbersConnection, error) {
+	return models.PaginateSerialNumber(ctx, limit, after, serialNo, productID, productType, warehouseID, currentStatus)
+}
+
+// GetSerialNumberHistory is the resolver for the getSerialNumberHistory field.
+func (r *queryResolver) GetSerialNumberHistory(ctx context.Context, serialNo string, productID *int, productType *models.ProductType) ([]*models.SerialNumberMovement, error) {
+	return models.GetSerialNumberHistory(ctx, serialNo, productID, productType)
+}
+
 // GetInventoryAdjustment is the resolver for the getInventoryAdjustment field.
 func (r *queryResolver) GetInventoryAdjustment(ctx context.Context, id int) (*models.InventoryAdjustment, error) {
 	return models.GetInventoryAdjustment(ctx, id)
@@ -2803,6 +3938,26 @@ func (r *queryResolver) PaginateBankingTransaction(ctx context.Context, limit *i
 		endDate)
 }
 
+// GetBankStatement is the resolver for the getBankStatement field.
+func (r *queryResolver) GetBankStatement(ctx context.Context, id int) (*models.BankStatement, error) {
+	return models.GetBankStatement(ctx, id)
+}
+
+// PaginateBankStatement is the resolver for the paginateBankStatement field.
+func (r *queryResolver) PaginateBankStatement(ctx context.Context, limit *int, after *string, accountID *int, status *models.BankStatementStatus) (*models.BankStatementsConnection, error) {
+	return models.PaginateBankStatement(ctx, limit, after, accountID, status)
+}
+
+// SuggestBankStatementMatches is the resolver for the suggestBankStatementMatches field.
+func (r *queryResolver) SuggestBankStatementMatches(ctx context.Context, lineID int) ([]*models.BankingTransaction, error) {
+	return models.SuggestBankStatementMatches(ctx, lineID)
+}
+
+// GetBankReconciliationSummary is the resolver for the getBankReconciliationSummary field.
+func (r *queryResolver) GetBankReconciliationSummary(ctx context.Context, accountID int, startDate models.MyDateString, endDate models.MyDateString) (*models.BankReconciliationSummary, error) {
+	return models.GetBankReconciliationSummary(ctx, accountID, startDate, endDate)
+}
+
 // GetProductSalesReport is the resolver for the getProductSalesReport field.
 func (r *queryResolver) GetProductSalesReport(ctx context.Context, fromDate models.MyDateString, toDate models.MyDateString, branchID *int) ([]*reports.ProductSalesReportResponse, error) {
 	return reports.GetProductSalesReport(ctx, fromDate, toDate, branchID)
@@ -2813,6 +3968,16 @@ func (r *queryResolver) GetStockSummaryReport(ctx context.Context, fromDate mode
 	return reports.GetStockSummaryReport(ctx, fromDate, toDate, warehouseID)
 }
 
+// GetLowStockReport is the resolver for the getLowStockReport field.
+func (r *queryResolver) GetLowStockReport(ctx context.Context, warehouseID *int, supplierID *int) ([]*models.LowStockResponse, error) {
+	return reports.GetLowStockReport(ctx, warehouseID, supplierID)
+}
+
+// GetInTransitTransferReport is the resolver for the getInTransitTransferReport field.
+func (r *queryResolver) GetInTransitTransferReport(ctx context.Context, warehouseID *int) ([]*models.InTransitTransferResponse, error) {
+	return models.GetInTransitTransferReport(ctx, warehouseID)
+}
+
 // GetARAgingSummaryReport is the resolver for the getARAgingSummaryReport field.
 func (r *queryResolver) GetARAgingSummaryReport(ctx context.Context, currentDate models.MyDateString, branchID *int, warehouseID *int) ([]*reports.ARAgingSummaryResponse, error) {
 	return reports.GetARAgingSummaryReport(ctx, currentDate, branchID, warehouseID)
@@ -2953,6 +4118,16 @@ func (r *queryResolver) GetPaymentsMade(ctx context.Context, fromDate models.MyD
 	return reports.GetPaymentsMadeReport(ctx, fromDate, toDate)
 }
 
+// GetWithholdingTaxSummaryReport is the resolver for the getWithholdingTaxSummaryReport field.
+func (r *queryResolver) GetWithholdingTaxSummaryReport(ctx context.Context, fromDate models.MyDateString, toDate models.MyDateString, branchID *int, supplierID *int) ([]*reports.WithholdingTaxSummary, error) {
+	return reports.GetWithholdingTaxSummaryReport(ctx, fromDate, toDate, branchID, supplierID)
+}
+
+// GetWithholdingTaxCertificate is the resolver for the getWithholdingTaxCertificate field.
+func (r *queryResolver) GetWithholdingTaxCertificate(ctx context.Context, supplierID int, fromDate models.MyDateString, toDate models.MyDateString, branchID *int) (*reports.WithholdingTaxCertificate, error) {
+	return reports.GetWithholdingTaxCertificate(ctx, supplierID, fromDate, toDate, branchID)
+}
+
 // GetCustomerRefundHistoryReport is the resolver for the getCustomerRefundHistoryReport field.
 func (r *queryResolver) GetCustomerRefundHistoryReport(ctx context.Context, fromDate models.MyDateString, toDate models.MyDateString) ([]*reports.CustomerRefundHistory, error) {
 	return reports.GetCustomerRefundHistoryReport(ctx, fromDate, toDate)
@@ -3048,6 +4223,14 @@ func (r *recurringBillResolver) BillTax(ctx context.Context, obj *models.Recurri
 	return middlewares.ResolveTaxInfo(ctx, obj.BillTaxId, obj.BillTaxType)
 }
 
+// Warehouse is the resolver for the warehouse field.
+func (r *recurringBillResolver) Warehouse(ctx context.Context, obj *models.RecurringBill) (*models.AllWarehouse, error) {
+	if obj.WarehouseId == 0 {
+		return nil, nil
+	}
+	return middlewares.GetAllWarehouse(ctx, obj.WarehouseId)
+}
+
 // Details is the resolver for the details field.
 func (r *recurringBillResolver) Details(ctx context.Context, obj *models.RecurringBill) ([]*models.RecurringBillDetail, error) {
 	return middlewares.GetRecurringBillDetails(ctx, obj.ID)
@@ -3063,6 +4246,91 @@ func (r *recurringBillDetailResolver) DetailTax(ctx context.Context, obj *models
 	return middlewares.ResolveTaxInfo(ctx, obj.DetailTaxId, obj.DetailTaxType)
 }
 
+// ExpenseAccount is the resolver for the expenseAccount field.
+func (r *recurringExpenseResolver) ExpenseAccount(ctx context.Context, obj *models.RecurringExpense) (*models.AllAccount, error) {
+	return middlewares.GetAllAccount(ctx, obj.ExpenseAccountId)
+}
+
+// AssetAccount is the resolver for the assetAccount field.
+func (r *recurringExpenseResolver) AssetAccount(ctx context.Context, obj *models.RecurringExpense) (*models.AllAccount, error) {
+	return middlewares.GetAllAccount(ctx, obj.AssetAccountId)
+}
+
+// Branch is the resolver for the branch field.
+func (r *recurringExpenseResolver) Branch(ctx context.Context, obj *models.RecurringExpense) (*models.AllBranch, error) {
+	return middlewares.GetAllBranch(ctx, obj.BranchId)
+}
+
+// Currency is the resolver for the currency field.
+func (r *recurringExpenseResolver) Currency(ctx context.Context, obj *models.RecurringExpense) (*models.AllCurrency, error) {
+	return middlewares.GetAllCurrency(ctx, obj.CurrencyId)
+}
+
+// Supplier is the resolver for the supplier field.
+func (r *recurringExpenseResolver) Supplier(ctx context.Context, obj *models.RecurringExpense) (*models.Supplier, error) {
+	return middlewares.GetSupplier(ctx, obj.SupplierId)
+}
+
+// Customer is the resolver for the customer field.
+func (r *recurringExpenseResolver) Customer(ctx context.Context, obj *models.RecurringExpense) (*models.Customer, error) {
+	return middlewares.GetCustomer(ctx, obj.CustomerId)
+}
+
+// ExpenseTax is the resolver for the expenseTax field.
+func (r *recurringExpenseResolver) ExpenseTax(ctx context.Context, obj *models.RecurringExpense) (*models.TaxInfo, error) {
+	return middlewares.ResolveTaxInfo(ctx, obj.ExpenseTaxId, obj.ExpenseTaxType)
+}
+
+// Customer is the resolver for the customer field.
+func (r *recurringInvoiceResolver) Customer(ctx context.Context, obj *models.RecurringInvoice) (*models.Customer, error) {
+	return middlewares.GetCustomer(ctx, obj.CustomerId)
+}
+
+// Branch is the resolver for the branch field.
+func (r *recurringInvoiceResolver) Branch(ctx context.Context, obj *models.RecurringInvoice) (*models.AllBranch, error) {
+	return middlewares.GetAllBranch(ctx, obj.BranchId)
+}
+
+// SalesPerson is the resolver for the salesPerson field.
+func (r *recurringInvoiceResolver) SalesPerson(ctx context.Context, obj *models.RecurringInvoice) (*models.AllSalesPerson, error) {
+	return middlewares.GetAllSalesPerson(ctx, obj.SalesPersonId)
+}
+
+// Currency is the resolver for the currency field.
+func (r *recurringInvoiceResolver) Currency(ctx context.Context, obj *models.RecurringInvoice) (*models.AllCurrency, error) {
+	return middlewares.GetAllCurrency(ctx, obj.CurrencyId)
+}
+
+// Warehouse is the resolver for the warehouse field.
+func (r *recurringInvoiceResolver) Warehouse(ctx context.Context, obj *models.RecurringInvoice) (*models.AllWarehouse, error) {
+	return middlewares.GetAllWarehouse(ctx, obj.WarehouseId)
+}
+
+// InvoiceTax is the resolver for the invoiceTax field.
+func (r *recurringInvoiceResolver) InvoiceTax(ctx context.Context, obj *models.RecurringInvoice) (*models.TaxInfo, error) {
+	return middlewares.ResolveTaxInfo(ctx, obj.InvoiceTaxId, obj.InvoiceTaxType)
+}
+
+// Details is the resolver for the details field.
+func (r *recurringInvoiceResolver) Details(ctx context.Context, obj *models.RecurringInvoice) ([]*models.RecurringInvoiceDetail, error) {
+	return middlewares.GetRecurringInvoiceDetails(ctx, obj.ID)
+}
+
+// Product is the resolver for the product field.
+func (r *recurringInvoiceDetailResolver) Product(ctx context.Context, obj *models.RecurringInvoiceDetail) (*models.AllProduct, error) {
+	return GetAllProduct(ctx, obj.ProductId, obj.ProductType)
+}
+
+// DetailAccount is the resolver for the detailAccount field.
+func (r *recurringInvoiceDetailResolver) DetailAccount(ctx context.Context, obj *models.RecurringInvoiceDetail) (*models.AllAccount, error) {
+	return middlewares.GetAllAccount(ctx, obj.DetailAccountId)
+}
+
+// DetailTax is the resolver for the detailTax field.
+func (r *recurringInvoiceDetailResolver) DetailTax(ctx context.Context, obj *models.RecurringInvoiceDetail) (*models.TaxInfo, error) {
+	return middlewares.ResolveTaxInfo(ctx, obj.DetailTaxId, obj.DetailTaxType)
+}
+
 // Branch is the resolver for the branch field.
 func (r *refundResolver) Branch(ctx context.Context, obj *models.Refund) (*models.AllBranch, error) {
 	return middlewares.GetAllBranch(ctx, obj.BranchId)
@@ -3093,6 +4361,11 @@ func (r *refundResolver) Customer(ctx context.Context, obj *models.Refund) (*mod
 	return middlewares.GetCustomer(ctx, obj.CustomerId)
 }
 
+// Warehouse is the resolver for the warehouse field.
+func (r *reorderPointResolver) Warehouse(ctx context.Context, obj *models.ReorderPoint) (*models.AllWarehouse, error) {
+	return middlewares.GetAllWarehouse(ctx, obj.WarehouseId)
+}
+
 // RoleModules is the resolver for the roleModules field.
 func (r *roleResolver) RoleModules(ctx context.Context, obj *models.Role) ([]*models.RoleModule, error) {
 	return middlewares.GetRoleModules(ctx, obj.ID)
@@ -3143,6 +4416,11 @@ func (r *salesInvoiceResolver) Documents(ctx context.Context, obj *models.SalesI
 	return middlewares.GetSalesInvoiceDocuments(ctx, obj.ID)
 }
 
+// PaymentReminders is the resolver for the paymentReminders field.
+func (r *salesInvoiceResolver) PaymentReminders(ctx context.Context, obj *models.SalesInvoice) ([]*models.PaymentReminder, error) {
+	return models.ListPaymentReminder(ctx, obj.ID)
+}
+
 // Details is the resolver for the details field.
 func (r *salesInvoiceResolver) Details(ctx context.Context, obj *models.SalesInvoice) ([]*models.SalesInvoiceDetail, error) {
 	return middlewares.GetSalesInvoiceDetails(ctx, obj.ID)
@@ -3173,6 +4451,14 @@ func (r *salesInvoiceDetailResolver) DetailAccount(ctx context.Context, obj *mod
 	return middlewares.GetAllAccount(ctx, obj.DetailAccountId)
 }
 
+// Unit is the resolver for the unit field.
+func (r *salesInvoiceDetailResolver) Unit(ctx context.Context, obj *models.SalesInvoiceDetail) (*models.AllProductUnit, error) {
+	if obj.UnitId == 0 {
+		return nil, nil
+	}
+	return middlewares.GetAllProductUnit(ctx, obj.UnitId)
+}
+
 // DetailTax is the resolver for the detailTax field.
 func (r *salesInvoiceDetailResolver) DetailTax(ctx context.Context, obj *models.SalesInvoiceDetail) (*models.TaxInfo, error) {
 	return middlewares.ResolveTaxInfo(ctx, obj.DetailTaxId, obj.DetailTaxType)
@@ -3247,11 +4533,59 @@ func (r *salesOrderDetailResolver) DetailAccount(ctx context.Context, obj *model
 	return middlewares.GetAllAccount(ctx, obj.DetailAccountId)
 }
 
+// Unit is the resolver for the unit field.
+func (r *salesOrderDetailResolver) Unit(ctx context.Context, obj *models.SalesOrderDetail) (*models.AllProductUnit, error) {
+	if obj.UnitId == 0 {
+		return nil, nil
+	}
+	return middlewares.GetAllProductUnit(ctx, obj.UnitId)
+}
+
 // DetailTax is the resolver for the detailTax field.
 func (r *salesOrderDetailResolver) DetailTax(ctx context.Context, obj *models.SalesOrderDetail) (*models.TaxInfo, error) {
 	return middlewares.ResolveTaxInfo(ctx, obj.DetailTaxId, obj.DetailTaxType)
 }
 
+// Product is the resolver for the product field.
+func (r *serialNumberResolver) Product(ctx context.Context, obj *models.SerialNumber) (*models.AllProduct, error) {
+	return GetAllProduct(ctx, obj.ProductId, obj.ProductType)
+}
+
+// Warehouse is the resolver for the warehouse field.
+func (r *serialNumberResolver) Warehouse(ctx context.Context, obj *models.SerialNumber) (*models.AllWarehouse, error) {
+	return middlewares.GetAllWarehouse(ctx, obj.WarehouseId)
+}
+
+// Supplier is the resolver for the supplier field.
+func (r *serialNumberResolver) Supplier(ctx context.Context, obj *models.SerialNumber) (*models.Supplier, error) {
+	return middlewares.GetSupplier(ctx, obj.SupplierId)
+}
+
+// Customer is the resolver for the customer field.
+func (r *serialNumberResolver) Customer(ctx context.Context, obj *models.SerialNumber) (*models.Customer, error) {
+	return middlewares.GetCustomer(ctx, obj.CustomerId)
+}
+
+// ReferenceType is the resolver for the referenceType field.
+func (r *serialNumberMovementResolver) ReferenceType(ctx context.Context, obj *models.SerialNumberMovement) (string, error) {
+	return string(obj.ReferenceType), nil
+}
+
+// Warehouse is the resolver for the warehouse field.
+func (r *serialNumberMovementResolver) Warehouse(ctx context.Context, obj *models.SerialNumberMovement) (*models.AllWarehouse, error) {
+	return middlewares.GetAllWarehouse(ctx, obj.WarehouseId)
+}
+
+// Supplier is the resolver for the supplier field.
+func (r *serialNumberMovementResolver) Supplier(ctx context.Context, obj *models.SerialNumberMovement) (*models.Supplier, error) {
+	return middlewares.GetSupplier(ctx, obj.SupplierId)
+}
+
+// Customer is the resolver for the customer field.
+func (r *serialNumberMovementResolver) Customer(ctx context.Context, obj *models.SerialNumberMovement) (*models.Customer, error) {
+	return middlewares.GetCustomer(ctx, obj.CustomerId)
+}
+
 // State is the resolver for the state field.
 func (r *shippingAddressResolver) State(ctx context.Context, obj *models.ShippingAddress) (*models.AllState, error) {
 	return middlewares.GetAllState(ctx, obj.StateId)
@@ -3267,6 +4601,21 @@ func (r *stockSummaryResolver) Product(ctx context.Context, obj *models.StockSum
 	return GetAllProduct(ctx, obj.ProductId, obj.ProductType)
 }
 
+// Warehouse is the resolver for the warehouse field.
+func (r *stocktakeResolver) Warehouse(ctx context.Context, obj *models.Stocktake) (*models.AllWarehouse, error) {
+	return middlewares.GetAllWarehouse(ctx, obj.WarehouseId)
+}
+
+// Lines is the resolver for the lines field.
+func (r *stocktakeResolver) Lines(ctx context.Context, obj *models.Stocktake) ([]*models.StocktakeLine, error) {
+	return models.GetStocktakeLines(ctx, obj.ID)
+}
+
+// Product is the resolver for the product field.
+func (r *stocktakeLineResolver) Product(ctx context.Context, obj *models.StocktakeLine) (*models.AllProduct, error) {
+	return GetAllProduct(ctx, obj.ProductId, obj.ProductType)
+}
+
 // Currency is the resolver for the currency field.
 func (r *supplierResolver) Currency(ctx context.Context, obj *models.Supplier) (*models.AllCurrency, error) {
 	return middlewares.GetAllCurrency(ctx, obj.CurrencyId)
@@ -3465,6 +4814,14 @@ func (r *supplierCreditDetailResolver) DetailAccount(ctx context.Context, obj *m
 	return middlewares.GetAllAccount(ctx, obj.DetailAccountId)
 }
 
+// Unit is the resolver for the unit field.
+func (r *supplierCreditDetailResolver) Unit(ctx context.Context, obj *models.SupplierCreditDetail) (*models.AllProductUnit, error) {
+	if obj.UnitId == 0 {
+		return nil, nil
+	}
+	return middlewares.GetAllProductUnit(ctx, obj.UnitId)
+}
+
 // DetailTax is the resolver for the detailTax field.
 func (r *supplierCreditDetailResolver) DetailTax(ctx context.Context, obj *models.SupplierCreditDetail) (*models.TaxInfo, error) {
 	return middlewares.ResolveTaxInfo(ctx, obj.DetailTaxId, obj.DetailTaxType)
@@ -3505,6 +4862,14 @@ func (r *supplierPaymentResolver) WithdrawAccount(ctx context.Context, obj *mode
 	return middlewares.GetAllAccount(ctx, obj.WithdrawAccountId)
 }
 
+// WithholdingTax is the resolver for the withholdingTax field.
+func (r *supplierPaymentResolver) WithholdingTax(ctx context.Context, obj *models.SupplierPayment) (*models.WithholdingTax, error) {
+	if obj.WithholdingTaxId == 0 {
+		return nil, nil
+	}
+	return models.GetWithholdingTax(ctx, obj.WithholdingTaxId)
+}
+
 // Documents is the resolver for the documents field.
 func (r *supplierPaymentResolver) Documents(ctx context.Context, obj *models.SupplierPayment) ([]*models.Document, error) {
 	return middlewares.GetSupplierPaymentDocuments(ctx, obj.ID)
@@ -3558,6 +4923,14 @@ func (r *transferOrderResolver) Details(ctx context.Context, obj *models.Transfe
 	return middlewares.GetTransferOrderDetails(ctx, obj.ID)
 }
 
+// Unit is the resolver for the unit field.
+func (r *transferOrderDetailResolver) Unit(ctx context.Context, obj *models.TransferOrderDetail) (*models.AllProductUnit, error) {
+	if obj.UnitId == 0 {
+		return nil, nil
+	}
+	return middlewares.GetAllProductUnit(ctx, obj.UnitId)
+}
+
 // Role is the resolver for the role field.
 func (r *userAccountResolver) Role(ctx context.Context, obj *models.UserAccount) (*models.Role, error) {
 	return middlewares.GetRole(ctx, utils.DereferencePtr(obj.RoleId))
@@ -3583,6 +4956,41 @@ func (r *warehouseInventoryResponseResolver) ProductUnit(ctx context.Context, ob
 	return middlewares.GetAllProductUnit(ctx, obj.ProductUnitId)
 }
 
+// EventType is the resolver for the eventType field.
+func (r *webhookDeliveryResolver) EventType(ctx context.Context, obj *models.WebhookDelivery) (string, error) {
+	return string(obj.EventType), nil
+}
+
+// Events is the resolver for the events field.
+func (r *webhookEndpointResolver) Events(ctx context.Context, obj *models.WebhookEndpoint) ([]string, error) {
+	return obj.EventList(), nil
+}
+
+// ForeignCurrency is the resolver for the foreignCurrency field.
+func (r *currencyExchangeResolver) ForeignCurrency(ctx context.Context, obj *models.CurrencyExchange) (*models.AllCurrency, error) {
+	return middlewares.GetAllCurrency(ctx, obj.ForeignCurrencyId)
+}
+
+// Account is the resolver for the account field.
+func (r *fxRevaluationLineResolver) Account(ctx context.Context, obj *models.FxRevaluationLine) (*models.AllAccount, error) {
+	return middlewares.GetAllAccount(ctx, obj.AccountId)
+}
+
+// Currency is the resolver for the currency field.
+func (r *fxRevaluationLineResolver) Currency(ctx context.Context, obj *models.FxRevaluationLine) (*models.AllCurrency, error) {
+	return middlewares.GetAllCurrency(ctx, obj.CurrencyId)
+}
+
+// PayableAccount is the resolver for the payableAccount field.
+func (r *withholdingTaxResolver) PayableAccount(ctx context.Context, obj *models.WithholdingTax) (*models.AllAccount, error) {
+	return middlewares.GetAllAccount(ctx, obj.PayableAccountId)
+}
+
+// ReceivableAccount is the resolver for the receivableAccount field.
+func (r *withholdingTaxResolver) ReceivableAccount(ctx context.Context, obj *models.WithholdingTax) (*models.AllAccount, error) {
+	return middlewares.GetAllAccount(ctx, obj.ReceivableAccountId)
+}
+
 // Account returns AccountResolver implementation.
 func (r *Resolver) Account() AccountResolver { return &accountResolver{r} }
 
@@ -3615,6 +5023,22 @@ func (r *Resolver) AllProductVariant() AllProductVariantResolver {
 	return &allProductVariantResolver{r}
 }
 
+// AssemblyOrder returns AssemblyOrderResolver implementation.
+func (r *Resolver) AssemblyOrder() AssemblyOrderResolver { return &assemblyOrderResolver{r} }
+
+// AssemblyOrderDetail returns AssemblyOrderDetailResolver implementation.
+func (r *Resolver) AssemblyOrderDetail() AssemblyOrderDetailResolver {
+	return &assemblyOrderDetailResolver{r}
+}
+
+// BankStatement returns BankStatementResolver implementation.
+func (r *Resolver) BankStatement() BankStatementResolver { return &bankStatementResolver{r} }
+
+// BankStatementLine returns BankStatementLineResolver implementation.
+func (r *Resolver) BankStatementLine() BankStatementLineResolver {
+	return &bankStatementLineResolver{r}
+}
+
 // BankingAccount returns BankingAccountResolver implementation.
 func (r *Resolver) BankingAccount() BankingAccountResolver { return &bankingAccountResolver{r} }
 
@@ -3629,6 +5053,14 @@ func (r *Resolver) Bill() BillResolver { return &billResolver{r} }
 // BillDetail returns BillDetailResolver implementation.
 func (r *Resolver) BillDetail() BillDetailResolver { return &billDetailResolver{r} }
 
+// BillOfMaterials returns BillOfMaterialsResolver implementation.
+func (r *Resolver) BillOfMaterials() BillOfMaterialsResolver { return &billOfMaterialsResolver{r} }
+
+// BillOfMaterialsComponent returns BillOfMaterialsComponentResolver implementation.
+func (r *Resolver) BillOfMaterialsComponent() BillOfMaterialsComponentResolver {
+	return &billOfMaterialsComponentResolver{r}
+}
+
 // BillPayment returns BillPaymentResolver implementation.
 func (r *Resolver) BillPayment() BillPaymentResolver { return &billPaymentResolver{r} }
 
@@ -3641,6 +5073,12 @@ func (r *Resolver) BillsConnection() BillsConnectionResolver { return &billsConn
 // Branch returns BranchResolver implementation.
 func (r *Resolver) Branch() BranchResolver { return &branchResolver{r} }
 
+// Budget returns BudgetResolver implementation.
+func (r *Resolver) Budget() BudgetResolver { return &budgetResolver{r} }
+
+// BudgetAccount returns BudgetAccountResolver implementation.
+func (r *Resolver) BudgetAccount() BudgetAccountResolver { return &budgetAccountResolver{r} }
+
 // Business returns BusinessResolver implementation.
 func (r *Resolver) Business() BusinessResolver { return &businessResolver{r} }
 
@@ -3655,6 +5093,9 @@ func (r *Resolver) CreditNoteDetailResponse() CreditNoteDetailResponseResolver {
 	return &creditNoteDetailResponseResolver{r}
 }
 
+// CurrencyExchange returns CurrencyExchangeResolver implementation.
+func (r *Resolver) CurrencyExchange() CurrencyExchangeResolver { return &currencyExchangeResolver{r} }
+
 // Customer returns CustomerResolver implementation.
 func (r *Resolver) Customer() CustomerResolver { return &customerResolver{r} }
 
@@ -3676,12 +5117,31 @@ func (r *Resolver) CustomerRefundHistory() CustomerRefundHistoryResolver {
 	return &customerRefundHistoryResolver{r}
 }
 
+// Estimate returns EstimateResolver implementation.
+func (r *Resolver) Estimate() EstimateResolver { return &estimateResolver{r} }
+
+// EstimateDetail returns EstimateDetailResolver implementation.
+func (r *Resolver) EstimateDetail() EstimateDetailResolver { return &estimateDetailResolver{r} }
+
 // Expense returns ExpenseResolver implementation.
 func (r *Resolver) Expense() ExpenseResolver { return &expenseResolver{r} }
 
 // ExpenseDetail returns ExpenseDetailResolver implementation.
 func (r *Resolver) ExpenseDetail() ExpenseDetailResolver { return &expenseDetailResolver{r} }
 
+// FixedAsset returns FixedAssetResolver implementation.
+func (r *Resolver) FixedAsset() FixedAssetResolver { return &fixedAssetResolver{r} }
+
+// FixedAssetCategory returns FixedAssetCategoryResolver implementation.
+func (r *Resolver) FixedAssetCategory() FixedAssetCategoryResolver {
+	return &fixedAssetCategoryResolver{r}
+}
+
+// FxRevaluationLine returns FxRevaluationLineResolver implementation.
+func (r *Resolver) FxRevaluationLine() FxRevaluationLineResolver {
+	return &fxRevaluationLineResolver{r}
+}
+
 // InventoryAdjustment returns InventoryAdjustmentResolver implementation.
 func (r *Resolver) InventoryAdjustment() InventoryAdjustmentResolver {
 	return &inventoryAdjustmentResolver{r}
@@ -3718,6 +5178,12 @@ func (r *Resolver) JournalTransaction() JournalTransactionResolver {
 	return &journalTransactionResolver{r}
 }
 
+// LandedCost returns LandedCostResolver implementation.
+func (r *Resolver) LandedCost() LandedCostResolver { return &landedCostResolver{r} }
+
+// LandedCostLine returns LandedCostLineResolver implementation.
+func (r *Resolver) LandedCostLine() LandedCostLineResolver { return &landedCostLineResolver{r} }
+
 // MoneyAccount returns MoneyAccountResolver implementation.
 func (r *Resolver) MoneyAccount() MoneyAccountResolver { return &moneyAccountResolver{r} }
 
@@ -3751,6 +5217,17 @@ func (r *Resolver) PaymentMade() PaymentMadeResolver { return &paymentMadeResolv
 // PaymentReceived returns PaymentReceivedResolver implementation.
 func (r *Resolver) PaymentReceived() PaymentReceivedResolver { return &paymentReceivedResolver{r} }
 
+// PaymentReminderRule returns PaymentReminderRuleResolver implementation.
+func (r *Resolver) PaymentReminderRule() PaymentReminderRuleResolver {
+	return &paymentReminderRuleResolver{r}
+}
+
+// PriceList returns PriceListResolver implementation.
+func (r *Resolver) PriceList() PriceListResolver { return &priceListResolver{r} }
+
+// PriceListItem returns PriceListItemResolver implementation.
+func (r *Resolver) PriceListItem() PriceListItemResolver { return &priceListItemResolver{r} }
+
 // Product returns ProductResolver implementation.
 func (r *Resolver) Product() ProductResolver { return &productResolver{r} }
 
@@ -3760,6 +5237,11 @@ func (r *Resolver) ProductCategory() ProductCategoryResolver { return &productCa
 // ProductGroup returns ProductGroupResolver implementation.
 func (r *Resolver) ProductGroup() ProductGroupResolver { return &productGroupResolver{r} }
 
+// ProductUnitConversion returns ProductUnitConversionResolver implementation.
+func (r *Resolver) ProductUnitConversion() ProductUnitConversionResolver {
+	return &productUnitConversionResolver{r}
+}
+
 // ProductVariant returns ProductVariantResolver implementation.
 func (r *Resolver) ProductVariant() ProductVariantResolver { return &productVariantResolver{r} }
 
@@ -3792,9 +5274,23 @@ func (r *Resolver) RecurringBillDetail() RecurringBillDetailResolver {
 	return &recurringBillDetailResolver{r}
 }
 
+// RecurringExpense returns RecurringExpenseResolver implementation.
+func (r *Resolver) RecurringExpense() RecurringExpenseResolver { return &recurringExpenseResolver{r} }
+
+// RecurringInvoice returns RecurringInvoiceResolver implementation.
+func (r *Resolver) RecurringInvoice() RecurringInvoiceResolver { return &recurringInvoiceResolver{r} }
+
+// RecurringInvoiceDetail returns RecurringInvoiceDetailResolver implementation.
+func (r *Resolver) RecurringInvoiceDetail() RecurringInvoiceDetailResolver {
+	return &recurringInvoiceDetailResolver{r}
+}
+
 // Refund returns RefundResolver implementation.
 func (r *Resolver) Refund() RefundResolver { return &refundResolver{r} }
 
+// ReorderPoint returns ReorderPointResolver implementation.
+func (r *Resolver) ReorderPoint() ReorderPointResolver { return &reorderPointResolver{r} }
+
 // Role returns RoleResolver implementation.
 func (r *Resolver) Role() RoleResolver { return &roleResolver{r} }
 
@@ -3820,12 +5316,26 @@ func (r *Resolver) SalesOrder() SalesOrderResolver { return &salesOrderResolver{
 // SalesOrderDetail returns SalesOrderDetailResolver implementation.
 func (r *Resolver) SalesOrderDetail() SalesOrderDetailResolver { return &salesOrderDetailResolver{r} }
 
+// SerialNumber returns SerialNumberResolver implementation.
+func (r *Resolver) SerialNumber() SerialNumberResolver { return &serialNumberResolver{r} }
+
+// SerialNumberMovement returns SerialNumberMovementResolver implementation.
+func (r *Resolver) SerialNumberMovement() SerialNumberMovementResolver {
+	return &serialNumberMovementResolver{r}
+}
+
 // ShippingAddress returns ShippingAddressResolver implementation.
 func (r *Resolver) ShippingAddress() ShippingAddressResolver { return &shippingAddressResolver{r} }
 
 // StockSummary returns StockSummaryResolver implementation.
 func (r *Resolver) StockSummary() StockSummaryResolver { return &stockSummaryResolver{r} }
 
+// Stocktake returns StocktakeResolver implementation.
+func (r *Resolver) Stocktake() StocktakeResolver { return &stocktakeResolver{r} }
+
+// StocktakeLine returns StocktakeLineResolver implementation.
+func (r *Resolver) StocktakeLine() StocktakeLineResolver { return &stocktakeLineResolver{r} }
+
 // Supplier returns SupplierResolver implementation.
 func (r *Resolver) Supplier() SupplierResolver { return &supplierResolver{r} }
 
@@ -3869,6 +5379,11 @@ func (r *Resolver) TaxGroup() TaxGroupResolver { return &taxGroupResolver{r} }
 // TransferOrder returns TransferOrderResolver implementation.
 func (r *Resolver) TransferOrder() TransferOrderResolver { return &transferOrderResolver{r} }
 
+// TransferOrderDetail returns TransferOrderDetailResolver implementation.
+func (r *Resolver) TransferOrderDetail() TransferOrderDetailResolver {
+	return &transferOrderDetailResolver{r}
+}
+
 // UserAccount returns UserAccountResolver implementation.
 func (r *Resolver) UserAccount() UserAccountResolver { return &userAccountResolver{r} }
 
@@ -3880,6 +5395,15 @@ func (r *Resolver) WarehouseInventoryResponse() WarehouseInventoryResponseResolv
 	return &warehouseInventoryResponseResolver{r}
 }
 
+// WebhookDelivery returns WebhookDeliveryResolver implementation.
+func (r *Resolver) WebhookDelivery() WebhookDeliveryResolver { return &webhookDeliveryResolver{r} }
+
+// WebhookEndpoint returns WebhookEndpointResolver implementation.
+func (r *Resolver) WebhookEndpoint() WebhookEndpointResolver { return &webhookEndpointResolver{r} }
+
+// WithholdingTax returns WithholdingTaxResolver implementation.
+func (r *Resolver) WithholdingTax() WithholdingTaxResolver { return &withholdingTaxResolver{r} }
+
 type accountResolver struct{ *Resolver }
 type accountCurrencyDailyBalanceResolver struct{ *Resolver }
 type accountJournalResolver struct{ *Resolver }
@@ -3888,25 +5412,39 @@ type accountTransactionResolver struct{ *Resolver }
 type allAccountResolver struct{ *Resolver }
 type allProductResolver struct{ *Resolver }
 type allProductVariantResolver struct{ *Resolver }
+type assemblyOrderResolver struct{ *Resolver }
+type assemblyOrderDetailResolver struct{ *Resolver }
+type bankStatementResolver struct{ *Resolver }
+type bankStatementLineResolver struct{ *Resolver }
 type bankingAccountResolver struct{ *Resolver }
 type bankingTransactionResolver struct{ *Resolver }
 type billResolver struct{ *Resolver }
 type billDetailResolver struct{ *Resolver }
+type billOfMaterialsResolver struct{ *Resolver }
+type billOfMaterialsComponentResolver struct{ *Resolver }
 type billPaymentResolver struct{ *Resolver }
 type billingAddressResolver struct{ *Resolver }
 type billsConnectionResolver struct{ *Resolver }
 type branchResolver struct{ *Resolver }
+type budgetResolver struct{ *Resolver }
+type budgetAccountResolver struct{ *Resolver }
 type businessResolver struct{ *Resolver }
 type creditNoteResolver struct{ *Resolver }
 type creditNoteDetailResolver struct{ *Resolver }
 type creditNoteDetailResponseResolver struct{ *Resolver }
+type currencyExchangeResolver struct{ *Resolver }
 type customerResolver struct{ *Resolver }
 type customerCreditAdvanceResolver struct{ *Resolver }
 type customerCreditInvoiceResolver struct{ *Resolver }
 type customerPaymentResolver struct{ *Resolver }
 type customerRefundHistoryResolver struct{ *Resolver }
+type estimateResolver struct{ *Resolver }
+type estimateDetailResolver struct{ *Resolver }
 type expenseResolver struct{ *Resolver }
 type expenseDetailResolver struct{ *Resolver }
+type fixedAssetResolver struct{ *Resolver }
+type fixedAssetCategoryResolver struct{ *Resolver }
+type fxRevaluationLineResolver struct{ *Resolver }
 type inventoryAdjustmentResolver struct{ *Resolver }
 type inventoryAdjustmentDetailResolver struct{ *Resolver }
 type inventorySummaryResponseResolver struct{ *Resolver }
@@ -3915,6 +5453,8 @@ type inventoryValuationSummaryResponseResolver struct{ *Resolver }
 type invoicePaymentResolver struct{ *Resolver }
 type journalResolver struct{ *Resolver }
 type journalTransactionResolver struct{ *Resolver }
+type landedCostResolver struct{ *Resolver }
+type landedCostLineResolver struct{ *Resolver }
 type moneyAccountResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
 type openingBalanceResolver struct{ *Resolver }
@@ -3924,9 +5464,13 @@ type payableDetailResponseResolver struct{ *Resolver }
 type payableSummaryResponseResolver struct{ *Resolver }
 type paymentMadeResolver struct{ *Resolver }
 type paymentReceivedResolver struct{ *Resolver }
+type paymentReminderRuleResolver struct{ *Resolver }
+type priceListResolver struct{ *Resolver }
+type priceListItemResolver struct{ *Resolver }
 type productResolver struct{ *Resolver }
 type productCategoryResolver struct{ *Resolver }
 type productGroupResolver struct{ *Resolver }
+type productUnitConversionResolver struct{ *Resolver }
 type productVariantResolver struct{ *Resolver }
 type purchaseOrderResolver struct{ *Resolver }
 type purchaseOrderDetailResolver struct{ *Resolver }
@@ -3935,7 +5479,11 @@ type receivableDetailResponseResolver struct{ *Resolver }
 type receivableSummaryResponseResolver struct{ *Resolver }
 type recurringBillResolver struct{ *Resolver }
 type recurringBillDetailResolver struct{ *Resolver }
+type recurringExpenseResolver struct{ *Resolver }
+type recurringInvoiceResolver struct{ *Resolver }
+type recurringInvoiceDetailResolver struct{ *Resolver }
 type refundResolver struct{ *Resolver }
+type reorderPointResolver struct{ *Resolver }
 type roleResolver struct{ *Resolver }
 type roleModuleResolver struct{ *Resolver }
 type salesInvoiceResolver struct{ *Resolver }
@@ -3943,8 +5491,12 @@ type salesInvoiceDetailResolver struct{ *Resolver }
 type salesInvoicesConnectionResolver struct{ *Resolver }
 type salesOrderResolver struct{ *Resolver }
 type salesOrderDetailResolver struct{ *Resolver }
+type serialNumberResolver struct{ *Resolver }
+type serialNumberMovementResolver struct{ *Resolver }
 type shippingAddressResolver struct{ *Resolver }
 type stockSummaryResolver struct{ *Resolver }
+type stocktakeResolver struct{ *Resolver }
+type stocktakeLineResolver struct{ *Resolver }
 type supplierResolver struct{ *Resolver }
 type supplierCreditResolver struct{ *Resolver }
 type supplierCreditAdvanceResolver struct{ *Resolver }
@@ -3956,6 +5508,10 @@ type supplierPaymentResolver struct{ *Resolver }
 type supplierRefundHistoryResolver struct{ *Resolver }
 type taxGroupResolver struct{ *Resolver }
 type transferOrderResolver struct{ *Resolver }
+type transferOrderDetailResolver struct{ *Resolver }
 type userAccountResolver struct{ *Resolver }
 type warehouseResolver struct{ *Resolver }
 type warehouseInventoryResponseResolver struct{ *Resolver }
+type webhookDeliveryResolver struct{ *Resolver }
+type webhookEndpointResolver struct{ *Resolver }
+type withholdingTaxResolver struct{ *Resolver }
@@ -0,0 +1,77 @@
+package mailer_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mmdatafocus/books_backend/mailer"
+)
+
+func testMessage() *mailer.Message {
+	return &mailer.Message{
+		FromName:  "Acme Trading",
+		FromEmail: "billing@acme.test",
+		To:        []string{"customer@example.test"},
+		Bcc:       []string{"archive@acme.test"},
+		Subject:   "Invoice INV-0001\r\nBcc: attacker@example.test",
+		TextBody:  "Please find attached.",
+		Attachments: []mailer.Attachment{
+			{FileName: "INV-0001.pdf", ContentType: "application/pdf", Data: []byte("%PDF-1.4")},
+		},
+	}
+}
+
+func TestMessageBytes(t *testing.T) {
+	msg := testMessage()
+	data, err := msg.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+	for _, want := range []string{
+		"From: \"Acme Trading\" <billing@acme.test>\r\n",
+		"To: customer@example.test\r\n",
+		"Content-Type: multipart/mixed;",
+		"Content-Type: application/pdf; name=\"INV-0001.pdf\"",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("message missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "archive@acme.test") {
+		t.Fatal("bcc recipient must not appear in headers")
+	}
+	if strings.Contains(out, "\r\nBcc:") {
+		t.Fatal("subject line breaks must not start a new header")
+	}
+	if got := msg.Recipients(); len(got) != 2 {
+		t.Fatalf("recipients: got %v", got)
+	}
+
+	msg.To = nil
+	if _, err := msg.Bytes(); err == nil {
+		t.Fatal("expected an error without recipients")
+	}
+}
+
+func TestFileTransport(t *testing.T) {
+	dir := t.TempDir()
+	transport := &mailer.FileTransport{Dir: dir}
+	if err := transport.Send(context.Background(), testMessage()); err != nil {
+		t.Fatal(err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one .eml file, got %v (%v)", files, err)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "To: customer@example.test") {
+		t.Fatal("written message has no To header")
+	}
+}
@@ -0,0 +1,140 @@
+package mailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+type Message struct {
+	FromName    string
+	FromEmail   string
+	ReplyTo     string
+	To          []string
+	Cc          []string
+	Bcc         []string
+	Subject     string
+	TextBody    string
+	Attachments []Attachment
+}
+
+type Attachment struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
+// Recipients returns every envelope recipient, including Bcc.
+func (m *Message) Recipients() []string {
+	all := make([]string, 0, len(m.To)+len(m.Cc)+len(m.Bcc))
+	all = append(all, m.To...)
+	all = append(all, m.Cc...)
+	return append(all, m.Bcc...)
+}
+
+func (m *Message) Validate() error {
+	if strings.TrimSpace(m.FromEmail) == "" {
+		return errors.New("sender email is required")
+	}
+	if len(m.To) == 0 {
+		return errors.New("at least one recipient is required")
+	}
+	for _, addr := range append([]string{m.FromEmail}, m.Recipients()...) {
+		if _, err := mail.ParseAddress(addr); err != nil {
+			return fmt.Errorf("invalid email address %q", addr)
+		}
+	}
+	return nil
+}
+
+// Bytes encodes the message as RFC 5322 with a multipart/mixed body when there are attachments.
+// Bcc recipients are not written to the headers.
+func (m *Message) Bytes() ([]byte, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	from := mail.Address{Name: m.FromName, Address: m.FromEmail}
+	writeHeader(&buf, "From", from.String())
+	writeHeader(&buf, "To", strings.Join(m.To, ", "))
+	if len(m.Cc) > 0 {
+		writeHeader(&buf, "Cc", strings.Join(m.Cc, ", "))
+	}
+	if m.ReplyTo != "" {
+		writeHeader(&buf, "Reply-To", m.ReplyTo)
+	}
+	writeHeader(&buf, "Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	writeHeader(&buf, "Date", time.Now().Format(time.RFC1123Z))
+	writeHeader(&buf, "Message-ID", fmt.Sprintf("<%s@%s>", randomToken(), domainOf(m.FromEmail)))
+	writeHeader(&buf, "MIME-Version", "1.0")
+
+	if len(m.Attachments) == 0 {
+		writeHeader(&buf, "Content-Type", "text/plain; charset=utf-8")
+		writeHeader(&buf, "Content-Transfer-Encoding", "base64")
+		buf.WriteString("\r\n")
+		writeBase64(&buf, []byte(m.TextBody))
+		return buf.Bytes(), nil
+	}
+
+	boundary := "b_" + randomToken()
+	writeHeader(&buf, "Content-Type", fmt.Sprintf("multipart/mixed; boundary=%q", boundary))
+	buf.WriteString("\r\n")
+
+	buf.WriteString("--" + boundary + "\r\n")
+	writeHeader(&buf, "Content-Type", "text/plain; charset=utf-8")
+	writeHeader(&buf, "Content-Transfer-Encoding", "base64")
+	buf.WriteString("\r\n")
+	writeBase64(&buf, []byte(m.TextBody))
+
+	for _, a := range m.Attachments {
+		contentType := a.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		name := mime.QEncoding.Encode("utf-8", a.FileName)
+		buf.WriteString("--" + boundary + "\r\n")
+		writeHeader(&buf, "Content-Type", fmt.Sprintf("%s; name=%q", contentType, name))
+		writeHeader(&buf, "Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+		writeHeader(&buf, "Content-Transfer-Encoding", "base64")
+		buf.WriteString("\r\n")
+		writeBase64(&buf, a.Data)
+	}
+	buf.WriteString("--" + boundary + "--\r\n")
+	return buf.Bytes(), nil
+}
+
+func writeHeader(buf *bytes.Buffer, key string, value string) {
+	// strip CR/LF so user supplied values cannot inject headers
+	value = strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+	buf.WriteString(key + ": " + value + "\r\n")
+}
+
+// writeBase64 writes data base64 encoded in 76 character lines.
+func writeBase64(buf *bytes.Buffer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+}
+
+func randomToken() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func domainOf(addr string) string {
+	if i := strings.LastIndexByte(addr, '@'); i >= 0 {
+		return addr[i+1:]
+	}
+	return "localhost"
+}
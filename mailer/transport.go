@@ -0,0 +1,189 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	TransportSMTP = "smtp"
+	TransportFile = "file"
+	TransportLog  = "log"
+)
+
+// Transport delivers an encoded message. Implementations must be safe for concurrent use.
+type Transport interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// DefaultFromEmail is the envelope sender used when a business has not configured one.
+func DefaultFromEmail() string {
+	return strings.TrimSpace(os.Getenv("MAIL_FROM"))
+}
+
+// NewTransportFromEnv picks the transport from MAIL_TRANSPORT:
+//   - smtp: SMTP_HOST, SMTP_PORT (587), SMTP_USERNAME, SMTP_PASSWORD
+//   - file: writes .eml files to MAIL_FILE_DIR (default os.TempDir()/mail)
+//   - log (default): logs the envelope only, nothing leaves the process
+func NewTransportFromEnv(logger *logrus.Logger) (Transport, error) {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("MAIL_TRANSPORT"))) {
+	case TransportSMTP:
+		host := strings.TrimSpace(os.Getenv("SMTP_HOST"))
+		if host == "" {
+			return nil, fmt.Errorf("SMTP_HOST is required for the smtp mail transport")
+		}
+		port := 587
+		if v := strings.TrimSpace(os.Getenv("SMTP_PORT")); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid SMTP_PORT %q", v)
+			}
+			port = n
+		}
+		return &SMTPTransport{
+			Host:     host,
+			Port:     port,
+			Username: strings.TrimSpace(os.Getenv("SMTP_USERNAME")),
+			Password: os.Getenv("SMTP_PASSWORD"),
+		}, nil
+	case TransportFile:
+		dir := strings.TrimSpace(os.Getenv("MAIL_FILE_DIR"))
+		if dir == "" {
+			dir = filepath.Join(os.TempDir(), "mail")
+		}
+		return &FileTransport{Dir: dir}, nil
+	case TransportLog, "":
+		return &LogTransport{Logger: logger}, nil
+	default:
+		return nil, fmt.Errorf("unknown MAIL_TRANSPORT %q", os.Getenv("MAIL_TRANSPORT"))
+	}
+}
+
+// SMTPTransport sends through an SMTP relay. Port 465 uses implicit TLS; other ports
+// upgrade with STARTTLS when the server offers it.
+type SMTPTransport struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	Timeout  time.Duration
+}
+
+func (t *SMTPTransport) Send(ctx context.Context, msg *Message) error {
+	data, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+	timeout := t.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	addr := net.JoinHostPort(t.Host, strconv.Itoa(t.Port))
+	dialer := &net.Dialer{Timeout: timeout}
+
+	var conn net.Conn
+	if t.Port == 465 {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: t.Host})
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	client, err := smtp.NewClient(conn, t.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if t.Port != 465 {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: t.Host}); err != nil {
+				return err
+			}
+		}
+	}
+	if t.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", t.Username, t.Password, t.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(msg.FromEmail); err != nil {
+		return err
+	}
+	for _, rcpt := range msg.Recipients() {
+		if err := client.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// FileTransport writes each message as an .eml file, for local development and tests.
+type FileTransport struct {
+	Dir string
+
+	mu  sync.Mutex
+	seq int
+}
+
+func (t *FileTransport) Send(ctx context.Context, msg *Message) error {
+	data, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(t.Dir, 0o755); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	t.seq++
+	name := fmt.Sprintf("%s-%04d.eml", time.Now().UTC().Format("20060102T150405"), t.seq)
+	t.mu.Unlock()
+	return os.WriteFile(filepath.Join(t.Dir, name), data, 0o644)
+}
+
+// LogTransport only logs the envelope. It is the default so that environments without
+// mail settings never deliver to real customers by accident.
+type LogTransport struct {
+	Logger *logrus.Logger
+}
+
+func (t *LogTransport) Send(ctx context.Context, msg *Message) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+	if t.Logger != nil {
+		t.Logger.WithFields(logrus.Fields{
+			"field":       "Mailer",
+			"from":        msg.FromEmail,
+			"to":          strings.Join(msg.Recipients(), ","),
+			"subject":     msg.Subject,
+			"attachments": len(msg.Attachments),
+		}).Info("mail transport is log only; message not delivered")
+	}
+	return nil
+}
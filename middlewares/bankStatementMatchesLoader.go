@@ -0,0 +1,28 @@
+package middlewares
+
+import (
+	"context"
+
+	"github.com/graph-gophers/dataloader/v7"
+	"github.com/mmdatafocus/books_backend/models"
+	"gorm.io/gorm"
+)
+
+type bankStatementMatchReader struct {
+	db *gorm.DB
+}
+
+func (r *bankStatementMatchReader) GetBankStatementMatches(ctx context.Context, Ids []int) []*dataloader.Result[[]*models.BankStatementMatch] {
+	var results []models.BankStatementMatch
+	err := r.db.WithContext(ctx).Where("bank_statement_line_id IN ?", Ids).Find(&results).Error
+	if err != nil {
+		return handleError[[]*models.BankStatementMatch](len(Ids), err)
+	}
+
+	return generateLoaderArrayResults(results, Ids)
+}
+
+func GetBankStatementMatches(ctx context.Context, bankStatementLineId int) ([]*models.BankStatementMatch, error) {
+	loaders := For(ctx)
+	return loaders.bankStatementMatchLoader.Load(ctx, bankStatementLineId)()
+}
@@ -113,3 +113,8 @@ func GetCreditNoteDocuments(ctx context.Context, creditNoteId int) ([]*models.Do
 	loaders := For(ctx)
 	return loaders.creditNoteDocumentLoader.Load(ctx, creditNoteId)()
 }
+
+func GetEstimateDocuments(ctx context.Context, estimateId int) ([]*models.Document, error) {
+	loaders := For(ctx)
+	return loaders.estimateDocumentLoader.Load(ctx, estimateId)()
+}
@@ -0,0 +1,28 @@
+package middlewares
+
+import (
+	"context"
+
+	"github.com/graph-gophers/dataloader/v7"
+	"github.com/mmdatafocus/books_backend/models"
+	"gorm.io/gorm"
+)
+
+type estimateDetailReader struct {
+	db *gorm.DB
+}
+
+func (r *estimateDetailReader) GetEstimateDetails(ctx context.Context, Ids []int) []*dataloader.Result[[]*models.EstimateDetail] {
+	var results []models.EstimateDetail
+	err := r.db.WithContext(ctx).Where("estimate_id IN ?", Ids).Find(&results).Error
+	if err != nil {
+		return handleError[[]*models.EstimateDetail](len(Ids), err)
+	}
+
+	return generateLoaderArrayResults(results, Ids)
+}
+
+func GetEstimateDetails(ctx context.Context, estimateId int) ([]*models.EstimateDetail, error) {
+	loaders := For(ctx)
+	return loaders.estimateDetailLoader.Load(ctx, estimateId)()
+}
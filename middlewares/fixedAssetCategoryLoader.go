@@ -0,0 +1,27 @@
+package middlewares
+
+import (
+	"context"
+
+	"github.com/graph-gophers/dataloader/v7"
+	"github.com/mmdatafocus/books_backend/models"
+	"gorm.io/gorm"
+)
+
+type fixedAssetCategoryReader struct {
+	db *gorm.DB
+}
+
+func (r *fixedAssetCategoryReader) getFixedAssetCategories(ctx context.Context, ids []int) []*dataloader.Result[*models.FixedAssetCategory] {
+	var results []models.FixedAssetCategory
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&results).Error
+	if err != nil {
+		return handleError[*models.FixedAssetCategory](len(ids), err)
+	}
+	return generateLoaderResults(results, ids)
+}
+
+func GetFixedAssetCategory(ctx context.Context, id int) (*models.FixedAssetCategory, error) {
+	loaders := For(ctx)
+	return loaders.fixedAssetCategoryLoader.Load(ctx, id)()
+}
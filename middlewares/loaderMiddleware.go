@@ -77,6 +77,11 @@ type Loaders struct {
 	supplierPaymentDocumentLoader *dataloader.Loader[int, []*models.Document]
 
 	recurringBillDetailLoader    *dataloader.Loader[int, []*models.RecurringBillDetail]
+	recurringInvoiceDetailLoader *dataloader.Loader[int, []*models.RecurringInvoiceDetail]
+	bankStatementMatchLoader     *dataloader.Loader[int, []*models.BankStatementMatch]
+	fixedAssetCategoryLoader     *dataloader.Loader[int, *models.FixedAssetCategory]
+	estimateDetailLoader         *dataloader.Loader[int, []*models.EstimateDetail]
+	estimateDocumentLoader       *dataloader.Loader[int, []*models.Document]
 	supplierCreditDetailLoader   *dataloader.Loader[int, []*models.SupplierCreditDetail]
 	supplierCreditDocumentLoader *dataloader.Loader[int, []*models.Document]
 
@@ -212,6 +217,11 @@ func NewLoaders(conn *gorm.DB) *Loaders {
 	supplierPaidBillReader := &supplierPaidBillReader{db: conn}
 
 	recurringBillDetailReader := &recurringBillDetailReader{db: conn}
+	recurringInvoiceDetailReader := &recurringInvoiceDetailReader{db: conn}
+	bankStatementMatchReader := &bankStatementMatchReader{db: conn}
+	fixedAssetCategoryReader := &fixedAssetCategoryReader{db: conn}
+	estimateDetailReader := &estimateDetailReader{db: conn}
+	estimateDocumentReader := &documentReader{db: conn, referenceType: "estimates"}
 	supplierCreditDetailReader := &supplierCreditDetailReader{db: conn}
 
 	creditNoteDetailsReader := &creditNoteDetailsReader{db: conn}
@@ -335,6 +345,11 @@ func NewLoaders(conn *gorm.DB) *Loaders {
 		supplierPaymentDocumentLoader: dataloader.NewBatchedLoader(supplierPaymentDocumentReader.GetDocuments, dataloader.WithWait[int, []*models.Document](time.Millisecond)),
 
 		recurringBillDetailLoader:    dataloader.NewBatchedLoader(recurringBillDetailReader.GetRecurringBillDetails, dataloader.WithWait[int, []*models.RecurringBillDetail](time.Millisecond)),
+		recurringInvoiceDetailLoader: dataloader.NewBatchedLoader(recurringInvoiceDetailReader.GetRecurringInvoiceDetails, dataloader.WithWait[int, []*models.RecurringInvoiceDetail](time.Millisecond)),
+		bankStatementMatchLoader:     dataloader.NewBatchedLoader(bankStatementMatchReader.GetBankStatementMatches, dataloader.WithWait[int, []*models.BankStatementMatch](time.Millisecond)),
+		fixedAssetCategoryLoader:     dataloader.NewBatchedLoader(fixedAssetCategoryReader.getFixedAssetCategories, dataloader.WithWait[int, *models.FixedAssetCategory](time.Millisecond)),
+		estimateDetailLoader:         dataloader.NewBatchedLoader(estimateDetailReader.GetEstimateDetails, dataloader.WithWait[int, []*models.EstimateDetail](time.Millisecond)),
+		estimateDocumentLoader:       dataloader.NewBatchedLoader(estimateDocumentReader.GetDocuments, dataloader.WithWait[int, []*models.Document](time.Millisecond)),
 		supplierCreditDetailLoader:   dataloader.NewBatchedLoader(supplierCreditDetailReader.GetSupplierCreditDetails, dataloader.WithWait[int, []*models.SupplierCreditDetail](time.Millisecond)),
 		supplierCreditDocumentLoader: dataloader.NewBatchedLoader(supplierCreditDocumentReader.GetDocuments, dataloader.WithWait[int, []*models.Document](time.Millisecond)),
 
@@ -0,0 +1,28 @@
+package middlewares
+
+import (
+	"context"
+
+	"github.com/graph-gophers/dataloader/v7"
+	"github.com/mmdatafocus/books_backend/models"
+	"gorm.io/gorm"
+)
+
+type recurringInvoiceDetailReader struct {
+	db *gorm.DB
+}
+
+func (r *recurringInvoiceDetailReader) GetRecurringInvoiceDetails(ctx context.Context, Ids []int) []*dataloader.Result[[]*models.RecurringInvoiceDetail] {
+	var results []models.RecurringInvoiceDetail
+	err := r.db.WithContext(ctx).Where("recurring_invoice_id IN ?", Ids).Find(&results).Error
+	if err != nil {
+		return handleError[[]*models.RecurringInvoiceDetail](len(Ids), err)
+	}
+
+	return generateLoaderArrayResults(results, Ids)
+}
+
+func GetRecurringInvoiceDetails(ctx context.Context, recurringInvoiceId int) ([]*models.RecurringInvoiceDetail, error) {
+	loaders := For(ctx)
+	return loaders.recurringInvoiceDetailLoader.Load(ctx, recurringInvoiceId)()
+}
@@ -58,6 +58,7 @@ type NewPosCheckout struct {
 	Notes                         string                  `json:"notes"`
 	TermsAndConditions            string                  `json:"terms_and_conditions"`
 	CurrencyId                    int                     `json:"currency_id" binding:"required"`
+	PriceListId                   int                     `json:"price_list_id"`
 	ExchangeRate                  decimal.Decimal         `json:"exchange_rate"`
 	WarehouseId                   int                     `json:"warehouse_id" binding:"required"`
 	InvoiceDiscount               decimal.Decimal         `json:"invoice_discount"`
@@ -117,6 +118,25 @@ func (input NewPosCheckout) validate(ctx context.Context, businessId string, _ i
 	return nil
 }
 
+// priceLines prices lines that ask for it from the customer's price list and
+// converts lines sold in another unit into the base unit.
+func (input *NewPosCheckout) priceLines(ctx context.Context, businessId string) error {
+	list, err := documentPriceList(ctx, businessId, PriceListTypeSales, &input.PriceListId, input.CustomerId, input.CurrencyId, input.InvoiceDate)
+	if err != nil {
+		return err
+	}
+	for i := range input.Details {
+		d := &input.Details[i]
+		if err := list.fillRate(ctx, businessId, d.ProductType, d.ProductId, d.UnitId, d.DetailQty, d.UsePriceListRate, &d.DetailUnitRate); err != nil {
+			return fmt.Errorf("%s: %w", d.Name, err)
+		}
+		if err := d.LineUnit.toBaseUnit(ctx, businessId, d.ProductType, d.ProductId, &d.DetailQty, &d.DetailUnitRate); err != nil {
+			return fmt.Errorf("%s: %w", d.Name, err)
+		}
+	}
+	return nil
+}
+
 func CreatePosInvoicePayment(ctx context.Context, input *NewPosCheckout) (string, error) {
 	db := config.GetDB()
 
@@ -130,6 +150,9 @@ func CreatePosInvoicePayment(ctx context.Context, input *NewPosCheckout) (string
 		return "", err
 	}
 
+	if err := input.priceLines(ctx, businessId); err != nil {
+		return "", err
+	}
 	// validate SalesInvoice
 	if err := input.validate(ctx, businessId, 0); err != nil {
 		return "", err
@@ -153,6 +176,7 @@ func CreatePosInvoicePayment(ctx context.Context, input *NewPosCheckout) (string
 			Description:        item.Description,
 			DetailQty:          item.DetailQty,
 			DetailUnitRate:     item.DetailUnitRate,
+			LineUnit:           item.LineUnit,
 			DetailTaxId:        item.DetailTaxId,
 			DetailTaxType:      item.DetailTaxType,
 			DetailDiscount:     item.DetailDiscount,
@@ -224,6 +248,7 @@ func CreatePosInvoicePayment(ctx context.Context, input *NewPosCheckout) (string
 		Notes:                         input.Notes,
 		TermsAndConditions:            input.TermsAndConditions,
 		CurrencyId:                    input.CurrencyId,
+		PriceListId:                   input.PriceListId,
 		ExchangeRate:                  input.ExchangeRate,
 		WarehouseId:                   input.WarehouseId,
 		InvoiceDiscount:               input.InvoiceDiscount,
@@ -90,6 +90,11 @@ func (input *NewAccount) validate(ctx context.Context, businessId string, id int
 			return errors.New("parent not found")
 		}
 	}
+	branches, err := validateBranches(ctx, businessId, input.Branches)
+	if err != nil {
+		return err
+	}
+	input.Branches = branches
 	return nil
 }
 
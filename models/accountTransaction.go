@@ -22,7 +22,7 @@ type PubSubMessageRecord struct {
 	BusinessId          string               `gorm:"size:64;not null;index;index:idx_outbox_reconcile,priority:1" json:"business_id"`
 	TransactionDateTime time.Time            `gorm:"index;not null" json:"transaction_date_time"`
 	ReferenceId         int                  `json:"reference_id"`
-	ReferenceType       AccountReferenceType `gorm:"type:enum('JN','IV','CP','CN','CNA','CNR','EP','ER','BL','SP','POS', 'PVOS','IVAQ','IVAV','IWO','ACP','ASP','COB','SOB','OB','AC','AD','SCR','OI','TO','SC','SCA','OD','OC','SAA','SAR','CAA','CAR','PGOS','POSIVP')" json:"reference_type"`
+	ReferenceType       AccountReferenceType `gorm:"type:enum('JN','IV','CP','CN','CNA','CNR','EP','ER','BL','SP','POS', 'PVOS','IVAQ','IVAV','IWO','ACP','ASP','COB','SOB','OB','AC','AD','SCR','OI','TO','SC','SCA','OD','OC','SAA','SAR','CAA','CAR','PGOS','POSIVP','FAD','FADS','TXR','FXR','AO','TOR','LC')" json:"reference_type"`
 	Action              PubSubMessageAction  `gorm:"type:enum('C','U','D')" json:"action"`
 	OldObj              []byte               `gorm:"type:blob" json:"old_obj"`
 	NewObj              []byte               `gorm:"type:blob" json:"new_obj"`
@@ -72,7 +72,7 @@ type AccountJournal struct {
 	CustomerId          int                  `gorm:"index" json:"customer_id"`
 	SupplierId          int                  `gorm:"index" json:"supplier_id"`
 	ReferenceId         int                  `gorm:"index:idx_aj_biz_ref,priority:3" json:"reference_id"`
-	ReferenceType       AccountReferenceType `gorm:"type:enum('JN','IV','CP','CN','CNA','CNR','EP','ER','BL','SP','POS', 'PVOS','IVAQ','IVAV','IWO','ACP','ASP','COB','SOB','OB','AC','AD','SCR','OI','TO','SC','SCA','OD','OC','SAA','SAR','CAA','CAR','PGOS','POSIVP');index:idx_aj_biz_ref,priority:2" json:"reference_type"`
+	ReferenceType       AccountReferenceType `gorm:"type:enum('JN','IV','CP','CN','CNA','CNR','EP','ER','BL','SP','POS', 'PVOS','IVAQ','IVAV','IWO','ACP','ASP','COB','SOB','OB','AC','AD','SCR','OI','TO','SC','SCA','OD','OC','SAA','SAR','CAA','CAR','PGOS','POSIVP','FAD','FADS','TXR','FXR','AO','TOR','LC');index:idx_aj_biz_ref,priority:2" json:"reference_type"`
 	// Composite indexes (Phase A):
 	// - idx_aj_biz_ref:  (business_id, reference_type, reference_id)
 	// - idx_aj_biz_date: (business_id, transaction_date_time)
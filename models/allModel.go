@@ -44,13 +44,20 @@ func MapAllModel[ModelT any, AllT Identifier](ctx context.Context) (map[int]*All
 
 	// retrieve from redis
 	key := utils.GetTypeName[AllT]() + "Map:" + businessId
+	// branch restricted users skip the cache, as GetResource
+	_, restricted := utils.GetBranchIdsFromContext(ctx)
 
 	var allMap map[int]*AllT
+	exists := false
 
 	// retrieve from redis
-	if exists, err := config.GetRedisObject(key, &allMap); err != nil {
-		return nil, err
-	} else if !exists {
+	if !restricted {
+		var err error
+		if exists, err = config.GetRedisObject(key, &allMap); err != nil {
+			return nil, err
+		}
+	}
+	if !exists {
 		// if the map has not been cached yet
 		// fetch resources and constrcut the map, cache the result
 
@@ -69,6 +76,9 @@ func MapAllModel[ModelT any, AllT Identifier](ctx context.Context) (map[int]*All
 			allMap[(*allModel).GetId()] = allModel
 		}
 
+		if restricted {
+			return allMap, nil
+		}
 		// store redis
 		var duration time.Duration
 		// if utils.typeHasExpiration(typeName) {
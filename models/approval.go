@@ -0,0 +1,859 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ApprovalDocumentType string
+
+const (
+	ApprovalDocumentTypeBill                ApprovalDocumentType = "BILL"
+	ApprovalDocumentTypeExpense             ApprovalDocumentType = "EXPENSE"
+	ApprovalDocumentTypeManualJournal       ApprovalDocumentType = "MANUAL_JOURNAL"
+	ApprovalDocumentTypeSupplierPayment     ApprovalDocumentType = "SUPPLIER_PAYMENT"
+	ApprovalDocumentTypeInventoryAdjustment ApprovalDocumentType = "INVENTORY_ADJUSTMENT"
+)
+
+func (t ApprovalDocumentType) IsValid() bool {
+	_, ok := approvalDocumentTables[t]
+	return ok
+}
+
+// approvalDocumentTables is the reference type comments and history of each document use.
+var approvalDocumentTables = map[ApprovalDocumentType]string{
+	ApprovalDocumentTypeBill:                "bills",
+	ApprovalDocumentTypeExpense:             "expenses",
+	ApprovalDocumentTypeManualJournal:       "journals",
+	ApprovalDocumentTypeSupplierPayment:     "supplier_payments",
+	ApprovalDocumentTypeInventoryAdjustment: "inventory_adjustments",
+}
+
+type ApprovalStatus string
+
+const (
+	ApprovalStatusPending   ApprovalStatus = "PENDING"
+	ApprovalStatusApproved  ApprovalStatus = "APPROVED"
+	ApprovalStatusRejected  ApprovalStatus = "REJECTED"
+	ApprovalStatusCancelled ApprovalStatus = "CANCELLED"
+)
+
+// approvalPosted reports whether a document with this approval status is on the books.
+// Documents no policy applied to have no approval status.
+func approvalPosted(status *ApprovalStatus) bool {
+	return status == nil || *status == ApprovalStatusApproved
+}
+
+// ApprovalPolicy holds documents of DocumentType whose base currency amount is at least
+// MinAmount until every level approves them, in order. When several active policies
+// cover an amount, the one with the highest MinAmount applies.
+type ApprovalPolicy struct {
+	ID           int                   `gorm:"primary_key" json:"id"`
+	BusinessId   string                `gorm:"index;not null" json:"business_id" binding:"required"`
+	Name         string                `gorm:"size:100;not null" json:"name" binding:"required"`
+	DocumentType ApprovalDocumentType  `gorm:"size:30;not null" json:"document_type"`
+	MinAmount    decimal.Decimal       `gorm:"type:decimal(20,4);default:0" json:"min_amount"`
+	IsActive     *bool                 `gorm:"not null;default:true" json:"is_active"`
+	Levels       []ApprovalPolicyLevel `gorm:"foreignKey:PolicyId" json:"levels"`
+	CreatedAt    time.Time             `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time             `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// ApprovalPolicyLevel is one step of a policy's chain; users of RoleId (and owners) approve it.
+type ApprovalPolicyLevel struct {
+	ID       int `gorm:"primary_key" json:"id"`
+	PolicyId int `gorm:"index;not null" json:"policy_id"`
+	Level    int `gorm:"not null" json:"level"`
+	RoleId   int `gorm:"not null" json:"role_id"`
+}
+
+// ApproverRoleIds are the roles of each level, first level first.
+type NewApprovalPolicy struct {
+	Name            string               `json:"name" binding:"required"`
+	DocumentType    ApprovalDocumentType `json:"document_type" binding:"required"`
+	MinAmount       decimal.Decimal      `json:"min_amount"`
+	ApproverRoleIds []int                `json:"approver_role_ids"`
+}
+
+// ApprovalRequest is one submission of a document for approval. The steps are copied from
+// the policy when the document is submitted, so later policy changes do not affect it.
+type ApprovalRequest struct {
+	ID              int                  `gorm:"primary_key" json:"id"`
+	BusinessId      string               `gorm:"index;not null" json:"business_id"`
+	PolicyId        int                  `gorm:"index;not null" json:"policy_id"`
+	PolicyName      string               `gorm:"size:100" json:"policy_name"`
+	DocumentType    ApprovalDocumentType `gorm:"size:30;not null;index:idx_approval_request_reference" json:"document_type"`
+	ReferenceId     int                  `gorm:"not null;index:idx_approval_request_reference" json:"reference_id"`
+	ReferenceNumber string               `gorm:"size:255" json:"reference_number"`
+	BranchId        int                  `gorm:"index" json:"branch_id"`
+	Amount          decimal.Decimal      `gorm:"type:decimal(20,4);default:0" json:"amount"`
+	Status          ApprovalStatus       `gorm:"size:20;not null;index" json:"status"`
+	CurrentLevel    int                  `gorm:"not null;default:1" json:"current_level"`
+	RequestedBy     int                  `gorm:"not null" json:"requested_by"`
+	RequestedByName string               `gorm:"size:100" json:"requested_by_name"`
+	CompletedAt     *time.Time           `json:"completed_at"`
+	Steps           []ApprovalStep       `gorm:"foreignKey:ApprovalRequestId" json:"steps"`
+	CreatedAt       time.Time            `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time            `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type ApprovalStep struct {
+	ID                int            `gorm:"primary_key" json:"id"`
+	ApprovalRequestId int            `gorm:"index;not null" json:"approval_request_id"`
+	Level             int            `gorm:"not null" json:"level"`
+	RoleId            int            `gorm:"not null" json:"role_id"`
+	Status            ApprovalStatus `gorm:"size:20;not null" json:"status"`
+	ActedBy           int            `gorm:"default:0" json:"acted_by"`
+	ActedByName       string         `gorm:"size:100" json:"acted_by_name"`
+	ActedAt           *time.Time     `json:"acted_at"`
+}
+
+// approvalSubject is a document about to be posted to accounting.
+// Amount is in CurrencyId; CurrencyId 0 means the base currency.
+type approvalSubject struct {
+	DocumentType    ApprovalDocumentType
+	ReferenceId     int
+	ReferenceNumber string
+	BranchId        int
+	CurrencyId      int
+	ExchangeRate    decimal.Decimal
+	Amount          decimal.Decimal
+}
+
+// baseAmount is the amount policies are matched against.
+func (s approvalSubject) baseAmount(ctx context.Context, businessId string) (decimal.Decimal, error) {
+	amount := s.Amount.Abs()
+	if s.CurrencyId > 0 {
+		business, err := GetBusinessById(ctx, businessId)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		if s.CurrencyId != business.BaseCurrencyId {
+			amount = amount.Mul(s.ExchangeRate)
+		}
+	}
+	return amount, nil
+}
+
+// requiresApproval reports whether an active policy covers the subject.
+func (s approvalSubject) requiresApproval(ctx context.Context, businessId string) (bool, error) {
+	amount, err := s.baseAmount(ctx, businessId)
+	if err != nil {
+		return false, err
+	}
+	policy, err := matchApprovalPolicy(ctx, businessId, s.DocumentType, amount)
+	return policy != nil, err
+}
+
+func (input *NewApprovalPolicy) validate(ctx context.Context, businessId string, id int) error {
+	if strings.TrimSpace(input.Name) == "" {
+		return errors.New("name is required")
+	}
+	if err := utils.ValidateUnique[ApprovalPolicy](ctx, businessId, "name", input.Name, id); err != nil {
+		return err
+	}
+	if !input.DocumentType.IsValid() {
+		return errors.New("invalid document type")
+	}
+	if input.MinAmount.IsNegative() {
+		return errors.New("minimum amount cannot be negative")
+	}
+	if len(input.ApproverRoleIds) == 0 {
+		return errors.New("at least one approver role is required")
+	}
+	for _, roleId := range input.ApproverRoleIds {
+		if err := utils.ValidateResourceId[Role](ctx, businessId, roleId); err != nil {
+			return errors.New("approver role not found")
+		}
+	}
+	return nil
+}
+
+func (p *ApprovalPolicy) assign(input *NewApprovalPolicy) {
+	p.Name = strings.TrimSpace(input.Name)
+	p.DocumentType = input.DocumentType
+	p.MinAmount = input.MinAmount
+	p.Levels = make([]ApprovalPolicyLevel, len(input.ApproverRoleIds))
+	for i, roleId := range input.ApproverRoleIds {
+		p.Levels[i] = ApprovalPolicyLevel{Level: i + 1, RoleId: roleId}
+	}
+}
+
+func CreateApprovalPolicy(ctx context.Context, input *NewApprovalPolicy) (*ApprovalPolicy, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if err := input.validate(ctx, businessId, 0); err != nil {
+		return nil, err
+	}
+
+	policy := ApprovalPolicy{BusinessId: businessId, IsActive: utils.NewTrue()}
+	policy.assign(input)
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Create(&policy).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// UpdateApprovalPolicy applies to documents submitted afterwards; pending requests keep
+// the chain they were submitted with.
+func UpdateApprovalPolicy(ctx context.Context, id int, input *NewApprovalPolicy) (*ApprovalPolicy, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if err := input.validate(ctx, businessId, id); err != nil {
+		return nil, err
+	}
+
+	existing, err := utils.FetchModel[ApprovalPolicy](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+	existing.assign(input)
+
+	db := config.GetDB()
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("policy_id = ?", id).Delete(&ApprovalPolicyLevel{}).Error; err != nil {
+			return err
+		}
+		return tx.Save(existing).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+func DeleteApprovalPolicy(ctx context.Context, id int) (*ApprovalPolicy, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	result, err := utils.FetchModel[ApprovalPolicy](ctx, businessId, id, "Levels")
+	if err != nil {
+		return nil, err
+	}
+	count, err := utils.ResourceCountWhere[ApprovalRequest](ctx, businessId, "policy_id = ? AND status = ?", id, ApprovalStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("policy has documents pending approval")
+	}
+
+	db := config.GetDB()
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("policy_id = ?", id).Delete(&ApprovalPolicyLevel{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(result).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func ToggleActiveApprovalPolicy(ctx context.Context, id int, isActive bool) (*ApprovalPolicy, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	policy, err := utils.FetchModel[ApprovalPolicy](ctx, businessId, id, "Levels")
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Model(policy).Update("IsActive", isActive).Error; err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func GetApprovalPolicy(ctx context.Context, id int) (*ApprovalPolicy, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	return utils.FetchModel[ApprovalPolicy](ctx, businessId, id, "Levels")
+}
+
+func ListApprovalPolicy(ctx context.Context, documentType *ApprovalDocumentType) ([]*ApprovalPolicy, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	db := config.GetDB()
+	dbCtx := db.WithContext(ctx).Preload("Levels", func(db *gorm.DB) *gorm.DB {
+		return db.Order("level")
+	}).Where("business_id = ?", businessId)
+	if documentType != nil {
+		dbCtx = dbCtx.Where("document_type = ?", *documentType)
+	}
+	var results []*ApprovalPolicy
+	if err := dbCtx.Order("document_type, min_amount").Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// matchApprovalPolicy returns the active policy covering amount, or nil when the document
+// does not need approval.
+func matchApprovalPolicy(ctx context.Context, businessId string, documentType ApprovalDocumentType, amount decimal.Decimal) (*ApprovalPolicy, error) {
+	db := config.GetDB()
+	var policies []*ApprovalPolicy
+	if err := db.WithContext(ctx).Preload("Levels", func(db *gorm.DB) *gorm.DB {
+		return db.Order("level")
+	}).
+		Where("business_id = ? AND document_type = ? AND is_active = ? AND min_amount <= ?", businessId, documentType, true, amount).
+		Order("min_amount DESC, id").
+		Limit(1).
+		Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	if len(policies) == 0 || len(policies[0].Levels) == 0 {
+		return nil, nil
+	}
+	return policies[0], nil
+}
+
+// hasApprovalPolicy reports whether documentType has any active policy.
+func hasApprovalPolicy(ctx context.Context, businessId string, documentType ApprovalDocumentType) (bool, error) {
+	count, err := utils.ResourceCountWhere[ApprovalPolicy](ctx, businessId, "document_type = ? AND is_active = ?", documentType, true)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// holdForApproval withdraws any open request of the document and, when an active policy
+// covers its amount, submits it again and marks it PENDING. It returns the document's
+// approval status: nil when the document can be posted right away.
+func holdForApproval(ctx context.Context, tx *gorm.DB, s approvalSubject) (*ApprovalStatus, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if err := withdrawApproval(ctx, tx, s.DocumentType, s.ReferenceId); err != nil {
+		return nil, err
+	}
+
+	amount, err := s.baseAmount(ctx, businessId)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := matchApprovalPolicy(ctx, businessId, s.DocumentType, amount)
+	if err != nil || policy == nil {
+		return nil, err
+	}
+
+	userId, _ := utils.GetUserIdFromContext(ctx)
+	userName, _ := utils.GetUserNameFromContext(ctx)
+	request := ApprovalRequest{
+		BusinessId:      businessId,
+		PolicyId:        policy.ID,
+		PolicyName:      policy.Name,
+		DocumentType:    s.DocumentType,
+		ReferenceId:     s.ReferenceId,
+		ReferenceNumber: s.ReferenceNumber,
+		BranchId:        s.BranchId,
+		Amount:          amount,
+		Status:          ApprovalStatusPending,
+		CurrentLevel:    1,
+		RequestedBy:     userId,
+		RequestedByName: userName,
+	}
+	for _, level := range policy.Levels {
+		request.Steps = append(request.Steps, ApprovalStep{
+			Level:  level.Level,
+			RoleId: level.RoleId,
+			Status: ApprovalStatusPending,
+		})
+	}
+	if err := tx.WithContext(ctx).Create(&request).Error; err != nil {
+		return nil, err
+	}
+
+	status := ApprovalStatusPending
+	if err := setDocumentApprovalStatus(ctx, tx, s.DocumentType, s.ReferenceId, &status); err != nil {
+		return nil, err
+	}
+	description := fmt.Sprintf("Submitted for approval under policy %s", policy.Name)
+	if err := createHistory(tx.WithContext(ctx), "Update", s.ReferenceId, approvalDocumentTables[s.DocumentType], nil, nil, description); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// withdrawApproval cancels the open request of a document that is being changed or deleted.
+// The document's approval status is cleared; it only describes the version that was approved.
+func withdrawApproval(ctx context.Context, tx *gorm.DB, documentType ApprovalDocumentType, referenceId int) error {
+	now := time.Now().UTC()
+	result := tx.WithContext(ctx).Model(&ApprovalRequest{}).
+		Where("document_type = ? AND reference_id = ? AND status = ?", documentType, referenceId, ApprovalStatusPending).
+		Updates(map[string]interface{}{
+			"status":       ApprovalStatusCancelled,
+			"completed_at": &now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	model, err := approvalDocumentModel(documentType)
+	if err != nil {
+		return err
+	}
+	return tx.WithContext(ctx).Model(model).Where("id = ?", referenceId).UpdateColumn("approval_status", nil).Error
+}
+
+func approvalDocumentModel(documentType ApprovalDocumentType) (interface{}, error) {
+	switch documentType {
+	case ApprovalDocumentTypeBill:
+		return &Bill{}, nil
+	case ApprovalDocumentTypeExpense:
+		return &Expense{}, nil
+	case ApprovalDocumentTypeManualJournal:
+		return &Journal{}, nil
+	case ApprovalDocumentTypeSupplierPayment:
+		return &SupplierPayment{}, nil
+	case ApprovalDocumentTypeInventoryAdjustment:
+		return &InventoryAdjustment{}, nil
+	}
+	return nil, errors.New("invalid document type")
+}
+
+func setDocumentApprovalStatus(ctx context.Context, tx *gorm.DB, documentType ApprovalDocumentType, referenceId int, status *ApprovalStatus) error {
+	model, err := approvalDocumentModel(documentType)
+	if err != nil {
+		return err
+	}
+	return tx.WithContext(ctx).Model(model).Where("id = ?", referenceId).UpdateColumn("approval_status", status).Error
+}
+
+// publishApprovable writes the accounting outbox record of a created or edited document
+// that has no status of its own. old is nil on create; wasPosted tells whether old is on
+// the books. A document held for approval is taken off the books until it is approved.
+func publishApprovable(ctx context.Context, tx *gorm.DB, businessId string, date time.Time, id int, refType AccountReferenceType,
+	status *ApprovalStatus, obj interface{}, old interface{}, wasPosted bool) error {
+
+	switch {
+	case status != nil && wasPosted:
+		return PublishToAccounting(ctx, tx, businessId, date, id, refType, nil, old, PubSubMessageActionDelete)
+	case status != nil:
+		return nil
+	case wasPosted:
+		return PublishToAccounting(ctx, tx, businessId, date, id, refType, obj, old, PubSubMessageActionUpdate)
+	}
+	return PublishToAccounting(ctx, tx, businessId, date, id, refType, obj, nil, PubSubMessageActionCreate)
+}
+
+// currentApprover loads the user acting on an approval request.
+func currentApprover(ctx context.Context) (*User, error) {
+	userId, ok := utils.GetUserIdFromContext(ctx)
+	if !ok || userId <= 0 {
+		return nil, errors.New("user id is required")
+	}
+	db := config.GetDB()
+	var user User
+	if err := db.WithContext(ctx).Where("id = ?", userId).Take(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// canActOnStep: owners can approve any level, other users the levels of their role.
+func canActOnStep(user *User, step *ApprovalStep) bool {
+	if user.Role == UserRoleOwner {
+		return true
+	}
+	return user.Role == UserRoleCustom && user.RoleId == step.RoleId
+}
+
+// CheckApprover enforces segregation of duties: the submitter cannot approve their own
+// document and nobody approves more than one level of the same request.
+func (r *ApprovalRequest) CheckApprover(user *User) (*ApprovalStep, error) {
+	if r.Status != ApprovalStatusPending {
+		return nil, errors.New("approval request is not pending")
+	}
+	if r.RequestedBy == user.ID {
+		return nil, errors.New("you cannot approve your own document")
+	}
+	var current *ApprovalStep
+	for i := range r.Steps {
+		step := &r.Steps[i]
+		if step.Level == r.CurrentLevel {
+			current = step
+		} else if step.ActedBy == user.ID {
+			return nil, errors.New("you have already approved another level of this document")
+		}
+	}
+	if current == nil {
+		return nil, errors.New("approval request has no pending level")
+	}
+	if !canActOnStep(user, current) {
+		return nil, errors.New("you are not an approver for this level")
+	}
+	return current, nil
+}
+
+func (r *ApprovalRequest) lastLevel() int {
+	last := 0
+	for _, step := range r.Steps {
+		if step.Level > last {
+			last = step.Level
+		}
+	}
+	return last
+}
+
+func fetchApprovalRequestForChange(ctx context.Context, tx *gorm.DB, businessId string, id int) (*ApprovalRequest, error) {
+	var request ApprovalRequest
+	err := tx.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("business_id = ? AND id = ?", businessId, id).
+		First(&request).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	if err := tx.WithContext(ctx).Where("approval_request_id = ?", id).Order("level").Find(&request.Steps).Error; err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// addApprovalComment keeps the approver's comment with the document's other comments.
+func addApprovalComment(ctx context.Context, tx *gorm.DB, request *ApprovalRequest, user *User, comment string) error {
+	if strings.TrimSpace(comment) == "" {
+		return nil
+	}
+	return tx.WithContext(ctx).Create(&Comment{
+		BusinessId:    request.BusinessId,
+		Description:   comment,
+		ReferenceID:   request.ReferenceId,
+		ReferenceType: approvalDocumentTables[request.DocumentType],
+		UserId:        user.ID,
+		UserName:      user.Name,
+	}).Error
+}
+
+// ApproveApprovalRequest approves the current level of a request. Approving the last
+// level posts the document the way confirming it would have.
+func ApproveApprovalRequest(ctx context.Context, id int, comment *string) (*ApprovalRequest, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	user, err := currentApprover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	tx := db.WithContext(ctx).Begin()
+	request, err := fetchApprovalRequestForChange(ctx, tx, businessId, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	step, err := request.CheckApprover(user)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	step.Status = ApprovalStatusApproved
+	step.ActedBy = user.ID
+	step.ActedByName = user.Name
+	step.ActedAt = &now
+	if err := tx.WithContext(ctx).Save(step).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	lastLevel := request.lastLevel()
+	description := fmt.Sprintf("Approved level %d of %d", step.Level, lastLevel)
+	if step.Level >= lastLevel {
+		request.Status = ApprovalStatusApproved
+		request.CompletedAt = &now
+	} else {
+		request.CurrentLevel = step.Level + 1
+	}
+	if err := tx.WithContext(ctx).Model(request).Updates(map[string]interface{}{
+		"Status":       request.Status,
+		"CurrentLevel": request.CurrentLevel,
+		"CompletedAt":  request.CompletedAt,
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := addApprovalComment(ctx, tx, request, user, utils.DereferencePtr(comment, "")); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := createHistory(tx.WithContext(ctx), "Update", request.ReferenceId, approvalDocumentTables[request.DocumentType], nil, nil, description); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if request.Status == ApprovalStatusApproved {
+		status := ApprovalStatusApproved
+		if err := setDocumentApprovalStatus(ctx, tx, request.DocumentType, request.ReferenceId, &status); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := postApprovedDocument(ctx, tx, businessId, request.DocumentType, request.ReferenceId); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// RejectApprovalRequest ends the request at the current level. The document stays off the
+// books; editing it submits it again.
+func RejectApprovalRequest(ctx context.Context, id int, comment string) (*ApprovalRequest, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if strings.TrimSpace(comment) == "" {
+		return nil, errors.New("comment is required")
+	}
+	user, err := currentApprover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	tx := db.WithContext(ctx).Begin()
+	request, err := fetchApprovalRequestForChange(ctx, tx, businessId, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	step, err := request.CheckApprover(user)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	step.Status = ApprovalStatusRejected
+	step.ActedBy = user.ID
+	step.ActedByName = user.Name
+	step.ActedAt = &now
+	if err := tx.WithContext(ctx).Save(step).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	request.Status = ApprovalStatusRejected
+	request.CompletedAt = &now
+	if err := tx.WithContext(ctx).Model(request).Updates(map[string]interface{}{
+		"Status":      request.Status,
+		"CompletedAt": request.CompletedAt,
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := releaseRejectedDocument(ctx, tx, businessId, request.DocumentType, request.ReferenceId); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	status := ApprovalStatusRejected
+	if err := setDocumentApprovalStatus(ctx, tx, request.DocumentType, request.ReferenceId, &status); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := addApprovalComment(ctx, tx, request, user, comment); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	description := fmt.Sprintf("Rejected at level %d: %s", step.Level, comment)
+	if err := createHistory(tx.WithContext(ctx), "Update", request.ReferenceId, approvalDocumentTables[request.DocumentType], nil, nil, description); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// fetchHeldDocument loads a document held for approval through tx and locks it, so it
+// cannot change between the approval and its posting.
+func fetchHeldDocument[T any](ctx context.Context, tx *gorm.DB, businessId string, id int, associations ...string) (*T, error) {
+	query := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("business_id = ?", businessId)
+	for _, field := range associations {
+		query = query.Preload(field)
+	}
+	var result T
+	if err := query.First(&result, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ErrorRecordNotFound
+		}
+		return nil, err
+	}
+	return &result, nil
+}
+
+// fetchHeldDocumentForChange is fetchHeldDocument that also checks the transaction lock.
+func fetchHeldDocumentForChange[T utils.ModelChangeLocker](ctx context.Context, tx *gorm.DB, businessId string, id int, associations ...string) (*T, error) {
+	result, err := fetchHeldDocument[T](ctx, tx, businessId, id, associations...)
+	if err != nil {
+		return nil, err
+	}
+	if err := (*result).CheckTransactionLock(ctx); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// postApprovedDocument does what posting the document would have done had no policy held it.
+func postApprovedDocument(ctx context.Context, tx *gorm.DB, businessId string, documentType ApprovalDocumentType, id int) error {
+	switch documentType {
+	case ApprovalDocumentTypeBill:
+		bill, err := fetchHeldDocumentForChange[Bill](ctx, tx, businessId, id, "Details")
+		if err != nil {
+			return err
+		}
+		if bill.CurrentStatus != BillStatusDraft {
+			return errors.New("bill is no longer a draft")
+		}
+		approved := ApprovalStatusApproved
+		bill.ApprovalStatus = &approved
+		return applyBillStatus(ctx, tx, businessId, bill, string(BillStatusConfirmed))
+	case ApprovalDocumentTypeInventoryAdjustment:
+		inventoryAdjustment, err := fetchHeldDocumentForChange[InventoryAdjustment](ctx, tx, businessId, id, "Details")
+		if err != nil {
+			return err
+		}
+		if inventoryAdjustment.CurrentStatus != InventoryAdjustmentStatusDraft {
+			return errors.New("inventory adjustment is no longer a draft")
+		}
+		return adjustInventoryAdjustment(ctx, tx, businessId, inventoryAdjustment)
+	case ApprovalDocumentTypeExpense:
+		expense, err := fetchHeldDocumentForChange[Expense](ctx, tx, businessId, id)
+		if err != nil {
+			return err
+		}
+		return PublishToAccounting(ctx, tx, businessId, expense.ExpenseDate, expense.ID, AccountReferenceTypeExpense, expense, nil, PubSubMessageActionCreate)
+	case ApprovalDocumentTypeManualJournal:
+		journal, err := fetchHeldDocumentForChange[Journal](ctx, tx, businessId, id, "Transactions")
+		if err != nil {
+			return err
+		}
+		return PublishToAccounting(ctx, tx, businessId, journal.JournalDate, journal.ID, AccountReferenceTypeJournal, journal, nil, PubSubMessageActionCreate)
+	case ApprovalDocumentTypeSupplierPayment:
+		supplierPayment, err := fetchHeldDocumentForChange[SupplierPayment](ctx, tx, businessId, id, "PaidBills")
+		if err != nil {
+			return err
+		}
+		return PublishToAccounting(ctx, tx, businessId, supplierPayment.PaymentDate, supplierPayment.ID, AccountReferenceTypeSupplierPayment, supplierPayment, nil, PubSubMessageActionCreate)
+	}
+	return errors.New("invalid document type")
+}
+
+// releaseRejectedDocument gives back what a held document kept reserved while it waited for approval.
+func releaseRejectedDocument(ctx context.Context, tx *gorm.DB, businessId string, documentType ApprovalDocumentType, id int) error {
+	if documentType != ApprovalDocumentTypeSupplierPayment {
+		return nil
+	}
+	supplierPayment, err := fetchHeldDocumentForChange[SupplierPayment](ctx, tx, businessId, id, "PaidBills")
+	if err != nil {
+		return err
+	}
+	return releaseSupplierPaidBills(ctx, tx, businessId, supplierPayment.PaidBills)
+}
+
+func GetApprovalRequest(ctx context.Context, id int) (*ApprovalRequest, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	return utils.FetchModel[ApprovalRequest](ctx, businessId, id, "Steps")
+}
+
+// ListApprovalRequest returns every submission of a document, latest first.
+func ListApprovalRequest(ctx context.Context, documentType ApprovalDocumentType, referenceId int) ([]*ApprovalRequest, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	db := config.GetDB()
+	var results []*ApprovalRequest
+	if err := db.WithContext(ctx).Preload("Steps", func(db *gorm.DB) *gorm.DB {
+		return db.Order("level")
+	}).
+		Where("business_id = ? AND document_type = ? AND reference_id = ?", businessId, documentType, referenceId).
+		Order("id DESC").
+		Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetApprovalInbox returns the pending requests the current user can act on now, oldest first.
+func GetApprovalInbox(ctx context.Context, documentType *ApprovalDocumentType) ([]*ApprovalRequest, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	user, err := currentApprover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	dbCtx := db.WithContext(ctx).Preload("Steps", func(db *gorm.DB) *gorm.DB {
+		return db.Order("level")
+	}).
+		Where("business_id = ? AND status = ? AND requested_by <> ?", businessId, ApprovalStatusPending, user.ID)
+	if documentType != nil {
+		dbCtx = dbCtx.Where("document_type = ?", *documentType)
+	}
+	if user.Role != UserRoleOwner {
+		dbCtx = dbCtx.Where("EXISTS (SELECT 1 FROM approval_steps s WHERE s.approval_request_id = approval_requests.id AND s.level = approval_requests.current_level AND s.role_id = ?)", user.RoleId)
+	}
+	var requests []*ApprovalRequest
+	if err := dbCtx.Order("id").Find(&requests).Error; err != nil {
+		return nil, err
+	}
+
+	results := make([]*ApprovalRequest, 0, len(requests))
+	for _, request := range requests {
+		if _, err := request.CheckApprover(user); err == nil {
+			results = append(results, request)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,57 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/mmdatafocus/books_backend/models"
+)
+
+func TestApprovalRequestCheckApprover(t *testing.T) {
+	newRequest := func() *models.ApprovalRequest {
+		return &models.ApprovalRequest{
+			Status:       models.ApprovalStatusPending,
+			CurrentLevel: 1,
+			RequestedBy:  1,
+			Steps: []models.ApprovalStep{
+				{Level: 1, RoleId: 10, Status: models.ApprovalStatusPending},
+				{Level: 2, RoleId: 20, Status: models.ApprovalStatusPending},
+			},
+		}
+	}
+	clerk := &models.User{ID: 1, Role: models.UserRoleCustom, RoleId: 10}
+	manager := &models.User{ID: 2, Role: models.UserRoleCustom, RoleId: 10}
+	director := &models.User{ID: 3, Role: models.UserRoleCustom, RoleId: 20}
+	owner := &models.User{ID: 4, Role: models.UserRoleOwner}
+
+	request := newRequest()
+	if _, err := request.CheckApprover(clerk); err == nil {
+		t.Error("the submitter must not approve their own document")
+	}
+	if _, err := request.CheckApprover(director); err == nil {
+		t.Error("a level 2 approver must not act on level 1")
+	}
+	step, err := request.CheckApprover(manager)
+	if err != nil || step.Level != 1 {
+		t.Fatalf("level 1 approver = %v, %v", step, err)
+	}
+	if _, err := request.CheckApprover(owner); err != nil {
+		t.Errorf("owners approve any level: %v", err)
+	}
+
+	// the manager approved level 1 and cannot approve level 2 as well, even as an owner
+	request.Steps[0].Status = models.ApprovalStatusApproved
+	request.Steps[0].ActedBy = manager.ID
+	request.CurrentLevel = 2
+	if _, err := request.CheckApprover(&models.User{ID: manager.ID, Role: models.UserRoleOwner}); err == nil {
+		t.Error("one user must not approve two levels")
+	}
+	if step, err := request.CheckApprover(director); err != nil || step.Level != 2 {
+		t.Errorf("level 2 approver = %v, %v", step, err)
+	}
+
+	request = newRequest()
+	request.Status = models.ApprovalStatusRejected
+	if _, err := request.CheckApprover(owner); err == nil {
+		t.Error("a rejected request cannot be acted on")
+	}
+}
@@ -0,0 +1,586 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type AssemblyOrderType string
+
+const (
+	AssemblyOrderTypeAssembly    AssemblyOrderType = "ASSEMBLY"
+	AssemblyOrderTypeDisassembly AssemblyOrderType = "DISASSEMBLY"
+)
+
+func (t AssemblyOrderType) IsValid() bool {
+	switch t {
+	case AssemblyOrderTypeAssembly, AssemblyOrderTypeDisassembly:
+		return true
+	}
+	return false
+}
+
+// AssemblyOrder builds Quantity of a finished good from its components in one warehouse
+// (ASSEMBLY), or breaks finished goods back into components (DISASSEMBLY). The produced
+// stock is valued at the FIFO cost of what was consumed plus LabourCost and OverheadCost,
+// which are credited to CostAccountId. Orders with a SalesInvoiceId were posted by kitting
+// on sale and follow their invoice.
+type AssemblyOrder struct {
+	ID             int                   `gorm:"primary_key" json:"id"`
+	BusinessId     string                `gorm:"index;not null" json:"business_id" binding:"required"`
+	OrderNumber    string                `gorm:"size:255;not null" json:"order_number"`
+	OrderType      AssemblyOrderType     `gorm:"size:12;not null" json:"order_type"`
+	OrderDate      time.Time             `gorm:"not null" json:"order_date" binding:"required"`
+	WarehouseId    int                   `gorm:"index;not null" json:"warehouse_id" binding:"required"`
+	ProductId      int                   `gorm:"not null" json:"product_id" binding:"required"`
+	ProductType    ProductType           `gorm:"type:enum('S','V');default:S;not null" json:"product_type"`
+	BatchNumber    string                `gorm:"size:100" json:"batch_number"`
+	Quantity       decimal.Decimal       `gorm:"type:decimal(20,4);default:0" json:"quantity"`
+	LabourCost     decimal.Decimal       `gorm:"type:decimal(20,4);default:0" json:"labour_cost"`
+	OverheadCost   decimal.Decimal       `gorm:"type:decimal(20,4);default:0" json:"overhead_cost"`
+	CostAccountId  int                   `gorm:"default:0" json:"cost_account_id"`
+	SalesInvoiceId int                   `gorm:"index;default:0" json:"sales_invoice_id"`
+	Notes          string                `gorm:"size:255" json:"notes"`
+	CurrentStatus  AssemblyOrderStatus   `gorm:"type:enum('Draft', 'Confirmed');not null" json:"current_status" binding:"required"`
+	Details        []AssemblyOrderDetail `gorm:"foreignKey:AssemblyOrderId" json:"details"`
+	CreatedAt      time.Time             `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time             `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// AssemblyOrderDetail is a component line; Qty is the total quantity consumed (or, for a
+// disassembly, recovered) in the component's base unit.
+type AssemblyOrderDetail struct {
+	ID              int             `gorm:"primary_key" json:"id"`
+	AssemblyOrderId int             `gorm:"index;not null" json:"assembly_order_id" binding:"required"`
+	ProductId       int             `gorm:"not null" json:"product_id"`
+	ProductType     ProductType     `gorm:"type:enum('S','V');default:S;not null" json:"product_type"`
+	BatchNumber     string          `gorm:"size:100" json:"batch_number"`
+	Name            string          `gorm:"size:100" json:"name" binding:"required"`
+	Qty             decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"qty" binding:"required"`
+	CreatedAt       time.Time       `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// NewAssemblyOrder takes its component lines from the product's bill of materials when
+// Details is empty.
+type NewAssemblyOrder struct {
+	OrderNumber   string                   `json:"order_number"`
+	OrderType     AssemblyOrderType        `json:"order_type"`
+	OrderDate     time.Time                `json:"order_date"`
+	WarehouseId   int                      `json:"warehouse_id"`
+	ProductId     int                      `json:"product_id"`
+	ProductType   ProductType              `json:"product_type"`
+	BatchNumber   string                   `json:"batch_number"`
+	Quantity      decimal.Decimal          `json:"quantity"`
+	LabourCost    decimal.Decimal          `json:"labour_cost"`
+	OverheadCost  decimal.Decimal          `json:"overhead_cost"`
+	CostAccountId int                      `json:"cost_account_id"`
+	Notes         string                   `json:"notes"`
+	CurrentStatus AssemblyOrderStatus      `json:"current_status"`
+	Details       []NewAssemblyOrderDetail `json:"details"`
+}
+
+type NewAssemblyOrderDetail struct {
+	ProductId   int             `json:"product_id"`
+	ProductType ProductType     `json:"product_type"`
+	BatchNumber string          `json:"batch_number"`
+	Name        string          `json:"name"`
+	Qty         decimal.Decimal `json:"qty"`
+}
+
+type AssemblyOrdersConnection struct {
+	Edges    []*AssemblyOrdersEdge `json:"edges"`
+	PageInfo *PageInfo             `json:"pageInfo"`
+}
+
+type AssemblyOrdersEdge Edge[AssemblyOrder]
+
+func (obj AssemblyOrder) GetId() int {
+	return obj.ID
+}
+
+func (ao AssemblyOrder) GetCursor() string {
+	return ao.CreatedAt.String()
+}
+
+// AssemblyOrderLine is one stock movement of an assembly order. DetailId is 0 for the
+// finished good.
+type AssemblyOrderLine struct {
+	DetailId    int
+	ProductId   int
+	ProductType ProductType
+	BatchNumber string
+	Qty         decimal.Decimal
+}
+
+func (ao *AssemblyOrder) finishedGoodLines() []AssemblyOrderLine {
+	return []AssemblyOrderLine{{
+		ProductId:   ao.ProductId,
+		ProductType: ao.ProductType,
+		BatchNumber: ao.BatchNumber,
+		Qty:         ao.Quantity,
+	}}
+}
+
+func (ao *AssemblyOrder) componentLines() []AssemblyOrderLine {
+	lines := make([]AssemblyOrderLine, 0, len(ao.Details))
+	for _, d := range ao.Details {
+		lines = append(lines, AssemblyOrderLine{
+			DetailId:    d.ID,
+			ProductId:   d.ProductId,
+			ProductType: d.ProductType,
+			BatchNumber: d.BatchNumber,
+			Qty:         d.Qty,
+		})
+	}
+	return lines
+}
+
+// ConsumedLines are the stock the order takes out of the warehouse.
+func (ao *AssemblyOrder) ConsumedLines() []AssemblyOrderLine {
+	if ao.OrderType == AssemblyOrderTypeDisassembly {
+		return ao.finishedGoodLines()
+	}
+	return ao.componentLines()
+}
+
+// ProducedLines are the stock the order puts into the warehouse.
+func (ao *AssemblyOrder) ProducedLines() []AssemblyOrderLine {
+	if ao.OrderType == AssemblyOrderTypeDisassembly {
+		return ao.componentLines()
+	}
+	return ao.finishedGoodLines()
+}
+
+// AllocateAssemblyCost spreads total over the produced lines in proportion to weights,
+// or to quantities when no line has a weight, and returns each line's unit cost rounded
+// to the stock ledger's four places.
+func AllocateAssemblyCost(total decimal.Decimal, qtys []decimal.Decimal, weights []decimal.Decimal) []decimal.Decimal {
+	shares := weights
+	sum := decimal.Zero
+	for _, w := range weights {
+		sum = sum.Add(w)
+	}
+	if !sum.IsPositive() {
+		shares = qtys
+		sum = decimal.Zero
+		for _, q := range qtys {
+			sum = sum.Add(q)
+		}
+	}
+	units := make([]decimal.Decimal, len(qtys))
+	if !sum.IsPositive() {
+		return units
+	}
+	for i, q := range qtys {
+		if !q.IsPositive() {
+			continue
+		}
+		units[i] = total.Mul(shares[i]).Div(sum).DivRound(q, 4)
+	}
+	return units
+}
+
+// fillFromBillOfMaterials builds the component lines from the product's bill when none
+// were entered. Wastage is only consumed when assembling.
+func (input *NewAssemblyOrder) fillFromBillOfMaterials(ctx context.Context, tx *gorm.DB, businessId string) error {
+	if len(input.Details) > 0 {
+		return nil
+	}
+	bom, err := productBillOfMaterials(tx, businessId, input.ProductType, input.ProductId)
+	if err != nil {
+		return err
+	}
+	if bom == nil {
+		return errors.New("product has no bill of materials")
+	}
+	for _, c := range bom.Components {
+		name, err := componentName(ctx, c.ProductType, c.ProductId)
+		if err != nil {
+			return err
+		}
+		wastage := c.WastagePercent
+		if input.OrderType == AssemblyOrderTypeDisassembly {
+			wastage = decimal.Zero
+		}
+		input.Details = append(input.Details, NewAssemblyOrderDetail{
+			ProductId:   c.ProductId,
+			ProductType: c.ProductType,
+			Name:        name,
+			Qty:         ComponentQty(c.Qty, wastage, input.Quantity),
+		})
+	}
+	return nil
+}
+
+// refuseSerialTracked fails for a serial tracked product: orders move stock by quantity
+// and cannot say which of its units they consume or produce.
+func refuseSerialTracked(ctx context.Context, productType ProductType, productId int) error {
+	tracking, err := productSerialTracking(config.GetDB().WithContext(ctx), productId, productType)
+	if err != nil {
+		return err
+	}
+	if !tracking.IsSerialTracking {
+		return nil
+	}
+	name, err := componentName(ctx, productType, productId)
+	if err != nil {
+		name = fmt.Sprintf("product_id=%d", productId)
+	}
+	return fmt.Errorf("%s is serial tracked and cannot be assembled or disassembled", strings.TrimSpace(name))
+}
+
+func (input *NewAssemblyOrder) validate(ctx context.Context, businessId string) error {
+	if !input.OrderType.IsValid() {
+		return errors.New("invalid assembly order type")
+	}
+	if input.CurrentStatus != AssemblyOrderStatusDraft && input.CurrentStatus != AssemblyOrderStatusConfirmed {
+		return errors.New("invalid assembly order status")
+	}
+	if err := utils.ValidateResourceId[Warehouse](ctx, businessId, input.WarehouseId); err != nil {
+		return errors.New("warehouse not found")
+	}
+	if input.ProductType != ProductTypeSingle && input.ProductType != ProductTypeVariant {
+		return errors.New("finished goods must be single products or variants")
+	}
+	if !IsRealProduct(ctx, businessId, input.ProductId, input.ProductType) {
+		return errors.New("product's inventory has not been tracked")
+	}
+	if err := refuseSerialTracked(ctx, input.ProductType, input.ProductId); err != nil {
+		return err
+	}
+	if !input.Quantity.IsPositive() {
+		return errors.New("quantity must be greater than zero")
+	}
+	if input.LabourCost.IsNegative() || input.OverheadCost.IsNegative() {
+		return errors.New("labour and overhead costs cannot be negative")
+	}
+	if input.LabourCost.Add(input.OverheadCost).IsPositive() {
+		if input.CostAccountId <= 0 {
+			return errors.New("cost account is required for labour and overhead")
+		}
+		if err := utils.ValidateResourceId[Account](ctx, businessId, input.CostAccountId); err != nil {
+			return errors.New("cost account not found")
+		}
+	}
+	if len(input.Details) == 0 {
+		return errors.New("at least one component is required")
+	}
+	for _, d := range input.Details {
+		if d.ProductType != ProductTypeSingle && d.ProductType != ProductTypeVariant {
+			return errors.New("components must be single products or variants")
+		}
+		if d.ProductType == input.ProductType && d.ProductId == input.ProductId {
+			return errors.New("a product cannot be a component of itself")
+		}
+		if !IsRealProduct(ctx, businessId, d.ProductId, d.ProductType) {
+			return errors.New("component's inventory has not been tracked")
+		}
+		if err := refuseSerialTracked(ctx, d.ProductType, d.ProductId); err != nil {
+			return err
+		}
+		if !d.Qty.IsPositive() {
+			return errors.New("component quantity must be greater than zero")
+		}
+	}
+	return nil
+}
+
+// validateConfirm checks what a confirmed order will post: the period lock, value
+// adjustments dated after the order, and stock on hand for everything it consumes.
+func (ao *AssemblyOrder) validateConfirm(ctx context.Context) error {
+	if err := ValidateTransactionLock(ctx, ao.OrderDate, ao.BusinessId, AccountantTransactionLock); err != nil {
+		return err
+	}
+	lines := append(ao.ConsumedLines(), ao.ProducedLines()...)
+	for _, line := range lines {
+		if err := refuseSerialTracked(ctx, line.ProductType, line.ProductId); err != nil {
+			return err
+		}
+		batch := line.BatchNumber
+		if err := ValidateValueAdjustment(ctx, ao.BusinessId, ao.OrderDate, line.ProductType, line.ProductId, &batch); err != nil {
+			return err
+		}
+	}
+
+	asOf := MyDateString(ao.OrderDate)
+	for _, line := range ao.ConsumedLines() {
+		pid := line.ProductId
+		pt := line.ProductType
+		batch := line.BatchNumber
+		rows, err := InventorySnapshotByProductWarehouse(ctx, asOf, &ao.WarehouseId, &pid, &pt, &batch)
+		if err != nil {
+			return err
+		}
+		onHand := decimal.Zero
+		for _, r := range rows {
+			onHand = onHand.Add(r.StockOnHand)
+		}
+		if onHand.LessThan(line.Qty) {
+			name, err := componentName(ctx, line.ProductType, line.ProductId)
+			if err != nil {
+				name = fmt.Sprintf("product_id=%d", line.ProductId)
+			}
+			return fmt.Errorf("insufficient stock on hand for %s (on_hand=%s, required_qty=%s)", strings.TrimSpace(name), onHand.String(), line.Qty.String())
+		}
+	}
+	return nil
+}
+
+// applyAssemblyOrderStock moves a confirmed order through the adjusted in/out columns of
+// the stock summaries; reverse undoes it.
+func applyAssemblyOrderStock(tx *gorm.DB, ao *AssemblyOrder, reverse bool) error {
+	ctx := tx.Statement.Context
+	if err := utils.BusinessLock(ctx, ao.BusinessId, "stockLock", "assemblyOrder.go", "applyAssemblyOrderStock"); err != nil {
+		return err
+	}
+	for _, line := range ao.ConsumedLines() {
+		qty := line.Qty.Neg()
+		if reverse {
+			qty = line.Qty
+		}
+		if err := UpdateStockSummaryAdjustedQtyOut(tx, ao.BusinessId, ao.WarehouseId, line.ProductId, string(line.ProductType), line.BatchNumber, qty, ao.OrderDate); err != nil {
+			return err
+		}
+	}
+	for _, line := range ao.ProducedLines() {
+		qty := line.Qty
+		if reverse {
+			qty = line.Qty.Neg()
+		}
+		if err := UpdateStockSummaryAdjustedQtyIn(tx, ao.BusinessId, ao.WarehouseId, line.ProductId, string(line.ProductType), line.BatchNumber, qty, ao.OrderDate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// confirmAssemblyOrder posts a draft order: stock summaries now, ledger and journals
+// through the accounting outbox.
+func confirmAssemblyOrder(ctx context.Context, tx *gorm.DB, ao *AssemblyOrder) error {
+	if err := ao.validateConfirm(ctx); err != nil {
+		return err
+	}
+	if err := tx.WithContext(ctx).Model(ao).Update("CurrentStatus", AssemblyOrderStatusConfirmed).Error; err != nil {
+		return err
+	}
+	ao.CurrentStatus = AssemblyOrderStatusConfirmed
+	if err := applyAssemblyOrderStock(tx.WithContext(ctx), ao, false); err != nil {
+		return err
+	}
+	return PublishToAccounting(ctx, tx, ao.BusinessId, ao.OrderDate, ao.ID, AccountReferenceTypeAssemblyOrder, ao, nil, PubSubMessageActionCreate)
+}
+
+func createAssemblyOrder(ctx context.Context, tx *gorm.DB, businessId string, input *NewAssemblyOrder, salesInvoiceId int) (*AssemblyOrder, error) {
+	if err := input.fillFromBillOfMaterials(ctx, tx, businessId); err != nil {
+		return nil, err
+	}
+	if err := input.validate(ctx, businessId); err != nil {
+		return nil, err
+	}
+
+	details := make([]AssemblyOrderDetail, len(input.Details))
+	for i, d := range input.Details {
+		details[i] = AssemblyOrderDetail{
+			ProductId:   d.ProductId,
+			ProductType: d.ProductType,
+			BatchNumber: d.BatchNumber,
+			Name:        d.Name,
+			Qty:         d.Qty,
+		}
+	}
+	ao := AssemblyOrder{
+		BusinessId:     businessId,
+		OrderNumber:    input.OrderNumber,
+		OrderType:      input.OrderType,
+		OrderDate:      input.OrderDate,
+		WarehouseId:    input.WarehouseId,
+		ProductId:      input.ProductId,
+		ProductType:    input.ProductType,
+		BatchNumber:    input.BatchNumber,
+		Quantity:       input.Quantity,
+		LabourCost:     input.LabourCost,
+		OverheadCost:   input.OverheadCost,
+		CostAccountId:  input.CostAccountId,
+		SalesInvoiceId: salesInvoiceId,
+		Notes:          input.Notes,
+		CurrentStatus:  AssemblyOrderStatusDraft,
+		Details:        details,
+	}
+	if err := tx.WithContext(ctx).Create(&ao).Error; err != nil {
+		return nil, err
+	}
+	if input.CurrentStatus == AssemblyOrderStatusConfirmed {
+		if err := confirmAssemblyOrder(ctx, tx, &ao); err != nil {
+			return nil, err
+		}
+	}
+	return &ao, nil
+}
+
+func CreateAssemblyOrder(ctx context.Context, input *NewAssemblyOrder) (*AssemblyOrder, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	db := config.GetDB()
+	tx := db.Begin()
+	ao, err := createAssemblyOrder(ctx, tx, businessId, input, 0)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return ao, nil
+}
+
+func ConfirmAssemblyOrder(ctx context.Context, id int) (*AssemblyOrder, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	ao, err := utils.FetchModel[AssemblyOrder](ctx, businessId, id, "Details")
+	if err != nil {
+		return nil, err
+	}
+	if ao.CurrentStatus != AssemblyOrderStatusDraft {
+		return nil, errors.New("only draft assembly orders can be confirmed")
+	}
+
+	db := config.GetDB()
+	tx := db.Begin()
+	if err := confirmAssemblyOrder(ctx, tx, ao); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return ao, nil
+}
+
+// deleteAssemblyOrder removes an order and, when it was confirmed, reverses its stock
+// summaries and ledger postings.
+func deleteAssemblyOrder(ctx context.Context, tx *gorm.DB, ao *AssemblyOrder) error {
+	if ao.CurrentStatus == AssemblyOrderStatusConfirmed {
+		if err := ValidateTransactionLock(ctx, ao.OrderDate, ao.BusinessId, AccountantTransactionLock); err != nil {
+			return err
+		}
+		if err := applyAssemblyOrderStock(tx.WithContext(ctx), ao, true); err != nil {
+			return err
+		}
+	}
+
+	oldForMsg := *ao
+	oldForMsg.Details = append([]AssemblyOrderDetail(nil), ao.Details...)
+	if err := tx.WithContext(ctx).Where("assembly_order_id = ?", ao.ID).Delete(&AssemblyOrderDetail{}).Error; err != nil {
+		return err
+	}
+	if err := tx.WithContext(ctx).Delete(ao).Error; err != nil {
+		return err
+	}
+
+	if ao.CurrentStatus == AssemblyOrderStatusConfirmed {
+		return PublishToAccounting(ctx, tx, ao.BusinessId, ao.OrderDate, ao.ID, AccountReferenceTypeAssemblyOrder, nil, &oldForMsg, PubSubMessageActionDelete)
+	}
+	return nil
+}
+
+func DeleteAssemblyOrder(ctx context.Context, id int) (*AssemblyOrder, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	result, err := utils.FetchModel[AssemblyOrder](ctx, businessId, id, "Details")
+	if err != nil {
+		return nil, err
+	}
+	if result.SalesInvoiceId > 0 {
+		return nil, errors.New("assembly order was posted by a sales invoice; change the invoice instead")
+	}
+
+	db := config.GetDB()
+	tx := db.Begin()
+	if err := deleteAssemblyOrder(ctx, tx, result); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func GetAssemblyOrder(ctx context.Context, id int) (*AssemblyOrder, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	return utils.FetchModel[AssemblyOrder](ctx, businessId, id)
+}
+
+func GetAssemblyOrderDetails(ctx context.Context, assemblyOrderId int) ([]*AssemblyOrderDetail, error) {
+	db := config.GetDB()
+	var details []*AssemblyOrderDetail
+	if err := db.WithContext(ctx).Where("assembly_order_id = ?", assemblyOrderId).Order("id").Find(&details).Error; err != nil {
+		return nil, err
+	}
+	return details, nil
+}
+
+func PaginateAssemblyOrder(
+	ctx context.Context, limit *int, after *string,
+	orderNumber *string,
+	orderType *AssemblyOrderType,
+	currentStatus *AssemblyOrderStatus,
+	salesInvoiceId *int,
+) (*AssemblyOrdersConnection, error) {
+
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	db := config.GetDB()
+	dbCtx := db.WithContext(ctx).Where("business_id = ?", businessId)
+
+	if orderNumber != nil && *orderNumber != "" {
+		dbCtx.Where("order_number LIKE ?", "%"+*orderNumber+"%")
+	}
+	if orderType != nil {
+		dbCtx.Where("order_type = ?", *orderType)
+	}
+	if currentStatus != nil {
+		dbCtx.Where("current_status = ?", *currentStatus)
+	}
+	if salesInvoiceId != nil && *salesInvoiceId > 0 {
+		dbCtx.Where("sales_invoice_id = ?", *salesInvoiceId)
+	}
+
+	edges, pageInfo, err := FetchPageCompositeCursor[AssemblyOrder](dbCtx, *limit, after, "created_at", "<")
+	if err != nil {
+		return nil, err
+	}
+	var assemblyOrdersConnection AssemblyOrdersConnection
+	assemblyOrdersConnection.PageInfo = pageInfo
+	for _, edge := range edges {
+		assemblyOrdersEdge := AssemblyOrdersEdge(edge)
+		assemblyOrdersConnection.Edges = append(assemblyOrdersConnection.Edges, &assemblyOrdersEdge)
+	}
+
+	return &assemblyOrdersConnection, err
+}
@@ -0,0 +1,55 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/shopspring/decimal"
+)
+
+func TestComponentQty(t *testing.T) {
+	d := decimal.RequireFromString
+	for _, tc := range []struct {
+		qty, wastage, output string
+		want                 string
+	}{
+		{"2", "0", "5", "10"},
+		{"2", "10", "5", "11"},
+		{"0.3333", "2.5", "3", "1.0249"},
+	} {
+		got := models.ComponentQty(d(tc.qty), d(tc.wastage), d(tc.output))
+		if !got.Equal(d(tc.want)) {
+			t.Errorf("ComponentQty(%s, %s, %s) = %s, want %s", tc.qty, tc.wastage, tc.output, got, tc.want)
+		}
+	}
+}
+
+func TestAllocateAssemblyCost(t *testing.T) {
+	d := decimal.RequireFromString
+	dec := func(values ...string) []decimal.Decimal {
+		result := make([]decimal.Decimal, len(values))
+		for i, v := range values {
+			result[i] = d(v)
+		}
+		return result
+	}
+	for _, tc := range []struct {
+		name    string
+		total   string
+		qtys    []decimal.Decimal
+		weights []decimal.Decimal
+		want    []decimal.Decimal
+	}{
+		{"single finished good", "100", dec("3"), dec("0"), dec("33.3333")},
+		{"by weight", "100", dec("2", "1"), dec("20", "60"), dec("12.5", "75")},
+		{"by quantity without weights", "80", dec("1", "3"), dec("0", "0"), dec("20", "20")},
+		{"zero quantity line", "50", dec("0", "5"), dec("0", "0"), dec("0", "10")},
+	} {
+		got := models.AllocateAssemblyCost(d(tc.total), tc.qtys, tc.weights)
+		for i := range tc.want {
+			if !got[i].Equal(tc.want[i]) {
+				t.Errorf("%s: unit cost %d = %s, want %s", tc.name, i, got[i], tc.want[i])
+			}
+		}
+	}
+}
@@ -28,6 +28,7 @@ func validateReferenceType(ctx context.Context, businessId string, referenceType
 		"credit_notes":          true,
 		"customers":             true,
 		"customer_payments":     true,
+		"estimates":             true,
 		"expenses":              true,
 		"inventory_adjustments": true,
 		"journals":              true,
@@ -0,0 +1,1160 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type BankStatementStatus string
+
+const (
+	BankStatementStatusOpen       BankStatementStatus = "OPEN"
+	BankStatementStatusReconciled BankStatementStatus = "RECONCILED"
+)
+
+type BankStatementLineStatus string
+
+const (
+	BankStatementLineStatusUnmatched BankStatementLineStatus = "UNMATCHED"
+	BankStatementLineStatusMatched   BankStatementLineStatus = "MATCHED"
+	BankStatementLineStatusSplit     BankStatementLineStatus = "SPLIT"
+)
+
+// auto-match only links a line when the best candidate scores at least this much
+const bankMatchAutoThreshold = 70
+
+// candidates further than this from the statement date are never suggested
+const bankMatchDateWindowDays = 7
+
+const maxBankStatementSizeBytes int64 = 5 * 1024 * 1024
+
+// BankStatement is an imported bank statement for a banking account (Bank, Cash or CreditCard account).
+// Lines are matched to BankingTransaction rows; once reconciled, matched transactions are locked.
+type BankStatement struct {
+	ID             int                 `gorm:"primary_key" json:"id"`
+	BusinessId     string              `gorm:"index;not null" json:"business_id"`
+	AccountId      int                 `gorm:"index;not null" json:"account_id"`
+	CurrencyId     int                 `gorm:"not null" json:"currency_id"`
+	FileName       string              `gorm:"size:255" json:"file_name"`
+	Format         BankStatementFormat `gorm:"size:20;not null" json:"format"`
+	StartDate      time.Time           `gorm:"not null" json:"start_date"`
+	EndDate        time.Time           `gorm:"index;not null" json:"end_date"`
+	OpeningBalance decimal.Decimal     `gorm:"type:decimal(20,4);default:0" json:"opening_balance"`
+	ClosingBalance decimal.Decimal     `gorm:"type:decimal(20,4);default:0" json:"closing_balance"`
+	Status         BankStatementStatus `gorm:"size:20;not null;default:'OPEN';index" json:"status"`
+	ReconciledAt   *time.Time          `gorm:"default:null" json:"reconciled_at"`
+	Lines          []BankStatementLine `gorm:"foreignKey:BankStatementId" json:"lines"`
+	CreatedAt      time.Time           `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time           `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// BankStatementLine is one entry of a statement. Amount is signed: positive = money in, negative = money out.
+// A split line keeps its original amount and gets child lines (ParentLineId) which are matched instead.
+type BankStatementLine struct {
+	ID              int                     `gorm:"primary_key" json:"id"`
+	BusinessId      string                  `gorm:"index;not null" json:"business_id"`
+	BankStatementId int                     `gorm:"index;not null" json:"bank_statement_id"`
+	AccountId       int                     `gorm:"index;not null" json:"account_id"`
+	ParentLineId    int                     `gorm:"index;default:0" json:"parent_line_id"`
+	TransactionDate time.Time               `gorm:"not null" json:"transaction_date"`
+	Amount          decimal.Decimal         `gorm:"type:decimal(20,4);default:0" json:"amount"`
+	Description     string                  `gorm:"type:text" json:"description"`
+	ReferenceNumber string                  `gorm:"size:255" json:"reference_number"`
+	ExternalId      string                  `gorm:"size:255;index" json:"external_id"`
+	Status          BankStatementLineStatus `gorm:"size:20;not null;default:'UNMATCHED';index" json:"status"`
+	MatchConfidence int                     `gorm:"default:0" json:"match_confidence"`
+	Matches         []BankStatementMatch    `gorm:"foreignKey:BankStatementLineId" json:"matches"`
+	CreatedAt       time.Time               `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time               `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// BankStatementMatch links a statement line to the banking transaction that clears it.
+// Banking transactions posted by workflows are recreated when their source document changes,
+// so the source (TransactionType, TransactionId) is kept alongside BankingTransactionId and
+// either one identifies the matched transaction.
+type BankStatementMatch struct {
+	ID                   int                    `gorm:"primary_key" json:"id"`
+	BusinessId           string                 `gorm:"index;not null" json:"business_id"`
+	BankStatementId      int                    `gorm:"index;not null" json:"bank_statement_id"`
+	BankStatementLineId  int                    `gorm:"index;not null" json:"bank_statement_line_id"`
+	AccountId            int                    `gorm:"index;not null" json:"account_id"`
+	BankingTransactionId int                    `gorm:"index;default:0" json:"banking_transaction_id"`
+	TransactionType      BankingTransactionType `gorm:"size:50;index:idx_bank_statement_match_source" json:"transaction_type"`
+	TransactionId        int                    `gorm:"index:idx_bank_statement_match_source;default:0" json:"transaction_id"`
+	Amount               decimal.Decimal        `gorm:"type:decimal(20,4);default:0" json:"amount"`
+	Confidence           int                    `gorm:"default:0" json:"confidence"`
+	IsAutoMatched        bool                   `gorm:"not null;default:false" json:"is_auto_matched"`
+	CreatedAt            time.Time              `gorm:"autoCreateTime" json:"created_at"`
+}
+
+type NewBankStatementExpense struct {
+	BranchId         int      `json:"branch_id"`
+	ExpenseAccountId int      `json:"expense_account_id"`
+	SupplierId       int      `json:"supplier_id"`
+	CustomerId       int      `json:"customer_id"`
+	ExpenseTaxId     int      `json:"expense_tax_id"`
+	ExpenseTaxType   *TaxType `json:"expense_tax_type"`
+	Notes            string   `json:"notes"`
+}
+
+type NewBankStatementDeposit struct {
+	BranchId        int                    `json:"branch_id"`
+	TransactionType BankingTransactionType `json:"transaction_type"`
+	FromAccountId   int                    `json:"from_account_id"`
+	CustomerId      int                    `json:"customer_id"`
+	Description     string                 `json:"description"`
+}
+
+type BankReconciliationSummary struct {
+	AccountId             int                   `json:"account_id"`
+	StartDate             time.Time             `json:"start_date"`
+	EndDate               time.Time             `json:"end_date"`
+	StatementBalance      decimal.Decimal       `json:"statement_balance"`
+	BookBalance           decimal.Decimal       `json:"book_balance"`
+	UnclearedDeposits     decimal.Decimal       `json:"uncleared_deposits"`
+	UnclearedPayments     decimal.Decimal       `json:"uncleared_payments"`
+	AdjustedBookBalance   decimal.Decimal       `json:"adjusted_book_balance"`
+	Difference            decimal.Decimal       `json:"difference"`
+	UnmatchedLineCount    int                   `json:"unmatched_line_count"`
+	UnmatchedLineAmount   decimal.Decimal       `json:"unmatched_line_amount"`
+	IsReconciled          bool                  `json:"is_reconciled"`
+	UnclearedTransactions []*BankingTransaction `json:"uncleared_transactions"`
+}
+
+type BankStatementsConnection struct {
+	Edges    []*BankStatementsEdge `json:"edges"`
+	PageInfo *PageInfo             `json:"pageInfo"`
+}
+
+type BankStatementsEdge Edge[BankStatement]
+
+func (obj BankStatement) GetId() int {
+	return obj.ID
+}
+
+// returns decoded curosr string
+func (bs BankStatement) GetCursor() string {
+	return bs.CreatedAt.String()
+}
+
+func (line BankStatementLine) GetId() int {
+	return line.ID
+}
+
+func (bs BankStatement) isReconciled() bool {
+	return bs.Status == BankStatementStatusReconciled
+}
+
+// bankMatchCandidate is a banking transaction seen from the statement account:
+// Amount is positive when the account received money, negative when it paid out.
+type bankMatchCandidate struct {
+	BankingTransactionId int
+	TransactionType      BankingTransactionType
+	TransactionId        int
+	TransactionDate      time.Time
+	Amount               decimal.Decimal
+	ReferenceNumber      string
+	TransactionNumber    string
+}
+
+type bankMatchPair struct {
+	LineIndex      int
+	CandidateIndex int
+	Score          int
+}
+
+func normalizeBankReference(value string) string {
+	return strings.ToLower(strings.Join(strings.Fields(value), ""))
+}
+
+// scoreBankStatementMatch rates how likely the candidate is the transaction behind the line (0-100).
+// The amount must match exactly; the date and the reference add confidence on top.
+func scoreBankStatementMatch(line BankStatementLine, candidate bankMatchCandidate) int {
+	if !line.Amount.Equal(candidate.Amount) {
+		return 0
+	}
+	days := int(line.TransactionDate.Sub(candidate.TransactionDate).Hours() / 24)
+	if days < 0 {
+		days = -days
+	}
+	if days > bankMatchDateWindowDays {
+		return 0
+	}
+
+	score := 50
+	switch {
+	case days == 0:
+		score += 30
+	case days <= 3:
+		score += 20
+	default:
+		score += 10
+	}
+
+	lineRef := normalizeBankReference(line.ReferenceNumber)
+	lineText := normalizeBankReference(line.Description) + lineRef
+	for _, ref := range []string{candidate.ReferenceNumber, candidate.TransactionNumber} {
+		ref = normalizeBankReference(ref)
+		if ref == "" {
+			continue
+		}
+		if ref == lineRef {
+			score += 20
+			break
+		}
+		if strings.Contains(lineText, ref) {
+			score += 10
+			break
+		}
+	}
+	if score > 100 {
+		score = 100
+	}
+	return score
+}
+
+// pickBankStatementMatches pairs lines with candidates, best score first,
+// using each line and each candidate at most once.
+func pickBankStatementMatches(lines []BankStatementLine, candidates []bankMatchCandidate, threshold int) []bankMatchPair {
+	var pairs []bankMatchPair
+	for i, line := range lines {
+		for j, candidate := range candidates {
+			if score := scoreBankStatementMatch(line, candidate); score >= threshold {
+				pairs = append(pairs, bankMatchPair{LineIndex: i, CandidateIndex: j, Score: score})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(a, b int) bool {
+		if pairs[a].Score != pairs[b].Score {
+			return pairs[a].Score > pairs[b].Score
+		}
+		return lines[pairs[a].LineIndex].TransactionDate.Before(lines[pairs[b].LineIndex].TransactionDate)
+	})
+
+	usedLines := make(map[int]bool)
+	usedCandidates := make(map[int]bool)
+	var picked []bankMatchPair
+	for _, pair := range pairs {
+		if usedLines[pair.LineIndex] || usedCandidates[pair.CandidateIndex] {
+			continue
+		}
+		usedLines[pair.LineIndex] = true
+		usedCandidates[pair.CandidateIndex] = true
+		picked = append(picked, pair)
+	}
+	return picked
+}
+
+// unmatchedBankingTransactionsQuery selects banking transactions of the account not yet matched to any statement line.
+func unmatchedBankingTransactionsQuery(db *gorm.DB, businessId string, accountId int) *gorm.DB {
+	return db.Model(&BankingTransaction{}).
+		Where("banking_transactions.business_id = ?", businessId).
+		Where("(banking_transactions.from_account_id = ? OR banking_transactions.to_account_id = ?)", accountId, accountId).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM bank_statement_matches m
+			WHERE m.business_id = banking_transactions.business_id
+				AND m.account_id = ?
+				AND (m.banking_transaction_id = banking_transactions.id
+					OR (m.transaction_id > 0
+						AND m.transaction_id = banking_transactions.transaction_id
+						AND m.transaction_type = banking_transactions.transaction_type))
+		)`, accountId)
+}
+
+func toBankMatchCandidate(bt BankingTransaction, accountId int) bankMatchCandidate {
+	amount := bt.FromAccountAmount.Neg()
+	if bt.ToAccountId == accountId {
+		amount = bt.ToAccountAmount
+	}
+	return bankMatchCandidate{
+		BankingTransactionId: bt.ID,
+		TransactionType:      bt.TransactionType,
+		TransactionId:        bt.TransactionId,
+		TransactionDate:      bt.TransactionDate,
+		Amount:               amount,
+		ReferenceNumber:      bt.ReferenceNumber,
+		TransactionNumber:    bt.TransactionNumber,
+	}
+}
+
+func (m BankStatementMatch) sourceCondition(db *gorm.DB) *gorm.DB {
+	if m.TransactionId > 0 {
+		return db.Where("transaction_id = ? AND transaction_type = ?", m.TransactionId, m.TransactionType)
+	}
+	return db.Where("id = ?", m.BankingTransactionId)
+}
+
+// validateBankReconciliationLock rejects changes to a transaction cleared on a reconciled bank statement.
+func validateBankReconciliationLock(ctx context.Context, businessId string, bankingTransactionId int, transactionType BankingTransactionType, transactionId int) error {
+	if bankingTransactionId <= 0 && transactionId <= 0 {
+		return nil
+	}
+	db := config.GetDB()
+	dbCtx := db.WithContext(ctx).Table("bank_statement_matches AS m").
+		Joins("JOIN bank_statements AS s ON s.id = m.bank_statement_id").
+		Where("m.business_id = ? AND s.status = ?", businessId, BankStatementStatusReconciled)
+	switch {
+	case bankingTransactionId > 0 && transactionId > 0:
+		dbCtx.Where("(m.banking_transaction_id = ? OR (m.transaction_id = ? AND m.transaction_type = ?))", bankingTransactionId, transactionId, transactionType)
+	case bankingTransactionId > 0:
+		dbCtx.Where("m.banking_transaction_id = ?", bankingTransactionId)
+	default:
+		dbCtx.Where("m.transaction_id = ? AND m.transaction_type = ?", transactionId, transactionType)
+	}
+	var count int64
+	if err := dbCtx.Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("transaction has been reconciled")
+	}
+	return nil
+}
+
+func fetchBankingAccount(ctx context.Context, businessId string, accountId int) (*Account, error) {
+	account, err := utils.FetchModel[Account](ctx, businessId, accountId)
+	if err != nil {
+		return nil, errors.New("account not found")
+	}
+	if account.DetailType != AccountDetailTypeBank &&
+		account.DetailType != AccountDetailTypeCash &&
+		account.DetailType != AccountDetailTypeCreditCard {
+		return nil, errors.New("account is not a banking account")
+	}
+	return account, nil
+}
+
+// fetchOpenBankStatementLine returns the line and its statement, failing if the statement is reconciled.
+func fetchOpenBankStatementLine(ctx context.Context, businessId string, lineId int) (*BankStatementLine, *BankStatement, error) {
+	line, err := utils.FetchModel[BankStatementLine](ctx, businessId, lineId)
+	if err != nil {
+		return nil, nil, err
+	}
+	statement, err := utils.FetchModel[BankStatement](ctx, businessId, line.BankStatementId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if statement.isReconciled() {
+		return nil, nil, errors.New("bank statement has been reconciled")
+	}
+	return line, statement, nil
+}
+
+func ImportBankStatement(ctx context.Context, accountId int, file graphql.Upload, format *BankStatementFormat) (*BankStatement, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if file.File == nil {
+		return nil, errors.New("nil file provided")
+	}
+	account, err := fetchBankingAccount(ctx, businessId, accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file.File, maxBankStatementSizeBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBankStatementSizeBytes {
+		return nil, errors.New("statement file is too large")
+	}
+
+	var statementFormat BankStatementFormat
+	if format != nil && *format != "" {
+		statementFormat = *format
+	} else if statementFormat, err = detectBankStatementFormat(file.Filename, data); err != nil {
+		return nil, err
+	}
+	parsed, err := ParseBankStatement(statementFormat, data)
+	if err != nil {
+		return nil, err
+	}
+
+	business, err := GetBusinessById(ctx, businessId)
+	if err != nil {
+		return nil, err
+	}
+	currencyId := account.CurrencyId
+	if currencyId == 0 {
+		currencyId = business.BaseCurrencyId
+	}
+	// statement dates are calendar dates of the business, store them like other transaction dates
+	toBusinessDate := func(date time.Time) (time.Time, error) {
+		businessDate := MyDateString(date)
+		if err := businessDate.StartOfDayUTCTime(business.Timezone); err != nil {
+			return date, err
+		}
+		return time.Time(businessDate), nil
+	}
+
+	// lines already imported for this account (same bank id) are skipped so overlapping files can be uploaded
+	var existingIds []string
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Model(&BankStatementLine{}).
+		Where("business_id = ? AND account_id = ? AND external_id <> ''", businessId, accountId).
+		Pluck("external_id", &existingIds).Error; err != nil {
+		return nil, err
+	}
+	imported := make(map[string]bool, len(existingIds))
+	for _, id := range existingIds {
+		imported[id] = true
+	}
+
+	statement := BankStatement{
+		BusinessId: businessId,
+		AccountId:  accountId,
+		CurrencyId: currencyId,
+		FileName:   file.Filename,
+		Format:     statementFormat,
+		Status:     BankStatementStatusOpen,
+	}
+	if statement.StartDate, err = toBusinessDate(*parsed.StartDate); err != nil {
+		return nil, err
+	}
+	if statement.EndDate, err = toBusinessDate(*parsed.EndDate); err != nil {
+		return nil, err
+	}
+	total := decimal.Zero
+	skipped := 0
+	for _, line := range parsed.Lines {
+		if line.ExternalId != "" && imported[line.ExternalId] {
+			skipped++
+			continue
+		}
+		imported[line.ExternalId] = line.ExternalId != ""
+		transactionDate, err := toBusinessDate(line.TransactionDate)
+		if err != nil {
+			return nil, err
+		}
+		total = total.Add(line.Amount)
+		statement.Lines = append(statement.Lines, BankStatementLine{
+			BusinessId:      businessId,
+			AccountId:       accountId,
+			TransactionDate: transactionDate,
+			Amount:          line.Amount,
+			Description:     line.Description,
+			ReferenceNumber: line.ReferenceNumber,
+			ExternalId:      line.ExternalId,
+			Status:          BankStatementLineStatusUnmatched,
+		})
+	}
+	if len(statement.Lines) == 0 {
+		return nil, errors.New("all statement lines have already been imported")
+	}
+	switch {
+	case parsed.OpeningBalance != nil:
+		statement.OpeningBalance = *parsed.OpeningBalance
+		statement.ClosingBalance = statement.OpeningBalance.Add(total)
+	case parsed.ClosingBalance != nil:
+		statement.ClosingBalance = *parsed.ClosingBalance
+		statement.OpeningBalance = statement.ClosingBalance.Sub(total)
+	default:
+		// continue from the previous statement of the account
+		var previous BankStatement
+		if err := db.WithContext(ctx).
+			Where("business_id = ? AND account_id = ? AND end_date <= ?", businessId, accountId, statement.StartDate).
+			Order("end_date DESC").Limit(1).Find(&previous).Error; err != nil {
+			return nil, err
+		}
+		statement.OpeningBalance = previous.ClosingBalance
+		statement.ClosingBalance = previous.ClosingBalance.Add(total)
+	}
+	if skipped == 0 && parsed.ClosingBalance != nil && parsed.OpeningBalance != nil && !statement.ClosingBalance.Equal(*parsed.ClosingBalance) {
+		return nil, fmt.Errorf("statement lines do not add up to the closing balance %s", parsed.ClosingBalance.String())
+	}
+
+	if err := db.WithContext(ctx).Create(&statement).Error; err != nil {
+		return nil, err
+	}
+	if _, err := AutoMatchBankStatement(ctx, statement.ID); err != nil {
+		return nil, err
+	}
+	return GetBankStatement(ctx, statement.ID)
+}
+
+// AutoMatchBankStatement links unmatched lines to unmatched banking transactions of the account
+// whose amount is equal and date/reference are close enough. Returns the number of lines matched.
+func AutoMatchBankStatement(ctx context.Context, id int) (int, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return 0, errors.New("business id is required")
+	}
+	statement, err := utils.FetchModel[BankStatement](ctx, businessId, id)
+	if err != nil {
+		return 0, err
+	}
+	if statement.isReconciled() {
+		return 0, errors.New("bank statement has been reconciled")
+	}
+
+	db := config.GetDB()
+	var lines []BankStatementLine
+	if err := db.WithContext(ctx).
+		Where("business_id = ? AND bank_statement_id = ? AND status = ?", businessId, id, BankStatementLineStatusUnmatched).
+		Order("transaction_date, id").Find(&lines).Error; err != nil {
+		return 0, err
+	}
+	if len(lines) == 0 {
+		return 0, nil
+	}
+
+	window := time.Duration(bankMatchDateWindowDays) * 24 * time.Hour
+	var transactions []BankingTransaction
+	if err := unmatchedBankingTransactionsQuery(db.WithContext(ctx), businessId, statement.AccountId).
+		Where("banking_transactions.transaction_date BETWEEN ? AND ?", statement.StartDate.Add(-window), statement.EndDate.Add(window+24*time.Hour)).
+		Find(&transactions).Error; err != nil {
+		return 0, err
+	}
+	candidates := make([]bankMatchCandidate, 0, len(transactions))
+	for _, bt := range transactions {
+		candidates = append(candidates, toBankMatchCandidate(bt, statement.AccountId))
+	}
+
+	pairs := pickBankStatementMatches(lines, candidates, bankMatchAutoThreshold)
+	if len(pairs) == 0 {
+		return 0, nil
+	}
+
+	tx := db.Begin()
+	for _, pair := range pairs {
+		line := lines[pair.LineIndex]
+		candidate := candidates[pair.CandidateIndex]
+		match := BankStatementMatch{
+			BankingTransactionId: candidate.BankingTransactionId,
+			TransactionType:      candidate.TransactionType,
+			TransactionId:        candidate.TransactionId,
+			Amount:               candidate.Amount,
+			Confidence:           pair.Score,
+			IsAutoMatched:        true,
+		}
+		if err := saveBankStatementMatches(ctx, tx, &line, pair.Score, match); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+	return len(pairs), tx.Commit().Error
+}
+
+func saveBankStatementMatches(ctx context.Context, tx *gorm.DB, line *BankStatementLine, confidence int, matches ...BankStatementMatch) error {
+	for i := range matches {
+		matches[i].BusinessId = line.BusinessId
+		matches[i].BankStatementId = line.BankStatementId
+		matches[i].BankStatementLineId = line.ID
+		matches[i].AccountId = line.AccountId
+	}
+	if err := tx.WithContext(ctx).Create(&matches).Error; err != nil {
+		return err
+	}
+	line.Status = BankStatementLineStatusMatched
+	line.MatchConfidence = confidence
+	return tx.WithContext(ctx).Model(line).Updates(map[string]interface{}{
+		"Status":          line.Status,
+		"MatchConfidence": line.MatchConfidence,
+	}).Error
+}
+
+// SuggestBankStatementMatches lists unmatched banking transactions that could clear the line, best first.
+func SuggestBankStatementMatches(ctx context.Context, lineId int) ([]*BankingTransaction, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	line, err := utils.FetchModel[BankStatementLine](ctx, businessId, lineId)
+	if err != nil {
+		return nil, err
+	}
+
+	window := time.Duration(bankMatchDateWindowDays) * 24 * time.Hour
+	db := config.GetDB()
+	var transactions []*BankingTransaction
+	if err := unmatchedBankingTransactionsQuery(db.WithContext(ctx), businessId, line.AccountId).
+		Where("banking_transactions.transaction_date BETWEEN ? AND ?", line.TransactionDate.Add(-window), line.TransactionDate.Add(window+24*time.Hour)).
+		Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+	scores := make(map[int]int, len(transactions))
+	results := make([]*BankingTransaction, 0, len(transactions))
+	for _, bt := range transactions {
+		if score := scoreBankStatementMatch(*line, toBankMatchCandidate(*bt, line.AccountId)); score > 0 {
+			scores[bt.ID] = score
+			results = append(results, bt)
+		}
+	}
+	sort.SliceStable(results, func(a, b int) bool {
+		return scores[results[a].ID] > scores[results[b].ID]
+	})
+	return results, nil
+}
+
+// MatchBankStatementLine manually clears the line with one or more banking transactions;
+// their total must equal the line amount.
+func MatchBankStatementLine(ctx context.Context, lineId int, bankingTransactionIds []int) (*BankStatementLine, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if len(bankingTransactionIds) == 0 {
+		return nil, errors.New("at least one banking transaction is required")
+	}
+	line, _, err := fetchOpenBankStatementLine(ctx, businessId, lineId)
+	if err != nil {
+		return nil, err
+	}
+	if line.Status != BankStatementLineStatusUnmatched {
+		return nil, errors.New("statement line is already matched or split")
+	}
+
+	db := config.GetDB()
+	var transactions []BankingTransaction
+	if err := unmatchedBankingTransactionsQuery(db.WithContext(ctx), businessId, line.AccountId).
+		Where("banking_transactions.id IN ?", bankingTransactionIds).
+		Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+	if len(transactions) != len(bankingTransactionIds) {
+		return nil, errors.New("banking transaction not found or already matched")
+	}
+
+	total := decimal.Zero
+	matches := make([]BankStatementMatch, 0, len(transactions))
+	for _, bt := range transactions {
+		candidate := toBankMatchCandidate(bt, line.AccountId)
+		total = total.Add(candidate.Amount)
+		matches = append(matches, BankStatementMatch{
+			BankingTransactionId: candidate.BankingTransactionId,
+			TransactionType:      candidate.TransactionType,
+			TransactionId:        candidate.TransactionId,
+			Amount:               candidate.Amount,
+			Confidence:           100,
+		})
+	}
+	if !total.Equal(line.Amount) {
+		return nil, fmt.Errorf("matched amount %s does not equal statement amount %s", total.String(), line.Amount.String())
+	}
+
+	tx := db.Begin()
+	if err := saveBankStatementMatches(ctx, tx, line, 100, matches...); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return line, tx.Commit().Error
+}
+
+// UnmatchBankStatementLine removes the line's matches. For a split line, the parts are removed
+// (none of them may be matched) and the original line becomes unmatched again.
+func UnmatchBankStatementLine(ctx context.Context, lineId int) (*BankStatementLine, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	line, _, err := fetchOpenBankStatementLine(ctx, businessId, lineId)
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	tx := db.Begin()
+	switch line.Status {
+	case BankStatementLineStatusMatched:
+		if err := tx.WithContext(ctx).Where("bank_statement_line_id = ?", line.ID).Delete(&BankStatementMatch{}).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	case BankStatementLineStatusSplit:
+		var matched int64
+		if err := tx.WithContext(ctx).Model(&BankStatementLine{}).
+			Where("parent_line_id = ? AND status <> ?", line.ID, BankStatementLineStatusUnmatched).
+			Count(&matched).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if matched > 0 {
+			tx.Rollback()
+			return nil, errors.New("unmatch the split parts first")
+		}
+		if err := tx.WithContext(ctx).Where("parent_line_id = ?", line.ID).Delete(&BankStatementLine{}).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	default:
+		tx.Rollback()
+		return line, nil
+	}
+
+	line.Status = BankStatementLineStatusUnmatched
+	line.MatchConfidence = 0
+	if err := tx.WithContext(ctx).Model(line).Updates(map[string]interface{}{
+		"Status":          line.Status,
+		"MatchConfidence": line.MatchConfidence,
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return line, tx.Commit().Error
+}
+
+// SplitBankStatementLine divides an unmatched line into parts that are matched separately,
+// e.g. one bank deposit covering several customer payments.
+func SplitBankStatementLine(ctx context.Context, lineId int, amounts []*decimal.Decimal) ([]*BankStatementLine, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if len(amounts) < 2 {
+		return nil, errors.New("a line must be split into at least two parts")
+	}
+	line, _, err := fetchOpenBankStatementLine(ctx, businessId, lineId)
+	if err != nil {
+		return nil, err
+	}
+	if line.Status != BankStatementLineStatusUnmatched {
+		return nil, errors.New("only unmatched lines can be split")
+	}
+
+	total := decimal.Zero
+	parts := make([]*BankStatementLine, 0, len(amounts))
+	for _, amount := range amounts {
+		if amount == nil || amount.IsZero() || amount.IsNegative() != line.Amount.IsNegative() {
+			return nil, errors.New("split amounts must be non-zero and have the same sign as the line")
+		}
+		total = total.Add(*amount)
+		parts = append(parts, &BankStatementLine{
+			BusinessId:      businessId,
+			BankStatementId: line.BankStatementId,
+			AccountId:       line.AccountId,
+			ParentLineId:    line.ID,
+			TransactionDate: line.TransactionDate,
+			Amount:          *amount,
+			Description:     line.Description,
+			ReferenceNumber: line.ReferenceNumber,
+			Status:          BankStatementLineStatusUnmatched,
+		})
+	}
+	if !total.Equal(line.Amount) {
+		return nil, fmt.Errorf("split amounts add up to %s instead of %s", total.String(), line.Amount.String())
+	}
+
+	db := config.GetDB()
+	tx := db.Begin()
+	if err := tx.WithContext(ctx).Create(&parts).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.WithContext(ctx).Model(line).Update("Status", BankStatementLineStatusSplit).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return parts, tx.Commit().Error
+}
+
+// CreateExpenseFromBankStatementLine records an unmatched withdrawal as an expense paid from the statement account
+// and matches the line to it. The line amount is the total paid, so any tax is treated as inclusive.
+func CreateExpenseFromBankStatementLine(ctx context.Context, lineId int, input *NewBankStatementExpense) (*Expense, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	line, statement, err := fetchOpenBankStatementLine(ctx, businessId, lineId)
+	if err != nil {
+		return nil, err
+	}
+	if line.Status != BankStatementLineStatusUnmatched {
+		return nil, errors.New("statement line is already matched or split")
+	}
+	if !line.Amount.IsNegative() {
+		return nil, errors.New("only withdrawals can be recorded as expenses")
+	}
+
+	exchangeRate, err := GetExchangeRateAsOf(ctx, businessId, statement.CurrencyId, line.TransactionDate)
+	if err != nil {
+		return nil, err
+	}
+	notes := input.Notes
+	if notes == "" {
+		notes = line.Description
+	}
+	expense, err := CreateExpense(ctx, &NewExpense{
+		ExpenseAccountId: input.ExpenseAccountId,
+		AssetAccountId:   statement.AccountId,
+		BranchId:         input.BranchId,
+		ExpenseDate:      line.TransactionDate,
+		CurrencyId:       statement.CurrencyId,
+		ExchangeRate:     exchangeRate,
+		Amount:           line.Amount.Abs(),
+		SupplierId:       input.SupplierId,
+		CustomerId:       input.CustomerId,
+		ReferenceNumber:  line.ReferenceNumber,
+		Notes:            notes,
+		ExpenseTaxId:     input.ExpenseTaxId,
+		ExpenseTaxType:   input.ExpenseTaxType,
+		IsTaxInclusive:   utils.NewTrue(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the banking transaction is posted by the expense workflow, match on the source document
+	db := config.GetDB()
+	tx := db.Begin()
+	if err := saveBankStatementMatches(ctx, tx, line, 100, BankStatementMatch{
+		TransactionType: BankingTransactionTypeExpense,
+		TransactionId:   expense.ID,
+		Amount:          line.Amount,
+		Confidence:      100,
+	}); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return expense, tx.Commit().Error
+}
+
+// CreateDepositFromBankStatementLine records an unmatched deposit as a banking transaction into the statement account
+// and matches the line to it.
+func CreateDepositFromBankStatementLine(ctx context.Context, lineId int, input *NewBankStatementDeposit) (*BankingTransaction, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	line, statement, err := fetchOpenBankStatementLine(ctx, businessId, lineId)
+	if err != nil {
+		return nil, err
+	}
+	if line.Status != BankStatementLineStatusUnmatched {
+		return nil, errors.New("statement line is already matched or split")
+	}
+	if !line.Amount.IsPositive() {
+		return nil, errors.New("only deposits can be recorded as deposits")
+	}
+	switch input.TransactionType {
+	case BankingTransactionTypeOtherIncome,
+		BankingTransactionTypeInterestIncome,
+		BankingTransactionTypeOwnerContribution,
+		BankingTransactionTypeDepositFromOtherAccounts:
+	default:
+		return nil, errors.New("invalid deposit transaction type")
+	}
+
+	exchangeRate, err := GetExchangeRateAsOf(ctx, businessId, statement.CurrencyId, line.TransactionDate)
+	if err != nil {
+		return nil, err
+	}
+	description := input.Description
+	if description == "" {
+		description = line.Description
+	}
+	bankingTransaction, err := CreateBankingTransaction(ctx, &NewBankingTransaction{
+		BranchId:        input.BranchId,
+		TransactionDate: line.TransactionDate,
+		Amount:          line.Amount,
+		ReferenceNumber: line.ReferenceNumber,
+		Description:     description,
+		TransactionType: input.TransactionType,
+		FromAccountId:   input.FromAccountId,
+		ToAccountId:     statement.AccountId,
+		CurrencyId:      statement.CurrencyId,
+		ExchangeRate:    exchangeRate,
+		CustomerId:      input.CustomerId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	tx := db.Begin()
+	if err := saveBankStatementMatches(ctx, tx, line, 100, BankStatementMatch{
+		BankingTransactionId: bankingTransaction.ID,
+		TransactionType:      bankingTransaction.TransactionType,
+		TransactionId:        bankingTransaction.TransactionId,
+		Amount:               bankingTransaction.ToAccountAmount,
+		Confidence:           100,
+	}); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return bankingTransaction, tx.Commit().Error
+}
+
+// ReconcileBankStatement closes the statement once every line is matched and the lines add up
+// to the closing balance. Matched transactions can no longer be edited or deleted.
+func ReconcileBankStatement(ctx context.Context, id int) (*BankStatement, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	statement, err := utils.FetchModel[BankStatement](ctx, businessId, id, "Lines")
+	if err != nil {
+		return nil, err
+	}
+	if statement.isReconciled() {
+		return nil, errors.New("bank statement has already been reconciled")
+	}
+
+	total := decimal.Zero
+	for _, line := range statement.Lines {
+		if line.ParentLineId == 0 {
+			total = total.Add(line.Amount)
+		}
+		if line.Status == BankStatementLineStatusUnmatched {
+			return nil, fmt.Errorf("statement line %s %s is not matched", line.TransactionDate.Format("2006-01-02"), line.Amount.String())
+		}
+	}
+	if !statement.OpeningBalance.Add(total).Equal(statement.ClosingBalance) {
+		return nil, errors.New("statement lines do not add up to the closing balance")
+	}
+
+	// banking transactions may have been recreated since matching, make sure each match still points at one
+	db := config.GetDB()
+	var matches []BankStatementMatch
+	if err := db.WithContext(ctx).Where("business_id = ? AND bank_statement_id = ?", businessId, id).Find(&matches).Error; err != nil {
+		return nil, err
+	}
+	for _, match := range matches {
+		var count int64
+		if err := match.sourceCondition(db.WithContext(ctx).Model(&BankingTransaction{}).Where("business_id = ?", businessId)).
+			Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count == 0 && match.BankingTransactionId > 0 {
+			return nil, errors.New("a matched banking transaction no longer exists, unmatch and match the line again")
+		}
+	}
+
+	now := time.Now().UTC()
+	if err := db.WithContext(ctx).Model(statement).Updates(map[string]interface{}{
+		"Status":       BankStatementStatusReconciled,
+		"ReconciledAt": now,
+	}).Error; err != nil {
+		return nil, err
+	}
+	statement.Status = BankStatementStatusReconciled
+	statement.ReconciledAt = &now
+	return statement, nil
+}
+
+// UndoBankStatementReconciliation reopens the statement and unlocks its transactions,
+// unless the statement period falls within the banking transaction lock date.
+func UndoBankStatementReconciliation(ctx context.Context, id int) (*BankStatement, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	statement, err := utils.FetchModel[BankStatement](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+	if !statement.isReconciled() {
+		return statement, nil
+	}
+	if err := validateTransactionLock(ctx, statement.EndDate, businessId, BankingTransactionLock); err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Model(statement).Updates(map[string]interface{}{
+		"Status":       BankStatementStatusOpen,
+		"ReconciledAt": nil,
+	}).Error; err != nil {
+		return nil, err
+	}
+	statement.Status = BankStatementStatusOpen
+	statement.ReconciledAt = nil
+	return statement, nil
+}
+
+func DeleteBankStatement(ctx context.Context, id int) (*BankStatement, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	result, err := utils.FetchModel[BankStatement](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+	if result.isReconciled() {
+		return nil, errors.New("reconciled bank statement cannot be deleted")
+	}
+
+	db := config.GetDB()
+	tx := db.Begin()
+	if err := tx.WithContext(ctx).Where("bank_statement_id = ?", id).Delete(&BankStatementMatch{}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.WithContext(ctx).Where("bank_statement_id = ?", id).Delete(&BankStatementLine{}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.WithContext(ctx).Delete(result).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return result, tx.Commit().Error
+}
+
+func GetBankStatement(ctx context.Context, id int) (*BankStatement, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	return utils.FetchModel[BankStatement](ctx, businessId, id)
+}
+
+func GetBankStatementLines(ctx context.Context, statementId int, status *BankStatementLineStatus) ([]*BankStatementLine, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	db := config.GetDB()
+	dbCtx := db.WithContext(ctx).Where("business_id = ? AND bank_statement_id = ?", businessId, statementId)
+	if status != nil && *status != "" {
+		dbCtx.Where("status = ?", *status)
+	}
+	var results []*BankStatementLine
+	if err := dbCtx.Order("transaction_date, id").Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func PaginateBankStatement(ctx context.Context, limit *int, after *string, accountId *int, status *BankStatementStatus) (*BankStatementsConnection, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	db := config.GetDB()
+	dbCtx := db.WithContext(ctx).Where("business_id = ?", businessId)
+	if accountId != nil && *accountId > 0 {
+		dbCtx.Where("account_id = ?", *accountId)
+	}
+	if status != nil && *status != "" {
+		dbCtx.Where("status = ?", *status)
+	}
+
+	edges, pageInfo, err := FetchPageCompositeCursor[BankStatement](dbCtx, *limit, after, "created_at", "<")
+	if err != nil {
+		return nil, err
+	}
+	var bankStatementsConnection BankStatementsConnection
+	bankStatementsConnection.PageInfo = pageInfo
+	for _, edge := range edges {
+		bankStatementsEdge := BankStatementsEdge(edge)
+		bankStatementsConnection.Edges = append(bankStatementsConnection.Edges, &bankStatementsEdge)
+	}
+
+	return &bankStatementsConnection, err
+}
+
+// GetBankReconciliationSummary compares the statement balance with the book balance at the end of the period.
+// Uncleared items are banking transactions of the account up to the period end that no statement line matches;
+// transactions dated before the account's first statement are treated as cleared.
+func GetBankReconciliationSummary(ctx context.Context, accountId int, startDate MyDateString, endDate MyDateString) (*BankReconciliationSummary, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	business, err := GetBusiness(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fetchBankingAccount(ctx, businessId, accountId); err != nil {
+		return nil, err
+	}
+	if err := startDate.StartOfDayUTCTime(business.Timezone); err != nil {
+		return nil, err
+	}
+	if err := endDate.EndOfDayUTCTime(business.Timezone); err != nil {
+		return nil, err
+	}
+	fromDate := time.Time(startDate)
+	toDate := time.Time(endDate)
+
+	summary := BankReconciliationSummary{
+		AccountId: accountId,
+		StartDate: fromDate,
+		EndDate:   toDate,
+	}
+	db := config.GetDB()
+
+	// statement balance: closing balance of the latest statement ending in the period
+	var statements []BankStatement
+	if err := db.WithContext(ctx).
+		Where("business_id = ? AND account_id = ? AND end_date BETWEEN ? AND ?", businessId, accountId, fromDate, toDate).
+		Order("end_date").Find(&statements).Error; err != nil {
+		return nil, err
+	}
+	summary.IsReconciled = len(statements) > 0
+	for _, statement := range statements {
+		summary.StatementBalance = statement.ClosingBalance
+		if !statement.isReconciled() {
+			summary.IsReconciled = false
+		}
+	}
+
+	if err := db.WithContext(ctx).Raw(`
+		SELECT acdb.running_balance
+		FROM account_currency_daily_balances acdb
+		WHERE acdb.business_id = ?
+			AND acdb.branch_id = ?
+			AND acdb.account_id = ?
+			AND acdb.transaction_date <= ?
+		ORDER BY acdb.transaction_date DESC
+		LIMIT 1;
+	`, businessId, 0, accountId, toDate).Scan(&summary.BookBalance).Error; err != nil {
+		return nil, err
+	}
+
+	var firstStatement BankStatement
+	if err := db.WithContext(ctx).
+		Where("business_id = ? AND account_id = ?", businessId, accountId).
+		Order("start_date").Limit(1).Find(&firstStatement).Error; err != nil {
+		return nil, err
+	}
+	if firstStatement.ID > 0 {
+		if err := unmatchedBankingTransactionsQuery(db.WithContext(ctx), businessId, accountId).
+			Where("banking_transactions.transaction_date BETWEEN ? AND ?", firstStatement.StartDate, toDate).
+			Order("banking_transactions.transaction_date").
+			Find(&summary.UnclearedTransactions).Error; err != nil {
+			return nil, err
+		}
+	}
+	for _, bt := range summary.UnclearedTransactions {
+		amount := toBankMatchCandidate(*bt, accountId).Amount
+		if amount.IsPositive() {
+			summary.UnclearedDeposits = summary.UnclearedDeposits.Add(amount)
+		} else {
+			summary.UnclearedPayments = summary.UnclearedPayments.Add(amount.Abs())
+		}
+	}
+	summary.AdjustedBookBalance = summary.BookBalance.Sub(summary.UnclearedDeposits).Add(summary.UnclearedPayments)
+	summary.Difference = summary.StatementBalance.Sub(summary.AdjustedBookBalance)
+
+	var unmatched struct {
+		Count  int
+		Amount decimal.Decimal
+	}
+	if err := db.WithContext(ctx).Model(&BankStatementLine{}).
+		Select("COUNT(*) AS count, COALESCE(SUM(amount), 0) AS amount").
+		Where("business_id = ? AND account_id = ? AND status = ? AND transaction_date BETWEEN ? AND ?",
+			businessId, accountId, BankStatementLineStatusUnmatched, fromDate, toDate).
+		Scan(&unmatched).Error; err != nil {
+		return nil, err
+	}
+	summary.UnmatchedLineCount = unmatched.Count
+	summary.UnmatchedLineAmount = unmatched.Amount
+
+	return &summary, nil
+}
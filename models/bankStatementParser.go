@@ -0,0 +1,493 @@
+package models
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type BankStatementFormat string
+
+const (
+	BankStatementFormatCSV     BankStatementFormat = "CSV"
+	BankStatementFormatOFX     BankStatementFormat = "OFX"
+	BankStatementFormatCAMT053 BankStatementFormat = "CAMT053"
+)
+
+// ParsedBankStatement is the format-independent result of reading a statement file.
+// Line amounts are signed: positive = money in, negative = money out.
+type ParsedBankStatement struct {
+	StartDate      *time.Time
+	EndDate        *time.Time
+	OpeningBalance *decimal.Decimal
+	ClosingBalance *decimal.Decimal
+	Lines          []ParsedBankStatementLine
+}
+
+type ParsedBankStatementLine struct {
+	TransactionDate time.Time
+	Amount          decimal.Decimal
+	Description     string
+	ReferenceNumber string
+	ExternalId      string
+}
+
+// detectBankStatementFormat guesses the format from the file name, then from the content.
+func detectBankStatementFormat(filename string, data []byte) (BankStatementFormat, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return BankStatementFormatCSV, nil
+	case ".ofx", ".qfx":
+		return BankStatementFormatOFX, nil
+	case ".xml", ".053":
+		return BankStatementFormatCAMT053, nil
+	}
+	head := strings.ToUpper(string(data[:min(len(data), 512)]))
+	if strings.Contains(head, "OFXHEADER") || strings.Contains(head, "<OFX>") {
+		return BankStatementFormatOFX, nil
+	}
+	if strings.Contains(head, "CAMT.053") || strings.Contains(head, "BKTOCSTMRSTMT") {
+		return BankStatementFormatCAMT053, nil
+	}
+	return "", errors.New("unsupported statement format: only csv, ofx and camt.053 are allowed")
+}
+
+// ParseBankStatement reads a statement file; the period is derived from the lines when the file does not state it.
+func ParseBankStatement(format BankStatementFormat, data []byte) (*ParsedBankStatement, error) {
+	var (
+		statement *ParsedBankStatement
+		err       error
+	)
+	switch format {
+	case BankStatementFormatCSV:
+		statement, err = parseBankStatementCSV(data)
+	case BankStatementFormatOFX:
+		statement, err = parseBankStatementOFX(data)
+	case BankStatementFormatCAMT053:
+		statement, err = parseBankStatementCAMT053(data)
+	default:
+		return nil, errors.New("invalid statement format")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(statement.Lines) == 0 {
+		return nil, errors.New("statement has no transactions")
+	}
+	// fill the period from the lines when the file does not state it
+	for _, line := range statement.Lines {
+		date := line.TransactionDate
+		if statement.StartDate == nil || date.Before(*statement.StartDate) {
+			statement.StartDate = &date
+		}
+		if statement.EndDate == nil || date.After(*statement.EndDate) {
+			statement.EndDate = &date
+		}
+	}
+	return statement, nil
+}
+
+// date layouts accepted in csv statements; day-first before month-first
+var bankStatementDateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02T15:04:05",
+	"2006/01/02",
+	"02/01/2006",
+	"02-01-2006",
+	"02.01.2006",
+	"02-Jan-2006",
+	"02 Jan 2006",
+	"Jan 2, 2006",
+}
+
+func parseBankStatementDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range bankStatementDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+func parseBankStatementAmount(value string) (decimal.Decimal, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return decimal.Zero, nil
+	}
+	negative := false
+	// accounting style negatives: (1,000.00)
+	if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+		negative = true
+		value = strings.Trim(value, "()")
+	}
+	value = strings.ReplaceAll(value, ",", "")
+	value = strings.ReplaceAll(value, " ", "")
+	amount, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid amount %q", value)
+	}
+	if negative {
+		amount = amount.Neg()
+	}
+	return amount, nil
+}
+
+// parseBankStatementCSV reads a csv with a header row.
+// Required columns: date and either amount (signed) or debit/credit (withdrawal/deposit).
+// Optional columns: description, reference, balance.
+func parseBankStatementCSV(data []byte) (*ParsedBankStatement, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("csv statement header not found")
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "date", "transaction date", "posting date", "value date":
+			name = "date"
+		case "description", "details", "narration", "memo", "particulars":
+			name = "description"
+		case "reference", "reference number", "ref", "cheque no", "check number":
+			name = "reference"
+		case "debit", "withdrawal", "withdrawals", "money out":
+			name = "debit"
+		case "credit", "deposit", "deposits", "money in":
+			name = "credit"
+		}
+		if _, exists := columns[name]; !exists {
+			columns[name] = i
+		}
+	}
+	dateCol, ok := columns["date"]
+	if !ok {
+		return nil, errors.New("csv statement requires a date column")
+	}
+	amountCol, hasAmount := columns["amount"]
+	_, hasDebit := columns["debit"]
+	_, hasCredit := columns["credit"]
+	if !hasAmount && !hasDebit && !hasCredit {
+		return nil, errors.New("csv statement requires an amount or debit/credit columns")
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	statement := &ParsedBankStatement{}
+	var lastBalance *decimal.Decimal
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+		if dateCol >= len(record) || strings.TrimSpace(record[dateCol]) == "" {
+			continue
+		}
+		date, err := parseBankStatementDate(record[dateCol])
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+		var amount decimal.Decimal
+		if hasAmount && amountCol < len(record) {
+			if amount, err = parseBankStatementAmount(record[amountCol]); err != nil {
+				return nil, fmt.Errorf("row %d: %w", row, err)
+			}
+		} else {
+			credit, err := parseBankStatementAmount(field(record, "credit"))
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", row, err)
+			}
+			debit, err := parseBankStatementAmount(field(record, "debit"))
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", row, err)
+			}
+			amount = credit.Abs().Sub(debit.Abs())
+		}
+		if amount.IsZero() {
+			continue
+		}
+		if value := field(record, "balance"); value != "" {
+			balance, err := parseBankStatementAmount(value)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", row, err)
+			}
+			if lastBalance == nil {
+				opening := balance.Sub(amount)
+				statement.OpeningBalance = &opening
+			}
+			lastBalance = &balance
+		}
+		statement.Lines = append(statement.Lines, ParsedBankStatementLine{
+			TransactionDate: date,
+			Amount:          amount,
+			Description:     field(record, "description"),
+			ReferenceNumber: field(record, "reference"),
+		})
+	}
+	statement.ClosingBalance = lastBalance
+	return statement, nil
+}
+
+// parseBankStatementOFX reads OFX 1.x (SGML, unclosed tags) and OFX 2.x (XML) bank statements.
+func parseBankStatementOFX(data []byte) (*ParsedBankStatement, error) {
+	content := string(data)
+	statement := &ParsedBankStatement{}
+
+	if value := ofxTagValue(content, "DTSTART"); value != "" {
+		if date, err := parseOFXDate(value); err == nil {
+			statement.StartDate = &date
+		}
+	}
+	if value := ofxTagValue(content, "DTEND"); value != "" {
+		if date, err := parseOFXDate(value); err == nil {
+			statement.EndDate = &date
+		}
+	}
+	if ledger := ofxBlock(content, "LEDGERBAL"); ledger != "" {
+		if balance, err := parseBankStatementAmount(ofxTagValue(ledger, "BALAMT")); err == nil {
+			statement.ClosingBalance = &balance
+		}
+	}
+
+	blocks := ofxBlocks(content, "STMTTRN")
+	if len(blocks) == 0 {
+		return nil, errors.New("ofx statement has no STMTTRN entries")
+	}
+	for i, block := range blocks {
+		date, err := parseOFXDate(ofxTagValue(block, "DTPOSTED"))
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", i+1, err)
+		}
+		amount, err := parseBankStatementAmount(ofxTagValue(block, "TRNAMT"))
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", i+1, err)
+		}
+		description := ofxTagValue(block, "NAME")
+		if memo := ofxTagValue(block, "MEMO"); memo != "" {
+			if description != "" {
+				description += " - "
+			}
+			description += memo
+		}
+		reference := ofxTagValue(block, "CHECKNUM")
+		if reference == "" {
+			reference = ofxTagValue(block, "REFNUM")
+		}
+		statement.Lines = append(statement.Lines, ParsedBankStatementLine{
+			TransactionDate: date,
+			Amount:          amount,
+			Description:     description,
+			ReferenceNumber: reference,
+			ExternalId:      ofxTagValue(block, "FITID"),
+		})
+	}
+	if statement.ClosingBalance != nil {
+		total := decimal.Zero
+		for _, line := range statement.Lines {
+			total = total.Add(line.Amount)
+		}
+		opening := statement.ClosingBalance.Sub(total)
+		statement.OpeningBalance = &opening
+	}
+	return statement, nil
+}
+
+// ofxBlocks returns the content between every <TAG> and </TAG> pair.
+func ofxBlocks(content string, tag string) []string {
+	open, closing := "<"+tag+">", "</"+tag+">"
+	var blocks []string
+	for {
+		start := strings.Index(content, open)
+		if start < 0 {
+			return blocks
+		}
+		content = content[start+len(open):]
+		end := strings.Index(content, closing)
+		if end < 0 {
+			blocks = append(blocks, content)
+			return blocks
+		}
+		blocks = append(blocks, content[:end])
+		content = content[end+len(closing):]
+	}
+}
+
+func ofxBlock(content string, tag string) string {
+	if blocks := ofxBlocks(content, tag); len(blocks) > 0 {
+		return blocks[0]
+	}
+	return ""
+}
+
+// ofxTagValue returns the value of a leaf element; SGML leaves end at the next tag or line break.
+func ofxTagValue(content string, tag string) string {
+	open := "<" + tag + ">"
+	start := strings.Index(content, open)
+	if start < 0 {
+		return ""
+	}
+	value := content[start+len(open):]
+	if end := strings.IndexAny(value, "<\r\n"); end >= 0 {
+		value = value[:end]
+	}
+	return strings.TrimSpace(value)
+}
+
+// parseOFXDate parses YYYYMMDD[HHMMSS[.XXX]][[gmt offset:tz name]], keeping only the date part.
+func parseOFXDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	date, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return date, nil
+}
+
+type camt053Document struct {
+	Statements []struct {
+		FromToDate struct {
+			From string `xml:"FrDtTm"`
+			To   string `xml:"ToDtTm"`
+		} `xml:"FrToDt"`
+		Balances []struct {
+			Code      string        `xml:"Tp>CdOrPrtry>Cd"`
+			Amount    camt053Amount `xml:"Amt"`
+			Indicator string        `xml:"CdtDbtInd"`
+			Date      string        `xml:"Dt>Dt"`
+		} `xml:"Bal"`
+		Entries []struct {
+			Amount          camt053Amount `xml:"Amt"`
+			Indicator       string        `xml:"CdtDbtInd"`
+			BookingDate     string        `xml:"BookgDt>Dt"`
+			BookingDateTime string        `xml:"BookgDt>DtTm"`
+			ValueDate       string        `xml:"ValDt>Dt"`
+			ServicerRef     string        `xml:"AcctSvcrRef"`
+			AdditionalInfo  string        `xml:"AddtlNtryInf"`
+			Details         []struct {
+				EndToEndId   string   `xml:"Refs>EndToEndId"`
+				Unstructured []string `xml:"RmtInf>Ustrd"`
+			} `xml:"NtryDtls>TxDtls"`
+		} `xml:"Ntry"`
+	} `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camt053Amount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+func camt053Signed(amount camt053Amount, indicator string) (decimal.Decimal, error) {
+	value, err := parseBankStatementAmount(amount.Value)
+	if err != nil {
+		return value, err
+	}
+	if strings.EqualFold(indicator, "DBIT") {
+		return value.Abs().Neg(), nil
+	}
+	return value.Abs(), nil
+}
+
+func parseCAMTDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if len(value) >= 10 {
+		if date, err := time.Parse("2006-01-02", value[:10]); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+// parseBankStatementCAMT053 reads ISO 20022 camt.053 (BkToCstmrStmt) statements.
+// Multiple Stmt elements are merged; balances are taken from the first opening and the last closing.
+func parseBankStatementCAMT053(data []byte) (*ParsedBankStatement, error) {
+	var document camt053Document
+	if err := xml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid camt.053 statement: %w", err)
+	}
+	if len(document.Statements) == 0 {
+		return nil, errors.New("camt.053 statement has no Stmt element")
+	}
+	statement := &ParsedBankStatement{}
+	for _, stmt := range document.Statements {
+		if stmt.FromToDate.From != "" && statement.StartDate == nil {
+			if date, err := parseCAMTDate(stmt.FromToDate.From); err == nil {
+				statement.StartDate = &date
+			}
+		}
+		if stmt.FromToDate.To != "" {
+			if date, err := parseCAMTDate(stmt.FromToDate.To); err == nil {
+				statement.EndDate = &date
+			}
+		}
+		for _, balance := range stmt.Balances {
+			amount, err := camt053Signed(balance.Amount, balance.Indicator)
+			if err != nil {
+				return nil, err
+			}
+			switch strings.ToUpper(balance.Code) {
+			case "OPBD", "PRCD":
+				if statement.OpeningBalance == nil {
+					statement.OpeningBalance = &amount
+				}
+			case "CLBD":
+				statement.ClosingBalance = &amount
+			}
+		}
+		for i, entry := range stmt.Entries {
+			dateValue := entry.BookingDate
+			if dateValue == "" {
+				dateValue = entry.BookingDateTime
+			}
+			if dateValue == "" {
+				dateValue = entry.ValueDate
+			}
+			date, err := parseCAMTDate(dateValue)
+			if err != nil {
+				return nil, fmt.Errorf("entry %d: %w", i+1, err)
+			}
+			amount, err := camt053Signed(entry.Amount, entry.Indicator)
+			if err != nil {
+				return nil, fmt.Errorf("entry %d: %w", i+1, err)
+			}
+			description := entry.AdditionalInfo
+			reference := ""
+			for _, detail := range entry.Details {
+				if reference == "" && detail.EndToEndId != "" && detail.EndToEndId != "NOTPROVIDED" {
+					reference = detail.EndToEndId
+				}
+				if description == "" && len(detail.Unstructured) > 0 {
+					description = strings.Join(detail.Unstructured, " ")
+				}
+			}
+			statement.Lines = append(statement.Lines, ParsedBankStatementLine{
+				TransactionDate: date,
+				Amount:          amount,
+				Description:     strings.TrimSpace(description),
+				ReferenceNumber: reference,
+				ExternalId:      entry.ServicerRef,
+			})
+		}
+	}
+	return statement, nil
+}
@@ -0,0 +1,96 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/shopspring/decimal"
+)
+
+func TestParseBankStatementCSV(t *testing.T) {
+	data := []byte("Date,Description,Reference,Debit,Credit,Balance\n" +
+		"01/03/2024,Opening deposit,DEP-1,,\"1,000.00\",1500.00\n" +
+		"05/03/2024,Office rent,CHQ-22,400.00,,1100.00\n")
+
+	statement, err := models.ParseBankStatement(models.BankStatementFormatCSV, data)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(statement.Lines) != 2 {
+		t.Fatalf("lines: got %d want 2", len(statement.Lines))
+	}
+	if !statement.Lines[0].Amount.Equal(decimal.NewFromInt(1000)) || !statement.Lines[1].Amount.Equal(decimal.NewFromInt(-400)) {
+		t.Fatalf("amounts: got %s, %s", statement.Lines[0].Amount, statement.Lines[1].Amount)
+	}
+	if got := statement.Lines[1].TransactionDate.Format("2006-01-02"); got != "2024-03-05" {
+		t.Fatalf("date: got %s want 2024-03-05 (day first)", got)
+	}
+	if statement.OpeningBalance == nil || !statement.OpeningBalance.Equal(decimal.NewFromInt(500)) {
+		t.Fatalf("opening balance: got %v want 500", statement.OpeningBalance)
+	}
+	if statement.ClosingBalance == nil || !statement.ClosingBalance.Equal(decimal.NewFromInt(1100)) {
+		t.Fatalf("closing balance: got %v want 1100", statement.ClosingBalance)
+	}
+}
+
+func TestParseBankStatementOFX(t *testing.T) {
+	data := []byte(`OFXHEADER:100
+<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS><BANKTRANLIST>
+<DTSTART>20240301<DTEND>20240331
+<STMTTRN><TRNTYPE>DEBIT<DTPOSTED>20240304120000[+6.5:MMT]<TRNAMT>-250.50<FITID>F1<NAME>Electricity<MEMO>March bill
+</STMTTRN>
+<STMTTRN><TRNTYPE>CREDIT<DTPOSTED>20240310<TRNAMT>900<FITID>F2<NAME>Customer payment<REFNUM>INV-7
+</STMTTRN>
+</BANKTRANLIST><LEDGERBAL><BALAMT>1649.50<DTASOF>20240331</LEDGERBAL></STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>`)
+
+	statement, err := models.ParseBankStatement(models.BankStatementFormatOFX, data)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(statement.Lines) != 2 {
+		t.Fatalf("lines: got %d want 2", len(statement.Lines))
+	}
+	first := statement.Lines[0]
+	if first.ExternalId != "F1" || first.Description != "Electricity - March bill" || !first.Amount.Equal(decimal.RequireFromString("-250.50")) {
+		t.Fatalf("first line: %+v", first)
+	}
+	if statement.Lines[1].ReferenceNumber != "INV-7" {
+		t.Fatalf("reference: got %q want INV-7", statement.Lines[1].ReferenceNumber)
+	}
+	if statement.OpeningBalance == nil || !statement.OpeningBalance.Equal(decimal.NewFromInt(1000)) {
+		t.Fatalf("opening balance: got %v want 1000", statement.OpeningBalance)
+	}
+}
+
+func TestParseBankStatementCAMT053(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+  <BkToCstmrStmt>
+    <Stmt>
+      <Bal><Tp><CdOrPrtry><Cd>OPBD</Cd></CdOrPrtry></Tp><Amt Ccy="USD">100.00</Amt><CdtDbtInd>CRDT</CdtDbtInd><Dt><Dt>2024-03-01</Dt></Dt></Bal>
+      <Bal><Tp><CdOrPrtry><Cd>CLBD</Cd></CdOrPrtry></Tp><Amt Ccy="USD">70.00</Amt><CdtDbtInd>CRDT</CdtDbtInd><Dt><Dt>2024-03-31</Dt></Dt></Bal>
+      <Ntry>
+        <Amt Ccy="USD">30.00</Amt><CdtDbtInd>DBIT</CdtDbtInd>
+        <BookgDt><Dt>2024-03-15</Dt></BookgDt>
+        <AcctSvcrRef>BANK-1</AcctSvcrRef>
+        <NtryDtls><TxDtls><Refs><EndToEndId>E2E-9</EndToEndId></Refs><RmtInf><Ustrd>Card fee</Ustrd></RmtInf></TxDtls></NtryDtls>
+      </Ntry>
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>`)
+
+	statement, err := models.ParseBankStatement(models.BankStatementFormatCAMT053, data)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(statement.Lines) != 1 {
+		t.Fatalf("lines: got %d want 1", len(statement.Lines))
+	}
+	line := statement.Lines[0]
+	if !line.Amount.Equal(decimal.NewFromInt(-30)) || line.ReferenceNumber != "E2E-9" || line.ExternalId != "BANK-1" || line.Description != "Card fee" {
+		t.Fatalf("line: %+v", line)
+	}
+	if !statement.OpeningBalance.Equal(decimal.NewFromInt(100)) || !statement.ClosingBalance.Equal(decimal.NewFromInt(70)) {
+		t.Fatalf("balances: got %s / %s", statement.OpeningBalance, statement.ClosingBalance)
+	}
+}
@@ -107,7 +107,10 @@ type NewBankingTransactionDetail struct {
 }
 
 func (bt BankingTransaction) CheckTransactionLock(ctx context.Context) error {
-	return validateTransactionLock(ctx, bt.TransactionDate, bt.BusinessId, BankingTransactionLock)
+	if err := validateTransactionLock(ctx, bt.TransactionDate, bt.BusinessId, BankingTransactionLock); err != nil {
+		return err
+	}
+	return validateBankReconciliationLock(ctx, bt.BusinessId, bt.ID, bt.TransactionType, bt.TransactionId)
 }
 
 func (bt BankingTransaction) Delete(tx *gorm.DB, ctx context.Context) error {
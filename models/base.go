@@ -226,6 +226,16 @@ const (
 )
 
 func validateTransactionLock(ctx context.Context, transactionDate time.Time, businessId string, lockType TransactionLockType) error {
+	if err := validatePeriodLock(ctx, transactionDate, businessId, lockType); err != nil {
+		return err
+	}
+	if lockType == SalesTransactionLock || lockType == PurchaseTransactionLock {
+		return validateTaxFilingLock(ctx, transactionDate, businessId)
+	}
+	return nil
+}
+
+func validatePeriodLock(ctx context.Context, transactionDate time.Time, businessId string, lockType TransactionLockType) error {
 	business, err := GetBusinessById(ctx, businessId)
 	if err != nil {
 		return err
@@ -268,8 +278,9 @@ func validateTransactionLock(ctx context.Context, transactionDate time.Time, bus
 
 // ValidateTransactionLock enforces posting locks (period close) server-side.
 // This is safe to call from both API mutations and async accounting workers.
+// The tax filing lock is not checked: documents saved before a return was filed still post.
 func ValidateTransactionLock(ctx context.Context, transactionDate time.Time, businessId string, lockType TransactionLockType) error {
-	return validateTransactionLock(ctx, transactionDate, businessId, lockType)
+	return validatePeriodLock(ctx, transactionDate, businessId, lockType)
 }
 
 func ValidateValueAdjustment(ctx context.Context, businessId string, transactionDate time.Time, productType ProductType, productId int, batchNumber *string, sameday ...bool) error {
@@ -335,6 +346,12 @@ func ValidateValueAdjustment(ctx context.Context, businessId string, transaction
 		return err
 	}
 
+	// A valuation method switch restates all stock on its date, like a value adjustment
+	// of every product, so nothing may be posted to stock on or before it.
+	if business.InventoryValuationMethodDate != nil && !adjDate.After(*business.InventoryValuationMethodDate) {
+		return fmt.Errorf("not allowed. Inventory valuation method was changed on %s", business.InventoryValuationMethodDate.In(adjDate.Location()).Format("2006-01-02"))
+	}
+
 	if len(sameday) == 0 || !sameday[0] {
 		adjDate = time.Date(adjDate.Year(), adjDate.Month(), adjDate.Day(), 23, 59, 59, 999, adjDate.Location())
 	}
@@ -502,6 +519,13 @@ func ValidateAccountReference(ctx context.Context, businessId string, referenceI
 		AccountReferenceTypeSupplierCredit:              "supplier_credits",
 		AccountReferenceTypeSupplierAdvanceApplied:      "supplier_credit_bills",
 		AccountReferenceTypeTransferOrder:               "transfer_orders",
+		AccountReferenceTypeAssemblyOrder:               "assembly_orders",
+		AccountReferenceTypeTransferOrderReceipt:        "transfer_order_receipts",
+		AccountReferenceTypeLandedCost:                  "landed_costs",
+		AccountReferenceTypeFixedAssetDepreciation:      "fixed_asset_depreciations",
+		AccountReferenceTypeFixedAssetDisposal:          "fixed_assets",
+		AccountReferenceTypeTaxReturn:                   "tax_returns",
+		AccountReferenceTypeFxRevaluation:               "fx_revaluations",
 
 		// don't know how to validate
 		AccountReferenceTypeCreditNoteRefund:      "",
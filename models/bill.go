@@ -19,6 +19,7 @@ type Bill struct {
 	BranchId                   int             `gorm:"index;not null" json:"branch_id"`
 	PurchaseOrderId            int             `gorm:"index;default:null" json:"purchase_order_id"`
 	PurchaseOrderNumber        string          `gorm:"size:255" json:"purchase_order_number"`
+	RecurringBillId            int             `gorm:"index;default:null" json:"recurring_bill_id"`
 	BillNumber                 string          `gorm:"size:255;not null" json:"bill_number" binding:"required"`
 	SequenceNo                 decimal.Decimal `gorm:"type:decimal(15);not null" json:"sequence_no"`
 	ReferenceNumber            string          `gorm:"size:255;default:null" json:"reference_number"`
@@ -29,6 +30,7 @@ type Bill struct {
 	BillSubject                string          `gorm:"size:255;default:null" json:"bill_subject"`
 	Notes                      string          `gorm:"type:text;default:null" json:"notes"`
 	CurrencyId                 int             `gorm:"not null" json:"currency_id" binding:"required"`
+	PriceListId                int             `gorm:"default:0" json:"price_list_id"`
 	ExchangeRate               decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"exchange_rate"`
 	BillDiscount               decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"bill_discount"`
 	BillDiscountType           *DiscountType   `gorm:"type:enum('P', 'A');default:null" json:"bill_discount_type"`
@@ -39,6 +41,7 @@ type Bill struct {
 	BillTaxType                *TaxType        `gorm:"type:enum('I', 'G');default:null" json:"bill_tax_type"`
 	BillTaxAmount              decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"bill_tax_amount"`
 	CurrentStatus              BillStatus      `gorm:"type:enum('Draft', 'Confirmed','Void', 'Partial Paid', 'Paid');default:Draft" json:"current_status" binding:"required"`
+	ApprovalStatus             *ApprovalStatus `gorm:"size:20;default:null" json:"approval_status"`
 	Documents                  []*Document     `gorm:"polymorphic:Reference" json:"documents"`
 	WarehouseId                int             `gorm:"not null" json:"warehouse_id"`
 	BillSubtotal               decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"bill_subtotal"`
@@ -66,6 +69,7 @@ type NewBill struct {
 	BillSubject                string          `json:"bill_subject"`
 	Notes                      string          `json:"notes"`
 	CurrencyId                 int             `json:"currency_id" binding:"required"`
+	PriceListId                int             `json:"price_list_id"`
 	ExchangeRate               decimal.Decimal `json:"exchange_rate"`
 	BillDiscount               decimal.Decimal `json:"bill_discount"`
 	BillDiscountType           *DiscountType   `json:"bill_discount_type"`
@@ -76,6 +80,7 @@ type NewBill struct {
 	CurrentStatus              BillStatus      `json:"current_status" binding:"required"`
 	Documents                  []*NewDocument  `json:"documents"`
 	WarehouseId                int             `json:"warehouse_id" binding:"required"`
+	RecurringBillId            int             `json:"recurring_bill_id"`
 	Details                    []NewBillDetail `json:"details"`
 }
 
@@ -85,12 +90,13 @@ type BillDetail struct {
 	ProductId            int             `gorm:"index" json:"product_id"`
 	ProductType          ProductType     `gorm:"type:enum('S','G','C','V','I');default:S" json:"product_type"`
 	BatchNumber          string          `gorm:"size:100" json:"batch_number"`
+	SerialNumbers        []string        `gorm:"type:text;serializer:json" json:"serial_numbers"`
 	Name                 string          `gorm:"size:100" json:"name" binding:"required"`
 	Description          string          `gorm:"size:255;default:null" json:"description"`
 	CustomerId           int             `gorm:"default:null" json:"customer_id"`
 	DetailAccountId      int             `gorm:"default:null" json:"detail_account_id"`
 	DetailQty            decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_qty" binding:"required"`
-	DetailUnitRate       decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_unit_rate" binding:"required"`
+	DetailUnitRate       decimal.Decimal `gorm:"type:decimal(20,8);default:0" json:"detail_unit_rate" binding:"required"`
 	DetailTaxId          int             `gorm:"default:null" json:"detail_tax_id"`
 	DetailTaxType        *TaxType        `gorm:"type:enum('I', 'G');default:null" json:"detail_tax_type"`
 	DetailDiscount       decimal.Decimal `gorm:"default:0" json:"detail_discount"`
@@ -101,6 +107,7 @@ type BillDetail struct {
 	PurchaseOrderItemId  int             `gorm:"index" json:"purchase_order_item_id"`
 	CreatedAt            time.Time       `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt            time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+	LineUnit
 }
 
 type NewBillDetail struct {
@@ -108,18 +115,21 @@ type NewBillDetail struct {
 	ProductId           int             `json:"product_id"`
 	ProductType         ProductType     `json:"product_type"`
 	BatchNumber         string          `json:"batch_number"`
+	SerialNumbers       []string        `json:"serial_numbers"`
 	Name                string          `json:"name" binding:"required"`
 	Description         string          `json:"description"`
 	CustomerId          int             `json:"customer_id"`
 	DetailAccountId     int             `json:"detail_account_id"`
 	DetailQty           decimal.Decimal `json:"detail_qty" binding:"required"`
 	DetailUnitRate      decimal.Decimal `json:"detail_unit_rate" binding:"required"`
+	UsePriceListRate    *bool           `json:"use_price_list_rate"`
 	DetailTaxId         int             `json:"detail_tax_id"`
 	DetailTaxType       *TaxType        `json:"detail_tax_type"`
 	DetailDiscount      decimal.Decimal `json:"detail_discount"`
 	DetailDiscountType  *DiscountType   `json:"detail_discount_type"`
 	IsDeletedItem       *bool           `json:"is_deleted_item"`
 	PurchaseOrderItemId int             `json:"purchase_order_item_id"`
+	LineUnit
 }
 
 type BillsConnection struct {
@@ -191,6 +201,18 @@ func (b *Bill) GetID() int {
 	return b.ID
 }
 
+func (bill *Bill) approvalSubject() approvalSubject {
+	return approvalSubject{
+		DocumentType:    ApprovalDocumentTypeBill,
+		ReferenceId:     bill.ID,
+		ReferenceNumber: bill.BillNumber,
+		BranchId:        bill.BranchId,
+		CurrencyId:      bill.CurrencyId,
+		ExchangeRate:    bill.ExchangeRate,
+		Amount:          bill.BillTotalAmount,
+	}
+}
+
 func (bill *Bill) GetFieldValues(tx *gorm.DB) (*utils.DetailFieldValues, error) {
 	return utils.FetchDetailFieldValues(tx, &BillDetail{}, "bill_id", bill.ID)
 }
@@ -303,6 +325,33 @@ func (bill Bill) ValidateStockQty(ctx context.Context, businessId string) error
 	return nil
 }
 
+func (input *NewBill) applyPriceList(ctx context.Context, businessId string) error {
+	list, err := documentPriceList(ctx, businessId, PriceListTypePurchase, &input.PriceListId, input.SupplierId, input.CurrencyId, input.BillDate)
+	if err != nil {
+		return err
+	}
+	for i := range input.Details {
+		d := &input.Details[i]
+		if err := list.fillRate(ctx, businessId, d.ProductType, d.ProductId, d.UnitId, d.DetailQty, d.UsePriceListRate, &d.DetailUnitRate); err != nil {
+			return fmt.Errorf("%s: %w", d.Name, err)
+		}
+	}
+	return nil
+}
+
+func (input *NewBill) detailsToBaseUnit(ctx context.Context, businessId string) error {
+	for i := range input.Details {
+		d := &input.Details[i]
+		if d.IsDeletedItem != nil && *d.IsDeletedItem {
+			continue
+		}
+		if err := d.LineUnit.toBaseUnit(ctx, businessId, d.ProductType, d.ProductId, &d.DetailQty, &d.DetailUnitRate); err != nil {
+			return fmt.Errorf("%s: %w", d.Name, err)
+		}
+	}
+	return nil
+}
+
 func CreateBill(ctx context.Context, input *NewBill) (*Bill, error) {
 
 	businessId, ok := utils.GetBusinessIdFromContext(ctx)
@@ -315,9 +364,18 @@ func CreateBill(ctx context.Context, input *NewBill) (*Bill, error) {
 	// This ensures stock movements happen through the same status-transition path everywhere.
 	requestedStatus := input.CurrentStatus
 
+	if err := input.applyPriceList(ctx, businessId); err != nil {
+		return nil, err
+	}
+	if err := input.detailsToBaseUnit(ctx, businessId); err != nil {
+		return nil, err
+	}
 	if err := input.validate(ctx, businessId, 0); err != nil {
 		return nil, err
 	}
+	if err := resolveExchangeRate(ctx, businessId, input.CurrencyId, input.BillDate, &input.ExchangeRate); err != nil {
+		return nil, err
+	}
 
 	db := config.GetDB()
 	var po PurchaseOrder
@@ -361,12 +419,14 @@ func CreateBill(ctx context.Context, input *NewBill) (*Bill, error) {
 			ProductId:           item.ProductId,
 			ProductType:         item.ProductType,
 			BatchNumber:         item.BatchNumber,
+			SerialNumbers:       item.SerialNumbers,
 			Name:                item.Name,
 			Description:         item.Description,
 			DetailAccountId:     item.DetailAccountId,
 			CustomerId:          item.CustomerId,
 			DetailQty:           item.DetailQty,
 			DetailUnitRate:      item.DetailUnitRate,
+			LineUnit:            item.LineUnit,
 			DetailTaxId:         item.DetailTaxId,
 			DetailTaxType:       item.DetailTaxType,
 			DetailDiscount:      item.DetailDiscount,
@@ -432,6 +492,7 @@ func CreateBill(ctx context.Context, input *NewBill) (*Bill, error) {
 		BranchId:            input.BranchId,
 		PurchaseOrderId:     purchaseOrderId,
 		PurchaseOrderNumber: input.PurchaseOrderNumber,
+		RecurringBillId:     input.RecurringBillId,
 		// BillNumber:              input.BillNumber,
 		ReferenceNumber:            input.ReferenceNumber,
 		BillDate:                   input.BillDate,
@@ -441,6 +502,7 @@ func CreateBill(ctx context.Context, input *NewBill) (*Bill, error) {
 		BillSubject:                input.BillSubject,
 		Notes:                      input.Notes,
 		CurrencyId:                 input.CurrencyId,
+		PriceListId:                input.PriceListId,
 		ExchangeRate:               input.ExchangeRate,
 		BillDiscount:               input.BillDiscount,
 		BillDiscountType:           input.BillDiscountType,
@@ -486,8 +548,17 @@ func CreateBill(ctx context.Context, input *NewBill) (*Bill, error) {
 		return nil, err
 	}
 
-	// If requested "Confirmed", apply the status transition deterministically (Draft -> Confirmed).
+	// A bill an approval policy covers stays a draft until it is approved.
 	if requestedStatus == BillStatusConfirmed {
+		bill.ApprovalStatus, err = holdForApproval(ctx, tx, bill.approvalSubject())
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// If requested "Confirmed", apply the status transition deterministically (Draft -> Confirmed).
+	if requestedStatus == BillStatusConfirmed && bill.ApprovalStatus == nil {
 		if err := tx.WithContext(ctx).Model(&bill).Update("CurrentStatus", BillStatusConfirmed).Error; err != nil {
 			tx.Rollback()
 			return nil, err
@@ -515,11 +586,20 @@ func CreateBill(ctx context.Context, input *NewBill) (*Bill, error) {
 			}
 		}
 
+		if err := postSerialNumbers(tx.WithContext(ctx), businessId, bill.serialNumberPosting()); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
 		// Write outbox record (publishing happens after commit via dispatcher).
 		if err := PublishToAccounting(ctx, tx, businessId, bill.BillDate, bill.ID, AccountReferenceTypeBill, bill, nil, PubSubMessageActionCreate); err != nil {
 			tx.Rollback()
 			return nil, err
 		}
+		if err := QueueWebhookEvent(ctx, tx, businessId, WebhookEventBillConfirmed, string(AccountReferenceTypeBill), bill.ID, bill); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
 	}
 
 	if err := tx.Commit().Error; err != nil {
@@ -537,6 +617,12 @@ func UpdateBill(ctx context.Context, billID int, updatedBill *NewBill) (*Bill, e
 		return nil, errors.New("business id is required")
 	}
 
+	if err := updatedBill.applyPriceList(ctx, businessId); err != nil {
+		return nil, err
+	}
+	if err := updatedBill.detailsToBaseUnit(ctx, businessId); err != nil {
+		return nil, err
+	}
 	if err := updatedBill.validate(ctx, businessId, billID); err != nil {
 		return nil, err
 	}
@@ -554,6 +640,25 @@ func UpdateBill(ctx context.Context, billID int, updatedBill *NewBill) (*Bill, e
 	if config.StrictInventoryDocImmutability() && oldBill.CurrentStatus == BillStatusConfirmed {
 		return nil, errors.New("cannot edit a confirmed bill; void and recreate to preserve inventory/valuation integrity")
 	}
+	if oldBill.CurrentStatus == BillStatusConfirmed {
+		if err := ensureBillHasNoLandedCost(ctx, businessId, oldBill); err != nil {
+			return nil, err
+		}
+	}
+
+	// A draft being confirmed may have to wait for approval: it is saved as a draft and
+	// confirmed at the end, in the same transaction, the way UpdateStatusBill does.
+	confirmDraft := false
+	if oldBill.CurrentStatus == BillStatusDraft && updatedBill.CurrentStatus == BillStatusConfirmed {
+		hasPolicy, err := hasApprovalPolicy(ctx, businessId, ApprovalDocumentTypeBill)
+		if err != nil {
+			return nil, err
+		}
+		if hasPolicy {
+			updatedBill.CurrentStatus = BillStatusDraft
+			confirmDraft = true
+		}
+	}
 
 	// copy oldBill instead of fetching from db again
 	existingBill := *oldBill
@@ -607,6 +712,7 @@ func UpdateBill(ctx context.Context, billID int, updatedBill *NewBill) (*Bill, e
 	existingBill.BillSubject = updatedBill.BillSubject
 	existingBill.Notes = updatedBill.Notes
 	existingBill.CurrencyId = updatedBill.CurrencyId
+	existingBill.PriceListId = updatedBill.PriceListId
 	existingBill.CurrentStatus = updatedBill.CurrentStatus
 	existingBill.ExchangeRate = updatedBill.ExchangeRate
 	existingBill.BillDiscount = updatedBill.BillDiscount
@@ -626,6 +732,14 @@ func UpdateBill(ctx context.Context, billID int, updatedBill *NewBill) (*Bill, e
 	// Iterate through the updated items
 
 	tx := db.Begin()
+	// editing a draft withdraws it from approval
+	if oldStatus == BillStatusDraft {
+		if err := withdrawApproval(ctx, tx, ApprovalDocumentTypeBill, billID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		existingBill.ApprovalStatus = nil
+	}
 	for _, updatedItem := range updatedBill.Details {
 
 		var existingItem *BillDetail
@@ -653,11 +767,13 @@ func UpdateBill(ctx context.Context, billID int, updatedBill *NewBill) (*Bill, e
 				ProductId:           updatedItem.ProductId,
 				ProductType:         updatedItem.ProductType,
 				BatchNumber:         updatedItem.BatchNumber,
+				SerialNumbers:       updatedItem.SerialNumbers,
 				Name:                updatedItem.Name,
 				Description:         updatedItem.Description,
 				DetailAccountId:     updatedItem.DetailAccountId,
 				DetailQty:           updatedItem.DetailQty,
 				DetailUnitRate:      updatedItem.DetailUnitRate,
+				LineUnit:            updatedItem.LineUnit,
 				DetailTaxId:         updatedItem.DetailTaxId,
 				DetailTaxType:       updatedItem.DetailTaxType,
 				DetailDiscount:      updatedItem.DetailDiscount,
@@ -804,11 +920,13 @@ func UpdateBill(ctx context.Context, billID int, updatedBill *NewBill) (*Bill, e
 				// existingItem.ProductId = updatedItem.ProductId
 				// existingItem.ProductType = updatedItem.ProductType
 				existingItem.BatchNumber = updatedItem.BatchNumber
+				existingItem.SerialNumbers = updatedItem.SerialNumbers
 				existingItem.Name = updatedItem.Name
 				existingItem.Description = updatedItem.Description
 				existingItem.DetailAccountId = updatedItem.DetailAccountId
 				existingItem.DetailQty = updatedItem.DetailQty
 				existingItem.DetailUnitRate = updatedItem.DetailUnitRate
+				existingItem.LineUnit = updatedItem.LineUnit
 				existingItem.DetailTaxId = updatedItem.DetailTaxId
 				existingItem.DetailTaxType = updatedItem.DetailTaxType
 				existingItem.DetailDiscount = updatedItem.DetailDiscount
@@ -983,6 +1101,19 @@ func UpdateBill(ctx context.Context, billID int, updatedBill *NewBill) (*Bill, e
 	existingBill.BillTotalAmount = orderTotalAmount
 	existingBill.RemainingBalance = orderTotalAmount
 
+	// a confirmed bill an approval policy covers cannot change without approval
+	if oldStatus == BillStatusConfirmed {
+		required, err := existingBill.approvalSubject().requiresApproval(ctx, businessId)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if required {
+			tx.Rollback()
+			return nil, errors.New("cannot edit a confirmed bill that requires approval; void and recreate it")
+		}
+	}
+
 	// Save the updated bill to the database
 	if err := tx.WithContext(ctx).
 		// Omit("Documents").
@@ -1002,12 +1133,24 @@ func UpdateBill(ctx context.Context, billID int, updatedBill *NewBill) (*Bill, e
 	// 	return nil, err
 	// }
 
+	if existingBill.CurrentStatus == BillStatusConfirmed {
+		if err := postSerialNumbers(tx.WithContext(ctx), businessId, existingBill.serialNumberPosting()); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
 	if oldStatus == BillStatusDraft && existingBill.CurrentStatus == BillStatusConfirmed {
 		err := PublishToAccounting(ctx, tx, businessId, existingBill.BillDate, existingBill.ID, AccountReferenceTypeBill, existingBill, nil, PubSubMessageActionCreate)
 		if err != nil {
 			tx.Rollback()
 			return nil, err
 		}
+		err = QueueWebhookEvent(ctx, tx, businessId, WebhookEventBillConfirmed, string(AccountReferenceTypeBill), existingBill.ID, existingBill)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
 	} else if oldStatus == BillStatusConfirmed && existingBill.CurrentStatus == BillStatusConfirmed {
 		err := PublishToAccounting(ctx, tx, businessId, existingBill.BillDate, existingBill.ID, AccountReferenceTypeBill, existingBill, oldBill, PubSubMessageActionUpdate)
 		if err != nil {
@@ -1023,6 +1166,13 @@ func UpdateBill(ctx context.Context, billID int, updatedBill *NewBill) (*Bill, e
 	}
 	existingBill.Documents = documents
 
+	if confirmDraft {
+		if err := confirmDraftBill(ctx, tx, businessId, &existingBill); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return nil, err
 	}
@@ -1051,6 +1201,11 @@ func DeleteBill(ctx context.Context, id int) (*Bill, error) {
 
 	tx := db.Begin()
 
+	if err := withdrawApproval(ctx, tx, ApprovalDocumentTypeBill, id); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	for _, billItem := range result.Details {
 		if result.CurrentStatus == BillStatusConfirmed {
 			inventoryAccId := 0
@@ -1104,6 +1259,14 @@ func DeleteBill(ctx context.Context, id int) (*Bill, error) {
 	}
 
 	if result.CurrentStatus == BillStatusConfirmed {
+		if err := voidBillLandedCosts(ctx, tx, businessId, result.ID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := unpostSerialNumbers(tx.WithContext(ctx), businessId, StockReferenceTypeBill, result.ID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
 		err = PublishToAccounting(ctx, tx, businessId, result.BillDate, result.ID, AccountReferenceTypeBill, nil, result, PubSubMessageActionDelete)
 		if err != nil {
 			tx.Rollback()
@@ -1139,24 +1302,67 @@ func UpdateStatusBill(ctx context.Context, id int, status string) (*Bill, error)
 		}
 	}
 
-	oldStatus := bill.CurrentStatus
+	if bill.CurrentStatus == BillStatusDraft {
+		if status == string(BillStatusConfirmed) {
+			if err := confirmDraftBill(ctx, tx, businessId, bill); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+			if err := tx.Commit().Error; err != nil {
+				return nil, err
+			}
+			return bill, nil
+		} else if err := withdrawApproval(ctx, tx, ApprovalDocumentTypeBill, bill.ID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
 
-	if err := tx.WithContext(ctx).Model(&bill).UpdateColumn("CurrentStatus", status).Error; err != nil {
+	if err := applyBillStatus(ctx, tx, businessId, bill, status); err != nil {
 		tx.Rollback()
 		return nil, err
 	}
 
+	// Commit the transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return bill, nil
+}
+
+// confirmDraftBill confirms a draft bill in tx. A bill an approval policy covers stays a
+// draft until it is approved.
+func confirmDraftBill(ctx context.Context, tx *gorm.DB, businessId string, bill *Bill) error {
+	if bill.ApprovalStatus != nil && *bill.ApprovalStatus == ApprovalStatusPending {
+		return errors.New("bill is waiting for approval")
+	}
+	var err error
+	bill.ApprovalStatus, err = holdForApproval(ctx, tx, bill.approvalSubject())
+	if err != nil || bill.ApprovalStatus != nil {
+		return err
+	}
+	return applyBillStatus(ctx, tx, businessId, bill, string(BillStatusConfirmed))
+}
+
+// applyBillStatus moves a bill to status with its stock, purchase order and accounting
+// side effects, and logs the change.
+func applyBillStatus(ctx context.Context, tx *gorm.DB, businessId string, bill *Bill, status string) error {
+	oldStatus := bill.CurrentStatus
+
+	if err := tx.WithContext(ctx).Model(&bill).UpdateColumn("CurrentStatus", status).Error; err != nil {
+		return err
+	}
+
 	// Apply inventory side-effects deterministically (prefer explicit command handler).
 	if config.UseStockCommandsFor("BILL") {
 		bill.CurrentStatus = BillStatus(status)
 		if err := ApplyBillStockForStatusTransition(tx.WithContext(ctx), bill, oldStatus); err != nil {
-			tx.Rollback()
-			return nil, err
+			return err
 		}
 	} else {
 		if err := bill.AfterUpdateCurrentStatus(tx.WithContext(ctx), string(oldStatus)); err != nil {
-			tx.Rollback()
-			return nil, err
+			return err
 		}
 	}
 
@@ -1167,29 +1373,24 @@ func UpdateStatusBill(ctx context.Context, id int, status string) (*Bill, error)
 			if billItem.ProductId > 0 {
 				product, err := GetProductOrVariant(ctx, string(billItem.ProductType), billItem.ProductId)
 				if err != nil {
-					tx.Rollback()
-					return nil, err
+					return err
 				}
 				inventoryAccId = product.GetInventoryAccountID()
 			}
 			if status == string(BillStatusVoid) || status == string(BillStatusConfirmed) {
 				if status == string(BillStatusVoid) {
 					if err := UpdatePoDetailBilledQty(tx, ctx, bill.PurchaseOrderId, billItem, "delete", decimal.NewFromFloat(0), inventoryAccId); err != nil {
-						tx.Rollback()
-						return nil, err
+						return err
 					}
 				}
 				if status == string(BillStatusConfirmed) {
 					if err := UpdatePoDetailBilledQty(tx, ctx, bill.PurchaseOrderId, billItem, "create", decimal.NewFromFloat(0), inventoryAccId); err != nil {
-						tx.Rollback()
-						return nil, err
+						return err
 					}
 				}
 
-				_, err = ChangePoCurrentStatus(tx.WithContext(ctx), ctx, businessId, bill.PurchaseOrderId)
-				if err != nil {
-					tx.Rollback()
-					return nil, err
+				if _, err := ChangePoCurrentStatus(tx.WithContext(ctx), ctx, businessId, bill.PurchaseOrderId); err != nil {
+					return err
 				}
 			}
 
@@ -1197,31 +1398,32 @@ func UpdateStatusBill(ctx context.Context, id int, status string) (*Bill, error)
 	}
 
 	if oldStatus == BillStatusDraft && status == string(BillStatusConfirmed) {
+		if err := postSerialNumbers(tx.WithContext(ctx), businessId, bill.serialNumberPosting()); err != nil {
+			return err
+		}
 		err := PublishToAccounting(ctx, tx, businessId, bill.BillDate, bill.ID, AccountReferenceTypeBill, bill, nil, PubSubMessageActionCreate)
 		if err != nil {
-			tx.Rollback()
-			return nil, err
+			return err
+		}
+		err = QueueWebhookEvent(ctx, tx, businessId, WebhookEventBillConfirmed, string(AccountReferenceTypeBill), bill.ID, bill)
+		if err != nil {
+			return err
 		}
 	} else if oldStatus == BillStatusConfirmed && status == string(BillStatusVoid) {
-		err = PublishToAccounting(ctx, tx, businessId, bill.BillDate, bill.ID, AccountReferenceTypeBill, nil, bill, PubSubMessageActionDelete)
+		if err := voidBillLandedCosts(ctx, tx, businessId, bill.ID); err != nil {
+			return err
+		}
+		if err := unpostSerialNumbers(tx.WithContext(ctx), businessId, StockReferenceTypeBill, bill.ID); err != nil {
+			return err
+		}
+		err := PublishToAccounting(ctx, tx, businessId, bill.BillDate, bill.ID, AccountReferenceTypeBill, nil, bill, PubSubMessageActionDelete)
 		if err != nil {
-			tx.Rollback()
-			return nil, err
+			return err
 		}
 	}
 
 	// log history of status change
-	if err := createHistory(tx.WithContext(ctx), "Update", bill.ID, "bills", nil, nil, "Updated current status to "+status); err != nil {
-		tx.Rollback()
-		return nil, err
-	}
-
-	// Commit the transaction
-	if err := tx.Commit().Error; err != nil {
-		return nil, err
-	}
-
-	return bill, nil
+	return createHistory(tx.WithContext(ctx), "Update", bill.ID, "bills", nil, nil, "Updated current status to "+status)
 }
 
 func GetBill(ctx context.Context, id int) (*Bill, error) {
@@ -0,0 +1,265 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// BillOfMaterials lists the components that make up one unit of a composite product.
+// Composite products have no master table of their own, so the bill is attached to the
+// inventory-tracked product or variant that holds the finished good's stock. When
+// KitOnSale is set, confirming a sales invoice for the product assembles the invoiced
+// quantity from its components instead of selling finished stock.
+type BillOfMaterials struct {
+	ID          int                        `gorm:"primary_key" json:"id"`
+	BusinessId  string                     `gorm:"index;not null" json:"business_id" binding:"required"`
+	ProductId   int                        `gorm:"index;not null" json:"product_id" binding:"required"`
+	ProductType ProductType                `gorm:"type:enum('S','V');default:S;not null" json:"product_type"`
+	Notes       string                     `gorm:"size:255" json:"notes"`
+	KitOnSale   *bool                      `gorm:"not null;default:false" json:"kit_on_sale"`
+	Components  []BillOfMaterialsComponent `gorm:"foreignKey:BillOfMaterialsId" json:"components"`
+	CreatedAt   time.Time                  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time                  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// BillOfMaterialsComponent is the quantity of a component, in its base unit, used per
+// unit of the finished good. WastagePercent is consumed on top of Qty when assembling.
+type BillOfMaterialsComponent struct {
+	ID                int             `gorm:"primary_key" json:"id"`
+	BillOfMaterialsId int             `gorm:"index;not null" json:"bill_of_materials_id"`
+	ProductId         int             `gorm:"not null" json:"product_id"`
+	ProductType       ProductType     `gorm:"type:enum('S','V');default:S;not null" json:"product_type"`
+	Qty               decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"qty"`
+	WastagePercent    decimal.Decimal `gorm:"type:decimal(10,4);default:0" json:"wastage_percent"`
+}
+
+type NewBillOfMaterials struct {
+	ProductId   int                           `json:"product_id" binding:"required"`
+	ProductType ProductType                   `json:"product_type" binding:"required"`
+	Notes       string                        `json:"notes"`
+	KitOnSale   *bool                         `json:"kit_on_sale"`
+	Components  []NewBillOfMaterialsComponent `json:"components"`
+}
+
+type NewBillOfMaterialsComponent struct {
+	ProductId      int             `json:"product_id"`
+	ProductType    ProductType     `json:"product_type"`
+	Qty            decimal.Decimal `json:"qty"`
+	WastagePercent decimal.Decimal `json:"wastage_percent"`
+}
+
+// ComponentQty is the quantity of a component consumed to assemble outputQty finished
+// goods, wastage included.
+func ComponentQty(qty decimal.Decimal, wastagePercent decimal.Decimal, outputQty decimal.Decimal) decimal.Decimal {
+	return qty.Mul(hundred.Add(wastagePercent)).Mul(outputQty).DivRound(hundred, 4)
+}
+
+func (input *NewBillOfMaterials) validate(ctx context.Context, businessId string, id int) error {
+	if input.ProductType != ProductTypeSingle && input.ProductType != ProductTypeVariant {
+		return errors.New("bills of materials can only be set on single products or variants")
+	}
+	if !IsRealProduct(ctx, businessId, input.ProductId, input.ProductType) {
+		return errors.New("product's inventory has not been tracked")
+	}
+	count, err := utils.ResourceCountWhere[BillOfMaterials](ctx, businessId, "product_id = ? AND product_type = ? AND id <> ?", input.ProductId, input.ProductType, id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("product already has a bill of materials")
+	}
+	if len(input.Components) == 0 {
+		return errors.New("at least one component is required")
+	}
+
+	type componentKey struct {
+		productType ProductType
+		productId   int
+	}
+	seen := make(map[componentKey]bool)
+	for _, c := range input.Components {
+		if c.ProductType != ProductTypeSingle && c.ProductType != ProductTypeVariant {
+			return errors.New("components must be single products or variants")
+		}
+		if c.ProductType == input.ProductType && c.ProductId == input.ProductId {
+			return errors.New("a product cannot be a component of itself")
+		}
+		if !IsRealProduct(ctx, businessId, c.ProductId, c.ProductType) {
+			return errors.New("component's inventory has not been tracked")
+		}
+		if !c.Qty.IsPositive() {
+			return errors.New("component quantity must be greater than zero")
+		}
+		if c.WastagePercent.IsNegative() {
+			return errors.New("wastage cannot be negative")
+		}
+		key := componentKey{c.ProductType, c.ProductId}
+		if seen[key] {
+			return errors.New("duplicate component")
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+func (bom *BillOfMaterials) assign(input *NewBillOfMaterials) {
+	bom.ProductId = input.ProductId
+	bom.ProductType = input.ProductType
+	bom.Notes = input.Notes
+	bom.KitOnSale = utils.NewFalse()
+	if input.KitOnSale != nil && *input.KitOnSale {
+		bom.KitOnSale = utils.NewTrue()
+	}
+	bom.Components = make([]BillOfMaterialsComponent, len(input.Components))
+	for i, c := range input.Components {
+		bom.Components[i] = BillOfMaterialsComponent{
+			ProductId:      c.ProductId,
+			ProductType:    c.ProductType,
+			Qty:            c.Qty,
+			WastagePercent: c.WastagePercent,
+		}
+	}
+}
+
+func CreateBillOfMaterials(ctx context.Context, input *NewBillOfMaterials) (*BillOfMaterials, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if err := input.validate(ctx, businessId, 0); err != nil {
+		return nil, err
+	}
+
+	bom := BillOfMaterials{BusinessId: businessId}
+	bom.assign(input)
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Create(&bom).Error; err != nil {
+		return nil, err
+	}
+	return &bom, nil
+}
+
+// UpdateBillOfMaterials applies to assembly orders created afterwards; saved orders keep
+// their component lines.
+func UpdateBillOfMaterials(ctx context.Context, id int, input *NewBillOfMaterials) (*BillOfMaterials, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if err := input.validate(ctx, businessId, id); err != nil {
+		return nil, err
+	}
+
+	existing, err := utils.FetchModel[BillOfMaterials](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+	existing.assign(input)
+
+	db := config.GetDB()
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("bill_of_materials_id = ?", id).Delete(&BillOfMaterialsComponent{}).Error; err != nil {
+			return err
+		}
+		return tx.Save(existing).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+func DeleteBillOfMaterials(ctx context.Context, id int) (*BillOfMaterials, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	result, err := utils.FetchModel[BillOfMaterials](ctx, businessId, id, "Components")
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("bill_of_materials_id = ?", id).Delete(&BillOfMaterialsComponent{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(result).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func GetBillOfMaterials(ctx context.Context, id int) (*BillOfMaterials, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	return utils.FetchModel[BillOfMaterials](ctx, businessId, id, "Components")
+}
+
+func ListBillOfMaterials(ctx context.Context, productId *int, productType *ProductType, kitOnSale *bool) ([]*BillOfMaterials, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	db := config.GetDB()
+	dbCtx := db.WithContext(ctx).Preload("Components").Where("business_id = ?", businessId)
+	if productId != nil && *productId > 0 {
+		dbCtx = dbCtx.Where("product_id = ?", *productId)
+	}
+	if productType != nil {
+		dbCtx = dbCtx.Where("product_type = ?", *productType)
+	}
+	if kitOnSale != nil {
+		dbCtx = dbCtx.Where("kit_on_sale = ?", *kitOnSale)
+	}
+	var results []*BillOfMaterials
+	if err := dbCtx.Order("id").Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// productBillOfMaterials returns the product's bill, or nil when it has none.
+func productBillOfMaterials(tx *gorm.DB, businessId string, productType ProductType, productId int) (*BillOfMaterials, error) {
+	var bom BillOfMaterials
+	err := tx.Preload("Components").
+		Where("business_id = ? AND product_id = ? AND product_type = ?", businessId, productId, productType).
+		First(&bom).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &bom, nil
+}
+
+// componentName is used for assembly order lines built from a bill of materials.
+func componentName(ctx context.Context, productType ProductType, productId int) (string, error) {
+	product, err := GetProductOrVariant(ctx, string(productType), productId)
+	if err != nil {
+		return "", err
+	}
+	switch p := product.(type) {
+	case *Product:
+		return p.Name, nil
+	case *ProductVariant:
+		return p.Name, nil
+	}
+	return fmt.Sprintf("product_id=%d", productId), nil
+}
@@ -0,0 +1,202 @@
+package models_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+)
+
+// Regression: confirming a draft bill under an approval policy saves it as a draft and holds
+// it for approval in the same transaction. Lines entered in a non-base unit must be converted
+// to the base unit once, not once per pass (qty x factor^2, rate / factor^2).
+func TestBill_ConfirmUnderApprovalPolicyConvertsLineUnitOnce(t *testing.T) {
+	if strings.TrimSpace(os.Getenv("INTEGRATION_TESTS")) == "" {
+		t.Skip("set INTEGRATION_TESTS=1 to run integration tests (requires docker)")
+	}
+
+	ctx := context.Background()
+
+	redisName, redisPort := startRedisContainer(t)
+	t.Cleanup(func() { _ = dockerRmForce(redisName) })
+
+	mysqlName, mysqlPort := startMySQLContainer(t)
+	t.Cleanup(func() { _ = dockerRmForce(mysqlName) })
+
+	t.Setenv("REDIS_ADDRESS", fmt.Sprintf("127.0.0.1:%s", redisPort))
+	t.Setenv("DB_USER", "root")
+	t.Setenv("DB_PASSWORD", "testpw")
+	t.Setenv("DB_HOST", "127.0.0.1")
+	t.Setenv("DB_PORT", mysqlPort)
+	t.Setenv("DB_NAME_2", "pitibooks_test")
+	t.Setenv("STOCK_COMMANDS_DOCS", "")
+
+	config.ConnectDatabaseWithRetry()
+	config.ConnectRedisWithRetry()
+	models.MigrateTable()
+
+	ctx = utils.SetUserIdInContext(ctx, 1)
+	ctx = utils.SetUserNameInContext(ctx, "Test")
+	ctx = utils.SetUsernameInContext(ctx, "test@local")
+
+	biz, err := models.CreateBusiness(ctx, &models.NewBusiness{
+		Name:  "Test Biz",
+		Email: "owner@test.local",
+	})
+	if err != nil {
+		t.Fatalf("CreateBusiness: %v", err)
+	}
+	businessID := biz.ID.String()
+	ctx = utils.SetBusinessIdInContext(ctx, businessID)
+
+	db := config.GetDB()
+	relaxDate := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := db.WithContext(ctx).Model(&models.Business{}).Where("id = ?", biz.ID).Updates(map[string]interface{}{
+		"MigrationDate":                 relaxDate,
+		"SalesTransactionLockDate":      relaxDate,
+		"PurchaseTransactionLockDate":   relaxDate,
+		"BankingTransactionLockDate":    relaxDate,
+		"AccountantTransactionLockDate": relaxDate,
+	}).Error; err != nil {
+		t.Fatalf("relax business lock dates: %v", err)
+	}
+
+	var primary models.Warehouse
+	if err := db.WithContext(ctx).Where("business_id = ? AND name = ?", businessID, "Primary Warehouse").First(&primary).Error; err != nil {
+		t.Fatalf("fetch primary warehouse: %v", err)
+	}
+
+	piece, err := models.CreateProductUnit(ctx, &models.NewProductUnit{Name: "Pcs", Abbreviation: "pc", Precision: models.PrecisionZero})
+	if err != nil {
+		t.Fatalf("CreateProductUnit: %v", err)
+	}
+	carton, err := models.CreateProductUnit(ctx, &models.NewProductUnit{Name: "Carton", Abbreviation: "ctn", Precision: models.PrecisionZero})
+	if err != nil {
+		t.Fatalf("CreateProductUnit: %v", err)
+	}
+
+	sysAccounts, err := models.GetSystemAccounts(businessID)
+	if err != nil {
+		t.Fatalf("GetSystemAccounts: %v", err)
+	}
+	invAcc := sysAccounts[models.AccountCodeInventoryAsset]
+	purchaseAcc := sysAccounts[models.AccountCodeCostOfGoodsSold]
+
+	product, err := models.CreateProduct(ctx, &models.NewProduct{
+		Name:               "Apple cover",
+		Sku:                "APPLE-COVER-001",
+		Barcode:            "APPLE-COVER-001",
+		UnitId:             piece.ID,
+		SalesAccountId:     sysAccounts[models.AccountCodeSales],
+		PurchaseAccountId:  purchaseAcc,
+		InventoryAccountId: invAcc,
+		IsBatchTracking:    utils.NewFalse(),
+	})
+	if err != nil {
+		t.Fatalf("CreateProduct: %v", err)
+	}
+	if _, err := models.SetProductUnitConversions(ctx, models.ProductTypeSingle, product.ID, []*models.NewProductUnitConversion{
+		{UnitId: carton.ID, Factor: decimal.NewFromInt(12)},
+	}); err != nil {
+		t.Fatalf("SetProductUnitConversions: %v", err)
+	}
+
+	supplier, err := models.CreateSupplier(ctx, &models.NewSupplier{
+		Name:                 "Supplier A",
+		Email:                "supplier@test.local",
+		CurrencyId:           biz.BaseCurrencyId,
+		ExchangeRate:         decimal.NewFromInt(1),
+		SupplierPaymentTerms: models.PaymentTermsDueOnReceipt,
+	})
+	if err != nil {
+		t.Fatalf("CreateSupplier: %v", err)
+	}
+
+	role, err := models.CreateRole(ctx, &models.NewRole{Name: "Bill approver"})
+	if err != nil {
+		t.Fatalf("CreateRole: %v", err)
+	}
+	if _, err := models.CreateApprovalPolicy(ctx, &models.NewApprovalPolicy{
+		Name:            "All bills",
+		DocumentType:    models.ApprovalDocumentTypeBill,
+		ApproverRoleIds: []int{role.ID},
+	}); err != nil {
+		t.Fatalf("CreateApprovalPolicy: %v", err)
+	}
+
+	isTaxInclusive := false
+	input := models.NewBill{
+		SupplierId:       supplier.ID,
+		BranchId:         biz.PrimaryBranchId,
+		BillDate:         time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC),
+		BillPaymentTerms: models.PaymentTermsDueOnReceipt,
+		CurrencyId:       biz.BaseCurrencyId,
+		ExchangeRate:     decimal.NewFromInt(1),
+		WarehouseId:      primary.ID,
+		IsTaxInclusive:   &isTaxInclusive,
+		CurrentStatus:    models.BillStatusDraft,
+		ReferenceNumber:  "BL-1",
+		Details: []models.NewBillDetail{{
+			ProductId:       product.ID,
+			ProductType:     models.ProductTypeSingle,
+			Name:            "Apple cover",
+			DetailAccountId: purchaseAcc,
+			DetailQty:       decimal.NewFromInt(2),
+			DetailUnitRate:  decimal.NewFromInt(2400),
+			LineUnit:        models.LineUnit{UnitId: carton.ID},
+		}},
+	}
+	bill, err := models.CreateBill(ctx, &input)
+	if err != nil {
+		t.Fatalf("CreateBill: %v", err)
+	}
+
+	input.CurrentStatus = models.BillStatusConfirmed
+	input.Details = []models.NewBillDetail{{
+		DetailId:        bill.Details[0].ID,
+		ProductId:       product.ID,
+		ProductType:     models.ProductTypeSingle,
+		Name:            "Apple cover",
+		DetailAccountId: purchaseAcc,
+		DetailQty:       decimal.NewFromInt(2),
+		DetailUnitRate:  decimal.NewFromInt(2400),
+		LineUnit:        models.LineUnit{UnitId: carton.ID},
+	}}
+	updated, err := models.UpdateBill(ctx, bill.ID, &input)
+	if err != nil {
+		t.Fatalf("UpdateBill: %v", err)
+	}
+	if updated.CurrentStatus != models.BillStatusDraft || updated.ApprovalStatus == nil || *updated.ApprovalStatus != models.ApprovalStatusPending {
+		t.Fatalf("updated bill = %s, approval %v; want a draft pending approval", updated.CurrentStatus, updated.ApprovalStatus)
+	}
+
+	var details []models.BillDetail
+	if err := db.WithContext(ctx).Where("bill_id = ?", bill.ID).Find(&details).Error; err != nil {
+		t.Fatalf("fetch bill details: %v", err)
+	}
+	if len(details) != 1 {
+		t.Fatalf("expected 1 bill detail, got %d", len(details))
+	}
+	d := details[0]
+	if !d.DetailQty.Equal(decimal.NewFromInt(24)) || !d.DetailUnitRate.Equal(decimal.NewFromInt(200)) {
+		t.Fatalf("base unit line = qty %s @ %s, want 24 @ 200", d.DetailQty, d.DetailUnitRate)
+	}
+	if !d.UnitQty.Equal(decimal.NewFromInt(2)) || !d.UnitRate.Equal(decimal.NewFromInt(2400)) || !d.UnitFactor.Equal(decimal.NewFromInt(12)) {
+		t.Fatalf("line unit = %s x %s @ %s, want 2 x 12 @ 2400", d.UnitQty, d.UnitFactor, d.UnitRate)
+	}
+
+	var saved models.Bill
+	if err := db.WithContext(ctx).First(&saved, bill.ID).Error; err != nil {
+		t.Fatalf("fetch bill: %v", err)
+	}
+	if saved.ApprovalStatus == nil || *saved.ApprovalStatus != models.ApprovalStatusPending {
+		t.Fatalf("bill approval status = %v, want pending", saved.ApprovalStatus)
+	}
+}
@@ -229,3 +229,84 @@ func ToggleActiveBranch(ctx context.Context, id int, isActive bool) (*Branch, er
 	}
 	return ToggleActiveModel[Branch](ctx, businessId, id, isActive)
 }
+
+// validateBranches checks a branch list such as User.Branches or MoneyAccount.Branches
+// and returns it in stored form. An empty list means every branch.
+func validateBranches(ctx context.Context, businessId string, branches string) (string, error) {
+	ids, err := utils.ParseBranchIds(branches)
+	if err != nil {
+		return "", err
+	}
+	if len(ids) > 0 {
+		count, err := utils.ResourceCountWhere[Branch](ctx, businessId, "id IN ?", ids)
+		if err != nil {
+			return "", err
+		}
+		if int(count) != len(ids) {
+			return "", errors.New("branch not found")
+		}
+	}
+	return utils.FormatBranchIds(ids), nil
+}
+
+// NarrowReportBranch returns the branches a report should run for, nil meaning every
+// branch. Raw report queries are not branch-scoped by the database plugin, so every report
+// passes its branch through here. A restricted user asking for all branches gets the
+// branches they are permitted.
+func NarrowReportBranch(ctx context.Context, branchId *int) ([]int, error) {
+	ids, restricted := utils.GetBranchIdsFromContext(ctx)
+	if branchId == nil || *branchId == 0 {
+		if restricted {
+			return ids, nil
+		}
+		return nil, nil
+	}
+	if !utils.IsBranchAllowed(ctx, *branchId) {
+		return nil, config.ErrBranchNotPermitted
+	}
+	return []int{*branchId}, nil
+}
+
+// NarrowReportWarehouse returns the warehouses a stock report should run for, nil meaning
+// every warehouse. A restricted user asking for all warehouses gets the warehouses of the
+// branches they are permitted, possibly none, and cannot ask for another branch's warehouse.
+func NarrowReportWarehouse(ctx context.Context, warehouseId *int) ([]int, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	db := config.GetDB()
+	if warehouseId != nil && *warehouseId > 0 {
+		// looked up business-wide, to tell another branch's warehouse from a missing one
+		var warehouse Warehouse
+		if err := db.WithContext(utils.SetSkipBranchScopeInContext(ctx, true)).
+			Where("business_id = ?", businessId).
+			First(&warehouse, *warehouseId).Error; err != nil {
+			return nil, errors.New("warehouse not found")
+		}
+		if !utils.IsBranchAllowed(ctx, warehouse.BranchId) {
+			return nil, config.ErrBranchNotPermitted
+		}
+		return []int{warehouse.ID}, nil
+	}
+	branchIds, restricted := utils.GetBranchIdsFromContext(ctx)
+	if !restricted {
+		return nil, nil
+	}
+	ids := make([]int, 0)
+	if err := db.WithContext(ctx).Model(&Warehouse{}).
+		Where("business_id = ? AND branch_id IN ?", businessId, branchIds).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// BalanceBranchIds returns the branch rows of the daily balance tables a report sums.
+// Branch 0 holds the totals of every branch.
+func BalanceBranchIds(branchIds []int) []int {
+	if len(branchIds) == 0 {
+		return []int{0}
+	}
+	return branchIds
+}
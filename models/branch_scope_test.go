@@ -0,0 +1,78 @@
+package models_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/mmdatafocus/books_backend/utils"
+)
+
+func TestNarrowReportBranch(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+
+	// unrestricted users keep whatever they asked for
+	got, err := models.NarrowReportBranch(context.Background(), nil)
+	if err != nil || got != nil {
+		t.Fatalf("unrestricted all branches = %v, %v", got, err)
+	}
+	got, err = models.NarrowReportBranch(context.Background(), intPtr(7))
+	if err != nil || !reflect.DeepEqual(got, []int{7}) {
+		t.Fatalf("unrestricted branch 7 = %v, %v", got, err)
+	}
+
+	single := utils.SetBranchIdsInContext(context.Background(), []int{3})
+	got, err = models.NarrowReportBranch(single, nil)
+	if err != nil || !reflect.DeepEqual(got, []int{3}) {
+		t.Fatalf("single branch user asking for all = %v, %v", got, err)
+	}
+	got, err = models.NarrowReportBranch(single, intPtr(0))
+	if err != nil || !reflect.DeepEqual(got, []int{3}) {
+		t.Fatalf("single branch user asking for branch 0 = %v, %v", got, err)
+	}
+
+	several := utils.SetBranchIdsInContext(context.Background(), []int{3, 5})
+	got, err = models.NarrowReportBranch(several, nil)
+	if err != nil || !reflect.DeepEqual(got, []int{3, 5}) {
+		t.Fatalf("several branch user asking for all = %v, %v", got, err)
+	}
+	got, err = models.NarrowReportBranch(several, intPtr(5))
+	if err != nil || !reflect.DeepEqual(got, []int{5}) {
+		t.Fatalf("permitted branch = %v, %v", got, err)
+	}
+	if _, err := models.NarrowReportBranch(several, intPtr(4)); !errors.Is(err, config.ErrBranchNotPermitted) {
+		t.Errorf("other branch err = %v, want %v", err, config.ErrBranchNotPermitted)
+	}
+
+	if got := models.BalanceBranchIds(nil); !reflect.DeepEqual(got, []int{0}) {
+		t.Errorf("balance rows of every branch = %v, want [0]", got)
+	}
+}
+
+func TestParseBranchIds(t *testing.T) {
+	ids, err := utils.ParseBranchIds(" 4,1,,4 ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := utils.FormatBranchIds(ids); got != "1,4" {
+		t.Errorf("ParseBranchIds = %q, want \"1,4\"", got)
+	}
+	if ids, err := utils.ParseBranchIds(""); err != nil || len(ids) != 0 {
+		t.Errorf("empty list = %v, %v", ids, err)
+	}
+	for _, bad := range []string{"x", "0", "-2", "1;2"} {
+		if _, err := utils.ParseBranchIds(bad); err == nil {
+			t.Errorf("ParseBranchIds(%q) should fail", bad)
+		}
+	}
+	ids, err = utils.ParseBranchIds("2,x,5")
+	if err == nil {
+		t.Error("ParseBranchIds should report the invalid value")
+	}
+	if got := utils.FormatBranchIds(ids); got != "2,5" {
+		t.Errorf("valid ids next to an invalid one = %q, want \"2,5\"", got)
+	}
+}
@@ -0,0 +1,532 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+// BudgetMonths is the number of fiscal periods kept per budget account.
+const BudgetMonths = 12
+
+const maxBudgetFileSizeBytes = 5 << 20
+
+// Budget holds monthly targets per account for one fiscal year.
+// Income and expense amounts are the movement for the month, while asset,
+// liability and equity amounts are the expected balance at month end,
+// signed the way the balance sheet presents them.
+type Budget struct {
+	ID          int            `gorm:"primary_key" json:"id"`
+	BusinessId  string         `gorm:"index;not null" json:"business_id" binding:"required"`
+	Name        string         `gorm:"size:100;not null" json:"name" binding:"required"`
+	FiscalYear  int            `gorm:"index;not null" json:"fiscal_year" binding:"required"`
+	BranchId    int            `gorm:"index;not null;default:0" json:"branch_id"`
+	StartDate   time.Time      `gorm:"not null" json:"start_date"`
+	EndDate     time.Time      `gorm:"not null" json:"end_date"`
+	Description string         `gorm:"type:text" json:"description"`
+	Details     []BudgetDetail `gorm:"foreignKey:BudgetId" json:"-"`
+	CreatedAt   time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// BudgetDetail is the amount budgeted for an account in one fiscal month (1..12).
+type BudgetDetail struct {
+	ID        int             `gorm:"primary_key" json:"id"`
+	BudgetId  int             `gorm:"index;not null" json:"budget_id"`
+	AccountId int             `gorm:"index;not null" json:"account_id"`
+	Period    int             `gorm:"not null" json:"period"`
+	Amount    decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"amount"`
+}
+
+type BudgetAccount struct {
+	AccountId int               `json:"accountId"`
+	Amounts   []decimal.Decimal `json:"amounts"`
+	Total     decimal.Decimal   `json:"total"`
+}
+
+type NewBudget struct {
+	Name        string              `json:"name" binding:"required"`
+	FiscalYear  int                 `json:"fiscal_year" binding:"required"`
+	BranchId    int                 `json:"branch_id"`
+	Description string              `json:"description"`
+	Accounts    []*NewBudgetAccount `json:"accounts"`
+}
+
+type NewBudgetAccount struct {
+	AccountId int               `json:"account_id" binding:"required"`
+	Amounts   []decimal.Decimal `json:"amounts" binding:"required"`
+}
+
+type NewBudgetImport struct {
+	Name        string `json:"name" binding:"required"`
+	FiscalYear  int    `json:"fiscal_year" binding:"required"`
+	BranchId    int    `json:"branch_id"`
+	Description string `json:"description"`
+}
+
+type BudgetPeriodType string
+
+const (
+	BudgetPeriodTypeMonth   BudgetPeriodType = "MONTH"
+	BudgetPeriodTypeQuarter BudgetPeriodType = "QUARTER"
+	BudgetPeriodTypeYear    BudgetPeriodType = "YEAR"
+)
+
+func (t BudgetPeriodType) IsValid() bool {
+	switch t {
+	case BudgetPeriodTypeMonth, BudgetPeriodTypeQuarter, BudgetPeriodTypeYear:
+		return true
+	}
+	return false
+}
+
+// BudgetPeriod is one reporting column of a budget-vs-actual report.
+// Months lists the fiscal months (1..12) the period covers.
+type BudgetPeriod struct {
+	Name      string    `json:"name"`
+	StartDate time.Time `json:"startDate"`
+	EndDate   time.Time `json:"endDate"`
+	Months    []int     `json:"-"`
+}
+
+// BudgetFiscalYearRange returns the first and last day of the fiscal year
+// starting in the given calendar year.
+func BudgetFiscalYearRange(fiscalYearStart FiscalYear, year int) (time.Time, time.Time, error) {
+	month, err := time.Parse("Jan", string(fiscalYearStart))
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("invalid fiscal year")
+	}
+	start := time.Date(year, month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(1, 0, -1), nil
+}
+
+// BudgetPeriods splits the fiscal year starting at start into reporting periods.
+func BudgetPeriods(start time.Time, periodType BudgetPeriodType) []BudgetPeriod {
+	monthsPerPeriod := 1
+	switch periodType {
+	case BudgetPeriodTypeQuarter:
+		monthsPerPeriod = 3
+	case BudgetPeriodTypeYear:
+		monthsPerPeriod = BudgetMonths
+	}
+
+	periods := make([]BudgetPeriod, 0, BudgetMonths/monthsPerPeriod)
+	for first := 1; first <= BudgetMonths; first += monthsPerPeriod {
+		periodStart := start.AddDate(0, first-1, 0)
+		period := BudgetPeriod{
+			StartDate: periodStart,
+			EndDate:   periodStart.AddDate(0, monthsPerPeriod, -1),
+		}
+		for m := first; m < first+monthsPerPeriod; m++ {
+			period.Months = append(period.Months, m)
+		}
+		switch periodType {
+		case BudgetPeriodTypeQuarter:
+			period.Name = fmt.Sprintf("Q%d FY%d", (first-1)/3+1, start.Year())
+		case BudgetPeriodTypeYear:
+			period.Name = fmt.Sprintf("FY%d", start.Year())
+		default:
+			period.Name = periodStart.Format("Jan 2006")
+		}
+		periods = append(periods, period)
+	}
+	return periods
+}
+
+func (obj Budget) GetId() int {
+	return obj.ID
+}
+
+func (input NewBudget) validate(ctx context.Context, businessId string, id int) error {
+	if input.FiscalYear < 1900 || input.FiscalYear > 9999 {
+		return errors.New("invalid fiscal year")
+	}
+	if input.BranchId > 0 {
+		if err := utils.ValidateResourceId[Branch](ctx, businessId, input.BranchId); err != nil {
+			return errors.New("invalid branch id")
+		}
+	}
+	count, err := utils.ResourceCountWhere[Budget](ctx, businessId,
+		"name = ? AND fiscal_year = ? AND branch_id = ? AND NOT id = ?", input.Name, input.FiscalYear, input.BranchId, id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("duplicate budget name for the fiscal year and branch")
+	}
+
+	accountIds := make([]int, 0, len(input.Accounts))
+	seen := make(map[int]bool)
+	for _, account := range input.Accounts {
+		if len(account.Amounts) != BudgetMonths {
+			return fmt.Errorf("account %d must have %d monthly amounts", account.AccountId, BudgetMonths)
+		}
+		if seen[account.AccountId] {
+			return fmt.Errorf("account %d is budgeted more than once", account.AccountId)
+		}
+		seen[account.AccountId] = true
+		accountIds = append(accountIds, account.AccountId)
+	}
+	if len(accountIds) > 0 {
+		count, err := utils.ResourceCountWhere[Account](ctx, businessId, "id IN ?", accountIds)
+		if err != nil {
+			return err
+		}
+		if int(count) != len(accountIds) {
+			return errors.New("invalid account id")
+		}
+	}
+	return nil
+}
+
+func (b *Budget) assign(input *NewBudget, fiscalYearStart FiscalYear) error {
+	startDate, endDate, err := BudgetFiscalYearRange(fiscalYearStart, input.FiscalYear)
+	if err != nil {
+		return err
+	}
+	b.Name = input.Name
+	b.FiscalYear = input.FiscalYear
+	b.BranchId = input.BranchId
+	b.StartDate = startDate
+	b.EndDate = endDate
+	b.Description = input.Description
+	return nil
+}
+
+func (input NewBudget) details(budgetId int) []BudgetDetail {
+	details := make([]BudgetDetail, 0, len(input.Accounts)*BudgetMonths)
+	for _, account := range input.Accounts {
+		for i, amount := range account.Amounts {
+			if amount.IsZero() {
+				continue
+			}
+			details = append(details, BudgetDetail{
+				BudgetId:  budgetId,
+				AccountId: account.AccountId,
+				Period:    i + 1,
+				Amount:    amount,
+			})
+		}
+	}
+	return details
+}
+
+// replaceBudgetDetails swaps all monthly amounts of the budget within tx.
+func replaceBudgetDetails(tx *gorm.DB, budgetId int, input *NewBudget) error {
+	if err := tx.Where("budget_id = ?", budgetId).Delete(&BudgetDetail{}).Error; err != nil {
+		return err
+	}
+	details := input.details(budgetId)
+	if len(details) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(&details, 500).Error
+}
+
+func CreateBudget(ctx context.Context, input *NewBudget) (*Budget, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if err := input.validate(ctx, businessId, 0); err != nil {
+		return nil, err
+	}
+	business, err := GetBusinessById(ctx, businessId)
+	if err != nil {
+		return nil, err
+	}
+
+	budget := Budget{BusinessId: businessId}
+	if err := budget.assign(input, business.FiscalYear); err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	tx := db.WithContext(ctx).Begin()
+	if err := tx.Create(&budget).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := replaceBudgetDetails(tx, budget.ID, input); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
+
+func UpdateBudget(ctx context.Context, id int, input *NewBudget) (*Budget, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if err := input.validate(ctx, businessId, id); err != nil {
+		return nil, err
+	}
+	business, err := GetBusinessById(ctx, businessId)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := utils.FetchModel[Budget](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := existing.assign(input, business.FiscalYear); err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	tx := db.WithContext(ctx).Begin()
+	if err := tx.Save(existing).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := replaceBudgetDetails(tx, existing.ID, input); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+func DeleteBudget(ctx context.Context, id int) (*Budget, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	result, err := utils.FetchModel[Budget](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	tx := db.WithContext(ctx).Begin()
+	if err := tx.Where("budget_id = ?", result.ID).Delete(&BudgetDetail{}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Delete(result).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func GetBudget(ctx context.Context, id int) (*Budget, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	return utils.FetchModel[Budget](ctx, businessId, id)
+}
+
+func ListBudget(ctx context.Context, fiscalYear *int, branchId *int) ([]*Budget, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	db := config.GetDB()
+	dbCtx := db.WithContext(ctx).Where("business_id = ?", businessId)
+	if fiscalYear != nil && *fiscalYear > 0 {
+		dbCtx.Where("fiscal_year = ?", *fiscalYear)
+	}
+	if branchId != nil {
+		dbCtx.Where("branch_id = ?", *branchId)
+	}
+
+	var results []*Budget
+	if err := dbCtx.Order("fiscal_year DESC, name").Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetBudgetAccounts returns the budget's monthly amounts grouped by account,
+// with months that have no detail row filled with zero.
+func GetBudgetAccounts(ctx context.Context, budgetId int) ([]*BudgetAccount, error) {
+	db := config.GetDB()
+	var details []BudgetDetail
+	if err := db.WithContext(ctx).
+		Where("budget_id = ?", budgetId).
+		Order("account_id, period").
+		Find(&details).Error; err != nil {
+		return nil, err
+	}
+
+	results := make([]*BudgetAccount, 0)
+	byAccount := make(map[int]*BudgetAccount)
+	for _, detail := range details {
+		account, ok := byAccount[detail.AccountId]
+		if !ok {
+			account = &BudgetAccount{
+				AccountId: detail.AccountId,
+				Amounts:   make([]decimal.Decimal, BudgetMonths),
+			}
+			byAccount[detail.AccountId] = account
+			results = append(results, account)
+		}
+		if detail.Period < 1 || detail.Period > BudgetMonths {
+			continue
+		}
+		account.Amounts[detail.Period-1] = detail.Amount
+		account.Total = account.Total.Add(detail.Amount)
+	}
+	return results, nil
+}
+
+// ImportBudget creates a budget from a spreadsheet, or replaces the amounts of the
+// budget with the same name, fiscal year and branch.
+// The first row is a header; each following row holds the account code, the account
+// name and one amount column per fiscal month. Accounts are matched by code first,
+// then by name.
+func ImportBudget(ctx context.Context, input *NewBudgetImport, file graphql.Upload) (*Budget, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if file.File == nil {
+		return nil, errors.New("nil file provided")
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file.File, maxBudgetFileSizeBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBudgetFileSizeBytes {
+		return nil, errors.New("budget file is too large")
+	}
+	rows, err := readBudgetRows(file.Filename, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var accounts []Account
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Where("business_id = ?", businessId).Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	byCode := make(map[string]int)
+	byName := make(map[string]int)
+	for _, account := range accounts {
+		if account.Code != "" {
+			byCode[strings.ToLower(account.Code)] = account.ID
+		}
+		byName[strings.ToLower(account.Name)] = account.ID
+	}
+
+	budgetInput := NewBudget{
+		Name:        input.Name,
+		FiscalYear:  input.FiscalYear,
+		BranchId:    input.BranchId,
+		Description: input.Description,
+	}
+	for i, row := range rows {
+		if i == 0 || isBlankBudgetRow(row) {
+			continue
+		}
+		cell := func(col int) string {
+			if col < len(row) {
+				return strings.TrimSpace(row[col])
+			}
+			return ""
+		}
+		code, name := cell(0), cell(1)
+		accountId, ok := byCode[strings.ToLower(code)]
+		if !ok || code == "" {
+			accountId, ok = byName[strings.ToLower(name)]
+		}
+		if !ok || (code == "" && name == "") {
+			return nil, fmt.Errorf("row %d: account %q not found", i+1, strings.TrimSpace(code+" "+name))
+		}
+
+		amounts := make([]decimal.Decimal, BudgetMonths)
+		for m := 0; m < BudgetMonths; m++ {
+			value := strings.ReplaceAll(cell(m+2), ",", "")
+			if value == "" {
+				continue
+			}
+			amount, err := decimal.NewFromString(value)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: invalid amount %q", i+1, cell(m+2))
+			}
+			amounts[m] = amount
+		}
+		budgetInput.Accounts = append(budgetInput.Accounts, &NewBudgetAccount{
+			AccountId: accountId,
+			Amounts:   amounts,
+		})
+	}
+	if len(budgetInput.Accounts) == 0 {
+		return nil, errors.New("budget file has no account rows")
+	}
+
+	var existing Budget
+	err = db.WithContext(ctx).
+		Where("business_id = ? AND name = ? AND fiscal_year = ? AND branch_id = ?", businessId, input.Name, input.FiscalYear, input.BranchId).
+		First(&existing).Error
+	if err == nil {
+		return UpdateBudget(ctx, existing.ID, &budgetInput)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return CreateBudget(ctx, &budgetInput)
+}
+
+func readBudgetRows(filename string, data []byte) ([][]string, error) {
+	lower := strings.ToLower(filename)
+	switch {
+	case strings.HasSuffix(lower, ".csv"):
+		reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+		reader.FieldsPerRecord = -1
+		return reader.ReadAll()
+	case strings.HasSuffix(lower, ".xlsx"):
+		f, err := excelize.OpenReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open Excel file: %v", err)
+		}
+		defer f.Close()
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, errors.New("excel file has no sheets")
+		}
+		rows, err := f.GetRows(sheets[0])
+		if err != nil {
+			return nil, fmt.Errorf("unable to read sheet: %v", err)
+		}
+		return rows, nil
+	}
+	return nil, errors.New("invalid file type: only .xlsx and .csv files are allowed")
+}
+
+func isBlankBudgetRow(row []string) bool {
+	for _, value := range row {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
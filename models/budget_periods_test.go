@@ -0,0 +1,65 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/shopspring/decimal"
+)
+
+func TestBudgetFiscalYearRange(t *testing.T) {
+	start, end, err := models.BudgetFiscalYearRange(models.FiscalYearApr, 2024)
+	if err != nil {
+		t.Fatalf("range: %v", err)
+	}
+	if got := start.Format("2006-01-02"); got != "2024-04-01" {
+		t.Fatalf("start: got %s want 2024-04-01", got)
+	}
+	if got := end.Format("2006-01-02"); got != "2025-03-31" {
+		t.Fatalf("end: got %s want 2025-03-31", got)
+	}
+}
+
+func TestBudgetPeriods(t *testing.T) {
+	start, _, _ := models.BudgetFiscalYearRange(models.FiscalYearOct, 2024)
+
+	months := models.BudgetPeriods(start, models.BudgetPeriodTypeMonth)
+	if len(months) != 12 {
+		t.Fatalf("months: got %d want 12", len(months))
+	}
+	if months[3].Name != "Jan 2025" || months[3].EndDate.Format("2006-01-02") != "2025-01-31" {
+		t.Fatalf("fourth month: got %s ending %s", months[3].Name, months[3].EndDate.Format("2006-01-02"))
+	}
+
+	quarters := models.BudgetPeriods(start, models.BudgetPeriodTypeQuarter)
+	if len(quarters) != 4 {
+		t.Fatalf("quarters: got %d want 4", len(quarters))
+	}
+	q2 := quarters[1]
+	if q2.Name != "Q2 FY2024" || q2.StartDate.Format("2006-01-02") != "2025-01-01" || q2.EndDate.Format("2006-01-02") != "2025-03-31" {
+		t.Fatalf("second quarter: got %s %s..%s", q2.Name, q2.StartDate.Format("2006-01-02"), q2.EndDate.Format("2006-01-02"))
+	}
+	if len(q2.Months) != 3 || q2.Months[0] != 4 || q2.Months[2] != 6 {
+		t.Fatalf("second quarter months: got %v want [4 5 6]", q2.Months)
+	}
+
+	year := models.BudgetPeriods(start, models.BudgetPeriodTypeYear)
+	if len(year) != 1 || len(year[0].Months) != 12 || year[0].EndDate.Format("2006-01-02") != "2025-09-30" {
+		t.Fatalf("year: got %+v", year)
+	}
+}
+
+func TestNewBudgetVsActualAmount(t *testing.T) {
+	amount := models.NewBudgetVsActualAmount(decimal.NewFromInt(1200), decimal.NewFromInt(1000))
+	if !amount.Variance.Equal(decimal.NewFromInt(200)) {
+		t.Fatalf("variance: got %s want 200", amount.Variance)
+	}
+	if amount.VariancePercent == nil || !amount.VariancePercent.Equal(decimal.NewFromInt(20)) {
+		t.Fatalf("variance percent: got %v want 20", amount.VariancePercent)
+	}
+
+	unbudgeted := models.NewBudgetVsActualAmount(decimal.NewFromInt(50), decimal.Zero)
+	if unbudgeted.VariancePercent != nil {
+		t.Fatalf("variance percent without budget: got %s want nil", unbudgeted.VariancePercent)
+	}
+}
@@ -42,6 +42,10 @@ type Business struct {
 	PurchaseTransactionLockDate   time.Time   `json:"purchase_transaction_lock_date"`
 	BankingTransactionLockDate    time.Time   `json:"banking_transaction_lock_date"`
 	AccountantTransactionLockDate time.Time   `json:"accountant_transaction_lock_date"`
+	TaxFiledThroughDate           *time.Time  `gorm:"default:null" json:"tax_filed_through_date"`
+	// Changed only through SwitchInventoryValuationMethod, which records the switch date.
+	InventoryValuationMethod     InventoryValuationMethod `gorm:"size:20;not null;default:FIFO" json:"inventory_valuation_method"`
+	InventoryValuationMethodDate *time.Time               `gorm:"default:null" json:"inventory_valuation_method_date"`
 	// user create?
 	PrimaryBranchId int       `gorm:"not null" json:"primary_branch_id"`
 	IsActive        *bool     `gorm:"not null;default:true" json:"is_active"`
@@ -0,0 +1,474 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type CreditControlMode string
+
+const (
+	CreditControlModeOff   CreditControlMode = "OFF"
+	CreditControlModeWarn  CreditControlMode = "WARN"
+	CreditControlModeBlock CreditControlMode = "BLOCK"
+)
+
+func (m CreditControlMode) IsValid() bool {
+	switch m {
+	case CreditControlModeOff, CreditControlModeWarn, CreditControlModeBlock:
+		return true
+	}
+	return false
+}
+
+type CreditHoldType string
+
+const (
+	CreditHoldTypeManual CreditHoldType = "MANUAL"
+	CreditHoldTypeAuto   CreditHoldType = "AUTO"
+)
+
+// CreditControlSetting decides what happens when a sales invoice or sales order is
+// confirmed for a customer over their credit limit or on credit hold. In WARN mode the
+// confirmation goes through and a history note is kept; in BLOCK mode it fails unless
+// the user's role has the override action on the document's module.
+// OverdueHoldDays > 0 puts customers with an invoice that many days overdue on AUTO hold.
+type CreditControlSetting struct {
+	ID              int               `gorm:"primary_key" json:"id"`
+	BusinessId      string            `gorm:"index;not null" json:"business_id"`
+	Mode            CreditControlMode `gorm:"size:10;not null;default:'OFF'" json:"mode"`
+	OverdueHoldDays int               `gorm:"not null;default:0" json:"overdue_hold_days"`
+	CreatedAt       time.Time         `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time         `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type NewCreditControlSetting struct {
+	Mode            CreditControlMode `json:"mode"`
+	OverdueHoldDays int               `json:"overdue_hold_days"`
+}
+
+// CustomerCreditExposure is what a customer owes or has committed to, in base currency.
+// A zero CreditLimit means the customer has no limit.
+type CustomerCreditExposure struct {
+	CustomerId            int             `json:"customer_id"`
+	CreditLimit           decimal.Decimal `json:"credit_limit"`
+	OutstandingReceivable decimal.Decimal `json:"outstanding_receivable"`
+	UnusedCredit          decimal.Decimal `json:"unused_credit"`
+	OpenSalesOrderAmount  decimal.Decimal `json:"open_sales_order_amount"`
+	Exposure              decimal.Decimal `json:"exposure"`
+	AvailableCredit       decimal.Decimal `json:"available_credit"`
+	IsOverLimit           bool            `json:"is_over_limit"`
+	CreditHold            bool            `json:"credit_hold"`
+	CreditHoldType        *CreditHoldType `json:"credit_hold_type"`
+	CreditHoldReason      string          `json:"credit_hold_reason"`
+	CreditHoldSince       *time.Time      `json:"credit_hold_since"`
+	OverdueInvoiceCount   int             `json:"overdue_invoice_count"`
+	OldestOverdueDays     int             `json:"oldest_overdue_days"`
+}
+
+func (input NewCreditControlSetting) validate() error {
+	if !input.Mode.IsValid() {
+		return errors.New("invalid credit control mode")
+	}
+	if input.OverdueHoldDays < 0 {
+		return errors.New("overdue hold days cannot be negative")
+	}
+	return nil
+}
+
+func GetCreditControlSetting(ctx context.Context) (*CreditControlSetting, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	db := config.GetDB()
+	var settings []CreditControlSetting
+	if err := db.WithContext(ctx).Where("business_id = ?", businessId).Limit(1).Find(&settings).Error; err != nil {
+		return nil, err
+	}
+	if len(settings) > 0 {
+		return &settings[0], nil
+	}
+	return &CreditControlSetting{BusinessId: businessId, Mode: CreditControlModeOff}, nil
+}
+
+func UpdateCreditControlSetting(ctx context.Context, input *NewCreditControlSetting) (*CreditControlSetting, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if err := input.validate(); err != nil {
+		return nil, err
+	}
+	setting, err := GetCreditControlSetting(ctx)
+	if err != nil {
+		return nil, err
+	}
+	setting.Mode = input.Mode
+	setting.OverdueHoldDays = input.OverdueHoldDays
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Save(setting).Error; err != nil {
+		return nil, err
+	}
+	return setting, nil
+}
+
+// SetCreditHoldCustomer puts a customer on MANUAL hold. It replaces an AUTO hold so the
+// scheduler no longer releases it once the overdue invoices are paid.
+func SetCreditHoldCustomer(ctx context.Context, id int, reason string) (*Customer, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.New("reason is required")
+	}
+	customer, err := utils.FetchModel[Customer](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := setCustomerCreditHold(ctx, customer, CreditHoldTypeManual, reason, time.Now()); err != nil {
+		return nil, err
+	}
+	return customer, nil
+}
+
+// ReleaseCreditHoldCustomer takes a customer off credit hold, MANUAL or AUTO. An AUTO hold
+// is put back by the next scheduler run while the overdue invoices remain.
+func ReleaseCreditHoldCustomer(ctx context.Context, id int) (*Customer, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	customer, err := utils.FetchModel[Customer](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+	if customer.CreditHold == nil || !*customer.CreditHold {
+		return nil, errors.New("customer is not on credit hold")
+	}
+	if err := releaseCustomerCreditHold(ctx, customer); err != nil {
+		return nil, err
+	}
+	return customer, nil
+}
+
+func setCustomerCreditHold(ctx context.Context, customer *Customer, holdType CreditHoldType, reason string, since time.Time) error {
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Model(customer).Updates(map[string]interface{}{
+		"CreditHold":       true,
+		"CreditHoldType":   holdType,
+		"CreditHoldReason": reason,
+		"CreditHoldSince":  since,
+	}).Error; err != nil {
+		return err
+	}
+	customer.CreditHold = utils.NewTrue()
+	customer.CreditHoldType = &holdType
+	customer.CreditHoldReason = reason
+	customer.CreditHoldSince = &since
+	return nil
+}
+
+func releaseCustomerCreditHold(ctx context.Context, customer *Customer) error {
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Model(customer).Updates(map[string]interface{}{
+		"CreditHold":       false,
+		"CreditHoldType":   nil,
+		"CreditHoldReason": "",
+		"CreditHoldSince":  nil,
+	}).Error; err != nil {
+		return err
+	}
+	customer.CreditHold = utils.NewFalse()
+	customer.CreditHoldType = nil
+	customer.CreditHoldReason = ""
+	customer.CreditHoldSince = nil
+	return nil
+}
+
+func GetCustomerCreditExposure(ctx context.Context, customerId int) (*CustomerCreditExposure, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	customer, err := utils.FetchModel[Customer](ctx, businessId, customerId)
+	if err != nil {
+		return nil, err
+	}
+	return customerCreditExposure(ctx, customer, 0, nil)
+}
+
+// customerCreditExposure is outstanding receivable minus unused credits plus the
+// uninvoiced part of open sales orders. salesOrderId is the order of the document being
+// checked: a sales order being confirmed is left out whole, as the document is that
+// order, while for an invoice billing it only invoiceDetails are taken off what the
+// order still has to invoice.
+func customerCreditExposure(ctx context.Context, customer *Customer, salesOrderId int, invoiceDetails []SalesInvoiceDetail) (*CustomerCreditExposure, error) {
+	// exposure is business-wide even for users limited to some branches
+	ctx = utils.SetSkipBranchScopeInContext(ctx, true)
+	business, err := GetBusiness(ctx)
+	if err != nil {
+		return nil, err
+	}
+	receivable, err := GetTotalOutstandingReceivable(ctx, customer.ID)
+	if err != nil {
+		return nil, err
+	}
+	unused, err := GetTotalUnusedCustomerCredit(ctx, customer.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	var orders []SalesOrder
+	dbCtx := db.WithContext(ctx).Preload("Details").
+		Where("business_id = ? AND customer_id = ? AND current_status IN ?", business.ID.String(), customer.ID,
+			[]SalesOrderStatus{SalesOrderStatusConfirmed, SalesOrderStatusPartiallyInvoiced})
+	if salesOrderId > 0 && len(invoiceDetails) == 0 {
+		dbCtx = dbCtx.Where("id <> ?", salesOrderId)
+	}
+	if err := dbCtx.Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	openOrders := decimal.Zero
+	for _, order := range orders {
+		amount := order.UninvoicedAmount()
+		if order.ID == salesOrderId {
+			amount = order.UninvoicedAmountAfter(invoiceDetails)
+		}
+		if order.CurrencyId != business.BaseCurrencyId {
+			amount = amount.Mul(order.ExchangeRate)
+		}
+		openOrders = openOrders.Add(amount)
+	}
+
+	result := CustomerCreditExposure{
+		CustomerId:            customer.ID,
+		CreditLimit:           customer.CreditLimit,
+		OutstandingReceivable: *receivable,
+		UnusedCredit:          *unused,
+		OpenSalesOrderAmount:  openOrders,
+		Exposure:              receivable.Sub(*unused).Add(openOrders),
+		CreditHold:            customer.CreditHold != nil && *customer.CreditHold,
+		CreditHoldType:        customer.CreditHoldType,
+		CreditHoldReason:      customer.CreditHoldReason,
+		CreditHoldSince:       customer.CreditHoldSince,
+	}
+	if result.CreditLimit.IsPositive() {
+		result.AvailableCredit = result.CreditLimit.Sub(result.Exposure)
+		result.IsOverLimit = result.AvailableCredit.IsNegative()
+	}
+
+	today := localDate(time.Now(), business.Timezone)
+	var overdue struct {
+		Count      int
+		OldestDate *time.Time
+	}
+	if err := db.WithContext(ctx).Model(&SalesInvoice{}).
+		Select("COUNT(*) AS count, MIN(invoice_due_date) AS oldest_date").
+		Where("business_id = ? AND customer_id = ? AND current_status IN ? AND remaining_balance > 0",
+			business.ID.String(), customer.ID, []SalesInvoiceStatus{SalesInvoiceStatusConfirmed, SalesInvoiceStatusPartialPaid}).
+		Where("invoice_due_date < ?", today.UTC()).
+		Scan(&overdue).Error; err != nil {
+		return nil, err
+	}
+	result.OverdueInvoiceCount = overdue.Count
+	if overdue.OldestDate != nil {
+		result.OldestOverdueDays = int(today.Sub(localDate(*overdue.OldestDate, business.Timezone)).Hours() / 24)
+	}
+	return &result, nil
+}
+
+// creditViolation describes why confirming amount more for the customer breaks credit
+// control, or returns "" when it does not.
+func creditViolation(customerName string, exposure *CustomerCreditExposure, amount decimal.Decimal) string {
+	if exposure.CreditHold {
+		if exposure.CreditHoldReason != "" {
+			return fmt.Sprintf("customer %s is on credit hold: %s", customerName, exposure.CreditHoldReason)
+		}
+		return fmt.Sprintf("customer %s is on credit hold", customerName)
+	}
+	if exposure.CreditLimit.IsPositive() && exposure.Exposure.Add(amount).GreaterThan(exposure.CreditLimit) {
+		return fmt.Sprintf("customer %s would exceed the credit limit of %s (exposure %s, this document %s)",
+			customerName, exposure.CreditLimit.StringFixed(2), exposure.Exposure.StringFixed(2), amount.StringFixed(2))
+	}
+	return ""
+}
+
+// checkCustomerCredit applies the business's credit control to a document being confirmed.
+// amount is the document total in its own currency; salesOrderId and invoiceDetails are
+// as for customerCreditExposure. Warnings and overrides are kept in the document's history.
+func checkCustomerCredit(ctx context.Context, tx *gorm.DB, module string, referenceType string, referenceId int,
+	customerId int, currencyId int, exchangeRate decimal.Decimal, amount decimal.Decimal, salesOrderId int, invoiceDetails []SalesInvoiceDetail) error {
+
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return errors.New("business id is required")
+	}
+	setting, err := GetCreditControlSetting(ctx)
+	if err != nil {
+		return err
+	}
+	if setting.Mode == CreditControlModeOff {
+		return nil
+	}
+	business, err := GetBusiness(ctx)
+	if err != nil {
+		return err
+	}
+	if currencyId != business.BaseCurrencyId {
+		amount = amount.Mul(exchangeRate)
+	}
+	customer, err := utils.FetchModel[Customer](ctx, businessId, customerId)
+	if err != nil {
+		return err
+	}
+	exposure, err := customerCreditExposure(ctx, customer, salesOrderId, invoiceDetails)
+	if err != nil {
+		return err
+	}
+	violation := creditViolation(customer.Name, exposure, amount)
+	if violation == "" {
+		return nil
+	}
+
+	if setting.Mode == CreditControlModeBlock {
+		allowed, err := canOverrideCreditLimit(ctx, module)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return errors.New(violation)
+		}
+		return createHistory(tx.WithContext(ctx), "Update", referenceId, referenceType, nil, nil, "Credit control overridden: "+violation)
+	}
+	return createHistory(tx.WithContext(ctx), "Update", referenceId, referenceType, nil, nil, "Credit control warning: "+violation)
+}
+
+// canOverrideCreditLimit reports whether the current user's role has the override
+// action on module. Owners always can; system runs never can.
+func canOverrideCreditLimit(ctx context.Context, module string) (bool, error) {
+	userId, ok := utils.GetUserIdFromContext(ctx)
+	if !ok || userId == 0 {
+		return false, nil
+	}
+	db := config.GetDB()
+	var user User
+	if err := db.WithContext(ctx).Where("id = ?", userId).Take(&user).Error; err != nil {
+		return false, err
+	}
+	if user.Role == UserRoleOwner {
+		return true, nil
+	}
+	if user.Role != UserRoleCustom {
+		return false, nil
+	}
+	paths, err := GetQueryPathsFromRole(ctx, user.RoleId)
+	if err != nil {
+		return false, err
+	}
+	return paths["overrideCreditLimit"+module], nil
+}
+
+// GetCreditHoldBusinessIds returns the businesses with automatic credit hold turned on,
+// plus those that turned it off but still have AUTO holds to release.
+func GetCreditHoldBusinessIds(ctx context.Context) ([]string, error) {
+	db := config.GetDB()
+	var enabled, held []string
+	if err := db.WithContext(ctx).Model(&CreditControlSetting{}).
+		Where("mode <> ? AND overdue_hold_days > 0", CreditControlModeOff).
+		Distinct().
+		Pluck("business_id", &enabled).Error; err != nil {
+		return nil, err
+	}
+	if err := db.WithContext(ctx).Model(&Customer{}).
+		Where("credit_hold = ? AND credit_hold_type = ?", true, CreditHoldTypeAuto).
+		Distinct().
+		Pluck("business_id", &held).Error; err != nil {
+		return nil, err
+	}
+	for _, businessId := range held {
+		if !slices.Contains(enabled, businessId) {
+			enabled = append(enabled, businessId)
+		}
+	}
+	return enabled, nil
+}
+
+// ApplyAutoCreditHolds puts customers with an invoice more than OverdueHoldDays overdue
+// on AUTO hold, and releases AUTO holds whose customers have caught up. MANUAL holds are
+// left alone. It returns the number of customers changed.
+func ApplyAutoCreditHolds(ctx context.Context, now time.Time) (int, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return 0, errors.New("business id is required")
+	}
+	setting, err := GetCreditControlSetting(ctx)
+	if err != nil {
+		return 0, err
+	}
+	business, err := GetBusiness(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	db := config.GetDB()
+	var overdueIds []int
+	if setting.Mode != CreditControlModeOff && setting.OverdueHoldDays > 0 {
+		dueBefore := localDate(now, business.Timezone).AddDate(0, 0, -setting.OverdueHoldDays)
+		if err := db.WithContext(ctx).Model(&SalesInvoice{}).
+			Where("business_id = ? AND current_status IN ? AND remaining_balance > 0", businessId,
+				[]SalesInvoiceStatus{SalesInvoiceStatusConfirmed, SalesInvoiceStatusPartialPaid}).
+			Where("invoice_due_date < ?", dueBefore.UTC()).
+			Distinct().
+			Pluck("customer_id", &overdueIds).Error; err != nil {
+			return 0, err
+		}
+	}
+
+	changed := 0
+	reason := fmt.Sprintf("invoices more than %d days overdue", setting.OverdueHoldDays)
+	if len(overdueIds) > 0 {
+		var holds []*Customer
+		if err := db.WithContext(ctx).
+			Where("business_id = ? AND id IN ? AND credit_hold = ?", businessId, overdueIds, false).
+			Find(&holds).Error; err != nil {
+			return changed, err
+		}
+		for _, customer := range holds {
+			if err := setCustomerCreditHold(ctx, customer, CreditHoldTypeAuto, reason, now); err != nil {
+				return changed, err
+			}
+			changed++
+		}
+	}
+
+	releaseCtx := db.WithContext(ctx).
+		Where("business_id = ? AND credit_hold = ? AND credit_hold_type = ?", businessId, true, CreditHoldTypeAuto)
+	if len(overdueIds) > 0 {
+		releaseCtx = releaseCtx.Where("id NOT IN ?", overdueIds)
+	}
+	var releases []*Customer
+	if err := releaseCtx.Find(&releases).Error; err != nil {
+		return changed, err
+	}
+	for _, customer := range releases {
+		if err := releaseCustomerCreditHold(ctx, customer); err != nil {
+			return changed, err
+		}
+		changed++
+	}
+	return changed, nil
+}
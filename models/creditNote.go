@@ -81,11 +81,12 @@ type CreditNoteDetail struct {
 	ProductId            int             `gorm:"index" json:"product_id"`
 	ProductType          ProductType     `gorm:"type:enum('S','G','C','V','I');default:S" json:"product_type"`
 	BatchNumber          string          `gorm:"size:100" json:"batch_number"`
+	SerialNumbers        []string        `gorm:"type:text;serializer:json" json:"serial_numbers"`
 	Name                 string          `gorm:"size:100" json:"name" binding:"required"`
 	Description          string          `gorm:"size:255;default:null" json:"description"`
 	DetailAccountId      int             `gorm:"default:null" json:"detail_account_id"`
 	DetailQty            decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_qty" binding:"required"`
-	DetailUnitRate       decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_unit_rate" binding:"required"`
+	DetailUnitRate       decimal.Decimal `gorm:"type:decimal(20,8);default:0" json:"detail_unit_rate" binding:"required"`
 	DetailTaxId          int             `gorm:"default:null" json:"detail_tax_id"`
 	DetailTaxType        *TaxType        `gorm:"type:enum('I', 'G');default:null" json:"detail_tax_type"`
 	DetailDiscount       decimal.Decimal `json:"detail_discount"`
@@ -96,6 +97,7 @@ type CreditNoteDetail struct {
 	Cogs                 decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"cogs"`
 	CreatedAt            time.Time       `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt            time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+	LineUnit
 }
 
 type NewCreditNoteDetail struct {
@@ -103,6 +105,7 @@ type NewCreditNoteDetail struct {
 	ProductId          int             `json:"product_id"`
 	ProductType        ProductType     `json:"product_type"`
 	BatchNumber        string          `json:"batch_number"`
+	SerialNumbers      []string        `json:"serial_numbers"`
 	Name               string          `json:"name" binding:"required"`
 	Description        string          `json:"description"`
 	DetailAccountId    int             `json:"detail_account_id"`
@@ -114,6 +117,7 @@ type NewCreditNoteDetail struct {
 	DetailDiscount     decimal.Decimal `json:"detail_discount"`
 	DetailDiscountType *DiscountType   `json:"detail_discount_type"`
 	IsDeletedItem      *bool           `json:"is_deleted_item"`
+	LineUnit
 }
 
 type CustomerCreditAdvance struct {
@@ -380,6 +384,19 @@ func updateCreditNoteItemDetailTotal(item *CreditNoteDetail, isTaxInclusive bool
 	return orderSubtotal, totalDetailDiscountAmount, totalDetailTaxAmount, totalExclusiveTaxAmount
 }
 
+func (input *NewCreditNote) detailsToBaseUnit(ctx context.Context, businessId string) error {
+	for i := range input.Details {
+		d := &input.Details[i]
+		if d.IsDeletedItem != nil && *d.IsDeletedItem {
+			continue
+		}
+		if err := d.LineUnit.toBaseUnit(ctx, businessId, d.ProductType, d.ProductId, &d.DetailQty, &d.DetailUnitRate); err != nil {
+			return fmt.Errorf("%s: %w", d.Name, err)
+		}
+	}
+	return nil
+}
+
 func CreateCreditNote(ctx context.Context, input *NewCreditNote) (*CreditNote, error) {
 	db := config.GetDB()
 
@@ -392,10 +409,16 @@ func CreateCreditNote(ctx context.Context, input *NewCreditNote) (*CreditNote, e
 	// and then transition Draft -> Confirmed inside the same DB transaction.
 	requestedStatus := input.CurrentStatus
 
+	if err := input.detailsToBaseUnit(ctx, businessId); err != nil {
+		return nil, err
+	}
 	// validate Credit Note
 	if err := input.validate(ctx, businessId, 0); err != nil {
 		return nil, err
 	}
+	if err := resolveExchangeRate(ctx, businessId, input.CurrencyId, input.CreditNoteDate, &input.ExchangeRate); err != nil {
+		return nil, err
+	}
 
 	// construct Images
 	documents, err := mapNewDocuments(input.Documents, "credit_notes", 0)
@@ -415,11 +438,13 @@ func CreateCreditNote(ctx context.Context, input *NewCreditNote) (*CreditNote, e
 			ProductId:          item.ProductId,
 			ProductType:        item.ProductType,
 			BatchNumber:        item.BatchNumber,
+			SerialNumbers:      item.SerialNumbers,
 			Name:               item.Name,
 			Description:        item.Description,
 			DetailAccountId:    item.DetailAccountId,
 			DetailQty:          item.DetailQty,
 			DetailUnitRate:     item.DetailUnitRate,
+			LineUnit:           item.LineUnit,
 			DetailTaxId:        item.DetailTaxId,
 			DetailTaxType:      item.DetailTaxType,
 			DetailDiscount:     item.DetailDiscount,
@@ -547,6 +572,11 @@ func CreateCreditNote(ctx context.Context, input *NewCreditNote) (*CreditNote, e
 			}
 		}
 
+		if err := postSerialNumbers(tx.WithContext(ctx), businessId, creditNote.serialNumberPosting()); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
 		// Write outbox record (publishing happens after commit via dispatcher).
 		if err := PublishToAccounting(ctx, tx, businessId, creditNote.CreditNoteDate, creditNote.ID, AccountReferenceTypeCreditNote, creditNote, nil, PubSubMessageActionCreate); err != nil {
 			tx.Rollback()
@@ -568,6 +598,9 @@ func UpdateCreditNote(ctx context.Context, creditNoteId int, updatedCreditNote *
 		return nil, errors.New("business id is required")
 	}
 
+	if err := updatedCreditNote.detailsToBaseUnit(ctx, businessId); err != nil {
+		return nil, err
+	}
 	// validate CreditNote
 	if err := updatedCreditNote.validate(ctx, businessId, creditNoteId); err != nil {
 		return nil, err
@@ -644,10 +677,12 @@ func UpdateCreditNote(ctx context.Context, creditNoteId int, updatedCreditNote *
 				ProductType:        updatedItem.ProductType,
 				DetailAccountId:    updatedItem.DetailAccountId,
 				BatchNumber:        updatedItem.BatchNumber,
+				SerialNumbers:      updatedItem.SerialNumbers,
 				Name:               updatedItem.Name,
 				Description:        updatedItem.Description,
 				DetailQty:          updatedItem.DetailQty,
 				DetailUnitRate:     updatedItem.DetailUnitRate,
+				LineUnit:           updatedItem.LineUnit,
 				DetailTaxId:        updatedItem.DetailTaxId,
 				DetailTaxType:      updatedItem.DetailTaxType,
 				DetailDiscount:     updatedItem.DetailDiscount,
@@ -733,11 +768,13 @@ func UpdateCreditNote(ctx context.Context, creditNoteId int, updatedCreditNote *
 				// existingItem.ProductId = updatedItem.ProductId
 				// existingItem.ProductType = updatedItem.ProductType
 				existingItem.BatchNumber = updatedItem.BatchNumber
+				existingItem.SerialNumbers = updatedItem.SerialNumbers
 				existingItem.Name = updatedItem.Name
 				existingItem.Description = updatedItem.Description
 				existingItem.DetailAccountId = updatedItem.DetailAccountId
 				existingItem.DetailQty = updatedItem.DetailQty
 				existingItem.DetailUnitRate = updatedItem.DetailUnitRate
+				existingItem.LineUnit = updatedItem.LineUnit
 				existingItem.DetailTaxId = updatedItem.DetailTaxId
 				existingItem.DetailTaxType = updatedItem.DetailTaxType
 				existingItem.DetailDiscount = updatedItem.DetailDiscount
@@ -894,6 +931,18 @@ func UpdateCreditNote(ctx context.Context, creditNoteId int, updatedCreditNote *
 		return nil, err
 	}
 
+	if existingCreditNote.CurrentStatus == CreditNoteStatusConfirmed {
+		if err := postSerialNumbers(tx.WithContext(ctx), businessId, existingCreditNote.serialNumberPosting()); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	} else if oldStatus == CreditNoteStatusConfirmed {
+		if err := unpostSerialNumbers(tx.WithContext(ctx), businessId, StockReferenceTypeCreditNote, existingCreditNote.ID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
 	if oldStatus == CreditNoteStatusDraft && existingCreditNote.CurrentStatus == CreditNoteStatusConfirmed {
 		err := PublishToAccounting(ctx, tx, businessId, existingCreditNote.CreditNoteDate, existingCreditNote.ID, AccountReferenceTypeCreditNote, existingCreditNote, nil, PubSubMessageActionCreate)
 		if err != nil {
@@ -1025,6 +1074,10 @@ func DeleteCreditNote(ctx context.Context, id int) (*CreditNote, error) {
 	}
 
 	if result.CurrentStatus == CreditNoteStatusConfirmed {
+		if err := unpostSerialNumbers(tx.WithContext(ctx), businessId, StockReferenceTypeCreditNote, result.ID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
 		err = PublishToAccounting(ctx, tx, businessId, oldCreditNote.CreditNoteDate, oldCreditNote.ID, AccountReferenceTypeCreditNote, nil, oldCreditNote, PubSubMessageActionDelete)
 		if err != nil {
 			tx.Rollback()
@@ -1085,12 +1138,20 @@ func UpdateStatusCreditNote(ctx context.Context, id int, status string) (*Credit
 	}
 
 	if oldStatus == CreditNoteStatusDraft && status == string(CreditNoteStatusConfirmed) {
+		if err := postSerialNumbers(tx.WithContext(ctx), businessId, creditNote.serialNumberPosting()); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
 		err := PublishToAccounting(ctx, tx, businessId, creditNote.CreditNoteDate, creditNote.ID, AccountReferenceTypeCreditNote, creditNote, nil, PubSubMessageActionCreate)
 		if err != nil {
 			tx.Rollback()
 			return nil, err
 		}
 	} else if oldStatus == CreditNoteStatusConfirmed && status == string(CreditNoteStatusVoid) {
+		if err := unpostSerialNumbers(tx.WithContext(ctx), businessId, StockReferenceTypeCreditNote, creditNote.ID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
 		err = PublishToAccounting(ctx, tx, businessId, creditNote.CreditNoteDate, creditNote.ID, AccountReferenceTypeCreditNote, nil, creditNote, PubSubMessageActionDelete)
 		if err != nil {
 			tx.Rollback()
@@ -0,0 +1,80 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/shopspring/decimal"
+)
+
+func TestSalesOrderUninvoicedAmount(t *testing.T) {
+	d := decimal.RequireFromString
+	order := models.SalesOrder{
+		CurrentStatus:    models.SalesOrderStatusConfirmed,
+		OrderTotalAmount: d("1100"),
+		Details: []models.SalesOrderDetail{
+			{DetailQty: d("10"), DetailInvoicedQty: d("0"), DetailTotalAmount: d("600")},
+			{DetailQty: d("4"), DetailInvoicedQty: d("0"), DetailTotalAmount: d("400")},
+		},
+	}
+	if got := order.UninvoicedAmount(); !got.Equal(d("1100")) {
+		t.Errorf("confirmed order: got %s, want 1100", got)
+	}
+
+	// half of the first line and all of the second invoiced: 300 of 1000 in lines is left,
+	// scaled to the order total including tax and shipping
+	order.CurrentStatus = models.SalesOrderStatusPartiallyInvoiced
+	order.Details[0].DetailInvoicedQty = d("5")
+	order.Details[1].DetailInvoicedQty = d("4")
+	if got := order.UninvoicedAmount(); !got.Equal(d("330")) {
+		t.Errorf("partially invoiced order: got %s, want 330", got)
+	}
+
+	order.CurrentStatus = models.SalesOrderStatusClosed
+	if got := order.UninvoicedAmount(); !got.IsZero() {
+		t.Errorf("closed order: got %s, want 0", got)
+	}
+}
+
+// An invoice billing part of an order only takes its own lines off the order's exposure,
+// so the rest of the order still counts against the credit limit.
+func TestSalesOrderUninvoicedAmountAfter_PartialInvoiceNearLimit(t *testing.T) {
+	d := decimal.RequireFromString
+	order := models.SalesOrder{
+		CurrentStatus:    models.SalesOrderStatusPartiallyInvoiced,
+		OrderTotalAmount: d("1000"),
+		Details: []models.SalesOrderDetail{
+			{ID: 1, ProductId: 7, ProductType: models.ProductTypeSingle, DetailQty: d("10"), DetailInvoicedQty: d("2"), DetailTotalAmount: d("500")},
+			{ID: 2, ProductId: 8, ProductType: models.ProductTypeSingle, DetailQty: d("5"), DetailInvoicedQty: d("0"), DetailTotalAmount: d("500")},
+		},
+	}
+	// 8 of line 1 (400) and all of line 2 (500) are still to invoice
+	if got := order.UninvoicedAmountAfter(nil); !got.Equal(d("900")) {
+		t.Fatalf("before the invoice: got %s, want 900", got)
+	}
+
+	// the invoice bills 4 more of line 1 by its order line and 1 of line 2 by product
+	invoice := []models.SalesInvoiceDetail{
+		{SalesOrderItemId: 1, ProductId: 7, ProductType: models.ProductTypeSingle, DetailQty: d("4")},
+		{ProductId: 8, ProductType: models.ProductTypeSingle, DetailQty: d("1")},
+	}
+	invoiceAmount := d("300")
+	remaining := order.UninvoicedAmountAfter(invoice)
+	if !remaining.Equal(d("600")) {
+		t.Fatalf("after the invoice: got %s, want 600", remaining)
+	}
+
+	// with 200 already receivable and a limit of 1000, the invoice takes exposure to
+	// 200 + 600 + 300 = 1100; leaving the whole order out would have shown 500
+	limit, receivable := d("1000"), d("200")
+	if exposure := receivable.Add(remaining).Add(invoiceAmount); !exposure.GreaterThan(limit) {
+		t.Errorf("exposure %s should exceed the limit %s", exposure, limit)
+	}
+
+	// a confirmed order billed for the first time is reduced the same way
+	order.CurrentStatus = models.SalesOrderStatusConfirmed
+	order.Details[0].DetailInvoicedQty = d("0")
+	if got := order.UninvoicedAmountAfter(invoice); !got.Equal(d("700")) {
+		t.Errorf("confirmed order after the invoice: got %s, want 700", got)
+	}
+}
@@ -121,23 +121,14 @@ func DeleteCurrencyExchange(ctx context.Context, id int) (*CurrencyExchange, err
 }
 
 func GetCurrencyExchange(ctx context.Context, id int) (*CurrencyExchange, error) {
-
-	db := config.GetDB()
-	var result CurrencyExchange
-
-	fieldNames, err := utils.GetQueryFields(ctx, &CurrencyExchange{})
-	if err != nil {
-		return nil, err
-	}
-
-	err = db.WithContext(ctx).Select(fieldNames).First(&result, id).Error
-	if err != nil {
-		return nil, utils.ErrorRecordNotFound
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
 	}
-	return &result, nil
+	return utils.FetchModel[CurrencyExchange](ctx, businessId, id)
 }
 
-func GetCurrencyExchanges(ctx context.Context, exchangeDate *time.Time, toCurrencyId *int, fromCurrencyId *int) ([]*CurrencyExchange, error) {
+func GetCurrencyExchanges(ctx context.Context, foreignCurrencyId *int, fromDate *MyDateString, toDate *MyDateString) ([]*CurrencyExchange, error) {
 
 	db := config.GetDB()
 	var results []*CurrencyExchange
@@ -151,20 +142,53 @@ func GetCurrencyExchanges(ctx context.Context, exchangeDate *time.Time, toCurren
 	if !ok || businessId == "" {
 		return nil, errors.New("business id is required")
 	}
+	business, err := GetBusinessById(ctx, businessId)
+	if err != nil {
+		return nil, err
+	}
 
 	dbCtx := db.WithContext(ctx).Where("business_id = ?", businessId)
-	if exchangeDate != nil {
-		dbCtx = dbCtx.Where("exchange_date = ?", exchangeDate)
+	if foreignCurrencyId != nil && *foreignCurrencyId > 0 {
+		dbCtx = dbCtx.Where("foreign_currency_id = ?", foreignCurrencyId)
+	}
+	if fromDate != nil {
+		if err := fromDate.StartOfDayUTCTime(business.Timezone); err != nil {
+			return nil, err
+		}
+		dbCtx = dbCtx.Where("exchange_date >= ?", time.Time(*fromDate))
+	}
+	if toDate != nil {
+		if err := toDate.EndOfDayUTCTime(business.Timezone); err != nil {
+			return nil, err
+		}
+		dbCtx = dbCtx.Where("exchange_date <= ?", time.Time(*toDate))
+	}
+	err = dbCtx.Select(fieldNames).Order("exchange_date desc, foreign_currency_id").Find(&results).Error
+	if err != nil {
+		return nil, err
 	}
-	if fromCurrencyId != nil && *fromCurrencyId > 0 {
-		dbCtx = dbCtx.Where("from_currency_id = ?", fromCurrencyId)
+	return results, nil
+}
+
+// GetExchangeRateAsOf returns the most recent rate recorded for the currency on or before date.
+// Base currency documents carry no rate, so zero is returned for them.
+func GetExchangeRateAsOf(ctx context.Context, businessId string, currencyId int, date time.Time) (decimal.Decimal, error) {
+	business, err := GetBusinessById(ctx, businessId)
+	if err != nil {
+		return decimal.Zero, err
 	}
-	if toCurrencyId != nil && *toCurrencyId > 0 {
-		dbCtx = dbCtx.Where("to_currency_id = ?", toCurrencyId)
+	if currencyId == business.BaseCurrencyId {
+		return decimal.Zero, nil
 	}
-	err = dbCtx.Select(fieldNames).Order("exchange_date desc, from_currency_id, to_currency_id").Find(&results).Error
+
+	db := config.GetDB()
+	var result CurrencyExchange
+	err = db.WithContext(ctx).
+		Where("business_id = ? AND foreign_currency_id = ? AND exchange_date <= ?", businessId, currencyId, date).
+		Order("exchange_date desc").
+		First(&result).Error
 	if err != nil {
-		return nil, err
+		return decimal.Zero, errors.New("exchange rate not found")
 	}
-	return results, nil
+	return result.ExchangeRate, nil
 }
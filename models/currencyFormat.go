@@ -0,0 +1,57 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Digits maps the currency setting to a digit count; unknown values fall back to 2.
+func (p DecimalPlaces) Digits() int32 {
+	switch p {
+	case DecimalPlacesZero:
+		return 0
+	case DecimalPlacesThree:
+		return 3
+	default:
+		return 2
+	}
+}
+
+// FormatNumber rounds amount to the currency's decimal places and groups thousands,
+// e.g. 1234567.5 with "0" places gives "1,234,568".
+func FormatNumber(amount decimal.Decimal, places DecimalPlaces) string {
+	s := amount.StringFixed(places.Digits())
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i:]
+	}
+
+	var b strings.Builder
+	if negative {
+		b.WriteByte('-')
+	}
+	for i, r := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteString(fracPart)
+	return b.String()
+}
+
+// FormatAmount is FormatNumber prefixed with the currency symbol, e.g. "MMK 1,500" or "-USD 12.50".
+func FormatAmount(amount decimal.Decimal, symbol string, places DecimalPlaces) string {
+	s := FormatNumber(amount, places)
+	if symbol == "" {
+		return s
+	}
+	if strings.HasPrefix(s, "-") {
+		return "-" + symbol + " " + s[1:]
+	}
+	return symbol + " " + s
+}
@@ -0,0 +1,29 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/shopspring/decimal"
+)
+
+func TestFormatAmount(t *testing.T) {
+	cases := []struct {
+		amount string
+		symbol string
+		places models.DecimalPlaces
+		want   string
+	}{
+		{"1234567.5", "MMK", models.DecimalPlacesZero, "MMK 1,234,568"},
+		{"1234.5", "USD", models.DecimalPlacesTwo, "USD 1,234.50"},
+		{"-12.3456", "KWD", models.DecimalPlacesThree, "-KWD 12.346"},
+		{"999", "", models.DecimalPlacesTwo, "999.00"},
+		{"100000", "THB", "", "THB 100,000.00"},
+	}
+	for _, c := range cases {
+		got := models.FormatAmount(decimal.RequireFromString(c.amount), c.symbol, c.places)
+		if got != c.want {
+			t.Errorf("FormatAmount(%s, %s, %q): got %q want %q", c.amount, c.symbol, c.places, got, c.want)
+		}
+	}
+}
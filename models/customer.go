@@ -24,7 +24,13 @@ type Customer struct {
 	CustomerPaymentTerms           PaymentTerms     `gorm:"type:enum('Net15', 'Net30', 'Net45', 'Net60', 'DueMonthEnd', 'DueNextMonthEnd', 'DueOnReceipt', 'Custom');not null;default:'DueOnReceipt'" json:"customer_payment_terms" binding:"required"`
 	CustomerPaymentTermsCustomDays int              `gorm:"default:0" json:"customer_payment_terms_custom_days"`
 	Notes                          string           `gorm:"type:text" json:"notes"`
+	PriceListId                    int              `gorm:"default:0" json:"price_list_id"`
 	CreditLimit                    decimal.Decimal  `gorm:"type:decimal(20,4);default:0" json:"credit_limit"`
+	ExcludeFromReminders           *bool            `gorm:"not null;default:false" json:"exclude_from_reminders"`
+	CreditHold                     *bool            `gorm:"not null;default:false" json:"credit_hold"`
+	CreditHoldType                 *CreditHoldType  `gorm:"size:10;default:null" json:"credit_hold_type"`
+	CreditHoldReason               string           `gorm:"size:255" json:"credit_hold_reason"`
+	CreditHoldSince                *time.Time       `json:"credit_hold_since"`
 	BillingAddress                 BillingAddress   `gorm:"polymorphic:Reference" json:"billing_address"`
 	ShippingAddress                ShippingAddress  `gorm:"polymorphic:Reference" json:"shipping_address"`
 	ContactPersons                 []*ContactPerson `gorm:"polymorphic:Reference" json:"contact_persons"`
@@ -60,7 +66,9 @@ type NewCustomer struct {
 	CustomerPaymentTerms           PaymentTerms        `json:"customer_payment_terms" binding:"required"`
 	CustomerPaymentTermsCustomDays int                 `json:"customer_payment_terms_custom_days"`
 	Notes                          string              `json:"notes"`
+	PriceListId                    int                 `json:"price_list_id"`
 	CreditLimit                    decimal.Decimal     `json:"credit_limit"`
+	ExcludeFromReminders           *bool               `json:"exclude_from_reminders"`
 	BillingAddress                 *NewBillingAddress  `json:"billing_address"`
 	ShippingAddress                *NewShippingAddress `json:"shipping_address"`
 	ContactPersons                 []*NewContactPerson `json:"contact_persons"`
@@ -117,6 +125,9 @@ func (input *NewCustomer) validate(ctx context.Context, businessId string, id in
 	if err := utils.ValidateResourceId[Currency](ctx, businessId, input.CurrencyId); err != nil {
 		return errors.New("currency not found")
 	}
+	if err := validatePriceListId(ctx, businessId, input.PriceListId, PriceListTypeSales); err != nil {
+		return err
+	}
 	// validate tax
 	if input.CustomerTaxType != nil {
 		if err := validateTaxExists(ctx, businessId, input.CustomerTaxId, *input.CustomerTaxType); err != nil {
@@ -161,7 +172,10 @@ func CreateCustomer(ctx context.Context, input *NewCustomer) (*Customer, error)
 		CustomerPaymentTerms:           input.CustomerPaymentTerms,
 		CustomerPaymentTermsCustomDays: input.CustomerPaymentTermsCustomDays,
 		Notes:                          input.Notes,
+		PriceListId:                    input.PriceListId,
 		CreditLimit:                    input.CreditLimit,
+		ExcludeFromReminders:           utils.NewFalse(),
+		CreditHold:                     utils.NewFalse(),
 		ContactPersons:                 contactPersons,
 		Documents:                      documents,
 		IsActive:                       utils.NewTrue(),
@@ -169,6 +183,9 @@ func CreateCustomer(ctx context.Context, input *NewCustomer) (*Customer, error)
 		OpeningBalance:                 input.OpeningBalance,
 	}
 
+	if input.ExcludeFromReminders != nil {
+		customer.ExcludeFromReminders = input.ExcludeFromReminders
+	}
 	if input.BillingAddress != nil {
 		customer.BillingAddress = mapBillingAddressInput(*input.BillingAddress)
 	}
@@ -256,7 +273,7 @@ func UpdateCustomer(ctx context.Context, id int, input *NewCustomer) (*Customer,
 	}
 	db := config.GetDB()
 	tx := db.Begin()
-	err = tx.WithContext(ctx).Model(&customer).Updates(map[string]interface{}{
+	updates := map[string]interface{}{
 		"Name":                           input.Name,
 		"Email":                          input.Email,
 		"Phone":                          input.Phone,
@@ -267,12 +284,17 @@ func UpdateCustomer(ctx context.Context, id int, input *NewCustomer) (*Customer,
 		"CustomerPaymentTerms":           input.CustomerPaymentTerms,
 		"CustomerPaymentTermsCustomDays": input.CustomerPaymentTermsCustomDays,
 		"Notes":                          input.Notes,
+		"PriceListId":                    input.PriceListId,
 		"CreditLimit":                    input.CreditLimit,
 		"OpeningBalanceBranchId":         input.OpeningBalanceBranchId,
 		"OpeningBalance":                 input.OpeningBalance,
 		// "ContactPersons":                 customer.ContactPersons,
 		// "Documents":                      customer.Documents,
-	}).Error
+	}
+	if input.ExcludeFromReminders != nil {
+		updates["ExcludeFromReminders"] = *input.ExcludeFromReminders
+	}
+	err = tx.WithContext(ctx).Model(&customer).Updates(updates).Error
 	if err != nil {
 		tx.Rollback()
 		return nil, err
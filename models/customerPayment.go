@@ -13,42 +13,47 @@ import (
 )
 
 type CustomerPayment struct {
-	ID               int             `gorm:"primary_key" json:"id"`
-	BusinessId       string          `gorm:"index;not null" json:"business_id" binding:"required"`
-	CustomerId       int             `gorm:"index;not null" json:"customer_id" binding:"required"`
-	BranchId         int             `gorm:"index;not null" json:"branch_id"`
-	CurrencyId       int             `gorm:"index;not null" json:"currency_id" binding:"required"`
-	ExchangeRate     decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"exchange_rate"`
-	Amount           decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"amount" binding:"required"`
-	BankCharges      decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"bank_charges"`
-	PaymentDate      time.Time       `gorm:"not null" json:"payment_date" binding:"required"`
-	PaymentNumber    string          `gorm:"size:255;not null" json:"payment_number" binding:"required"`
-	SequenceNo       decimal.Decimal `gorm:"type:decimal(15);not null" json:"sequence_no"`
-	PaymentModeId    int             `gorm:"default:null" json:"payment_mode"`
-	DepositAccountId int             `gorm:"default:null" json:"deposit_account_id"`
-	ReferenceNumber  string          `gorm:"size:255;default:null" json:"reference_number"`
-	Notes            string          `gorm:"type:text;default:null" json:"notes"`
-	Documents        []*Document     `gorm:"polymorphic:Reference" json:"documents"`
-	PaidInvoices     []PaidInvoice   `json:"paid_invoices" validate:"required,dive,required"`
-	CreatedAt        time.Time       `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt        time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+	ID           int             `gorm:"primary_key" json:"id"`
+	BusinessId   string          `gorm:"index;not null" json:"business_id" binding:"required"`
+	CustomerId   int             `gorm:"index;not null" json:"customer_id" binding:"required"`
+	BranchId     int             `gorm:"index;not null" json:"branch_id"`
+	CurrencyId   int             `gorm:"index;not null" json:"currency_id" binding:"required"`
+	ExchangeRate decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"exchange_rate"`
+	Amount       decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"amount" binding:"required"`
+	BankCharges  decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"bank_charges"`
+	// Amount settles the invoices in full; only Amount less WithholdingTaxAmount is deposited.
+	WithholdingTaxId     int             `gorm:"default:null" json:"withholding_tax_id"`
+	WithholdingTaxAmount decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"withholding_tax_amount"`
+	PaymentDate          time.Time       `gorm:"not null" json:"payment_date" binding:"required"`
+	PaymentNumber        string          `gorm:"size:255;not null" json:"payment_number" binding:"required"`
+	SequenceNo           decimal.Decimal `gorm:"type:decimal(15);not null" json:"sequence_no"`
+	PaymentModeId        int             `gorm:"default:null" json:"payment_mode"`
+	DepositAccountId     int             `gorm:"default:null" json:"deposit_account_id"`
+	ReferenceNumber      string          `gorm:"size:255;default:null" json:"reference_number"`
+	Notes                string          `gorm:"type:text;default:null" json:"notes"`
+	Documents            []*Document     `gorm:"polymorphic:Reference" json:"documents"`
+	PaidInvoices         []PaidInvoice   `json:"paid_invoices" validate:"required,dive,required"`
+	CreatedAt            time.Time       `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt            time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
 type NewCustomerPayment struct {
-	BusinessId       string           `json:"business_id" binding:"required"`
-	CustomerId       int              `json:"customer_id" binding:"required"`
-	BranchId         int              `json:"branch_id"`
-	CurrencyId       int              `json:"currency_id" binding:"required"`
-	Amount           decimal.Decimal  `json:"amount" binding:"required"`
-	ExchangeRate     decimal.Decimal  `json:"exchange_rate"`
-	BankCharges      decimal.Decimal  `json:"bank_charges"`
-	PaymentDate      time.Time        `json:"payment_date" binding:"required"`
-	PaymentModeId    int              `json:"payment_mode"`
-	DepositAccountId int              `json:"deposit_account_id"`
-	ReferenceNumber  string           `json:"reference_number"`
-	Notes            string           `json:"notes"`
-	Documents        []*NewDocument   `json:"documents"`
-	PaidInvoices     []NewPaidInvoice `json:"paid_invoices"`
+	BusinessId           string           `json:"business_id" binding:"required"`
+	CustomerId           int              `json:"customer_id" binding:"required"`
+	BranchId             int              `json:"branch_id"`
+	CurrencyId           int              `json:"currency_id" binding:"required"`
+	Amount               decimal.Decimal  `json:"amount" binding:"required"`
+	ExchangeRate         decimal.Decimal  `json:"exchange_rate"`
+	BankCharges          decimal.Decimal  `json:"bank_charges"`
+	WithholdingTaxId     int              `json:"withholding_tax_id"`
+	WithholdingTaxAmount decimal.Decimal  `json:"withholding_tax_amount"`
+	PaymentDate          time.Time        `json:"payment_date" binding:"required"`
+	PaymentModeId        int              `json:"payment_mode"`
+	DepositAccountId     int              `json:"deposit_account_id"`
+	ReferenceNumber      string           `json:"reference_number"`
+	Notes                string           `json:"notes"`
+	Documents            []*NewDocument   `json:"documents"`
+	PaidInvoices         []NewPaidInvoice `json:"paid_invoices"`
 }
 
 type CustomerPaymentsEdge Edge[CustomerPayment]
@@ -90,7 +95,10 @@ func (pi *PaidInvoice) GetID() int {
 }
 
 func (cp CustomerPayment) CheckTransactionLock(ctx context.Context) error {
-	return validateTransactionLock(ctx, cp.PaymentDate, cp.BusinessId, SalesTransactionLock)
+	if err := validateTransactionLock(ctx, cp.PaymentDate, cp.BusinessId, SalesTransactionLock); err != nil {
+		return err
+	}
+	return validateBankReconciliationLock(ctx, cp.BusinessId, 0, BankingTransactionTypeCustomerPayment, cp.ID)
 }
 
 func (input NewCustomerPayment) validate(ctx context.Context, businessId string) error {
@@ -125,10 +133,17 @@ func CreateCustomerPayment(ctx context.Context, input *NewCustomerPayment) (*Cus
 	if err := input.validate(ctx, businessId); err != nil {
 		return nil, err
 	}
+	if err := resolveExchangeRate(ctx, businessId, input.CurrencyId, input.PaymentDate, &input.ExchangeRate); err != nil {
+		return nil, err
+	}
 	business, err := GetBusinessById(ctx, businessId)
 	if err != nil {
 		return nil, err
 	}
+	withheld, err := resolveWithholding(ctx, businessId, input.WithholdingTaxId, input.WithholdingTaxAmount, input.Amount)
+	if err != nil {
+		return nil, err
+	}
 
 	db := config.GetDB()
 	tx := db.Begin()
@@ -158,20 +173,22 @@ func CreateCustomerPayment(ctx context.Context, input *NewCustomerPayment) (*Cus
 	}
 
 	customerPayment := CustomerPayment{
-		BusinessId:       businessId,
-		CustomerId:       input.CustomerId,
-		BranchId:         input.BranchId,
-		CurrencyId:       input.CurrencyId,
-		ExchangeRate:     input.ExchangeRate,
-		Amount:           input.Amount,
-		BankCharges:      input.BankCharges,
-		PaymentDate:      input.PaymentDate,
-		PaymentModeId:    input.PaymentModeId,
-		DepositAccountId: input.DepositAccountId,
-		ReferenceNumber:  input.ReferenceNumber,
-		Notes:            input.Notes,
-		Documents:        documents,
-		PaidInvoices:     paidInvoices,
+		BusinessId:           businessId,
+		CustomerId:           input.CustomerId,
+		BranchId:             input.BranchId,
+		CurrencyId:           input.CurrencyId,
+		ExchangeRate:         input.ExchangeRate,
+		Amount:               input.Amount,
+		BankCharges:          input.BankCharges,
+		WithholdingTaxId:     input.WithholdingTaxId,
+		WithholdingTaxAmount: withheld,
+		PaymentDate:          input.PaymentDate,
+		PaymentModeId:        input.PaymentModeId,
+		DepositAccountId:     input.DepositAccountId,
+		ReferenceNumber:      input.ReferenceNumber,
+		Notes:                input.Notes,
+		Documents:            documents,
+		PaidInvoices:         paidInvoices,
 	}
 
 	seqNo, err := utils.GetSequence[CustomerPayment](ctx, businessId)
@@ -199,6 +216,12 @@ func CreateCustomerPayment(ctx context.Context, input *NewCustomerPayment) (*Cus
 		return nil, err
 	}
 
+	err = QueueWebhookEvent(ctx, tx, businessId, WebhookEventPaymentReceived, string(AccountReferenceTypeCustomerPayment), customerPayment.ID, customerPayment)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	if err = tx.Commit().Error; err != nil {
 		return nil, err
 	}
@@ -221,6 +244,10 @@ func UpdateCustomerPayment(ctx context.Context, paymentID int, updatedCustomerPa
 	if err != nil {
 		return nil, err
 	}
+	withheld, err := resolveWithholding(ctx, businessId, updatedCustomerPayment.WithholdingTaxId, updatedCustomerPayment.WithholdingTaxAmount, updatedCustomerPayment.Amount)
+	if err != nil {
+		return nil, err
+	}
 
 	oldCustomerPayment, err := utils.FetchModelForChange[CustomerPayment](ctx, businessId, paymentID)
 	if err != nil {
@@ -256,6 +283,8 @@ func UpdateCustomerPayment(ctx context.Context, paymentID int, updatedCustomerPa
 	existingCustomerPayment.ExchangeRate = updatedCustomerPayment.ExchangeRate
 	existingCustomerPayment.Amount = updatedCustomerPayment.Amount
 	existingCustomerPayment.BankCharges = updatedCustomerPayment.BankCharges
+	existingCustomerPayment.WithholdingTaxId = updatedCustomerPayment.WithholdingTaxId
+	existingCustomerPayment.WithholdingTaxAmount = withheld
 	existingCustomerPayment.PaymentDate = updatedCustomerPayment.PaymentDate
 	existingCustomerPayment.PaymentModeId = updatedCustomerPayment.PaymentModeId
 	existingCustomerPayment.DepositAccountId = updatedCustomerPayment.DepositAccountId
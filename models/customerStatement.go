@@ -0,0 +1,192 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+)
+
+// CustomerStatement is the account activity of one customer between two dates,
+// in the customer's currency.
+type CustomerStatement struct {
+	CustomerId     int                      `json:"customerId"`
+	CustomerName   string                   `json:"customerName"`
+	CurrencyId     int                      `json:"currencyId"`
+	FromDate       time.Time                `json:"fromDate"`
+	ToDate         time.Time                `json:"toDate"`
+	OpeningBalance decimal.Decimal          `json:"openingBalance"`
+	InvoicedAmount decimal.Decimal          `json:"invoicedAmount"`
+	ReceivedAmount decimal.Decimal          `json:"receivedAmount"`
+	ClosingBalance decimal.Decimal          `json:"closingBalance"`
+	Lines          []*CustomerStatementLine `json:"lines"`
+}
+
+// CustomerStatementLine is one transaction. Amount raises the balance (invoices, refunds),
+// Payment lowers it (payments, credit notes, write-offs).
+type CustomerStatementLine struct {
+	Date              time.Time       `json:"date"`
+	TransactionType   string          `json:"transactionType"`
+	ReferenceId       int             `json:"referenceId"`
+	TransactionNumber string          `json:"transactionNumber"`
+	Details           string          `json:"details"`
+	Amount            decimal.Decimal `json:"amount"`
+	Payment           decimal.Decimal `json:"payment"`
+	Balance           decimal.Decimal `json:"balance"`
+}
+
+// statement lines on the same day are ordered charges first
+var customerStatementTypeOrder = map[string]int{
+	"Invoice":     0,
+	"Refund":      1,
+	"Payment":     2,
+	"Credit Note": 3,
+	"Write Off":   4,
+}
+
+func GetCustomerStatement(ctx context.Context, customerId int, fromDate MyDateString, toDate MyDateString) (*CustomerStatement, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	business, err := GetBusiness(ctx)
+	if err != nil {
+		return nil, errors.New("business id is required")
+	}
+	if err := fromDate.StartOfDayUTCTime(business.Timezone); err != nil {
+		return nil, err
+	}
+	if err := toDate.EndOfDayUTCTime(business.Timezone); err != nil {
+		return nil, err
+	}
+	from, to := time.Time(fromDate), time.Time(toDate)
+	if to.Before(from) {
+		return nil, errors.New("to date must be after from date")
+	}
+	customer, err := utils.FetchModel[Customer](ctx, businessId, customerId)
+	if err != nil {
+		return nil, errors.New("customer not found")
+	}
+
+	lines, err := customerStatementLines(ctx, businessId, customer, to)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		if !lines[i].Date.Equal(lines[j].Date) {
+			return lines[i].Date.Before(lines[j].Date)
+		}
+		return customerStatementTypeOrder[lines[i].TransactionType] < customerStatementTypeOrder[lines[j].TransactionType]
+	})
+
+	statement := &CustomerStatement{
+		CustomerId:   customer.ID,
+		CustomerName: customer.Name,
+		CurrencyId:   customer.CurrencyId,
+		FromDate:     from,
+		ToDate:       to,
+		Lines:        make([]*CustomerStatementLine, 0),
+	}
+	balance := decimal.Zero
+	for _, line := range lines {
+		balance = balance.Add(line.Amount).Sub(line.Payment)
+		if line.Date.Before(from) {
+			statement.OpeningBalance = balance
+			continue
+		}
+		line.Balance = balance
+		statement.InvoicedAmount = statement.InvoicedAmount.Add(line.Amount)
+		statement.ReceivedAmount = statement.ReceivedAmount.Add(line.Payment)
+		statement.Lines = append(statement.Lines, line)
+	}
+	statement.ClosingBalance = balance
+	return statement, nil
+}
+
+// customerStatementLines loads every posted transaction of the customer up to toDate.
+func customerStatementLines(ctx context.Context, businessId string, customer *Customer, toDate time.Time) ([]*CustomerStatementLine, error) {
+	db := config.GetDB().WithContext(ctx)
+	lines := make([]*CustomerStatementLine, 0)
+
+	var invoices []SalesInvoice
+	if err := db.Where("business_id = ? AND customer_id = ? AND currency_id = ? AND invoice_date <= ?", businessId, customer.ID, customer.CurrencyId, toDate).
+		Not("current_status IN ?", []SalesInvoiceStatus{SalesInvoiceStatusDraft, SalesInvoiceStatusVoid}).
+		Find(&invoices).Error; err != nil {
+		return nil, err
+	}
+	for _, inv := range invoices {
+		lines = append(lines, &CustomerStatementLine{
+			Date:              inv.InvoiceDate,
+			TransactionType:   "Invoice",
+			ReferenceId:       inv.ID,
+			TransactionNumber: inv.InvoiceNumber,
+			Details:           inv.InvoiceSubject,
+			Amount:            inv.InvoiceTotalAmount,
+		})
+		if inv.WriteOffDate != nil && !inv.WriteOffDate.After(toDate) && inv.InvoiceTotalWriteOffAmount.IsPositive() {
+			lines = append(lines, &CustomerStatementLine{
+				Date:              *inv.WriteOffDate,
+				TransactionType:   "Write Off",
+				ReferenceId:       inv.ID,
+				TransactionNumber: inv.InvoiceNumber,
+				Details:           inv.WriteOffReason,
+				Payment:           inv.InvoiceTotalWriteOffAmount,
+			})
+		}
+	}
+
+	var payments []CustomerPayment
+	if err := db.Where("business_id = ? AND customer_id = ? AND currency_id = ? AND payment_date <= ?", businessId, customer.ID, customer.CurrencyId, toDate).
+		Find(&payments).Error; err != nil {
+		return nil, err
+	}
+	for _, payment := range payments {
+		lines = append(lines, &CustomerStatementLine{
+			Date:              payment.PaymentDate,
+			TransactionType:   "Payment",
+			ReferenceId:       payment.ID,
+			TransactionNumber: payment.PaymentNumber,
+			Details:           payment.ReferenceNumber,
+			Payment:           payment.Amount,
+		})
+	}
+
+	var creditNotes []CreditNote
+	if err := db.Where("business_id = ? AND customer_id = ? AND currency_id = ? AND credit_note_date <= ?", businessId, customer.ID, customer.CurrencyId, toDate).
+		Not("current_status IN ?", []CreditNoteStatus{CreditNoteStatusDraft, CreditNoteStatusVoid}).
+		Find(&creditNotes).Error; err != nil {
+		return nil, err
+	}
+	for _, cn := range creditNotes {
+		lines = append(lines, &CustomerStatementLine{
+			Date:              cn.CreditNoteDate,
+			TransactionType:   "Credit Note",
+			ReferenceId:       cn.ID,
+			TransactionNumber: cn.CreditNoteNumber,
+			Details:           cn.CreditNoteSubject,
+			Payment:           cn.CreditNoteTotalAmount,
+		})
+	}
+
+	var refunds []Refund
+	if err := db.Where("business_id = ? AND customer_id = ? AND currency_id = ? AND refund_date <= ?", businessId, customer.ID, customer.CurrencyId, toDate).
+		Where("reference_type IN ?", []RefundReferenceType{RefundReferenceTypeCreditNote, RefundReferenceTypeCustomerAdvance}).
+		Find(&refunds).Error; err != nil {
+		return nil, err
+	}
+	for _, refund := range refunds {
+		lines = append(lines, &CustomerStatementLine{
+			Date:              refund.RefundDate,
+			TransactionType:   "Refund",
+			ReferenceId:       refund.ID,
+			TransactionNumber: refund.ReferenceNumber,
+			Details:           refund.Description,
+			Amount:            refund.Amount,
+		})
+	}
+	return lines, nil
+}
@@ -51,6 +51,9 @@ func GetDefaultModules() map[string]string {
 		"AccountJournalTransactions":   "read",
 		"AccountTransactionReport":     "read",
 		"AccountTypeSummaryReport":     "read",
+		"ApprovalInbox":                "read",
+		"ApprovalPolicy":               "create;update;delete;read",
+		"ApprovalRequest":              "read;approve",
 		"APAgingDetailReport":          "read",
 		"APAgingSummaryReport":         "read",
 		"ARAgingDetailReport":          "read",
@@ -69,31 +72,44 @@ func GetDefaultModules() map[string]string {
 		"Comment":                      "create;delete",
 		"CreditNote":                   "create;update;delete;read",
 		"CreditNoteDetailsReport":      "read",
+		"CreditControlSetting":         "read;update",
 		"Currency":                     "create;update;delete;read",
+		"CurrencyExchange":             "create;update;delete;read",
 		"CustomerApplyCredit":          "create",
 		"CustomerApplyToInvoice":       "create",
 		"CustomerBalancesReport":       "read",
 		"CustomerBalanceSummaryReport": "read",
-		"Customer":                     "create;update;delete;read",
+		"Customer":                     "create;update;delete;read;hold",
+		"CustomerCreditExposure":       "read",
 		"CustomerCreditInvoice":        "delete",
 		"CustomerPayment":              "create;update;delete;read",
 		"CustomerRefundHistoryReport":  "read",
+		"CustomerStatement":            "read;email",
 		"DeliveryMethod":               "create;update;delete;read",
 		"DetailedGeneralLedgerReport":  "read",
+		"Estimate":                     "create;update;delete;read;convert",
 		"Document":                     "read",
+		"DocumentPdf":                  "read",
+		"EmailMessage":                 "read;update",
+		"EmailSetting":                 "read;update",
+		"EmailTemplate":                "read;update",
 		"Expense":                      "create;update;delete;read",
 		"ExpenseByCategory":            "read",
 		"ExpenseDetailReport":          "read",
 		"ExpenseSummaryByCategory":     "read",
 		"File":                         "upload;remove",
+		"FxRevaluation":                "create;delete;read",
 		"GeneralLedgerReport":          "read",
 		// "History":                          "read", listHistory is allowed by default
 		// "History": "delete"
 		"Image":                           "upload;remove",
 		"InventoryAdjustment":             "create;delete;read",
+		"InventoryValuationMethod":        "update",
+		"InventoryValuationMethodRecord":  "read",
 		"InventorySummaryReport":          "read",
 		"InventoryValuation":              "read",
 		"InventoryValuationSummaryReport": "read",
+		"InTransitTransferReport":         "read",
 		"Journal":                         "create;update;delete;read",
 		"JournalReport":                   "read",
 		"Module":                          "read",
@@ -105,9 +121,13 @@ func GetDefaultModules() map[string]string {
 		"PayableDetailReport":             "read",
 		"PayableSummaryReport":            "read",
 		"PaymentMode":                     "create;update;delete;read",
+		"PaymentReceipt":                  "email",
+		"PaymentReminder":                 "read",
+		"PaymentReminderRule":             "create;update;delete;read",
 		"PaymentsMade":                    "read",
 		"PaymentsReceived":                "read",
 		"PosInvoicePayment":               "create",
+		"PriceList":                       "create;update;delete;read",
 		"ProductCategory":                 "create;update;delete;read",
 		"Product":                         "create;update;delete;read",
 		"ProductGroup":                    "create;update;delete;read",
@@ -115,6 +135,11 @@ func GetDefaultModules() map[string]string {
 		"ProductSalesReport":              "read",
 		"ProductTransactions":             "read",
 		"ProductUnit":                     "create;update;delete;read",
+		"ProductUnitConversion":           "update;read",
+		"ReorderPoint":                    "update;read",
+		"LowStockReport":                  "read",
+		"AbcClassification":               "read",
+		"EffectivePrice":                  "read",
 		"ProductVariant":                  "create;update;delete;read",
 		"ProfitAndLossReport":             "read",
 		"PurchaseOrder":                   "create;update;delete;read",
@@ -131,9 +156,9 @@ func GetDefaultModules() map[string]string {
 		"SalesByCustomerReport":           "read",
 		"SalesByProductReport":            "read",
 		"SalesBySalesPersonReport":        "read",
-		"SalesInvoice":                    "create;update;delete;read",
+		"SalesInvoice":                    "create;update;delete;read;email;override",
 		"SalesInvoiceDetailReport":        "read",
-		"SalesOrder":                      "create;update;delete;read",
+		"SalesOrder":                      "create;update;delete;read;override",
 		"SalesOrderDetailReport":          "read",
 		"SalesPerson":                     "create;update;delete;read",
 		"ShipmentPreference":              "create;update;delete;read",
@@ -151,7 +176,10 @@ func GetDefaultModules() map[string]string {
 		"SupplierRefundHistoryReport":     "read",
 		"Tax":                             "create;update;delete;read",
 		"TaxGroup":                        "create;update;delete;read",
+		"TaxReturn":                       "file;delete;read",
 		"TaxSetting":                      "update",
+		"TaxSummaryDetailReport":          "read",
+		"TaxSummaryReport":                "read",
 		"TopExpense":                      "read",
 		"TotalCashFlow":                   "read",
 		"TotalIncomeExpense":              "read",
@@ -160,7 +188,14 @@ func GetDefaultModules() map[string]string {
 		"TransactionLocking":              "update",
 		"TransactionLockingRecord":        "read",
 		"TransactionNumberSeries":         "create;update;delete;read",
-		"TransferOrder":                   "create;read",
+		"TransferOrder":                   "create;update;read",
+		"TransferOrderReceipt":            "create;delete;read",
+		"BillOfMaterials":                 "create;update;delete;read",
+		"AssemblyOrder":                   "create;update;delete;read",
+		"Stocktake":                       "create;update;delete;read",
+		"LandedCost":                      "create;update;delete;read",
+		"SerialNumber":                    "read",
+		"SerialNumberHistory":             "read",
 		"TrialBalanceReport":              "read",
 		"UnusedCustomerCreditAdvances":    "read",
 		"UnusedCustomerCredits":           "read",
@@ -170,6 +205,11 @@ func GetDefaultModules() map[string]string {
 		// "User":                             "create;update;delete;read",
 		"Warehouse":                        "create;update;delete;read",
 		"WarehouseInventoryReport":         "read",
+		"WebhookDelivery":                  "read;update",
+		"WebhookEndpoint":                  "create;update;delete;read",
+		"WithholdingTax":                   "create;update;delete;read",
+		"WithholdingTaxCertificate":        "read",
+		"WithholdingTaxSummaryReport":      "read",
 		"UnrealisedExchangeGainLossReport": "read",
 		"RealisedExchangeGainLossReport":   "read",
 	}
@@ -266,6 +306,9 @@ func GetQueryPrefixMap() map[string][]string {
 		"AccountJournalTransactions|read":   {"get"},
 		"AccountTransactionReport|read":     {"paginate", "getAll"},
 		"AccountTypeSummaryReport|read":     {"get"},
+		"ApprovalInbox|read":                {"get"},
+		"ApprovalPolicy|read":               {"get", "list"},
+		"ApprovalRequest|read":              {"get", "list"},
 		"AvailableStocks|read":              {"get"},
 		"BalanceSheetReport|read":           {"get"},
 		"BankingAccount|read":               {"list"},
@@ -280,15 +323,25 @@ func GetQueryPrefixMap() map[string][]string {
 		"Comment|read":                      {"get", "list"},
 		"CreditNote|read":                   {"get", "paginate"},
 		"CreditNoteDetailsReport|read":      {"get"},
+		"CreditControlSetting|read":         {"get"},
 		"Currency|read":                     {"get", "list", "listAll"},
+		"CurrencyExchange|read":             {"get", "list"},
+		"FxRevaluation|read":                {"get", "list"},
 		"Customer|read":                     {"get", "list", "paginate"},
+		"CustomerCreditExposure|read":       {"get"},
 		"CustomerBalanceSummaryReport|read": {"get"},
 		"CustomerBalancesReport|read":       {"get"},
 		"CustomerPayment|read":              {"get", "paginate"},
 		"CustomerRefundHistoryReport|read":  {"get"},
+		"CustomerStatement|read":            {"get"},
 		"DeliveryMethod|read":               {"get", "list", "listAll"},
 		"DetailedGeneralLedgerReport|read":  {"paginate", "getAll"},
+		"Estimate|read":                     {"get", "paginate"},
 		"Document|read":                     {"get"},
+		"DocumentPdf|read":                  {"get"},
+		"EmailMessage|read":                 {"get", "paginate"},
+		"EmailSetting|read":                 {"get"},
+		"EmailTemplate|read":                {"list"},
 		"Expense|read":                      {"get", "paginate"},
 		"ExpenseByCategory|read":            {"get"},
 		"ExpenseDetailReport|read":          {"get"},
@@ -296,9 +349,11 @@ func GetQueryPrefixMap() map[string][]string {
 		"GeneralLedgerReport|read":          {"get"},
 		// "History|read":                          {"get", "list", "paginate"},
 		"InventoryAdjustment|read":              {"get", "paginate"},
+		"InventoryValuationMethodRecord|read":   {"list"},
 		"InventorySummaryReport|read":           {"get"},
 		"InventoryValuation|read":               {"get"},
 		"InventoryValuationSummaryReport|read":  {"get"},
+		"InTransitTransferReport|read":          {"get"},
 		"Journal|read":                          {"get", "paginate"},
 		"JournalReport|read":                    {"paginate", "getAll"},
 		"Module|read":                           {"get", "list"},
@@ -310,8 +365,12 @@ func GetQueryPrefixMap() map[string][]string {
 		"PayableOpeningBalanceDetails|read":     {"get"},
 		"PayableSummaryReport|read":             {"get"},
 		"PaymentMode|read":                      {"get", "list", "listAll"},
+		"PaymentReminder|read":                  {"list"},
+		"PaymentReminderRule|read":              {"get", "list"},
 		"PaymentsMade|read":                     {"get"},
 		"PaymentsReceived|read":                 {"get"},
+		"PriceList|read":                        {"get", "list"},
+		"EffectivePrice|read":                   {"get"},
 		"Product|read":                          {"get", "listAll", "paginate"},
 		"ProductCategory|read":                  {"get", "list", "listAll", "paginate"},
 		"ProductGroup|read":                     {"get", "paginate"},
@@ -319,6 +378,10 @@ func GetQueryPrefixMap() map[string][]string {
 		"ProductSalesReport|read":               {"get"},
 		"ProductTransactions|read":              {"get"},
 		"ProductUnit|read":                      {"get", "list", "listAll", "paginate"},
+		"ProductUnitConversion|read":            {"get", "list"},
+		"ReorderPoint|read":                     {"list"},
+		"LowStockReport|read":                   {"get"},
+		"AbcClassification|read":                {"get"},
 		"ProductVariant|read":                   {"get", "listAll", "paginate"},
 		"ProfitAndLossReport|read":              {"get"},
 		"PurchaseOrder|read":                    {"get", "paginate"},
@@ -353,6 +416,9 @@ func GetQueryPrefixMap() map[string][]string {
 		"SupplierRefundHistoryReport|read":      {"get"},
 		"Tax|read":                              {"get", "list", "listAll"},
 		"TaxGroup|read":                         {"get", "list", "listAll"},
+		"TaxReturn|read":                        {"get", "list"},
+		"TaxSummaryDetailReport|read":           {"get"},
+		"TaxSummaryReport|read":                 {"get"},
 		"TopExpense|read":                       {"get"},
 		"TotalCashFlow|read":                    {"get"},
 		"TotalIncomeExpense|read":               {"get"},
@@ -361,6 +427,13 @@ func GetQueryPrefixMap() map[string][]string {
 		"TransactionLockingRecord|read":         {"list"},
 		"TransactionNumberSeries|read":          {"get", "list"},
 		"TransferOrder|read":                    {"get", "paginate"},
+		"TransferOrderReceipt|read":             {"list"},
+		"BillOfMaterials|read":                  {"get", "list"},
+		"AssemblyOrder|read":                    {"get", "paginate"},
+		"Stocktake|read":                        {"get", "paginate"},
+		"LandedCost|read":                       {"get", "paginate"},
+		"SerialNumber|read":                     {"get", "paginate"},
+		"SerialNumberHistory|read":              {"get"},
 		"TrialBalanceReport|read":               {"get"},
 		"UnrealisedExchangeGainLossReport|read": {"get"},
 		"UnusedCustomerCreditAdvances|read":     {"get"},
@@ -371,57 +444,89 @@ func GetQueryPrefixMap() map[string][]string {
 		"UserAccount|read":                      {"get", "list"},
 		"Warehouse|read":                        {"get", "list", "listAll"},
 		"WarehouseInventoryReport|read":         {"get"},
+		"WebhookDelivery|read":                  {"get", "paginate"},
+		"WebhookEndpoint|read":                  {"get", "list"},
+		"WithholdingTax|read":                   {"get", "list"},
+		"WithholdingTaxCertificate|read":        {"get"},
+		"WithholdingTaxSummaryReport|read":      {"get"},
 
 		"Image|upload":      {"uploadSingle", "uploadMultiple"},
 		"Image|remove":      {"removeSingle"},
 		"Attachment|upload": {"create"},
 		"Attachment|remove": {"delete"},
 
-		"Account|update":                 {"toggleActive", "update"},
-		"BankingTransaction|update":      {"update"},
-		"Bill|update":                    {"confirm", "update", "void"},
-		"Branch|update":                  {"toggleActive", "update"},
-		"Business|update":                {"toggleActive", "update"},
-		"CreditNote|update":              {"open", "update", "void"},
-		"Currency|update":                {"toggleActive", "update"},
-		"Customer|update":                {"toggleActive", "update"},
-		"CustomerPayment|update":         {"update"},
-		"DeliveryMethod|update":          {"toggleActive", "update"},
-		"Expense|update":                 {"update"},
-		"Journal|update":                 {"update"},
-		"Module|update":                  {"update"},
-		"MoneyAccount|update":            {"toggleActive", "update"},
-		"OpeningBalance|update":          {"update"},
-		"PaymentMode|update":             {"toggleActive", "update"},
-		"Product|create":                 {"import", "create"},
-		"Product|update":                 {"toggleActive", "update"},
-		"ProductCategory|update":         {"toggleActive", "update"},
-		"ProductGroup|update":            {"toggleActive", "update"},
-		"ProductModifier|update":         {"toggleActive", "update"},
-		"ProductUnit|update":             {"toggleActive", "update"},
-		"ProductVariant|update":          {"toggleActive", "update"},
-		"PurchaseOrder|update":           {"cancel", "confirm", "update"},
-		"Reason|update":                  {"toggleActive", "update"},
-		"RecurringBill|update":           {"update"},
-		"Refund|update":                  {"update"},
-		"Role|update":                    {"update"},
-		"RoleModule|update":              {"save"},
-		"SalesInvoice|update":            {"cancelWriteOff", "confirm", "update", "void", "writeOff"},
-		"SalesOrder|update":              {"cancel", "confirm", "update"},
-		"SalesPerson|update":             {"toggleActive", "update"},
-		"ShipmentPreference|update":      {"toggleActive", "update"},
-		"State|update":                   {"toggleActive", "update"},
-		"Supplier|update":                {"toggleActive", "update"},
-		"SupplierCredit|update":          {"confirm", "update", "void"},
-		"SupplierPayment|update":         {"update"},
-		"Tax|update":                     {"toggleActive", "update"},
-		"TaxGroup|update":                {"toggleActive", "update"},
-		"TaxSetting|update":              {"update"},
-		"Township|update":                {"toggleActive", "update"},
-		"TransactionLocking|update":      {"update"},
-		"TransactionNumberSeries|update": {"update"},
-		"UserAccount|update":             {"toggleActive", "update"},
-		"Warehouse|update":               {"toggleActive", "update"},
+		"Account|update":                  {"toggleActive", "update"},
+		"ApprovalPolicy|update":           {"toggleActive", "update"},
+		"ApprovalRequest|approve":         {"approve", "reject"},
+		"BankingTransaction|update":       {"update"},
+		"Bill|update":                     {"confirm", "update", "void"},
+		"Branch|update":                   {"toggleActive", "update"},
+		"Business|update":                 {"toggleActive", "update"},
+		"CreditNote|update":               {"open", "update", "void"},
+		"Currency|update":                 {"toggleActive", "update"},
+		"CreditControlSetting|update":     {"update"},
+		"Customer|update":                 {"toggleActive", "update"},
+		"Customer|hold":                   {"setCreditHold", "releaseCreditHold"},
+		"CustomerPayment|update":          {"update"},
+		"DeliveryMethod|update":           {"toggleActive", "update"},
+		"EmailMessage|update":             {"retry"},
+		"EmailSetting|update":             {"update"},
+		"EmailTemplate|update":            {"update"},
+		"Estimate|update":                 {"accept", "decline", "send", "update"},
+		"Estimate|convert":                {"createSalesOrderFrom", "createSalesInvoiceFrom"},
+		"CustomerStatement|email":         {"send"},
+		"PaymentReceipt|email":            {"send"},
+		"SalesInvoice|email":              {"send"},
+		"SalesInvoice|override":           {"overrideCreditLimit"},
+		"SalesOrder|override":             {"overrideCreditLimit"},
+		"Expense|update":                  {"update"},
+		"Journal|update":                  {"update"},
+		"Module|update":                   {"update"},
+		"MoneyAccount|update":             {"toggleActive", "update"},
+		"OpeningBalance|update":           {"update"},
+		"PaymentMode|update":              {"toggleActive", "update"},
+		"PaymentReminderRule|update":      {"toggleActive", "update"},
+		"PriceList|update":                {"toggleActive", "update"},
+		"Product|create":                  {"import", "create"},
+		"CurrencyExchange|create":         {"import", "fetch", "create"},
+		"Product|update":                  {"toggleActive", "update"},
+		"ProductCategory|update":          {"toggleActive", "update"},
+		"ProductGroup|update":             {"toggleActive", "update"},
+		"ProductModifier|update":          {"toggleActive", "update"},
+		"ProductUnit|update":              {"toggleActive", "update"},
+		"ProductUnitConversion|update":    {"set"},
+		"ReorderPoint|update":             {"set"},
+		"AssemblyOrder|update":            {"confirm"},
+		"Stocktake|update":                {"count", "recount", "approve"},
+		"LandedCost|update":               {"confirm"},
+		"TransferOrder|update":            {"ship"},
+		"ProductVariant|update":           {"toggleActive", "update"},
+		"PurchaseOrder|create":            {"create", "generate"},
+		"PurchaseOrder|update":            {"cancel", "confirm", "update"},
+		"Reason|update":                   {"toggleActive", "update"},
+		"RecurringBill|update":            {"update"},
+		"Refund|update":                   {"update"},
+		"Role|update":                     {"update"},
+		"RoleModule|update":               {"save"},
+		"SalesInvoice|update":             {"cancelWriteOff", "confirm", "update", "void", "writeOff"},
+		"SalesOrder|update":               {"cancel", "confirm", "update"},
+		"SalesPerson|update":              {"toggleActive", "update"},
+		"ShipmentPreference|update":       {"toggleActive", "update"},
+		"State|update":                    {"toggleActive", "update"},
+		"Supplier|update":                 {"toggleActive", "update"},
+		"SupplierCredit|update":           {"confirm", "update", "void"},
+		"SupplierPayment|update":          {"update"},
+		"Tax|update":                      {"toggleActive", "update"},
+		"TaxGroup|update":                 {"toggleActive", "update"},
+		"TaxSetting|update":               {"update"},
+		"Township|update":                 {"toggleActive", "update"},
+		"TransactionLocking|update":       {"update"},
+		"InventoryValuationMethod|update": {"switch"},
+		"TransactionNumberSeries|update":  {"update"},
+		"UserAccount|update":              {"toggleActive", "update"},
+		"Warehouse|update":                {"toggleActive", "update"},
+		"WebhookDelivery|update":          {"redeliver"},
+		"WebhookEndpoint|update":          {"rotateSecret", "toggleActive", "update"},
 	}
 }
 
@@ -429,6 +534,10 @@ func GetTransactionNumberSeriesDefault() *NewTransactionNumberSeries {
 	series := &NewTransactionNumberSeries{
 		Name: "Default Transaction Series",
 		Modules: []NewTransactionNumberSeriesModule{
+			{
+				ModuleName: "Estimate",
+				Prefix:     "QT-",
+			},
 			{
 				ModuleName: "Sales Order",
 				Prefix:     "SO-",
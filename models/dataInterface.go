@@ -164,6 +164,19 @@ func (d Document) GetDefault(id int) Data {
 	}
 }
 
+func (c FixedAssetCategory) GetId() int {
+	return c.ID
+}
+
+func (c FixedAssetCategory) GetDefault(id int) Data {
+	return FixedAssetCategory{
+		ID:                 id,
+		DepreciationMethod: FixedAssetDepreciationMethodStraightLine,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	}
+}
+
 func (m Module) GetId() int {
 	return m.ID
 }
@@ -498,6 +511,10 @@ func (spb SupplierPaidBill) GetReferenceId() int {
 	return spb.SupplierPaymentId
 }
 
+func (d EstimateDetail) GetReferenceId() int {
+	return d.EstimateId
+}
+
 func (d PurchaseOrderDetail) GetReferenceId() int {
 	return d.PurchaseOrderId
 }
@@ -506,6 +523,14 @@ func (d RecurringBillDetail) GetReferenceId() int {
 	return d.RecurringBillId
 }
 
+func (d RecurringInvoiceDetail) GetReferenceId() int {
+	return d.RecurringInvoiceId
+}
+
+func (m BankStatementMatch) GetReferenceId() int {
+	return m.BankStatementLineId
+}
+
 func (d SalesInvoiceDetail) GetReferenceId() int {
 	return d.SalesInvoiceId
 }
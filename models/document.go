@@ -120,6 +120,7 @@ func GetDocument(ctx context.Context, id int) (*Document, error) {
 		"suppliers":                     "suppliers",
 		"purchase_orders":               "purchase_orders",
 		"bills":                         "bills",
+		"estimates":                     "estimates",
 		"sales_orders":                  "sales_orders",
 		"sales_invoices":                "sales_invoices",
 		"customer_payments":             "customer_payments",
@@ -2,7 +2,8 @@ package models
 
 import "time"
 
-// DocumentTemplate stores per-business document template configuration (UI only).
+// DocumentTemplate stores per-business document template configuration.
+// The browser reads the whole config; the server-side renderer (pdfrender) reads a subset.
 //
 // IMPORTANT: This is NOT a financial “second source of truth”.
 // It only affects rendering (print/PDF/email previews), never posting/COGS/valuation logic.
@@ -28,13 +29,23 @@ const (
 	DocumentTypeCreditNote     = "credit_note"
 	DocumentTypePaymentReceipt = "payment_receipt"
 	DocumentTypeBill           = "bill"
+	DocumentTypeEstimate       = "estimate"
+	DocumentTypeStatement      = "customer_statement"
 )
 
 func IsAllowedDocumentTemplateType(t string) bool {
 	switch t {
-	case DocumentTypeInvoice, DocumentTypeCreditNote, DocumentTypePaymentReceipt, DocumentTypeBill:
+	case DocumentTypeInvoice, DocumentTypeCreditNote, DocumentTypePaymentReceipt, DocumentTypeBill, DocumentTypeEstimate, DocumentTypeStatement:
 		return true
 	default:
 		return false
 	}
 }
+
+// DocumentPdf is a server-rendered PDF stored in object storage.
+type DocumentPdf struct {
+	Url       string    `json:"url"`
+	ObjectKey string    `json:"objectKey"`
+	FileName  string    `json:"fileName"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
@@ -0,0 +1,574 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/utils"
+)
+
+// EmailSetting is the sender identity a business uses for outgoing documents.
+// FromEmail must be accepted by the configured mail relay; when empty the worker
+// falls back to the MAIL_FROM environment variable.
+type EmailSetting struct {
+	ID         int       `gorm:"primary_key" json:"id"`
+	BusinessId string    `gorm:"index;not null" json:"business_id"`
+	FromName   string    `gorm:"size:100" json:"from_name"`
+	FromEmail  string    `gorm:"size:255" json:"from_email"`
+	ReplyTo    string    `gorm:"size:255" json:"reply_to"`
+	BccEmail   string    `gorm:"size:255" json:"bcc_email"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type NewEmailSetting struct {
+	FromName  string `json:"from_name"`
+	FromEmail string `json:"from_email"`
+	ReplyTo   string `json:"reply_to"`
+	BccEmail  string `json:"bcc_email"`
+}
+
+// EmailTemplate overrides the built-in subject and body for one document type.
+// Both are Go text/templates over EmailTemplateData.
+type EmailTemplate struct {
+	ID           int       `gorm:"primary_key" json:"id"`
+	BusinessId   string    `gorm:"index:idx_et_biz_doc,priority:1;not null" json:"business_id"`
+	DocumentType string    `gorm:"index:idx_et_biz_doc,priority:2;size:50;not null" json:"document_type"`
+	Subject      string    `gorm:"size:255;not null" json:"subject"`
+	Body         string    `gorm:"type:text;not null" json:"body"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type NewEmailTemplate struct {
+	DocumentType string `json:"document_type"`
+	Subject      string `json:"subject"`
+	Body         string `json:"body"`
+}
+
+// EmailTemplateData is what subject and body templates can reference, e.g. {{.DocumentNumber}}.
+type EmailTemplateData struct {
+	BusinessName   string
+	CustomerName   string
+	DocumentNumber string
+	DocumentDate   string
+	DueDate        string
+	Amount         string
+	BalanceDue     string
+	FromDate       string
+	ToDate         string
+	DaysOverdue    int
+	LateFee        string
+}
+
+// EmailMessage is a queued email. Rows are delivered by the email dispatcher with
+// retries, the same way PubSubMessageRecord rows are published by the outbox dispatcher.
+// The PDF attachment is rendered at send time from ReferenceType/ReferenceId.
+type EmailMessage struct {
+	ID                int                `gorm:"primary_key;index:idx_email_dispatch,priority:3" json:"id"`
+	BusinessId        string             `gorm:"index;not null" json:"business_id"`
+	ReferenceType     string             `gorm:"size:50;not null;index:idx_email_ref,priority:1" json:"reference_type"`
+	ReferenceId       int                `gorm:"not null;index:idx_email_ref,priority:2" json:"reference_id"`
+	FromName          string             `gorm:"size:100" json:"from_name"`
+	FromEmail         string             `gorm:"size:255" json:"from_email"`
+	ReplyTo           string             `gorm:"size:255" json:"reply_to"`
+	ToAddresses       string             `gorm:"size:1000;not null" json:"to_addresses"`
+	CcAddresses       string             `gorm:"size:1000" json:"cc_addresses"`
+	BccAddresses      string             `gorm:"size:1000" json:"bcc_addresses"`
+	Subject           string             `gorm:"size:255;not null" json:"subject"`
+	Body              string             `gorm:"type:text" json:"body"`
+	AttachPdf         bool               `gorm:"not null;default:true" json:"attach_pdf"`
+	StatementFromDate *time.Time         `gorm:"type:date" json:"statement_from_date"`
+	StatementToDate   *time.Time         `gorm:"type:date" json:"statement_to_date"`
+	Status            EmailMessageStatus `gorm:"size:20;not null;default:'Pending';index:idx_email_dispatch,priority:1" json:"status"`
+	Attempts          int                `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt     *time.Time         `gorm:"index:idx_email_dispatch,priority:2" json:"next_attempt_at"`
+	LockedAt          *time.Time         `json:"locked_at"`
+	LockedBy          *string            `gorm:"size:100" json:"locked_by"`
+	LastError         *string            `gorm:"type:text" json:"last_error"`
+	SentAt            *time.Time         `json:"sent_at"`
+	RequestedById     int                `json:"requested_by_id"`
+	RequestedByName   string             `gorm:"size:100" json:"requested_by_name"`
+	CreatedAt         time.Time          `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time          `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// NewDocumentEmail is the send request; empty fields fall back to the customer's email
+// and the business's email template.
+type NewDocumentEmail struct {
+	To        []string `json:"to"`
+	Cc        []string `json:"cc"`
+	Subject   *string  `json:"subject"`
+	Body      *string  `json:"body"`
+	AttachPdf *bool    `json:"attach_pdf"`
+}
+
+type EmailMessagesConnection struct {
+	Edges    []*EmailMessagesEdge `json:"edges"`
+	PageInfo *PageInfo            `json:"pageInfo"`
+}
+
+type EmailMessagesEdge Edge[EmailMessage]
+
+var defaultEmailTemplates = map[string]NewEmailTemplate{
+	DocumentTypeInvoice: {
+		Subject: "Invoice {{.DocumentNumber}} from {{.BusinessName}}",
+		Body: "Dear {{.CustomerName}},\n\n" +
+			"Please find attached invoice {{.DocumentNumber}} dated {{.DocumentDate}} for {{.Amount}}.\n" +
+			"The balance of {{.BalanceDue}} is due on {{.DueDate}}.\n\n" +
+			"Thank you for your business.\n\n{{.BusinessName}}",
+	},
+	DocumentTypePaymentReceipt: {
+		Subject: "Payment receipt {{.DocumentNumber}} from {{.BusinessName}}",
+		Body: "Dear {{.CustomerName}},\n\n" +
+			"Thank you for your payment of {{.Amount}} received on {{.DocumentDate}}.\n" +
+			"Your receipt {{.DocumentNumber}} is attached.\n\n{{.BusinessName}}",
+	},
+	DocumentTypeStatement: {
+		Subject: "Account statement from {{.BusinessName}}",
+		Body: "Dear {{.CustomerName}},\n\n" +
+			"Please find attached your account statement for {{.FromDate}} to {{.ToDate}}.\n" +
+			"Balance due as of {{.ToDate}}: {{.BalanceDue}}.\n\n{{.BusinessName}}",
+	},
+}
+
+// history reference types are table names, as written by the model hooks
+var emailHistoryReferenceTypes = map[string]string{
+	DocumentTypeInvoice:        "sales_invoices",
+	DocumentTypePaymentReceipt: "customer_payments",
+	DocumentTypeStatement:      "customers",
+}
+
+const emailDateLayout = "02 Jan 2006"
+
+func (m EmailMessage) GetId() int {
+	return m.ID
+}
+
+func (m EmailMessage) GetCursor() string {
+	return m.CreatedAt.String()
+}
+
+func (input *NewEmailSetting) validate() error {
+	for _, addr := range []string{input.FromEmail, input.ReplyTo, input.BccEmail} {
+		if addr = strings.TrimSpace(addr); addr == "" {
+			continue
+		}
+		if _, err := mail.ParseAddress(addr); err != nil {
+			return fmt.Errorf("invalid email address %q", addr)
+		}
+	}
+	return nil
+}
+
+// GetEmailSetting returns the business's sender settings, or defaults taken from
+// the business profile when none have been saved (ID is then 0).
+func GetEmailSetting(ctx context.Context) (*EmailSetting, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	db := config.GetDB()
+	var settings []EmailSetting
+	if err := db.WithContext(ctx).Where("business_id = ?", businessId).Limit(1).Find(&settings).Error; err != nil {
+		return nil, err
+	}
+	if len(settings) > 0 {
+		return &settings[0], nil
+	}
+	business, err := GetBusiness(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &EmailSetting{BusinessId: businessId, FromName: business.Name, ReplyTo: business.Email}, nil
+}
+
+func UpdateEmailSetting(ctx context.Context, input *NewEmailSetting) (*EmailSetting, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if err := input.validate(); err != nil {
+		return nil, err
+	}
+	setting, err := GetEmailSetting(ctx)
+	if err != nil {
+		return nil, err
+	}
+	setting.FromName = strings.TrimSpace(input.FromName)
+	setting.FromEmail = strings.TrimSpace(input.FromEmail)
+	setting.ReplyTo = strings.TrimSpace(input.ReplyTo)
+	setting.BccEmail = strings.TrimSpace(input.BccEmail)
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Save(setting).Error; err != nil {
+		return nil, err
+	}
+	return setting, nil
+}
+
+// ListEmailTemplate returns the subject and body in effect for every emailable
+// document type, saved or built in.
+func ListEmailTemplate(ctx context.Context) ([]*EmailTemplate, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	results := make([]*EmailTemplate, 0, len(defaultEmailTemplates))
+	for _, documentType := range []string{DocumentTypeInvoice, DocumentTypePaymentReceipt, DocumentTypeStatement} {
+		tpl, err := getEmailTemplate(ctx, businessId, documentType)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, tpl)
+	}
+	return results, nil
+}
+
+// UpdateEmailTemplate saves the business's template for a document type.
+// Templates are checked against sample data so mistakes surface here, not at send time.
+func UpdateEmailTemplate(ctx context.Context, input *NewEmailTemplate) (*EmailTemplate, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if _, ok := defaultEmailTemplates[input.DocumentType]; !ok {
+		return nil, errors.New("invalid document type")
+	}
+	if strings.TrimSpace(input.Subject) == "" {
+		return nil, errors.New("subject is required")
+	}
+	if _, err := renderEmailTemplate(input.Subject, EmailTemplateData{}); err != nil {
+		return nil, fmt.Errorf("subject: %w", err)
+	}
+	if _, err := renderEmailTemplate(input.Body, EmailTemplateData{}); err != nil {
+		return nil, fmt.Errorf("body: %w", err)
+	}
+
+	tpl, err := getEmailTemplate(ctx, businessId, input.DocumentType)
+	if err != nil {
+		return nil, err
+	}
+	tpl.Subject = input.Subject
+	tpl.Body = input.Body
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Save(tpl).Error; err != nil {
+		return nil, err
+	}
+	return tpl, nil
+}
+
+func getEmailTemplate(ctx context.Context, businessId string, documentType string) (*EmailTemplate, error) {
+	db := config.GetDB()
+	var templates []EmailTemplate
+	if err := db.WithContext(ctx).
+		Where("business_id = ? AND document_type = ?", businessId, documentType).
+		Limit(1).
+		Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	if len(templates) > 0 {
+		return &templates[0], nil
+	}
+	def := defaultEmailTemplates[documentType]
+	return &EmailTemplate{BusinessId: businessId, DocumentType: documentType, Subject: def.Subject, Body: def.Body}, nil
+}
+
+func renderEmailTemplate(text string, data EmailTemplateData) (string, error) {
+	tpl, err := template.New("email").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// SendSalesInvoice queues an email with the invoice PDF to the customer.
+func SendSalesInvoice(ctx context.Context, id int, input *NewDocumentEmail) (*EmailMessage, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	invoice, err := utils.FetchModel[SalesInvoice](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.CurrentStatus == SalesInvoiceStatusDraft || invoice.CurrentStatus == SalesInvoiceStatusVoid {
+		return nil, fmt.Errorf("cannot send a %s invoice", strings.ToLower(string(invoice.CurrentStatus)))
+	}
+	business, customer, currency, err := emailParties(ctx, businessId, invoice.CustomerId, invoice.CurrencyId)
+	if err != nil {
+		return nil, err
+	}
+	data := EmailTemplateData{
+		BusinessName:   business.Name,
+		CustomerName:   customer.Name,
+		DocumentNumber: invoice.InvoiceNumber,
+		DocumentDate:   formatEmailDate(invoice.InvoiceDate, business.Timezone),
+		Amount:         FormatAmount(invoice.InvoiceTotalAmount, currency.Symbol, currency.DecimalPlaces),
+		BalanceDue:     FormatAmount(invoice.RemainingBalance, currency.Symbol, currency.DecimalPlaces),
+	}
+	if invoice.InvoiceDueDate != nil {
+		data.DueDate = formatEmailDate(*invoice.InvoiceDueDate, business.Timezone)
+	}
+	return queueEmail(ctx, businessId, DocumentTypeInvoice, invoice.ID, customer, data, input, nil, nil)
+}
+
+// SendPaymentReceipt queues an email with the payment receipt PDF to the customer.
+func SendPaymentReceipt(ctx context.Context, id int, input *NewDocumentEmail) (*EmailMessage, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	payment, err := utils.FetchModel[CustomerPayment](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+	business, customer, currency, err := emailParties(ctx, businessId, payment.CustomerId, payment.CurrencyId)
+	if err != nil {
+		return nil, err
+	}
+	data := EmailTemplateData{
+		BusinessName:   business.Name,
+		CustomerName:   customer.Name,
+		DocumentNumber: payment.PaymentNumber,
+		DocumentDate:   formatEmailDate(payment.PaymentDate, business.Timezone),
+		Amount:         FormatAmount(payment.Amount, currency.Symbol, currency.DecimalPlaces),
+	}
+	return queueEmail(ctx, businessId, DocumentTypePaymentReceipt, payment.ID, customer, data, input, nil, nil)
+}
+
+// SendCustomerStatement queues an email with the customer's statement for the period.
+func SendCustomerStatement(ctx context.Context, customerId int, fromDate MyDateString, toDate MyDateString, input *NewDocumentEmail) (*EmailMessage, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	statement, err := GetCustomerStatement(ctx, customerId, fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+	business, customer, currency, err := emailParties(ctx, businessId, customerId, statement.CurrencyId)
+	if err != nil {
+		return nil, err
+	}
+	data := EmailTemplateData{
+		BusinessName: business.Name,
+		CustomerName: customer.Name,
+		FromDate:     formatEmailDate(statement.FromDate, business.Timezone),
+		ToDate:       formatEmailDate(statement.ToDate, business.Timezone),
+		Amount:       FormatAmount(statement.InvoicedAmount, currency.Symbol, currency.DecimalPlaces),
+		BalanceDue:   FormatAmount(statement.ClosingBalance, currency.Symbol, currency.DecimalPlaces),
+	}
+	// keep the dates as requested; GetCustomerStatement converts them to the business's day bounds
+	from, to := time.Time(fromDate), time.Time(toDate)
+	return queueEmail(ctx, businessId, DocumentTypeStatement, customer.ID, customer, data, input, &from, &to)
+}
+
+func emailParties(ctx context.Context, businessId string, customerId int, currencyId int) (*Business, *Customer, *Currency, error) {
+	business, err := GetBusiness(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	customer, err := utils.FetchModel[Customer](ctx, businessId, customerId)
+	if err != nil {
+		return nil, nil, nil, errors.New("customer not found")
+	}
+	currency, err := utils.FetchModel[Currency](ctx, businessId, currencyId)
+	if err != nil {
+		return nil, nil, nil, errors.New("currency not found")
+	}
+	return business, customer, currency, nil
+}
+
+func queueEmail(ctx context.Context, businessId string, documentType string, referenceId int,
+	customer *Customer, data EmailTemplateData, input *NewDocumentEmail, fromDate *time.Time, toDate *time.Time) (*EmailMessage, error) {
+	if input == nil {
+		input = &NewDocumentEmail{}
+	}
+	to, err := normalizeEmailAddresses(input.To)
+	if err != nil {
+		return nil, err
+	}
+	if len(to) == 0 && strings.TrimSpace(customer.Email) != "" {
+		to, err = normalizeEmailAddresses([]string{customer.Email})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(to) == 0 {
+		return nil, errors.New("customer has no email address; specify a recipient")
+	}
+	cc, err := normalizeEmailAddresses(input.Cc)
+	if err != nil {
+		return nil, err
+	}
+
+	tpl, err := getEmailTemplate(ctx, businessId, documentType)
+	if err != nil {
+		return nil, err
+	}
+	subjectText, bodyText := tpl.Subject, tpl.Body
+	if input.Subject != nil && strings.TrimSpace(*input.Subject) != "" {
+		subjectText = *input.Subject
+	}
+	if input.Body != nil && strings.TrimSpace(*input.Body) != "" {
+		bodyText = *input.Body
+	}
+	subject, err := renderEmailTemplate(subjectText, data)
+	if err != nil {
+		return nil, fmt.Errorf("subject: %w", err)
+	}
+	body, err := renderEmailTemplate(bodyText, data)
+	if err != nil {
+		return nil, fmt.Errorf("body: %w", err)
+	}
+
+	setting, err := GetEmailSetting(ctx)
+	if err != nil {
+		return nil, err
+	}
+	userId, _ := utils.GetUserIdFromContext(ctx)
+	userName, _ := utils.GetUserNameFromContext(ctx)
+
+	msg := EmailMessage{
+		BusinessId:        businessId,
+		ReferenceType:     documentType,
+		ReferenceId:       referenceId,
+		FromName:          setting.FromName,
+		FromEmail:         setting.FromEmail,
+		ReplyTo:           setting.ReplyTo,
+		ToAddresses:       strings.Join(to, ","),
+		CcAddresses:       strings.Join(cc, ","),
+		BccAddresses:      setting.BccEmail,
+		Subject:           strings.TrimSpace(subject),
+		Body:              body,
+		AttachPdf:         input.AttachPdf == nil || *input.AttachPdf,
+		StatementFromDate: fromDate,
+		StatementToDate:   toDate,
+		Status:            EmailMessageStatusPending,
+		RequestedById:     userId,
+		RequestedByName:   userName,
+	}
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Create(&msg).Error; err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+func normalizeEmailAddresses(addresses []string) ([]string, error) {
+	results := make([]string, 0, len(addresses))
+	for _, addr := range addresses {
+		addr = strings.TrimSpace(addr)
+		if addr == "" {
+			continue
+		}
+		parsed, err := mail.ParseAddress(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid email address %q", addr)
+		}
+		results = append(results, parsed.Address)
+	}
+	return results, nil
+}
+
+func formatEmailDate(t time.Time, timezone string) string {
+	if timezone == "" {
+		return t.Format(emailDateLayout)
+	}
+	return utils.ConvertToLocalTime(t, timezone).Format(emailDateLayout)
+}
+
+// RetryEmailMessage puts a failed or dead message back in the queue.
+func RetryEmailMessage(ctx context.Context, id int) (*EmailMessage, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	msg, err := utils.FetchModel[EmailMessage](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Status != EmailMessageStatusFailed && msg.Status != EmailMessageStatusDead {
+		return nil, errors.New("only failed emails can be retried")
+	}
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Model(msg).Updates(map[string]interface{}{
+		"status":          EmailMessageStatusPending,
+		"attempts":        0,
+		"next_attempt_at": nil,
+	}).Error; err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// SaveEmailHistory records the outcome of a delivery against the emailed document.
+// ctx carries the message's business and the user who queued it.
+func SaveEmailHistory(ctx context.Context, msg *EmailMessage, sendErr error) error {
+	referenceType, ok := emailHistoryReferenceTypes[msg.ReferenceType]
+	if !ok {
+		referenceType = msg.ReferenceType
+	}
+
+	actionType, description := "EMAIL", fmt.Sprintf("Emailed to %s", strings.ReplaceAll(msg.ToAddresses, ",", ", "))
+	if sendErr != nil {
+		actionType, description = "EMAIL_FAIL", fmt.Sprintf("Email to %s failed: %v", strings.ReplaceAll(msg.ToAddresses, ",", ", "), sendErr)
+	}
+	return createHistory(config.GetDB().WithContext(ctx), actionType, msg.ReferenceId, referenceType, nil, nil, description)
+}
+
+func GetEmailMessage(ctx context.Context, id int) (*EmailMessage, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	return utils.FetchModel[EmailMessage](ctx, businessId, id)
+}
+
+func PaginateEmailMessage(ctx context.Context, limit *int, after *string,
+	referenceType *string,
+	referenceId *int,
+	status *EmailMessageStatus) (*EmailMessagesConnection, error) {
+
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	db := config.GetDB()
+	dbCtx := db.WithContext(ctx).Where("business_id = ?", businessId)
+	if referenceType != nil && *referenceType != "" {
+		dbCtx.Where("reference_type = ?", *referenceType)
+	}
+	if referenceId != nil && *referenceId > 0 {
+		dbCtx.Where("reference_id = ?", *referenceId)
+	}
+	if status != nil {
+		dbCtx.Where("status = ?", *status)
+	}
+
+	edges, pageInfo, err := FetchPageCompositeCursor[EmailMessage](dbCtx, *limit, after, "created_at", "<")
+	if err != nil {
+		return nil, err
+	}
+	var emailMessagesConnection EmailMessagesConnection
+	emailMessagesConnection.PageInfo = pageInfo
+	for _, edge := range edges {
+		emailMessagesEdge := EmailMessagesEdge(edge)
+		emailMessagesConnection.Edges = append(emailMessagesConnection.Edges, &emailMessagesEdge)
+	}
+	return &emailMessagesConnection, err
+}
@@ -276,6 +276,13 @@ const (
 	AccountReferenceTypeOtherIncome                  AccountReferenceType = "OI"
 	AccountReferenceTypeTransferOrder                AccountReferenceType = "TO"
 	AccountReferenceTypePosInvoicePayment            AccountReferenceType = "POSIVP"
+	AccountReferenceTypeFixedAssetDepreciation       AccountReferenceType = "FAD"
+	AccountReferenceTypeFixedAssetDisposal           AccountReferenceType = "FADS"
+	AccountReferenceTypeTaxReturn                    AccountReferenceType = "TXR"
+	AccountReferenceTypeFxRevaluation                AccountReferenceType = "FXR"
+	AccountReferenceTypeAssemblyOrder                AccountReferenceType = "AO"
+	AccountReferenceTypeTransferOrderReceipt         AccountReferenceType = "TOR"
+	AccountReferenceTypeLandedCost                   AccountReferenceType = "LC"
 )
 
 func (t AccountReferenceType) MarshalGQL(w io.Writer) {
@@ -324,6 +331,13 @@ func (t *AccountReferenceType) UnmarshalGQL(i interface{}) error {
 		"OI":     AccountReferenceTypeOtherIncome,
 		"TO":     AccountReferenceTypeTransferOrder,
 		"POSIVP": AccountReferenceTypePosInvoicePayment,
+		"FAD":    AccountReferenceTypeFixedAssetDepreciation,
+		"FADS":   AccountReferenceTypeFixedAssetDisposal,
+		"TXR":    AccountReferenceTypeTaxReturn,
+		"FXR":    AccountReferenceTypeFxRevaluation,
+		"AO":     AccountReferenceTypeAssemblyOrder,
+		"TOR":    AccountReferenceTypeTransferOrderReceipt,
+		"LC":     AccountReferenceTypeLandedCost,
 	}
 
 	*t, ok = accountReferenceType[str]
@@ -944,6 +958,111 @@ func (s *SalesOrderStatus) UnmarshalGQL(i interface{}) error {
 	return nil
 }
 
+type EstimateStatus string
+
+const (
+	EstimateStatusDraft    EstimateStatus = "Draft"
+	EstimateStatusSent     EstimateStatus = "Sent"
+	EstimateStatusAccepted EstimateStatus = "Accepted"
+	EstimateStatusDeclined EstimateStatus = "Declined"
+	EstimateStatusExpired  EstimateStatus = "Expired"
+)
+
+func (s EstimateStatus) MarshalGQL(w io.Writer) {
+	w.Write([]byte(strconv.Quote(string(s))))
+}
+
+func (s *EstimateStatus) UnmarshalGQL(i interface{}) error {
+	str, ok := i.(string)
+	if !ok {
+		return errors.New("estimate status must be string")
+	}
+
+	estimateStatus := map[string]EstimateStatus{
+		"Draft":    EstimateStatusDraft,
+		"Sent":     EstimateStatusSent,
+		"Accepted": EstimateStatusAccepted,
+		"Declined": EstimateStatusDeclined,
+		"Expired":  EstimateStatusExpired,
+	}
+
+	*s, ok = estimateStatus[str]
+	if !ok {
+		return errors.New("invalid estimate status")
+	}
+	return nil
+}
+
+type EmailMessageStatus string
+
+const (
+	EmailMessageStatusPending    EmailMessageStatus = "Pending"
+	EmailMessageStatusProcessing EmailMessageStatus = "Processing"
+	EmailMessageStatusSent       EmailMessageStatus = "Sent"
+	EmailMessageStatusFailed     EmailMessageStatus = "Failed"
+	EmailMessageStatusDead       EmailMessageStatus = "Dead"
+)
+
+func (s EmailMessageStatus) MarshalGQL(w io.Writer) {
+	w.Write([]byte(strconv.Quote(string(s))))
+}
+
+func (s *EmailMessageStatus) UnmarshalGQL(i interface{}) error {
+	str, ok := i.(string)
+	if !ok {
+		return errors.New("email message status must be string")
+	}
+
+	emailMessageStatus := map[string]EmailMessageStatus{
+		"Pending":    EmailMessageStatusPending,
+		"Processing": EmailMessageStatusProcessing,
+		"Sent":       EmailMessageStatusSent,
+		"Failed":     EmailMessageStatusFailed,
+		"Dead":       EmailMessageStatusDead,
+	}
+
+	*s, ok = emailMessageStatus[str]
+	if !ok {
+		return errors.New("invalid email message status")
+	}
+	return nil
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending    WebhookDeliveryStatus = "Pending"
+	WebhookDeliveryStatusProcessing WebhookDeliveryStatus = "Processing"
+	WebhookDeliveryStatusSucceeded  WebhookDeliveryStatus = "Succeeded"
+	WebhookDeliveryStatusFailed     WebhookDeliveryStatus = "Failed"
+	WebhookDeliveryStatusDead       WebhookDeliveryStatus = "Dead"
+)
+
+func (s WebhookDeliveryStatus) MarshalGQL(w io.Writer) {
+	w.Write([]byte(strconv.Quote(string(s))))
+}
+
+func (s *WebhookDeliveryStatus) UnmarshalGQL(i interface{}) error {
+	str, ok := i.(string)
+	if !ok {
+		return errors.New("webhook delivery status must be string")
+	}
+
+	webhookDeliveryStatus := map[string]WebhookDeliveryStatus{
+		"Pending":    WebhookDeliveryStatusPending,
+		"Processing": WebhookDeliveryStatusProcessing,
+		"Succeeded":  WebhookDeliveryStatusSucceeded,
+		"Failed":     WebhookDeliveryStatusFailed,
+		"Dead":       WebhookDeliveryStatusDead,
+	}
+
+	*s, ok = webhookDeliveryStatus[str]
+	if !ok {
+		return errors.New("invalid webhook delivery status")
+	}
+	return nil
+}
+
 type StockReferenceType string
 
 const (
@@ -957,6 +1076,8 @@ const (
 	StockReferenceTypeInventoryAdjustmentQuantity  StockReferenceType = "IVAQ"
 	StockReferenceTypeInventoryAdjustmentValue     StockReferenceType = "IVAV"
 	StockReferenceTypeTransferOrder                StockReferenceType = "TO"
+	StockReferenceTypeAssemblyOrder                StockReferenceType = "AO"
+	StockReferenceTypeTransferOrderReceipt         StockReferenceType = "TOR"
 )
 
 func (t StockReferenceType) MarshalGQL(w io.Writer) {
@@ -980,6 +1101,8 @@ func (t *StockReferenceType) UnmarshalGQL(i interface{}) error {
 		"PGOS": StockReferenceTypeProductGroupOpeningStock,
 		"PCOS": StockReferenceTypeProductCompositeOpeningStock,
 		"TO":   StockReferenceTypeTransferOrder,
+		"AO":   StockReferenceTypeAssemblyOrder,
+		"TOR":  StockReferenceTypeTransferOrderReceipt,
 	}
 
 	*t, ok = stockReferenceType[str]
@@ -1152,6 +1275,7 @@ type TransferOrderStatus string
 const (
 	TransferOrderStatusDraft     TransferOrderStatus = "Draft"
 	TransferOrderStatusConfirmed TransferOrderStatus = "Confirmed"
+	TransferOrderStatusInTransit TransferOrderStatus = "InTransit"
 	TransferOrderStatusClosed    TransferOrderStatus = "Closed"
 )
 
@@ -1168,6 +1292,7 @@ func (s *TransferOrderStatus) UnmarshalGQL(i interface{}) error {
 	transferOrderStatus := map[string]TransferOrderStatus{
 		"Draft":     TransferOrderStatusDraft,
 		"Confirmed": TransferOrderStatusConfirmed,
+		"InTransit": TransferOrderStatusInTransit,
 		"Closed":    TransferOrderStatusClosed,
 	}
 
@@ -1178,6 +1303,128 @@ func (s *TransferOrderStatus) UnmarshalGQL(i interface{}) error {
 	return nil
 }
 
+type AssemblyOrderStatus string
+
+const (
+	AssemblyOrderStatusDraft     AssemblyOrderStatus = "Draft"
+	AssemblyOrderStatusConfirmed AssemblyOrderStatus = "Confirmed"
+)
+
+func (s AssemblyOrderStatus) MarshalGQL(w io.Writer) {
+	w.Write([]byte(strconv.Quote(string(s))))
+}
+
+func (s *AssemblyOrderStatus) UnmarshalGQL(i interface{}) error {
+	str, ok := i.(string)
+	if !ok {
+		return errors.New("assembly order status must be string")
+	}
+
+	assemblyOrderStatus := map[string]AssemblyOrderStatus{
+		"Draft":     AssemblyOrderStatusDraft,
+		"Confirmed": AssemblyOrderStatusConfirmed,
+	}
+
+	*s, ok = assemblyOrderStatus[str]
+	if !ok {
+		return errors.New("invalid assembly order status")
+	}
+	return nil
+}
+
+type LandedCostStatus string
+
+const (
+	LandedCostStatusDraft     LandedCostStatus = "Draft"
+	LandedCostStatusConfirmed LandedCostStatus = "Confirmed"
+	LandedCostStatusVoid      LandedCostStatus = "Void"
+)
+
+func (s LandedCostStatus) MarshalGQL(w io.Writer) {
+	w.Write([]byte(strconv.Quote(string(s))))
+}
+
+func (s *LandedCostStatus) UnmarshalGQL(i interface{}) error {
+	str, ok := i.(string)
+	if !ok {
+		return errors.New("landed cost status must be string")
+	}
+
+	landedCostStatus := map[string]LandedCostStatus{
+		"Draft":     LandedCostStatusDraft,
+		"Confirmed": LandedCostStatusConfirmed,
+		"Void":      LandedCostStatusVoid,
+	}
+
+	*s, ok = landedCostStatus[str]
+	if !ok {
+		return errors.New("invalid landed cost status")
+	}
+	return nil
+}
+
+type SerialNumberStatus string
+
+const (
+	SerialNumberStatusInStock   SerialNumberStatus = "InStock"
+	SerialNumberStatusInTransit SerialNumberStatus = "InTransit"
+	SerialNumberStatusSold      SerialNumberStatus = "Sold"
+	SerialNumberStatusRemoved   SerialNumberStatus = "Removed"
+)
+
+func (s SerialNumberStatus) MarshalGQL(w io.Writer) {
+	w.Write([]byte(strconv.Quote(string(s))))
+}
+
+func (s *SerialNumberStatus) UnmarshalGQL(i interface{}) error {
+	str, ok := i.(string)
+	if !ok {
+		return errors.New("serial number status must be string")
+	}
+
+	serialNumberStatus := map[string]SerialNumberStatus{
+		"InStock":   SerialNumberStatusInStock,
+		"InTransit": SerialNumberStatusInTransit,
+		"Sold":      SerialNumberStatusSold,
+		"Removed":   SerialNumberStatusRemoved,
+	}
+
+	*s, ok = serialNumberStatus[str]
+	if !ok {
+		return errors.New("invalid serial number status")
+	}
+	return nil
+}
+
+type StocktakeStatus string
+
+const (
+	StocktakeStatusCounting StocktakeStatus = "Counting"
+	StocktakeStatusApproved StocktakeStatus = "Approved"
+)
+
+func (s StocktakeStatus) MarshalGQL(w io.Writer) {
+	w.Write([]byte(strconv.Quote(string(s))))
+}
+
+func (s *StocktakeStatus) UnmarshalGQL(i interface{}) error {
+	str, ok := i.(string)
+	if !ok {
+		return errors.New("stocktake status must be string")
+	}
+
+	stocktakeStatus := map[string]StocktakeStatus{
+		"Counting": StocktakeStatusCounting,
+		"Approved": StocktakeStatusApproved,
+	}
+
+	*s, ok = stocktakeStatus[str]
+	if !ok {
+		return errors.New("invalid stocktake status")
+	}
+	return nil
+}
+
 type InventoryAdjustmentStatus string
 
 const (
@@ -0,0 +1,776 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// Estimate is a quotation sent to a customer. It never touches stock or the ledger;
+// once accepted it is converted into a draft sales order or sales invoice.
+type Estimate struct {
+	ID                          int              `gorm:"primary_key" json:"id"`
+	BusinessId                  string           `gorm:"index;not null" json:"business_id" binding:"required"`
+	CustomerId                  int              `gorm:"index;not null" json:"customer_id" binding:"required"`
+	BranchId                    int              `gorm:"index;not null" json:"branch_id" binding:"required"`
+	EstimateNumber              string           `gorm:"size:255;not null" json:"estimate_number" binding:"required"`
+	SequenceNo                  decimal.Decimal  `gorm:"type:decimal(15);not null" json:"sequence_no"`
+	ReferenceNumber             string           `gorm:"size:255" json:"reference_number"`
+	EstimateDate                time.Time        `gorm:"not null" json:"estimate_date" binding:"required"`
+	ExpiryDate                  *time.Time       `gorm:"index" json:"expiry_date"`
+	SalesPersonId               int              `json:"sales_person_id"`
+	Subject                     string           `gorm:"size:255" json:"subject"`
+	Notes                       string           `gorm:"type:text" json:"notes"`
+	TermsAndConditions          string           `gorm:"type:text" json:"terms_and_conditions"`
+	CurrencyId                  int              `gorm:"not null" json:"currency_id" binding:"required"`
+	ExchangeRate                decimal.Decimal  `gorm:"type:decimal(20,4);default:0" json:"exchange_rate"`
+	WarehouseId                 int              `gorm:"not null" json:"warehouse_id" binding:"required"`
+	EstimateDiscount            decimal.Decimal  `gorm:"type:decimal(20,4);default:0" json:"estimate_discount"`
+	EstimateDiscountType        *DiscountType    `gorm:"type:enum('P', 'A');default:null" json:"estimate_discount_type"`
+	EstimateDiscountAmount      decimal.Decimal  `gorm:"type:decimal(20,4);default:0" json:"estimate_discount_amount"`
+	ShippingCharges             decimal.Decimal  `gorm:"type:decimal(20,4);default:0" json:"shipping_charges"`
+	AdjustmentAmount            decimal.Decimal  `gorm:"type:decimal(20,4);default:0" json:"adjustment_amount"`
+	IsTaxInclusive              *bool            `gorm:"not null;default:false" json:"is_tax_inclusive"`
+	EstimateTaxId               int              `json:"estimate_tax_id"`
+	EstimateTaxType             *TaxType         `gorm:"type:enum('I', 'G');default:null" json:"estimate_tax_type"`
+	EstimateTaxAmount           decimal.Decimal  `gorm:"type:decimal(20,4);default:0" json:"estimate_tax_amount"`
+	CurrentStatus               EstimateStatus   `gorm:"type:enum('Draft', 'Sent', 'Accepted', 'Declined', 'Expired');not null" json:"current_status" binding:"required"`
+	Documents                   []*Document      `gorm:"polymorphic:Reference" json:"documents"`
+	EstimateSubtotal            decimal.Decimal  `gorm:"type:decimal(20,4);default:0" json:"estimate_subtotal"`
+	EstimateTotalDiscountAmount decimal.Decimal  `gorm:"type:decimal(20,4);default:0" json:"estimate_total_discount_amount"`
+	EstimateTotalTaxAmount      decimal.Decimal  `gorm:"type:decimal(20,4);default:0" json:"estimate_total_tax_amount"`
+	EstimateTotalAmount         decimal.Decimal  `gorm:"type:decimal(20,4);default:0" json:"estimate_total_amount"`
+	SalesOrderId                int              `gorm:"index;default:null" json:"sales_order_id"`
+	SalesInvoiceId              int              `gorm:"index;default:null" json:"sales_invoice_id"`
+	CreatedAt                   time.Time        `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt                   time.Time        `gorm:"autoUpdateTime" json:"updated_at"`
+	Details                     []EstimateDetail `gorm:"foreignKey:EstimateId" json:"details"`
+}
+
+type NewEstimate struct {
+	CustomerId           int                 `json:"customer_id" binding:"required"`
+	BranchId             int                 `json:"branch_id" binding:"required"`
+	ReferenceNumber      string              `json:"reference_number"`
+	EstimateDate         time.Time           `json:"estimate_date" binding:"required"`
+	ExpiryDate           *time.Time          `json:"expiry_date"`
+	SalesPersonId        int                 `json:"sales_person_id"`
+	Subject              string              `json:"subject"`
+	Notes                string              `json:"notes"`
+	TermsAndConditions   string              `json:"terms_and_conditions"`
+	CurrencyId           int                 `json:"currency_id" binding:"required"`
+	ExchangeRate         decimal.Decimal     `json:"exchange_rate"`
+	WarehouseId          int                 `json:"warehouse_id" binding:"required"`
+	EstimateDiscount     decimal.Decimal     `json:"estimate_discount"`
+	EstimateDiscountType *DiscountType       `json:"estimate_discount_type"`
+	ShippingCharges      decimal.Decimal     `json:"shipping_charges"`
+	AdjustmentAmount     decimal.Decimal     `json:"adjustment_amount"`
+	IsTaxInclusive       *bool               `json:"is_tax_inclusive" binding:"required"`
+	EstimateTaxId        int                 `json:"estimate_tax_id"`
+	EstimateTaxType      *TaxType            `json:"estimate_tax_type"`
+	Documents            []*NewDocument      `json:"documents"`
+	Details              []NewEstimateDetail `json:"details"`
+}
+
+type EstimateDetail struct {
+	ID                   int             `gorm:"primary_key" json:"id"`
+	EstimateId           int             `gorm:"index;not null" json:"estimate_id" binding:"required"`
+	ProductId            int             `json:"product_id"`
+	ProductType          ProductType     `gorm:"type:enum('S','G','C','V','I');default:S" json:"product_type"`
+	BatchNumber          string          `gorm:"size:100" json:"batch_number"`
+	Name                 string          `gorm:"size:100" json:"name" binding:"required"`
+	Description          string          `gorm:"size:255" json:"description"`
+	DetailQty            decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_qty" binding:"required"`
+	DetailUnitRate       decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_unit_rate" binding:"required"`
+	DetailDiscount       decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_discount"`
+	DetailDiscountType   *DiscountType   `gorm:"type:enum('P', 'A');default:null" json:"detail_discount_type"`
+	DetailTaxId          int             `json:"detail_tax_id"`
+	DetailTaxType        *TaxType        `gorm:"type:enum('I', 'G');default:null" json:"detail_tax_type"`
+	DetailAccountId      int             `gorm:"default:null" json:"detail_account_id"`
+	DetailDiscountAmount decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_discount_amount"`
+	DetailTaxAmount      decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_tax_amount"`
+	DetailTotalAmount    decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_total_amount"`
+}
+
+type NewEstimateDetail struct {
+	DetailId           int             `json:"detail_id"`
+	ProductId          int             `json:"product_id"`
+	ProductType        ProductType     `json:"product_type"`
+	BatchNumber        string          `json:"batch_number"`
+	Name               string          `json:"name" binding:"required"`
+	Description        string          `json:"description"`
+	DetailQty          decimal.Decimal `json:"detail_qty" binding:"required"`
+	DetailUnitRate     decimal.Decimal `json:"detail_unit_rate" binding:"required"`
+	DetailDiscount     decimal.Decimal `json:"detail_discount"`
+	DetailDiscountType *DiscountType   `json:"detail_discount_type"`
+	DetailTaxId        int             `json:"detail_tax_id"`
+	DetailTaxType      *TaxType        `json:"detail_tax_type"`
+	DetailAccountId    int             `json:"detail_account_id"`
+	IsDeletedItem      *bool           `json:"is_deleted_item"`
+}
+
+type EstimatesConnection struct {
+	Edges    []*EstimatesEdge `json:"edges"`
+	PageInfo *PageInfo        `json:"pageInfo"`
+}
+
+type EstimatesEdge Edge[Estimate]
+
+// allowed manual status changes; Expired is only set by the scheduler
+var estimateStatusTransitions = map[EstimateStatus][]EstimateStatus{
+	EstimateStatusDraft: {EstimateStatusSent},
+	EstimateStatusSent:  {EstimateStatusAccepted, EstimateStatusDeclined},
+}
+
+func (e Estimate) GetCursor() string {
+	return e.CreatedAt.String()
+}
+
+func (e Estimate) GetId() int {
+	return e.ID
+}
+
+// estimates do not post anything, so there is no period to lock
+func (e Estimate) CheckTransactionLock(ctx context.Context) error {
+	return nil
+}
+
+// IsConverted reports whether a sales order or invoice has been created from the estimate.
+func (e Estimate) IsConverted() bool {
+	return e.SalesOrderId > 0 || e.SalesInvoiceId > 0
+}
+
+// IsExpiredAt reports whether the expiry date has passed by now.
+// The expiry date is the last day the estimate is valid.
+func (e Estimate) IsExpiredAt(now time.Time) bool {
+	if e.ExpiryDate == nil {
+		return false
+	}
+	return !e.ExpiryDate.AddDate(0, 0, 1).After(now)
+}
+
+// CanTransitionTo validates a manual status change.
+func (e Estimate) CanTransitionTo(status EstimateStatus, now time.Time) error {
+	if e.IsConverted() {
+		return errors.New("estimate has already been converted")
+	}
+	for _, next := range estimateStatusTransitions[e.CurrentStatus] {
+		if next == status {
+			if status != EstimateStatusDeclined && e.IsExpiredAt(now) {
+				return errors.New("estimate has expired")
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot change estimate from %s to %s", e.CurrentStatus, status)
+}
+
+// CanConvert validates converting the estimate into a sales order or invoice.
+func (e Estimate) CanConvert(now time.Time) error {
+	if e.IsConverted() {
+		return errors.New("estimate has already been converted")
+	}
+	switch e.CurrentStatus {
+	case EstimateStatusDeclined:
+		return errors.New("cannot convert a declined estimate")
+	case EstimateStatusExpired:
+		return errors.New("cannot convert an expired estimate")
+	}
+	if e.IsExpiredAt(now) {
+		return errors.New("cannot convert an expired estimate")
+	}
+	return nil
+}
+
+func (input NewEstimate) validate(ctx context.Context, businessId string) error {
+	// exists customer
+	if err := utils.ValidateResourceId[Customer](ctx, businessId, input.CustomerId); err != nil {
+		return errors.New("customer not found")
+	}
+	// exists branch
+	if err := utils.ValidateResourceId[Branch](ctx, businessId, input.BranchId); err != nil {
+		return errors.New("branch not found")
+	}
+	// exists Currency
+	if err := utils.ValidateResourceId[Currency](ctx, businessId, input.CurrencyId); err != nil {
+		return errors.New("currency not found")
+	}
+	// exists warehouse
+	if err := utils.ValidateResourceId[Warehouse](ctx, businessId, input.WarehouseId); err != nil {
+		return errors.New("warehouse not found")
+	}
+	if input.SalesPersonId > 0 {
+		if err := utils.ValidateResourceId[SalesPerson](ctx, businessId, input.SalesPersonId); err != nil {
+			return errors.New("salesPerson not found")
+		}
+	}
+	if input.ExpiryDate != nil && input.ExpiryDate.Before(input.EstimateDate) {
+		return errors.New("expiry date must not be before estimate date")
+	}
+	if input.IsTaxInclusive == nil {
+		return errors.New("isTaxInclusive is required")
+	}
+	return nil
+}
+
+func (item *EstimateDetail) CalculateItemDiscountAndTax(ctx context.Context, isTaxInclusive bool) {
+	db := config.GetDB()
+
+	// calculate discount amount
+	detailAmount := item.DetailQty.Mul(item.DetailUnitRate)
+	var discountAmount decimal.Decimal
+	if item.DetailDiscountType != nil {
+		discountAmount = utils.CalculateDiscountAmount(detailAmount, item.DetailDiscount, string(*item.DetailDiscountType))
+	}
+	item.DetailDiscountAmount = discountAmount
+	item.DetailTotalAmount = detailAmount.Sub(discountAmount)
+
+	taxAmount := decimal.NewFromFloat(0)
+	if item.DetailTaxId > 0 && item.DetailTaxType != nil {
+		taxAmount = utils.CalculateTaxAmount(ctx, db, item.DetailTaxId, *item.DetailTaxType == TaxTypeGroup, item.DetailTotalAmount, isTaxInclusive)
+	}
+	item.DetailTaxAmount = taxAmount
+}
+
+// calculateTotals recomputes line and header amounts the same way sales orders do.
+func (e *Estimate) calculateTotals(ctx context.Context) {
+	db := config.GetDB()
+	isTaxInclusive := e.IsTaxInclusive != nil && *e.IsTaxInclusive
+
+	var subtotal, totalExclusiveTaxAmount, totalDetailDiscountAmount, totalDetailTaxAmount decimal.Decimal
+	for i := range e.Details {
+		item := &e.Details[i]
+		item.CalculateItemDiscountAndTax(ctx, isTaxInclusive)
+		subtotal = subtotal.Add(item.DetailTotalAmount)
+		totalDetailDiscountAmount = totalDetailDiscountAmount.Add(item.DetailDiscountAmount)
+		totalDetailTaxAmount = totalDetailTaxAmount.Add(item.DetailTaxAmount)
+		if !isTaxInclusive {
+			totalExclusiveTaxAmount = totalExclusiveTaxAmount.Add(item.DetailTaxAmount)
+		}
+	}
+
+	var discountAmount decimal.Decimal
+	if e.EstimateDiscountType != nil {
+		discountAmount = utils.CalculateDiscountAmount(subtotal, e.EstimateDiscount, string(*e.EstimateDiscountType))
+	}
+
+	// estimate level tax is always exclusive
+	taxAmount := decimal.NewFromFloat(0)
+	if e.EstimateTaxId > 0 && e.EstimateTaxType != nil {
+		taxAmount = utils.CalculateTaxAmount(ctx, db, e.EstimateTaxId, *e.EstimateTaxType == TaxTypeGroup, subtotal, false)
+	}
+
+	e.EstimateSubtotal = subtotal
+	e.EstimateDiscountAmount = discountAmount
+	e.EstimateTaxAmount = taxAmount
+	e.EstimateTotalDiscountAmount = discountAmount.Add(totalDetailDiscountAmount)
+	e.EstimateTotalTaxAmount = taxAmount.Add(totalDetailTaxAmount)
+	e.EstimateTotalAmount = subtotal.Add(taxAmount).Add(totalExclusiveTaxAmount).Add(e.AdjustmentAmount).Add(e.ShippingCharges).Sub(discountAmount)
+}
+
+func (e *Estimate) applyInput(input *NewEstimate) {
+	e.CustomerId = input.CustomerId
+	e.BranchId = input.BranchId
+	e.ReferenceNumber = input.ReferenceNumber
+	e.EstimateDate = input.EstimateDate
+	e.ExpiryDate = input.ExpiryDate
+	e.SalesPersonId = input.SalesPersonId
+	e.Subject = input.Subject
+	e.Notes = input.Notes
+	e.TermsAndConditions = input.TermsAndConditions
+	e.CurrencyId = input.CurrencyId
+	e.ExchangeRate = input.ExchangeRate
+	e.WarehouseId = input.WarehouseId
+	e.EstimateDiscount = input.EstimateDiscount
+	e.EstimateDiscountType = input.EstimateDiscountType
+	e.ShippingCharges = input.ShippingCharges
+	e.AdjustmentAmount = input.AdjustmentAmount
+	e.IsTaxInclusive = input.IsTaxInclusive
+	e.EstimateTaxId = input.EstimateTaxId
+	e.EstimateTaxType = input.EstimateTaxType
+}
+
+func (item *EstimateDetail) applyInput(input NewEstimateDetail) {
+	item.ProductId = input.ProductId
+	item.ProductType = input.ProductType
+	item.BatchNumber = input.BatchNumber
+	item.Name = input.Name
+	item.Description = input.Description
+	item.DetailQty = input.DetailQty
+	item.DetailUnitRate = input.DetailUnitRate
+	item.DetailDiscount = input.DetailDiscount
+	item.DetailDiscountType = input.DetailDiscountType
+	item.DetailTaxId = input.DetailTaxId
+	item.DetailTaxType = input.DetailTaxType
+	item.DetailAccountId = input.DetailAccountId
+}
+
+func CreateEstimate(ctx context.Context, input *NewEstimate) (*Estimate, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if err := input.validate(ctx, businessId); err != nil {
+		return nil, err
+	}
+
+	documents, err := mapNewDocuments(input.Documents, "estimates", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	estimate := Estimate{
+		BusinessId:    businessId,
+		CurrentStatus: EstimateStatusDraft,
+		Documents:     documents,
+	}
+	estimate.applyInput(input)
+	for _, item := range input.Details {
+		var detail EstimateDetail
+		detail.applyInput(item)
+		estimate.Details = append(estimate.Details, detail)
+	}
+	estimate.calculateTotals(ctx)
+
+	db := config.GetDB()
+	tx := db.Begin()
+	seqNo, err := utils.GetSequence[Estimate](ctx, businessId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	prefix, err := getTransactionPrefix(ctx, input.BranchId, "Estimate")
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	estimate.SequenceNo = decimal.NewFromInt(seqNo)
+	estimate.EstimateNumber = prefix + fmt.Sprint(seqNo)
+
+	if err := tx.WithContext(ctx).Create(&estimate).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return &estimate, nil
+}
+
+// UpdateEstimate edits an estimate that has not been accepted or converted.
+// Declined and expired estimates go back to draft so they have to be sent again.
+func UpdateEstimate(ctx context.Context, id int, input *NewEstimate) (*Estimate, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if err := input.validate(ctx, businessId); err != nil {
+		return nil, err
+	}
+	estimate, err := utils.FetchModelForChange[Estimate](ctx, businessId, id, "Details")
+	if err != nil {
+		return nil, err
+	}
+	if estimate.IsConverted() {
+		return nil, errors.New("cannot edit an estimate that has been converted")
+	}
+	if estimate.CurrentStatus == EstimateStatusAccepted {
+		return nil, errors.New("cannot edit an accepted estimate")
+	}
+
+	estimate.applyInput(input)
+	if estimate.CurrentStatus == EstimateStatusDeclined || estimate.CurrentStatus == EstimateStatusExpired {
+		estimate.CurrentStatus = EstimateStatusDraft
+	}
+
+	existingByID := make(map[int]EstimateDetail, len(estimate.Details))
+	for _, detail := range estimate.Details {
+		existingByID[detail.ID] = detail
+	}
+	var details []EstimateDetail
+	var deletedIds []int
+	for _, item := range input.Details {
+		if item.IsDeletedItem != nil && *item.IsDeletedItem {
+			if item.DetailId > 0 {
+				deletedIds = append(deletedIds, item.DetailId)
+			}
+			continue
+		}
+		detail := EstimateDetail{EstimateId: id}
+		if item.DetailId > 0 {
+			existing, ok := existingByID[item.DetailId]
+			if !ok {
+				return nil, errors.New("estimate detail not found")
+			}
+			detail = existing
+		}
+		detail.applyInput(item)
+		details = append(details, detail)
+	}
+	estimate.Details = details
+	estimate.calculateTotals(ctx)
+
+	db := config.GetDB()
+	tx := db.Begin()
+	if len(deletedIds) > 0 {
+		if err := tx.WithContext(ctx).Where("estimate_id = ? AND id IN ?", id, deletedIds).Delete(&EstimateDetail{}).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	for i := range estimate.Details {
+		if err := tx.WithContext(ctx).Save(&estimate.Details[i]).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	if err := tx.WithContext(ctx).Omit("Details").Save(estimate).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	documents, err := upsertDocuments(ctx, tx, input.Documents, "estimates", id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	estimate.Documents = documents
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return estimate, nil
+}
+
+func DeleteEstimate(ctx context.Context, id int) (*Estimate, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	result, err := utils.FetchModelForChange[Estimate](ctx, businessId, id, "Documents", "Details")
+	if err != nil {
+		return nil, utils.ErrorRecordNotFound
+	}
+	if result.IsConverted() {
+		return nil, errors.New("cannot delete an estimate that has been converted")
+	}
+
+	db := config.GetDB()
+	tx := db.Begin()
+	if err := tx.WithContext(ctx).Model(&result).Association("Details").Unscoped().Clear(); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.WithContext(ctx).Delete(&result).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := deleteDocuments(ctx, tx, result.Documents); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func UpdateStatusEstimate(ctx context.Context, id int, status EstimateStatus) (*Estimate, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	estimate, err := utils.FetchModelForChange[Estimate](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := estimate.CanTransitionTo(status, time.Now()); err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	// guard against a concurrent change of status
+	result := db.WithContext(ctx).Model(estimate).
+		Where("current_status = ?", estimate.CurrentStatus).
+		Update("CurrentStatus", status)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("estimate status has changed, please reload")
+	}
+	estimate.CurrentStatus = status
+	return estimate, nil
+}
+
+// ConvertEstimateToSalesOrder creates a draft sales order with the estimate's lines,
+// taxes and discounts, and marks the estimate accepted.
+func ConvertEstimateToSalesOrder(ctx context.Context, id int) (*SalesOrder, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	estimate, customer, err := fetchEstimateForConversion(ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	details := make([]NewSalesOrderDetail, 0, len(estimate.Details))
+	for _, item := range estimate.Details {
+		details = append(details, NewSalesOrderDetail{
+			ProductId:          item.ProductId,
+			ProductType:        item.ProductType,
+			BatchNumber:        item.BatchNumber,
+			Name:               item.Name,
+			Description:        item.Description,
+			DetailQty:          item.DetailQty,
+			DetailUnitRate:     item.DetailUnitRate,
+			DetailDiscount:     item.DetailDiscount,
+			DetailDiscountType: item.DetailDiscountType,
+			DetailTaxId:        item.DetailTaxId,
+			DetailTaxType:      item.DetailTaxType,
+			DetailAccountId:    item.DetailAccountId,
+		})
+	}
+	salesOrder, err := CreateSalesOrder(ctx, &NewSalesOrder{
+		CustomerId:                  estimate.CustomerId,
+		BranchId:                    estimate.BranchId,
+		ReferenceNumber:             estimate.EstimateNumber,
+		OrderDate:                   time.Now(),
+		OrderPaymentTerms:           customer.CustomerPaymentTerms,
+		OrderPaymentTermsCustomDays: customer.CustomerPaymentTermsCustomDays,
+		SalesPersonId:               estimate.SalesPersonId,
+		Notes:                       estimate.Notes,
+		TermsAndConditions:          estimate.TermsAndConditions,
+		CurrencyId:                  estimate.CurrencyId,
+		ExchangeRate:                estimate.ExchangeRate,
+		OrderDiscount:               estimate.EstimateDiscount,
+		OrderDiscountType:           estimate.EstimateDiscountType,
+		ShippingCharges:             estimate.ShippingCharges,
+		AdjustmentAmount:            estimate.AdjustmentAmount,
+		IsTaxInclusive:              estimate.IsTaxInclusive,
+		OrderTaxId:                  estimate.EstimateTaxId,
+		OrderTaxType:                estimate.EstimateTaxType,
+		CurrentStatus:               SalesOrderStatusDraft,
+		WarehouseId:                 estimate.WarehouseId,
+		EstimateId:                  estimate.ID,
+		Details:                     details,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return salesOrder, nil
+}
+
+// ConvertEstimateToSalesInvoice creates a draft sales invoice with the estimate's lines,
+// taxes and discounts, and marks the estimate accepted.
+func ConvertEstimateToSalesInvoice(ctx context.Context, id int) (*SalesInvoice, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	estimate, customer, err := fetchEstimateForConversion(ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	details := make([]NewSalesInvoiceDetail, 0, len(estimate.Details))
+	for _, item := range estimate.Details {
+		details = append(details, NewSalesInvoiceDetail{
+			ProductId:          item.ProductId,
+			ProductType:        item.ProductType,
+			BatchNumber:        item.BatchNumber,
+			Name:               item.Name,
+			Description:        item.Description,
+			DetailAccountId:    item.DetailAccountId,
+			DetailQty:          item.DetailQty,
+			DetailUnitRate:     item.DetailUnitRate,
+			DetailTaxId:        item.DetailTaxId,
+			DetailTaxType:      item.DetailTaxType,
+			DetailDiscount:     item.DetailDiscount,
+			DetailDiscountType: item.DetailDiscountType,
+		})
+	}
+	salesInvoice, err := CreateSalesInvoice(ctx, &NewSalesInvoice{
+		CustomerId:                    estimate.CustomerId,
+		BranchId:                      estimate.BranchId,
+		ReferenceNumber:               estimate.EstimateNumber,
+		InvoiceDate:                   time.Now(),
+		InvoicePaymentTerms:           customer.CustomerPaymentTerms,
+		InvoicePaymentTermsCustomDays: customer.CustomerPaymentTermsCustomDays,
+		SalesPersonId:                 estimate.SalesPersonId,
+		InvoiceSubject:                estimate.Subject,
+		Notes:                         estimate.Notes,
+		TermsAndConditions:            estimate.TermsAndConditions,
+		CurrencyId:                    estimate.CurrencyId,
+		ExchangeRate:                  estimate.ExchangeRate,
+		WarehouseId:                   estimate.WarehouseId,
+		InvoiceDiscount:               estimate.EstimateDiscount,
+		InvoiceDiscountType:           estimate.EstimateDiscountType,
+		ShippingCharges:               estimate.ShippingCharges,
+		AdjustmentAmount:              estimate.AdjustmentAmount,
+		IsTaxInclusive:                estimate.IsTaxInclusive,
+		InvoiceTaxId:                  estimate.EstimateTaxId,
+		InvoiceTaxType:                estimate.EstimateTaxType,
+		CurrentStatus:                 SalesInvoiceStatusDraft,
+		EstimateId:                    estimate.ID,
+		Details:                       details,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return salesInvoice, nil
+}
+
+func fetchEstimateForConversion(ctx context.Context, businessId string, id int) (*Estimate, *Customer, error) {
+	estimate, err := utils.FetchModel[Estimate](ctx, businessId, id, "Details")
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := estimate.CanConvert(time.Now()); err != nil {
+		return nil, nil, err
+	}
+	customer, err := utils.FetchModel[Customer](ctx, businessId, estimate.CustomerId)
+	if err != nil {
+		return nil, nil, errors.New("customer not found")
+	}
+	return estimate, customer, nil
+}
+
+// markEstimateConverted records the back-reference only if no other conversion got there
+// first. It runs in the transaction creating the document, so a conversion that loses the
+// race rolls its document back with it.
+func markEstimateConverted(tx *gorm.DB, estimateId int, column string, referenceId int) error {
+	result := tx.Model(&Estimate{ID: estimateId}).
+		Where("COALESCE(sales_order_id, 0) = 0 AND COALESCE(sales_invoice_id, 0) = 0").
+		Updates(map[string]interface{}{
+			"current_status": EstimateStatusAccepted,
+			column:           referenceId,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("estimate has already been converted")
+	}
+	return nil
+}
+
+// releaseEstimate clears the back-reference when the converted document is deleted,
+// so the estimate can be converted again.
+func releaseEstimate(tx *gorm.DB, estimateId int, column string, referenceId int) error {
+	return tx.Model(&Estimate{ID: estimateId}).
+		Where(column+" = ?", referenceId).
+		Update(column, gorm.Expr("NULL")).Error
+}
+
+// GetDueExpiredEstimates returns sent estimates whose expiry date has passed, ordered by
+// expiry date and id and starting after the estimate `after` when it is given.
+func GetDueExpiredEstimates(ctx context.Context, now time.Time, after *Estimate, limit int) ([]*Estimate, error) {
+	db := config.GetDB()
+	var results []*Estimate
+	query := db.WithContext(ctx).
+		Where("current_status = ? AND expiry_date IS NOT NULL AND expiry_date <= ? AND COALESCE(sales_order_id, 0) = 0 AND COALESCE(sales_invoice_id, 0) = 0",
+			EstimateStatusSent, now.AddDate(0, 0, -1))
+	if after != nil && after.ExpiryDate != nil {
+		query = query.Where("(expiry_date > ? OR (expiry_date = ? AND id > ?))",
+			*after.ExpiryDate, *after.ExpiryDate, after.ID)
+	}
+	err := query.
+		Order("expiry_date, id").
+		Limit(limit).
+		Find(&results).Error
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// ExpireEstimate marks a sent estimate as expired once its expiry date has passed.
+func ExpireEstimate(ctx context.Context, id int, now time.Time) error {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return errors.New("business id is required")
+	}
+	estimate, err := utils.FetchModel[Estimate](ctx, businessId, id)
+	if err != nil {
+		return err
+	}
+	if estimate.CurrentStatus != EstimateStatusSent || estimate.IsConverted() || !estimate.IsExpiredAt(now) {
+		return nil
+	}
+	db := config.GetDB()
+	return db.WithContext(ctx).Model(estimate).
+		Where("current_status = ?", EstimateStatusSent).
+		Update("CurrentStatus", EstimateStatusExpired).Error
+}
+
+func GetEstimate(ctx context.Context, id int) (*Estimate, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	return utils.FetchModel[Estimate](ctx, businessId, id)
+}
+
+func PaginateEstimate(ctx context.Context, limit *int, after *string,
+	estimateNumber *string,
+	referenceNumber *string,
+	branchID *int,
+	customerID *int,
+	status *EstimateStatus,
+	startEstimateDate *MyDateString,
+	endEstimateDate *MyDateString) (*EstimatesConnection, error) {
+
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	business, err := GetBusiness(ctx)
+	if err != nil {
+		return nil, errors.New("business id is required")
+	}
+	if err := startEstimateDate.StartOfDayUTCTime(business.Timezone); err != nil {
+		return nil, err
+	}
+	if err := endEstimateDate.EndOfDayUTCTime(business.Timezone); err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	dbCtx := db.WithContext(ctx).Where("business_id = ?", businessId)
+	if estimateNumber != nil && *estimateNumber != "" {
+		dbCtx.Where("estimate_number LIKE ?", "%"+*estimateNumber+"%")
+	}
+	if referenceNumber != nil && *referenceNumber != "" {
+		dbCtx.Where("reference_number LIKE ?", "%"+*referenceNumber+"%")
+	}
+	if branchID != nil && *branchID > 0 {
+		dbCtx.Where("branch_id = ?", *branchID)
+	}
+	if customerID != nil && *customerID > 0 {
+		dbCtx.Where("customer_id = ?", *customerID)
+	}
+	if status != nil {
+		dbCtx.Where("current_status = ?", *status)
+	}
+	if startEstimateDate != nil && endEstimateDate != nil {
+		dbCtx.Where("estimate_date BETWEEN ? AND ?", startEstimateDate, endEstimateDate)
+	}
+
+	edges, pageInfo, err := FetchPageCompositeCursor[Estimate](dbCtx, *limit, after, "created_at", "<")
+	if err != nil {
+		return nil, err
+	}
+	var estimatesConnection EstimatesConnection
+	estimatesConnection.PageInfo = pageInfo
+	for _, edge := range edges {
+		estimatesEdge := EstimatesEdge(edge)
+		estimatesConnection.Edges = append(estimatesConnection.Edges, &estimatesEdge)
+	}
+	return &estimatesConnection, err
+}
@@ -0,0 +1,72 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/models"
+)
+
+func TestEstimateIsExpiredAt(t *testing.T) {
+	expiry := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	estimate := models.Estimate{ExpiryDate: &expiry}
+
+	if estimate.IsExpiredAt(expiry.Add(23 * time.Hour)) {
+		t.Fatal("estimate should still be valid on its expiry date")
+	}
+	if !estimate.IsExpiredAt(expiry.AddDate(0, 0, 1)) {
+		t.Fatal("estimate should expire the day after its expiry date")
+	}
+	if (models.Estimate{}).IsExpiredAt(expiry.AddDate(1, 0, 0)) {
+		t.Fatal("estimate without expiry date should never expire")
+	}
+}
+
+func TestEstimateCanTransitionTo(t *testing.T) {
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		from  models.EstimateStatus
+		to    models.EstimateStatus
+		valid bool
+	}{
+		{models.EstimateStatusDraft, models.EstimateStatusSent, true},
+		{models.EstimateStatusDraft, models.EstimateStatusAccepted, false},
+		{models.EstimateStatusSent, models.EstimateStatusAccepted, true},
+		{models.EstimateStatusSent, models.EstimateStatusDeclined, true},
+		{models.EstimateStatusDeclined, models.EstimateStatusAccepted, false},
+		{models.EstimateStatusExpired, models.EstimateStatusSent, false},
+		{models.EstimateStatusAccepted, models.EstimateStatusDeclined, false},
+	}
+	for _, c := range cases {
+		err := models.Estimate{CurrentStatus: c.from}.CanTransitionTo(c.to, now)
+		if (err == nil) != c.valid {
+			t.Errorf("%s -> %s: got err %v, want valid %v", c.from, c.to, err, c.valid)
+		}
+	}
+
+	expiry := now.AddDate(0, 0, -2)
+	expired := models.Estimate{CurrentStatus: models.EstimateStatusSent, ExpiryDate: &expiry}
+	if err := expired.CanTransitionTo(models.EstimateStatusAccepted, now); err == nil {
+		t.Error("accepting an estimate past its expiry date should fail")
+	}
+	if err := expired.CanTransitionTo(models.EstimateStatusDeclined, now); err != nil {
+		t.Errorf("declining an estimate past its expiry date: %v", err)
+	}
+}
+
+func TestEstimateCanConvert(t *testing.T) {
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	if err := (models.Estimate{CurrentStatus: models.EstimateStatusSent}).CanConvert(now); err != nil {
+		t.Fatalf("sent estimate: %v", err)
+	}
+	if err := (models.Estimate{CurrentStatus: models.EstimateStatusDeclined}).CanConvert(now); err == nil {
+		t.Fatal("declined estimate should not convert")
+	}
+	if err := (models.Estimate{CurrentStatus: models.EstimateStatusAccepted, SalesOrderId: 5}).CanConvert(now); err == nil {
+		t.Fatal("converted estimate should not convert again")
+	}
+	expiry := now.AddDate(0, 0, -1)
+	if err := (models.Estimate{CurrentStatus: models.EstimateStatusSent, ExpiryDate: &expiry}).CanConvert(now); err == nil {
+		t.Fatal("estimate past its expiry date should not convert")
+	}
+}
@@ -0,0 +1,320 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+)
+
+const maxExchangeRateFileSizeBytes = 2 << 20
+
+// ExchangeRateQuote is the rate of one foreign currency on a calendar date, in base
+// currency per unit of the foreign currency, the way CurrencyExchange stores it.
+type ExchangeRateQuote struct {
+	CurrencySymbol string
+	Date           time.Time
+	Rate           decimal.Decimal
+}
+
+// ExchangeRateProvider supplies the rates of a day against a base currency.
+type ExchangeRateProvider interface {
+	FetchRates(ctx context.Context, baseCurrency string, date time.Time) ([]ExchangeRateQuote, error)
+}
+
+// ParseExchangeRateCsv reads rates from a CSV file with a header row and the columns
+// date (YYYY-MM-DD), currency symbol and rate.
+func ParseExchangeRateCsv(r io.Reader) ([]ExchangeRateQuote, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var quotes []ExchangeRateQuote
+	for i, row := range rows {
+		if i == 0 || len(row) == 0 || (len(row) == 1 && strings.TrimSpace(row[0]) == "") {
+			continue
+		}
+		if len(row) < 3 {
+			return nil, fmt.Errorf("row %d: date, currency and rate are required", i+1)
+		}
+		date, err := time.Parse("2006-01-02", strings.TrimPrefix(strings.TrimSpace(row[0]), "\ufeff"))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid date %q", i+1, row[0])
+		}
+		rate, err := decimal.NewFromString(strings.TrimSpace(row[2]))
+		if err != nil || !rate.IsPositive() {
+			return nil, fmt.Errorf("row %d: invalid rate %q", i+1, row[2])
+		}
+		quotes = append(quotes, ExchangeRateQuote{
+			CurrencySymbol: strings.ToUpper(strings.TrimSpace(row[1])),
+			Date:           date,
+			Rate:           rate,
+		})
+	}
+	return quotes, nil
+}
+
+// CsvExchangeRateProvider serves rates from a CSV file in the ParseExchangeRateCsv layout.
+type CsvExchangeRateProvider struct {
+	Path string
+}
+
+func (p *CsvExchangeRateProvider) FetchRates(ctx context.Context, baseCurrency string, date time.Time) ([]ExchangeRateQuote, error) {
+	f, err := os.Open(p.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	quotes, err := ParseExchangeRateCsv(f)
+	if err != nil {
+		return nil, err
+	}
+	var results []ExchangeRateQuote
+	for _, quote := range quotes {
+		if quote.Date.Format("2006-01-02") == date.Format("2006-01-02") {
+			results = append(results, quote)
+		}
+	}
+	return results, nil
+}
+
+// HTTPExchangeRateProvider calls URL with base and date query parameters and expects
+// {"base": "USD", "date": "2024-01-31", "rates": {"EUR": 0.92, ...}}, rates being units
+// of each currency per one unit of base, the way most public feeds quote them.
+type HTTPExchangeRateProvider struct {
+	URL    string
+	Client *http.Client
+}
+
+type httpExchangeRateResponse struct {
+	Base  string                     `json:"base"`
+	Date  string                     `json:"date"`
+	Rates map[string]decimal.Decimal `json:"rates"`
+}
+
+func NewHTTPExchangeRateProvider(url string) *HTTPExchangeRateProvider {
+	return &HTTPExchangeRateProvider{
+		URL:    url,
+		Client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (p *HTTPExchangeRateProvider) FetchRates(ctx context.Context, baseCurrency string, date time.Time) ([]ExchangeRateQuote, error) {
+	u, err := url.Parse(p.URL)
+	if err != nil {
+		return nil, err
+	}
+	query := u.Query()
+	query.Set("base", baseCurrency)
+	query.Set("date", date.Format("2006-01-02"))
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("exchange rate provider responded %s", resp.Status)
+	}
+
+	var body httpExchangeRateResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxExchangeRateFileSizeBytes)).Decode(&body); err != nil {
+		return nil, err
+	}
+	if body.Base != "" && !strings.EqualFold(body.Base, baseCurrency) {
+		return nil, fmt.Errorf("exchange rate provider quoted %s instead of %s", body.Base, baseCurrency)
+	}
+
+	var quotes []ExchangeRateQuote
+	for symbol, perBase := range body.Rates {
+		if !perBase.IsPositive() || strings.EqualFold(symbol, baseCurrency) {
+			continue
+		}
+		quotes = append(quotes, ExchangeRateQuote{
+			CurrencySymbol: strings.ToUpper(symbol),
+			Date:           date,
+			Rate:           decimal.NewFromInt(1).DivRound(perBase, 4),
+		})
+	}
+	return quotes, nil
+}
+
+var exchangeRateProvider ExchangeRateProvider
+
+// SetExchangeRateProvider replaces the provider fetchCurrencyExchange uses.
+func SetExchangeRateProvider(provider ExchangeRateProvider) {
+	exchangeRateProvider = provider
+}
+
+// getExchangeRateProvider returns the provider set with SetExchangeRateProvider, or the one
+// configured by env:
+// - EXCHANGE_RATE_PROVIDER_URL: an HTTPExchangeRateProvider feed
+// - EXCHANGE_RATE_FILE: a CsvExchangeRateProvider file
+func getExchangeRateProvider() (ExchangeRateProvider, error) {
+	if exchangeRateProvider != nil {
+		return exchangeRateProvider, nil
+	}
+	if v := strings.TrimSpace(os.Getenv("EXCHANGE_RATE_PROVIDER_URL")); v != "" {
+		return NewHTTPExchangeRateProvider(v), nil
+	}
+	if v := strings.TrimSpace(os.Getenv("EXCHANGE_RATE_FILE")); v != "" {
+		return &CsvExchangeRateProvider{Path: v}, nil
+	}
+	return nil, errors.New("no exchange rate provider is configured")
+}
+
+// ImportCurrencyExchange saves the rates of an uploaded CSV file, replacing rates already
+// recorded for the same currency and date.
+func ImportCurrencyExchange(ctx context.Context, file graphql.Upload) ([]*CurrencyExchange, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if file.File == nil {
+		return nil, errors.New("nil file provided")
+	}
+	if !strings.HasSuffix(strings.ToLower(file.Filename), ".csv") {
+		return nil, errors.New("invalid file type: only .csv files are allowed")
+	}
+	data, err := io.ReadAll(io.LimitReader(file.File, maxExchangeRateFileSizeBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxExchangeRateFileSizeBytes {
+		return nil, errors.New("exchange rate file is too large")
+	}
+	quotes, err := ParseExchangeRateCsv(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return saveExchangeRateQuotes(ctx, businessId, quotes, "Imported from file", true)
+}
+
+// FetchCurrencyExchange saves the configured provider's rates of date for the business's
+// currencies. Currencies the provider does not quote are left as they are.
+func FetchCurrencyExchange(ctx context.Context, date MyDateString) ([]*CurrencyExchange, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	business, err := GetBusinessById(ctx, businessId)
+	if err != nil {
+		return nil, err
+	}
+	var baseCurrency Currency
+	if err := config.GetDB().WithContext(ctx).First(&baseCurrency, business.BaseCurrencyId).Error; err != nil {
+		return nil, err
+	}
+	provider, err := getExchangeRateProvider()
+	if err != nil {
+		return nil, err
+	}
+	quotes, err := provider.FetchRates(ctx, baseCurrency.Symbol, time.Time(date))
+	if err != nil {
+		return nil, err
+	}
+	return saveExchangeRateQuotes(ctx, businessId, quotes, "Fetched from exchange rate provider", false)
+}
+
+// saveExchangeRateQuotes upserts the quotes by currency and date. Quotes for unknown
+// currencies fail when strict is set and are skipped otherwise.
+func saveExchangeRateQuotes(ctx context.Context, businessId string, quotes []ExchangeRateQuote, notes string, strict bool) ([]*CurrencyExchange, error) {
+	business, err := GetBusinessById(ctx, businessId)
+	if err != nil {
+		return nil, err
+	}
+	location, err := time.LoadLocation(business.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	db := config.GetDB()
+	var currencies []Currency
+	if err := db.WithContext(ctx).Where("business_id = ?", businessId).Find(&currencies).Error; err != nil {
+		return nil, err
+	}
+	currencyIds := make(map[string]int)
+	for _, currency := range currencies {
+		if currency.ID != business.BaseCurrencyId {
+			currencyIds[strings.ToUpper(currency.Symbol)] = currency.ID
+		}
+	}
+
+	tx := db.Begin()
+	var results []*CurrencyExchange
+	for _, quote := range quotes {
+		currencyId, ok := currencyIds[quote.CurrencySymbol]
+		if !ok {
+			if strict {
+				tx.Rollback()
+				return nil, fmt.Errorf("currency %s not found", quote.CurrencySymbol)
+			}
+			continue
+		}
+		exchangeDate := time.Date(quote.Date.Year(), quote.Date.Month(), quote.Date.Day(), 0, 0, 0, 0, location).UTC()
+		if err := validateTransactionLock(ctx, exchangeDate, businessId, AccountantTransactionLock); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		var exchange CurrencyExchange
+		err := tx.WithContext(ctx).
+			Where("business_id = ? AND foreign_currency_id = ? AND exchange_date = ?", businessId, currencyId, exchangeDate).
+			Limit(1).Find(&exchange).Error
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		exchange.BusinessId = businessId
+		exchange.ForeignCurrencyId = currencyId
+		exchange.ExchangeDate = exchangeDate
+		exchange.ExchangeRate = quote.Rate
+		exchange.Notes = notes
+		if err := tx.WithContext(ctx).Save(&exchange).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		results = append(results, &exchange)
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// resolveExchangeRate fills in the rate of a foreign currency document created without
+// one from the latest rate recorded on or before its date.
+func resolveExchangeRate(ctx context.Context, businessId string, currencyId int, date time.Time, exchangeRate *decimal.Decimal) error {
+	if !exchangeRate.IsZero() {
+		return nil
+	}
+	rate, err := GetExchangeRateAsOf(ctx, businessId, currencyId, date)
+	if err != nil {
+		return errors.New("exchange rate is required: no rate is recorded for the document date")
+	}
+	*exchangeRate = rate
+	return nil
+}
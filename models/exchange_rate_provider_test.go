@@ -0,0 +1,110 @@
+package models_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/shopspring/decimal"
+)
+
+func TestParseExchangeRateCsv(t *testing.T) {
+	csv := "\ufeffdate,currency,rate\n2024-01-31, usd ,2100.5\n\n2024-01-31,THB,60\n"
+	quotes, err := models.ParseExchangeRateCsv(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(quotes) != 2 {
+		t.Fatalf("got %d quotes, want 2", len(quotes))
+	}
+	if quotes[0].CurrencySymbol != "USD" || !quotes[0].Rate.Equal(decimal.RequireFromString("2100.5")) {
+		t.Errorf("unexpected quote %+v", quotes[0])
+	}
+	if quotes[1].Date.Format("2006-01-02") != "2024-01-31" {
+		t.Errorf("unexpected date %v", quotes[1].Date)
+	}
+
+	for _, bad := range []string{
+		"date,currency,rate\n31/01/2024,USD,1\n",
+		"date,currency,rate\n2024-01-31,USD,-1\n",
+		"date,currency,rate\n2024-01-31,USD\n",
+	} {
+		if _, err := models.ParseExchangeRateCsv(strings.NewReader(bad)); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestHTTPExchangeRateProvider(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"base":"MMK","date":"2024-01-31","rates":{"USD":0.0005,"THB":0.016,"MMK":1,"XXX":0}}`))
+	}))
+	defer srv.Close()
+
+	provider := models.NewHTTPExchangeRateProvider(srv.URL + "/rates?key=k")
+	date := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	quotes, err := provider.FetchRates(context.Background(), "MMK", date)
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if !strings.Contains(query, "base=MMK") || !strings.Contains(query, "date=2024-01-31") || !strings.Contains(query, "key=k") {
+		t.Errorf("unexpected query %q", query)
+	}
+	sort.Slice(quotes, func(i, j int) bool { return quotes[i].CurrencySymbol < quotes[j].CurrencySymbol })
+	if len(quotes) != 2 {
+		t.Fatalf("got %d quotes, want 2", len(quotes))
+	}
+	// quoted per unit of base, stored per unit of foreign currency
+	if quotes[0].CurrencySymbol != "THB" || !quotes[0].Rate.Equal(decimal.RequireFromString("62.5")) {
+		t.Errorf("unexpected quote %+v", quotes[0])
+	}
+	if quotes[1].CurrencySymbol != "USD" || !quotes[1].Rate.Equal(decimal.NewFromInt(2000)) {
+		t.Errorf("unexpected quote %+v", quotes[1])
+	}
+
+	if _, err := provider.FetchRates(context.Background(), "USD", date); err == nil {
+		t.Error("a feed quoted against another base should be rejected")
+	}
+}
+
+func TestRevalueFxBalances(t *testing.T) {
+	d := decimal.RequireFromString
+	balances := []*models.FxRevaluationBalance{
+		// receivable of 100 USD booked at 2000
+		{AccountId: 1, CurrencyId: 2, ForeignBalance: d("100"), BaseBalance: d("200000")},
+		// payable of 50 USD booked at 2050
+		{AccountId: 2, CurrencyId: 2, ForeignBalance: d("-50"), BaseBalance: d("-102500")},
+		// already at the closing rate
+		{AccountId: 3, CurrencyId: 3, ForeignBalance: d("10"), BaseBalance: d("600")},
+	}
+	rates := map[int]decimal.Decimal{2: d("2100"), 3: d("60")}
+
+	lines, total, err := models.RevalueFxBalances(balances, rates)
+	if err != nil {
+		t.Fatalf("revalue: %v", err)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	if !lines[0].GainLoss.Equal(d("10000")) || !lines[0].RevaluedBalance.Equal(d("210000")) {
+		t.Errorf("receivable: %+v", lines[0])
+	}
+	if !lines[1].GainLoss.Equal(d("-2500")) {
+		t.Errorf("payable: %+v", lines[1])
+	}
+	if !total.Equal(d("7500")) {
+		t.Errorf("total = %s, want 7500", total)
+	}
+
+	if _, _, err := models.RevalueFxBalances(balances, map[int]decimal.Decimal{2: d("2100")}); err == nil {
+		t.Error("a currency without a rate should be an error")
+	}
+}
@@ -36,28 +36,31 @@ type Expense struct {
 	Documents                []*Document     `gorm:"polymorphic:Reference" json:"documents"`
 	ExpenseTotalRefundAmount decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"expense_total_refund_amount"`
 	RemainingBalance         decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"remaining_balance"`
+	RecurringExpenseId       int             `gorm:"index;default:null" json:"recurring_expense_id"`
+	ApprovalStatus           *ApprovalStatus `gorm:"size:20;default:null" json:"approval_status"`
 	CreatedAt                time.Time       `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt                time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
 type NewExpense struct {
-	BusinessId       string          `json:"business_id" binding:"required"`
-	ExpenseAccountId int             `json:"expense_account_id" binding:"required"`
-	AssetAccountId   int             `json:"asset_account_id" binding:"required"`
-	BranchId         int             `json:"branch_id"`
-	ExpenseDate      time.Time       `json:"expense_date" binding:"required"`
-	CurrencyId       int             `json:"currency_id" binding:"required"`
-	ExchangeRate     decimal.Decimal `json:"exchange_rate"`
-	Amount           decimal.Decimal `json:"amount"`
-	BankCharges      decimal.Decimal `json:"bank_charges"`
-	SupplierId       int             `json:"supplier_id"`
-	CustomerId       int             `json:"customer_id"`
-	ReferenceNumber  string          `json:"reference_number"`
-	Notes            string          `json:"notes"`
-	ExpenseTaxId     int             `json:"expense_tax_id"`
-	ExpenseTaxType   *TaxType        `json:"expense_tax_type"`
-	IsTaxInclusive   *bool           `json:"is_tax_inclusive" binding:"required"`
-	Documents        []*NewDocument  `json:"documents"`
+	BusinessId         string          `json:"business_id" binding:"required"`
+	ExpenseAccountId   int             `json:"expense_account_id" binding:"required"`
+	AssetAccountId     int             `json:"asset_account_id" binding:"required"`
+	BranchId           int             `json:"branch_id"`
+	ExpenseDate        time.Time       `json:"expense_date" binding:"required"`
+	CurrencyId         int             `json:"currency_id" binding:"required"`
+	ExchangeRate       decimal.Decimal `json:"exchange_rate"`
+	Amount             decimal.Decimal `json:"amount"`
+	BankCharges        decimal.Decimal `json:"bank_charges"`
+	SupplierId         int             `json:"supplier_id"`
+	CustomerId         int             `json:"customer_id"`
+	ReferenceNumber    string          `json:"reference_number"`
+	Notes              string          `json:"notes"`
+	ExpenseTaxId       int             `json:"expense_tax_id"`
+	ExpenseTaxType     *TaxType        `json:"expense_tax_type"`
+	IsTaxInclusive     *bool           `json:"is_tax_inclusive" binding:"required"`
+	Documents          []*NewDocument  `json:"documents"`
+	RecurringExpenseId int             `json:"recurring_expense_id"`
 }
 
 type ExpensesEdge Edge[Expense]
@@ -102,8 +105,23 @@ func (e *Expense) GetDueDate() time.Time {
 	return e.ExpenseDate
 }
 
+func (e Expense) approvalSubject() approvalSubject {
+	return approvalSubject{
+		DocumentType:    ApprovalDocumentTypeExpense,
+		ReferenceId:     e.ID,
+		ReferenceNumber: e.ExpenseNumber,
+		BranchId:        e.BranchId,
+		CurrencyId:      e.CurrencyId,
+		ExchangeRate:    e.ExchangeRate,
+		Amount:          e.TotalAmount,
+	}
+}
+
 func (e Expense) CheckTransactionLock(ctx context.Context) error {
-	return validateTransactionLock(ctx, e.ExpenseDate, e.BusinessId, PurchaseTransactionLock)
+	if err := validateTransactionLock(ctx, e.ExpenseDate, e.BusinessId, PurchaseTransactionLock); err != nil {
+		return err
+	}
+	return validateBankReconciliationLock(ctx, e.BusinessId, 0, BankingTransactionTypeExpense, e.ID)
 }
 
 // validate input for both create & update. (id = 0 for create)
@@ -181,6 +199,9 @@ func CreateExpense(ctx context.Context, input *NewExpense) (*Expense, error) {
 	if err := input.validate(ctx, businessId, 0); err != nil {
 		return nil, err
 	}
+	if err := resolveExchangeRate(ctx, businessId, input.CurrencyId, input.ExpenseDate, &input.ExchangeRate); err != nil {
+		return nil, err
+	}
 
 	// construct Documents
 	documents, err := mapNewDocuments(input.Documents, "expenses", 0)
@@ -229,6 +250,8 @@ func CreateExpense(ctx context.Context, input *NewExpense) (*Expense, error) {
 		ExpenseTaxType:   input.ExpenseTaxType,
 		IsTaxInclusive:   &isTaxInclusive,
 		Documents:        documents,
+
+		RecurringExpenseId: input.RecurringExpenseId,
 	}
 
 	tx := db.Begin()
@@ -254,7 +277,12 @@ func CreateExpense(ctx context.Context, input *NewExpense) (*Expense, error) {
 
 	expense.Documents = nil
 
-	err = PublishToAccounting(ctx, tx, businessId, expense.ExpenseDate, expense.ID, AccountReferenceTypeExpense, expense, nil, PubSubMessageActionCreate)
+	expense.ApprovalStatus, err = holdForApproval(ctx, tx, expense.approvalSubject())
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	err = publishApprovable(ctx, tx, businessId, expense.ExpenseDate, expense.ID, AccountReferenceTypeExpense, expense.ApprovalStatus, expense, nil, false)
 	if err != nil {
 		tx.Rollback()
 		return nil, err
@@ -318,6 +346,7 @@ func UpdateExpense(ctx context.Context, id int, input *NewExpense) (*Expense, er
 		RemainingBalance: totalAmount,
 		SupplierId:       input.SupplierId,
 		CustomerId:       input.CustomerId,
+		ExpenseNumber:    beforeUpdate.ExpenseNumber,
 		ReferenceNumber:  input.ReferenceNumber,
 		Notes:            input.Notes,
 		ExpenseTaxId:     input.ExpenseTaxId,
@@ -360,7 +389,13 @@ func UpdateExpense(ctx context.Context, id int, input *NewExpense) (*Expense, er
 	update.Documents = nil
 	beforeUpdate.Documents = nil
 
-	err = PublishToAccounting(ctx, tx, businessId, update.ExpenseDate, update.ID, AccountReferenceTypeExpense, update, beforeUpdate, PubSubMessageActionUpdate)
+	// an edit an approval policy covers is submitted again
+	update.ApprovalStatus, err = holdForApproval(ctx, tx, update.approvalSubject())
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	err = publishApprovable(ctx, tx, businessId, update.ExpenseDate, update.ID, AccountReferenceTypeExpense, update.ApprovalStatus, update, beforeUpdate, approvalPosted(beforeUpdate.ApprovalStatus))
 	if err != nil {
 		tx.Rollback()
 		return nil, err
@@ -387,6 +422,10 @@ func DeleteExpense(ctx context.Context, id int) (*Expense, error) {
 	db := config.GetDB()
 	tx := db.Begin()
 
+	if err := withdrawApproval(ctx, tx, ApprovalDocumentTypeExpense, id); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
 	if err := tx.WithContext(ctx).Delete(&result).Error; err != nil {
 		tx.Rollback()
 		return nil, err
@@ -396,10 +435,13 @@ func DeleteExpense(ctx context.Context, id int) (*Expense, error) {
 		return nil, err
 	}
 	result.Documents = nil
-	err = PublishToAccounting(ctx, tx, businessId, result.ExpenseDate, result.ID, AccountReferenceTypeExpense, nil, result, PubSubMessageActionDelete)
-	if err != nil {
-		tx.Rollback()
-		return nil, err
+	// an expense still waiting for approval never reached the books
+	if approvalPosted(result.ApprovalStatus) {
+		err = PublishToAccounting(ctx, tx, businessId, result.ExpenseDate, result.ID, AccountReferenceTypeExpense, nil, result, PubSubMessageActionDelete)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
 	}
 
 	if err := tx.Commit().Error; err != nil {
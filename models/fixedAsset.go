@@ -0,0 +1,636 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FixedAssetStatus string
+
+const (
+	FixedAssetStatusActive           FixedAssetStatus = "ACTIVE"
+	FixedAssetStatusFullyDepreciated FixedAssetStatus = "FULLY_DEPRECIATED"
+	FixedAssetStatusDisposed         FixedAssetStatus = "DISPOSED"
+	FixedAssetStatusWrittenOff       FixedAssetStatus = "WRITTEN_OFF"
+)
+
+func (s FixedAssetStatus) IsValid() bool {
+	switch s {
+	case FixedAssetStatusActive, FixedAssetStatusFullyDepreciated, FixedAssetStatusDisposed, FixedAssetStatusWrittenOff:
+		return true
+	}
+	return false
+}
+
+type FixedAssetAcquisitionType string
+
+const (
+	FixedAssetAcquisitionTypeBill    FixedAssetAcquisitionType = "BILL"
+	FixedAssetAcquisitionTypeJournal FixedAssetAcquisitionType = "JOURNAL"
+)
+
+func (t FixedAssetAcquisitionType) IsValid() bool {
+	switch t {
+	case FixedAssetAcquisitionTypeBill, FixedAssetAcquisitionTypeJournal:
+		return true
+	}
+	return false
+}
+
+// FixedAsset is an entry of the asset register. The acquisition is already on the ledger
+// through the linked bill or journal line, so registering an asset posts nothing;
+// depreciation and disposal post through the accounting outbox.
+// Amounts are in the business base currency.
+type FixedAsset struct {
+	ID                               int                          `gorm:"primary_key" json:"id"`
+	BusinessId                       string                       `gorm:"index;not null" json:"business_id" binding:"required"`
+	BranchId                         int                          `gorm:"index;not null" json:"branch_id"`
+	CategoryId                       int                          `gorm:"index;not null" json:"category_id" binding:"required"`
+	AssetNumber                      string                       `gorm:"size:50;not null" json:"asset_number" binding:"required"`
+	Name                             string                       `gorm:"size:100;not null" json:"name" binding:"required"`
+	Description                      string                       `gorm:"type:text" json:"description"`
+	SerialNumber                     string                       `gorm:"size:100" json:"serial_number"`
+	AcquisitionType                  FixedAssetAcquisitionType    `gorm:"size:20;not null" json:"acquisition_type"`
+	AcquisitionReferenceId           int                          `gorm:"index;not null" json:"acquisition_reference_id"`
+	AcquisitionDetailId              int                          `gorm:"index;not null" json:"acquisition_detail_id"`
+	AcquisitionDate                  time.Time                    `gorm:"not null" json:"acquisition_date"`
+	AcquisitionCost                  decimal.Decimal              `gorm:"type:decimal(20,4);default:0" json:"acquisition_cost"`
+	SalvageValue                     decimal.Decimal              `gorm:"type:decimal(20,4);default:0" json:"salvage_value"`
+	DepreciationMethod               FixedAssetDepreciationMethod `gorm:"size:30;not null" json:"depreciation_method"`
+	UsefulLifeMonths                 int                          `gorm:"not null;default:0" json:"useful_life_months"`
+	DecliningBalanceRate             decimal.Decimal              `gorm:"type:decimal(20,4);default:0" json:"declining_balance_rate"`
+	TotalUnits                       decimal.Decimal              `gorm:"type:decimal(20,4);default:0" json:"total_units"`
+	AssetAccountId                   int                          `gorm:"not null" json:"asset_account_id"`
+	AccumulatedDepreciationAccountId int                          `gorm:"not null" json:"accumulated_depreciation_account_id"`
+	DepreciationExpenseAccountId     int                          `gorm:"not null" json:"depreciation_expense_account_id"`
+	DisposalGainLossAccountId        int                          `gorm:"not null" json:"disposal_gain_loss_account_id"`
+	AccumulatedDepreciation          decimal.Decimal              `gorm:"type:decimal(20,4);default:0" json:"accumulated_depreciation"`
+	DepreciatedMonths                int                          `gorm:"not null;default:0" json:"depreciated_months"`
+	UnitsUsed                        decimal.Decimal              `gorm:"type:decimal(20,4);default:0" json:"units_used"`
+	LastDepreciationDate             *time.Time                   `gorm:"default:null" json:"last_depreciation_date"`
+	NextDepreciationDate             *time.Time                   `gorm:"index;default:null" json:"next_depreciation_date"`
+	Status                           FixedAssetStatus             `gorm:"size:20;not null;index" json:"status"`
+	DisposalDate                     *time.Time                   `gorm:"default:null" json:"disposal_date"`
+	DisposalProceeds                 decimal.Decimal              `gorm:"type:decimal(20,4);default:0" json:"disposal_proceeds"`
+	ProceedsAccountId                int                          `gorm:"default:0" json:"proceeds_account_id"`
+	DisposalGainLoss                 decimal.Decimal              `gorm:"type:decimal(20,4);default:0" json:"disposal_gain_loss"`
+	CreatedAt                        time.Time                    `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt                        time.Time                    `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type NewFixedAsset struct {
+	BranchId               int                           `json:"branch_id"`
+	CategoryId             int                           `json:"category_id" binding:"required"`
+	AssetNumber            string                        `json:"asset_number" binding:"required"`
+	Name                   string                        `json:"name" binding:"required"`
+	Description            string                        `json:"description"`
+	SerialNumber           string                        `json:"serial_number"`
+	AcquisitionType        FixedAssetAcquisitionType     `json:"acquisition_type" binding:"required"`
+	AcquisitionReferenceId int                           `json:"acquisition_reference_id" binding:"required"`
+	AcquisitionDetailId    int                           `json:"acquisition_detail_id" binding:"required"`
+	AcquisitionCost        *decimal.Decimal              `json:"acquisition_cost"`
+	SalvageValue           decimal.Decimal               `json:"salvage_value"`
+	DepreciationMethod     *FixedAssetDepreciationMethod `json:"depreciation_method"`
+	UsefulLifeMonths       *int                          `json:"useful_life_months"`
+	DecliningBalanceRate   *decimal.Decimal              `json:"declining_balance_rate"`
+	TotalUnits             decimal.Decimal               `json:"total_units"`
+}
+
+type NewFixedAssetDisposal struct {
+	DisposalDate      time.Time       `json:"disposal_date" binding:"required"`
+	Proceeds          decimal.Decimal `json:"proceeds"`
+	ProceedsAccountId int             `json:"proceeds_account_id"`
+	IsWriteOff        *bool           `json:"is_write_off"`
+}
+
+type FixedAssetsConnection struct {
+	Edges    []*FixedAssetsEdge `json:"edges"`
+	PageInfo *PageInfo          `json:"pageInfo"`
+}
+
+type FixedAssetsEdge Edge[FixedAsset]
+
+func (obj FixedAsset) GetId() int {
+	return obj.ID
+}
+
+// implements methods for pagination
+
+// node
+// returns decoded curosr string
+func (fa FixedAsset) GetCursor() string {
+	return fa.CreatedAt.String()
+}
+
+// BookValue is the cost less depreciation posted so far.
+func (fa FixedAsset) BookValue() decimal.Decimal {
+	return fa.AcquisitionCost.Sub(fa.AccumulatedDepreciation)
+}
+
+// depreciableAmount is what remains to be depreciated down to the salvage value.
+func (fa FixedAsset) depreciableAmount() decimal.Decimal {
+	remaining := fa.AcquisitionCost.Sub(fa.SalvageValue).Sub(fa.AccumulatedDepreciation)
+	if remaining.IsNegative() {
+		return decimal.Zero
+	}
+	return remaining
+}
+
+// fixedAssetAcquisition is the bill or journal line an asset is acquired through.
+type fixedAssetAcquisition struct {
+	date      time.Time
+	branchId  int
+	accountId int
+	amount    decimal.Decimal
+}
+
+func getFixedAssetAcquisition(ctx context.Context, businessId string, business *Business, acquisitionType FixedAssetAcquisitionType, referenceId int, detailId int) (*fixedAssetAcquisition, error) {
+	db := config.GetDB()
+	switch acquisitionType {
+	case FixedAssetAcquisitionTypeBill:
+		var bill Bill
+		if err := db.WithContext(ctx).Where("business_id = ? AND id = ?", businessId, referenceId).First(&bill).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("acquisition bill not found")
+			}
+			return nil, err
+		}
+		if bill.CurrentStatus == BillStatusDraft || bill.CurrentStatus == BillStatusVoid {
+			return nil, errors.New("acquisition bill must be confirmed")
+		}
+		var detail BillDetail
+		if err := db.WithContext(ctx).Where("bill_id = ? AND id = ?", bill.ID, detailId).First(&detail).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("acquisition bill line not found")
+			}
+			return nil, err
+		}
+		// same line amount the bill posts to the detail account
+		amount := detail.DetailTotalAmount.Add(detail.DetailDiscountAmount)
+		if bill.IsTaxInclusive != nil && *bill.IsTaxInclusive {
+			amount = amount.Sub(detail.DetailTaxAmount)
+		}
+		if bill.CurrencyId != business.BaseCurrencyId {
+			amount = amount.Mul(bill.ExchangeRate)
+		}
+		return &fixedAssetAcquisition{
+			date:      bill.BillDate,
+			branchId:  bill.BranchId,
+			accountId: detail.DetailAccountId,
+			amount:    amount.Round(4),
+		}, nil
+	case FixedAssetAcquisitionTypeJournal:
+		var journal Journal
+		if err := db.WithContext(ctx).Where("business_id = ? AND id = ?", businessId, referenceId).First(&journal).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("acquisition journal not found")
+			}
+			return nil, err
+		}
+		var transaction JournalTransaction
+		if err := db.WithContext(ctx).Where("journal_id = ? AND id = ?", journal.ID, detailId).First(&transaction).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("acquisition journal line not found")
+			}
+			return nil, err
+		}
+		if !transaction.Debit.IsPositive() {
+			return nil, errors.New("acquisition journal line must be a debit")
+		}
+		amount := transaction.Debit
+		if journal.CurrencyId != business.BaseCurrencyId {
+			amount = amount.Mul(journal.ExchangeRate)
+		}
+		return &fixedAssetAcquisition{
+			date:      journal.JournalDate,
+			branchId:  journal.BranchId,
+			accountId: transaction.AccountId,
+			amount:    amount.Round(4),
+		}, nil
+	}
+	return nil, errors.New("invalid acquisition type")
+}
+
+// assign validates the input against the category and acquisition line and copies it onto the asset.
+func (fa *FixedAsset) assign(ctx context.Context, businessId string, input *NewFixedAsset) error {
+	if err := utils.ValidateUnique[FixedAsset](ctx, businessId, "asset_number", input.AssetNumber, fa.ID); err != nil {
+		return err
+	}
+	if !input.AcquisitionType.IsValid() {
+		return errors.New("invalid acquisition type")
+	}
+	category, err := utils.FetchModel[FixedAssetCategory](ctx, businessId, input.CategoryId)
+	if err != nil {
+		return errors.New("invalid category id")
+	}
+	business, err := GetBusinessById(ctx, businessId)
+	if err != nil {
+		return err
+	}
+	acquisition, err := getFixedAssetAcquisition(ctx, businessId, business, input.AcquisitionType, input.AcquisitionReferenceId, input.AcquisitionDetailId)
+	if err != nil {
+		return err
+	}
+	if acquisition.accountId != category.AssetAccountId {
+		return errors.New("acquisition line is not posted to the category asset account")
+	}
+
+	cost := acquisition.amount
+	if input.AcquisitionCost != nil {
+		cost = *input.AcquisitionCost
+	}
+	if !cost.IsPositive() {
+		return errors.New("acquisition cost must be greater than zero")
+	}
+	// several assets may come from one line, but together they cannot exceed it
+	var registered decimal.Decimal
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Model(&FixedAsset{}).
+		Where("business_id = ? AND acquisition_type = ? AND acquisition_reference_id = ? AND acquisition_detail_id = ? AND id <> ?",
+			businessId, input.AcquisitionType, input.AcquisitionReferenceId, input.AcquisitionDetailId, fa.ID).
+		Select("COALESCE(SUM(acquisition_cost), 0)").Scan(&registered).Error; err != nil {
+		return err
+	}
+	if registered.Add(cost).GreaterThan(acquisition.amount) {
+		return errors.New("acquisition cost exceeds the unregistered amount of the line")
+	}
+	if input.SalvageValue.IsNegative() || input.SalvageValue.GreaterThanOrEqual(cost) {
+		return errors.New("salvage value must be less than the acquisition cost")
+	}
+
+	method := category.DepreciationMethod
+	if input.DepreciationMethod != nil {
+		method = *input.DepreciationMethod
+	}
+	usefulLifeMonths := category.UsefulLifeMonths
+	if input.UsefulLifeMonths != nil {
+		usefulLifeMonths = *input.UsefulLifeMonths
+	}
+	decliningBalanceRate := category.DecliningBalanceRate
+	if input.DecliningBalanceRate != nil {
+		decliningBalanceRate = *input.DecliningBalanceRate
+	}
+	if err := validateFixedAssetSettings(method, usefulLifeMonths, decliningBalanceRate); err != nil {
+		return err
+	}
+	if method == FixedAssetDepreciationMethodUnitsOfProduction && !input.TotalUnits.IsPositive() {
+		return errors.New("total units must be greater than zero")
+	}
+
+	branchId := input.BranchId
+	if branchId == 0 {
+		branchId = acquisition.branchId
+	} else if err := utils.ValidateResourceId[Branch](ctx, businessId, branchId); err != nil {
+		return errors.New("invalid branch id")
+	}
+
+	nextDepreciationDate, err := fixedAssetMonthEnd(acquisition.date, business.Timezone)
+	if err != nil {
+		return err
+	}
+
+	fa.BranchId = branchId
+	fa.CategoryId = category.ID
+	fa.AssetNumber = input.AssetNumber
+	fa.Name = input.Name
+	fa.Description = input.Description
+	fa.SerialNumber = input.SerialNumber
+	fa.AcquisitionType = input.AcquisitionType
+	fa.AcquisitionReferenceId = input.AcquisitionReferenceId
+	fa.AcquisitionDetailId = input.AcquisitionDetailId
+	fa.AcquisitionDate = acquisition.date
+	fa.AcquisitionCost = cost
+	fa.SalvageValue = input.SalvageValue
+	fa.DepreciationMethod = method
+	fa.UsefulLifeMonths = usefulLifeMonths
+	fa.DecliningBalanceRate = decliningBalanceRate
+	fa.TotalUnits = input.TotalUnits
+	fa.AssetAccountId = category.AssetAccountId
+	fa.AccumulatedDepreciationAccountId = category.AccumulatedDepreciationAccountId
+	fa.DepreciationExpenseAccountId = category.DepreciationExpenseAccountId
+	fa.DisposalGainLossAccountId = category.DisposalGainLossAccountId
+	fa.Status = FixedAssetStatusActive
+	fa.NextDepreciationDate = &nextDepreciationDate
+	return nil
+}
+
+func CreateFixedAsset(ctx context.Context, input *NewFixedAsset) (*FixedAsset, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	fixedAsset := FixedAsset{BusinessId: businessId}
+	if err := fixedAsset.assign(ctx, businessId, input); err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Create(&fixedAsset).Error; err != nil {
+		return nil, err
+	}
+	return &fixedAsset, nil
+}
+
+// UpdateFixedAsset changes any field until the first depreciation is posted;
+// afterwards only the name, description and serial number can change.
+func UpdateFixedAsset(ctx context.Context, id int, input *NewFixedAsset) (*FixedAsset, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	existing, err := utils.FetchModel[FixedAsset](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+	if existing.DepreciatedMonths > 0 || existing.Status != FixedAssetStatusActive {
+		existing.Name = input.Name
+		existing.Description = input.Description
+		existing.SerialNumber = input.SerialNumber
+	} else if err := existing.assign(ctx, businessId, input); err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Save(existing).Error; err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+func DeleteFixedAsset(ctx context.Context, id int) (*FixedAsset, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	result, err := utils.FetchModel[FixedAsset](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+	count, err := utils.ResourceCountWhere[FixedAssetDepreciation](ctx, businessId, "fixed_asset_id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 || result.DisposalDate != nil {
+		return nil, errors.New("fixed asset has depreciation or disposal postings")
+	}
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Delete(result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func GetFixedAsset(ctx context.Context, id int) (*FixedAsset, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	return utils.FetchModel[FixedAsset](ctx, businessId, id)
+}
+
+func PaginateFixedAsset(ctx context.Context, limit *int, after *string, categoryId *int, branchId *int, status *FixedAssetStatus, name *string) (*FixedAssetsConnection, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	db := config.GetDB()
+	dbCtx := db.WithContext(ctx).Where("business_id = ?", businessId)
+	if categoryId != nil && *categoryId > 0 {
+		dbCtx.Where("category_id = ?", *categoryId)
+	}
+	if branchId != nil && *branchId > 0 {
+		dbCtx.Where("branch_id = ?", *branchId)
+	}
+	if status != nil && *status != "" {
+		dbCtx.Where("status = ?", *status)
+	}
+	if name != nil && *name != "" {
+		dbCtx.Where("name LIKE ? OR asset_number LIKE ?", "%"+*name+"%", "%"+*name+"%")
+	}
+
+	edges, pageInfo, err := FetchPageCompositeCursor[FixedAsset](dbCtx, *limit, after, "created_at", "<")
+	if err != nil {
+		return nil, err
+	}
+
+	var fixedAssetsConnection FixedAssetsConnection
+	fixedAssetsConnection.PageInfo = pageInfo
+	for _, edge := range edges {
+		fixedAssetsEdge := FixedAssetsEdge(edge)
+		fixedAssetsConnection.Edges = append(fixedAssetsConnection.Edges, &fixedAssetsEdge)
+	}
+
+	return &fixedAssetsConnection, nil
+}
+
+// DisposeFixedAsset sells or writes off the asset. Depreciation is first brought up to the
+// end of the month before disposal (not for units-of-production assets, which need usage).
+// The posting removes cost and accumulated depreciation, debits the proceeds account
+// (Undeposited Funds by default) and books the difference as gain or loss.
+func DisposeFixedAsset(ctx context.Context, id int, input *NewFixedAssetDisposal) (*FixedAsset, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	business, err := GetBusinessById(ctx, businessId)
+	if err != nil {
+		return nil, err
+	}
+
+	isWriteOff := input.IsWriteOff != nil && *input.IsWriteOff
+	proceeds := input.Proceeds
+	if proceeds.IsNegative() {
+		return nil, errors.New("proceeds cannot be negative")
+	}
+	if isWriteOff && !proceeds.IsZero() {
+		return nil, errors.New("write-off cannot have proceeds")
+	}
+	proceedsAccountId := input.ProceedsAccountId
+	if proceeds.IsPositive() {
+		if proceedsAccountId == 0 {
+			systemAccounts, err := GetSystemAccounts(businessId)
+			if err != nil {
+				return nil, err
+			}
+			proceedsAccountId = systemAccounts[AccountCodeUndepositedFunds]
+		}
+		count, err := utils.ResourceCountWhere[Account](ctx, businessId,
+			"id = ? AND main_type = 'Asset' AND detail_type NOT IN ('Cash', 'Bank', 'AccountsReceivable') AND (currency_id = 0 OR currency_id = ?)",
+			proceedsAccountId, business.BaseCurrencyId)
+		if err != nil {
+			return nil, err
+		}
+		if count <= 0 {
+			return nil, errors.New("proceeds account must be a base currency asset account other than cash, bank or receivables")
+		}
+	} else {
+		proceedsAccountId = 0
+	}
+
+	if err := ValidateTransactionLock(ctx, input.DisposalDate, businessId, AccountantTransactionLock); err != nil {
+		return nil, err
+	}
+
+	existing, err := utils.FetchModel[FixedAsset](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+	if existing.DisposalDate != nil {
+		return nil, errors.New("fixed asset is already disposed")
+	}
+	if input.DisposalDate.Before(existing.AcquisitionDate) {
+		return nil, errors.New("disposal date is before the acquisition date")
+	}
+
+	// catch up depreciation to the end of the month before disposal
+	if existing.DepreciationMethod != FixedAssetDepreciationMethodUnitsOfProduction {
+		disposalMonthEnd, err := fixedAssetMonthEnd(input.DisposalDate, business.Timezone)
+		if err != nil {
+			return nil, err
+		}
+		previousMonthEnd, err := fixedAssetPreviousMonthEnd(disposalMonthEnd, business.Timezone)
+		if err != nil {
+			return nil, err
+		}
+		for existing.NextDepreciationDate != nil && !existing.NextDepreciationDate.After(previousMonthEnd) {
+			depreciation, err := depreciateFixedAssetPeriod(ctx, existing.ID, *existing.NextDepreciationDate, decimal.Zero)
+			if err != nil {
+				return nil, err
+			}
+			if depreciation == nil {
+				break
+			}
+			if existing, err = utils.FetchModel[FixedAsset](ctx, businessId, id); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if existing.LastDepreciationDate != nil && existing.LastDepreciationDate.After(input.DisposalDate) {
+		return nil, errors.New("depreciation is posted after the disposal date")
+	}
+
+	db := config.GetDB()
+	tx := db.WithContext(ctx).Begin()
+	var fixedAsset FixedAsset
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("business_id = ? AND id = ?", businessId, id).First(&fixedAsset).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if fixedAsset.DisposalDate != nil {
+		tx.Rollback()
+		return nil, errors.New("fixed asset is already disposed")
+	}
+
+	disposalDate := input.DisposalDate
+	fixedAsset.DisposalDate = &disposalDate
+	fixedAsset.DisposalProceeds = proceeds
+	fixedAsset.ProceedsAccountId = proceedsAccountId
+	fixedAsset.DisposalGainLoss = proceeds.Sub(fixedAsset.BookValue())
+	fixedAsset.NextDepreciationDate = nil
+	fixedAsset.Status = FixedAssetStatusDisposed
+	if isWriteOff {
+		fixedAsset.Status = FixedAssetStatusWrittenOff
+	}
+
+	if err := tx.Save(&fixedAsset).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := PublishToAccounting(ctx, tx, businessId, disposalDate, fixedAsset.ID, AccountReferenceTypeFixedAssetDisposal, fixedAsset, nil, PubSubMessageActionCreate); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return &fixedAsset, nil
+}
+
+// CancelFixedAssetDisposal reverses the disposal posting and puts the asset back in service.
+func CancelFixedAssetDisposal(ctx context.Context, id int) (*FixedAsset, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	business, err := GetBusinessById(ctx, businessId)
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	tx := db.WithContext(ctx).Begin()
+	var fixedAsset FixedAsset
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("business_id = ? AND id = ?", businessId, id).First(&fixedAsset).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if fixedAsset.DisposalDate == nil {
+		tx.Rollback()
+		return nil, errors.New("fixed asset is not disposed")
+	}
+	if err := ValidateTransactionLock(ctx, *fixedAsset.DisposalDate, businessId, AccountantTransactionLock); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	oldFixedAsset := fixedAsset
+
+	fixedAsset.DisposalDate = nil
+	fixedAsset.DisposalProceeds = decimal.Zero
+	fixedAsset.ProceedsAccountId = 0
+	fixedAsset.DisposalGainLoss = decimal.Zero
+	if err := fixedAsset.resetSchedule(business.Timezone); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Save(&fixedAsset).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := PublishToAccounting(ctx, tx, businessId, *oldFixedAsset.DisposalDate, oldFixedAsset.ID, AccountReferenceTypeFixedAssetDisposal, nil, oldFixedAsset, PubSubMessageActionDelete); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return &fixedAsset, nil
+}
+
+// resetSchedule derives status and next depreciation date of an asset in service
+// from the depreciation posted so far.
+func (fa *FixedAsset) resetSchedule(timezone string) error {
+	if fa.depreciableAmount().IsZero() {
+		fa.Status = FixedAssetStatusFullyDepreciated
+		fa.NextDepreciationDate = nil
+		return nil
+	}
+	fa.Status = FixedAssetStatusActive
+	var next time.Time
+	var err error
+	if fa.LastDepreciationDate != nil {
+		next, err = fixedAssetNextMonthEnd(*fa.LastDepreciationDate, timezone)
+	} else {
+		next, err = fixedAssetMonthEnd(fa.AcquisitionDate, timezone)
+	}
+	if err != nil {
+		return err
+	}
+	fa.NextDepreciationDate = &next
+	return nil
+}
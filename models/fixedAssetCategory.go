@@ -0,0 +1,216 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+)
+
+type FixedAssetDepreciationMethod string
+
+const (
+	FixedAssetDepreciationMethodStraightLine      FixedAssetDepreciationMethod = "STRAIGHT_LINE"
+	FixedAssetDepreciationMethodDecliningBalance  FixedAssetDepreciationMethod = "DECLINING_BALANCE"
+	FixedAssetDepreciationMethodUnitsOfProduction FixedAssetDepreciationMethod = "UNITS_OF_PRODUCTION"
+)
+
+func (m FixedAssetDepreciationMethod) IsValid() bool {
+	switch m {
+	case FixedAssetDepreciationMethodStraightLine,
+		FixedAssetDepreciationMethodDecliningBalance,
+		FixedAssetDepreciationMethodUnitsOfProduction:
+		return true
+	}
+	return false
+}
+
+// FixedAssetCategory holds the default depreciation settings and ledger accounts
+// copied onto assets registered under it.
+type FixedAssetCategory struct {
+	ID                               int                          `gorm:"primary_key" json:"id"`
+	BusinessId                       string                       `gorm:"index;not null" json:"business_id" binding:"required"`
+	Name                             string                       `gorm:"size:100;not null" json:"name" binding:"required"`
+	DepreciationMethod               FixedAssetDepreciationMethod `gorm:"size:30;not null" json:"depreciation_method" binding:"required"`
+	UsefulLifeMonths                 int                          `gorm:"not null;default:0" json:"useful_life_months"`
+	DecliningBalanceRate             decimal.Decimal              `gorm:"type:decimal(20,4);default:0" json:"declining_balance_rate"`
+	AssetAccountId                   int                          `gorm:"not null" json:"asset_account_id" binding:"required"`
+	AccumulatedDepreciationAccountId int                          `gorm:"not null" json:"accumulated_depreciation_account_id" binding:"required"`
+	DepreciationExpenseAccountId     int                          `gorm:"not null" json:"depreciation_expense_account_id" binding:"required"`
+	DisposalGainLossAccountId        int                          `gorm:"not null" json:"disposal_gain_loss_account_id" binding:"required"`
+	CreatedAt                        time.Time                    `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt                        time.Time                    `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type NewFixedAssetCategory struct {
+	Name                             string                       `json:"name" binding:"required"`
+	DepreciationMethod               FixedAssetDepreciationMethod `json:"depreciation_method" binding:"required"`
+	UsefulLifeMonths                 int                          `json:"useful_life_months"`
+	DecliningBalanceRate             decimal.Decimal              `json:"declining_balance_rate"`
+	AssetAccountId                   int                          `json:"asset_account_id" binding:"required"`
+	AccumulatedDepreciationAccountId int                          `json:"accumulated_depreciation_account_id" binding:"required"`
+	DepreciationExpenseAccountId     int                          `json:"depreciation_expense_account_id" binding:"required"`
+	DisposalGainLossAccountId        int                          `json:"disposal_gain_loss_account_id" binding:"required"`
+}
+
+// validateFixedAssetSettings checks the depreciation settings shared by categories and assets.
+func validateFixedAssetSettings(method FixedAssetDepreciationMethod, usefulLifeMonths int, decliningBalanceRate decimal.Decimal) error {
+	if !method.IsValid() {
+		return errors.New("invalid depreciation method")
+	}
+	if method != FixedAssetDepreciationMethodUnitsOfProduction && usefulLifeMonths <= 0 {
+		return errors.New("useful life must be greater than zero")
+	}
+	if decliningBalanceRate.IsNegative() || decliningBalanceRate.GreaterThan(decimal.NewFromInt(100)) {
+		return errors.New("declining balance rate must be between 0 and 100")
+	}
+	return nil
+}
+
+// validateFixedAssetAccounts checks the ledger accounts an asset posts to.
+func validateFixedAssetAccounts(ctx context.Context, businessId string, assetAccountId int, accumulatedDepreciationAccountId int, depreciationExpenseAccountId int, disposalGainLossAccountId int) error {
+	checks := []struct {
+		accountId int
+		where     string
+		message   string
+	}{
+		{assetAccountId, "detail_type = 'FixedAsset'", "asset account must be a fixed asset account"},
+		{accumulatedDepreciationAccountId, "detail_type = 'FixedAsset'", "accumulated depreciation account must be a fixed asset account"},
+		{depreciationExpenseAccountId, "main_type = 'Expense'", "depreciation expense account must be an expense account"},
+		{disposalGainLossAccountId, "main_type IN ('Income', 'Expense')", "disposal gain/loss account must be an income or expense account"},
+	}
+	for _, check := range checks {
+		count, err := utils.ResourceCountWhere[Account](ctx, businessId, "id = ? AND "+check.where, check.accountId)
+		if err != nil {
+			return err
+		}
+		if count <= 0 {
+			return errors.New(check.message)
+		}
+	}
+	if assetAccountId == accumulatedDepreciationAccountId {
+		return errors.New("asset and accumulated depreciation accounts must differ")
+	}
+	return nil
+}
+
+func (input NewFixedAssetCategory) validate(ctx context.Context, businessId string, id int) error {
+	if err := utils.ValidateUnique[FixedAssetCategory](ctx, businessId, "name", input.Name, id); err != nil {
+		return err
+	}
+	if err := validateFixedAssetSettings(input.DepreciationMethod, input.UsefulLifeMonths, input.DecliningBalanceRate); err != nil {
+		return err
+	}
+	return validateFixedAssetAccounts(ctx, businessId, input.AssetAccountId, input.AccumulatedDepreciationAccountId, input.DepreciationExpenseAccountId, input.DisposalGainLossAccountId)
+}
+
+func (c *FixedAssetCategory) assign(input *NewFixedAssetCategory) {
+	c.Name = input.Name
+	c.DepreciationMethod = input.DepreciationMethod
+	c.UsefulLifeMonths = input.UsefulLifeMonths
+	c.DecliningBalanceRate = input.DecliningBalanceRate
+	c.AssetAccountId = input.AssetAccountId
+	c.AccumulatedDepreciationAccountId = input.AccumulatedDepreciationAccountId
+	c.DepreciationExpenseAccountId = input.DepreciationExpenseAccountId
+	c.DisposalGainLossAccountId = input.DisposalGainLossAccountId
+}
+
+func CreateFixedAssetCategory(ctx context.Context, input *NewFixedAssetCategory) (*FixedAssetCategory, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if err := input.validate(ctx, businessId, 0); err != nil {
+		return nil, err
+	}
+
+	category := FixedAssetCategory{BusinessId: businessId}
+	category.assign(input)
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Create(&category).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// UpdateFixedAssetCategory changes the defaults for assets registered afterwards;
+// existing assets keep the settings they were registered with.
+func UpdateFixedAssetCategory(ctx context.Context, id int, input *NewFixedAssetCategory) (*FixedAssetCategory, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if err := input.validate(ctx, businessId, id); err != nil {
+		return nil, err
+	}
+
+	existing, err := utils.FetchModel[FixedAssetCategory](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+	existing.assign(input)
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Save(existing).Error; err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+func DeleteFixedAssetCategory(ctx context.Context, id int) (*FixedAssetCategory, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	result, err := utils.FetchModel[FixedAssetCategory](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := utils.ResourceCountWhere[FixedAsset](ctx, businessId, "category_id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("category is used by fixed assets")
+	}
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Delete(result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func GetFixedAssetCategory(ctx context.Context, id int) (*FixedAssetCategory, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	return utils.FetchModel[FixedAssetCategory](ctx, businessId, id)
+}
+
+func ListFixedAssetCategory(ctx context.Context, name *string) ([]*FixedAssetCategory, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	db := config.GetDB()
+	dbCtx := db.WithContext(ctx).Where("business_id = ?", businessId)
+	if name != nil && len(*name) > 0 {
+		dbCtx = dbCtx.Where("name LIKE ?", "%"+*name+"%")
+	}
+
+	var results []*FixedAssetCategory
+	if err := dbCtx.Order("name").Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,486 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maximum months depreciated for one asset in a single scheduler pass
+const fixedAssetMaxCatchUp = 12
+
+// FixedAssetDepreciation is the depreciation posted for one asset and month.
+// Unique constraint: (fixed_asset_id, period_date) keeps a month from being posted twice.
+// PeriodDate is the last moment of the month in the business timezone, stored in UTC.
+type FixedAssetDepreciation struct {
+	ID                               int             `gorm:"primary_key" json:"id"`
+	BusinessId                       string          `gorm:"index;not null" json:"business_id"`
+	FixedAssetId                     int             `gorm:"not null;index:uniq_fixed_asset_period,unique" json:"fixed_asset_id"`
+	BranchId                         int             `gorm:"not null" json:"branch_id"`
+	PeriodDate                       time.Time       `gorm:"not null;index:uniq_fixed_asset_period,unique" json:"period_date"`
+	Amount                           decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"amount"`
+	UnitsUsed                        decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"units_used"`
+	AccumulatedDepreciation          decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"accumulated_depreciation"`
+	BookValue                        decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"book_value"`
+	DepreciationExpenseAccountId     int             `gorm:"not null" json:"depreciation_expense_account_id"`
+	AccumulatedDepreciationAccountId int             `gorm:"not null" json:"accumulated_depreciation_account_id"`
+	Description                      string          `gorm:"size:255" json:"description"`
+	CreatedAt                        time.Time       `gorm:"autoCreateTime" json:"created_at"`
+}
+
+type NewFixedAssetUsage struct {
+	FixedAssetId int             `json:"fixed_asset_id" binding:"required"`
+	Units        decimal.Decimal `json:"units" binding:"required"`
+}
+
+// FixedAssetScheduleRow is one month of an asset's depreciation schedule,
+// either posted or projected from the current book value.
+type FixedAssetScheduleRow struct {
+	PeriodDate              time.Time       `json:"periodDate"`
+	OpeningBookValue        decimal.Decimal `json:"openingBookValue"`
+	Depreciation            decimal.Decimal `json:"depreciation"`
+	AccumulatedDepreciation decimal.Decimal `json:"accumulatedDepreciation"`
+	ClosingBookValue        decimal.Decimal `json:"closingBookValue"`
+	IsPosted                bool            `json:"isPosted"`
+}
+
+func (obj FixedAssetDepreciation) GetId() int {
+	return obj.ID
+}
+
+func fixedAssetLocation(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		timezone = "Asia/Yangon"
+	}
+	return time.LoadLocation(timezone)
+}
+
+// fixedAssetMonthEnd returns the last moment of the local month containing t, in UTC.
+func fixedAssetMonthEnd(t time.Time, timezone string) (time.Time, error) {
+	location, err := fixedAssetLocation(timezone)
+	if err != nil {
+		return t, err
+	}
+	local := t.In(location)
+	firstOfNextMonth := time.Date(local.Year(), local.Month()+1, 1, 0, 0, 0, 0, location)
+	return firstOfNextMonth.Add(-time.Second).UTC(), nil
+}
+
+func fixedAssetNextMonthEnd(monthEnd time.Time, timezone string) (time.Time, error) {
+	return fixedAssetMonthEnd(monthEnd.Add(time.Second), timezone)
+}
+
+func fixedAssetPreviousMonthEnd(monthEnd time.Time, timezone string) (time.Time, error) {
+	location, err := fixedAssetLocation(timezone)
+	if err != nil {
+		return monthEnd, err
+	}
+	local := monthEnd.In(location)
+	firstOfMonth := time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, location)
+	return firstOfMonth.Add(-time.Second).UTC(), nil
+}
+
+// NextDepreciationAmount returns the depreciation for the asset's next month.
+//   - Straight line spreads cost less salvage value evenly over the useful life.
+//   - Declining balance applies the annual rate to the book value, or double the
+//     straight-line rate when no rate is set, switching to straight line once that is higher.
+//   - Units of production depreciates by the share of total units used in the month.
+//
+// The last month of the useful life (or of the total units) takes whatever is left,
+// so rounding never leaves a residue above the salvage value.
+func (fa FixedAsset) NextDepreciationAmount(units decimal.Decimal) decimal.Decimal {
+	remaining := fa.depreciableAmount()
+	if remaining.IsZero() {
+		return decimal.Zero
+	}
+	depreciable := fa.AcquisitionCost.Sub(fa.SalvageValue)
+
+	var amount decimal.Decimal
+	switch fa.DepreciationMethod {
+	case FixedAssetDepreciationMethodUnitsOfProduction:
+		if !fa.TotalUnits.IsPositive() || !units.IsPositive() {
+			return decimal.Zero
+		}
+		if fa.UnitsUsed.Add(units).GreaterThanOrEqual(fa.TotalUnits) {
+			return remaining
+		}
+		amount = depreciable.Mul(units).Div(fa.TotalUnits).Round(2)
+	case FixedAssetDepreciationMethodDecliningBalance:
+		remainingMonths := fa.UsefulLifeMonths - fa.DepreciatedMonths
+		if remainingMonths <= 1 {
+			return remaining
+		}
+		rate := fa.DecliningBalanceRate
+		if rate.IsZero() {
+			rate = decimal.NewFromInt(2400).Div(decimal.NewFromInt(int64(fa.UsefulLifeMonths)))
+		}
+		amount = fa.BookValue().Mul(rate).Div(decimal.NewFromInt(1200)).Round(2)
+		straightLine := remaining.Div(decimal.NewFromInt(int64(remainingMonths))).Round(2)
+		if straightLine.GreaterThan(amount) {
+			amount = straightLine
+		}
+	default:
+		if fa.DepreciatedMonths+1 >= fa.UsefulLifeMonths {
+			return remaining
+		}
+		amount = depreciable.Div(decimal.NewFromInt(int64(fa.UsefulLifeMonths))).Round(2)
+	}
+	if amount.GreaterThan(remaining) {
+		return remaining
+	}
+	return amount
+}
+
+// depreciateFixedAssetPeriod posts the depreciation of one month. The asset must be due for
+// exactly that month. A zero amount is recorded without a ledger posting, so the schedule
+// still advances. Returns nil when another run already posted the month.
+func depreciateFixedAssetPeriod(ctx context.Context, fixedAssetId int, periodDate time.Time, units decimal.Decimal) (*FixedAssetDepreciation, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	business, err := GetBusinessById(ctx, businessId)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateTransactionLock(ctx, periodDate, businessId, AccountantTransactionLock); err != nil {
+		return nil, err
+	}
+	location, err := fixedAssetLocation(business.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	tx := db.WithContext(ctx).Begin()
+	var fixedAsset FixedAsset
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("business_id = ? AND id = ?", businessId, fixedAssetId).First(&fixedAsset).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if fixedAsset.Status != FixedAssetStatusActive || fixedAsset.NextDepreciationDate == nil ||
+		!fixedAsset.NextDepreciationDate.Equal(periodDate) {
+		tx.Rollback()
+		return nil, nil
+	}
+
+	amount := fixedAsset.NextDepreciationAmount(units)
+	fixedAsset.AccumulatedDepreciation = fixedAsset.AccumulatedDepreciation.Add(amount)
+	fixedAsset.DepreciatedMonths++
+	if fixedAsset.DepreciationMethod == FixedAssetDepreciationMethodUnitsOfProduction {
+		fixedAsset.UnitsUsed = fixedAsset.UnitsUsed.Add(units)
+	}
+	fixedAsset.LastDepreciationDate = &periodDate
+	if err := fixedAsset.resetSchedule(business.Timezone); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	depreciation := FixedAssetDepreciation{
+		BusinessId:                       businessId,
+		FixedAssetId:                     fixedAsset.ID,
+		BranchId:                         fixedAsset.BranchId,
+		PeriodDate:                       periodDate,
+		Amount:                           amount,
+		UnitsUsed:                        units,
+		AccumulatedDepreciation:          fixedAsset.AccumulatedDepreciation,
+		BookValue:                        fixedAsset.BookValue(),
+		DepreciationExpenseAccountId:     fixedAsset.DepreciationExpenseAccountId,
+		AccumulatedDepreciationAccountId: fixedAsset.AccumulatedDepreciationAccountId,
+		Description:                      fmt.Sprintf("Depreciation of %s %s for %s", fixedAsset.AssetNumber, fixedAsset.Name, periodDate.In(location).Format("Jan 2006")),
+	}
+	if err := tx.Create(&depreciation).Error; err != nil {
+		tx.Rollback()
+		if isDuplicateKeyError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := tx.Save(&fixedAsset).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if amount.IsPositive() {
+		if err := PublishToAccounting(ctx, tx, businessId, periodDate, depreciation.ID, AccountReferenceTypeFixedAssetDepreciation, depreciation, nil, PubSubMessageActionCreate); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return &depreciation, nil
+}
+
+// GetDueFixedAssets returns assets whose next month has ended by `now`, ordered by next
+// depreciation date and id and starting after the asset `after` when it is given.
+// Units-of-production assets need usage figures and are only depreciated by a manual run.
+func GetDueFixedAssets(ctx context.Context, now time.Time, after *FixedAsset, limit int) ([]*FixedAsset, error) {
+	db := config.GetDB()
+	var results []*FixedAsset
+	query := db.WithContext(ctx).
+		Where("status = ? AND depreciation_method <> ? AND next_depreciation_date IS NOT NULL AND next_depreciation_date <= ?",
+			FixedAssetStatusActive, FixedAssetDepreciationMethodUnitsOfProduction, now)
+	if after != nil && after.NextDepreciationDate != nil {
+		query = query.Where("(next_depreciation_date > ? OR (next_depreciation_date = ? AND id > ?))",
+			*after.NextDepreciationDate, *after.NextDepreciationDate, after.ID)
+	}
+	err := query.
+		Order("next_depreciation_date, id").
+		Limit(limit).
+		Find(&results).Error
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// DepreciateFixedAsset posts every month of the asset that has ended by `now`,
+// at most fixedAssetMaxCatchUp per call. Returns the number of months posted.
+func DepreciateFixedAsset(ctx context.Context, fixedAssetId int, now time.Time) (int, error) {
+	db := config.GetDB()
+	posted := 0
+	for i := 0; i < fixedAssetMaxCatchUp; i++ {
+		var fixedAsset FixedAsset
+		if err := db.WithContext(ctx).First(&fixedAsset, fixedAssetId).Error; err != nil {
+			return posted, err
+		}
+		if fixedAsset.Status != FixedAssetStatusActive || fixedAsset.NextDepreciationDate == nil ||
+			fixedAsset.NextDepreciationDate.After(now) {
+			break
+		}
+		depreciation, err := depreciateFixedAssetPeriod(ctx, fixedAsset.ID, *fixedAsset.NextDepreciationDate, decimal.Zero)
+		if err != nil {
+			return posted, err
+		}
+		if depreciation == nil {
+			break
+		}
+		posted++
+	}
+	return posted, nil
+}
+
+// RunFixedAssetDepreciation posts depreciation of all assets in service up to the end of the
+// month containing periodDate. Units-of-production assets use the given usage for that month
+// and zero units for earlier months that were never run.
+func RunFixedAssetDepreciation(ctx context.Context, periodDate MyDateString, usages []*NewFixedAssetUsage) ([]*FixedAssetDepreciation, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	business, err := GetBusinessById(ctx, businessId)
+	if err != nil {
+		return nil, err
+	}
+	if err := periodDate.StartOfDayUTCTime(business.Timezone); err != nil {
+		return nil, err
+	}
+	target, err := fixedAssetMonthEnd(time.Time(periodDate), business.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	units := make(map[int]decimal.Decimal)
+	for _, usage := range usages {
+		if usage.Units.IsNegative() {
+			return nil, errors.New("units used cannot be negative")
+		}
+		units[usage.FixedAssetId] = units[usage.FixedAssetId].Add(usage.Units)
+	}
+
+	db := config.GetDB()
+	var fixedAssets []*FixedAsset
+	if err := db.WithContext(ctx).
+		Where("business_id = ? AND status = ? AND next_depreciation_date IS NOT NULL AND next_depreciation_date <= ?", businessId, FixedAssetStatusActive, target).
+		Order("id").Find(&fixedAssets).Error; err != nil {
+		return nil, err
+	}
+
+	results := make([]*FixedAssetDepreciation, 0)
+	for _, fixedAsset := range fixedAssets {
+		next := fixedAsset.NextDepreciationDate
+		for next != nil && !next.After(target) {
+			periodUnits := decimal.Zero
+			if next.Equal(target) {
+				periodUnits = units[fixedAsset.ID]
+			}
+			depreciation, err := depreciateFixedAssetPeriod(ctx, fixedAsset.ID, *next, periodUnits)
+			if err != nil {
+				return results, err
+			}
+			if depreciation == nil {
+				break
+			}
+			results = append(results, depreciation)
+
+			var current FixedAsset
+			if err := db.WithContext(ctx).Select("next_depreciation_date").First(&current, fixedAsset.ID).Error; err != nil {
+				return results, err
+			}
+			next = current.NextDepreciationDate
+		}
+	}
+	return results, nil
+}
+
+// ReverseFixedAssetDepreciation removes the latest depreciation of an asset in service
+// and reverses its ledger posting.
+func ReverseFixedAssetDepreciation(ctx context.Context, fixedAssetId int) (*FixedAssetDepreciation, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	business, err := GetBusinessById(ctx, businessId)
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	tx := db.WithContext(ctx).Begin()
+	var fixedAsset FixedAsset
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("business_id = ? AND id = ?", businessId, fixedAssetId).First(&fixedAsset).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if fixedAsset.DisposalDate != nil {
+		tx.Rollback()
+		return nil, errors.New("cancel the disposal before reversing depreciation")
+	}
+
+	var latest FixedAssetDepreciation
+	if err := tx.Where("fixed_asset_id = ?", fixedAsset.ID).Order("period_date DESC").First(&latest).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("fixed asset has no depreciation")
+		}
+		return nil, err
+	}
+	if err := ValidateTransactionLock(ctx, latest.PeriodDate, businessId, AccountantTransactionLock); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var previous FixedAssetDepreciation
+	err = tx.Where("fixed_asset_id = ? AND id <> ?", fixedAsset.ID, latest.ID).Order("period_date DESC").First(&previous).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Rollback()
+		return nil, err
+	}
+	if previous.ID > 0 {
+		fixedAsset.LastDepreciationDate = &previous.PeriodDate
+	} else {
+		fixedAsset.LastDepreciationDate = nil
+	}
+	fixedAsset.AccumulatedDepreciation = fixedAsset.AccumulatedDepreciation.Sub(latest.Amount)
+	fixedAsset.DepreciatedMonths--
+	if fixedAsset.DepreciationMethod == FixedAssetDepreciationMethodUnitsOfProduction {
+		fixedAsset.UnitsUsed = fixedAsset.UnitsUsed.Sub(latest.UnitsUsed)
+	}
+	if err := fixedAsset.resetSchedule(business.Timezone); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Delete(&latest).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Save(&fixedAsset).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if latest.Amount.IsPositive() {
+		if err := PublishToAccounting(ctx, tx, businessId, latest.PeriodDate, latest.ID, AccountReferenceTypeFixedAssetDepreciation, nil, latest, PubSubMessageActionDelete); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return &latest, nil
+}
+
+func GetFixedAssetDepreciations(ctx context.Context, fixedAssetId int) ([]*FixedAssetDepreciation, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	db := config.GetDB()
+	var results []*FixedAssetDepreciation
+	if err := db.WithContext(ctx).
+		Where("business_id = ? AND fixed_asset_id = ?", businessId, fixedAssetId).
+		Order("period_date").Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetFixedAssetDepreciationSchedule lists the posted months followed by a projection to the
+// end of the useful life. Units-of-production assets have no projection.
+func GetFixedAssetDepreciationSchedule(ctx context.Context, fixedAssetId int) ([]*FixedAssetScheduleRow, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	business, err := GetBusinessById(ctx, businessId)
+	if err != nil {
+		return nil, err
+	}
+	fixedAsset, err := utils.FetchModel[FixedAsset](ctx, businessId, fixedAssetId)
+	if err != nil {
+		return nil, err
+	}
+	depreciations, err := GetFixedAssetDepreciations(ctx, fixedAssetId)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*FixedAssetScheduleRow, 0, len(depreciations))
+	for _, depreciation := range depreciations {
+		results = append(results, &FixedAssetScheduleRow{
+			PeriodDate:              depreciation.PeriodDate,
+			OpeningBookValue:        depreciation.BookValue.Add(depreciation.Amount),
+			Depreciation:            depreciation.Amount,
+			AccumulatedDepreciation: depreciation.AccumulatedDepreciation,
+			ClosingBookValue:        depreciation.BookValue,
+			IsPosted:                true,
+		})
+	}
+
+	if fixedAsset.DepreciationMethod == FixedAssetDepreciationMethodUnitsOfProduction {
+		return results, nil
+	}
+	projected := *fixedAsset
+	for projected.Status == FixedAssetStatusActive && projected.NextDepreciationDate != nil &&
+		projected.DepreciatedMonths < projected.UsefulLifeMonths {
+		periodDate := *projected.NextDepreciationDate
+		amount := projected.NextDepreciationAmount(decimal.Zero)
+		opening := projected.BookValue()
+		projected.AccumulatedDepreciation = projected.AccumulatedDepreciation.Add(amount)
+		projected.DepreciatedMonths++
+		projected.LastDepreciationDate = &periodDate
+		if err := projected.resetSchedule(business.Timezone); err != nil {
+			return nil, err
+		}
+		results = append(results, &FixedAssetScheduleRow{
+			PeriodDate:              periodDate,
+			OpeningBookValue:        opening,
+			Depreciation:            amount,
+			AccumulatedDepreciation: projected.AccumulatedDepreciation,
+			ClosingBookValue:        projected.BookValue(),
+		})
+	}
+	return results, nil
+}
@@ -0,0 +1,112 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/shopspring/decimal"
+)
+
+// depreciateAll runs an asset through its life and returns the monthly amounts.
+func depreciateAll(fa models.FixedAsset, units []decimal.Decimal) []decimal.Decimal {
+	amounts := make([]decimal.Decimal, 0)
+	for i := 0; i < 1000; i++ {
+		u := decimal.Zero
+		if i < len(units) {
+			u = units[i]
+		} else if fa.DepreciationMethod == models.FixedAssetDepreciationMethodUnitsOfProduction {
+			break
+		}
+		amount := fa.NextDepreciationAmount(u)
+		if amount.IsZero() && fa.DepreciationMethod != models.FixedAssetDepreciationMethodUnitsOfProduction {
+			break
+		}
+		amounts = append(amounts, amount)
+		fa.AccumulatedDepreciation = fa.AccumulatedDepreciation.Add(amount)
+		fa.DepreciatedMonths++
+		fa.UnitsUsed = fa.UnitsUsed.Add(u)
+	}
+	return amounts
+}
+
+func sum(amounts []decimal.Decimal) decimal.Decimal {
+	total := decimal.Zero
+	for _, amount := range amounts {
+		total = total.Add(amount)
+	}
+	return total
+}
+
+func TestFixedAssetStraightLine(t *testing.T) {
+	fa := models.FixedAsset{
+		DepreciationMethod: models.FixedAssetDepreciationMethodStraightLine,
+		AcquisitionCost:    decimal.NewFromInt(1000),
+		SalvageValue:       decimal.NewFromInt(100),
+		UsefulLifeMonths:   7,
+	}
+	amounts := depreciateAll(fa, nil)
+	if len(amounts) != 7 {
+		t.Fatalf("months: got %d want 7", len(amounts))
+	}
+	if !amounts[0].Equal(decimal.RequireFromString("128.57")) {
+		t.Fatalf("first month: got %s want 128.57", amounts[0])
+	}
+	if !amounts[6].Equal(decimal.RequireFromString("128.58")) {
+		t.Fatalf("last month: got %s want 128.58", amounts[6])
+	}
+	if !sum(amounts).Equal(decimal.NewFromInt(900)) {
+		t.Fatalf("total: got %s want 900", sum(amounts))
+	}
+}
+
+func TestFixedAssetDecliningBalance(t *testing.T) {
+	fa := models.FixedAsset{
+		DepreciationMethod: models.FixedAssetDepreciationMethodDecliningBalance,
+		AcquisitionCost:    decimal.NewFromInt(12000),
+		UsefulLifeMonths:   24,
+	}
+	amounts := depreciateAll(fa, nil)
+	// double declining: 100% a year on 12000 is 1000 for the first month
+	if !amounts[0].Equal(decimal.NewFromInt(1000)) {
+		t.Fatalf("first month: got %s want 1000", amounts[0])
+	}
+	if !amounts[1].LessThan(amounts[0]) {
+		t.Fatalf("second month: got %s want less than %s", amounts[1], amounts[0])
+	}
+	if len(amounts) != 24 {
+		t.Fatalf("months: got %d want 24", len(amounts))
+	}
+	if !sum(amounts).Equal(decimal.NewFromInt(12000)) {
+		t.Fatalf("total: got %s want 12000", sum(amounts))
+	}
+}
+
+func TestFixedAssetUnitsOfProduction(t *testing.T) {
+	fa := models.FixedAsset{
+		DepreciationMethod: models.FixedAssetDepreciationMethodUnitsOfProduction,
+		AcquisitionCost:    decimal.NewFromInt(10000),
+		SalvageValue:       decimal.NewFromInt(1000),
+		TotalUnits:         decimal.NewFromInt(3000),
+	}
+	if amount := fa.NextDepreciationAmount(decimal.Zero); !amount.IsZero() {
+		t.Fatalf("idle month: got %s want 0", amount)
+	}
+	amounts := depreciateAll(fa, []decimal.Decimal{
+		decimal.NewFromInt(1000),
+		decimal.Zero,
+		decimal.NewFromInt(1500),
+		decimal.NewFromInt(800),
+	})
+	if !amounts[0].Equal(decimal.NewFromInt(3000)) {
+		t.Fatalf("first month: got %s want 3000", amounts[0])
+	}
+	if !amounts[1].IsZero() {
+		t.Fatalf("idle month: got %s want 0", amounts[1])
+	}
+	if !amounts[3].Equal(decimal.NewFromInt(1500)) {
+		t.Fatalf("last month: got %s want remaining 1500", amounts[3])
+	}
+	if !sum(amounts).Equal(decimal.NewFromInt(9000)) {
+		t.Fatalf("total: got %s want 9000", sum(amounts))
+	}
+}
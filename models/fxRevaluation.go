@@ -0,0 +1,319 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+)
+
+// FxRevaluation restates one branch's open foreign currency balances on receivable, payable
+// and bank accounts at period-end rates. The unrealised gain or loss is posted against the
+// exchange gain or loss account on RevaluationDate and reversed on ReversalDate, the next
+// day, so payments still realise their gain or loss from the original document rates.
+type FxRevaluation struct {
+	ID                int                 `gorm:"primary_key" json:"id"`
+	BusinessId        string              `gorm:"index;not null" json:"business_id"`
+	BranchId          int                 `gorm:"not null" json:"branch_id"`
+	RevaluationDate   time.Time           `gorm:"not null;index" json:"revaluation_date"`
+	ReversalDate      time.Time           `gorm:"not null" json:"reversal_date"`
+	GainLossAccountId int                 `gorm:"not null" json:"gain_loss_account_id"`
+	TotalGainLoss     decimal.Decimal     `gorm:"type:decimal(20,4);default:0" json:"total_gain_loss"`
+	Notes             string              `gorm:"type:text;default:null" json:"notes"`
+	CreatedBy         int                 `gorm:"not null" json:"created_by"`
+	CreatedByName     string              `gorm:"size:100" json:"created_by_name"`
+	Lines             []FxRevaluationLine `gorm:"foreignKey:FxRevaluationId" json:"lines"`
+	CreatedAt         time.Time           `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time           `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// FxRevaluationLine is one account's balance in one currency. BaseBalance is the balance at
+// the document rates, RevaluedBalance the foreign balance at ExchangeRate; GainLoss is the
+// difference, positive when the balance is worth more in base currency.
+type FxRevaluationLine struct {
+	ID              int             `gorm:"primary_key" json:"id"`
+	FxRevaluationId int             `gorm:"index;not null" json:"fx_revaluation_id"`
+	AccountId       int             `gorm:"not null" json:"account_id"`
+	CurrencyId      int             `gorm:"not null" json:"currency_id"`
+	ForeignBalance  decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"foreign_balance"`
+	BaseBalance     decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"base_balance"`
+	ExchangeRate    decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"exchange_rate"`
+	RevaluedBalance decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"revalued_balance"`
+	GainLoss        decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"gain_loss"`
+}
+
+// NewFxRevaluation runs a revaluation. Currencies without a rate in Rates use the latest
+// recorded rate on or before RevaluationDate.
+type NewFxRevaluation struct {
+	BranchId        int                  `json:"branch_id" binding:"required"`
+	RevaluationDate MyDateString         `json:"revaluation_date" binding:"required"`
+	Rates           []*FxRevaluationRate `json:"rates"`
+	Notes           string               `json:"notes"`
+}
+
+type FxRevaluationRate struct {
+	CurrencyId   int             `json:"currency_id"`
+	ExchangeRate decimal.Decimal `json:"exchange_rate"`
+}
+
+// FxRevaluationBalance is an open foreign currency balance as kept in the daily balances.
+type FxRevaluationBalance struct {
+	AccountId      int
+	CurrencyId     int
+	ForeignBalance decimal.Decimal
+	BaseBalance    decimal.Decimal
+}
+
+func (obj FxRevaluation) GetId() int {
+	return obj.ID
+}
+
+func (obj FxRevaluation) CheckTransactionLock(ctx context.Context) error {
+	return validateTransactionLock(ctx, obj.RevaluationDate, obj.BusinessId, AccountantTransactionLock)
+}
+
+// RevalueFxBalances values each balance at its currency's rate. A currency missing from
+// rates is an error.
+func RevalueFxBalances(balances []*FxRevaluationBalance, rates map[int]decimal.Decimal) ([]FxRevaluationLine, decimal.Decimal, error) {
+	var lines []FxRevaluationLine
+	total := decimal.Zero
+	for _, balance := range balances {
+		rate, ok := rates[balance.CurrencyId]
+		if !ok || !rate.IsPositive() {
+			return nil, decimal.Zero, fmt.Errorf("exchange rate not found for currency %d", balance.CurrencyId)
+		}
+		revalued := balance.ForeignBalance.Mul(rate).Round(4)
+		gainLoss := revalued.Sub(balance.BaseBalance)
+		if gainLoss.IsZero() {
+			continue
+		}
+		lines = append(lines, FxRevaluationLine{
+			AccountId:       balance.AccountId,
+			CurrencyId:      balance.CurrencyId,
+			ForeignBalance:  balance.ForeignBalance,
+			BaseBalance:     balance.BaseBalance,
+			ExchangeRate:    rate,
+			RevaluedBalance: revalued,
+			GainLoss:        gainLoss,
+		})
+		total = total.Add(gainLoss)
+	}
+	return lines, total, nil
+}
+
+// getFxRevaluationBalances reads the branch's closing foreign currency balances on
+// receivable, payable and bank accounts as of date, a local calendar date.
+func getFxRevaluationBalances(ctx context.Context, business *Business, branchId int, date string) ([]*FxRevaluationBalance, error) {
+	sql := `
+WITH LatestBalances AS (
+    SELECT
+        account_id, currency_id, running_balance, running_base_balance,
+        ROW_NUMBER() OVER (PARTITION BY account_id, currency_id ORDER BY transaction_date DESC) AS rn
+    FROM account_currency_daily_balances
+    WHERE
+        business_id = @businessId
+        AND branch_id = @branchId
+        AND transaction_date <= @date
+        AND currency_id <> @baseCurrencyId
+)
+SELECT
+    lb.account_id,
+    lb.currency_id,
+    lb.running_balance AS foreign_balance,
+    lb.running_base_balance AS base_balance
+FROM LatestBalances lb
+    INNER JOIN accounts a ON a.id = lb.account_id
+WHERE
+    lb.rn = 1
+    AND lb.running_balance <> 0
+    AND a.detail_type IN ('AccountsReceivable', 'AccountsPayable', 'Bank')
+ORDER BY lb.currency_id, lb.account_id
+`
+	var results []*FxRevaluationBalance
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Raw(sql, map[string]interface{}{
+		"businessId":     business.ID,
+		"branchId":       branchId,
+		"date":           date,
+		"baseCurrencyId": business.BaseCurrencyId,
+	}).Scan(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (input *NewFxRevaluation) validate(ctx context.Context, businessId string, revaluationDate time.Time) error {
+	if err := utils.ValidateResourceId[Branch](ctx, businessId, input.BranchId); err != nil {
+		return errors.New("branch not found")
+	}
+	if !utils.IsBranchAllowed(ctx, input.BranchId) {
+		return config.ErrBranchNotPermitted
+	}
+	if err := validateTransactionLock(ctx, revaluationDate, businessId, AccountantTransactionLock); err != nil {
+		return err
+	}
+	count, err := utils.ResourceCountWhere[FxRevaluation](ctx, businessId, "branch_id = ? AND revaluation_date = ?", input.BranchId, revaluationDate)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("the branch is already revalued on this date")
+	}
+	for _, rate := range input.Rates {
+		if !rate.ExchangeRate.IsPositive() {
+			return errors.New("exchange rate must be greater than zero")
+		}
+	}
+	return nil
+}
+
+// CreateFxRevaluation revalues the branch's open foreign currency balances at the end of
+// the revaluation date and posts the reversing unrealised gain or loss.
+func CreateFxRevaluation(ctx context.Context, input *NewFxRevaluation) (*FxRevaluation, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	userId, ok := utils.GetUserIdFromContext(ctx)
+	if !ok {
+		return nil, errors.New("user id is required")
+	}
+	userName, _ := utils.GetUserNameFromContext(ctx)
+
+	business, err := GetBusinessById(ctx, businessId)
+	if err != nil {
+		return nil, err
+	}
+	date := time.Time(input.RevaluationDate).Format("2006-01-02")
+	startOfDay := input.RevaluationDate
+	if err := startOfDay.StartOfDayUTCTime(business.Timezone); err != nil {
+		return nil, err
+	}
+	if err := input.RevaluationDate.EndOfDayUTCTime(business.Timezone); err != nil {
+		return nil, err
+	}
+	revaluationDate := time.Time(input.RevaluationDate)
+	if err := input.validate(ctx, businessId, revaluationDate); err != nil {
+		return nil, err
+	}
+	systemAccounts, err := GetSystemAccounts(businessId)
+	if err != nil {
+		return nil, err
+	}
+
+	balances, err := getFxRevaluationBalances(ctx, business, input.BranchId, date)
+	if err != nil {
+		return nil, err
+	}
+	rates := make(map[int]decimal.Decimal)
+	for _, rate := range input.Rates {
+		rates[rate.CurrencyId] = rate.ExchangeRate
+	}
+	for _, balance := range balances {
+		if _, ok := rates[balance.CurrencyId]; ok {
+			continue
+		}
+		rate, err := GetExchangeRateAsOf(ctx, businessId, balance.CurrencyId, revaluationDate)
+		if err != nil {
+			return nil, fmt.Errorf("exchange rate not found for currency %d", balance.CurrencyId)
+		}
+		rates[balance.CurrencyId] = rate
+	}
+	lines, total, err := RevalueFxBalances(balances, rates)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, errors.New("no foreign currency balance to revalue")
+	}
+
+	revaluation := FxRevaluation{
+		BusinessId:        businessId,
+		BranchId:          input.BranchId,
+		RevaluationDate:   revaluationDate,
+		ReversalDate:      time.Time(startOfDay).AddDate(0, 0, 1),
+		GainLossAccountId: systemAccounts[AccountCodeExchangeGainOrLoss],
+		TotalGainLoss:     total,
+		Notes:             input.Notes,
+		CreatedBy:         userId,
+		CreatedByName:     userName,
+		Lines:             lines,
+	}
+
+	db := config.GetDB()
+	tx := db.Begin()
+	if err := tx.WithContext(ctx).Create(&revaluation).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	err = PublishToAccounting(ctx, tx, businessId, revaluation.RevaluationDate, revaluation.ID, AccountReferenceTypeFxRevaluation, revaluation, nil, PubSubMessageActionCreate)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return &revaluation, nil
+}
+
+// DeleteFxRevaluation reverses both the revaluation and its next-day reversal.
+func DeleteFxRevaluation(ctx context.Context, id int) (*FxRevaluation, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	result, err := utils.FetchModelForChange[FxRevaluation](ctx, businessId, id, "Lines")
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	tx := db.Begin()
+	if err := tx.WithContext(ctx).Where("fx_revaluation_id = ?", id).Delete(&FxRevaluationLine{}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.WithContext(ctx).Delete(&result).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	err = PublishToAccounting(ctx, tx, businessId, result.RevaluationDate, result.ID, AccountReferenceTypeFxRevaluation, nil, result, PubSubMessageActionDelete)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func GetFxRevaluation(ctx context.Context, id int) (*FxRevaluation, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	return utils.FetchModel[FxRevaluation](ctx, businessId, id, "Lines")
+}
+
+func ListFxRevaluation(ctx context.Context, branchId *int) ([]*FxRevaluation, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	db := config.GetDB()
+	dbCtx := db.WithContext(ctx).Preload("Lines").Where("business_id = ?", businessId)
+	if branchId != nil && *branchId > 0 {
+		dbCtx = dbCtx.Where("branch_id = ?", *branchId)
+	}
+	var results []*FxRevaluation
+	if err := dbCtx.Order("revaluation_date DESC").Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -14,12 +14,17 @@ type Resource interface {
 
 // first find in redis, then in db, using ctx's business_id in WHERE, cache result
 // (may return RecordNotFound error)
+// A branch restricted user skips the cache: its entries are not limited to the user's
+// branches, and what the user loads is limited to them.
 func GetResource[T Resource](ctx context.Context, id int, associations ...string) (*T, error) {
 
 	businessId, ok := utils.GetBusinessIdFromContext(ctx)
 	if !ok || businessId == "" {
 		return nil, errors.New("business id is required")
 	}
+	if _, restricted := utils.GetBranchIdsFromContext(ctx); restricted {
+		return utils.FetchModel[T](ctx, businessId, id, associations...)
+	}
 	// find in redis
 	result, err := utils.RetrieveRedis[T](id)
 	if err != nil {
@@ -49,17 +54,23 @@ func GetResource[T Resource](ctx context.Context, id int, associations ...string
 }
 
 // list all resources, redis or db, cache result
+// (branch restricted users skip the cache, as GetResource)
 func ListAllResource[ModelT any, AllModelT any](ctx context.Context, orders ...string) ([]*AllModelT, error) {
 
 	businessId, ok := utils.GetBusinessIdFromContext(ctx)
 	if !ok || businessId == "" {
 		return nil, errors.New("business id is required")
 	}
+	_, restricted := utils.GetBranchIdsFromContext(ctx)
 
 	// first try redis cache
-	results, err := utils.RetrieveRedisList[AllModelT](businessId)
-	if err != nil {
-		return nil, err
+	var results []*AllModelT
+	var err error
+	if !restricted {
+		results, err = utils.RetrieveRedisList[AllModelT](businessId)
+		if err != nil {
+			return nil, err
+		}
 	}
 	// if not exists in redis
 	if results == nil {
@@ -75,6 +86,9 @@ func ListAllResource[ModelT any, AllModelT any](ctx context.Context, orders ...s
 			return nil, err
 		}
 
+		if restricted {
+			return results, nil
+		}
 		// caching the result
 		if err := utils.StoreRedisList[AllModelT](results, businessId); err != nil {
 			return nil, err
@@ -81,6 +81,7 @@ type InventoryAdjustment struct {
 	BranchId        int                         `gorm:"not null" json:"branch_id" binding:"required"`
 	WarehouseId     int                         `gorm:"not null" json:"warehouse_id" binding:"required"`
 	CurrentStatus   InventoryAdjustmentStatus   `gorm:"type:enum('Draft', 'Adjusted');not null" json:"current_status" binding:"required"`
+	ApprovalStatus  *ApprovalStatus             `gorm:"size:20;default:null" json:"approval_status"`
 	ReasonId        int                         `gorm:"not null" json:"reasonId"`
 	Description     string                      `gorm:"type:text;default:null" json:"description"`
 	Documents       []*Document                 `gorm:"polymorphic:Reference" json:"documents"`
@@ -112,12 +113,14 @@ type InventoryAdjustmentDetail struct {
 	ProductId             int                 `gorm:"default:null" json:"product_id"`
 	ProductType           ProductType         `gorm:"type:enum('S','G','C','V','I');default:S" json:"product_type"`
 	BatchNumber           string              `gorm:"size:100;default:null" json:"batch_number"`
+	SerialNumbers         []string            `gorm:"type:text;serializer:json" json:"serial_numbers"`
 	Name                  string              `gorm:"size:100" json:"name" binding:"required"`
 	Description           string              `gorm:"size:255;default:null" json:"description"`
 	AdjustedValue         decimal.Decimal     `gorm:"type:decimal(20,4);default:0" json:"adjusted_value" binding:"required"`
-	CostPrice             decimal.Decimal     `gorm:"type:decimal(20,4);default:0" json:"cost_price"`
+	CostPrice             decimal.Decimal     `gorm:"type:decimal(20,8);default:0" json:"cost_price"`
 	CreatedAt             time.Time           `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt             time.Time           `gorm:"autoUpdateTime" json:"updated_at"`
+	LineUnit
 }
 
 type NewInventoryAdjustmentDetail struct {
@@ -125,11 +128,13 @@ type NewInventoryAdjustmentDetail struct {
 	ProductId     int             `json:"product_id"`
 	ProductType   ProductType     `json:"product_type"`
 	BatchNumber   string          `json:"batch_number"`
+	SerialNumbers []string        `json:"serial_numbers"`
 	Name          string          `json:"name" binding:"required"`
 	Description   string          `json:"description"`
 	AdjustedValue decimal.Decimal `json:"adjusted_value" binding:"required"`
 	CostPrice     decimal.Decimal `json:"cost_price" binding:"required"`
 	IsDeletedItem *bool           `json:"is_deleted_item"`
+	LineUnit
 }
 
 type InventoryAdjustmentsConnection struct {
@@ -148,6 +153,30 @@ func (invAdj InventoryAdjustment) GetCursor() string {
 	return invAdj.CreatedAt.String()
 }
 
+// approvalSubject: quantity adjustments are weighed at cost, value adjustments by the value
+// they add or remove.
+func (invAdj InventoryAdjustment) CheckTransactionLock(ctx context.Context) error {
+	return validateTransactionLock(ctx, invAdj.AdjustmentDate, invAdj.BusinessId, AccountantTransactionLock)
+}
+
+func (invAdj *InventoryAdjustment) approvalSubject() approvalSubject {
+	amount := decimal.Zero
+	for _, d := range invAdj.Details {
+		if invAdj.AdjustmentType == InventoryAdjustmentTypeQuantity {
+			amount = amount.Add(d.AdjustedValue.Mul(d.CostPrice).Abs())
+		} else {
+			amount = amount.Add(d.AdjustedValue.Abs())
+		}
+	}
+	return approvalSubject{
+		DocumentType:    ApprovalDocumentTypeInventoryAdjustment,
+		ReferenceId:     invAdj.ID,
+		ReferenceNumber: invAdj.ReferenceNumber,
+		BranchId:        invAdj.BranchId,
+		Amount:          amount,
+	}
+}
+
 func (invAdj *InventoryAdjustment) GetFieldValues(tx *gorm.DB) (*utils.DetailFieldValues, error) {
 	return utils.FetchDetailFieldValues(tx, &InventoryAdjustmentDetail{}, "inventory_adjustment_id", invAdj.ID)
 }
@@ -289,9 +318,29 @@ func ValidateInventoryAdjustmentInput(ctx context.Context, input *NewInventoryAd
 	if !ok || businessId == "" {
 		return errors.New("business id is required")
 	}
+	if err := input.detailsToBaseUnit(ctx, businessId); err != nil {
+		return err
+	}
 	return input.validate(ctx, businessId, 0)
 }
 
+// Value adjustments revalue stock without moving it, so only quantity lines take a unit.
+func (input *NewInventoryAdjustment) detailsToBaseUnit(ctx context.Context, businessId string) error {
+	for i := range input.Details {
+		d := &input.Details[i]
+		if input.AdjustmentType != InventoryAdjustmentTypeQuantity {
+			if d.UnitId > 0 {
+				return fmt.Errorf("%s: units of measure only apply to quantity adjustments", d.Name)
+			}
+			continue
+		}
+		if err := d.LineUnit.toBaseUnit(ctx, businessId, d.ProductType, d.ProductId, &d.AdjustedValue, &d.CostPrice); err != nil {
+			return fmt.Errorf("%s: %w", d.Name, err)
+		}
+	}
+	return nil
+}
+
 func CreateInventoryAdjustment(ctx context.Context, input *NewInventoryAdjustment) (*InventoryAdjustment, error) {
 	db := config.GetDB()
 
@@ -303,6 +352,25 @@ func CreateInventoryAdjustment(ctx context.Context, input *NewInventoryAdjustmen
 	if !ok || userId == 0 {
 		return nil, errors.New("user id is required")
 	}
+
+	tx := db.Begin()
+	inventoryAdjustment, err := createInventoryAdjustment(ctx, tx, businessId, userId, input)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return inventoryAdjustment, nil
+}
+
+// createInventoryAdjustment stores the adjustment through tx, and posts it when it is
+// created as Adjusted and no approval policy holds it.
+func createInventoryAdjustment(ctx context.Context, tx *gorm.DB, businessId string, userId int, input *NewInventoryAdjustment) (*InventoryAdjustment, error) {
+	if err := input.detailsToBaseUnit(ctx, businessId); err != nil {
+		return nil, err
+	}
 	// validate InventoryAdjustment
 	if err := input.validate(ctx, businessId, 0); err != nil {
 		return nil, err
@@ -327,10 +395,12 @@ func CreateInventoryAdjustment(ctx context.Context, input *NewInventoryAdjustmen
 			ProductId:     item.ProductId,
 			ProductType:   item.ProductType,
 			BatchNumber:   item.BatchNumber,
+			SerialNumbers: item.SerialNumbers,
 			Name:          item.Name,
 			Description:   item.Description,
 			AdjustedValue: item.AdjustedValue,
 			CostPrice:     item.CostPrice,
+			LineUnit:      item.LineUnit,
 		}
 		// Add the item to the InventoryAdjustment
 		adjustmentItems = append(adjustmentItems, adjustmentItem)
@@ -361,49 +431,66 @@ func CreateInventoryAdjustment(ctx context.Context, input *NewInventoryAdjustmen
 		CreatedBy:       userId,
 	}
 
-	tx := db.Begin()
-
 	err = tx.WithContext(ctx).Create(&inventoryAdjustment).Error
 	if err != nil {
-		tx.Rollback()
 		return nil, err
 	}
 
-	// If requested "Adjusted", apply the status transition deterministically (Draft -> Adjusted).
+	// An adjustment an approval policy covers stays a draft until it is approved.
 	requestedStatus := input.CurrentStatus
 	if requestedStatus == InventoryAdjustmentStatusAdjusted {
-		if err := tx.WithContext(ctx).Model(&inventoryAdjustment).Update("CurrentStatus", InventoryAdjustmentStatusAdjusted).Error; err != nil {
-			tx.Rollback()
+		inventoryAdjustment.ApprovalStatus, err = holdForApproval(ctx, tx, inventoryAdjustment.approvalSubject())
+		if err != nil {
 			return nil, err
 		}
-		inventoryAdjustment.CurrentStatus = InventoryAdjustmentStatusAdjusted
+	}
 
-		// Apply inventory side-effects deterministically for quantity adjustments.
-		if config.UseStockCommandsFor("INVENTORY_ADJUSTMENT") {
-			if err := ApplyInventoryAdjustmentStockForStatusTransition(tx.WithContext(ctx), &inventoryAdjustment, InventoryAdjustmentStatusDraft); err != nil {
-				tx.Rollback()
-				return nil, err
-			}
+	// If requested "Adjusted", apply the status transition deterministically (Draft -> Adjusted).
+	// Not adjusted yet: do not publish posting.
+	if requestedStatus == InventoryAdjustmentStatusAdjusted && inventoryAdjustment.ApprovalStatus == nil {
+		if err := adjustInventoryAdjustment(ctx, tx, businessId, &inventoryAdjustment); err != nil {
+			return nil, err
 		}
+	}
 
-		// Guardrail for VALUE adjustments:
-		// Value adjustments (IVAV) require existing stock history baseline to revalue inventory.
-		// If stock_histories are not ready yet (e.g. opening stock not posted/worker lag),
-		// the async workflow will fail and the UI will show no journal + no valuation changes.
-		// Fail fast with a clear error instead of silently creating an un-postable adjustment.
-		if inventoryAdjustment.AdjustmentType == InventoryAdjustmentTypeValue {
-			stockDate, err := utils.ConvertToDate(inventoryAdjustment.AdjustmentDate, business.Timezone)
-			if err != nil {
-				tx.Rollback()
-				return nil, err
+	return &inventoryAdjustment, nil
+}
+
+// adjustInventoryAdjustment moves a draft adjustment to Adjusted and posts it.
+func adjustInventoryAdjustment(ctx context.Context, tx *gorm.DB, businessId string, inventoryAdjustment *InventoryAdjustment) error {
+	business, err := GetBusinessById(ctx, businessId)
+	if err != nil {
+		return err
+	}
+	if err := tx.WithContext(ctx).Model(inventoryAdjustment).Update("CurrentStatus", InventoryAdjustmentStatusAdjusted).Error; err != nil {
+		return err
+	}
+	inventoryAdjustment.CurrentStatus = InventoryAdjustmentStatusAdjusted
+
+	// Apply inventory side-effects deterministically for quantity adjustments.
+	if config.UseStockCommandsFor("INVENTORY_ADJUSTMENT") {
+		if err := ApplyInventoryAdjustmentStockForStatusTransition(tx.WithContext(ctx), inventoryAdjustment, InventoryAdjustmentStatusDraft); err != nil {
+			return err
+		}
+	}
+
+	// Guardrail for VALUE adjustments:
+	// Value adjustments (IVAV) require existing stock history baseline to revalue inventory.
+	// If stock_histories are not ready yet (e.g. opening stock not posted/worker lag),
+	// the async workflow will fail and the UI will show no journal + no valuation changes.
+	// Fail fast with a clear error instead of silently creating an un-postable adjustment.
+	if inventoryAdjustment.AdjustmentType == InventoryAdjustmentTypeValue {
+		stockDate, err := utils.ConvertToDate(inventoryAdjustment.AdjustmentDate, business.Timezone)
+		if err != nil {
+			return err
+		}
+		stockDateExclusiveEnd := stockDate.AddDate(0, 0, 1)
+		for _, d := range inventoryAdjustment.Details {
+			if d.ProductId <= 0 {
+				continue
 			}
-			stockDateExclusiveEnd := stockDate.AddDate(0, 0, 1)
-			for _, d := range inventoryAdjustment.Details {
-				if d.ProductId <= 0 {
-					continue
-				}
-				var exists int
-				if err := tx.WithContext(ctx).Raw(`
+			var exists int
+			if err := tx.WithContext(ctx).Raw(`
 SELECT 1
 FROM stock_histories
 WHERE business_id = ?
@@ -416,37 +503,28 @@ WHERE business_id = ?
   AND reversed_by_stock_history_id IS NULL
 LIMIT 1
 `, inventoryAdjustment.BusinessId, inventoryAdjustment.WarehouseId, d.ProductId, d.ProductType, d.BatchNumber, stockDateExclusiveEnd).Scan(&exists).Error; err != nil {
-					tx.Rollback()
-					return nil, err
-				}
-				if exists != 1 {
-					tx.Rollback()
-					return nil, errors.New("inventory valuation is not ready for this item yet (stock history missing). Please wait a moment and try again, or ensure opening stock posting has completed.")
-				}
-			}
-		}
-
-		// Write outbox record only when adjusted (posting should not happen for Draft).
-		if inventoryAdjustment.AdjustmentType == InventoryAdjustmentTypeQuantity {
-			if err := PublishToAccounting(ctx, tx, businessId, inventoryAdjustment.AdjustmentDate, inventoryAdjustment.ID, AccountReferenceTypeInventoryAdjustmentQuantity, inventoryAdjustment, nil, PubSubMessageActionCreate); err != nil {
-				tx.Rollback()
-				return nil, err
+				return err
 			}
-		} else {
-			if err := PublishToAccounting(ctx, tx, businessId, inventoryAdjustment.AdjustmentDate, inventoryAdjustment.ID, AccountReferenceTypeInventoryAdjustmentValue, inventoryAdjustment, nil, PubSubMessageActionCreate); err != nil {
-				tx.Rollback()
-				return nil, err
+			if exists != 1 {
+				return errors.New("inventory valuation is not ready for this item yet (stock history missing). Please wait a moment and try again, or ensure opening stock posting has completed.")
 			}
 		}
-	} else {
-		// Not adjusted yet: do not publish posting.
 	}
 
-	if err := tx.Commit().Error; err != nil {
-		return nil, err
+	// Write outbox record only when adjusted (posting should not happen for Draft).
+	if inventoryAdjustment.AdjustmentType == InventoryAdjustmentTypeQuantity {
+		if err := postSerialNumbers(tx.WithContext(ctx), businessId, inventoryAdjustment.serialNumberPosting()); err != nil {
+			return err
+		}
+		if err := PublishToAccounting(ctx, tx, businessId, inventoryAdjustment.AdjustmentDate, inventoryAdjustment.ID, AccountReferenceTypeInventoryAdjustmentQuantity, inventoryAdjustment, nil, PubSubMessageActionCreate); err != nil {
+			return err
+		}
+	} else {
+		if err := PublishToAccounting(ctx, tx, businessId, inventoryAdjustment.AdjustmentDate, inventoryAdjustment.ID, AccountReferenceTypeInventoryAdjustmentValue, inventoryAdjustment, nil, PubSubMessageActionCreate); err != nil {
+			return err
+		}
 	}
-
-	return &inventoryAdjustment, nil
+	return nil
 }
 
 // func UpdateInventoryAdjustment(ctx context.Context, id int, input *NewInventoryAdjustment) (*InventoryAdjustment, error) {
@@ -658,6 +736,11 @@ func DeleteInventoryAdjustment(ctx context.Context, id int) (*InventoryAdjustmen
 	db := config.GetDB()
 	tx := db.Begin()
 
+	if err := withdrawApproval(ctx, tx, ApprovalDocumentTypeInventoryAdjustment, id); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	// Keep cache tables consistent with the chosen processing mode:
 	// - When stock commands are enabled for INVENTORY_ADJUSTMENT, we apply stock_summaries updates synchronously.
 	// - When disabled, inventory availability is reconciled via async stock ledger workflows (and their cache updates),
@@ -707,6 +790,10 @@ func DeleteInventoryAdjustment(ctx context.Context, id int) (*InventoryAdjustmen
 	}
 
 	if result.AdjustmentType == InventoryAdjustmentTypeQuantity {
+		if err := unpostSerialNumbers(tx.WithContext(ctx), businessId, StockReferenceTypeInventoryAdjustmentQuantity, result.ID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
 		err = PublishToAccounting(ctx, tx, businessId, result.AdjustmentDate, result.ID, AccountReferenceTypeInventoryAdjustmentQuantity, nil, &oldForMsg, PubSubMessageActionDelete)
 		if err != nil {
 			tx.Rollback()
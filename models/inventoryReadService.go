@@ -67,10 +67,10 @@ WITH Ledger AS (
         SUM(CASE WHEN sh.reference_type IN ('POS','PGOS','PCOS') THEN sh.qty ELSE 0 END) AS opening_qty,
         SUM(CASE WHEN sh.reference_type IN ('BL','CN') AND sh.qty > 0 THEN sh.qty ELSE 0 END) AS received_qty,
         SUM(CASE WHEN sh.reference_type = 'IV' THEN ABS(sh.qty) ELSE 0 END) AS sale_qty,
-        SUM(CASE WHEN sh.reference_type = 'TO' AND sh.is_transfer_in = true THEN sh.qty ELSE 0 END) AS transfer_qty_in,
+        SUM(CASE WHEN sh.reference_type IN ('TO','TOR') AND sh.is_transfer_in = true THEN sh.qty ELSE 0 END) AS transfer_qty_in,
         SUM(CASE WHEN sh.reference_type = 'TO' AND sh.is_transfer_in = false THEN ABS(sh.qty) ELSE 0 END) AS transfer_qty_out,
-        SUM(CASE WHEN sh.reference_type = 'IVAQ' AND sh.qty > 0 THEN sh.qty ELSE 0 END) AS adjusted_qty_in,
-        SUM(CASE WHEN sh.reference_type = 'IVAQ' AND sh.qty < 0 THEN ABS(sh.qty) ELSE 0 END) AS adjusted_qty_out,
+        SUM(CASE WHEN sh.reference_type IN ('IVAQ','AO') AND sh.qty > 0 THEN sh.qty ELSE 0 END) AS adjusted_qty_in,
+        SUM(CASE WHEN sh.reference_type IN ('IVAQ','AO') AND sh.qty < 0 THEN ABS(sh.qty) ELSE 0 END) AS adjusted_qty_out,
         SUM(sh.qty) AS current_qty
     FROM stock_histories sh
     WHERE sh.business_id = @businessId
@@ -118,10 +118,10 @@ WITH Ledger AS (
         SUM(CASE WHEN sh.reference_type IN ('POS','PGOS','PCOS') THEN sh.qty ELSE 0 END) AS opening_qty,
         SUM(CASE WHEN sh.reference_type IN ('BL','CN') AND sh.qty > 0 THEN sh.qty ELSE 0 END) AS received_qty,
         SUM(CASE WHEN sh.reference_type = 'IV' THEN ABS(sh.qty) ELSE 0 END) AS sale_qty,
-        SUM(CASE WHEN sh.reference_type = 'TO' AND sh.is_transfer_in = true THEN sh.qty ELSE 0 END) AS transfer_qty_in,
+        SUM(CASE WHEN sh.reference_type IN ('TO','TOR') AND sh.is_transfer_in = true THEN sh.qty ELSE 0 END) AS transfer_qty_in,
         SUM(CASE WHEN sh.reference_type = 'TO' AND sh.is_transfer_in = false THEN ABS(sh.qty) ELSE 0 END) AS transfer_qty_out,
-        SUM(CASE WHEN sh.reference_type = 'IVAQ' AND sh.qty > 0 THEN sh.qty ELSE 0 END) AS adjusted_qty_in,
-        SUM(CASE WHEN sh.reference_type = 'IVAQ' AND sh.qty < 0 THEN ABS(sh.qty) ELSE 0 END) AS adjusted_qty_out,
+        SUM(CASE WHEN sh.reference_type IN ('IVAQ','AO') AND sh.qty > 0 THEN sh.qty ELSE 0 END) AS adjusted_qty_in,
+        SUM(CASE WHEN sh.reference_type IN ('IVAQ','AO') AND sh.qty < 0 THEN ABS(sh.qty) ELSE 0 END) AS adjusted_qty_out,
         SUM(sh.qty) AS current_qty
     FROM stock_histories sh
     WHERE sh.business_id = @businessId
@@ -356,10 +356,10 @@ WITH Ledger AS (
         SUM(CASE WHEN sh.reference_type IN ('POS','PGOS','PCOS') THEN sh.qty ELSE 0 END) AS opening_qty,
         SUM(CASE WHEN sh.reference_type IN ('BL','CN') AND sh.qty > 0 THEN sh.qty ELSE 0 END) AS received_qty,
         SUM(CASE WHEN sh.reference_type = 'IV' THEN ABS(sh.qty) ELSE 0 END) AS sale_qty,
-        SUM(CASE WHEN sh.reference_type = 'TO' AND sh.is_transfer_in = true THEN sh.qty ELSE 0 END) AS transfer_qty_in,
+        SUM(CASE WHEN sh.reference_type IN ('TO','TOR') AND sh.is_transfer_in = true THEN sh.qty ELSE 0 END) AS transfer_qty_in,
         SUM(CASE WHEN sh.reference_type = 'TO' AND sh.is_transfer_in = false THEN ABS(sh.qty) ELSE 0 END) AS transfer_qty_out,
-        SUM(CASE WHEN sh.reference_type = 'IVAQ' AND sh.qty > 0 THEN sh.qty ELSE 0 END) AS adjusted_qty_in,
-        SUM(CASE WHEN sh.reference_type = 'IVAQ' AND sh.qty < 0 THEN ABS(sh.qty) ELSE 0 END) AS adjusted_qty_out,
+        SUM(CASE WHEN sh.reference_type IN ('IVAQ','AO') AND sh.qty > 0 THEN sh.qty ELSE 0 END) AS adjusted_qty_in,
+        SUM(CASE WHEN sh.reference_type IN ('IVAQ','AO') AND sh.qty < 0 THEN ABS(sh.qty) ELSE 0 END) AS adjusted_qty_out,
         SUM(sh.qty) AS current_qty
     FROM stock_histories sh
     WHERE sh.business_id = @businessId
@@ -466,10 +466,10 @@ WITH Ledger AS (
         SUM(CASE WHEN sh.reference_type IN ('POS','PGOS','PCOS') THEN sh.qty ELSE 0 END) AS opening_qty,
         SUM(CASE WHEN sh.reference_type IN ('BL','CN') AND sh.qty > 0 THEN sh.qty ELSE 0 END) AS received_qty,
         SUM(CASE WHEN sh.reference_type = 'IV' THEN ABS(sh.qty) ELSE 0 END) AS sale_qty,
-        SUM(CASE WHEN sh.reference_type = 'TO' AND sh.is_transfer_in = true THEN sh.qty ELSE 0 END) AS transfer_qty_in,
+        SUM(CASE WHEN sh.reference_type IN ('TO','TOR') AND sh.is_transfer_in = true THEN sh.qty ELSE 0 END) AS transfer_qty_in,
         SUM(CASE WHEN sh.reference_type = 'TO' AND sh.is_transfer_in = false THEN ABS(sh.qty) ELSE 0 END) AS transfer_qty_out,
-        SUM(CASE WHEN sh.reference_type = 'IVAQ' AND sh.qty > 0 THEN sh.qty ELSE 0 END) AS adjusted_qty_in,
-        SUM(CASE WHEN sh.reference_type = 'IVAQ' AND sh.qty < 0 THEN ABS(sh.qty) ELSE 0 END) AS adjusted_qty_out,
+        SUM(CASE WHEN sh.reference_type IN ('IVAQ','AO') AND sh.qty > 0 THEN sh.qty ELSE 0 END) AS adjusted_qty_in,
+        SUM(CASE WHEN sh.reference_type IN ('IVAQ','AO') AND sh.qty < 0 THEN ABS(sh.qty) ELSE 0 END) AS adjusted_qty_out,
         SUM(sh.qty) AS current_qty
     FROM stock_histories sh
     WHERE sh.business_id = @businessId
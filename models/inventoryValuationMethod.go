@@ -0,0 +1,518 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// InventoryValuationMethod decides how outgoing stock is costed. FIFO consumes the
+// oldest remaining incoming layers; WEIGHTED_AVERAGE costs each outgoing row at the
+// moving average of the warehouse's stock on hand at that point in the ledger.
+type InventoryValuationMethod string
+
+const (
+	InventoryValuationMethodFIFO            InventoryValuationMethod = "FIFO"
+	InventoryValuationMethodWeightedAverage InventoryValuationMethod = "WEIGHTED_AVERAGE"
+)
+
+func (m InventoryValuationMethod) IsValid() bool {
+	switch m {
+	case InventoryValuationMethodFIFO, InventoryValuationMethodWeightedAverage:
+		return true
+	}
+	return false
+}
+
+func (m InventoryValuationMethod) label() string {
+	if m == InventoryValuationMethodWeightedAverage {
+		return "weighted average"
+	}
+	return "FIFO"
+}
+
+// InventoryValuationMethodRecord keeps the history of method switches. Stock dated on or
+// before SwitchDate was valued with FromMethod and is closed by the value adjustments the
+// switch posted on that date; later stock is valued with ToMethod.
+// A switch with a ProductId applies to that product or variant only, which from then on is
+// valued with ToMethod whatever the business's method is.
+type InventoryValuationMethodRecord struct {
+	ID          int                      `gorm:"primary_key" json:"id"`
+	BusinessId  string                   `gorm:"index;not null" json:"business_id"`
+	ProductId   int                      `gorm:"index;default:0" json:"product_id"`
+	ProductType ProductType              `gorm:"type:enum('S','V');default:S" json:"product_type"`
+	FromMethod  InventoryValuationMethod `gorm:"size:20;not null" json:"from_method"`
+	ToMethod    InventoryValuationMethod `gorm:"size:20;not null" json:"to_method"`
+	SwitchDate  time.Time                `gorm:"not null" json:"switch_date"`
+	AccountId   int                      `gorm:"not null" json:"account_id"`
+	Reason      string                   `gorm:"default:null" json:"reason"`
+	UserId      int                      `gorm:"index;not null" json:"user_id"`
+	UserName    string                   `gorm:"size:100" json:"user_name"`
+	CreatedAt   time.Time                `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// NewInventoryValuationMethod switches the business's method, or one product's when
+// ProductId is set.
+type NewInventoryValuationMethod struct {
+	Method      InventoryValuationMethod `json:"method" binding:"required"`
+	SwitchDate  time.Time                `json:"switch_date" binding:"required"`
+	AccountId   int                      `json:"account_id" binding:"required"`
+	ReasonId    int                      `json:"reason_id" binding:"required"`
+	Reason      string                   `json:"reason"`
+	ProductId   int                      `json:"product_id"`
+	ProductType ProductType              `json:"product_type"`
+}
+
+// CurrentInventoryValuationMethod treats businesses created before the setting existed as FIFO.
+func (b *Business) CurrentInventoryValuationMethod() InventoryValuationMethod {
+	if b.InventoryValuationMethod.IsValid() {
+		return b.InventoryValuationMethod
+	}
+	return InventoryValuationMethodFIFO
+}
+
+// InventoryValuationMethodAt returns the method that values the product's stock dated stockDate.
+func InventoryValuationMethodAt(tx *gorm.DB, business *Business, productId int, productType ProductType, stockDate time.Time) (InventoryValuationMethod, error) {
+	switches, err := InventoryValuationMethodSwitches(tx, business.ID.String(), productId, productType, stockDate)
+	if err != nil {
+		return "", err
+	}
+	return InventoryValuationMethodOn(business, switches, stockDate), nil
+}
+
+// InventoryValuationMethodSwitches returns the switches that decide the product's method from
+// `from` on, oldest first: the business's switches dated on or after from, and every switch
+// made for the product.
+func InventoryValuationMethodSwitches(tx *gorm.DB, businessId string, productId int, productType ProductType, from time.Time) ([]InventoryValuationMethodRecord, error) {
+	var records []InventoryValuationMethodRecord
+	err := tx.Where("business_id = ? AND ((product_id = 0 AND switch_date >= ?) OR (product_id > 0 AND product_id = ? AND product_type = ?))",
+		businessId, from, productId, productType).
+		Order("switch_date, id").
+		Find(&records).Error
+	return records, err
+}
+
+// InventoryValuationMethodOn returns the method that values stock dated stockDate, given the
+// switches from InventoryValuationMethodSwitches. Once a product has been switched on its
+// own it follows its own switches: the method the next one on or after stockDate moved away
+// from, or the one it moved to last. Before that, and for other products, the business's
+// switches decide the same way, or its current method when there is none.
+func InventoryValuationMethodOn(business *Business, switches []InventoryValuationMethodRecord, stockDate time.Time) InventoryValuationMethod {
+	var productMethod InventoryValuationMethod
+	for _, record := range switches {
+		if record.ProductId == 0 {
+			continue
+		}
+		if !record.SwitchDate.Before(stockDate) {
+			if productMethod == "" {
+				break
+			}
+			return record.FromMethod
+		}
+		productMethod = record.ToMethod
+	}
+	if productMethod != "" {
+		return productMethod
+	}
+	for _, record := range switches {
+		if record.ProductId == 0 && !record.SwitchDate.Before(stockDate) {
+			return record.FromMethod
+		}
+	}
+	return business.CurrentInventoryValuationMethod()
+}
+
+// WeightedAverageUnitCost is the unit cost of qty on hand worth value. An empty or
+// negative pool keeps the last average so goods sold short are still costed.
+func WeightedAverageUnitCost(qty decimal.Decimal, value decimal.Decimal, lastUnitCost decimal.Decimal) decimal.Decimal {
+	if !qty.IsPositive() {
+		return lastUnitCost
+	}
+	return value.DivRound(qty, 4)
+}
+
+type inventoryOnHand struct {
+	WarehouseId int
+	ProductId   int
+	ProductType ProductType
+	Qty         decimal.Decimal
+	AssetValue  decimal.Decimal
+}
+
+// SwitchInventoryValuationMethod changes the business's valuation method from the day after
+// switchDate, or only the product's when input.ProductId is set. Stock on hand is restated
+// in every warehouse by a value adjustment dated switchDate at its value under the new
+// method, so the new method starts from a single layer per item. An item's batches are one stock to both
+// methods, so each item is restated once; products switched on their own keep their method
+// and are left out of a business switch. The adjustments' journals against AccountId carry
+// the difference between the old and the new method's value. The switch is prospective: no
+// stock may have moved after switchDate, and documents dated on or before it can no longer
+// touch stock.
+func SwitchInventoryValuationMethod(ctx context.Context, input *NewInventoryValuationMethod) (*Business, error) {
+	db := config.GetDB()
+
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	userId, ok := utils.GetUserIdFromContext(ctx)
+	if !ok || userId == 0 {
+		return nil, errors.New("user id is required")
+	}
+	userName, ok := utils.GetUserNameFromContext(ctx)
+	if !ok {
+		return nil, errors.New("user name is required")
+	}
+
+	var business Business
+	if err := db.WithContext(ctx).Where("id = ?", businessId).First(&business).Error; err != nil {
+		return nil, utils.ErrorRecordNotFound
+	}
+	if !input.Method.IsValid() {
+		return nil, errors.New("invalid inventory valuation method")
+	}
+	forProduct := input.ProductId > 0
+	productName := ""
+	if forProduct {
+		if input.ProductType != ProductTypeSingle && input.ProductType != ProductTypeVariant {
+			return nil, errors.New("the valuation method can only be set for single products and variants")
+		}
+		name, err := componentName(ctx, input.ProductType, input.ProductId)
+		if err != nil {
+			return nil, err
+		}
+		productName = name
+	}
+
+	// the switch follows the previous one that covered the same stock
+	previous := db.WithContext(ctx).Where("business_id = ?", businessId)
+	if forProduct {
+		previous = previous.Where("product_id = 0 OR (product_id = ? AND product_type = ?)", input.ProductId, input.ProductType)
+	}
+	var previousSwitches []InventoryValuationMethodRecord
+	if err := previous.Order("switch_date, id").Find(&previousSwitches).Error; err != nil {
+		return nil, err
+	}
+	fromMethod := business.CurrentInventoryValuationMethod()
+	var lastSwitch *InventoryValuationMethodRecord
+	for i := range previousSwitches {
+		lastSwitch = &previousSwitches[i]
+		if forProduct && lastSwitch.ProductId > 0 {
+			fromMethod = lastSwitch.ToMethod
+		}
+	}
+	if input.Method == fromMethod {
+		return nil, fmt.Errorf("inventory is already valued with %s", fromMethod.label())
+	}
+	if err := utils.ValidateResourceId[Account](ctx, businessId, input.AccountId); err != nil {
+		return nil, errors.New("account not found")
+	}
+	if err := utils.ValidateResourceId[Reason](ctx, businessId, input.ReasonId); err != nil {
+		return nil, errors.New("reason not found")
+	}
+	if err := ValidateTransactionLock(ctx, input.SwitchDate, businessId, AccountantTransactionLock); err != nil {
+		return nil, err
+	}
+	switchDate, err := utils.ConvertToDate(input.SwitchDate, business.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	if lastSwitch != nil && !switchDate.After(lastSwitch.SwitchDate) {
+		return nil, errors.New("switch date must be after the previous valuation method change")
+	}
+	switchDateExclusiveEnd := switchDate.AddDate(0, 0, 1)
+
+	// the stock the switch covers: the product's, or every product not switched on its own
+	covered, coveredArgs := "(product_id, product_type) NOT IN (SELECT product_id, product_type FROM inventory_valuation_method_records WHERE business_id = ? AND product_id > 0)", []interface{}{businessId}
+	if forProduct {
+		covered, coveredArgs = "product_id = ? AND product_type = ?", []interface{}{input.ProductId, input.ProductType}
+	}
+
+	var laterCount int64
+	if err := db.WithContext(ctx).Model(&StockHistory{}).
+		Where("business_id = ? AND stock_date >= ? AND is_reversal = 0 AND reversed_by_stock_history_id IS NULL", businessId, switchDateExclusiveEnd).
+		Where(covered, coveredArgs...).
+		Count(&laterCount).Error; err != nil {
+		return nil, err
+	}
+	if laterCount > 0 {
+		return nil, errors.New("stock has moved after the switch date; choose a date on or after the latest stock transaction")
+	}
+	var pendingCount int64
+	if err := db.WithContext(ctx).Model(&PubSubMessageRecord{}).
+		Where("business_id = ? AND is_processed = 0", businessId).
+		Count(&pendingCount).Error; err != nil {
+		return nil, err
+	}
+	if pendingCount > 0 {
+		return nil, errors.New("postings are still being processed; try again shortly")
+	}
+
+	var onHand []inventoryOnHand
+	if err := db.WithContext(ctx).Raw(`
+		SELECT
+		  warehouse_id,
+		  product_id,
+		  product_type,
+		  SUM(qty) AS qty,
+		  SUM(qty * base_unit_value) AS asset_value
+		FROM stock_histories
+		WHERE business_id = ?
+		  AND stock_date < ?
+		  AND is_reversal = 0
+		  AND reversed_by_stock_history_id IS NULL
+		  AND `+covered+`
+		GROUP BY warehouse_id, product_id, product_type
+		HAVING SUM(qty) > 0
+		ORDER BY warehouse_id, product_type, product_id
+	`, append([]interface{}{businessId, switchDateExclusiveEnd}, coveredArgs...)...).Scan(&onHand).Error; err != nil {
+		return nil, err
+	}
+
+	description := fmt.Sprintf("Inventory valuation method changed from %s to %s", fromMethod.label(), input.Method.label())
+	if forProduct {
+		description = fmt.Sprintf("Inventory valuation method of %s changed from %s to %s", productName, fromMethod.label(), input.Method.label())
+	}
+	adjustments := make([]*InventoryAdjustment, 0)
+	for _, item := range onHand {
+		var adjustment *InventoryAdjustment
+		if n := len(adjustments); n > 0 && adjustments[n-1].WarehouseId == item.WarehouseId {
+			adjustment = adjustments[n-1]
+		} else {
+			warehouse, err := utils.FetchModel[Warehouse](ctx, businessId, item.WarehouseId)
+			if err != nil {
+				return nil, err
+			}
+			adjustment = &InventoryAdjustment{
+				BusinessId:     businessId,
+				AdjustmentType: InventoryAdjustmentTypeValue,
+				AdjustmentDate: switchDate,
+				AccountId:      input.AccountId,
+				BranchId:       warehouse.BranchId,
+				WarehouseId:    warehouse.ID,
+				ReasonId:       input.ReasonId,
+				Description:    description,
+				CurrentStatus:  InventoryAdjustmentStatusDraft,
+				CreatedBy:      userId,
+			}
+			adjustments = append(adjustments, adjustment)
+		}
+		name, err := componentName(ctx, item.ProductType, item.ProductId)
+		if err != nil {
+			return nil, err
+		}
+		value, err := onHandValueUnder(db.WithContext(ctx), businessId, item, input.Method, switchDateExclusiveEnd)
+		if err != nil {
+			return nil, err
+		}
+		adjustment.Details = append(adjustment.Details, InventoryAdjustmentDetail{
+			ProductId:     item.ProductId,
+			ProductType:   item.ProductType,
+			Name:          name,
+			AdjustedValue: WeightedAverageUnitCost(item.Qty, value, decimal.Zero),
+			CostPrice:     WeightedAverageUnitCost(item.Qty, item.AssetValue, decimal.Zero),
+		})
+	}
+
+	tx := db.Begin()
+	for _, adjustment := range adjustments {
+		if err := tx.WithContext(ctx).Create(adjustment).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := adjustInventoryAdjustment(ctx, tx, businessId, adjustment); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// Written with the adjustments so the worker values them with the previous method.
+	record := InventoryValuationMethodRecord{
+		BusinessId: businessId,
+		FromMethod: fromMethod,
+		ToMethod:   input.Method,
+		SwitchDate: switchDate,
+		AccountId:  input.AccountId,
+		Reason:     input.Reason,
+		UserId:     userId,
+		UserName:   userName,
+	}
+	if forProduct {
+		record.ProductId = input.ProductId
+		record.ProductType = input.ProductType
+	}
+	if err := tx.WithContext(ctx).Create(&record).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if !forProduct {
+		if err := tx.WithContext(ctx).Model(&business).Updates(map[string]interface{}{
+			"InventoryValuationMethod":     input.Method,
+			"InventoryValuationMethodDate": switchDate,
+		}).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	if forProduct {
+		return &business, nil
+	}
+	business.InventoryValuationMethod = input.Method
+	business.InventoryValuationMethodDate = &switchDate
+
+	// caching
+	if err := business.RemoveRedis(); err != nil {
+		return nil, err
+	}
+	if err := utils.ClearRedisAdmin[Business](); err != nil {
+		return nil, err
+	}
+	return &business, nil
+}
+
+// onHandValueUnder values an item's stock on hand in a warehouse, dated before `before`,
+// as method would have. A value adjustment, which a previous switch's restatement is too,
+// leaves a single layer both methods agree on at the end of its date, so the ledger value
+// up to then opens the pool and only the rows after it are replayed, incoming rows first
+// within a day like the costing workflow walks them.
+func onHandValueUnder(tx *gorm.DB, businessId string, item inventoryOnHand, method InventoryValuationMethod, before time.Time) (decimal.Decimal, error) {
+	active := "business_id = ? AND warehouse_id = ? AND product_id = ? AND product_type = ? AND is_reversal = 0 AND reversed_by_stock_history_id IS NULL"
+	args := []interface{}{businessId, item.WarehouseId, item.ProductId, item.ProductType}
+
+	var lastValueAdjustment StockHistory
+	if err := tx.Where(active, args...).
+		Where("reference_type = ? AND stock_date < ?", StockReferenceTypeInventoryAdjustmentValue, before).
+		Order("stock_date DESC").
+		Limit(1).
+		Find(&lastValueAdjustment).Error; err != nil {
+		return decimal.Zero, err
+	}
+	var opening struct {
+		Qty        decimal.Decimal
+		AssetValue decimal.Decimal
+	}
+	rowsQuery := tx.Where(active, args...).Where("stock_date < ?", before)
+	if lastValueAdjustment.ID > 0 {
+		from := lastValueAdjustment.StockDate.AddDate(0, 0, 1)
+		if err := tx.Model(&StockHistory{}).
+			Select("COALESCE(SUM(qty), 0) AS qty, COALESCE(SUM(qty * base_unit_value), 0) AS asset_value").
+			Where(active, args...).
+			Where("stock_date < ?", from).
+			Scan(&opening).Error; err != nil {
+			return decimal.Zero, err
+		}
+		rowsQuery = rowsQuery.Where("stock_date >= ?", from)
+	}
+	var rows []*StockHistory
+	if err := rowsQuery.Order("stock_date, is_outgoing, id").Find(&rows).Error; err != nil {
+		return decimal.Zero, err
+	}
+	if method == InventoryValuationMethodWeightedAverage {
+		_, value := WeightedAverageOnHand(opening.Qty, opening.AssetValue, rows)
+		return value, nil
+	}
+	return FifoOnHandValue(opening.Qty, opening.AssetValue, rows), nil
+}
+
+// WeightedAverageOnHand is the quantity and value left after walking rows from an opening
+// quantity and value with every outgoing row leaving at the moving average.
+func WeightedAverageOnHand(openingQty decimal.Decimal, openingValue decimal.Decimal, rows []*StockHistory) (decimal.Decimal, decimal.Decimal) {
+	revalue := make(map[int]bool)
+	for _, row := range rows {
+		if row.IsOutgoing != nil && *row.IsOutgoing {
+			revalue[row.ID] = true
+		}
+	}
+	costs := WeightedAverageCosts(openingQty, openingValue, rows, revalue)
+	qty, value := openingQty, openingValue
+	for _, row := range rows {
+		rowQty := row.Qty.Abs()
+		unitCost := row.BaseUnitValue
+		if revalue[row.ID] {
+			rowQty = rowQty.Neg()
+			unitCost = costs[row.ID]
+		}
+		qty = qty.Add(rowQty)
+		value = value.Add(rowQty.Mul(unitCost))
+	}
+	return qty, value
+}
+
+// FifoOnHandValue is the value of the layers left after walking rows from an opening
+// quantity and value, which form the oldest layer, with outgoing rows consuming the
+// oldest layers first. Stock sold short leaves nothing to value.
+func FifoOnHandValue(openingQty decimal.Decimal, openingValue decimal.Decimal, rows []*StockHistory) decimal.Decimal {
+	type layer struct{ qty, unitCost decimal.Decimal }
+	var layers []layer
+	if openingQty.IsPositive() {
+		layers = append(layers, layer{openingQty, openingValue.Div(openingQty)})
+	}
+	for _, row := range rows {
+		if row.IsOutgoing == nil || !*row.IsOutgoing {
+			layers = append(layers, layer{row.Qty.Abs(), row.BaseUnitValue})
+			continue
+		}
+		out := row.Qty.Abs()
+		for out.IsPositive() && len(layers) > 0 {
+			taken := decimal.Min(out, layers[0].qty)
+			layers[0].qty = layers[0].qty.Sub(taken)
+			out = out.Sub(taken)
+			if !layers[0].qty.IsPositive() {
+				layers = layers[1:]
+			}
+		}
+	}
+	value := decimal.Zero
+	for _, l := range layers {
+		value = value.Add(l.qty.Mul(l.unitCost))
+	}
+	return value
+}
+
+func GetInventoryValuationMethodRecords(ctx context.Context) ([]*InventoryValuationMethodRecord, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	db := config.GetDB()
+	var results []*InventoryValuationMethodRecord
+	if err := db.WithContext(ctx).Where("business_id = ?", businessId).Order("switch_date DESC, id DESC").Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// WeightedAverageCosts walks one item's ledger rows in a warehouse, in posting order, from
+// an opening quantity and value. Outgoing rows listed in revalue leave at the moving
+// average; all other rows move the pool at their recorded unit value. It returns the unit
+// cost of each revalued row by ID.
+func WeightedAverageCosts(openingQty decimal.Decimal, openingValue decimal.Decimal, rows []*StockHistory, revalue map[int]bool) map[int]decimal.Decimal {
+	costs := make(map[int]decimal.Decimal, len(revalue))
+	qty, value := openingQty, openingValue
+	lastUnitCost := WeightedAverageUnitCost(qty, value, decimal.Zero)
+	for _, row := range rows {
+		rowQty := row.Qty.Abs()
+		unitCost := row.BaseUnitValue
+		if row.IsOutgoing != nil && *row.IsOutgoing {
+			rowQty = rowQty.Neg()
+			if revalue[row.ID] {
+				unitCost = WeightedAverageUnitCost(qty, value, lastUnitCost)
+				costs[row.ID] = unitCost
+			}
+		}
+		qty = qty.Add(rowQty)
+		value = value.Add(rowQty.Mul(unitCost))
+		lastUnitCost = WeightedAverageUnitCost(qty, value, lastUnitCost)
+	}
+	return costs
+}
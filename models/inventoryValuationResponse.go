@@ -24,6 +24,8 @@ type InventoryValuationResponse struct {
 	Details            []*InventoryValuationDetail `gorm:"-" json:"details,omitempty"`
 	ClosingStockOnHand decimal.Decimal             `json:"closingStockOnHand"`
 	ClosingAssetValue  decimal.Decimal             `json:"closingAssetValue"`
+	// ValuationMethod values the item's outgoing stock as of toDate.
+	ValuationMethod InventoryValuationMethod `gorm:"-" json:"valuationMethod"`
 }
 
 type InventoryValuationDetail struct {
@@ -254,6 +256,10 @@ ORDER BY stock_histories.stock_date, stock_histories.cumulative_sequence, stock_
 		response.ClosingAssetValue = response.OpeningAssetValue
 		response.ClosingStockOnHand = response.OpeningStockOnHand
 	}
+	response.ValuationMethod, err = InventoryValuationMethodAt(db.WithContext(ctx), business, productId, productType, time.Time(toDate))
+	if err != nil {
+		return nil, err
+	}
 	return &response, nil
 }
 
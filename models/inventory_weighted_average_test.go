@@ -0,0 +1,183 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+)
+
+func TestWeightedAverageCosts(t *testing.T) {
+	d := decimal.RequireFromString
+	in := func(id int, qty, unitValue string) *models.StockHistory {
+		return &models.StockHistory{ID: id, Qty: d(qty), BaseUnitValue: d(unitValue), IsOutgoing: utils.NewFalse()}
+	}
+	out := func(id int, qty, unitValue string) *models.StockHistory {
+		return &models.StockHistory{ID: id, Qty: d(qty).Neg(), BaseUnitValue: d(unitValue), IsOutgoing: utils.NewTrue()}
+	}
+	for _, tc := range []struct {
+		name                     string
+		openingQty, openingValue string
+		rows                     []*models.StockHistory
+		revalue                  []int
+		want                     map[int]string
+	}{
+		{
+			name:       "purchase averages into opening stock",
+			openingQty: "10", openingValue: "50",
+			rows:    []*models.StockHistory{in(1, "10", "8"), out(2, "5", "0"), out(3, "5", "0")},
+			revalue: []int{2, 3},
+			want:    map[int]string{2: "6.5", 3: "6.5"},
+		},
+		{
+			name:       "rows outside the set leave at their recorded cost",
+			openingQty: "0", openingValue: "0",
+			rows:    []*models.StockHistory{in(1, "4", "10"), out(2, "2", "12"), out(3, "1", "0")},
+			revalue: []int{3},
+			want:    map[int]string{3: "8"},
+		},
+		{
+			name:       "sold short keeps the last average",
+			openingQty: "0", openingValue: "0",
+			rows:    []*models.StockHistory{in(1, "2", "3"), out(2, "3", "0"), out(3, "1", "0"), in(4, "5", "4"), out(5, "1", "0")},
+			revalue: []int{2, 3, 5},
+			want:    map[int]string{2: "3", 3: "3", 5: "4.6667"},
+		},
+		{
+			name:       "average rounds to four places",
+			openingQty: "3", openingValue: "10",
+			rows:    []*models.StockHistory{out(1, "1", "0")},
+			revalue: []int{1},
+			want:    map[int]string{1: "3.3333"},
+		},
+	} {
+		revalue := make(map[int]bool)
+		for _, id := range tc.revalue {
+			revalue[id] = true
+		}
+		got := models.WeightedAverageCosts(d(tc.openingQty), d(tc.openingValue), tc.rows, revalue)
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: costed %d rows, want %d", tc.name, len(got), len(tc.want))
+		}
+		for id, want := range tc.want {
+			if !got[id].Equal(d(want)) {
+				t.Errorf("%s: row %d cost %s, want %s", tc.name, id, got[id], want)
+			}
+		}
+	}
+}
+
+// Switching methods restates stock on hand at what the new method would have left, so the
+// two methods value the same ledger differently.
+func TestOnHandValueByMethod(t *testing.T) {
+	d := decimal.RequireFromString
+	in := func(id int, qty, unitValue string) *models.StockHistory {
+		return &models.StockHistory{ID: id, Qty: d(qty), BaseUnitValue: d(unitValue), IsOutgoing: utils.NewFalse()}
+	}
+	out := func(id int, qty, unitValue string) *models.StockHistory {
+		return &models.StockHistory{ID: id, Qty: d(qty).Neg(), BaseUnitValue: d(unitValue), IsOutgoing: utils.NewTrue()}
+	}
+	for _, tc := range []struct {
+		name                     string
+		openingQty, openingValue string
+		rows                     []*models.StockHistory
+		wantQty, wantAverage     string
+		wantFifo                 string
+	}{
+		{
+			name:       "sale after two purchases",
+			openingQty: "0", openingValue: "0",
+			rows:    []*models.StockHistory{in(1, "10", "5"), in(2, "10", "8"), out(3, "10", "5")},
+			wantQty: "10", wantAverage: "65", wantFifo: "80",
+		},
+		{
+			name:       "opening stock is the oldest layer",
+			openingQty: "4", openingValue: "20",
+			rows:    []*models.StockHistory{in(1, "4", "10"), out(2, "6", "5")},
+			wantQty: "2", wantAverage: "15", wantFifo: "20",
+		},
+		{
+			name:       "sold short leaves nothing",
+			openingQty: "0", openingValue: "0",
+			rows:    []*models.StockHistory{in(1, "2", "3"), out(2, "3", "3")},
+			wantQty: "-1", wantAverage: "-3", wantFifo: "0",
+		},
+	} {
+		qty, value := models.WeightedAverageOnHand(d(tc.openingQty), d(tc.openingValue), tc.rows)
+		if !qty.Equal(d(tc.wantQty)) || !value.Equal(d(tc.wantAverage)) {
+			t.Errorf("%s: weighted average on hand %s worth %s, want %s worth %s", tc.name, qty, value, tc.wantQty, tc.wantAverage)
+		}
+		if got := models.FifoOnHandValue(d(tc.openingQty), d(tc.openingValue), tc.rows); !got.Equal(d(tc.wantFifo)) {
+			t.Errorf("%s: FIFO on hand worth %s, want %s", tc.name, got, tc.wantFifo)
+		}
+	}
+}
+
+func TestInventoryValuationMethodOn(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	business := &models.Business{InventoryValuationMethod: models.InventoryValuationMethodFIFO}
+	switches := []models.InventoryValuationMethodRecord{
+		{FromMethod: models.InventoryValuationMethodFIFO, ToMethod: models.InventoryValuationMethodWeightedAverage, SwitchDate: day(10)},
+		{FromMethod: models.InventoryValuationMethodWeightedAverage, ToMethod: models.InventoryValuationMethodFIFO, SwitchDate: day(20)},
+	}
+	for _, tc := range []struct {
+		stockDate time.Time
+		want      models.InventoryValuationMethod
+	}{
+		{day(5), models.InventoryValuationMethodFIFO},
+		{day(10), models.InventoryValuationMethodFIFO},
+		{day(11), models.InventoryValuationMethodWeightedAverage},
+		{day(20), models.InventoryValuationMethodWeightedAverage},
+		{day(21), models.InventoryValuationMethodFIFO},
+	} {
+		if got := models.InventoryValuationMethodOn(business, switches, tc.stockDate); got != tc.want {
+			t.Errorf("method on %s = %s, want %s", tc.stockDate.Format("2006-01-02"), got, tc.want)
+		}
+	}
+}
+
+// A product switched on its own keeps its method through later business switches.
+func TestInventoryValuationMethodOn_Product(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	business := &models.Business{InventoryValuationMethod: models.InventoryValuationMethodWeightedAverage}
+	switches := []models.InventoryValuationMethodRecord{
+		{ProductId: 7, ProductType: models.ProductTypeSingle, FromMethod: models.InventoryValuationMethodFIFO, ToMethod: models.InventoryValuationMethodWeightedAverage, SwitchDate: day(10)},
+		{ProductId: 7, ProductType: models.ProductTypeSingle, FromMethod: models.InventoryValuationMethodWeightedAverage, ToMethod: models.InventoryValuationMethodFIFO, SwitchDate: day(15)},
+		{FromMethod: models.InventoryValuationMethodFIFO, ToMethod: models.InventoryValuationMethodWeightedAverage, SwitchDate: day(20)},
+	}
+	for _, tc := range []struct {
+		stockDate time.Time
+		want      models.InventoryValuationMethod
+	}{
+		{day(5), models.InventoryValuationMethodFIFO},
+		{day(11), models.InventoryValuationMethodWeightedAverage},
+		{day(16), models.InventoryValuationMethodFIFO},
+		{day(21), models.InventoryValuationMethodFIFO},
+	} {
+		if got := models.InventoryValuationMethodOn(business, switches, tc.stockDate); got != tc.want {
+			t.Errorf("product method on %s = %s, want %s", tc.stockDate.Format("2006-01-02"), got, tc.want)
+		}
+	}
+
+	// other products follow the business
+	if got := models.InventoryValuationMethodOn(business, switches[2:], day(16)); got != models.InventoryValuationMethodFIFO {
+		t.Errorf("business method on 2026-03-16 = %s, want FIFO", got)
+	}
+	if got := models.InventoryValuationMethodOn(business, switches[2:], day(21)); got != models.InventoryValuationMethodWeightedAverage {
+		t.Errorf("business method on 2026-03-21 = %s, want WEIGHTED_AVERAGE", got)
+	}
+
+	// before its first own switch the product follows the business's earlier switches
+	earlier := []models.InventoryValuationMethodRecord{
+		{FromMethod: models.InventoryValuationMethodFIFO, ToMethod: models.InventoryValuationMethodWeightedAverage, SwitchDate: day(3)},
+		{ProductId: 7, ProductType: models.ProductTypeSingle, FromMethod: models.InventoryValuationMethodWeightedAverage, ToMethod: models.InventoryValuationMethodFIFO, SwitchDate: day(10)},
+	}
+	if got := models.InventoryValuationMethodOn(business, earlier, day(2)); got != models.InventoryValuationMethodFIFO {
+		t.Errorf("product method on 2026-03-02 = %s, want FIFO", got)
+	}
+	if got := models.InventoryValuationMethodOn(business, earlier, day(5)); got != models.InventoryValuationMethodWeightedAverage {
+		t.Errorf("product method on 2026-03-05 = %s, want WEIGHTED_AVERAGE", got)
+	}
+}
@@ -30,6 +30,7 @@ type Journal struct {
 	JournalTotalAmount decimal.Decimal      `gorm:"type:decimal(20,4);default:0" json:"journal_total_amount"`
 	Transactions       []JournalTransaction `gorm:"foreignKey:JournalId" json:"transactions"`
 	Documents          []*Document          `gorm:"polymorphic:Reference" json:"documents"`
+	ApprovalStatus     *ApprovalStatus      `gorm:"size:20;default:null" json:"approval_status"`
 	CreatedAt          time.Time            `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt          time.Time            `gorm:"autoUpdateTime" json:"updated_at"`
 }
@@ -76,8 +77,23 @@ type JournalsEdge struct {
 	Node   *Journal `json:"node"`
 }
 
+func (j Journal) approvalSubject() approvalSubject {
+	return approvalSubject{
+		DocumentType:    ApprovalDocumentTypeManualJournal,
+		ReferenceId:     j.ID,
+		ReferenceNumber: j.JournalNumber,
+		BranchId:        j.BranchId,
+		CurrencyId:      j.CurrencyId,
+		ExchangeRate:    j.ExchangeRate,
+		Amount:          j.JournalTotalAmount,
+	}
+}
+
 func (j Journal) CheckTransactionLock(ctx context.Context) error {
-	return validateTransactionLock(ctx, j.JournalDate, j.BusinessId, AccountantTransactionLock)
+	if err := validateTransactionLock(ctx, j.JournalDate, j.BusinessId, AccountantTransactionLock); err != nil {
+		return err
+	}
+	return validateBankReconciliationLock(ctx, j.BusinessId, 0, BankingTransactionTypeManualJournal, j.ID)
 }
 
 // GetID method for Journal reference Data
@@ -268,7 +284,12 @@ func CreateJournal(ctx context.Context, input *NewJournal) (*Journal, error) {
 		return nil, err
 	}
 
-	err = PublishToAccounting(ctx, tx, businessId, journal.JournalDate, journal.ID, AccountReferenceTypeJournal, journal, nil, PubSubMessageActionCreate)
+	journal.ApprovalStatus, err = holdForApproval(ctx, tx, journal.approvalSubject())
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	err = publishApprovable(ctx, tx, businessId, journal.JournalDate, journal.ID, AccountReferenceTypeJournal, journal.ApprovalStatus, journal, nil, false)
 	if err != nil {
 		tx.Rollback()
 		return nil, err
@@ -330,7 +351,13 @@ func UpdateJournal(ctx context.Context, id int, input *NewJournal) (*Journal, er
 	}
 
 	journal.Transactions = transactions
-	err = PublishToAccounting(ctx, tx, businessId, journal.JournalDate, journal.ID, AccountReferenceTypeJournal, journal, oldJournal, PubSubMessageActionUpdate)
+	// an edit an approval policy covers is submitted again
+	journal.ApprovalStatus, err = holdForApproval(ctx, tx, journal.approvalSubject())
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	err = publishApprovable(ctx, tx, businessId, journal.JournalDate, journal.ID, AccountReferenceTypeJournal, journal.ApprovalStatus, journal, oldJournal, approvalPosted(oldJournal.ApprovalStatus))
 	if err != nil {
 		tx.Rollback()
 		return nil, err
@@ -356,6 +383,10 @@ func DeleteJournal(ctx context.Context, id int) (*Journal, error) {
 
 	// db action
 	tx := db.Begin()
+	if err := withdrawApproval(ctx, tx, ApprovalDocumentTypeManualJournal, id); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
 	// delete associated transactions first
 	if err := tx.WithContext(ctx).Model(&journal).Association("Transactions").
 		Unscoped().Clear(); err != nil {
@@ -370,10 +401,13 @@ func DeleteJournal(ctx context.Context, id int) (*Journal, error) {
 		tx.Rollback()
 		return nil, err
 	}
-	err = PublishToAccounting(ctx, tx, businessId, journal.JournalDate, journal.ID, AccountReferenceTypeJournal, nil, journal, PubSubMessageActionDelete)
-	if err != nil {
-		tx.Rollback()
-		return nil, err
+	// a journal still waiting for approval never reached the books
+	if approvalPosted(journal.ApprovalStatus) {
+		err = PublishToAccounting(ctx, tx, businessId, journal.JournalDate, journal.ID, AccountReferenceTypeJournal, nil, journal, PubSubMessageActionDelete)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
 	}
 	if err := tx.Commit().Error; err != nil {
 		return nil, err
@@ -0,0 +1,625 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type LandedCostAllocationMethod string
+
+const (
+	LandedCostAllocationMethodQuantity LandedCostAllocationMethod = "QUANTITY"
+	LandedCostAllocationMethodValue    LandedCostAllocationMethod = "VALUE"
+	LandedCostAllocationMethodWeight   LandedCostAllocationMethod = "WEIGHT"
+	LandedCostAllocationMethodManual   LandedCostAllocationMethod = "MANUAL"
+)
+
+func (m LandedCostAllocationMethod) IsValid() bool {
+	switch m {
+	case LandedCostAllocationMethodQuantity, LandedCostAllocationMethodValue, LandedCostAllocationMethodWeight, LandedCostAllocationMethodManual:
+		return true
+	}
+	return false
+}
+
+// LandedCost capitalises the non-stock lines of one or more cost bills (freight, duty,
+// insurance) into the stock received on one or more receiving bills. Confirming it raises
+// the unit cost of the receiving bills' stock rows by each line's UnitCostDelta, so FIFO
+// layers and the COGS of later sales carry the landed cost, and moves TotalAmount from the
+// cost bills' accounts into inventory. Amounts are in the base currency.
+type LandedCost struct {
+	ID               int                        `gorm:"primary_key" json:"id"`
+	BusinessId       string                     `gorm:"index;not null" json:"business_id" binding:"required"`
+	LandedCostNumber string                     `gorm:"size:255;not null" json:"landed_cost_number"`
+	LandedCostDate   time.Time                  `gorm:"not null" json:"landed_cost_date" binding:"required"`
+	BranchId         int                        `gorm:"index;not null" json:"branch_id"`
+	AllocationMethod LandedCostAllocationMethod `gorm:"size:10;not null" json:"allocation_method"`
+	TotalAmount      decimal.Decimal            `gorm:"type:decimal(20,4);default:0" json:"total_amount"`
+	Notes            string                     `gorm:"size:255" json:"notes"`
+	CurrentStatus    LandedCostStatus           `gorm:"type:enum('Draft','Confirmed','Void');not null" json:"current_status" binding:"required"`
+	Charges          []LandedCostCharge         `gorm:"foreignKey:LandedCostId" json:"charges"`
+	Lines            []LandedCostLine           `gorm:"foreignKey:LandedCostId" json:"lines"`
+	CreatedAt        time.Time                  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt        time.Time                  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// LandedCostCharge is a cost bill line being capitalised, at its base amount before tax.
+type LandedCostCharge struct {
+	ID           int             `gorm:"primary_key" json:"id"`
+	LandedCostId int             `gorm:"index;not null" json:"landed_cost_id"`
+	BillId       int             `gorm:"index;not null" json:"bill_id"`
+	BillDetailId int             `gorm:"not null" json:"bill_detail_id"`
+	Name         string          `gorm:"size:100" json:"name"`
+	Amount       decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"amount"`
+	CreatedAt    time.Time       `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// LandedCostLine is a stock line of a receiving bill. Value is its base purchase value
+// and Weight its total weight; which of them (or Qty) drives the allocation depends on
+// the document's method.
+type LandedCostLine struct {
+	ID              int             `gorm:"primary_key" json:"id"`
+	LandedCostId    int             `gorm:"index;not null" json:"landed_cost_id"`
+	BillId          int             `gorm:"index;not null" json:"bill_id"`
+	BillDetailId    int             `gorm:"not null" json:"bill_detail_id"`
+	ProductId       int             `gorm:"not null" json:"product_id"`
+	ProductType     ProductType     `gorm:"type:enum('S','V');default:S;not null" json:"product_type"`
+	BatchNumber     string          `gorm:"size:100" json:"batch_number"`
+	Name            string          `gorm:"size:100" json:"name"`
+	Qty             decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"qty"`
+	Value           decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"value"`
+	Weight          decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"weight"`
+	AllocatedAmount decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"allocated_amount"`
+	UnitCostDelta   decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"unit_cost_delta"`
+	CreatedAt       time.Time       `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// NewLandedCost takes a weight (WEIGHT) or an amount (MANUAL) per receiving bill line
+// through Lines; the other methods need no lines.
+type NewLandedCost struct {
+	LandedCostNumber string                     `json:"landed_cost_number"`
+	LandedCostDate   time.Time                  `json:"landed_cost_date"`
+	AllocationMethod LandedCostAllocationMethod `json:"allocation_method"`
+	Notes            string                     `json:"notes"`
+	CurrentStatus    LandedCostStatus           `json:"current_status"`
+	CostBillIds      []int                      `json:"cost_bill_ids"`
+	ReceivingBillIds []int                      `json:"receiving_bill_ids"`
+	Lines            []NewLandedCostLine        `json:"lines"`
+}
+
+type NewLandedCostLine struct {
+	BillDetailId int             `json:"bill_detail_id"`
+	Weight       decimal.Decimal `json:"weight"`
+	Amount       decimal.Decimal `json:"amount"`
+}
+
+type LandedCostsConnection struct {
+	Edges    []*LandedCostsEdge `json:"edges"`
+	PageInfo *PageInfo          `json:"pageInfo"`
+}
+
+type LandedCostsEdge Edge[LandedCost]
+
+func (obj LandedCost) GetId() int {
+	return obj.ID
+}
+
+func (lc LandedCost) GetCursor() string {
+	return lc.CreatedAt.String()
+}
+
+// AllocateLandedCost spreads total over lines in proportion to bases, rounded to four
+// places. The last line with a base takes the rounding difference so the amounts always
+// add up to total.
+func AllocateLandedCost(total decimal.Decimal, bases []decimal.Decimal) []decimal.Decimal {
+	amounts := make([]decimal.Decimal, len(bases))
+	sum := decimal.Zero
+	last := -1
+	for i, b := range bases {
+		if b.IsPositive() {
+			sum = sum.Add(b)
+			last = i
+		}
+	}
+	if last < 0 {
+		return amounts
+	}
+	allocated := decimal.Zero
+	for i, b := range bases {
+		if !b.IsPositive() {
+			continue
+		}
+		if i == last {
+			amounts[i] = total.Sub(allocated)
+			break
+		}
+		amounts[i] = total.Mul(b).DivRound(sum, 4)
+		allocated = allocated.Add(amounts[i])
+	}
+	return amounts
+}
+
+// Allocate sets each line's AllocatedAmount and UnitCostDelta by the allocation method.
+// MANUAL lines come with their AllocatedAmount already set and must add up to TotalAmount.
+func (lc *LandedCost) Allocate() error {
+	if lc.AllocationMethod == LandedCostAllocationMethodManual {
+		sum := decimal.Zero
+		for _, line := range lc.Lines {
+			if line.AllocatedAmount.IsNegative() {
+				return errors.New("allocated amount cannot be negative")
+			}
+			sum = sum.Add(line.AllocatedAmount)
+		}
+		if !sum.Equal(lc.TotalAmount) {
+			return fmt.Errorf("allocated amounts (%s) must add up to the landed cost total (%s)", sum.String(), lc.TotalAmount.String())
+		}
+	} else {
+		bases := make([]decimal.Decimal, len(lc.Lines))
+		sum := decimal.Zero
+		for i, line := range lc.Lines {
+			switch lc.AllocationMethod {
+			case LandedCostAllocationMethodQuantity:
+				bases[i] = line.Qty
+			case LandedCostAllocationMethodValue:
+				bases[i] = line.Value
+			case LandedCostAllocationMethodWeight:
+				bases[i] = line.Weight
+			}
+			if bases[i].IsNegative() {
+				return errors.New("allocation basis cannot be negative")
+			}
+			sum = sum.Add(bases[i])
+		}
+		if !sum.IsPositive() {
+			return fmt.Errorf("receiving lines have nothing to allocate by %s", lc.AllocationMethod)
+		}
+		for i, amount := range AllocateLandedCost(lc.TotalAmount, bases) {
+			lc.Lines[i].AllocatedAmount = amount
+		}
+	}
+	for i, line := range lc.Lines {
+		lc.Lines[i].UnitCostDelta = line.AllocatedAmount.DivRound(line.Qty, 4)
+	}
+	return nil
+}
+
+// toBaseAmount converts a bill amount to the base currency.
+func (b *Bill) toBaseAmount(amount decimal.Decimal, baseCurrencyId int) decimal.Decimal {
+	if b.CurrencyId != baseCurrencyId {
+		return amount.Mul(b.ExchangeRate).Round(4)
+	}
+	return amount
+}
+
+// detailNetAmount is what a bill line debits to its account: the line amount before
+// discount, without tax.
+func (b *Bill) detailNetAmount(d BillDetail) decimal.Decimal {
+	amount := d.DetailTotalAmount.Add(d.DetailDiscountAmount)
+	if b.IsTaxInclusive != nil && *b.IsTaxInclusive {
+		amount = amount.Sub(d.DetailTaxAmount)
+	}
+	return amount
+}
+
+func (input *NewLandedCost) validate() error {
+	if !input.AllocationMethod.IsValid() {
+		return errors.New("invalid allocation method")
+	}
+	if input.CurrentStatus != LandedCostStatusDraft && input.CurrentStatus != LandedCostStatusConfirmed {
+		return errors.New("invalid landed cost status")
+	}
+	if len(input.CostBillIds) == 0 {
+		return errors.New("at least one cost bill is required")
+	}
+	if len(input.ReceivingBillIds) == 0 {
+		return errors.New("at least one receiving bill is required")
+	}
+	seen := make(map[int]bool)
+	for _, id := range append(slices.Clone(input.CostBillIds), input.ReceivingBillIds...) {
+		if seen[id] {
+			return errors.New("a bill can only be added to a landed cost once")
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// landedCostBill fetches a bill for a landed cost: it must be confirmed (or paid), in the
+// landed cost's branch and dated on or before it.
+func landedCostBill(ctx context.Context, businessId string, id int, branchId *int, date time.Time) (*Bill, error) {
+	bill, err := utils.FetchModel[Bill](ctx, businessId, id, "Details")
+	if err != nil {
+		return nil, err
+	}
+	if bill.CurrentStatus == BillStatusDraft || bill.CurrentStatus == BillStatusVoid {
+		return nil, fmt.Errorf("bill %s is not confirmed", bill.BillNumber)
+	}
+	if *branchId == 0 {
+		*branchId = bill.BranchId
+	} else if bill.BranchId != *branchId {
+		return nil, errors.New("all bills of a landed cost must be in the same branch")
+	}
+	if date.Before(bill.BillDate) {
+		return nil, fmt.Errorf("landed cost date cannot be before the date of bill %s", bill.BillNumber)
+	}
+	return bill, nil
+}
+
+// buildLandedCost collects the charges of the cost bills and the stock lines of the
+// receiving bills and allocates the charges over the lines.
+func buildLandedCost(ctx context.Context, businessId string, input *NewLandedCost) (*LandedCost, error) {
+	if err := input.validate(); err != nil {
+		return nil, err
+	}
+	business, err := GetBusiness(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lc := LandedCost{
+		BusinessId:       businessId,
+		LandedCostNumber: input.LandedCostNumber,
+		LandedCostDate:   input.LandedCostDate,
+		AllocationMethod: input.AllocationMethod,
+		Notes:            input.Notes,
+		CurrentStatus:    LandedCostStatusDraft,
+	}
+
+	for _, id := range input.CostBillIds {
+		bill, err := landedCostBill(ctx, businessId, id, &lc.BranchId, input.LandedCostDate)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range bill.Details {
+			if d.ProductId > 0 && IsRealProduct(ctx, businessId, d.ProductId, d.ProductType) {
+				continue
+			}
+			amount := bill.toBaseAmount(bill.detailNetAmount(d), business.BaseCurrencyId)
+			if amount.IsZero() {
+				continue
+			}
+			lc.Charges = append(lc.Charges, LandedCostCharge{BillId: bill.ID, BillDetailId: d.ID, Name: d.Name, Amount: amount})
+			lc.TotalAmount = lc.TotalAmount.Add(amount)
+		}
+	}
+	if !lc.TotalAmount.IsPositive() {
+		return nil, errors.New("cost bills have no charges to capitalise")
+	}
+	var used int64
+	if err := config.GetDB().WithContext(ctx).Model(&LandedCostCharge{}).
+		Joins("JOIN landed_costs ON landed_costs.id = landed_cost_charges.landed_cost_id").
+		Where("landed_costs.business_id = ? AND landed_costs.current_status <> ? AND landed_cost_charges.bill_id IN ?", businessId, LandedCostStatusVoid, input.CostBillIds).
+		Count(&used).Error; err != nil {
+		return nil, err
+	}
+	if used > 0 {
+		return nil, errors.New("a cost bill has already been allocated by another landed cost")
+	}
+
+	inputLines := make(map[int]NewLandedCostLine)
+	for _, l := range input.Lines {
+		inputLines[l.BillDetailId] = l
+	}
+	for _, id := range input.ReceivingBillIds {
+		bill, err := landedCostBill(ctx, businessId, id, &lc.BranchId, input.LandedCostDate)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range bill.Details {
+			if d.ProductId <= 0 || !d.DetailQty.IsPositive() || !IsRealProduct(ctx, businessId, d.ProductId, d.ProductType) {
+				continue
+			}
+			in := inputLines[d.ID]
+			lc.Lines = append(lc.Lines, LandedCostLine{
+				BillId:          bill.ID,
+				BillDetailId:    d.ID,
+				ProductId:       d.ProductId,
+				ProductType:     d.ProductType,
+				BatchNumber:     d.BatchNumber,
+				Name:            d.Name,
+				Qty:             d.DetailQty,
+				Value:           bill.toBaseAmount(d.DetailQty.Mul(d.DetailUnitRate), business.BaseCurrencyId),
+				Weight:          in.Weight,
+				AllocatedAmount: in.Amount,
+			})
+		}
+	}
+	if len(lc.Lines) == 0 {
+		return nil, errors.New("receiving bills have no inventory lines")
+	}
+	if err := lc.Allocate(); err != nil {
+		return nil, err
+	}
+	return &lc, nil
+}
+
+// validateConfirm checks the period lock at the landed cost's date and at every
+// receiving bill's date, whose stock it reprices, and that the receiving lines are still
+// on their bills without a value adjustment after them.
+func (lc *LandedCost) validateConfirm(ctx context.Context) error {
+	if err := ValidateTransactionLock(ctx, lc.LandedCostDate, lc.BusinessId, AccountantTransactionLock); err != nil {
+		return err
+	}
+	db := config.GetDB().WithContext(ctx)
+	billIds := make([]int, 0)
+	for _, c := range lc.Charges {
+		if !slices.Contains(billIds, c.BillId) {
+			billIds = append(billIds, c.BillId)
+		}
+	}
+	for _, line := range lc.Lines {
+		if !slices.Contains(billIds, line.BillId) {
+			billIds = append(billIds, line.BillId)
+		}
+	}
+	bills := make(map[int]Bill)
+	for _, id := range billIds {
+		var bill Bill
+		if err := db.Where("business_id = ? AND id = ?", lc.BusinessId, id).First(&bill).Error; err != nil {
+			return err
+		}
+		if bill.CurrentStatus == BillStatusDraft || bill.CurrentStatus == BillStatusVoid {
+			return fmt.Errorf("bill %s is not confirmed", bill.BillNumber)
+		}
+		bills[id] = bill
+	}
+	for _, line := range lc.Lines {
+		bill := bills[line.BillId]
+		if err := ValidateTransactionLock(ctx, bill.BillDate, lc.BusinessId, AccountantTransactionLock); err != nil {
+			return err
+		}
+		var count int64
+		if err := db.Model(&BillDetail{}).
+			Where("bill_id = ? AND id = ? AND product_id = ? AND product_type = ? AND detail_qty = ?", line.BillId, line.BillDetailId, line.ProductId, line.ProductType, line.Qty).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return fmt.Errorf("%s has changed on bill %s; recreate the landed cost", line.Name, bill.BillNumber)
+		}
+		batch := line.BatchNumber
+		if err := ValidateValueAdjustment(ctx, lc.BusinessId, bill.BillDate, line.ProductType, line.ProductId, &batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// confirmLandedCost posts a draft landed cost through the accounting outbox.
+func confirmLandedCost(ctx context.Context, tx *gorm.DB, lc *LandedCost) error {
+	if err := lc.validateConfirm(ctx); err != nil {
+		return err
+	}
+	if err := tx.WithContext(ctx).Model(lc).Update("CurrentStatus", LandedCostStatusConfirmed).Error; err != nil {
+		return err
+	}
+	lc.CurrentStatus = LandedCostStatusConfirmed
+	return PublishToAccounting(ctx, tx, lc.BusinessId, lc.LandedCostDate, lc.ID, AccountReferenceTypeLandedCost, lc, nil, PubSubMessageActionCreate)
+}
+
+func CreateLandedCost(ctx context.Context, input *NewLandedCost) (*LandedCost, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	lc, err := buildLandedCost(ctx, businessId, input)
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	tx := db.Begin()
+	if err := tx.WithContext(ctx).Create(lc).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if input.CurrentStatus == LandedCostStatusConfirmed {
+		if err := confirmLandedCost(ctx, tx, lc); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return lc, nil
+}
+
+func ConfirmLandedCost(ctx context.Context, id int) (*LandedCost, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	lc, err := utils.FetchModel[LandedCost](ctx, businessId, id, "Charges", "Lines")
+	if err != nil {
+		return nil, err
+	}
+	if lc.CurrentStatus != LandedCostStatusDraft {
+		return nil, errors.New("only draft landed costs can be confirmed")
+	}
+
+	db := config.GetDB()
+	tx := db.Begin()
+	if err := confirmLandedCost(ctx, tx, lc); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return lc, nil
+}
+
+// voidLandedCost takes a confirmed landed cost back out of the receiving bills' stock
+// and the ledger, keeping the document as Void.
+func voidLandedCost(ctx context.Context, tx *gorm.DB, lc *LandedCost) error {
+	if err := ValidateTransactionLock(ctx, lc.LandedCostDate, lc.BusinessId, AccountantTransactionLock); err != nil {
+		return err
+	}
+	if err := tx.WithContext(ctx).Model(lc).Update("CurrentStatus", LandedCostStatusVoid).Error; err != nil {
+		return err
+	}
+	oldForMsg := *lc
+	oldForMsg.CurrentStatus = LandedCostStatusConfirmed
+	lc.CurrentStatus = LandedCostStatusVoid
+	return PublishToAccounting(ctx, tx, lc.BusinessId, lc.LandedCostDate, lc.ID, AccountReferenceTypeLandedCost, nil, &oldForMsg, PubSubMessageActionDelete)
+}
+
+// billLandedCosts returns the confirmed landed costs a bill is a cost or receiving bill of.
+func billLandedCosts(ctx context.Context, tx *gorm.DB, businessId string, billId int) ([]*LandedCost, error) {
+	var landedCosts []*LandedCost
+	err := tx.WithContext(ctx).Preload("Charges").Preload("Lines").
+		Where("business_id = ? AND current_status = ?", businessId, LandedCostStatusConfirmed).
+		Where("(id IN (?) OR id IN (?))",
+			tx.Model(&LandedCostCharge{}).Select("landed_cost_id").Where("bill_id = ?", billId),
+			tx.Model(&LandedCostLine{}).Select("landed_cost_id").Where("bill_id = ?", billId)).
+		Order("id").
+		Find(&landedCosts).Error
+	return landedCosts, err
+}
+
+// ensureBillHasNoLandedCost blocks changes that would rewrite a bill's lines under a
+// confirmed landed cost.
+func ensureBillHasNoLandedCost(ctx context.Context, businessId string, bill *Bill) error {
+	landedCosts, err := billLandedCosts(ctx, config.GetDB(), businessId, bill.ID)
+	if err != nil {
+		return err
+	}
+	if len(landedCosts) > 0 {
+		return fmt.Errorf("bill is allocated by landed cost %s; void the landed cost first", landedCosts[0].LandedCostNumber)
+	}
+	return nil
+}
+
+// voidBillLandedCosts voids the confirmed landed costs of a bill that is being voided or
+// deleted. It must run before the bill's own reversal is published so the landed cost is
+// taken out of the receiving bills' stock rows before those rows are reversed.
+func voidBillLandedCosts(ctx context.Context, tx *gorm.DB, businessId string, billId int) error {
+	landedCosts, err := billLandedCosts(ctx, tx, businessId, billId)
+	if err != nil {
+		return err
+	}
+	for _, lc := range landedCosts {
+		if err := voidLandedCost(ctx, tx, lc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteLandedCost removes a landed cost, first voiding it when it was confirmed.
+func DeleteLandedCost(ctx context.Context, id int) (*LandedCost, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	lc, err := utils.FetchModel[LandedCost](ctx, businessId, id, "Charges", "Lines")
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	tx := db.Begin()
+	if lc.CurrentStatus == LandedCostStatusConfirmed {
+		if err := voidLandedCost(ctx, tx, lc); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	if err := tx.WithContext(ctx).Where("landed_cost_id = ?", lc.ID).Delete(&LandedCostCharge{}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.WithContext(ctx).Where("landed_cost_id = ?", lc.ID).Delete(&LandedCostLine{}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.WithContext(ctx).Delete(lc).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return lc, nil
+}
+
+func GetLandedCost(ctx context.Context, id int) (*LandedCost, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	return utils.FetchModel[LandedCost](ctx, businessId, id)
+}
+
+func GetLandedCostCharges(ctx context.Context, landedCostId int) ([]*LandedCostCharge, error) {
+	db := config.GetDB()
+	var charges []*LandedCostCharge
+	if err := db.WithContext(ctx).Where("landed_cost_id = ?", landedCostId).Order("id").Find(&charges).Error; err != nil {
+		return nil, err
+	}
+	return charges, nil
+}
+
+func GetLandedCostLines(ctx context.Context, landedCostId int) ([]*LandedCostLine, error) {
+	db := config.GetDB()
+	var lines []*LandedCostLine
+	if err := db.WithContext(ctx).Where("landed_cost_id = ?", landedCostId).Order("id").Find(&lines).Error; err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+func PaginateLandedCost(
+	ctx context.Context, limit *int, after *string,
+	landedCostNumber *string,
+	currentStatus *LandedCostStatus,
+	billId *int,
+) (*LandedCostsConnection, error) {
+
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	db := config.GetDB()
+	dbCtx := db.WithContext(ctx).Where("business_id = ?", businessId)
+
+	if landedCostNumber != nil && *landedCostNumber != "" {
+		dbCtx.Where("landed_cost_number LIKE ?", "%"+*landedCostNumber+"%")
+	}
+	if currentStatus != nil {
+		dbCtx.Where("current_status = ?", *currentStatus)
+	}
+	if billId != nil && *billId > 0 {
+		dbCtx.Where("(id IN (?) OR id IN (?))",
+			db.Model(&LandedCostCharge{}).Select("landed_cost_id").Where("bill_id = ?", *billId),
+			db.Model(&LandedCostLine{}).Select("landed_cost_id").Where("bill_id = ?", *billId))
+	}
+
+	edges, pageInfo, err := FetchPageCompositeCursor[LandedCost](dbCtx, *limit, after, "created_at", "<")
+	if err != nil {
+		return nil, err
+	}
+	var landedCostsConnection LandedCostsConnection
+	landedCostsConnection.PageInfo = pageInfo
+	for _, edge := range edges {
+		landedCostsEdge := LandedCostsEdge(edge)
+		landedCostsConnection.Edges = append(landedCostsConnection.Edges, &landedCostsEdge)
+	}
+
+	return &landedCostsConnection, err
+}
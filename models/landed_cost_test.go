@@ -0,0 +1,80 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/shopspring/decimal"
+)
+
+func TestAllocateLandedCost(t *testing.T) {
+	d := decimal.RequireFromString
+	got := models.AllocateLandedCost(d("100"), []decimal.Decimal{d("1"), d("0"), d("1"), d("1")})
+	want := []string{"33.3333", "0", "33.3333", "33.3334"}
+	for i := range want {
+		if !got[i].Equal(d(want[i])) {
+			t.Errorf("line %d: allocated %s, want %s", i, got[i], want[i])
+		}
+	}
+
+	for _, amount := range models.AllocateLandedCost(d("100"), []decimal.Decimal{d("0"), d("0")}) {
+		if !amount.IsZero() {
+			t.Fatalf("lines without a basis were allocated %s", amount)
+		}
+	}
+}
+
+func TestLandedCostAllocate(t *testing.T) {
+	d := decimal.RequireFromString
+	newLandedCost := func(method models.LandedCostAllocationMethod) *models.LandedCost {
+		return &models.LandedCost{
+			AllocationMethod: method,
+			TotalAmount:      d("60"),
+			Lines: []models.LandedCostLine{
+				{Name: "Widget", Qty: d("10"), Value: d("100"), Weight: d("5")},
+				{Name: "Gadget", Qty: d("20"), Value: d("500"), Weight: d("1")},
+			},
+		}
+	}
+
+	for method, want := range map[models.LandedCostAllocationMethod][2]string{
+		models.LandedCostAllocationMethodQuantity: {"20", "40"},
+		models.LandedCostAllocationMethodValue:    {"10", "50"},
+		models.LandedCostAllocationMethodWeight:   {"50", "10"},
+	} {
+		lc := newLandedCost(method)
+		if err := lc.Allocate(); err != nil {
+			t.Fatalf("%s: %v", method, err)
+		}
+		for i, line := range lc.Lines {
+			if !line.AllocatedAmount.Equal(d(want[i])) {
+				t.Errorf("%s: %s allocated %s, want %s", method, line.Name, line.AllocatedAmount, want[i])
+			}
+			if !line.UnitCostDelta.Equal(line.AllocatedAmount.DivRound(line.Qty, 4)) {
+				t.Errorf("%s: %s unit cost delta %s", method, line.Name, line.UnitCostDelta)
+			}
+		}
+	}
+
+	manual := newLandedCost(models.LandedCostAllocationMethodManual)
+	manual.Lines[0].AllocatedAmount = d("15")
+	manual.Lines[1].AllocatedAmount = d("40")
+	if err := manual.Allocate(); err == nil {
+		t.Fatal("manual amounts short of the total were accepted")
+	}
+	manual.Lines[1].AllocatedAmount = d("45")
+	if err := manual.Allocate(); err != nil {
+		t.Fatalf("manual: %v", err)
+	}
+	if !manual.Lines[1].UnitCostDelta.Equal(d("2.25")) {
+		t.Fatalf("manual unit cost delta = %s, want 2.25", manual.Lines[1].UnitCostDelta)
+	}
+
+	weightless := newLandedCost(models.LandedCostAllocationMethodWeight)
+	for i := range weightless.Lines {
+		weightless.Lines[i].Weight = decimal.Zero
+	}
+	if err := weightless.Allocate(); err == nil {
+		t.Fatal("weight allocation without weights was accepted")
+	}
+}
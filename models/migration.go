@@ -11,7 +11,7 @@ func MigrateTable() {
 
 	err := db.AutoMigrate(
 		&Account{}, &AccountCurrencyDailyBalance{}, &DailySummary{}, &AccountJournal{}, &AccountTransaction{},
-		&BankingTransaction{}, &BankingTransactionDetail{}, &Bill{}, &BillDetail{}, &Branch{}, &Business{}, &TransactionLockingRecord{},
+		&BankingTransaction{}, &BankingTransactionDetail{}, &BankStatement{}, &BankStatementLine{}, &BankStatementMatch{}, &Bill{}, &BillDetail{}, &Branch{}, &Budget{}, &BudgetDetail{}, &Business{}, &FixedAssetCategory{}, &FixedAsset{}, &FixedAssetDepreciation{}, &TransactionLockingRecord{},
 		&CreditNote{}, &CreditNoteDetail{},
 		&Comment{}, &Currency{}, &CurrencyExchange{},
 		&Customer{}, &CustomerPayment{}, &CustomerCreditInvoice{}, &CustomerCreditAdvance{}, &BillingAddress{}, &ShippingAddress{}, &ContactPerson{}, &Document{},
@@ -23,8 +23,9 @@ func MigrateTable() {
 		&Module{}, &MoneyAccount{},
 		&Product{}, &ProductGroup{}, &ProductOption{}, &ProductCategory{}, &ProductModifier{}, &ProductModifierUnit{},
 		&ProductVariant{}, &ProductUnit{}, &PurchaseOrder{}, &PurchaseOrderDetail{},
-		&Refund{}, &RecurringBill{}, &RecurringBillDetail{}, &Role{}, &RoleModule{},
-		&SalesOrder{}, &SalesOrderDetail{}, &SalesInvoice{}, &SalesInvoiceDetail{}, &Supplier{}, &SupplierPayment{},
+		&Refund{}, &RecurringBill{}, &RecurringBillDetail{}, &RecurringRun{},
+		&RecurringExpense{}, &RecurringInvoice{}, &RecurringInvoiceDetail{}, &Role{}, &RoleModule{},
+		&Estimate{}, &EstimateDetail{}, &SalesOrder{}, &SalesOrderDetail{}, &SalesInvoice{}, &SalesInvoiceDetail{}, &Supplier{}, &SupplierPayment{},
 		&SupplierPaidBill{}, &SupplierCredit{}, &SupplierCreditDetail{}, &SupplierCreditBill{}, &SupplierCreditAdvance{}, &State{},
 		&StockHistory{}, &StockSummary{}, &StockSummaryDailyBalance{},
 		&PaidInvoice{}, &PubSubMessageRecord{}, &PosCheckoutInvoicePayment{},
@@ -36,6 +37,23 @@ func MigrateTable() {
 		&InventoryMovement{}, &CogsAllocation{},
 		&ReconciliationReport{},
 		&DocumentTemplate{},
+		&EmailSetting{}, &EmailTemplate{}, &EmailMessage{},
+		&PaymentReminderRule{}, &PaymentReminder{},
+		&CreditControlSetting{},
+		&WebhookEndpoint{}, &WebhookDelivery{},
+		&ApprovalPolicy{}, &ApprovalPolicyLevel{}, &ApprovalRequest{}, &ApprovalStep{},
+		&TaxReturn{}, &TaxReturnLine{},
+		&WithholdingTax{},
+		&FxRevaluation{}, &FxRevaluationLine{},
+		&ProductUnitConversion{},
+		&PriceList{}, &PriceListItem{},
+		&ReorderPoint{},
+		&BillOfMaterials{}, &BillOfMaterialsComponent{}, &AssemblyOrder{}, &AssemblyOrderDetail{},
+		&InventoryValuationMethodRecord{},
+		&TransferOrderReceipt{}, &TransferOrderReceiptDetail{},
+		&Stocktake{}, &StocktakeLine{}, &StocktakeCount{},
+		&LandedCost{}, &LandedCostCharge{}, &LandedCostLine{},
+		&SerialNumber{}, &SerialNumberMovement{},
 		&IntegrationConnection{}, &IntegrationSyncRun{}, &IntegrationEntityMapping{}, &IntegrationSyncError{},
 	)
 	if err != nil {
@@ -45,4 +63,8 @@ func MigrateTable() {
 	if err := EnsureInventoryLedgerSchema(); err != nil {
 		log.Fatal(err)
 	}
+
+	if err := backfillRecurringBillSchedules(); err != nil {
+		log.Fatal(err)
+	}
 }
@@ -12,9 +12,11 @@ import (
 
 type ProductInterface interface {
 	GetPurchasePrice() decimal.Decimal
+	GetSalesPrice() decimal.Decimal
 	GetInventoryAccountID() int
 	GetId() int
 	GetIsBatchTracking() bool
+	GetUnitId() int
 }
 
 func GetProductOrVariant(ctx context.Context, productType string, productId int) (ProductInterface, error) {
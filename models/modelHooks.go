@@ -510,7 +510,7 @@ func (r *RecurringBill) AfterCreate(tx *gorm.DB) (err error) {
 }
 
 func (r *RecurringBill) BeforeUpdate(tx *gorm.DB) (err error) {
-	if err := SaveHistoryUpdate(tx, r.ID, r, "Updated RecurringBill"); err != nil {
+	if err := SaveHistoryUpdate(tx, r.ID, r, describeRecurringUpdate(tx, "RecurringBill")); err != nil {
 		return err
 	}
 
@@ -525,6 +525,62 @@ func (r *RecurringBill) AfterDelete(tx *gorm.DB) (err error) {
 	return nil
 }
 
+func (r *RecurringExpense) AfterCreate(tx *gorm.DB) (err error) {
+	description, err := describeTotalAmountCreated(tx.Statement.Context, "RecurringExpense", r.CurrencyId, r.Amount)
+	if err != nil {
+		return err
+	}
+	if err := SaveHistoryCreate(tx, r.ID, r, description); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *RecurringExpense) BeforeUpdate(tx *gorm.DB) (err error) {
+	if err := SaveHistoryUpdate(tx, r.ID, r, describeRecurringUpdate(tx, "RecurringExpense")); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *RecurringExpense) AfterDelete(tx *gorm.DB) (err error) {
+	if err := SaveHistoryDelete(tx, r.ID, r, "Deleted RecurringExpense"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *RecurringInvoice) AfterCreate(tx *gorm.DB) (err error) {
+	description, err := describeTotalAmountCreated(tx.Statement.Context, "RecurringInvoice", r.CurrencyId, r.InvoiceTotalAmount)
+	if err != nil {
+		return err
+	}
+	if err := SaveHistoryCreate(tx, r.ID, r, description); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *RecurringInvoice) BeforeUpdate(tx *gorm.DB) (err error) {
+	if err := SaveHistoryUpdate(tx, r.ID, r, describeRecurringUpdate(tx, "RecurringInvoice")); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *RecurringInvoice) AfterDelete(tx *gorm.DB) (err error) {
+	if err := SaveHistoryDelete(tx, r.ID, r, "Deleted RecurringInvoice"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (r *BankingTransaction) AfterCreate(tx *gorm.DB) (err error) {
 	description, err := describeTotalAmountCreated(tx.Statement.Context, "BankingTransaction", r.CurrencyId, r.Amount)
 	if err != nil {
@@ -553,6 +609,135 @@ func (r *BankingTransaction) AfterDelete(tx *gorm.DB) (err error) {
 	return nil
 }
 
+func (r *BankStatement) AfterCreate(tx *gorm.DB) (err error) {
+	if err := SaveHistoryCreate(tx, r.ID, r, "Imported BankStatement "+r.FileName); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *BankStatement) BeforeUpdate(tx *gorm.DB) (err error) {
+	description := "Updated BankStatement"
+	if fields, ok := tx.Statement.Dest.(map[string]interface{}); ok {
+		switch fields["Status"] {
+		case BankStatementStatusReconciled:
+			description = "Reconciled BankStatement"
+		case BankStatementStatusOpen:
+			description = "Reopened BankStatement"
+		}
+	}
+	if err := SaveHistoryUpdate(tx, r.ID, r, description); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *BankStatement) AfterDelete(tx *gorm.DB) (err error) {
+	if err := SaveHistoryDelete(tx, r.ID, r, "Deleted BankStatement"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (b *Budget) AfterCreate(tx *gorm.DB) (err error) {
+	if err := SaveHistoryCreate(tx, b.ID, b, "Created Budget "+b.Name); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (b *Budget) BeforeUpdate(tx *gorm.DB) (err error) {
+	if err := SaveHistoryUpdate(tx, b.ID, b, "Updated Budget"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (b *Budget) AfterDelete(tx *gorm.DB) (err error) {
+	if err := SaveHistoryDelete(tx, b.ID, b, "Deleted Budget"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *FixedAssetCategory) AfterCreate(tx *gorm.DB) (err error) {
+	if err := SaveHistoryCreate(tx, c.ID, c, "Created FixedAssetCategory "+c.Name); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *FixedAssetCategory) BeforeUpdate(tx *gorm.DB) (err error) {
+	if err := SaveHistoryUpdate(tx, c.ID, c, "Updated FixedAssetCategory"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *FixedAssetCategory) AfterDelete(tx *gorm.DB) (err error) {
+	if err := SaveHistoryDelete(tx, c.ID, c, "Deleted FixedAssetCategory"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (w *WithholdingTax) AfterCreate(tx *gorm.DB) (err error) {
+	if err := SaveHistoryCreate(tx, w.ID, w, "Created WithholdingTax "+w.Name); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (w *WithholdingTax) BeforeUpdate(tx *gorm.DB) (err error) {
+	if err := SaveHistoryUpdate(tx, w.ID, w, "Updated WithholdingTax"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (w *WithholdingTax) AfterDelete(tx *gorm.DB) (err error) {
+	if err := SaveHistoryDelete(tx, w.ID, w, "Deleted WithholdingTax"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (fa *FixedAsset) AfterCreate(tx *gorm.DB) (err error) {
+	if err := SaveHistoryCreate(tx, fa.ID, fa, "Registered FixedAsset "+fa.AssetNumber); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (fa *FixedAsset) BeforeUpdate(tx *gorm.DB) (err error) {
+	if err := SaveHistoryUpdate(tx, fa.ID, fa, "Updated FixedAsset"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (fa *FixedAsset) AfterDelete(tx *gorm.DB) (err error) {
+	if err := SaveHistoryDelete(tx, fa.ID, fa, "Deleted FixedAsset"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (p *ProductOption) AfterCreate(tx *gorm.DB) (err error) {
 	return nil
 }
@@ -1641,3 +1826,31 @@ func (o *InventoryAdjustment) AfterDelete(tx *gorm.DB) (err error) {
 // 		}
 // 	return nil
 // }
+
+func (e *Estimate) AfterCreate(tx *gorm.DB) (err error) {
+	description, err := describeTotalAmountCreated(tx.Statement.Context, "Estimate", e.CurrencyId, e.EstimateTotalAmount)
+	if err != nil {
+		return err
+	}
+	if err := SaveHistoryCreate(tx, e.ID, e, description); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (e *Estimate) BeforeUpdate(tx *gorm.DB) (err error) {
+	if err := SaveHistoryUpdate(tx, e.ID, e, "Updated Estimate"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (e *Estimate) AfterDelete(tx *gorm.DB) (err error) {
+	if err := SaveHistoryDelete(tx, e.ID, e, "Deleted Estimate"); err != nil {
+		return err
+	}
+
+	return nil
+}
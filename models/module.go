@@ -314,10 +314,14 @@ func GetModules(ctx context.Context, name *string) ([]*BaseModule, error) {
 		"BankingAccount":                   AccountantModule,
 		"BankingTransaction":               AccountantModule,
 		"Journal":                          AccountantModule,
+		"FxRevaluation":                    AccountantModule,
 		"MoneyAccount":                     AccountantModule,
 		"Refund":                           AccountantModule,
 		"TransactionLocking":               AccountantModule,
 		"TransactionLockingRecord":         AccountantModule,
+		"InventoryValuationMethod":         AccountantModule,
+		"InventoryValuationMethodRecord":   AccountantModule,
+		"TaxReturn":                        AccountantModule,
 		"TopExpense":                       DashboardModule,
 		"TotalCashFlow":                    DashboardModule,
 		"TotalIncomeExpense":               DashboardModule,
@@ -334,16 +338,27 @@ func GetModules(ctx context.Context, name *string) ([]*BaseModule, error) {
 		"ProductGroup":                     ProductsModule,
 		"InventoryAdjustment":              ProductsModule,
 		"TransferOrder":                    ProductsModule,
+		"TransferOrderReceipt":             ProductsModule,
+		"BillOfMaterials":                  ProductsModule,
+		"AssemblyOrder":                    ProductsModule,
+		"Stocktake":                        ProductsModule,
+		"SerialNumber":                     ProductsModule,
+		"SerialNumberHistory":              ProductsModule,
 		"OpeningStockGroup":                ProductsModule,
 		"ProductCategory":                  ProductsModule,
 		"ProductModifier":                  ProductsModule,
 		"AvailableStocks":                  ProductsModule,
 		"ClosingInventory":                 ProductsModule,
 		"ProductUnit":                      ProductsModule,
+		"ProductUnitConversion":            ProductsModule,
+		"PriceList":                        ProductsModule,
+		"EffectivePrice":                   ProductsModule,
+		"ReorderPoint":                     ProductsModule,
 		"ProductTransactions":              ProductsModule,
 		"Supplier":                         PurchasesModule,
 		"PurchaseOrder":                    PurchasesModule,
 		"Bill":                             PurchasesModule,
+		"LandedCost":                       PurchasesModule,
 		"SupplierPayment":                  PurchasesModule,
 		"Expense":                          PurchasesModule,
 		"SupplierCredit":                   PurchasesModule,
@@ -361,6 +376,8 @@ func GetModules(ctx context.Context, name *string) ([]*BaseModule, error) {
 		"JournalReport":                    Report_Accountant,
 		"TrialBalanceReport":               Report_Accountant,
 		"AccountJournalTransactions":       Report_Accountant,
+		"TaxSummaryReport":                 Report_Accountant,
+		"TaxSummaryDetailReport":           Report_Accountant,
 		"ProfitAndLossReport":              Report_BusinessOverview,
 		"CashFlowReport":                   Report_BusinessOverview,
 		"MovementOfEquityReport":           Report_BusinessOverview,
@@ -375,9 +392,14 @@ func GetModules(ctx context.Context, name *string) ([]*BaseModule, error) {
 		"InventoryValuationSummaryReport":  Report_Inventory,
 		"InventoryValuation":               Report_Inventory,
 		"WarehouseInventoryReport":         Report_Inventory,
+		"LowStockReport":                   Report_Inventory,
+		"InTransitTransferReport":          Report_Inventory,
+		"AbcClassification":                Report_Inventory,
 		"ProductSalesReport":               Report_Inventory,
 		"APAgingDetailReport":              Report_Payable,
 		"APAgingSummaryReport":             Report_Payable,
+		"WithholdingTaxSummaryReport":      Report_Payable,
+		"WithholdingTaxCertificate":        Report_Payable,
 		"PurchaseOrderDetailReport":        Report_Payable,
 		"PayableDetailReport":              Report_Payable,
 		"PayableSummaryReport":             Report_Payable,
@@ -405,12 +427,18 @@ func GetModules(ctx context.Context, name *string) ([]*BaseModule, error) {
 		"SalesByCustomerReport":            Report_Sales,
 		"SalesByProductReport":             Report_Sales,
 		"SalesBySalesPersonReport":         Report_Sales,
+		"Estimate":                         SalesModule,
 		"SalesOrder":                       SalesModule,
 		"SalesPerson":                      SalesModule,
 		"SalesInvoice":                     SalesModule,
 		"Customer":                         SalesModule,
+		"CustomerCreditExposure":           SalesModule,
 		"CreditNote":                       SalesModule,
 		"CustomerPayment":                  SalesModule,
+		"CustomerStatement":                SalesModule,
+		"PaymentReceipt":                   SalesModule,
+		"PaymentReminder":                  SalesModule,
+		"PaymentReminderRule":              SalesModule,
 		"CustomerApplyCredit":              SalesModule,
 		"CustomerApplyToInvoice":           SalesModule,
 		"CustomerCreditInvoice":            SalesModule,
@@ -420,6 +448,7 @@ func GetModules(ctx context.Context, name *string) ([]*BaseModule, error) {
 		"Business":                         SettingsModule,
 		"TransactionNumberSeries":          SettingsModule,
 		"Currency":                         SettingsModule,
+		"CurrencyExchange":                 SettingsModule,
 		"TaxGroup":                         SettingsModule,
 		"Tax":                              SettingsModule,
 		"PaymentMode":                      SettingsModule,
@@ -432,10 +461,21 @@ func GetModules(ctx context.Context, name *string) ([]*BaseModule, error) {
 		"OpeningBalance":                   SettingsModule,
 		"Role":                             SettingsModule,
 		"TaxSetting":                       SettingsModule,
+		"WithholdingTax":                   SettingsModule,
 		"OpeningBalanceDetails":            SettingsModule,
 		"PosInvoicePayment":                SettingsModule,
 		"RoleModule":                       SettingsModule,
 		"Document":                         SettingsModule,
+		"DocumentPdf":                      SettingsModule,
+		"EmailSetting":                     SettingsModule,
+		"CreditControlSetting":             SettingsModule,
+		"EmailTemplate":                    SettingsModule,
+		"EmailMessage":                     SettingsModule,
+		"WebhookEndpoint":                  SettingsModule,
+		"WebhookDelivery":                  SettingsModule,
+		"ApprovalPolicy":                   SettingsModule,
+		"ApprovalRequest":                  SettingsModule,
+		"ApprovalInbox":                    SettingsModule,
 	}
 
 	for _, module := range allModules {
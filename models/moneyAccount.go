@@ -73,6 +73,11 @@ func (input *NewMoneyAccount) validate(ctx context.Context, businessId string, i
 	if err := utils.ValidateResourceId[Currency](ctx, businessId, input.AccountCurrencyId); err != nil {
 		return errors.New("account currency not found")
 	}
+	branches, err := validateBranches(ctx, businessId, input.Branches)
+	if err != nil {
+		return err
+	}
+	input.Branches = branches
 	return nil
 }
 
@@ -0,0 +1,514 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+)
+
+type PaymentReminderLateFeeType string
+
+const (
+	PaymentReminderLateFeeTypeNone    PaymentReminderLateFeeType = "NONE"
+	PaymentReminderLateFeeTypeFixed   PaymentReminderLateFeeType = "FIXED"
+	PaymentReminderLateFeeTypePercent PaymentReminderLateFeeType = "PERCENT"
+)
+
+func (t PaymentReminderLateFeeType) IsValid() bool {
+	switch t {
+	case PaymentReminderLateFeeTypeNone, PaymentReminderLateFeeTypeFixed, PaymentReminderLateFeeTypePercent:
+		return true
+	}
+	return false
+}
+
+type PaymentReminderStatus string
+
+const (
+	PaymentReminderStatusPending PaymentReminderStatus = "PENDING"
+	PaymentReminderStatusSent    PaymentReminderStatus = "SENT"
+	PaymentReminderStatusSkipped PaymentReminderStatus = "SKIPPED"
+	PaymentReminderStatusFailed  PaymentReminderStatus = "FAILED"
+)
+
+// PaymentReminderRule is one dunning level of a business. DaysAfterDue is counted from the
+// invoice due date: -3 reminds three days before the due date, 30 once thirty days overdue.
+// Subject and Body are email templates over EmailTemplateData.
+type PaymentReminderRule struct {
+	ID               int                        `gorm:"primary_key" json:"id"`
+	BusinessId       string                     `gorm:"index;not null" json:"business_id" binding:"required"`
+	Name             string                     `gorm:"size:100;not null" json:"name" binding:"required"`
+	DaysAfterDue     int                        `gorm:"not null" json:"days_after_due"`
+	Subject          string                     `gorm:"size:255;not null" json:"subject"`
+	Body             string                     `gorm:"type:text;not null" json:"body"`
+	LateFeeType      PaymentReminderLateFeeType `gorm:"size:20;not null;default:'NONE'" json:"late_fee_type"`
+	LateFeeValue     decimal.Decimal            `gorm:"type:decimal(20,4);default:0" json:"late_fee_value"`
+	LateFeeAccountId int                        `gorm:"default:0" json:"late_fee_account_id"`
+	IsActive         *bool                      `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt        time.Time                  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt        time.Time                  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type NewPaymentReminderRule struct {
+	Name             string                     `json:"name" binding:"required"`
+	DaysAfterDue     int                        `json:"days_after_due"`
+	Subject          string                     `json:"subject"`
+	Body             string                     `json:"body"`
+	LateFeeType      PaymentReminderLateFeeType `json:"late_fee_type"`
+	LateFeeValue     decimal.Decimal            `json:"late_fee_value"`
+	LateFeeAccountId int                        `json:"late_fee_account_id"`
+}
+
+// PaymentReminder records that a rule fired for an invoice.
+// Unique constraint: (sales_invoice_id, rule_id) sends each level at most once per invoice.
+type PaymentReminder struct {
+	ID               int                   `gorm:"primary_key" json:"id"`
+	BusinessId       string                `gorm:"index;not null" json:"business_id"`
+	SalesInvoiceId   int                   `gorm:"not null;index:uniq_payment_reminder,unique" json:"sales_invoice_id"`
+	RuleId           int                   `gorm:"not null;index:uniq_payment_reminder,unique" json:"rule_id"`
+	RuleName         string                `gorm:"size:100" json:"rule_name"`
+	CustomerId       int                   `gorm:"index;not null" json:"customer_id"`
+	DaysOverdue      int                   `json:"days_overdue"`
+	BalanceDue       decimal.Decimal       `gorm:"type:decimal(20,4);default:0" json:"balance_due"`
+	LateFeeAmount    decimal.Decimal       `gorm:"type:decimal(20,4);default:0" json:"late_fee_amount"`
+	LateFeeInvoiceId int                   `gorm:"default:0" json:"late_fee_invoice_id"`
+	EmailMessageId   int                   `gorm:"default:0" json:"email_message_id"`
+	Status           PaymentReminderStatus `gorm:"size:20;not null" json:"status"`
+	LastError        *string               `gorm:"type:text" json:"last_error"`
+	CreatedAt        time.Time             `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt        time.Time             `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+const (
+	defaultPaymentReminderSubject = "Payment reminder: invoice {{.DocumentNumber}} from {{.BusinessName}}"
+	defaultPaymentReminderBody    = "Dear {{.CustomerName}},\n\n" +
+		"This is a reminder that invoice {{.DocumentNumber}} dated {{.DocumentDate}} was due on {{.DueDate}}.\n" +
+		"The outstanding balance is {{.BalanceDue}}.\n\n" +
+		"If you have already paid, please disregard this email.\n\n{{.BusinessName}}"
+)
+
+// a rule may fire at most a year either side of the due date
+const paymentReminderMaxDays = 365
+
+func (input *NewPaymentReminderRule) validate(ctx context.Context, businessId string, id int) error {
+	if strings.TrimSpace(input.Name) == "" {
+		return errors.New("name is required")
+	}
+	if err := utils.ValidateUnique[PaymentReminderRule](ctx, businessId, "name", input.Name, id); err != nil {
+		return err
+	}
+	if input.DaysAfterDue < -paymentReminderMaxDays || input.DaysAfterDue > paymentReminderMaxDays {
+		return fmt.Errorf("days after due must be between -%d and %d", paymentReminderMaxDays, paymentReminderMaxDays)
+	}
+	if err := utils.ValidateUnique[PaymentReminderRule](ctx, businessId, "days_after_due", input.DaysAfterDue, id); err != nil {
+		return errors.New("another reminder already fires on that day")
+	}
+	if _, err := renderEmailTemplate(input.Subject, EmailTemplateData{}); err != nil {
+		return fmt.Errorf("subject: %w", err)
+	}
+	if _, err := renderEmailTemplate(input.Body, EmailTemplateData{}); err != nil {
+		return fmt.Errorf("body: %w", err)
+	}
+
+	if input.LateFeeType == "" {
+		input.LateFeeType = PaymentReminderLateFeeTypeNone
+	}
+	if !input.LateFeeType.IsValid() {
+		return errors.New("invalid late fee type")
+	}
+	if input.LateFeeType == PaymentReminderLateFeeTypeNone {
+		return nil
+	}
+	if input.DaysAfterDue <= 0 {
+		return errors.New("late fees can only be charged on overdue invoices")
+	}
+	if !input.LateFeeValue.IsPositive() {
+		return errors.New("late fee must be greater than zero")
+	}
+	if input.LateFeeType == PaymentReminderLateFeeTypePercent && input.LateFeeValue.GreaterThan(decimal.NewFromInt(100)) {
+		return errors.New("late fee percentage must be between 0 and 100")
+	}
+	count, err := utils.ResourceCountWhere[Account](ctx, businessId, "id = ? AND main_type = 'Income'", input.LateFeeAccountId)
+	if err != nil {
+		return err
+	}
+	if count <= 0 {
+		return errors.New("late fee account must be an income account")
+	}
+	return nil
+}
+
+func (r *PaymentReminderRule) assign(input *NewPaymentReminderRule) {
+	r.Name = strings.TrimSpace(input.Name)
+	r.DaysAfterDue = input.DaysAfterDue
+	r.Subject = input.Subject
+	r.Body = input.Body
+	if strings.TrimSpace(r.Subject) == "" {
+		r.Subject = defaultPaymentReminderSubject
+	}
+	if strings.TrimSpace(r.Body) == "" {
+		r.Body = defaultPaymentReminderBody
+	}
+	r.LateFeeType = input.LateFeeType
+	r.LateFeeValue = decimal.Zero
+	r.LateFeeAccountId = 0
+	if input.LateFeeType != PaymentReminderLateFeeTypeNone {
+		r.LateFeeValue = input.LateFeeValue
+		r.LateFeeAccountId = input.LateFeeAccountId
+	}
+}
+
+// lateFee is the fee charged on the invoice's remaining balance, rounded to the currency.
+func (r PaymentReminderRule) lateFee(balance decimal.Decimal, places DecimalPlaces) decimal.Decimal {
+	switch r.LateFeeType {
+	case PaymentReminderLateFeeTypeFixed:
+		return r.LateFeeValue
+	case PaymentReminderLateFeeTypePercent:
+		return balance.Mul(r.LateFeeValue).Div(decimal.NewFromInt(100)).Round(places.Digits())
+	}
+	return decimal.Zero
+}
+
+func CreatePaymentReminderRule(ctx context.Context, input *NewPaymentReminderRule) (*PaymentReminderRule, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if err := input.validate(ctx, businessId, 0); err != nil {
+		return nil, err
+	}
+
+	rule := PaymentReminderRule{BusinessId: businessId, IsActive: utils.NewTrue()}
+	rule.assign(input)
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Create(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// UpdatePaymentReminderRule changes the rule for reminders sent afterwards.
+// Invoices that already received this level are not reminded again.
+func UpdatePaymentReminderRule(ctx context.Context, id int, input *NewPaymentReminderRule) (*PaymentReminderRule, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if err := input.validate(ctx, businessId, id); err != nil {
+		return nil, err
+	}
+
+	existing, err := utils.FetchModel[PaymentReminderRule](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+	existing.assign(input)
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Save(existing).Error; err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// DeletePaymentReminderRule removes a rule; reminders it already sent stay in the invoice history.
+func DeletePaymentReminderRule(ctx context.Context, id int) (*PaymentReminderRule, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	result, err := utils.FetchModel[PaymentReminderRule](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Delete(result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func ToggleActivePaymentReminderRule(ctx context.Context, id int, isActive bool) (*PaymentReminderRule, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	rule, err := utils.FetchModel[PaymentReminderRule](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Model(rule).Update("IsActive", isActive).Error; err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func GetPaymentReminderRule(ctx context.Context, id int) (*PaymentReminderRule, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	return utils.FetchModel[PaymentReminderRule](ctx, businessId, id)
+}
+
+func ListPaymentReminderRule(ctx context.Context) ([]*PaymentReminderRule, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	db := config.GetDB()
+	var results []*PaymentReminderRule
+	if err := db.WithContext(ctx).Where("business_id = ?", businessId).Order("days_after_due").Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// ListPaymentReminder returns the reminders sent for an invoice, oldest first.
+func ListPaymentReminder(ctx context.Context, salesInvoiceId int) ([]*PaymentReminder, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	db := config.GetDB()
+	var results []*PaymentReminder
+	if err := db.WithContext(ctx).
+		Where("business_id = ? AND sales_invoice_id = ?", businessId, salesInvoiceId).
+		Order("created_at").
+		Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// PaymentReminderWindow returns the due dates a rule applies to on the local date today:
+// invoices due on or after from (when set) and before `before`. A rule stops applying once
+// the next later rule takes over, and a before-due rule stops at the due date, so each
+// invoice gets only the latest level it qualifies for.
+func PaymentReminderWindow(today time.Time, daysAfterDue int, nextDaysAfterDue *int) (from *time.Time, before time.Time) {
+	before = today.AddDate(0, 0, 1-daysAfterDue)
+	if nextDaysAfterDue != nil {
+		f := today.AddDate(0, 0, 1-*nextDaysAfterDue)
+		from = &f
+	}
+	if daysAfterDue <= 0 && (from == nil || from.Before(today)) {
+		f := today
+		from = &f
+	}
+	return from, before
+}
+
+// GetPaymentReminderBusinessIds returns the businesses that have active reminder rules.
+func GetPaymentReminderBusinessIds(ctx context.Context) ([]string, error) {
+	db := config.GetDB()
+	var businessIds []string
+	if err := db.WithContext(ctx).Model(&PaymentReminderRule{}).
+		Where("is_active = ?", true).
+		Distinct().
+		Pluck("business_id", &businessIds).Error; err != nil {
+		return nil, err
+	}
+	return businessIds, nil
+}
+
+// SendPaymentReminders finds unpaid invoices that reached a reminder level and sends the
+// reminder, at most limit per call. Customers excluded from reminders are skipped.
+// It returns the number of reminders recorded.
+func SendPaymentReminders(ctx context.Context, now time.Time, limit int) (int, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return 0, errors.New("business id is required")
+	}
+	business, err := GetBusiness(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	db := config.GetDB()
+	var rules []*PaymentReminderRule
+	if err := db.WithContext(ctx).
+		Where("business_id = ? AND is_active = ?", businessId, true).
+		Find(&rules).Error; err != nil {
+		return 0, err
+	}
+	// latest level first, so each rule's window ends where the next one starts
+	sort.Slice(rules, func(i, j int) bool { return rules[i].DaysAfterDue > rules[j].DaysAfterDue })
+
+	today := localDate(now, business.Timezone)
+	sent := 0
+	for i, rule := range rules {
+		if sent >= limit {
+			break
+		}
+		var next *int
+		if i > 0 {
+			next = &rules[i-1].DaysAfterDue
+		}
+		from, before := PaymentReminderWindow(today, rule.DaysAfterDue, next)
+
+		dbCtx := db.WithContext(ctx).
+			Where("business_id = ? AND current_status IN ? AND remaining_balance > 0", businessId,
+				[]SalesInvoiceStatus{SalesInvoiceStatusConfirmed, SalesInvoiceStatusPartialPaid}).
+			Where("invoice_due_date < ?", before.UTC()).
+			Where("customer_id NOT IN (?)", db.Model(&Customer{}).Select("id").
+				Where("business_id = ? AND exclude_from_reminders = ?", businessId, true)).
+			Where("NOT EXISTS (?)", db.Model(&PaymentReminder{}).Select("1").
+				Where("payment_reminders.sales_invoice_id = sales_invoices.id AND payment_reminders.rule_id = ?", rule.ID))
+		if from != nil {
+			dbCtx = dbCtx.Where("invoice_due_date >= ?", from.UTC())
+		}
+		var invoices []*SalesInvoice
+		if err := dbCtx.Order("invoice_due_date").Limit(limit - sent).Find(&invoices).Error; err != nil {
+			return sent, err
+		}
+
+		for _, invoice := range invoices {
+			created, err := sendPaymentReminder(ctx, business, rule, invoice, today)
+			if err != nil {
+				return sent, err
+			}
+			if created {
+				sent++
+			}
+		}
+	}
+	return sent, nil
+}
+
+// sendPaymentReminder claims the (invoice, rule) pair and then charges the late fee and
+// queues the email. Reminders are at most once: a failure is recorded on the reminder and
+// in the invoice history rather than retried. Returns false when another instance claimed it.
+func sendPaymentReminder(ctx context.Context, business *Business, rule *PaymentReminderRule, invoice *SalesInvoice, today time.Time) (bool, error) {
+	db := config.GetDB()
+	reminder := PaymentReminder{
+		BusinessId:     invoice.BusinessId,
+		SalesInvoiceId: invoice.ID,
+		RuleId:         rule.ID,
+		RuleName:       rule.Name,
+		CustomerId:     invoice.CustomerId,
+		DaysOverdue:    int(today.Sub(localDate(*invoice.InvoiceDueDate, business.Timezone)).Hours() / 24),
+		BalanceDue:     invoice.RemainingBalance,
+		Status:         PaymentReminderStatusPending,
+	}
+	if err := db.WithContext(ctx).Create(&reminder).Error; err != nil {
+		if isDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	status, description, sendErr := deliverPaymentReminder(ctx, business, rule, invoice, &reminder)
+	updates := map[string]interface{}{
+		"status":              status,
+		"late_fee_amount":     reminder.LateFeeAmount,
+		"late_fee_invoice_id": reminder.LateFeeInvoiceId,
+		"email_message_id":    reminder.EmailMessageId,
+	}
+	if sendErr != nil {
+		msg := sendErr.Error()
+		updates["last_error"] = &msg
+	}
+	if err := db.WithContext(ctx).Model(&PaymentReminder{}).Where("id = ?", reminder.ID).Updates(updates).Error; err != nil {
+		return true, err
+	}
+	return true, createHistory(db.WithContext(ctx), "REMINDER", invoice.ID, "sales_invoices", nil, nil, description)
+}
+
+func deliverPaymentReminder(ctx context.Context, business *Business, rule *PaymentReminderRule, invoice *SalesInvoice, reminder *PaymentReminder) (PaymentReminderStatus, string, error) {
+	label := fmt.Sprintf("Payment reminder %q", rule.Name)
+	_, customer, currency, err := emailParties(ctx, invoice.BusinessId, invoice.CustomerId, invoice.CurrencyId)
+	if err != nil {
+		return PaymentReminderStatusFailed, fmt.Sprintf("%s failed: %v", label, err), err
+	}
+
+	// late fee invoices are reminded like any other but never charged a fee themselves
+	var feeNote string
+	if fee := rule.lateFee(invoice.RemainingBalance, currency.DecimalPlaces); fee.IsPositive() && invoice.PaymentReminderId == 0 {
+		feeInvoice, err := createLateFeeInvoice(ctx, rule, invoice, reminder.ID, fee)
+		if err != nil {
+			return PaymentReminderStatusFailed, fmt.Sprintf("%s failed: late fee: %v", label, err), err
+		}
+		reminder.LateFeeAmount = fee
+		reminder.LateFeeInvoiceId = feeInvoice.ID
+		feeNote = fmt.Sprintf("; late fee %s charged on %s", FormatAmount(fee, currency.Symbol, currency.DecimalPlaces), feeInvoice.InvoiceNumber)
+	}
+
+	if strings.TrimSpace(customer.Email) == "" {
+		err := errors.New("customer has no email address")
+		return PaymentReminderStatusSkipped, fmt.Sprintf("%s skipped: %v%s", label, err, feeNote), err
+	}
+	data := EmailTemplateData{
+		BusinessName:   business.Name,
+		CustomerName:   customer.Name,
+		DocumentNumber: invoice.InvoiceNumber,
+		DocumentDate:   formatEmailDate(invoice.InvoiceDate, business.Timezone),
+		DueDate:        formatEmailDate(*invoice.InvoiceDueDate, business.Timezone),
+		Amount:         FormatAmount(invoice.InvoiceTotalAmount, currency.Symbol, currency.DecimalPlaces),
+		BalanceDue:     FormatAmount(invoice.RemainingBalance, currency.Symbol, currency.DecimalPlaces),
+		DaysOverdue:    reminder.DaysOverdue,
+		LateFee:        FormatAmount(reminder.LateFeeAmount, currency.Symbol, currency.DecimalPlaces),
+	}
+	msg, err := queueEmail(ctx, invoice.BusinessId, DocumentTypeInvoice, invoice.ID, customer, data,
+		&NewDocumentEmail{Subject: &rule.Subject, Body: &rule.Body}, nil, nil)
+	if err != nil {
+		return PaymentReminderStatusFailed, fmt.Sprintf("%s failed: %v%s", label, err, feeNote), err
+	}
+	reminder.EmailMessageId = msg.ID
+	return PaymentReminderStatusSent, fmt.Sprintf("%s sent to %s%s", label, strings.ReplaceAll(msg.ToAddresses, ",", ", "), feeNote), nil
+}
+
+// createLateFeeInvoice charges the fee as a separate confirmed invoice, due on receipt,
+// so the overdue invoice itself is left as the customer received it.
+func createLateFeeInvoice(ctx context.Context, rule *PaymentReminderRule, invoice *SalesInvoice, reminderId int, fee decimal.Decimal) (*SalesInvoice, error) {
+	invoiceDate := time.Now().UTC()
+	exchangeRate, err := GetExchangeRateAsOf(ctx, invoice.BusinessId, invoice.CurrencyId, invoiceDate)
+	if err != nil {
+		return nil, err
+	}
+	return CreateSalesInvoice(ctx, &NewSalesInvoice{
+		CustomerId:          invoice.CustomerId,
+		BranchId:            invoice.BranchId,
+		ReferenceNumber:     invoice.InvoiceNumber,
+		InvoiceDate:         invoiceDate,
+		InvoicePaymentTerms: PaymentTermsDueOnReceipt,
+		InvoiceSubject:      "Late fee for invoice " + invoice.InvoiceNumber,
+		CurrencyId:          invoice.CurrencyId,
+		ExchangeRate:        exchangeRate,
+		WarehouseId:         invoice.WarehouseId,
+		IsTaxInclusive:      utils.NewFalse(),
+		CurrentStatus:       SalesInvoiceStatusConfirmed,
+		PaymentReminderId:   reminderId,
+		Details: []NewSalesInvoiceDetail{{
+			ProductType:     ProductTypeSingle,
+			Name:            "Late fee",
+			Description:     fmt.Sprintf("%s: invoice %s", rule.Name, invoice.InvoiceNumber),
+			DetailQty:       decimal.NewFromInt(1),
+			DetailUnitRate:  fee,
+			DetailAccountId: rule.LateFeeAccountId,
+		}},
+	})
+}
+
+// localDate returns midnight of t's calendar date in the timezone.
+func localDate(t time.Time, timezone string) time.Time {
+	if timezone != "" {
+		t = utils.ConvertToLocalTime(t, timezone)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
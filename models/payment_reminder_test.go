@@ -0,0 +1,62 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/models"
+)
+
+func TestPaymentReminderWindow(t *testing.T) {
+	today := time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)
+	// levels of a business: 3 days before due, 7 and 30 days overdue
+	levels := []int{-3, 7, 30}
+
+	// applies returns the level an invoice daysOverdue days past due is reminded at, or nil
+	applies := func(daysOverdue int) *int {
+		due := today.AddDate(0, 0, -daysOverdue)
+		var found *int
+		for i, level := range levels {
+			var next *int
+			if i+1 < len(levels) {
+				next = &levels[i+1]
+			}
+			from, before := models.PaymentReminderWindow(today, level, next)
+			if !due.Before(before) || (from != nil && due.Before(*from)) {
+				continue
+			}
+			if found != nil {
+				t.Fatalf("invoice %d days overdue matched levels %d and %d", daysOverdue, *found, level)
+			}
+			l := level
+			found = &l
+		}
+		return found
+	}
+
+	cases := []struct {
+		daysOverdue int
+		level       *int
+	}{
+		{-4, nil},
+		{-3, &levels[0]},
+		{0, &levels[0]},
+		{1, nil},
+		{6, nil},
+		{7, &levels[1]},
+		{29, &levels[1]},
+		{30, &levels[2]},
+		{400, &levels[2]},
+	}
+	for _, c := range cases {
+		got := applies(c.daysOverdue)
+		switch {
+		case c.level == nil && got != nil:
+			t.Errorf("%d days overdue: got level %d, want none", c.daysOverdue, *got)
+		case c.level != nil && got == nil:
+			t.Errorf("%d days overdue: got no level, want %d", c.daysOverdue, *c.level)
+		case c.level != nil && *got != *c.level:
+			t.Errorf("%d days overdue: got level %d, want %d", c.daysOverdue, *got, *c.level)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package models_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/mmdatafocus/books_backend/workflow"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// processPaymentJournal runs the payment's create outbox message through its workflow and
+// returns the journal it posted.
+func processPaymentJournal(t *testing.T, ctx context.Context, businessID string, referenceType models.AccountReferenceType, referenceID int,
+	process func(tx *gorm.DB, logger *logrus.Logger, msg config.PubSubMessage) error) models.AccountJournal {
+	t.Helper()
+	db := config.GetDB()
+
+	var outbox models.PubSubMessageRecord
+	if err := db.WithContext(ctx).
+		Where("business_id = ? AND reference_type = ? AND reference_id = ? AND action = ?",
+			businessID, referenceType, referenceID, models.PubSubMessageActionCreate).
+		Order("id DESC").
+		First(&outbox).Error; err != nil {
+		t.Fatalf("expected outbox record for %s %d: %v", referenceType, referenceID, err)
+	}
+	wtx := db.Begin()
+	if err := process(wtx, logrus.New(), models.ConvertToPubSubMessage(outbox)); err != nil {
+		_ = wtx.Rollback().Error
+		t.Fatalf("process %s workflow: %v", referenceType, err)
+	}
+	if err := wtx.Commit().Error; err != nil {
+		t.Fatalf("%s workflow commit: %v", referenceType, err)
+	}
+
+	var journal models.AccountJournal
+	if err := db.WithContext(ctx).Preload("AccountTransactions").
+		Where("business_id = ? AND reference_type = ? AND reference_id = ? AND is_reversal = ?", businessID, referenceType, referenceID, false).
+		First(&journal).Error; err != nil {
+		t.Fatalf("fetch %s journal: %v", referenceType, err)
+	}
+	return journal
+}
+
+// checkWithheldJournal checks that the journal balances and that the withheld tax sits on
+// withheldAccountId as a debit (debit true) or a credit.
+func checkWithheldJournal(t *testing.T, journal models.AccountJournal, withheldAccountId int, withheld decimal.Decimal, debit bool) {
+	t.Helper()
+	totalDebit, totalCredit := decimal.Zero, decimal.Zero
+	found := false
+	for _, line := range journal.AccountTransactions {
+		totalDebit = totalDebit.Add(line.BaseDebit)
+		totalCredit = totalCredit.Add(line.BaseCredit)
+		if line.AccountId != withheldAccountId {
+			continue
+		}
+		found = true
+		wantDebit, wantCredit := decimal.Zero, withheld
+		if debit {
+			wantDebit, wantCredit = withheld, decimal.Zero
+		}
+		if !line.BaseDebit.Equal(wantDebit) || !line.BaseCredit.Equal(wantCredit) {
+			t.Fatalf("withheld tax line = debit %s credit %s, want debit %s credit %s", line.BaseDebit, line.BaseCredit, wantDebit, wantCredit)
+		}
+	}
+	if !found {
+		t.Fatalf("journal has no line on the withholding tax account %d", withheldAccountId)
+	}
+	if !totalDebit.Equal(totalCredit) {
+		t.Fatalf("journal does not balance: debit %s, credit %s", totalDebit, totalCredit)
+	}
+}
+
+func createTestWithholdingTax(t *testing.T, ctx context.Context, accs map[string]int) *models.WithholdingTax {
+	t.Helper()
+	withholdingTax, err := models.CreateWithholdingTax(ctx, &models.NewWithholdingTax{
+		Name:                "Income tax 5%",
+		Rate:                decimal.NewFromInt(5),
+		PayableAccountId:    accs[models.AccountCodeTaxPayable],
+		ReceivableAccountId: accs[models.AccountCodeAdvanceTax],
+	})
+	if err != nil {
+		t.Fatalf("CreateWithholdingTax: %v", err)
+	}
+	return withholdingTax
+}
+
+// Tax withheld from a supplier payment is credited to the payable account, so the bill is
+// settled in full while only the rest leaves the withdraw account.
+func TestSupplierPaymentWorkflow_WithholdingTax(t *testing.T) {
+	ctx, biz, accs := setupRecurringBusiness(t)
+	businessID := biz.ID.String()
+	db := config.GetDB()
+	withholdingTax := createTestWithholdingTax(t, ctx, accs)
+
+	supplier, err := models.CreateSupplier(ctx, &models.NewSupplier{
+		Name:                 "Consultant",
+		Email:                "consultant@withholding.test",
+		CurrencyId:           biz.BaseCurrencyId,
+		ExchangeRate:         decimal.NewFromInt(1),
+		SupplierPaymentTerms: models.PaymentTermsDueOnReceipt,
+	})
+	if err != nil {
+		t.Fatalf("CreateSupplier: %v", err)
+	}
+	var primary models.Warehouse
+	if err := db.WithContext(ctx).Where("business_id = ? AND name = ?", businessID, "Primary Warehouse").First(&primary).Error; err != nil {
+		t.Fatalf("fetch primary warehouse: %v", err)
+	}
+	bill, err := models.CreateBill(ctx, &models.NewBill{
+		SupplierId:       supplier.ID,
+		BranchId:         biz.PrimaryBranchId,
+		BillDate:         time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC),
+		BillPaymentTerms: models.PaymentTermsDueOnReceipt,
+		CurrencyId:       biz.BaseCurrencyId,
+		ExchangeRate:     decimal.NewFromInt(1),
+		WarehouseId:      primary.ID,
+		IsTaxInclusive:   utils.NewFalse(),
+		CurrentStatus:    models.BillStatusConfirmed,
+		Details: []models.NewBillDetail{{
+			Name:            "Consulting",
+			DetailAccountId: accs[models.AccountCodeOtherExpenses],
+			DetailQty:       decimal.NewFromInt(1),
+			DetailUnitRate:  decimal.NewFromInt(100000),
+		}},
+	})
+	if err != nil {
+		t.Fatalf("CreateBill: %v", err)
+	}
+
+	payment, err := models.CreateSupplierPayment(ctx, &models.NewSupplierPayment{
+		SupplierId:        supplier.ID,
+		BranchId:          biz.PrimaryBranchId,
+		CurrencyId:        biz.BaseCurrencyId,
+		ExchangeRate:      decimal.NewFromInt(1),
+		Amount:            decimal.NewFromInt(100000),
+		BankCharges:       decimal.NewFromInt(1000),
+		WithholdingTaxId:  withholdingTax.ID,
+		PaymentDate:       time.Date(2026, 1, 20, 12, 0, 0, 0, time.UTC),
+		WithdrawAccountId: accs[models.AccountCodePettyCash],
+		PaidBills:         []models.NewPaidBill{{BillId: bill.ID, PaidAmount: decimal.NewFromInt(100000)}},
+	})
+	if err != nil {
+		t.Fatalf("CreateSupplierPayment: %v", err)
+	}
+	if !payment.WithholdingTaxAmount.Equal(decimal.NewFromInt(5000)) {
+		t.Fatalf("withheld = %s, want 5000 from the rate", payment.WithholdingTaxAmount)
+	}
+
+	journal := processPaymentJournal(t, ctx, businessID, models.AccountReferenceTypeSupplierPayment, payment.ID, workflow.ProcessSupplierPaymentWorkflow)
+	checkWithheldJournal(t, journal, withholdingTax.PayableAccountId, decimal.NewFromInt(5000), false)
+
+	for _, line := range journal.AccountTransactions {
+		if line.AccountId == accs[models.AccountCodePettyCash] && !line.BaseCredit.Equal(decimal.NewFromInt(96000)) {
+			t.Fatalf("withdraw account credit = %s, want 96000 (95000 paid + 1000 charges)", line.BaseCredit)
+		}
+	}
+}
+
+// Tax a customer withholds is debited to the receivable account, so the invoice is settled
+// in full while only the rest is deposited.
+func TestCustomerPaymentWorkflow_WithholdingTax(t *testing.T) {
+	ctx, biz, accs := setupRecurringBusiness(t)
+	businessID := biz.ID.String()
+	db := config.GetDB()
+	withholdingTax := createTestWithholdingTax(t, ctx, accs)
+
+	customer, err := models.CreateCustomer(ctx, &models.NewCustomer{Name: "Client"})
+	if err != nil {
+		t.Fatalf("CreateCustomer: %v", err)
+	}
+	var primary models.Warehouse
+	if err := db.WithContext(ctx).Where("business_id = ? AND name = ?", businessID, "Primary Warehouse").First(&primary).Error; err != nil {
+		t.Fatalf("fetch primary warehouse: %v", err)
+	}
+	invoice, err := models.CreateSalesInvoice(ctx, &models.NewSalesInvoice{
+		CustomerId:          customer.ID,
+		BranchId:            biz.PrimaryBranchId,
+		InvoiceDate:         time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC),
+		InvoicePaymentTerms: models.PaymentTermsDueOnReceipt,
+		CurrencyId:          biz.BaseCurrencyId,
+		ExchangeRate:        decimal.NewFromInt(1),
+		WarehouseId:         primary.ID,
+		IsTaxInclusive:      utils.NewFalse(),
+		CurrentStatus:       models.SalesInvoiceStatusConfirmed,
+		Details: []models.NewSalesInvoiceDetail{{
+			Name:            "Consulting",
+			DetailAccountId: accs[models.AccountCodeSales],
+			DetailQty:       decimal.NewFromInt(1),
+			DetailUnitRate:  decimal.NewFromInt(100000),
+		}},
+	})
+	if err != nil {
+		t.Fatalf("CreateSalesInvoice: %v", err)
+	}
+
+	payment, err := models.CreateCustomerPayment(ctx, &models.NewCustomerPayment{
+		CustomerId:           customer.ID,
+		BranchId:             biz.PrimaryBranchId,
+		CurrencyId:           biz.BaseCurrencyId,
+		ExchangeRate:         decimal.NewFromInt(1),
+		Amount:               decimal.NewFromInt(100000),
+		BankCharges:          decimal.NewFromInt(1000),
+		WithholdingTaxId:     withholdingTax.ID,
+		WithholdingTaxAmount: decimal.NewFromInt(4000),
+		PaymentDate:          time.Date(2026, 1, 20, 12, 0, 0, 0, time.UTC),
+		DepositAccountId:     accs[models.AccountCodePettyCash],
+		PaidInvoices:         []models.NewPaidInvoice{{InvoiceId: invoice.ID, PaidAmount: decimal.NewFromInt(100000)}},
+	})
+	if err != nil {
+		t.Fatalf("CreateCustomerPayment: %v", err)
+	}
+
+	journal := processPaymentJournal(t, ctx, businessID, models.AccountReferenceTypeCustomerPayment, payment.ID, workflow.ProcessCustomerPaymentWorkflow)
+	checkWithheldJournal(t, journal, withholdingTax.ReceivableAccountId, decimal.NewFromInt(4000), true)
+
+	for _, line := range journal.AccountTransactions {
+		if line.AccountId == accs[models.AccountCodePettyCash] && !line.BaseDebit.Equal(decimal.NewFromInt(95000)) {
+			t.Fatalf("deposit account debit = %s, want 95000 (96000 received less 1000 charges)", line.BaseDebit)
+		}
+	}
+}
@@ -0,0 +1,585 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type PriceListType string
+
+const (
+	PriceListTypeSales    PriceListType = "SALES"
+	PriceListTypePurchase PriceListType = "PURCHASE"
+)
+
+func (t PriceListType) IsValid() bool {
+	switch t {
+	case PriceListTypeSales, PriceListTypePurchase:
+		return true
+	}
+	return false
+}
+
+type PriceListPricingMethod string
+
+const (
+	PriceListPricingMethodFixed      PriceListPricingMethod = "FIXED"
+	PriceListPricingMethodPercentage PriceListPricingMethod = "PERCENTAGE"
+)
+
+func (m PriceListPricingMethod) IsValid() bool {
+	switch m {
+	case PriceListPricingMethodFixed, PriceListPricingMethodPercentage:
+		return true
+	}
+	return false
+}
+
+// PriceList prices products for the customers (SALES) or suppliers (PURCHASE) it is
+// assigned to. FIXED lists quote item prices in CurrencyId; PERCENTAGE lists mark the
+// product's sales or purchase price up (positive) or down (negative) by Percentage, or by
+// an item's own percentage. Items with a MinQty are quantity breaks: the highest MinQty
+// not above the line quantity, in base units, applies.
+type PriceList struct {
+	ID            int                    `gorm:"primary_key" json:"id"`
+	BusinessId    string                 `gorm:"index;not null" json:"business_id" binding:"required"`
+	Name          string                 `gorm:"size:100;not null" json:"name" binding:"required"`
+	Description   string                 `gorm:"size:255" json:"description"`
+	PriceListType PriceListType          `gorm:"size:10;not null" json:"price_list_type"`
+	PricingMethod PriceListPricingMethod `gorm:"size:10;not null" json:"pricing_method"`
+	Percentage    decimal.Decimal        `gorm:"type:decimal(10,4);default:0" json:"percentage"`
+	CurrencyId    int                    `gorm:"not null" json:"currency_id"`
+	ValidFrom     *time.Time             `json:"valid_from"`
+	ValidTo       *time.Time             `json:"valid_to"`
+	IsActive      *bool                  `gorm:"not null;default:true" json:"is_active"`
+	Items         []PriceListItem        `gorm:"foreignKey:PriceListId" json:"items"`
+	CreatedAt     time.Time              `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time              `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type PriceListItem struct {
+	ID          int             `gorm:"primary_key" json:"id"`
+	PriceListId int             `gorm:"index;not null" json:"price_list_id"`
+	ProductId   int             `gorm:"not null" json:"product_id"`
+	ProductType ProductType     `gorm:"type:enum('S','V');default:S;not null" json:"product_type"`
+	MinQty      decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"min_qty"`
+	Price       decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"price"`
+	Percentage  decimal.Decimal `gorm:"type:decimal(10,4);default:0" json:"percentage"`
+}
+
+type NewPriceList struct {
+	Name          string                 `json:"name" binding:"required"`
+	Description   string                 `json:"description"`
+	PriceListType PriceListType          `json:"price_list_type" binding:"required"`
+	PricingMethod PriceListPricingMethod `json:"pricing_method" binding:"required"`
+	Percentage    decimal.Decimal        `json:"percentage"`
+	CurrencyId    int                    `json:"currency_id"`
+	ValidFrom     *MyDateString          `json:"valid_from"`
+	ValidTo       *MyDateString          `json:"valid_to"`
+	Items         []*NewPriceListItem    `json:"items"`
+}
+
+type NewPriceListItem struct {
+	ProductId   int             `json:"product_id"`
+	ProductType ProductType     `json:"product_type"`
+	MinQty      decimal.Decimal `json:"min_qty"`
+	Price       decimal.Decimal `json:"price"`
+	Percentage  decimal.Decimal `json:"percentage"`
+}
+
+// EffectivePrice is the price of a product for a contact. PriceListId is 0 when no
+// price list applies and Price is the product's own price.
+type EffectivePrice struct {
+	Price       decimal.Decimal `json:"price"`
+	CurrencyId  int             `json:"currency_id"`
+	PriceListId int             `json:"price_list_id"`
+}
+
+var hundred = decimal.NewFromInt(100)
+
+// PriceFromList returns the list's price for qty base units of a product whose own price
+// is standardPrice, and false when the list does not price the product.
+func PriceFromList(list *PriceList, productType ProductType, productId int, qty decimal.Decimal, standardPrice decimal.Decimal) (decimal.Decimal, bool) {
+	var match *PriceListItem
+	for i := range list.Items {
+		item := &list.Items[i]
+		if item.ProductId != productId || item.ProductType != productType || item.MinQty.GreaterThan(qty) {
+			continue
+		}
+		if match == nil || item.MinQty.GreaterThan(match.MinQty) {
+			match = item
+		}
+	}
+
+	if list.PricingMethod == PriceListPricingMethodFixed {
+		if match == nil {
+			return decimal.Zero, false
+		}
+		return match.Price, true
+	}
+	percentage := list.Percentage
+	if match != nil {
+		percentage = match.Percentage
+	}
+	return standardPrice.Mul(hundred.Add(percentage)).DivRound(hundred, 4), true
+}
+
+// isValidOn reports whether the list can price a document dated date.
+func (list *PriceList) isValidOn(date time.Time) bool {
+	if list.IsActive != nil && !*list.IsActive {
+		return false
+	}
+	if list.ValidFrom != nil && date.Before(*list.ValidFrom) {
+		return false
+	}
+	if list.ValidTo != nil && date.After(*list.ValidTo) {
+		return false
+	}
+	return true
+}
+
+func (input *NewPriceList) validate(ctx context.Context, businessId string, id int) error {
+	if strings.TrimSpace(input.Name) == "" {
+		return errors.New("name is required")
+	}
+	if err := utils.ValidateUnique[PriceList](ctx, businessId, "name", input.Name, id); err != nil {
+		return err
+	}
+	if !input.PriceListType.IsValid() {
+		return errors.New("invalid price list type")
+	}
+	if !input.PricingMethod.IsValid() {
+		return errors.New("invalid pricing method")
+	}
+	business, err := GetBusinessById(ctx, businessId)
+	if err != nil {
+		return err
+	}
+	if input.CurrencyId == 0 {
+		input.CurrencyId = business.BaseCurrencyId
+	}
+	if err := utils.ValidateResourceId[Currency](ctx, businessId, input.CurrencyId); err != nil {
+		return errors.New("currency not found")
+	}
+	// product prices are in base currency, so are the prices marked up from them
+	if input.PricingMethod == PriceListPricingMethodPercentage && input.CurrencyId != business.BaseCurrencyId {
+		return errors.New("percentage price lists must be in the base currency")
+	}
+	if input.Percentage.LessThanOrEqual(hundred.Neg()) {
+		return errors.New("markdown must be less than 100%")
+	}
+	if input.ValidFrom != nil && input.ValidTo != nil && time.Time(*input.ValidTo).Before(time.Time(*input.ValidFrom)) {
+		return errors.New("valid to date must be after valid from date")
+	}
+
+	type breakKey struct {
+		productType ProductType
+		productId   int
+		minQty      string
+	}
+	seen := make(map[breakKey]bool)
+	for _, item := range input.Items {
+		if item.ProductType != ProductTypeSingle && item.ProductType != ProductTypeVariant {
+			return errors.New("price list items must be single products or variants")
+		}
+		if _, err := GetProductOrVariant(ctx, string(item.ProductType), item.ProductId); err != nil {
+			return errors.New("product not found")
+		}
+		if item.MinQty.IsNegative() || item.Price.IsNegative() {
+			return errors.New("price list quantities and prices cannot be negative")
+		}
+		if item.Percentage.LessThanOrEqual(hundred.Neg()) {
+			return errors.New("markdown must be less than 100%")
+		}
+		key := breakKey{item.ProductType, item.ProductId, item.MinQty.String()}
+		if seen[key] {
+			return errors.New("duplicate quantity break for a product")
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+func (list *PriceList) assign(ctx context.Context, businessId string, input *NewPriceList) error {
+	business, err := GetBusinessById(ctx, businessId)
+	if err != nil {
+		return err
+	}
+	list.Name = strings.TrimSpace(input.Name)
+	list.Description = input.Description
+	list.PriceListType = input.PriceListType
+	list.PricingMethod = input.PricingMethod
+	list.Percentage = input.Percentage
+	list.CurrencyId = input.CurrencyId
+	list.ValidFrom = nil
+	list.ValidTo = nil
+	if input.ValidFrom != nil {
+		validFrom := *input.ValidFrom
+		if err := validFrom.StartOfDayUTCTime(business.Timezone); err != nil {
+			return err
+		}
+		list.ValidFrom = (*time.Time)(&validFrom)
+	}
+	if input.ValidTo != nil {
+		validTo := *input.ValidTo
+		if err := validTo.EndOfDayUTCTime(business.Timezone); err != nil {
+			return err
+		}
+		list.ValidTo = (*time.Time)(&validTo)
+	}
+	list.Items = make([]PriceListItem, len(input.Items))
+	for i, item := range input.Items {
+		list.Items[i] = PriceListItem{
+			ProductId:   item.ProductId,
+			ProductType: item.ProductType,
+			MinQty:      item.MinQty,
+			Price:       item.Price,
+			Percentage:  item.Percentage,
+		}
+	}
+	return nil
+}
+
+func CreatePriceList(ctx context.Context, input *NewPriceList) (*PriceList, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if err := input.validate(ctx, businessId, 0); err != nil {
+		return nil, err
+	}
+
+	list := PriceList{BusinessId: businessId, IsActive: utils.NewTrue()}
+	if err := list.assign(ctx, businessId, input); err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Create(&list).Error; err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// UpdatePriceList prices documents saved afterwards; saved documents keep their rates.
+func UpdatePriceList(ctx context.Context, id int, input *NewPriceList) (*PriceList, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if err := input.validate(ctx, businessId, id); err != nil {
+		return nil, err
+	}
+
+	existing, err := utils.FetchModel[PriceList](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+	if existing.PriceListType != input.PriceListType {
+		if err := validatePriceListUnassigned(ctx, businessId, id); err != nil {
+			return nil, err
+		}
+	}
+	if err := existing.assign(ctx, businessId, input); err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("price_list_id = ?", id).Delete(&PriceListItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Save(existing).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+func validatePriceListUnassigned(ctx context.Context, businessId string, id int) error {
+	count, err := utils.ResourceCountWhere[Customer](ctx, businessId, "price_list_id = ?", id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("used by customer")
+	}
+	count, err = utils.ResourceCountWhere[Supplier](ctx, businessId, "price_list_id = ?", id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("used by supplier")
+	}
+	return nil
+}
+
+func DeletePriceList(ctx context.Context, id int) (*PriceList, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	result, err := utils.FetchModel[PriceList](ctx, businessId, id, "Items")
+	if err != nil {
+		return nil, err
+	}
+	if err := validatePriceListUnassigned(ctx, businessId, id); err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("price_list_id = ?", id).Delete(&PriceListItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(result).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func ToggleActivePriceList(ctx context.Context, id int, isActive bool) (*PriceList, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	list, err := utils.FetchModel[PriceList](ctx, businessId, id, "Items")
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Model(list).Update("IsActive", isActive).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func GetPriceList(ctx context.Context, id int) (*PriceList, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	return utils.FetchModel[PriceList](ctx, businessId, id, "Items")
+}
+
+func ListPriceList(ctx context.Context, priceListType *PriceListType, name *string) ([]*PriceList, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	db := config.GetDB()
+	dbCtx := db.WithContext(ctx).Preload("Items").Where("business_id = ?", businessId)
+	if priceListType != nil {
+		dbCtx = dbCtx.Where("price_list_type = ?", *priceListType)
+	}
+	if name != nil && *name != "" {
+		dbCtx = dbCtx.Where("name LIKE ?", "%"+*name+"%")
+	}
+	var results []*PriceList
+	if err := dbCtx.Order("name").Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// validatePriceListId checks a price list picked for a customer or supplier.
+func validatePriceListId(ctx context.Context, businessId string, id int, priceListType PriceListType) error {
+	if id == 0 {
+		return nil
+	}
+	count, err := utils.ResourceCountWhere[PriceList](ctx, businessId, "id = ? AND price_list_type = ?", id, priceListType)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("price list not found")
+	}
+	return nil
+}
+
+// documentPriceList returns the list that prices a document's lines: the one picked on the
+// document, or else the contact's when it is in the document's currency and valid on its
+// date. It returns nil when no list applies.
+func documentPriceList(ctx context.Context, businessId string, priceListType PriceListType, priceListId *int, contactId int, currencyId int, date time.Time) (*PriceList, error) {
+	explicit := *priceListId > 0
+	if !explicit {
+		var err error
+		*priceListId, err = contactPriceListId(ctx, businessId, priceListType, contactId)
+		if err != nil {
+			return nil, err
+		}
+		if *priceListId == 0 {
+			return nil, nil
+		}
+	}
+
+	var list PriceList
+	db := config.GetDB()
+	err := db.WithContext(ctx).Preload("Items").
+		Where("business_id = ? AND id = ? AND price_list_type = ?", businessId, *priceListId, priceListType).
+		Limit(1).Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	if list.ID == 0 {
+		return nil, errors.New("price list not found")
+	}
+	if list.CurrencyId != currencyId || !list.isValidOn(date) {
+		if explicit {
+			return nil, errors.New("the price list does not apply to the document's currency or date")
+		}
+		*priceListId = 0
+		return nil, nil
+	}
+	return &list, nil
+}
+
+func contactPriceListId(ctx context.Context, businessId string, priceListType PriceListType, contactId int) (int, error) {
+	var ids []int
+	db := config.GetDB()
+	dbCtx := db.WithContext(ctx)
+	if priceListType == PriceListTypeSales {
+		dbCtx = dbCtx.Model(&Customer{})
+	} else {
+		dbCtx = dbCtx.Model(&Supplier{})
+	}
+	err := dbCtx.Where("business_id = ? AND id = ?", businessId, contactId).Pluck("price_list_id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	return ids[0], nil
+}
+
+// standardUnitPrice is the product's own price of one unitId, from the unit conversion's
+// price when it has one.
+func standardUnitPrice(ctx context.Context, businessId string, priceListType PriceListType, productType ProductType, productId int, unitId int) (decimal.Decimal, decimal.Decimal, error) {
+	product, err := GetProductOrVariant(ctx, string(productType), productId)
+	if err != nil {
+		return decimal.Zero, decimal.Zero, err
+	}
+	basePrice := product.GetSalesPrice()
+	if priceListType == PriceListTypePurchase {
+		basePrice = product.GetPurchasePrice()
+	}
+	factor, err := ResolveUnitFactor(ctx, businessId, productType, productId, unitId)
+	if err != nil {
+		return decimal.Zero, decimal.Zero, err
+	}
+	if factor.Equal(decimal.NewFromInt(1)) {
+		return basePrice, factor, nil
+	}
+
+	var conversion ProductUnitConversion
+	db := config.GetDB()
+	err = db.WithContext(ctx).
+		Where("business_id = ? AND product_type = ? AND product_id = ? AND unit_id = ?", businessId, productType, productId, unitId).
+		Limit(1).Find(&conversion).Error
+	if err != nil {
+		return decimal.Zero, decimal.Zero, err
+	}
+	unitPrice := conversion.SalesPrice
+	if priceListType == PriceListTypePurchase {
+		unitPrice = conversion.PurchasePrice
+	}
+	if unitPrice.IsZero() {
+		unitPrice = basePrice.Mul(factor)
+	}
+	return unitPrice, factor, nil
+}
+
+// unitPrice prices qty of unitId from the list; false when the list does not price the product.
+func (list *PriceList) unitPrice(ctx context.Context, businessId string, productType ProductType, productId int, unitId int, qty decimal.Decimal) (decimal.Decimal, bool, error) {
+	standardPrice, factor, err := standardUnitPrice(ctx, businessId, list.PriceListType, productType, productId, unitId)
+	if err != nil {
+		return decimal.Zero, false, err
+	}
+	// list prices are per base unit
+	price, ok := PriceFromList(list, productType, productId, ToBaseUnitQty(qty, factor), ToBaseUnitRate(standardPrice, factor))
+	if !ok {
+		return decimal.Zero, false, nil
+	}
+	return price.Mul(factor).Round(4), true, nil
+}
+
+// fillRate prices a line that asks for the price list rate; a rate of zero entered by the
+// caller is kept. Without a price on the list the entered rate stays.
+func (list *PriceList) fillRate(ctx context.Context, businessId string, productType ProductType, productId int, unitId int, qty decimal.Decimal, usePriceListRate *bool, rate *decimal.Decimal) error {
+	if list == nil || usePriceListRate == nil || !*usePriceListRate || productId <= 0 || (productType != ProductTypeSingle && productType != ProductTypeVariant) {
+		return nil
+	}
+	price, ok, err := list.unitPrice(ctx, businessId, productType, productId, unitId, qty)
+	if err != nil || !ok {
+		return err
+	}
+	*rate = price
+	return nil
+}
+
+// GetEffectivePrice returns what a customer (SALES) or supplier (PURCHASE) pays for qty of
+// a product in unitId on date: the price of their price list when one applies in the
+// currency, otherwise the product's own price.
+func GetEffectivePrice(ctx context.Context, priceListType PriceListType, contactId int, productType ProductType, productId int, unitId *int, qty decimal.Decimal, date MyDateString, currencyId *int) (*EffectivePrice, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if !priceListType.IsValid() {
+		return nil, errors.New("invalid price list type")
+	}
+	business, err := GetBusinessById(ctx, businessId)
+	if err != nil {
+		return nil, err
+	}
+	unit := utils.DereferencePtr(unitId, 0)
+	result := EffectivePrice{CurrencyId: utils.DereferencePtr(currencyId, business.BaseCurrencyId)}
+
+	if err := date.EndOfDayUTCTime(business.Timezone); err != nil {
+		return nil, err
+	}
+	asOf := time.Time(date)
+	priceListId := 0
+	list, err := documentPriceList(ctx, businessId, priceListType, &priceListId, contactId, result.CurrencyId, asOf)
+	if err != nil {
+		return nil, err
+	}
+	if list != nil {
+		price, ok, err := list.unitPrice(ctx, businessId, productType, productId, unit, qty)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result.Price = price
+			result.PriceListId = list.ID
+			return &result, nil
+		}
+	}
+
+	standardPrice, _, err := standardUnitPrice(ctx, businessId, priceListType, productType, productId, unit)
+	if err != nil {
+		return nil, err
+	}
+	if result.CurrencyId != business.BaseCurrencyId {
+		rate, err := GetExchangeRateAsOf(ctx, businessId, result.CurrencyId, asOf)
+		if err != nil || !rate.IsPositive() {
+			return nil, errors.New("no exchange rate is recorded for the currency")
+		}
+		standardPrice = standardPrice.DivRound(rate, 4)
+	}
+	result.Price = standardPrice
+	return &result, nil
+}
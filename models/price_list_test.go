@@ -0,0 +1,66 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/shopspring/decimal"
+)
+
+func TestPriceFromList(t *testing.T) {
+	d := decimal.RequireFromString
+	standard := d("10")
+
+	fixed := &models.PriceList{
+		PricingMethod: models.PriceListPricingMethodFixed,
+		Items: []models.PriceListItem{
+			{ProductId: 1, ProductType: models.ProductTypeSingle, MinQty: d("0"), Price: d("9.5")},
+			{ProductId: 1, ProductType: models.ProductTypeSingle, MinQty: d("100"), Price: d("8")},
+			{ProductId: 1, ProductType: models.ProductTypeSingle, MinQty: d("10"), Price: d("9")},
+			{ProductId: 1, ProductType: models.ProductTypeVariant, MinQty: d("0"), Price: d("1")},
+		},
+	}
+	for _, tc := range []struct {
+		qty  string
+		want string
+	}{
+		{"1", "9.5"},
+		{"10", "9"},
+		{"99", "9"},
+		{"100", "8"},
+		{"500", "8"},
+	} {
+		price, ok := models.PriceFromList(fixed, models.ProductTypeSingle, 1, d(tc.qty), standard)
+		if !ok || !price.Equal(d(tc.want)) {
+			t.Errorf("fixed qty %s: price = %s, %v, want %s", tc.qty, price, ok, tc.want)
+		}
+	}
+	// products missing from a fixed list keep their own price
+	if _, ok := models.PriceFromList(fixed, models.ProductTypeSingle, 2, d("1"), standard); ok {
+		t.Error("fixed list priced a product it does not list")
+	}
+
+	percentage := &models.PriceList{
+		PricingMethod: models.PriceListPricingMethodPercentage,
+		Percentage:    d("-10"),
+		Items: []models.PriceListItem{
+			{ProductId: 1, ProductType: models.ProductTypeSingle, MinQty: d("50"), Percentage: d("-25")},
+			{ProductId: 3, ProductType: models.ProductTypeSingle, MinQty: d("0"), Percentage: d("15")},
+		},
+	}
+	for _, tc := range []struct {
+		productId int
+		qty       string
+		want      string
+	}{
+		{1, "1", "9"},    // below the break the list markdown applies
+		{1, "50", "7.5"}, // quantity break
+		{2, "1", "9"},    // unlisted products take the list markdown
+		{3, "1", "11.5"}, // item markup
+	} {
+		price, ok := models.PriceFromList(percentage, models.ProductTypeSingle, tc.productId, d(tc.qty), standard)
+		if !ok || !price.Equal(d(tc.want)) {
+			t.Errorf("percentage product %d qty %s: price = %s, %v, want %s", tc.productId, tc.qty, price, ok, tc.want)
+		}
+	}
+}
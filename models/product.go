@@ -42,6 +42,8 @@ type Product struct {
 	InventoryAccountId  int               `json:"inventory_account_id"`
 	IsActive            *bool             `gorm:"not null;default:true" json:"is_active"`
 	IsBatchTracking     *bool             `gorm:"not null;default:false" json:"is_batch_traking"`
+	IsSerialTracking    *bool             `gorm:"not null;default:false" json:"is_serial_tracking"`
+	WarrantyMonths      int               `gorm:"not null;default:0" json:"warranty_months"`
 	ExternalSystemId    string            `gorm:"index" json:"external_system_id"`
 	// Stocks              []Stock           `gorm:"foreignkey:ProductId" json:"stocks"`
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
@@ -94,6 +96,8 @@ type NewProduct struct {
 	InventoryAccountId  int                      `json:"inventory_account_id"`
 	OpeningStocks       []NewOpeningStock        `json:"opening_stocks"`
 	IsBatchTracking     *bool                    `json:"is_batch_traking"`
+	IsSerialTracking    *bool                    `json:"is_serial_tracking"`
+	WarrantyMonths      int                      `json:"warranty_months"`
 }
 
 type NewOpeningStock struct {
@@ -142,6 +146,10 @@ func (p *Product) GetPurchasePrice() decimal.Decimal {
 	return p.PurchasePrice
 }
 
+func (p *Product) GetSalesPrice() decimal.Decimal {
+	return p.SalesPrice
+}
+
 func (p *Product) GetInventoryAccountID() int {
 	return p.InventoryAccountId
 }
@@ -150,6 +158,10 @@ func (p *Product) GetIsBatchTracking() bool {
 	return *p.IsBatchTracking
 }
 
+func (p *Product) GetUnitId() int {
+	return p.UnitId
+}
+
 // returns ids of associated modifiers
 func (p Product) ModifierIds(ctx context.Context) (ids []int, err error) {
 	db := config.GetDB()
@@ -278,6 +290,14 @@ func (input *NewProduct) validate(ctx context.Context, businessId string, id int
 		}
 	}
 
+	// serial tracked stock has to come in on documents that carry the serials
+	if input.IsSerialTracking != nil && *input.IsSerialTracking && len(input.OpeningStocks) > 0 {
+		return errors.New("serial tracked products cannot have opening stock, receive them on a bill or inventory adjustment")
+	}
+	if input.WarrantyMonths < 0 {
+		return errors.New("warranty months cannot be negative")
+	}
+
 	// exists warehouse
 	if len(input.OpeningStocks) > 0 {
 		var warehouseIds []int
@@ -373,6 +393,8 @@ func CreateProduct(ctx context.Context, input *NewProduct) (*Product, error) {
 		InventoryAccountId:  input.InventoryAccountId,
 		IsActive:            utils.NewTrue(),
 		IsBatchTracking:     input.IsBatchTracking,
+		IsSerialTracking:    input.IsSerialTracking,
+		WarrantyMonths:      input.WarrantyMonths,
 		// asssociation
 		Modifiers: modifiers,
 		Images:    images,
@@ -503,6 +525,10 @@ func UpdateProduct(ctx context.Context, id int, input *NewProduct) (*Product, er
 			return nil, errors.New("cannot disable inventory tracking as stock(s) exist")
 		}
 	}
+	isSerialTracking, err := serialTrackingUpdate(ctx, businessId, id, ProductTypeSingle, product.IsSerialTracking, input.IsSerialTracking)
+	if err != nil {
+		return nil, err
+	}
 	hasValidInventory := input.InventoryAccountId > 0 && len(input.OpeningStocks) > 0
 	if hasValidInventory {
 		if err := db.WithContext(ctx).Model(&StockSummary{}).
@@ -577,6 +603,8 @@ func UpdateProduct(ctx context.Context, id int, input *NewProduct) (*Product, er
 		"PurchaseTaxType":     input.PurchaseTaxType,
 		"InventoryAccountId":  input.InventoryAccountId,
 		"IsBatchTracking":     input.IsBatchTracking,
+		"IsSerialTracking":    isSerialTracking,
+		"WarrantyMonths":      input.WarrantyMonths,
 	}).Error
 	if err != nil {
 		tx.Rollback()
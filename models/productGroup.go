@@ -398,6 +398,8 @@ func mapProductVariantsInput(ctx context.Context, businessId string, productGrou
 			PurchaseTaxId:       variant.PurchaseTaxId,
 			PurchaseTaxType:     variant.PurchaseTaxType,
 			InventoryAccountId:  variant.InventoryAccountId,
+			IsSerialTracking:    variant.IsSerialTracking,
+			WarrantyMonths:      variant.WarrantyMonths,
 		})
 		// names = append(names, variant.Name)
 		// barcodes = append(barcodes, variant.Barcode)
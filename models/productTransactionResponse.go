@@ -254,6 +254,46 @@ WITH Transactions AS (
 		dt.product_id = @productId
 			AND dt.product_type = @productType
 	{{- end }}
+	{{- if .MultipleTransactionType }} UNION {{- end }}
+
+	{{- if .AO }}
+	SELECT
+		a.order_number transaction_number,
+		a.order_date transaction_date,
+		a.id transaction_id,
+		'assembly_orders' transaction_type,
+		a.current_status status,
+		0 customer_id,
+		0 supplier_id,
+		0 currency_id,
+		CASE WHEN a.order_type = 'DISASSEMBLY' THEN -a.quantity ELSE a.quantity END qty,
+		0 price,
+		0 total
+	FROM
+		assembly_orders a
+	WHERE
+		a.product_id = @productId
+			AND a.product_type = @productType
+	UNION ALL
+	SELECT
+		a.order_number transaction_number,
+		a.order_date transaction_date,
+		a.id transaction_id,
+		'assembly_orders' transaction_type,
+		a.current_status status,
+		0 customer_id,
+		0 supplier_id,
+		0 currency_id,
+		CASE WHEN a.order_type = 'DISASSEMBLY' THEN ad.qty ELSE -ad.qty END qty,
+		0 price,
+		0 total
+	FROM
+		assembly_order_details ad
+			LEFT JOIN assembly_orders a ON a.id = ad.assembly_order_id
+	WHERE
+		ad.product_id = @productId
+			AND ad.product_type = @productType
+	{{- end }}
 )
 SELECT
 {{- if or .SO .SI .CN }}
@@ -285,6 +325,7 @@ ORDER BY Transactions.transaction_date
 		"SC":                      transactionType == nil || *transactionType == "SC",
 		"IA":                      transactionType == nil || *transactionType == "IA",
 		"TO":                      transactionType == nil || *transactionType == "TO",
+		"AO":                      transactionType == nil || *transactionType == "AO",
 		"MultipleTransactionType": transactionType == nil,
 	})
 	if err != nil {
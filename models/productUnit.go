@@ -129,6 +129,13 @@ func DeleteProductUnit(ctx context.Context, id int) (*ProductUnit, error) {
 	if count > 0 {
 		return nil, errors.New("used by product variant")
 	}
+	count, err = utils.ResourceCountWhere[ProductUnitConversion](ctx, businessId, "unit_id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("used by product unit conversion")
+	}
 
 	db := config.GetDB()
 	// db action
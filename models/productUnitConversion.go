@@ -0,0 +1,256 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+)
+
+// ProductUnitConversion is an alternative unit of a product or variant: Factor base units
+// make one unit, e.g. a carton of 24 pieces. Prices and barcode are optional and only
+// apply when the unit is selected.
+type ProductUnitConversion struct {
+	ID            int             `gorm:"primary_key" json:"id"`
+	BusinessId    string          `gorm:"index;not null" json:"business_id"`
+	ProductId     int             `gorm:"index;not null" json:"product_id"`
+	ProductType   ProductType     `gorm:"type:enum('S','V');default:S;not null" json:"product_type"`
+	UnitId        int             `gorm:"not null" json:"product_unit_id"`
+	Factor        decimal.Decimal `gorm:"type:decimal(20,4);not null" json:"factor"`
+	SalesPrice    decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"sales_price"`
+	PurchasePrice decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"purchase_price"`
+	Barcode       string          `gorm:"index;size:100" json:"barcode"`
+	CreatedAt     time.Time       `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type NewProductUnitConversion struct {
+	UnitId        int             `json:"product_unit_id"`
+	Factor        decimal.Decimal `json:"factor"`
+	SalesPrice    decimal.Decimal `json:"sales_price"`
+	PurchasePrice decimal.Decimal `json:"purchase_price"`
+	Barcode       string          `json:"barcode"`
+}
+
+// LineUnit is the unit a document line was entered in. The line's own quantity and rate
+// are always kept in the product's base unit, which is what stock summaries, stock
+// histories and FIFO costing work in; UnitQty and UnitRate keep the figures as entered.
+type LineUnit struct {
+	UnitId     int             `gorm:"default:0" json:"unit_id"`
+	UnitFactor decimal.Decimal `gorm:"type:decimal(20,4);default:1" json:"unit_factor"`
+	UnitQty    decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"unit_qty"`
+	UnitRate   decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"unit_rate"`
+}
+
+// ToBaseUnitQty converts a quantity in a unit of factor base units into base units.
+func ToBaseUnitQty(qty decimal.Decimal, factor decimal.Decimal) decimal.Decimal {
+	if !factor.IsPositive() {
+		return qty
+	}
+	return qty.Mul(factor).Round(4)
+}
+
+// ToBaseUnitRate converts a price per unit of factor base units into a price per base unit.
+// The rate columns of lines that take a unit, and the stock history's unit value, keep all
+// eight decimals. Document lines convert through ToBaseUnitLine, which keeps their amount.
+func ToBaseUnitRate(rate decimal.Decimal, factor decimal.Decimal) decimal.Decimal {
+	if !factor.IsPositive() || factor.Equal(decimal.NewFromInt(1)) {
+		return rate
+	}
+	return rate.DivRound(factor, 8)
+}
+
+// ToBaseUnitLine converts a line's quantity and rate together. The base quantity keeps four
+// decimals like every qty column, so with a factor such as 0.3333 it is rounded and
+// ToBaseUnitRate alone would drift from the amount entered. The rate is instead the entered
+// amount spread over the rounded base quantity, so base qty x rate is still that amount.
+func ToBaseUnitLine(qty decimal.Decimal, rate decimal.Decimal, factor decimal.Decimal) (decimal.Decimal, decimal.Decimal) {
+	baseQty := ToBaseUnitQty(qty, factor)
+	if baseQty.IsZero() || !factor.IsPositive() || factor.Equal(decimal.NewFromInt(1)) {
+		return baseQty, ToBaseUnitRate(rate, factor)
+	}
+	return baseQty, qty.Mul(rate).DivRound(baseQty, 8)
+}
+
+// toBaseUnit records qty and rate as entered in the line's unit and converts them into the
+// base unit of the product. rate may be nil for lines without a price.
+func (u *LineUnit) toBaseUnit(ctx context.Context, businessId string, productType ProductType, productId int, qty *decimal.Decimal, rate *decimal.Decimal) error {
+	factor, err := ResolveUnitFactor(ctx, businessId, productType, productId, u.UnitId)
+	if err != nil {
+		return err
+	}
+	u.UnitFactor = factor
+	u.UnitQty = *qty
+	if rate == nil {
+		*qty = ToBaseUnitQty(*qty, factor)
+		return nil
+	}
+	u.UnitRate = *rate
+	*qty, *rate = ToBaseUnitLine(*qty, *rate, factor)
+	return nil
+}
+
+// ResolveUnitFactor returns how many base units of the product make one unitId. The base
+// unit itself, and lines without a unit, have a factor of 1.
+func ResolveUnitFactor(ctx context.Context, businessId string, productType ProductType, productId int, unitId int) (decimal.Decimal, error) {
+	one := decimal.NewFromInt(1)
+	if unitId <= 0 {
+		return one, nil
+	}
+	if productId <= 0 || (productType != ProductTypeSingle && productType != ProductTypeVariant) {
+		return one, errors.New("units of measure only apply to single products and variants")
+	}
+	product, err := GetProductOrVariant(ctx, string(productType), productId)
+	if err != nil {
+		return one, err
+	}
+	if product.GetUnitId() == unitId {
+		return one, nil
+	}
+
+	var conversion ProductUnitConversion
+	db := config.GetDB()
+	err = db.WithContext(ctx).
+		Where("business_id = ? AND product_type = ? AND product_id = ? AND unit_id = ?", businessId, productType, productId, unitId).
+		Limit(1).Find(&conversion).Error
+	if err != nil {
+		return one, err
+	}
+	if conversion.ID == 0 {
+		return one, errors.New("the unit is not set up for the product")
+	}
+	return conversion.Factor, nil
+}
+
+func (input NewProductUnitConversion) validate(ctx context.Context, businessId string, baseUnitId int) error {
+	if err := utils.ValidateResourceId[ProductUnit](ctx, businessId, input.UnitId); err != nil {
+		return errors.New("product unit not found")
+	}
+	if input.UnitId == baseUnitId {
+		return errors.New("the base unit cannot be added as an alternative unit")
+	}
+	if !input.Factor.IsPositive() {
+		return errors.New("unit factor must be greater than zero")
+	}
+	if input.SalesPrice.IsNegative() || input.PurchasePrice.IsNegative() {
+		return errors.New("unit prices cannot be negative")
+	}
+	return nil
+}
+
+// SetProductUnitConversions replaces the alternative units of a product or variant.
+func SetProductUnitConversions(ctx context.Context, productType ProductType, productId int, input []*NewProductUnitConversion) ([]*ProductUnitConversion, error) {
+
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if productType != ProductTypeSingle && productType != ProductTypeVariant {
+		return nil, errors.New("units of measure only apply to single products and variants")
+	}
+	product, err := GetProductOrVariant(ctx, string(productType), productId)
+	if err != nil {
+		return nil, err
+	}
+	if product.GetUnitId() <= 0 {
+		return nil, errors.New("set the base unit of the product first")
+	}
+
+	db := config.GetDB()
+	seen := make(map[int]bool)
+	conversions := make([]*ProductUnitConversion, 0, len(input))
+	for _, item := range input {
+		if err := item.validate(ctx, businessId, product.GetUnitId()); err != nil {
+			return nil, err
+		}
+		if seen[item.UnitId] {
+			return nil, errors.New("duplicate unit in the conversion group")
+		}
+		seen[item.UnitId] = true
+
+		barcode := strings.TrimSpace(item.Barcode)
+		if barcode != "" {
+			var count int64
+			err := db.WithContext(ctx).Model(&ProductUnitConversion{}).
+				Where("business_id = ? AND barcode = ? AND NOT (product_type = ? AND product_id = ?)", businessId, barcode, productType, productId).
+				Count(&count).Error
+			if err != nil {
+				return nil, err
+			}
+			if count > 0 {
+				return nil, fmt.Errorf("barcode %s is already used by another unit", barcode)
+			}
+		}
+		conversions = append(conversions, &ProductUnitConversion{
+			BusinessId:    businessId,
+			ProductId:     productId,
+			ProductType:   productType,
+			UnitId:        item.UnitId,
+			Factor:        item.Factor,
+			SalesPrice:    item.SalesPrice,
+			PurchasePrice: item.PurchasePrice,
+			Barcode:       barcode,
+		})
+	}
+
+	tx := db.Begin()
+	err = tx.WithContext(ctx).
+		Where("business_id = ? AND product_type = ? AND product_id = ?", businessId, productType, productId).
+		Delete(&ProductUnitConversion{}).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if len(conversions) > 0 {
+		if err := tx.WithContext(ctx).Create(&conversions).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return conversions, nil
+}
+
+func GetProductUnitConversions(ctx context.Context, productType ProductType, productId int) ([]*ProductUnitConversion, error) {
+
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	db := config.GetDB()
+	var results []*ProductUnitConversion
+	err := db.WithContext(ctx).
+		Where("business_id = ? AND product_type = ? AND product_id = ?", businessId, productType, productId).
+		Order("factor").Find(&results).Error
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetProductUnitConversionByBarcode finds the product and unit a scanned unit barcode belongs to.
+func GetProductUnitConversionByBarcode(ctx context.Context, barcode string) (*ProductUnitConversion, error) {
+
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	db := config.GetDB()
+	var result ProductUnitConversion
+	err := db.WithContext(ctx).
+		Where("business_id = ? AND barcode = ?", businessId, strings.TrimSpace(barcode)).
+		First(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
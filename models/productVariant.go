@@ -29,6 +29,8 @@ type ProductVariant struct {
 	PurchaseTaxType     *TaxType        `gorm:"type:enum('I', 'G'); default:null" json:"purchase_tax_type"`
 	InventoryAccountId  int             `json:"inventory_account_id"`
 	IsBatchTracking     *bool           `gorm:"not null;default:false" json:"is_batch_traking"`
+	IsSerialTracking    *bool           `gorm:"not null;default:false" json:"is_serial_tracking"`
+	WarrantyMonths      int             `gorm:"not null;default:0" json:"warranty_months"`
 	IsActive            *bool           `gorm:"not null;default:true" json:"is_active"`
 	ExternalSystemId    string          `gorm:"index" json:"external_system_id"`
 	CreatedAt           time.Time       `gorm:"autoCreateTime" json:"created_at"`
@@ -53,6 +55,8 @@ type NewProductVariant struct {
 	PurchaseTaxType     *TaxType        `json:"purchase_tax_type"`
 	InventoryAccountId  int             `json:"inventory_account_id"`
 	IsBatchTracking     *bool           `json:"is_batch_traking"`
+	IsSerialTracking    *bool           `json:"is_serial_tracking"`
+	WarrantyMonths      int             `json:"warranty_months"`
 }
 
 type AllProductVariant struct {
@@ -89,6 +93,10 @@ func (pv *ProductVariant) GetPurchasePrice() decimal.Decimal {
 	return pv.PurchasePrice
 }
 
+func (pv *ProductVariant) GetSalesPrice() decimal.Decimal {
+	return pv.SalesPrice
+}
+
 func (pv *ProductVariant) GetInventoryAccountID() int {
 	return pv.InventoryAccountId
 }
@@ -97,6 +105,10 @@ func (pv *ProductVariant) GetIsBatchTracking() bool {
 	return *pv.IsBatchTracking
 }
 
+func (pv *ProductVariant) GetUnitId() int {
+	return pv.UnitId
+}
+
 // check if transactions exist when deleted
 func (v ProductVariant) validateTransactions(ctx context.Context) error {
 	var count int64
@@ -196,6 +208,9 @@ func (input *NewProductVariant) validate(ctx context.Context, businessId string,
 	if err := utils.ValidateResourceId[ProductGroup](ctx, businessId, input.ProductGroupId); err != nil {
 		return errors.New("product group not found")
 	}
+	if input.WarrantyMonths < 0 {
+		return errors.New("warranty months cannot be negative")
+	}
 	// name
 	if err := utils.ValidateUnique[ProductVariant](ctx, businessId, "name", input.Name, id); err != nil {
 		return err
@@ -263,6 +278,8 @@ func CreateProductVariant(ctx context.Context, input *NewProductVariant) (*Produ
 		PurchaseTaxType:     input.PurchaseTaxType,
 		InventoryAccountId:  input.InventoryAccountId,
 		IsBatchTracking:     input.IsBatchTracking,
+		IsSerialTracking:    input.IsSerialTracking,
+		WarrantyMonths:      input.WarrantyMonths,
 	}
 
 	db := config.GetDB()
@@ -290,6 +307,10 @@ func UpdateProductVariant(ctx context.Context, id int, input *NewProductVariant)
 	if err := input.validate(ctx, businessId, id); err != nil {
 		return nil, err
 	}
+	isSerialTracking, err := serialTrackingUpdate(ctx, businessId, id, ProductTypeVariant, productVariant.IsSerialTracking, input.IsSerialTracking)
+	if err != nil {
+		return nil, err
+	}
 
 	db := config.GetDB()
 	// db action
@@ -310,6 +331,8 @@ func UpdateProductVariant(ctx context.Context, id int, input *NewProductVariant)
 		"PurchaseTaxType":     input.PurchaseTaxType,
 		"InventoryAccountId":  input.InventoryAccountId,
 		"IsBatchTracking":     input.IsBatchTracking,
+		"IsSerialTracking":    isSerialTracking,
+		"WarrantyMonths":      input.WarrantyMonths,
 	}).Error
 	if err != nil {
 		return nil, err
@@ -0,0 +1,111 @@
+package models_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/shopspring/decimal"
+)
+
+func TestToBaseUnit(t *testing.T) {
+	d := decimal.RequireFromString
+	carton := d("24")
+
+	// 2.5 cartons of 24 at 400 a carton
+	qty := models.ToBaseUnitQty(d("2.5"), carton)
+	rate := models.ToBaseUnitRate(d("400"), carton)
+	if !qty.Equal(d("60")) {
+		t.Errorf("qty = %s, want 60", qty)
+	}
+	if !rate.Equal(d("16.66666667")) {
+		t.Errorf("rate = %s, want 16.66666667", rate)
+	}
+	if amount := qty.Mul(rate).Round(4); !amount.Equal(d("1000")) {
+		t.Errorf("amount = %s, want 1000", amount)
+	}
+
+	// the base unit and lines without a factor are left as entered
+	for _, factor := range []decimal.Decimal{decimal.NewFromInt(1), decimal.Zero} {
+		if q := models.ToBaseUnitQty(d("3"), factor); !q.Equal(d("3")) {
+			t.Errorf("factor %s: qty = %s, want 3", factor, q)
+		}
+		if r := models.ToBaseUnitRate(d("7.5"), factor); !r.Equal(d("7.5")) {
+			t.Errorf("factor %s: rate = %s, want 7.5", factor, r)
+		}
+	}
+
+	// a fractional factor, e.g. selling by the gram with a kilogram base unit
+	if q := models.ToBaseUnitQty(d("250"), d("0.001")); !q.Equal(d("0.25")) {
+		t.Errorf("qty = %s, want 0.25", q)
+	}
+}
+
+// A line entered in a unit with a fractional factor converts to a base qty and rate whose
+// product is still the amount entered, although the base qty is rounded to four decimals.
+func TestToBaseUnitLine_KeepsEnteredAmount(t *testing.T) {
+	d := decimal.RequireFromString
+	third := d("0.3333") // a factor of 1/3 as the four-decimal factor column keeps it
+
+	for _, line := range []struct{ qty, rate string }{
+		{"2.5", "100"},
+		{"7", "12.34"},
+		{"1.25", "999.99"},
+		{"0.0003", "45"},
+		{"120", "0.75"},
+	} {
+		qty, rate := d(line.qty), d(line.rate)
+		amount := qty.Mul(rate).Round(4)
+		baseQty, baseRate := models.ToBaseUnitLine(qty, rate, third)
+		if !baseQty.Equal(models.ToBaseUnitQty(qty, third)) {
+			t.Errorf("%s @ %s: base qty = %s, want %s", line.qty, line.rate, baseQty, models.ToBaseUnitQty(qty, third))
+		}
+		if got := baseQty.Mul(baseRate).Round(4); !got.Equal(amount) {
+			t.Errorf("%s @ %s: base qty %s x base rate %s = %s, want %s", line.qty, line.rate, baseQty, baseRate, got, amount)
+		}
+	}
+
+	// 2.5 x 0.3333 = 0.83325 is stored as 0.8333; dividing the rate by the factor alone
+	// would come to 250.0150 instead of the 250 entered
+	baseQty, baseRate := models.ToBaseUnitLine(d("2.5"), d("100"), third)
+	if drifted := baseQty.Mul(models.ToBaseUnitRate(d("100"), third)).Round(4); drifted.Equal(d("250")) {
+		t.Fatalf("expected the separately converted rate to drift, got %s", drifted)
+	}
+	if !baseRate.Equal(d("300.01200048")) {
+		t.Errorf("base rate = %s, want 300.01200048", baseRate)
+	}
+
+	// exact conversions keep the plain per-base-unit rate
+	if q, r := models.ToBaseUnitLine(d("2.5"), d("400"), d("24")); !q.Equal(d("60")) || !r.Equal(d("16.66666667")) {
+		t.Errorf("carton of 24: qty %s rate %s, want 60 and 16.66666667", q, r)
+	}
+}
+
+// The converted rate keeps eight decimals; columns rounding it to four would make the stored
+// qty x rate drift from the amount entered, in line totals and in FIFO cost.
+func TestBaseUnitRateColumns(t *testing.T) {
+	columns := []struct {
+		model interface{}
+		field string
+	}{
+		{models.BillDetail{}, "DetailUnitRate"},
+		{models.CreditNoteDetail{}, "DetailUnitRate"},
+		{models.PurchaseOrderDetail{}, "DetailUnitRate"},
+		{models.SalesInvoiceDetail{}, "DetailUnitRate"},
+		{models.SalesOrderDetail{}, "DetailUnitRate"},
+		{models.SupplierCreditDetail{}, "DetailUnitRate"},
+		{models.InventoryAdjustmentDetail{}, "CostPrice"},
+		{models.StockHistory{}, "BaseUnitValue"},
+	}
+	for _, c := range columns {
+		typ := reflect.TypeOf(c.model)
+		field, ok := typ.FieldByName(c.field)
+		if !ok {
+			t.Fatalf("%s has no field %s", typ.Name(), c.field)
+		}
+		if tag := field.Tag.Get("gorm"); !strings.Contains(tag, "decimal(20,8)") {
+			t.Errorf("%s.%s column = %q, want decimal(20,8)", typ.Name(), c.field, tag)
+		}
+	}
+}
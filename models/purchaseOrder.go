@@ -31,6 +31,7 @@ type PurchaseOrder struct {
 	Notes                       string          `gorm:"type:text;default:null" json:"notes"`
 	TermsAndConditions          string          `gorm:"type:text;default:null" json:"terms_and_conditions"`
 	CurrencyId                  int             `gorm:"not null" json:"currency_id" binding:"required"`
+	PriceListId                 int             `gorm:"default:0" json:"price_list_id"`
 	ExchangeRate                decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"exchange_rate"`
 	OrderDiscount               decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"order_discount"`
 	OrderDiscountType           *DiscountType   `gorm:"type:enum('P','A');default:null;" json:"order_discount_type"`
@@ -73,6 +74,7 @@ type NewPurchaseOrder struct {
 	Notes                       string                   `json:"notes"`
 	TermsAndConditions          string                   `json:"terms_and_conditions"`
 	CurrencyId                  int                      `json:"currency_id"`
+	PriceListId                 int                      `json:"price_list_id"`
 	ExchangeRate                decimal.Decimal          `json:"exchange_rate"`
 	OrderDiscount               decimal.Decimal          `json:"order_discount"`
 	OrderDiscountType           *DiscountType            `json:"order_discount_type"`
@@ -97,7 +99,7 @@ type PurchaseOrderDetail struct {
 	DetailAccountId      int             `gorm:"default:null" json:"detail_account_id"`
 	DetailQty            decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_qty" binding:"required"`
 	DetailBilledQty      decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_billed_qty"`
-	DetailUnitRate       decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_unit_rate" binding:"required"`
+	DetailUnitRate       decimal.Decimal `gorm:"type:decimal(20,8);default:0" json:"detail_unit_rate" binding:"required"`
 	DetailTaxId          int             `gorm:"default:null" json:"detail_tax_id"`
 	DetailTaxType        *TaxType        `gorm:"type:enum('I','G');default:null;" json:"detail_tax_type"`
 	DetailDiscount       decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_discount"`
@@ -105,6 +107,7 @@ type PurchaseOrderDetail struct {
 	DetailDiscountAmount decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_discount_amount"`
 	DetailTaxAmount      decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_tax_amount"`
 	DetailTotalAmount    decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_total_amount"`
+	LineUnit
 }
 
 type NewPurchaseOrderDetail struct {
@@ -117,11 +120,13 @@ type NewPurchaseOrderDetail struct {
 	DetailAccountId    int             `json:"detail_account_id"`
 	DetailQty          decimal.Decimal `json:"detail_qty" binding:"required"`
 	DetailUnitRate     decimal.Decimal `json:"detail_unit_rate" binding:"required"`
+	UsePriceListRate   *bool           `json:"use_price_list_rate"`
 	DetailTaxId        int             `json:"detail_tax_id"`
 	DetailTaxType      *TaxType        `json:"detail_tax_type"`
 	DetailDiscount     decimal.Decimal `json:"detail_discount"`
 	DetailDiscountType *DiscountType   `json:"detail_discount_type"`
 	IsDeletedItem      *bool           `json:"is_deleted_item"`
+	LineUnit
 }
 
 type PurchaseOrdersConnection struct {
@@ -254,6 +259,33 @@ func (input NewPurchaseOrder) validate(ctx context.Context, businessId string, _
 	return nil
 }
 
+func (input *NewPurchaseOrder) applyPriceList(ctx context.Context, businessId string) error {
+	list, err := documentPriceList(ctx, businessId, PriceListTypePurchase, &input.PriceListId, input.SupplierId, input.CurrencyId, input.OrderDate)
+	if err != nil {
+		return err
+	}
+	for i := range input.Details {
+		d := &input.Details[i]
+		if err := list.fillRate(ctx, businessId, d.ProductType, d.ProductId, d.UnitId, d.DetailQty, d.UsePriceListRate, &d.DetailUnitRate); err != nil {
+			return fmt.Errorf("%s: %w", d.Name, err)
+		}
+	}
+	return nil
+}
+
+func (input *NewPurchaseOrder) detailsToBaseUnit(ctx context.Context, businessId string) error {
+	for i := range input.Details {
+		d := &input.Details[i]
+		if d.IsDeletedItem != nil && *d.IsDeletedItem {
+			continue
+		}
+		if err := d.LineUnit.toBaseUnit(ctx, businessId, d.ProductType, d.ProductId, &d.DetailQty, &d.DetailUnitRate); err != nil {
+			return fmt.Errorf("%s: %w", d.Name, err)
+		}
+	}
+	return nil
+}
+
 func CreatePurchaseOrder(ctx context.Context, input *NewPurchaseOrder) (*PurchaseOrder, error) {
 	db := config.GetDB()
 
@@ -266,6 +298,12 @@ func CreatePurchaseOrder(ctx context.Context, input *NewPurchaseOrder) (*Purchas
 	// and then transition Draft -> Confirmed inside the same DB transaction.
 	requestedStatus := input.CurrentStatus
 
+	if err := input.applyPriceList(ctx, businessId); err != nil {
+		return nil, err
+	}
+	if err := input.detailsToBaseUnit(ctx, businessId); err != nil {
+		return nil, err
+	}
 	// validate PurchaseOrder
 	if err := input.validate(ctx, businessId, 0); err != nil {
 		return nil, err
@@ -294,6 +332,7 @@ func CreatePurchaseOrder(ctx context.Context, input *NewPurchaseOrder) (*Purchas
 			DetailAccountId:    item.DetailAccountId,
 			DetailQty:          item.DetailQty,
 			DetailUnitRate:     item.DetailUnitRate,
+			LineUnit:           item.LineUnit,
 			DetailTaxId:        item.DetailTaxId,
 			DetailTaxType:      item.DetailTaxType,
 			DetailDiscount:     item.DetailDiscount,
@@ -363,6 +402,7 @@ func CreatePurchaseOrder(ctx context.Context, input *NewPurchaseOrder) (*Purchas
 		Notes:                       input.Notes,
 		TermsAndConditions:          input.TermsAndConditions,
 		CurrencyId:                  input.CurrencyId,
+		PriceListId:                 input.PriceListId,
 		ExchangeRate:                input.ExchangeRate,
 		OrderDiscount:               input.OrderDiscount,
 		OrderDiscountType:           input.OrderDiscountType,
@@ -455,6 +495,12 @@ func UpdatePurchaseOrder(ctx context.Context, purchaseOrderID int, updatedOrder
 		return nil, errors.New("business id is required")
 	}
 
+	if err := updatedOrder.applyPriceList(ctx, businessId); err != nil {
+		return nil, err
+	}
+	if err := updatedOrder.detailsToBaseUnit(ctx, businessId); err != nil {
+		return nil, err
+	}
 	if err := updatedOrder.validate(ctx, businessId, purchaseOrderID); err != nil {
 		return nil, err
 	}
@@ -490,6 +536,7 @@ func UpdatePurchaseOrder(ctx context.Context, purchaseOrderID int, updatedOrder
 	existingOrder.Notes = updatedOrder.Notes
 	existingOrder.TermsAndConditions = updatedOrder.TermsAndConditions
 	existingOrder.CurrencyId = updatedOrder.CurrencyId
+	existingOrder.PriceListId = updatedOrder.PriceListId
 	existingOrder.ExchangeRate = updatedOrder.ExchangeRate
 	existingOrder.OrderDiscount = updatedOrder.OrderDiscount
 	existingOrder.OrderDiscountType = updatedOrder.OrderDiscountType
@@ -532,6 +579,7 @@ func UpdatePurchaseOrder(ctx context.Context, purchaseOrderID int, updatedOrder
 				DetailAccountId:    updatedItem.DetailAccountId,
 				DetailQty:          updatedItem.DetailQty,
 				DetailUnitRate:     updatedItem.DetailUnitRate,
+				LineUnit:           updatedItem.LineUnit,
 				DetailTaxId:        updatedItem.DetailTaxId,
 				DetailTaxType:      updatedItem.DetailTaxType,
 				DetailDiscount:     updatedItem.DetailDiscount,
@@ -600,6 +648,7 @@ func UpdatePurchaseOrder(ctx context.Context, purchaseOrderID int, updatedOrder
 				existingItem.DetailAccountId = updatedItem.DetailAccountId
 				existingItem.DetailQty = updatedItem.DetailQty
 				existingItem.DetailUnitRate = updatedItem.DetailUnitRate
+				existingItem.LineUnit = updatedItem.LineUnit
 				existingItem.DetailTaxId = updatedItem.DetailTaxId
 				existingItem.DetailTaxType = updatedItem.DetailTaxType
 				existingItem.DetailDiscount = updatedItem.DetailDiscount
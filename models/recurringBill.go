@@ -38,6 +38,13 @@ type RecurringBill struct {
 	BillTotalDiscountAmount    decimal.Decimal       `gorm:"type:decimal(20,4);default:0" json:"bill_total_discount_amount"`
 	BillTotalTaxAmount         decimal.Decimal       `gorm:"type:decimal(20,4);default:0" json:"bill_total_tax_amount"`
 	BillTotalAmount            decimal.Decimal       `gorm:"type:decimal(20,4);default:0" json:"bill_total_amount"`
+	WarehouseId                int                   `gorm:"default:null" json:"warehouse_id"`
+	BillStatus                 BillStatus            `gorm:"type:enum('Draft', 'Confirmed');default:Draft" json:"bill_status"`
+	LastGeneratedDate          *time.Time            `gorm:"default:null" json:"last_generated_date"`
+	NextOccurrenceDate         *time.Time            `gorm:"index;default:null" json:"next_occurrence_date"`
+	FailedAttempts             int                   `gorm:"not null;default:0" json:"failed_attempts"`
+	NextAttemptDate            *time.Time            `gorm:"default:null" json:"next_attempt_date"`
+	IsPaused                   *bool                 `gorm:"not null;default:false" json:"is_paused"`
 	Details                    []RecurringBillDetail `json:"recurring_bill_details" validate:"required,dive,required"`
 	CreatedAt                  time.Time             `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt                  time.Time             `gorm:"autoUpdateTime" json:"updated_at"`
@@ -62,6 +69,8 @@ type NewRecurringBill struct {
 	IsTaxInclusive             *bool                    `json:"is_tax_inclusive"`
 	BillTaxId                  int                      `json:"bill_tax_id"`
 	BillTaxType                *TaxType                 `json:"bill_tax_type"`
+	WarehouseId                int                      `json:"warehouse_id"`
+	BillStatus                 *BillStatus              `json:"bill_status"`
 	Details                    []NewRecurringBillDetail `json:"details"`
 }
 
@@ -194,6 +203,11 @@ func CreateRecurringBill(ctx context.Context, input *NewRecurringBill) (*Recurri
 		return nil, utils.ErrorRecordNotFound
 	}
 
+	billStatus, err := input.validateSchedule(ctx, businessId)
+	if err != nil {
+		return nil, err
+	}
+
 	var billItems []RecurringBillDetail
 	var billSubtotal,
 		billTotalAmount,
@@ -291,9 +305,16 @@ func CreateRecurringBill(ctx context.Context, input *NewRecurringBill) (*Recurri
 		BillTotalTaxAmount:         totalBillTaxAmount,
 		BillSubtotal:               billSubtotal,
 		BillTotalAmount:            billTotalAmount,
+		WarehouseId:                input.WarehouseId,
+		BillStatus:                 billStatus,
+	}
+
+	bill.NextOccurrenceDate, err = bill.nextOccurrence(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	err := db.WithContext(ctx).Create(&bill).Error
+	err = db.WithContext(ctx).Create(&bill).Error
 	if err != nil {
 		return nil, err
 	}
@@ -304,6 +325,16 @@ func CreateRecurringBill(ctx context.Context, input *NewRecurringBill) (*Recurri
 func UpdateRecurringBill(ctx context.Context, id int, updatedRecurringBill *NewRecurringBill) (*RecurringBill, error) {
 	db := config.GetDB()
 
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	billStatus, err := updatedRecurringBill.validateSchedule(ctx, businessId)
+	if err != nil {
+		return nil, err
+	}
+
 	// Fetch the existing purchase order
 	var existingRecurringBill RecurringBill
 	if err := db.WithContext(ctx).Preload("Details").First(&existingRecurringBill, id).Error; err != nil {
@@ -331,6 +362,14 @@ func UpdateRecurringBill(ctx context.Context, id int, updatedRecurringBill *NewR
 	existingRecurringBill.IsTaxInclusive = updatedRecurringBill.IsTaxInclusive
 	existingRecurringBill.BillTaxId = updatedRecurringBill.BillTaxId
 	existingRecurringBill.BillTaxType = updatedRecurringBill.BillTaxType
+	existingRecurringBill.WarehouseId = updatedRecurringBill.WarehouseId
+	existingRecurringBill.BillStatus = billStatus
+
+	// schedule may have changed, recompute from the last generated occurrence
+	existingRecurringBill.NextOccurrenceDate, err = existingRecurringBill.nextOccurrence(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	var orderSubtotal,
 		orderTotalAmount,
@@ -562,3 +601,159 @@ func PaginateRecurringBill(ctx context.Context, limit *int, after *string,
 
 	return &recurringBillsConnection, err
 }
+
+// PauseRecurringBill stops the scheduler from generating bills for the profile.
+func PauseRecurringBill(ctx context.Context, id int) (*RecurringBill, error) {
+	return setRecurringProfilePaused[RecurringBill](ctx, id, true)
+}
+
+// ResumeRecurringBill continues the profile from its next occurrence after today;
+// occurrences missed while paused are not generated.
+func ResumeRecurringBill(ctx context.Context, id int) (*RecurringBill, error) {
+	return setRecurringProfilePaused[RecurringBill](ctx, id, false)
+}
+
+func (input NewRecurringBill) validateSchedule(ctx context.Context, businessId string) (BillStatus, error) {
+	if input.WarehouseId > 0 {
+		if err := utils.ValidateResourceId[Warehouse](ctx, businessId, input.WarehouseId); err != nil {
+			return "", errors.New("warehouse not found")
+		}
+	}
+	if err := validateRecurringSchedule(input.StartDate, input.EndDate, input.RepeatTerms, input.RepeatTimes); err != nil {
+		return "", err
+	}
+	billStatus := BillStatusDraft
+	if input.BillStatus != nil {
+		billStatus = *input.BillStatus
+	}
+	if billStatus != BillStatusDraft && billStatus != BillStatusConfirmed {
+		return "", errors.New("recurring bills can only be generated as draft or confirmed")
+	}
+	return billStatus, nil
+}
+
+func (rb RecurringBill) schedule() recurringSchedule {
+	return recurringSchedule{
+		ProfileType:        "RecurringBill",
+		ReferenceType:      "recurring_bills",
+		DocumentName:       "Bill",
+		BusinessId:         rb.BusinessId,
+		StartDate:          rb.StartDate,
+		EndDate:            rb.EndDate,
+		IsNeverExpired:     rb.IsNeverExpired,
+		RepeatTerms:        rb.RepeatTerms,
+		RepeatTimes:        rb.RepeatTimes,
+		LastGeneratedDate:  rb.LastGeneratedDate,
+		NextOccurrenceDate: rb.NextOccurrenceDate,
+		FailedAttempts:     rb.FailedAttempts,
+		IsPaused:           rb.IsPaused,
+	}
+}
+
+// nextOccurrence returns the first occurrence after the last generated one, in the business timezone.
+func (rb RecurringBill) nextOccurrence(ctx context.Context) (*time.Time, error) {
+	return rb.schedule().next(ctx, rb.LastGeneratedDate)
+}
+
+// GetDueRecurringBills returns profiles (across all businesses) whose next occurrence is due,
+// leaving out those waiting to retry a failed generation.
+func GetDueRecurringBills(ctx context.Context, now time.Time, limit int) ([]*RecurringBill, error) {
+	return getDueRecurringProfiles[RecurringBill](ctx, now, limit)
+}
+
+// GenerateRecurringBills creates bills for every occurrence of the profile due at `now`.
+// Returns the number of bills created.
+func GenerateRecurringBills(ctx context.Context, profileId int, now time.Time) (int, error) {
+	return generateRecurringProfile[RecurringBill](ctx, profileId, now, "Details")
+}
+
+func (rb RecurringBill) generatedId(ctx context.Context, occurrence time.Time) (int, error) {
+	var bill Bill
+	err := config.GetDB().WithContext(ctx).
+		Where("business_id = ? AND recurring_bill_id = ? AND bill_date = ?", rb.BusinessId, rb.ID, occurrence).
+		Select("id").Limit(1).Find(&bill).Error
+	return bill.ID, err
+}
+
+func (rb RecurringBill) generate(ctx context.Context, occurrence time.Time) (int, string, error) {
+	input, err := rb.toNewBill(ctx, occurrence)
+	if err != nil {
+		return 0, "", err
+	}
+	bill, err := CreateBill(ctx, input)
+	if err != nil {
+		return 0, "", err
+	}
+	return bill.ID, bill.BillNumber, nil
+}
+
+func (rb RecurringBill) toNewBill(ctx context.Context, billDate time.Time) (*NewBill, error) {
+	exchangeRate, err := GetExchangeRateAsOf(ctx, rb.BusinessId, rb.CurrencyId, billDate)
+	if err != nil {
+		return nil, err
+	}
+
+	details := make([]NewBillDetail, 0, len(rb.Details))
+	for _, item := range rb.Details {
+		details = append(details, NewBillDetail{
+			ProductId:          item.ProductId,
+			ProductType:        item.ProductType,
+			Name:               item.Name,
+			Description:        item.Description,
+			CustomerId:         item.DetailCustomerId,
+			DetailAccountId:    item.DetailAccountId,
+			DetailQty:          item.DetailQty,
+			DetailUnitRate:     item.DetailUnitRate,
+			DetailTaxId:        item.DetailTaxId,
+			DetailTaxType:      item.DetailTaxType,
+			DetailDiscount:     item.DetailDiscount,
+			DetailDiscountType: item.DetailDiscountType,
+		})
+	}
+
+	return &NewBill{
+		SupplierId:                 rb.SupplierId,
+		BranchId:                   rb.BranchId,
+		BillDate:                   billDate,
+		BillPaymentTerms:           rb.BillPaymentTerms,
+		BillPaymentTermsCustomDays: rb.BillPaymentTermsCustomDays,
+		BillSubject:                rb.ProfileName,
+		Notes:                      rb.Notes,
+		CurrencyId:                 rb.CurrencyId,
+		ExchangeRate:               exchangeRate,
+		BillDiscount:               rb.BillDiscount,
+		BillDiscountType:           rb.BillDiscountType,
+		AdjustmentAmount:           rb.AdjustmentAmount,
+		IsTaxInclusive:             rb.IsTaxInclusive,
+		BillTaxId:                  rb.BillTaxId,
+		BillTaxType:                rb.BillTaxType,
+		CurrentStatus:              rb.BillStatus,
+		WarehouseId:                rb.WarehouseId,
+		RecurringBillId:            rb.ID,
+		Details:                    details,
+	}, nil
+}
+
+// backfillRecurringBillSchedules sets the next occurrence of profiles saved before bills were
+// generated from them. They continue from now on; earlier occurrences are not back-filled.
+func backfillRecurringBillSchedules() error {
+	db := config.GetDB()
+	var profiles []*RecurringBill
+	if err := db.Where("next_occurrence_date IS NULL AND last_generated_date IS NULL").Find(&profiles).Error; err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	for _, profile := range profiles {
+		next, err := profile.schedule().next(context.Background(), &now)
+		if err != nil {
+			return err
+		}
+		if next == nil {
+			continue
+		}
+		if err := db.Model(&RecurringBill{}).Where("id = ?", profile.ID).UpdateColumn("next_occurrence_date", next).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,54 +1,342 @@
 package models
 
 import (
+	"context"
+	"errors"
 	"time"
 
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/utils"
 	"github.com/shopspring/decimal"
 )
 
 type RecurringExpense struct {
-	ID               int             `gorm:"primary_key" json:"id"`
-	BusinessId       string          `gorm:"index;not null" json:"business_id" binding:"required"`
-	ProfileName      string          `gorm:"size:100;not null" json:"profile_name" binding:"required"`
-	RepeatTimes      int             `gorm:"not null;default:1" json:"repeat_times" binding:"required"`
-	RepeatTerms      RecurringTerms  `gorm:"type:enum('D', 'W', 'M', 'Y')" json:"repeat_terms" binding:"required"`
-	StartDate        time.Time       `gorm:"not null" json:"start_date" binding:"required"`
-	EndDate          time.Time       `json:"end_date"`
-	IsNeverExpired   *bool           `gorm:"default:false" json:"is_never_expired"`
-	ExpenseAccountId int             `gorm:"index;not null" json:"expense_account_id" binding:"required"`
-	AssetAccountId   int             `gorm:"index;not null" json:"asset_account_id" binding:"required"`
-	BranchId         int             `gorm:"index" json:"branch_id"`
-	ExpenseDate      time.Time       `gorm:"not null" json:"expense_date" binding:"required"`
-	CurrencyId       int             `gorm:"not null" json:"currency_id" binding:"required"`
-	Amount           decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"amount"`
-	SupplierId       int             `json:"supplier_id"`
-	CustomerId       int             `json:"customer_id"`
-	ReferenceNumber  string          `gorm:"size:255" json:"reference_number"`
-	Notes            string          `gorm:"type:text" json:"notes"`
-	ExpenseTaxId     int             `json:"expense_tax_id"`
-	ExpenseTaxType   TaxType         `gorm:"type:enum('I', 'G')" json:"expense_tax_type"`
-	CreatedAt        time.Time       `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt        time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+	ID                 int             `gorm:"primary_key" json:"id"`
+	BusinessId         string          `gorm:"index;not null" json:"business_id" binding:"required"`
+	ProfileName        string          `gorm:"size:100;not null" json:"profile_name" binding:"required"`
+	RepeatTimes        int             `gorm:"not null;default:1" json:"repeat_times" binding:"required"`
+	RepeatTerms        RecurringTerms  `gorm:"type:enum('D', 'W', 'M', 'Y')" json:"repeat_terms" binding:"required"`
+	StartDate          time.Time       `gorm:"not null" json:"start_date" binding:"required"`
+	EndDate            *time.Time      `gorm:"default:null" json:"end_date"`
+	IsNeverExpired     *bool           `gorm:"default:false" json:"is_never_expired"`
+	ExpenseAccountId   int             `gorm:"index;not null" json:"expense_account_id" binding:"required"`
+	AssetAccountId     int             `gorm:"index;not null" json:"asset_account_id" binding:"required"`
+	BranchId           int             `gorm:"index" json:"branch_id"`
+	CurrencyId         int             `gorm:"not null" json:"currency_id" binding:"required"`
+	Amount             decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"amount"`
+	BankCharges        decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"bank_charges"`
+	SupplierId         int             `json:"supplier_id"`
+	CustomerId         int             `json:"customer_id"`
+	ReferenceNumber    string          `gorm:"size:255" json:"reference_number"`
+	Notes              string          `gorm:"type:text" json:"notes"`
+	ExpenseTaxId       int             `json:"expense_tax_id"`
+	ExpenseTaxType     *TaxType        `gorm:"type:enum('I', 'G');default:null" json:"expense_tax_type"`
+	IsTaxInclusive     *bool           `gorm:"not null;default:false" json:"is_tax_inclusive"`
+	LastGeneratedDate  *time.Time      `gorm:"default:null" json:"last_generated_date"`
+	NextOccurrenceDate *time.Time      `gorm:"index;default:null" json:"next_occurrence_date"`
+	FailedAttempts     int             `gorm:"not null;default:0" json:"failed_attempts"`
+	NextAttemptDate    *time.Time      `gorm:"default:null" json:"next_attempt_date"`
+	IsPaused           *bool           `gorm:"not null;default:false" json:"is_paused"`
+	CreatedAt          time.Time       `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt          time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
 type NewRecurringExpense struct {
-	BusinessId       string          `json:"business_id" binding:"required"`
 	ProfileName      string          `json:"profile_name" binding:"required"`
 	RepeatTimes      int             `json:"repeat_times" binding:"required"`
 	RepeatTerms      RecurringTerms  `json:"repeat_terms" binding:"required"`
 	StartDate        time.Time       `json:"start_date" binding:"required"`
-	EndDate          time.Time       `json:"end_date"`
-	IsNeverExpired   *bool           `gorm:"default:false" json:"is_never_expired"`
+	EndDate          *time.Time      `json:"end_date"`
+	IsNeverExpired   *bool           `json:"is_never_expired"`
 	ExpenseAccountId int             `json:"expense_account_id" binding:"required"`
 	AssetAccountId   int             `json:"asset_account_id" binding:"required"`
 	BranchId         int             `json:"branch_id"`
-	ExpenseDate      time.Time       `json:"expense_date" binding:"required"`
 	CurrencyId       int             `json:"currency_id" binding:"required"`
 	Amount           decimal.Decimal `json:"amount"`
+	BankCharges      decimal.Decimal `json:"bank_charges"`
 	SupplierId       int             `json:"supplier_id"`
 	CustomerId       int             `json:"customer_id"`
 	ReferenceNumber  string          `json:"reference_number"`
 	Notes            string          `json:"notes"`
 	ExpenseTaxId     int             `json:"expense_tax_id"`
-	ExpenseTaxType   TaxType         `json:"expense_tax_type"`
+	ExpenseTaxType   *TaxType        `json:"expense_tax_type"`
+	IsTaxInclusive   *bool           `json:"is_tax_inclusive"`
+}
+
+type RecurringExpensesConnection struct {
+	Edges    []*RecurringExpensesEdge `json:"edges"`
+	PageInfo *PageInfo                `json:"pageInfo"`
+}
+
+type RecurringExpensesEdge Edge[RecurringExpense]
+
+func (obj RecurringExpense) GetId() int {
+	return obj.ID
+}
+
+// implements methods for pagination
+
+// node
+// returns decoded curosr string
+func (re RecurringExpense) GetCursor() string {
+	return re.CreatedAt.String()
+}
+
+func (input NewRecurringExpense) validate(ctx context.Context, businessId string, _ int) error {
+	if err := validateRecurringSchedule(input.StartDate, input.EndDate, input.RepeatTerms, input.RepeatTimes); err != nil {
+		return err
+	}
+	// generated expenses are validated with the same rules as manual ones
+	expense := input.toNewExpense(time.Now().UTC(), decimal.Zero)
+	return expense.validate(ctx, businessId, 0)
+}
+
+func (input NewRecurringExpense) toNewExpense(expenseDate time.Time, exchangeRate decimal.Decimal) *NewExpense {
+	isTaxInclusive := input.IsTaxInclusive
+	if isTaxInclusive == nil {
+		isTaxInclusive = utils.NewFalse()
+	}
+	return &NewExpense{
+		ExpenseAccountId: input.ExpenseAccountId,
+		AssetAccountId:   input.AssetAccountId,
+		BranchId:         input.BranchId,
+		ExpenseDate:      expenseDate,
+		CurrencyId:       input.CurrencyId,
+		ExchangeRate:     exchangeRate,
+		Amount:           input.Amount,
+		BankCharges:      input.BankCharges,
+		SupplierId:       input.SupplierId,
+		CustomerId:       input.CustomerId,
+		ReferenceNumber:  input.ReferenceNumber,
+		Notes:            input.Notes,
+		ExpenseTaxId:     input.ExpenseTaxId,
+		ExpenseTaxType:   input.ExpenseTaxType,
+		IsTaxInclusive:   isTaxInclusive,
+	}
+}
+
+func (re RecurringExpense) toNewRecurringExpense() NewRecurringExpense {
+	return NewRecurringExpense{
+		ProfileName:      re.ProfileName,
+		RepeatTimes:      re.RepeatTimes,
+		RepeatTerms:      re.RepeatTerms,
+		StartDate:        re.StartDate,
+		EndDate:          re.EndDate,
+		IsNeverExpired:   re.IsNeverExpired,
+		ExpenseAccountId: re.ExpenseAccountId,
+		AssetAccountId:   re.AssetAccountId,
+		BranchId:         re.BranchId,
+		CurrencyId:       re.CurrencyId,
+		Amount:           re.Amount,
+		BankCharges:      re.BankCharges,
+		SupplierId:       re.SupplierId,
+		CustomerId:       re.CustomerId,
+		ReferenceNumber:  re.ReferenceNumber,
+		Notes:            re.Notes,
+		ExpenseTaxId:     re.ExpenseTaxId,
+		ExpenseTaxType:   re.ExpenseTaxType,
+		IsTaxInclusive:   re.IsTaxInclusive,
+	}
+}
+
+func (re RecurringExpense) schedule() recurringSchedule {
+	return recurringSchedule{
+		ProfileType:        "RecurringExpense",
+		ReferenceType:      "recurring_expenses",
+		DocumentName:       "Expense",
+		BusinessId:         re.BusinessId,
+		StartDate:          re.StartDate,
+		EndDate:            re.EndDate,
+		IsNeverExpired:     re.IsNeverExpired,
+		RepeatTerms:        re.RepeatTerms,
+		RepeatTimes:        re.RepeatTimes,
+		LastGeneratedDate:  re.LastGeneratedDate,
+		NextOccurrenceDate: re.NextOccurrenceDate,
+		FailedAttempts:     re.FailedAttempts,
+		IsPaused:           re.IsPaused,
+	}
+}
+
+// nextOccurrence returns the first occurrence after the last generated one, in the business timezone.
+func (re RecurringExpense) nextOccurrence(ctx context.Context) (*time.Time, error) {
+	return re.schedule().next(ctx, re.LastGeneratedDate)
+}
+
+func (re *RecurringExpense) assign(input *NewRecurringExpense) {
+	isTaxInclusive := input.IsTaxInclusive
+	if isTaxInclusive == nil {
+		isTaxInclusive = utils.NewFalse()
+	}
+	re.ProfileName = input.ProfileName
+	re.RepeatTimes = input.RepeatTimes
+	re.RepeatTerms = input.RepeatTerms
+	re.StartDate = input.StartDate
+	re.EndDate = input.EndDate
+	re.IsNeverExpired = input.IsNeverExpired
+	re.ExpenseAccountId = input.ExpenseAccountId
+	re.AssetAccountId = input.AssetAccountId
+	re.BranchId = input.BranchId
+	re.CurrencyId = input.CurrencyId
+	re.Amount = input.Amount
+	re.BankCharges = input.BankCharges
+	re.SupplierId = input.SupplierId
+	re.CustomerId = input.CustomerId
+	re.ReferenceNumber = input.ReferenceNumber
+	re.Notes = input.Notes
+	re.ExpenseTaxId = input.ExpenseTaxId
+	re.ExpenseTaxType = input.ExpenseTaxType
+	re.IsTaxInclusive = isTaxInclusive
+}
+
+func CreateRecurringExpense(ctx context.Context, input *NewRecurringExpense) (*RecurringExpense, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if err := input.validate(ctx, businessId, 0); err != nil {
+		return nil, err
+	}
+
+	recurringExpense := RecurringExpense{
+		BusinessId: businessId,
+		IsPaused:   utils.NewFalse(),
+	}
+	recurringExpense.assign(input)
+
+	var err error
+	recurringExpense.NextOccurrenceDate, err = recurringExpense.nextOccurrence(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Create(&recurringExpense).Error; err != nil {
+		return nil, err
+	}
+	return &recurringExpense, nil
+}
+
+func UpdateRecurringExpense(ctx context.Context, id int, input *NewRecurringExpense) (*RecurringExpense, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if err := input.validate(ctx, businessId, id); err != nil {
+		return nil, err
+	}
+
+	existing, err := utils.FetchModel[RecurringExpense](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+	existing.assign(input)
+
+	// schedule may have changed, recompute from the last generated occurrence
+	existing.NextOccurrenceDate, err = existing.nextOccurrence(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Save(existing).Error; err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+func DeleteRecurringExpense(ctx context.Context, id int) (*RecurringExpense, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	result, err := utils.FetchModel[RecurringExpense](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Delete(result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PauseRecurringExpense stops the scheduler from generating expenses for the profile.
+func PauseRecurringExpense(ctx context.Context, id int) (*RecurringExpense, error) {
+	return setRecurringProfilePaused[RecurringExpense](ctx, id, true)
+}
+
+// ResumeRecurringExpense continues the profile from its next occurrence after today;
+// occurrences missed while paused are not generated.
+func ResumeRecurringExpense(ctx context.Context, id int) (*RecurringExpense, error) {
+	return setRecurringProfilePaused[RecurringExpense](ctx, id, false)
+}
+
+func GetRecurringExpense(ctx context.Context, id int) (*RecurringExpense, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	return utils.FetchModel[RecurringExpense](ctx, businessId, id)
+}
+
+func PaginateRecurringExpense(ctx context.Context, limit *int, after *string, name *string) (*RecurringExpensesConnection, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	db := config.GetDB()
+	dbCtx := db.WithContext(ctx).Where("business_id = ?", businessId)
+	if name != nil && *name != "" {
+		dbCtx.Where("profile_name LIKE ?", "%"+*name+"%")
+	}
+
+	edges, pageInfo, err := FetchPageCompositeCursor[RecurringExpense](dbCtx, *limit, after, "created_at", "<")
+	if err != nil {
+		return nil, err
+	}
+	var recurringExpensesConnection RecurringExpensesConnection
+	recurringExpensesConnection.PageInfo = pageInfo
+	for _, edge := range edges {
+		recurringExpensesEdge := RecurringExpensesEdge(edge)
+		recurringExpensesConnection.Edges = append(recurringExpensesConnection.Edges, &recurringExpensesEdge)
+	}
+
+	return &recurringExpensesConnection, err
+}
+
+// GetDueRecurringExpenses returns profiles (across all businesses) whose next occurrence is due,
+// leaving out those waiting to retry a failed generation.
+func GetDueRecurringExpenses(ctx context.Context, now time.Time, limit int) ([]*RecurringExpense, error) {
+	return getDueRecurringProfiles[RecurringExpense](ctx, now, limit)
+}
+
+// GenerateRecurringExpenses creates expenses for every occurrence of the profile due at `now`.
+// Returns the number of expenses created.
+func GenerateRecurringExpenses(ctx context.Context, profileId int, now time.Time) (int, error) {
+	return generateRecurringProfile[RecurringExpense](ctx, profileId, now)
+}
+
+func (re RecurringExpense) generatedId(ctx context.Context, occurrence time.Time) (int, error) {
+	var expense Expense
+	err := config.GetDB().WithContext(ctx).
+		Where("business_id = ? AND recurring_expense_id = ? AND expense_date = ?", re.BusinessId, re.ID, occurrence).
+		Select("id").Limit(1).Find(&expense).Error
+	return expense.ID, err
+}
+
+func (re RecurringExpense) generate(ctx context.Context, occurrence time.Time) (int, string, error) {
+	exchangeRate, err := GetExchangeRateAsOf(ctx, re.BusinessId, re.CurrencyId, occurrence)
+	if err != nil {
+		return 0, "", err
+	}
+	input := re.toNewRecurringExpense().toNewExpense(occurrence, exchangeRate)
+	input.RecurringExpenseId = re.ID
+	expense, err := CreateExpense(ctx, input)
+	if err != nil {
+		return 0, "", err
+	}
+	return expense.ID, expense.ExpenseNumber, nil
 }
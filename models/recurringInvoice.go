@@ -0,0 +1,549 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type RecurringInvoice struct {
+	ID                            int                      `gorm:"primary_key" json:"id"`
+	BusinessId                    string                   `gorm:"index;not null" json:"business_id" binding:"required"`
+	CustomerId                    int                      `gorm:"index;not null" json:"customer_id" binding:"required"`
+	BranchId                      int                      `gorm:"index;not null" json:"branch_id" binding:"required"`
+	ProfileName                   string                   `gorm:"size:100;not null" json:"profile_name" binding:"required"`
+	RepeatTimes                   int                      `gorm:"not null;default:1" json:"repeat_times" binding:"required"`
+	RepeatTerms                   RecurringTerms           `gorm:"type:enum('D', 'W', 'M', 'Y')" json:"repeat_terms" binding:"required"`
+	StartDate                     time.Time                `gorm:"not null" json:"start_date" binding:"required"`
+	EndDate                       *time.Time               `gorm:"default:null" json:"end_date"`
+	IsNeverExpired                *bool                    `gorm:"default:false" json:"is_never_expired"`
+	InvoicePaymentTerms           PaymentTerms             `gorm:"type:enum('Net15', 'Net30', 'Net45', 'Net60', 'DueMonthEnd', 'DueNextMonthEnd', 'DueOnReceipt', 'Custom');not null" json:"invoice_payment_terms" binding:"required"`
+	InvoicePaymentTermsCustomDays int                      `gorm:"default:0" json:"invoice_payment_terms_custom_days"`
+	SalesPersonId                 int                      `gorm:"default:null" json:"sales_person_id"`
+	InvoiceSubject                string                   `gorm:"size:255;default:null" json:"invoice_subject"`
+	Notes                         string                   `gorm:"type:text;default:null" json:"notes"`
+	TermsAndConditions            string                   `gorm:"type:text;default:null" json:"terms_and_conditions"`
+	CurrencyId                    int                      `gorm:"not null" json:"currency_id" binding:"required"`
+	WarehouseId                   int                      `gorm:"not null" json:"warehouse_id" binding:"required"`
+	InvoiceDiscount               decimal.Decimal          `gorm:"type:decimal(20,4);default:0" json:"invoice_discount"`
+	InvoiceDiscountType           *DiscountType            `gorm:"type:enum('P', 'A');default:null" json:"invoice_discount_type"`
+	InvoiceDiscountAmount         decimal.Decimal          `gorm:"type:decimal(20,4);default:0" json:"invoice_discount_amount"`
+	ShippingCharges               decimal.Decimal          `gorm:"type:decimal(20,4);default:0" json:"shipping_charges"`
+	AdjustmentAmount              decimal.Decimal          `gorm:"type:decimal(20,4);default:0" json:"adjustment_amount"`
+	IsTaxInclusive                *bool                    `gorm:"not null;default:false" json:"is_tax_inclusive"`
+	InvoiceTaxId                  int                      `gorm:"default:null" json:"invoice_tax_id"`
+	InvoiceTaxType                *TaxType                 `gorm:"type:enum('I', 'G');default:null" json:"invoice_tax_type"`
+	InvoiceTaxAmount              decimal.Decimal          `gorm:"type:decimal(20,4);default:0" json:"invoice_tax_amount"`
+	InvoiceSubtotal               decimal.Decimal          `gorm:"type:decimal(20,4);default:0" json:"invoice_subtotal"`
+	InvoiceTotalDiscountAmount    decimal.Decimal          `gorm:"type:decimal(20,4);default:0" json:"invoice_total_discount_amount"`
+	InvoiceTotalTaxAmount         decimal.Decimal          `gorm:"type:decimal(20,4);default:0" json:"invoice_total_tax_amount"`
+	InvoiceTotalAmount            decimal.Decimal          `gorm:"type:decimal(20,4);default:0" json:"invoice_total_amount"`
+	InvoiceStatus                 SalesInvoiceStatus       `gorm:"type:enum('Draft', 'Confirmed');default:Draft" json:"invoice_status"`
+	LastGeneratedDate             *time.Time               `gorm:"default:null" json:"last_generated_date"`
+	NextOccurrenceDate            *time.Time               `gorm:"index;default:null" json:"next_occurrence_date"`
+	FailedAttempts                int                      `gorm:"not null;default:0" json:"failed_attempts"`
+	NextAttemptDate               *time.Time               `gorm:"default:null" json:"next_attempt_date"`
+	IsPaused                      *bool                    `gorm:"not null;default:false" json:"is_paused"`
+	Details                       []RecurringInvoiceDetail `gorm:"foreignKey:RecurringInvoiceId" json:"details"`
+	CreatedAt                     time.Time                `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt                     time.Time                `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type NewRecurringInvoice struct {
+	CustomerId                    int                         `json:"customer_id" binding:"required"`
+	BranchId                      int                         `json:"branch_id" binding:"required"`
+	ProfileName                   string                      `json:"profile_name" binding:"required"`
+	RepeatTimes                   int                         `json:"repeat_times" binding:"required"`
+	RepeatTerms                   RecurringTerms              `json:"repeat_terms" binding:"required"`
+	StartDate                     time.Time                   `json:"start_date" binding:"required"`
+	EndDate                       *time.Time                  `json:"end_date"`
+	IsNeverExpired                *bool                       `json:"is_never_expired"`
+	InvoicePaymentTerms           PaymentTerms                `json:"invoice_payment_terms" binding:"required"`
+	InvoicePaymentTermsCustomDays int                         `json:"invoice_payment_terms_custom_days"`
+	SalesPersonId                 int                         `json:"sales_person_id"`
+	InvoiceSubject                string                      `json:"invoice_subject"`
+	Notes                         string                      `json:"notes"`
+	TermsAndConditions            string                      `json:"terms_and_conditions"`
+	CurrencyId                    int                         `json:"currency_id" binding:"required"`
+	WarehouseId                   int                         `json:"warehouse_id" binding:"required"`
+	InvoiceDiscount               decimal.Decimal             `json:"invoice_discount"`
+	InvoiceDiscountType           *DiscountType               `json:"invoice_discount_type"`
+	ShippingCharges               decimal.Decimal             `json:"shipping_charges"`
+	AdjustmentAmount              decimal.Decimal             `json:"adjustment_amount"`
+	IsTaxInclusive                *bool                       `json:"is_tax_inclusive" binding:"required"`
+	InvoiceTaxId                  int                         `json:"invoice_tax_id"`
+	InvoiceTaxType                *TaxType                    `json:"invoice_tax_type"`
+	InvoiceStatus                 *SalesInvoiceStatus         `json:"invoice_status"`
+	Details                       []NewRecurringInvoiceDetail `json:"details"`
+}
+
+type RecurringInvoiceDetail struct {
+	ID                   int             `gorm:"primary_key" json:"id"`
+	RecurringInvoiceId   int             `gorm:"index;not null" json:"recurring_invoice_id" binding:"required"`
+	ProductId            int             `json:"product_id"`
+	ProductType          ProductType     `gorm:"type:enum('S','G','C','V','I');default:S" json:"product_type"`
+	Name                 string          `gorm:"size:100" json:"name" binding:"required"`
+	Description          string          `gorm:"size:255;default:null" json:"description"`
+	DetailAccountId      int             `gorm:"default:null" json:"detail_account_id"`
+	DetailQty            decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_qty" binding:"required"`
+	DetailUnitRate       decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_unit_rate" binding:"required"`
+	DetailTaxId          int             `gorm:"default:null" json:"detail_tax_id"`
+	DetailTaxType        *TaxType        `gorm:"type:enum('I', 'G');default:null" json:"detail_tax_type"`
+	DetailDiscount       decimal.Decimal `json:"detail_discount"`
+	DetailDiscountType   *DiscountType   `gorm:"type:enum('P', 'A');default:null" json:"detail_discount_type"`
+	DetailDiscountAmount decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_discount_amount"`
+	DetailTaxAmount      decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_tax_amount"`
+	DetailTotalAmount    decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_total_amount"`
+}
+
+type NewRecurringInvoiceDetail struct {
+	DetailId           int             `json:"detail_id"`
+	ProductId          int             `json:"product_id"`
+	ProductType        ProductType     `json:"product_type"`
+	Name               string          `json:"name" binding:"required"`
+	Description        string          `json:"description"`
+	DetailAccountId    int             `json:"detail_account_id"`
+	DetailQty          decimal.Decimal `json:"detail_qty" binding:"required"`
+	DetailUnitRate     decimal.Decimal `json:"detail_unit_rate" binding:"required"`
+	DetailTaxId        int             `json:"detail_tax_id"`
+	DetailTaxType      *TaxType        `json:"detail_tax_type"`
+	DetailDiscount     decimal.Decimal `json:"detail_discount"`
+	DetailDiscountType *DiscountType   `json:"detail_discount_type"`
+	IsDeletedItem      *bool           `json:"is_deleted_item"`
+}
+
+type RecurringInvoicesConnection struct {
+	Edges    []*RecurringInvoicesEdge `json:"edges"`
+	PageInfo *PageInfo                `json:"pageInfo"`
+}
+
+type RecurringInvoicesEdge Edge[RecurringInvoice]
+
+func (obj RecurringInvoice) GetId() int {
+	return obj.ID
+}
+
+// implements methods for pagination
+
+// node
+// returns decoded curosr string
+func (ri RecurringInvoice) GetCursor() string {
+	return ri.CreatedAt.String()
+}
+
+func (input NewRecurringInvoice) validate(ctx context.Context, businessId string, _ int) (SalesInvoiceStatus, error) {
+	if input.IsTaxInclusive == nil {
+		return "", errors.New("is_tax_inclusive is required")
+	}
+	if err := validateRecurringSchedule(input.StartDate, input.EndDate, input.RepeatTerms, input.RepeatTimes); err != nil {
+		return "", err
+	}
+	if err := utils.ValidateResourceId[Customer](ctx, businessId, input.CustomerId); err != nil {
+		return "", errors.New("customer not found")
+	}
+	if err := utils.ValidateResourceId[Branch](ctx, businessId, input.BranchId); err != nil {
+		return "", errors.New("branch not found")
+	}
+	if err := utils.ValidateResourceId[Currency](ctx, businessId, input.CurrencyId); err != nil {
+		return "", errors.New("currency not found")
+	}
+	if err := utils.ValidateResourceId[Warehouse](ctx, businessId, input.WarehouseId); err != nil {
+		return "", errors.New("warehouse not found")
+	}
+	if input.SalesPersonId > 0 {
+		if err := utils.ValidateResourceId[SalesPerson](ctx, businessId, input.SalesPersonId); err != nil {
+			return "", errors.New("salesPerson not found")
+		}
+	}
+	if input.InvoiceTaxType != nil {
+		if err := validateTaxExists(ctx, businessId, input.InvoiceTaxId, *input.InvoiceTaxType); err != nil {
+			return "", errors.New("invoice tax not found")
+		}
+	}
+	if len(input.Details) == 0 {
+		return "", errors.New("at least one item is required")
+	}
+
+	invoiceStatus := SalesInvoiceStatusDraft
+	if input.InvoiceStatus != nil {
+		invoiceStatus = *input.InvoiceStatus
+	}
+	if invoiceStatus != SalesInvoiceStatusDraft && invoiceStatus != SalesInvoiceStatusConfirmed {
+		return "", errors.New("recurring invoices can only be generated as draft or confirmed")
+	}
+	return invoiceStatus, nil
+}
+
+func (item *RecurringInvoiceDetail) CalculateRecurringItemDiscountAndTax(ctx context.Context, isTaxInclusive bool) {
+	db := config.GetDB()
+
+	// calculate detail subtotal
+	detailAmount := item.DetailQty.Mul(item.DetailUnitRate)
+	// calculate discount amount
+	var discountAmount decimal.Decimal
+	if item.DetailDiscountType != nil {
+		discountAmount = utils.CalculateDiscountAmount(detailAmount, item.DetailDiscount, string(*item.DetailDiscountType))
+	}
+	item.DetailDiscountAmount = discountAmount
+
+	// Calculate subtotal amount
+	item.DetailTotalAmount = detailAmount.Sub(item.DetailDiscountAmount)
+
+	taxAmount := decimal.NewFromFloat(0)
+	if item.DetailTaxId > 0 {
+		isGroup := item.DetailTaxType != nil && *item.DetailTaxType == TaxTypeGroup
+		taxAmount = utils.CalculateTaxAmount(ctx, db, item.DetailTaxId, isGroup, item.DetailTotalAmount, isTaxInclusive)
+	}
+	item.DetailTaxAmount = taxAmount
+}
+
+// calculateTotals recomputes line and header amounts the same way CreateSalesInvoice does,
+// so the profile shows the total each generated invoice will carry.
+func (ri *RecurringInvoice) calculateTotals(ctx context.Context) {
+	db := config.GetDB()
+	isTaxInclusive := ri.IsTaxInclusive != nil && *ri.IsTaxInclusive
+
+	var subtotal, totalExclusiveTaxAmount, totalDetailDiscountAmount, totalDetailTaxAmount decimal.Decimal
+	for i := range ri.Details {
+		item := &ri.Details[i]
+		item.CalculateRecurringItemDiscountAndTax(ctx, isTaxInclusive)
+		subtotal = subtotal.Add(item.DetailTotalAmount)
+		totalDetailDiscountAmount = totalDetailDiscountAmount.Add(item.DetailDiscountAmount)
+		totalDetailTaxAmount = totalDetailTaxAmount.Add(item.DetailTaxAmount)
+		if !isTaxInclusive {
+			totalExclusiveTaxAmount = totalExclusiveTaxAmount.Add(item.DetailTaxAmount)
+		}
+	}
+
+	var discountAmount decimal.Decimal
+	if ri.InvoiceDiscountType != nil {
+		discountAmount = utils.CalculateDiscountAmount(subtotal, ri.InvoiceDiscount, string(*ri.InvoiceDiscountType))
+	}
+
+	// invoice level tax is always exclusive
+	taxAmount := decimal.NewFromFloat(0)
+	if ri.InvoiceTaxId > 0 {
+		isGroup := ri.InvoiceTaxType != nil && *ri.InvoiceTaxType == TaxTypeGroup
+		taxAmount = utils.CalculateTaxAmount(ctx, db, ri.InvoiceTaxId, isGroup, subtotal, false)
+	}
+
+	ri.InvoiceSubtotal = subtotal
+	ri.InvoiceDiscountAmount = discountAmount
+	ri.InvoiceTaxAmount = taxAmount
+	ri.InvoiceTotalDiscountAmount = discountAmount.Add(totalDetailDiscountAmount)
+	ri.InvoiceTotalTaxAmount = taxAmount.Add(totalDetailTaxAmount)
+	ri.InvoiceTotalAmount = subtotal.Add(taxAmount).Add(totalExclusiveTaxAmount).Add(ri.AdjustmentAmount).Add(ri.ShippingCharges).Sub(discountAmount)
+}
+
+func (ri *RecurringInvoice) assign(input *NewRecurringInvoice, invoiceStatus SalesInvoiceStatus) {
+	ri.CustomerId = input.CustomerId
+	ri.BranchId = input.BranchId
+	ri.ProfileName = input.ProfileName
+	ri.RepeatTimes = input.RepeatTimes
+	ri.RepeatTerms = input.RepeatTerms
+	ri.StartDate = input.StartDate
+	ri.EndDate = input.EndDate
+	ri.IsNeverExpired = input.IsNeverExpired
+	ri.InvoicePaymentTerms = input.InvoicePaymentTerms
+	ri.InvoicePaymentTermsCustomDays = input.InvoicePaymentTermsCustomDays
+	ri.SalesPersonId = input.SalesPersonId
+	ri.InvoiceSubject = input.InvoiceSubject
+	ri.Notes = input.Notes
+	ri.TermsAndConditions = input.TermsAndConditions
+	ri.CurrencyId = input.CurrencyId
+	ri.WarehouseId = input.WarehouseId
+	ri.InvoiceDiscount = input.InvoiceDiscount
+	ri.InvoiceDiscountType = input.InvoiceDiscountType
+	ri.ShippingCharges = input.ShippingCharges
+	ri.AdjustmentAmount = input.AdjustmentAmount
+	ri.IsTaxInclusive = input.IsTaxInclusive
+	ri.InvoiceTaxId = input.InvoiceTaxId
+	ri.InvoiceTaxType = input.InvoiceTaxType
+	ri.InvoiceStatus = invoiceStatus
+
+	// existing lines keep their id so Replace updates them in place
+	details := make([]RecurringInvoiceDetail, 0, len(input.Details))
+	for _, item := range input.Details {
+		if item.IsDeletedItem != nil && *item.IsDeletedItem {
+			continue
+		}
+		details = append(details, RecurringInvoiceDetail{
+			ID:                 item.DetailId,
+			RecurringInvoiceId: ri.ID,
+			ProductId:          item.ProductId,
+			ProductType:        item.ProductType,
+			Name:               item.Name,
+			Description:        item.Description,
+			DetailAccountId:    item.DetailAccountId,
+			DetailQty:          item.DetailQty,
+			DetailUnitRate:     item.DetailUnitRate,
+			DetailTaxId:        item.DetailTaxId,
+			DetailTaxType:      item.DetailTaxType,
+			DetailDiscount:     item.DetailDiscount,
+			DetailDiscountType: item.DetailDiscountType,
+		})
+	}
+	ri.Details = details
+}
+
+func (ri RecurringInvoice) schedule() recurringSchedule {
+	return recurringSchedule{
+		ProfileType:        "RecurringInvoice",
+		ReferenceType:      "recurring_invoices",
+		DocumentName:       "SalesInvoice",
+		BusinessId:         ri.BusinessId,
+		StartDate:          ri.StartDate,
+		EndDate:            ri.EndDate,
+		IsNeverExpired:     ri.IsNeverExpired,
+		RepeatTerms:        ri.RepeatTerms,
+		RepeatTimes:        ri.RepeatTimes,
+		LastGeneratedDate:  ri.LastGeneratedDate,
+		NextOccurrenceDate: ri.NextOccurrenceDate,
+		FailedAttempts:     ri.FailedAttempts,
+		IsPaused:           ri.IsPaused,
+	}
+}
+
+// nextOccurrence returns the first occurrence after the last generated one, in the business timezone.
+func (ri RecurringInvoice) nextOccurrence(ctx context.Context) (*time.Time, error) {
+	return ri.schedule().next(ctx, ri.LastGeneratedDate)
+}
+
+func CreateRecurringInvoice(ctx context.Context, input *NewRecurringInvoice) (*RecurringInvoice, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	invoiceStatus, err := input.validate(ctx, businessId, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	recurringInvoice := RecurringInvoice{
+		BusinessId: businessId,
+		IsPaused:   utils.NewFalse(),
+	}
+	recurringInvoice.assign(input, invoiceStatus)
+	recurringInvoice.calculateTotals(ctx)
+
+	recurringInvoice.NextOccurrenceDate, err = recurringInvoice.nextOccurrence(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Create(&recurringInvoice).Error; err != nil {
+		return nil, err
+	}
+	return &recurringInvoice, nil
+}
+
+func UpdateRecurringInvoice(ctx context.Context, id int, input *NewRecurringInvoice) (*RecurringInvoice, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	invoiceStatus, err := input.validate(ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := utils.FetchModel[RecurringInvoice](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+	existing.assign(input, invoiceStatus)
+	existing.calculateTotals(ctx)
+
+	// schedule may have changed, recompute from the last generated occurrence
+	existing.NextOccurrenceDate, err = existing.nextOccurrence(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	details := existing.Details
+	existing.Details = nil
+
+	db := config.GetDB()
+	tx := db.Begin()
+	if err := tx.WithContext(ctx).Save(existing).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.WithContext(ctx).Model(existing).
+		Session(&gorm.Session{FullSaveAssociations: true, SkipHooks: true}).
+		Association("Details").
+		Unscoped().
+		Replace(&details); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	existing.Details = details
+	return existing, nil
+}
+
+func DeleteRecurringInvoice(ctx context.Context, id int) (*RecurringInvoice, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	result, err := utils.FetchModel[RecurringInvoice](ctx, businessId, id, "Details")
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	tx := db.Begin()
+	if err := tx.WithContext(ctx).Model(result).Association("Details").Unscoped().Clear(); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.WithContext(ctx).Delete(result).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// PauseRecurringInvoice stops the scheduler from generating invoices for the profile.
+func PauseRecurringInvoice(ctx context.Context, id int) (*RecurringInvoice, error) {
+	return setRecurringProfilePaused[RecurringInvoice](ctx, id, true)
+}
+
+// ResumeRecurringInvoice continues the profile from its next occurrence after today;
+// occurrences missed while paused are not generated.
+func ResumeRecurringInvoice(ctx context.Context, id int) (*RecurringInvoice, error) {
+	return setRecurringProfilePaused[RecurringInvoice](ctx, id, false)
+}
+
+func GetRecurringInvoice(ctx context.Context, id int) (*RecurringInvoice, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	return utils.FetchModel[RecurringInvoice](ctx, businessId, id)
+}
+
+func PaginateRecurringInvoice(ctx context.Context, limit *int, after *string, name *string, customerId *int) (*RecurringInvoicesConnection, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	db := config.GetDB()
+	dbCtx := db.WithContext(ctx).Where("business_id = ?", businessId)
+	if name != nil && *name != "" {
+		dbCtx.Where("profile_name LIKE ?", "%"+*name+"%")
+	}
+	if customerId != nil && *customerId > 0 {
+		dbCtx.Where("customer_id = ?", *customerId)
+	}
+
+	edges, pageInfo, err := FetchPageCompositeCursor[RecurringInvoice](dbCtx, *limit, after, "created_at", "<")
+	if err != nil {
+		return nil, err
+	}
+	var recurringInvoicesConnection RecurringInvoicesConnection
+	recurringInvoicesConnection.PageInfo = pageInfo
+	for _, edge := range edges {
+		recurringInvoicesEdge := RecurringInvoicesEdge(edge)
+		recurringInvoicesConnection.Edges = append(recurringInvoicesConnection.Edges, &recurringInvoicesEdge)
+	}
+
+	return &recurringInvoicesConnection, err
+}
+
+// GetDueRecurringInvoices returns profiles (across all businesses) whose next occurrence is due,
+// leaving out those waiting to retry a failed generation.
+func GetDueRecurringInvoices(ctx context.Context, now time.Time, limit int) ([]*RecurringInvoice, error) {
+	return getDueRecurringProfiles[RecurringInvoice](ctx, now, limit)
+}
+
+// GenerateRecurringInvoices creates sales invoices for every occurrence of the profile due at `now`.
+// Returns the number of invoices created.
+func GenerateRecurringInvoices(ctx context.Context, profileId int, now time.Time) (int, error) {
+	return generateRecurringProfile[RecurringInvoice](ctx, profileId, now, "Details")
+}
+
+func (ri RecurringInvoice) generatedId(ctx context.Context, occurrence time.Time) (int, error) {
+	var invoice SalesInvoice
+	err := config.GetDB().WithContext(ctx).
+		Where("business_id = ? AND recurring_invoice_id = ? AND invoice_date = ?", ri.BusinessId, ri.ID, occurrence).
+		Select("id").Limit(1).Find(&invoice).Error
+	return invoice.ID, err
+}
+
+func (ri RecurringInvoice) generate(ctx context.Context, occurrence time.Time) (int, string, error) {
+	input, err := ri.toNewSalesInvoice(ctx, occurrence)
+	if err != nil {
+		return 0, "", err
+	}
+	invoice, err := CreateSalesInvoice(ctx, input)
+	if err != nil {
+		return 0, "", err
+	}
+	return invoice.ID, invoice.InvoiceNumber, nil
+}
+
+func (ri RecurringInvoice) toNewSalesInvoice(ctx context.Context, invoiceDate time.Time) (*NewSalesInvoice, error) {
+	exchangeRate, err := GetExchangeRateAsOf(ctx, ri.BusinessId, ri.CurrencyId, invoiceDate)
+	if err != nil {
+		return nil, err
+	}
+
+	details := make([]NewSalesInvoiceDetail, 0, len(ri.Details))
+	for _, item := range ri.Details {
+		details = append(details, NewSalesInvoiceDetail{
+			ProductId:          item.ProductId,
+			ProductType:        item.ProductType,
+			Name:               item.Name,
+			Description:        item.Description,
+			DetailAccountId:    item.DetailAccountId,
+			DetailQty:          item.DetailQty,
+			DetailUnitRate:     item.DetailUnitRate,
+			DetailTaxId:        item.DetailTaxId,
+			DetailTaxType:      item.DetailTaxType,
+			DetailDiscount:     item.DetailDiscount,
+			DetailDiscountType: item.DetailDiscountType,
+		})
+	}
+
+	return &NewSalesInvoice{
+		CustomerId:                    ri.CustomerId,
+		BranchId:                      ri.BranchId,
+		InvoiceDate:                   invoiceDate,
+		InvoicePaymentTerms:           ri.InvoicePaymentTerms,
+		InvoicePaymentTermsCustomDays: ri.InvoicePaymentTermsCustomDays,
+		SalesPersonId:                 ri.SalesPersonId,
+		InvoiceSubject:                ri.InvoiceSubject,
+		Notes:                         ri.Notes,
+		TermsAndConditions:            ri.TermsAndConditions,
+		CurrencyId:                    ri.CurrencyId,
+		ExchangeRate:                  exchangeRate,
+		WarehouseId:                   ri.WarehouseId,
+		InvoiceDiscount:               ri.InvoiceDiscount,
+		InvoiceDiscountType:           ri.InvoiceDiscountType,
+		ShippingCharges:               ri.ShippingCharges,
+		AdjustmentAmount:              ri.AdjustmentAmount,
+		IsTaxInclusive:                ri.IsTaxInclusive,
+		InvoiceTaxId:                  ri.InvoiceTaxId,
+		InvoiceTaxType:                ri.InvoiceTaxType,
+		CurrentStatus:                 ri.InvoiceStatus,
+		RecurringInvoiceId:            ri.ID,
+		Details:                       details,
+	}, nil
+}
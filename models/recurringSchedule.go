@@ -0,0 +1,486 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	mysqlDriver "github.com/go-sql-driver/mysql"
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/utils"
+	"gorm.io/gorm"
+)
+
+type RecurringRunStatus string
+
+const (
+	RecurringRunStatusStarted   RecurringRunStatus = "STARTED"
+	RecurringRunStatusSucceeded RecurringRunStatus = "SUCCEEDED"
+	RecurringRunStatusFailed    RecurringRunStatus = "FAILED"
+)
+
+// maximum occurrences generated for one profile in a single scheduler pass,
+// so a long outage catches up gradually instead of blocking the tick
+const recurringMaxCatchUp = 12
+
+// a STARTED run older than this is considered abandoned (process crashed mid-generation)
+const recurringRunStaleAfter = 10 * time.Minute
+
+// a profile whose generation failed waits before the next attempt, twice as long after each
+// consecutive failure up to a day, so it does not keep the due profiles behind it waiting
+const (
+	recurringRetryDelay    = 5 * time.Minute
+	recurringMaxRetryDelay = 24 * time.Hour
+)
+
+// RecurringRun records one generated occurrence of a recurring profile.
+// Unique constraint: (profile_type, profile_id, occurrence_date) guarantees
+// an occurrence is generated at most once across restarts and instances.
+type RecurringRun struct {
+	ID             int                `gorm:"primary_key" json:"id"`
+	BusinessId     string             `gorm:"size:64;index;not null" json:"business_id"`
+	ProfileType    string             `gorm:"size:50;not null;index:uniq_recurring_run,unique" json:"profile_type"`
+	ProfileId      int                `gorm:"not null;index:uniq_recurring_run,unique" json:"profile_id"`
+	OccurrenceDate time.Time          `gorm:"not null;index:uniq_recurring_run,unique" json:"occurrence_date"`
+	ReferenceId    int                `gorm:"default:0" json:"reference_id"`
+	Status         RecurringRunStatus `gorm:"size:20;not null;index" json:"status"`
+	LastError      *string            `gorm:"type:text" json:"last_error"`
+	CreatedAt      time.Time          `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time          `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func isDuplicateKeyError(err error) bool {
+	var mysqlErr *mysqlDriver.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == 1062
+	}
+	return false
+}
+
+// addRecurringPeriods adds n periods of the given terms to date.
+// Monthly/yearly steps are clamped to the last day of the month (Jan 31 -> Feb 28 -> Mar 31),
+// always counting from the original date so the day does not drift.
+func addRecurringPeriods(date time.Time, terms RecurringTerms, n int) time.Time {
+	switch terms {
+	case RecurringTermsDay:
+		return date.AddDate(0, 0, n)
+	case RecurringTermsWeek:
+		return date.AddDate(0, 0, 7*n)
+	case RecurringTermsMonth, RecurringTermsYear:
+		months := n
+		if terms == RecurringTermsYear {
+			months = 12 * n
+		}
+		firstOfMonth := time.Date(date.Year(), date.Month(), 1, date.Hour(), date.Minute(), date.Second(), date.Nanosecond(), date.Location())
+		target := firstOfMonth.AddDate(0, months, 0)
+		lastDay := target.AddDate(0, 1, -1).Day()
+		day := date.Day()
+		if day > lastDay {
+			day = lastDay
+		}
+		return time.Date(target.Year(), target.Month(), day, date.Hour(), date.Minute(), date.Second(), date.Nanosecond(), date.Location())
+	}
+	return date
+}
+
+// RecurringOccurrence returns the k-th (zero based) occurrence of a schedule,
+// computed in the business timezone so month/day boundaries follow local dates.
+func RecurringOccurrence(startDate time.Time, terms RecurringTerms, every int, k int, timezone string) time.Time {
+	if every <= 0 {
+		every = 1
+	}
+	if timezone == "" {
+		timezone = "Asia/Yangon"
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	return addRecurringPeriods(startDate.In(loc), terms, every*k).UTC()
+}
+
+// NextRecurringDate returns the first occurrence strictly after `after`
+// (or the start date itself when nothing was generated yet).
+// Returns nil when the schedule has ended.
+func NextRecurringDate(startDate time.Time, endDate *time.Time, isNeverExpired *bool, terms RecurringTerms, every int, after *time.Time, timezone string) *time.Time {
+	for k := 0; ; k++ {
+		occurrence := RecurringOccurrence(startDate, terms, every, k, timezone)
+		if endDate != nil && (isNeverExpired == nil || !*isNeverExpired) && occurrence.After(*endDate) {
+			return nil
+		}
+		if after == nil || occurrence.After(*after) {
+			return &occurrence
+		}
+		// guard against malformed schedules (e.g. unknown terms never advancing)
+		if k > 100000 {
+			return nil
+		}
+	}
+}
+
+// recurringClaim is the outcome of claiming an occurrence in RecurringRun.
+type recurringClaim int
+
+const (
+	recurringClaimed   recurringClaim = iota // this instance generates the occurrence
+	recurringCompleted                       // the occurrence was already generated
+	recurringHeld                            // another instance is generating it right now
+)
+
+// beginRecurringRun claims an occurrence for generation.
+// When a stale STARTED run is found, `exists` is asked whether the document was actually created
+// before the crash, and the run is completed instead of generating a duplicate.
+func beginRecurringRun(ctx context.Context, businessId string, profileType string, profileId int, occurrence time.Time, exists func() (int, error)) (*RecurringRun, recurringClaim, error) {
+	db := config.GetDB()
+	run := &RecurringRun{
+		BusinessId:     businessId,
+		ProfileType:    profileType,
+		ProfileId:      profileId,
+		OccurrenceDate: occurrence,
+		Status:         RecurringRunStatusStarted,
+	}
+	if err := db.WithContext(ctx).Create(run).Error; err == nil {
+		return run, recurringClaimed, nil
+	} else if !isDuplicateKeyError(err) {
+		return nil, recurringClaimed, err
+	}
+
+	var existing RecurringRun
+	if err := db.WithContext(ctx).
+		Where("profile_type = ? AND profile_id = ? AND occurrence_date = ?", profileType, profileId, occurrence).
+		First(&existing).Error; err != nil {
+		return nil, recurringClaimed, err
+	}
+
+	switch existing.Status {
+	case RecurringRunStatusSucceeded:
+		return &existing, recurringCompleted, nil
+	case RecurringRunStatusStarted:
+		if time.Since(existing.UpdatedAt) < recurringRunStaleAfter {
+			return &existing, recurringHeld, nil
+		}
+		referenceId, err := exists()
+		if err != nil {
+			return nil, recurringClaimed, err
+		}
+		if referenceId > 0 {
+			if err := completeRecurringRun(ctx, &existing, referenceId); err != nil {
+				return nil, recurringClaimed, err
+			}
+			return &existing, recurringCompleted, nil
+		}
+	}
+
+	// FAILED or abandoned STARTED: take it over
+	if err := db.WithContext(ctx).Model(&RecurringRun{}).Where("id = ?", existing.ID).
+		Updates(map[string]interface{}{"status": RecurringRunStatusStarted, "last_error": nil}).Error; err != nil {
+		return nil, recurringClaimed, err
+	}
+	existing.Status = RecurringRunStatusStarted
+	return &existing, recurringClaimed, nil
+}
+
+func completeRecurringRun(ctx context.Context, run *RecurringRun, referenceId int) error {
+	db := config.GetDB()
+	run.Status = RecurringRunStatusSucceeded
+	run.ReferenceId = referenceId
+	return db.WithContext(ctx).Model(&RecurringRun{}).Where("id = ?", run.ID).
+		Updates(map[string]interface{}{"status": RecurringRunStatusSucceeded, "reference_id": referenceId, "last_error": nil}).Error
+}
+
+func failRecurringRun(ctx context.Context, run *RecurringRun, cause error) error {
+	db := config.GetDB()
+	msg := cause.Error()
+	return db.WithContext(ctx).Model(&RecurringRun{}).Where("id = ?", run.ID).
+		Updates(map[string]interface{}{"status": RecurringRunStatusFailed, "last_error": &msg}).Error
+}
+
+// advanceRecurringProfile stores the last generated date and the next due date on a profile table,
+// clearing any failed attempts. UpdateColumns skips model hooks so scheduler progress does not
+// flood History.
+func advanceRecurringProfile(tx *gorm.DB, model interface{}, id int, lastGenerated time.Time, next *time.Time) error {
+	return tx.Model(model).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"last_generated_date":  lastGenerated,
+		"next_occurrence_date": next,
+		"failed_attempts":      0,
+		"next_attempt_date":    nil,
+	}).Error
+}
+
+// deferRecurringProfile records a failed generation on a profile table and when to try again.
+func deferRecurringProfile(tx *gorm.DB, model interface{}, id int, failedAttempts int, now time.Time) error {
+	return tx.Model(model).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"failed_attempts":   failedAttempts,
+		"next_attempt_date": now.Add(RecurringRetryDelay(failedAttempts)),
+	}).Error
+}
+
+// RecurringRetryDelay is how long a profile waits after its n-th consecutive failed generation.
+func RecurringRetryDelay(failedAttempts int) time.Duration {
+	delay := recurringRetryDelay
+	for i := 1; i < failedAttempts && delay < recurringMaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > recurringMaxRetryDelay {
+		delay = recurringMaxRetryDelay
+	}
+	return delay
+}
+
+// recurringSchedule is the schedule and scheduler state every recurring profile keeps.
+type recurringSchedule struct {
+	ProfileType        string // RecurringRun.ProfileType
+	ReferenceType      string // History reference type of the profile
+	DocumentName       string // what a generated document is called in History
+	BusinessId         string
+	StartDate          time.Time
+	EndDate            *time.Time
+	IsNeverExpired     *bool
+	RepeatTerms        RecurringTerms
+	RepeatTimes        int
+	LastGeneratedDate  *time.Time
+	NextOccurrenceDate *time.Time
+	FailedAttempts     int
+	IsPaused           *bool
+}
+
+// next returns the first occurrence after `after`, in the business timezone.
+func (s recurringSchedule) next(ctx context.Context, after *time.Time) (*time.Time, error) {
+	business, err := GetBusinessById(ctx, s.BusinessId)
+	if err != nil {
+		return nil, err
+	}
+	return NextRecurringDate(s.StartDate, s.EndDate, s.IsNeverExpired, s.RepeatTerms, s.RepeatTimes, after, business.Timezone), nil
+}
+
+// recurringProfile is a recurring bill, invoice or expense profile as the scheduler sees it.
+type recurringProfile interface {
+	GetId() int
+	schedule() recurringSchedule
+	// generatedId finds the document an interrupted generation already created for the occurrence
+	generatedId(ctx context.Context, occurrence time.Time) (int, error)
+	// generate creates the document of one occurrence, returning its id and number
+	generate(ctx context.Context, occurrence time.Time) (int, string, error)
+}
+
+// getDueRecurringProfiles returns profiles (across all businesses) whose next occurrence is due,
+// leaving out those waiting to retry a failed generation.
+func getDueRecurringProfiles[T any](ctx context.Context, now time.Time, limit int) ([]*T, error) {
+	db := config.GetDB()
+	var results []*T
+	err := db.WithContext(ctx).
+		Where("is_paused = ? AND next_occurrence_date IS NOT NULL AND next_occurrence_date <= ?", false, now).
+		Where("next_attempt_date IS NULL OR next_attempt_date <= ?", now).
+		Order("next_occurrence_date").
+		Limit(limit).
+		Find(&results).Error
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// generateRecurringProfile creates the documents of every occurrence of the profile due at `now`.
+// Each occurrence is claimed in RecurringRun first, so restarts and concurrent schedulers never
+// create the same document twice. A failed generation defers the profile's next attempt.
+// The schedule only moves past an occurrence once it has been generated: an occurrence another
+// instance is generating right now is left for that instance, which advances the schedule when
+// it succeeds, or for a later pass when it fails. Returns the number of documents created.
+func generateRecurringProfile[T any, P interface {
+	*T
+	recurringProfile
+}](ctx context.Context, profileId int, now time.Time, associations ...string) (int, error) {
+	db := config.GetDB()
+
+	var profile T
+	dbCtx := db.WithContext(ctx)
+	for _, association := range associations {
+		dbCtx = dbCtx.Preload(association)
+	}
+	if err := dbCtx.First(&profile, profileId).Error; err != nil {
+		return 0, err
+	}
+	s := P(&profile).schedule()
+	if s.IsPaused != nil && *s.IsPaused {
+		return 0, nil
+	}
+
+	created := 0
+	for i := 0; i < recurringMaxCatchUp; i++ {
+		occurrence := s.NextOccurrenceDate
+		if occurrence == nil || occurrence.After(now) {
+			break
+		}
+
+		documentId, claim, err := generateRecurringOccurrence(ctx, P(&profile), s, *occurrence)
+		if err != nil {
+			if derr := deferRecurringProfile(db.WithContext(ctx), new(T), profileId, s.FailedAttempts+1, now); derr != nil {
+				return created, derr
+			}
+			return created, err
+		}
+		if claim == recurringHeld {
+			break
+		}
+		if documentId > 0 {
+			created++
+		}
+
+		s.LastGeneratedDate = occurrence
+		s.NextOccurrenceDate, err = s.next(ctx, occurrence)
+		if err != nil {
+			return created, err
+		}
+		if err := advanceRecurringProfile(db.WithContext(ctx), new(T), profileId, *s.LastGeneratedDate, s.NextOccurrenceDate); err != nil {
+			return created, err
+		}
+		s.FailedAttempts = 0
+	}
+	return created, nil
+}
+
+// generateRecurringOccurrence generates one occurrence unless its claim shows it was already
+// generated or is held by another instance. Returns the new document's id, 0 when none was created.
+func generateRecurringOccurrence(ctx context.Context, profile recurringProfile, s recurringSchedule, occurrence time.Time) (int, recurringClaim, error) {
+	run, claim, err := beginRecurringRun(ctx, s.BusinessId, s.ProfileType, profile.GetId(), occurrence, func() (int, error) {
+		return profile.generatedId(ctx, occurrence)
+	})
+	if err != nil || claim != recurringClaimed {
+		return 0, claim, err
+	}
+
+	documentId, documentNumber, err := profile.generate(ctx, occurrence)
+	if err != nil {
+		if ferr := failRecurringRun(ctx, run, err); ferr != nil {
+			return 0, claim, ferr
+		}
+		return 0, claim, err
+	}
+	if err := completeRecurringRun(ctx, run, documentId); err != nil {
+		return 0, claim, err
+	}
+	return documentId, claim, saveRecurringGeneratedHistory(ctx, s.ReferenceType, profile.GetId(), "Generated "+s.DocumentName+" "+documentNumber)
+}
+
+// setRecurringProfilePaused pauses or resumes a profile. A resumed profile continues from its
+// next occurrence after now; occurrences missed while paused are not generated.
+func setRecurringProfilePaused[T any, P interface {
+	*T
+	recurringProfile
+}](ctx context.Context, id int, paused bool) (*T, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	result, err := utils.FetchModel[T](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+	s := P(result).schedule()
+	if s.IsPaused != nil && *s.IsPaused == paused {
+		return result, nil
+	}
+
+	updates := map[string]interface{}{"IsPaused": paused}
+	if !paused {
+		next, err := s.next(ctx, recurringResumeAfter(s.LastGeneratedDate, time.Now().UTC()))
+		if err != nil {
+			return nil, err
+		}
+		updates["NextOccurrenceDate"] = next
+		updates["FailedAttempts"] = 0
+		updates["NextAttemptDate"] = nil
+	}
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Model(result).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	return utils.FetchModel[T](ctx, businessId, id)
+}
+
+// RecurringSchedulePreview describes a schedule that has not necessarily been saved yet,
+// so the UI can show upcoming dates while a profile is being edited.
+type RecurringSchedulePreview struct {
+	StartDate      time.Time      `json:"start_date"`
+	EndDate        *time.Time     `json:"end_date"`
+	IsNeverExpired *bool          `json:"is_never_expired"`
+	RepeatTerms    RecurringTerms `json:"repeat_terms"`
+	RepeatTimes    int            `json:"repeat_times"`
+	After          *time.Time     `json:"after"`
+}
+
+// maximum dates returned by a schedule preview
+const recurringMaxPreview = 60
+
+// PreviewRecurringDates returns up to count upcoming occurrences of the schedule.
+func PreviewRecurringDates(ctx context.Context, input *RecurringSchedulePreview, count int) ([]time.Time, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	business, err := GetBusinessById(ctx, businessId)
+	if err != nil {
+		return nil, err
+	}
+	if count <= 0 {
+		count = 5
+	}
+	if count > recurringMaxPreview {
+		count = recurringMaxPreview
+	}
+
+	results := make([]time.Time, 0, count)
+	after := input.After
+	for len(results) < count {
+		next := NextRecurringDate(input.StartDate, input.EndDate, input.IsNeverExpired, input.RepeatTerms, input.RepeatTimes, after, business.Timezone)
+		if next == nil {
+			break
+		}
+		results = append(results, *next)
+		after = next
+	}
+	return results, nil
+}
+
+func validateRecurringSchedule(startDate time.Time, endDate *time.Time, terms RecurringTerms, every int) error {
+	if every <= 0 {
+		return errors.New("repeat times must be greater than zero")
+	}
+	switch terms {
+	case RecurringTermsDay, RecurringTermsWeek, RecurringTermsMonth, RecurringTermsYear:
+	default:
+		return errors.New("invalid repeat terms")
+	}
+	if endDate != nil && endDate.Before(startDate) {
+		return errors.New("end date must be after start date")
+	}
+	return nil
+}
+
+// recurringResumeAfter is the point a resumed profile continues from:
+// occurrences that fell due while it was paused are skipped, not back-filled.
+func recurringResumeAfter(lastGenerated *time.Time, now time.Time) *time.Time {
+	if lastGenerated != nil && lastGenerated.After(now) {
+		return lastGenerated
+	}
+	return &now
+}
+
+// saveRecurringGeneratedHistory records on the profile which document an occurrence produced.
+func saveRecurringGeneratedHistory(ctx context.Context, referenceType string, profileId int, description string) error {
+	return createHistory(config.GetDB().WithContext(ctx), "GENERATE", profileId, referenceType, nil, nil, description)
+}
+
+func describeRecurringUpdate(tx *gorm.DB, typename string) string {
+	if tx.Statement.Changed("IsPaused") {
+		if fields, ok := tx.Statement.Dest.(map[string]interface{}); ok {
+			if paused, ok := fields["IsPaused"].(bool); ok && paused {
+				return "Paused " + typename
+			}
+			return "Resumed " + typename
+		}
+	}
+	return "Updated " + typename
+}
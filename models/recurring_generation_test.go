@@ -0,0 +1,324 @@
+package models_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+)
+
+func setupRecurringBusiness(t *testing.T) (context.Context, *models.Business, map[string]int) {
+	if strings.TrimSpace(os.Getenv("INTEGRATION_TESTS")) == "" {
+		t.Skip("set INTEGRATION_TESTS=1 to run integration tests (requires docker)")
+	}
+
+	ctx := context.Background()
+
+	redisName, redisPort := startRedisContainer(t)
+	t.Cleanup(func() { _ = dockerRmForce(redisName) })
+
+	mysqlName, mysqlPort := startMySQLContainer(t)
+	t.Cleanup(func() { _ = dockerRmForce(mysqlName) })
+
+	t.Setenv("REDIS_ADDRESS", fmt.Sprintf("127.0.0.1:%s", redisPort))
+	t.Setenv("DB_USER", "root")
+	t.Setenv("DB_PASSWORD", "testpw")
+	t.Setenv("DB_HOST", "127.0.0.1")
+	t.Setenv("DB_PORT", mysqlPort)
+	t.Setenv("DB_NAME_2", "pitibooks_test")
+	t.Setenv("STOCK_COMMANDS_DOCS", "")
+
+	config.ConnectDatabaseWithRetry()
+	config.ConnectRedisWithRetry()
+	models.MigrateTable()
+
+	ctx = utils.SetUserIdInContext(ctx, 1)
+	ctx = utils.SetUserNameInContext(ctx, "Test")
+	ctx = utils.SetUsernameInContext(ctx, "test@local")
+
+	biz, err := models.CreateBusiness(ctx, &models.NewBusiness{
+		Name:  "Recurring Co",
+		Email: "owner@recurring.test",
+	})
+	if err != nil {
+		t.Fatalf("CreateBusiness: %v", err)
+	}
+	businessID := biz.ID.String()
+	ctx = utils.SetBusinessIdInContext(ctx, businessID)
+
+	relaxDate := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := config.GetDB().WithContext(ctx).Model(&models.Business{}).Where("id = ?", biz.ID).Updates(map[string]interface{}{
+		"MigrationDate":                 relaxDate,
+		"SalesTransactionLockDate":      relaxDate,
+		"PurchaseTransactionLockDate":   relaxDate,
+		"BankingTransactionLockDate":    relaxDate,
+		"AccountantTransactionLockDate": relaxDate,
+	}).Error; err != nil {
+		t.Fatalf("relax business lock dates: %v", err)
+	}
+
+	accs, err := models.GetSystemAccounts(businessID)
+	if err != nil {
+		t.Fatalf("GetSystemAccounts: %v", err)
+	}
+	return ctx, biz, accs
+}
+
+// checkRecurringGeneration generates the due occurrence once, then checks that a second pass
+// generates nothing, a paused profile generates nothing, and a resumed profile continues
+// after now rather than catching up.
+func checkRecurringGeneration(t *testing.T, generate func(now time.Time) (int, error),
+	pause func() (*bool, error), resume func() (*bool, *time.Time, error)) {
+	t.Helper()
+	now := time.Now().UTC()
+
+	created, err := generate(now)
+	if err != nil || created != 1 {
+		t.Fatalf("first generation = %d, %v; want 1", created, err)
+	}
+	if created, err := generate(now); err != nil || created != 0 {
+		t.Fatalf("second generation = %d, %v; want 0", created, err)
+	}
+
+	paused, err := pause()
+	if err != nil || paused == nil || !*paused {
+		t.Fatalf("pause = %v, %v", paused, err)
+	}
+	if created, err := generate(now.AddDate(0, 3, 0)); err != nil || created != 0 {
+		t.Fatalf("generation while paused = %d, %v; want 0", created, err)
+	}
+
+	paused, next, err := resume()
+	if err != nil || paused == nil || *paused {
+		t.Fatalf("resume = %v, %v", paused, err)
+	}
+	if next == nil || !next.After(now) {
+		t.Fatalf("resumed next occurrence = %v, want after %v", next, now)
+	}
+}
+
+func createTestRecurringBill(t *testing.T, ctx context.Context, biz *models.Business, accs map[string]int) *models.RecurringBill {
+	t.Helper()
+	supplier, err := models.CreateSupplier(ctx, &models.NewSupplier{
+		Name:                 "Landlord",
+		Email:                "landlord@recurring.test",
+		CurrencyId:           biz.BaseCurrencyId,
+		ExchangeRate:         decimal.NewFromInt(1),
+		SupplierPaymentTerms: models.PaymentTermsDueOnReceipt,
+	})
+	if err != nil {
+		t.Fatalf("CreateSupplier: %v", err)
+	}
+	isTaxInclusive := false
+	profile, err := models.CreateRecurringBill(ctx, &models.NewRecurringBill{
+		SupplierId:       supplier.ID,
+		BranchId:         biz.PrimaryBranchId,
+		ProfileName:      "Rent",
+		RepeatTimes:      1,
+		RepeatTerms:      models.RecurringTermsMonth,
+		StartDate:        time.Now().UTC().AddDate(0, 0, -1),
+		IsNeverExpired:   utils.NewTrue(),
+		BillPaymentTerms: models.PaymentTermsDueOnReceipt,
+		CurrencyId:       biz.BaseCurrencyId,
+		IsTaxInclusive:   &isTaxInclusive,
+		Details: []models.NewRecurringBillDetail{{
+			Name:            "Office rent",
+			DetailAccountId: accs[models.AccountCodeOtherExpenses],
+			DetailQty:       decimal.NewFromInt(1),
+			DetailUnitRate:  decimal.NewFromInt(500000),
+		}},
+	})
+	if err != nil {
+		t.Fatalf("CreateRecurringBill: %v", err)
+	}
+	return profile
+}
+
+func TestRecurringBill_GenerateAndPause(t *testing.T) {
+	ctx, biz, accs := setupRecurringBusiness(t)
+	profile := createTestRecurringBill(t, ctx, biz, accs)
+
+	checkRecurringGeneration(t,
+		func(now time.Time) (int, error) { return models.GenerateRecurringBills(ctx, profile.ID, now) },
+		func() (*bool, error) {
+			p, err := models.PauseRecurringBill(ctx, profile.ID)
+			if err != nil {
+				return nil, err
+			}
+			return p.IsPaused, nil
+		},
+		func() (*bool, *time.Time, error) {
+			p, err := models.ResumeRecurringBill(ctx, profile.ID)
+			if err != nil {
+				return nil, nil, err
+			}
+			return p.IsPaused, p.NextOccurrenceDate, nil
+		})
+
+	var count int64
+	if err := config.GetDB().WithContext(ctx).Model(&models.Bill{}).Where("recurring_bill_id = ?", profile.ID).Count(&count).Error; err != nil {
+		t.Fatalf("count bills: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("generated bills = %d, want 1", count)
+	}
+}
+
+// An occurrence another instance is generating is left to it: the schedule does not move
+// past it until it has been generated, here by a later pass once the claim failed.
+func TestRecurringBill_HeldClaimKeepsSchedule(t *testing.T) {
+	ctx, biz, accs := setupRecurringBusiness(t)
+	profile := createTestRecurringBill(t, ctx, biz, accs)
+	db := config.GetDB()
+	occurrence := *profile.NextOccurrenceDate
+
+	run := models.RecurringRun{
+		BusinessId:     biz.ID.String(),
+		ProfileType:    "RecurringBill",
+		ProfileId:      profile.ID,
+		OccurrenceDate: occurrence,
+		Status:         models.RecurringRunStatusStarted,
+	}
+	if err := db.WithContext(ctx).Create(&run).Error; err != nil {
+		t.Fatalf("create run: %v", err)
+	}
+
+	now := time.Now().UTC()
+	if created, err := models.GenerateRecurringBills(ctx, profile.ID, now); err != nil || created != 0 {
+		t.Fatalf("generate with held claim = %d, %v; want 0, nil", created, err)
+	}
+	var held models.RecurringBill
+	if err := db.WithContext(ctx).First(&held, profile.ID).Error; err != nil {
+		t.Fatalf("fetch profile: %v", err)
+	}
+	if held.NextOccurrenceDate == nil || !held.NextOccurrenceDate.Equal(occurrence) {
+		t.Fatalf("next occurrence = %v, want %v kept while the claim is held", held.NextOccurrenceDate, occurrence)
+	}
+
+	if err := db.WithContext(ctx).Model(&run).Update("status", models.RecurringRunStatusFailed).Error; err != nil {
+		t.Fatalf("fail run: %v", err)
+	}
+	if created, err := models.GenerateRecurringBills(ctx, profile.ID, now); err != nil || created != 1 {
+		t.Fatalf("generate after failed claim = %d, %v; want 1, nil", created, err)
+	}
+	var advanced models.RecurringBill
+	if err := db.WithContext(ctx).First(&advanced, profile.ID).Error; err != nil {
+		t.Fatalf("fetch profile: %v", err)
+	}
+	if advanced.NextOccurrenceDate == nil || !advanced.NextOccurrenceDate.After(occurrence) {
+		t.Fatalf("next occurrence = %v, want after %v once generated", advanced.NextOccurrenceDate, occurrence)
+	}
+}
+
+func TestRecurringInvoice_GenerateAndPause(t *testing.T) {
+	ctx, biz, accs := setupRecurringBusiness(t)
+
+	customer, err := models.CreateCustomer(ctx, &models.NewCustomer{Name: "Tenant"})
+	if err != nil {
+		t.Fatalf("CreateCustomer: %v", err)
+	}
+	var primary models.Warehouse
+	if err := config.GetDB().WithContext(ctx).Where("business_id = ? AND name = ?", biz.ID.String(), "Primary Warehouse").First(&primary).Error; err != nil {
+		t.Fatalf("fetch primary warehouse: %v", err)
+	}
+	profile, err := models.CreateRecurringInvoice(ctx, &models.NewRecurringInvoice{
+		CustomerId:          customer.ID,
+		BranchId:            biz.PrimaryBranchId,
+		ProfileName:         "Service fee",
+		RepeatTimes:         1,
+		RepeatTerms:         models.RecurringTermsMonth,
+		StartDate:           time.Now().UTC().AddDate(0, 0, -1),
+		IsNeverExpired:      utils.NewTrue(),
+		InvoicePaymentTerms: models.PaymentTermsDueOnReceipt,
+		CurrencyId:          biz.BaseCurrencyId,
+		WarehouseId:         primary.ID,
+		IsTaxInclusive:      utils.NewFalse(),
+		Details: []models.NewRecurringInvoiceDetail{{
+			Name:            "Monthly service",
+			DetailAccountId: accs[models.AccountCodeSales],
+			DetailQty:       decimal.NewFromInt(1),
+			DetailUnitRate:  decimal.NewFromInt(300000),
+		}},
+	})
+	if err != nil {
+		t.Fatalf("CreateRecurringInvoice: %v", err)
+	}
+
+	checkRecurringGeneration(t,
+		func(now time.Time) (int, error) { return models.GenerateRecurringInvoices(ctx, profile.ID, now) },
+		func() (*bool, error) {
+			p, err := models.PauseRecurringInvoice(ctx, profile.ID)
+			if err != nil {
+				return nil, err
+			}
+			return p.IsPaused, nil
+		},
+		func() (*bool, *time.Time, error) {
+			p, err := models.ResumeRecurringInvoice(ctx, profile.ID)
+			if err != nil {
+				return nil, nil, err
+			}
+			return p.IsPaused, p.NextOccurrenceDate, nil
+		})
+
+	var count int64
+	if err := config.GetDB().WithContext(ctx).Model(&models.SalesInvoice{}).Where("recurring_invoice_id = ?", profile.ID).Count(&count).Error; err != nil {
+		t.Fatalf("count invoices: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("generated invoices = %d, want 1", count)
+	}
+}
+
+func TestRecurringExpense_GenerateAndPause(t *testing.T) {
+	ctx, biz, accs := setupRecurringBusiness(t)
+
+	profile, err := models.CreateRecurringExpense(ctx, &models.NewRecurringExpense{
+		ProfileName:      "Parking",
+		RepeatTimes:      1,
+		RepeatTerms:      models.RecurringTermsMonth,
+		StartDate:        time.Now().UTC().AddDate(0, 0, -1),
+		IsNeverExpired:   utils.NewTrue(),
+		ExpenseAccountId: accs[models.AccountCodeParking],
+		AssetAccountId:   accs[models.AccountCodePettyCash],
+		BranchId:         biz.PrimaryBranchId,
+		CurrencyId:       biz.BaseCurrencyId,
+		Amount:           decimal.NewFromInt(20000),
+		IsTaxInclusive:   utils.NewFalse(),
+	})
+	if err != nil {
+		t.Fatalf("CreateRecurringExpense: %v", err)
+	}
+
+	checkRecurringGeneration(t,
+		func(now time.Time) (int, error) { return models.GenerateRecurringExpenses(ctx, profile.ID, now) },
+		func() (*bool, error) {
+			p, err := models.PauseRecurringExpense(ctx, profile.ID)
+			if err != nil {
+				return nil, err
+			}
+			return p.IsPaused, nil
+		},
+		func() (*bool, *time.Time, error) {
+			p, err := models.ResumeRecurringExpense(ctx, profile.ID)
+			if err != nil {
+				return nil, nil, err
+			}
+			return p.IsPaused, p.NextOccurrenceDate, nil
+		})
+
+	var count int64
+	if err := config.GetDB().WithContext(ctx).Model(&models.Expense{}).Where("recurring_expense_id = ?", profile.ID).Count(&count).Error; err != nil {
+		t.Fatalf("count expenses: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("generated expenses = %d, want 1", count)
+	}
+}
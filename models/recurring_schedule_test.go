@@ -0,0 +1,69 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/models"
+)
+
+func TestRecurringOccurrenceClampsMonthEnd(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Yangon")
+	if err != nil {
+		t.Skip("tzdata not available")
+	}
+	start := time.Date(2024, time.January, 31, 0, 0, 0, 0, loc)
+
+	want := []string{"2024-01-31", "2024-02-29", "2024-03-31", "2024-04-30"}
+	for k, w := range want {
+		got := models.RecurringOccurrence(start, models.RecurringTermsMonth, 1, k, "Asia/Yangon").In(loc).Format("2006-01-02")
+		if got != w {
+			t.Fatalf("occurrence %d: got %s want %s", k, got, w)
+		}
+	}
+}
+
+func TestNextRecurringDate(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Yangon")
+	if err != nil {
+		t.Skip("tzdata not available")
+	}
+	start := time.Date(2024, time.January, 10, 0, 0, 0, 0, loc)
+	end := time.Date(2024, time.March, 10, 0, 0, 0, 0, loc)
+	notNever := false
+
+	first := models.NextRecurringDate(start, &end, &notNever, models.RecurringTermsMonth, 1, nil, "Asia/Yangon")
+	if first == nil || !first.Equal(start) {
+		t.Fatalf("first occurrence: got %v want %v", first, start)
+	}
+
+	// every 2 weeks
+	next := models.NextRecurringDate(start, nil, nil, models.RecurringTermsWeek, 2, first, "Asia/Yangon")
+	if next == nil || next.In(loc).Format("2006-01-02") != "2024-01-24" {
+		t.Fatalf("biweekly: got %v", next)
+	}
+
+	// past the end date the schedule stops, unless it never expires
+	last := end
+	if got := models.NextRecurringDate(start, &end, &notNever, models.RecurringTermsMonth, 1, &last, "Asia/Yangon"); got != nil {
+		t.Fatalf("expected schedule to end, got %v", got)
+	}
+	never := true
+	if got := models.NextRecurringDate(start, &end, &never, models.RecurringTermsMonth, 1, &last, "Asia/Yangon"); got == nil {
+		t.Fatalf("expected never-expiring schedule to continue")
+	}
+}
+
+func TestRecurringRetryDelay(t *testing.T) {
+	want := map[int]time.Duration{
+		1:  5 * time.Minute,
+		2:  10 * time.Minute,
+		4:  40 * time.Minute,
+		20: 24 * time.Hour,
+	}
+	for attempts, w := range want {
+		if got := models.RecurringRetryDelay(attempts); got != w {
+			t.Errorf("retry delay after %d failures: got %v want %v", attempts, got, w)
+		}
+	}
+}
@@ -0,0 +1,349 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+)
+
+// ReorderPoint is the minimum stock of a product or variant in a warehouse. When the stock on
+// hand plus what is still on order falls to ReorderLevel, ReorderQty is suggested for purchase
+// from SupplierId, or from the product's own supplier when it is 0.
+type ReorderPoint struct {
+	ID           int             `gorm:"primary_key" json:"id"`
+	BusinessId   string          `gorm:"index;not null" json:"business_id"`
+	ProductId    int             `gorm:"index;not null" json:"product_id"`
+	ProductType  ProductType     `gorm:"type:enum('S','V');default:S;not null" json:"product_type"`
+	WarehouseId  int             `gorm:"index;not null" json:"warehouse_id"`
+	ReorderLevel decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"reorder_level"`
+	ReorderQty   decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"reorder_qty"`
+	SupplierId   int             `gorm:"default:0" json:"supplier_id"`
+	CreatedAt    time.Time       `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type NewReorderPoint struct {
+	WarehouseId  int             `json:"warehouse_id"`
+	ReorderLevel decimal.Decimal `json:"reorder_level"`
+	ReorderQty   decimal.Decimal `json:"reorder_qty"`
+	SupplierId   int             `json:"supplier_id"`
+}
+
+// LowStockResponse is a reorder point at or below its level. OnOrderQty is what open purchase
+// orders for the warehouse have not billed yet.
+type LowStockResponse struct {
+	ProductId     int             `json:"product_id"`
+	ProductType   ProductType     `json:"product_type"`
+	ProductName   string          `json:"product_name"`
+	ProductSku    string          `json:"product_sku"`
+	ProductUnitId int             `json:"product_unit_id"`
+	WarehouseId   int             `json:"warehouse_id"`
+	WarehouseName string          `json:"warehouse_name"`
+	SupplierId    int             `json:"supplier_id"`
+	StockOnHand   decimal.Decimal `json:"stock_on_hand"`
+	OnOrderQty    decimal.Decimal `json:"on_order_qty"`
+	ReorderLevel  decimal.Decimal `json:"reorder_level"`
+	ReorderQty    decimal.Decimal `json:"reorder_qty"`
+	SuggestedQty  decimal.Decimal `json:"suggested_qty"`
+}
+
+// SuggestedReorderQty returns what to order when stock plus open orders is at or below the
+// reorder level: the reorder quantity, or more when that would still leave stock under the
+// level. It returns zero when nothing needs ordering.
+func SuggestedReorderQty(stockOnHand decimal.Decimal, onOrderQty decimal.Decimal, reorderLevel decimal.Decimal, reorderQty decimal.Decimal) decimal.Decimal {
+	projected := stockOnHand.Add(onOrderQty)
+	if projected.GreaterThan(reorderLevel) {
+		return decimal.Zero
+	}
+	shortage := reorderLevel.Sub(projected)
+	if reorderQty.GreaterThan(shortage) {
+		return reorderQty
+	}
+	return shortage
+}
+
+func (input NewReorderPoint) validate(ctx context.Context, businessId string) error {
+	if err := utils.ValidateResourceId[Warehouse](ctx, businessId, input.WarehouseId); err != nil {
+		return errors.New("warehouse not found")
+	}
+	if input.SupplierId > 0 {
+		if err := utils.ValidateResourceId[Supplier](ctx, businessId, input.SupplierId); err != nil {
+			return errors.New("supplier not found")
+		}
+	}
+	if input.ReorderLevel.IsNegative() || input.ReorderQty.IsNegative() {
+		return errors.New("reorder level and quantity cannot be negative")
+	}
+	return nil
+}
+
+// SetReorderPoints replaces the reorder points of a product or variant, one per warehouse.
+func SetReorderPoints(ctx context.Context, productType ProductType, productId int, input []*NewReorderPoint) ([]*ReorderPoint, error) {
+
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if productType != ProductTypeSingle && productType != ProductTypeVariant {
+		return nil, errors.New("reorder points only apply to single products and variants")
+	}
+	product, err := GetProductOrVariant(ctx, string(productType), productId)
+	if err != nil {
+		return nil, err
+	}
+	if product.GetInventoryAccountID() <= 0 {
+		return nil, errors.New("reorder points only apply to inventory items")
+	}
+
+	seen := make(map[int]bool)
+	points := make([]*ReorderPoint, 0, len(input))
+	for _, item := range input {
+		if err := item.validate(ctx, businessId); err != nil {
+			return nil, err
+		}
+		if seen[item.WarehouseId] {
+			return nil, errors.New("duplicate warehouse in reorder points")
+		}
+		seen[item.WarehouseId] = true
+		points = append(points, &ReorderPoint{
+			BusinessId:   businessId,
+			ProductId:    productId,
+			ProductType:  productType,
+			WarehouseId:  item.WarehouseId,
+			ReorderLevel: item.ReorderLevel,
+			ReorderQty:   item.ReorderQty,
+			SupplierId:   item.SupplierId,
+		})
+	}
+
+	db := config.GetDB()
+	tx := db.Begin()
+	err = tx.WithContext(ctx).
+		Where("business_id = ? AND product_type = ? AND product_id = ?", businessId, productType, productId).
+		Delete(&ReorderPoint{}).Error
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if len(points) > 0 {
+		if err := tx.WithContext(ctx).Create(&points).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+func GetReorderPoints(ctx context.Context, productType ProductType, productId int) ([]*ReorderPoint, error) {
+
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	db := config.GetDB()
+	var results []*ReorderPoint
+	err := db.WithContext(ctx).
+		Where("business_id = ? AND product_type = ? AND product_id = ?", businessId, productType, productId).
+		Order("warehouse_id").Find(&results).Error
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// reorder points with product, warehouse, preferred supplier and the quantity still on order
+const reorderPointsSql = `
+WITH AllProducts AS (
+    SELECT id AS product_id, 'S' AS product_type, name AS product_name, sku AS product_sku, unit_id AS product_unit_id, supplier_id
+    FROM products
+    WHERE business_id = @businessId
+    UNION ALL
+    SELECT v.id AS product_id, 'V' AS product_type, v.name AS product_name, v.sku AS product_sku, v.unit_id AS product_unit_id, g.supplier_id
+    FROM product_variants v
+    LEFT JOIN product_groups g ON g.id = v.product_group_id
+    WHERE v.business_id = @businessId
+),
+OnOrder AS (
+    SELECT po.warehouse_id, d.product_id, d.product_type, SUM(d.detail_qty - d.detail_billed_qty) AS on_order_qty
+    FROM purchase_order_details d
+    JOIN purchase_orders po ON po.id = d.purchase_order_id
+    WHERE po.business_id = @businessId
+      AND po.current_status IN ('Draft', 'Confirmed', 'Partially Billed')
+      AND d.detail_qty > d.detail_billed_qty
+    GROUP BY po.warehouse_id, d.product_id, d.product_type
+)
+SELECT
+    rp.product_id,
+    rp.product_type,
+    ap.product_name,
+    ap.product_sku,
+    ap.product_unit_id,
+    rp.warehouse_id,
+    w.name AS warehouse_name,
+    CASE WHEN rp.supplier_id > 0 THEN rp.supplier_id ELSE COALESCE(ap.supplier_id, 0) END AS supplier_id,
+    COALESCE(oo.on_order_qty, 0) AS on_order_qty,
+    rp.reorder_level,
+    rp.reorder_qty
+FROM reorder_points rp
+JOIN AllProducts ap ON ap.product_id = rp.product_id AND ap.product_type = rp.product_type
+LEFT JOIN warehouses w ON w.id = rp.warehouse_id
+LEFT JOIN OnOrder oo ON oo.warehouse_id = rp.warehouse_id AND oo.product_id = rp.product_id AND oo.product_type = rp.product_type
+WHERE rp.business_id = @businessId
+ORDER BY ap.product_name, w.name
+`
+
+// GetLowStockLedger returns the reorder points whose stock on hand, taken from the stock
+// ledger, plus open purchase orders is at or below the reorder level. Restricted users only
+// get the warehouses of their branches.
+func GetLowStockLedger(ctx context.Context, warehouseId *int, supplierId *int) ([]*LowStockResponse, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	warehouseIds, err := NarrowReportWarehouse(ctx, warehouseId)
+	if err != nil {
+		return nil, err
+	}
+	var permitted map[int]bool
+	if warehouseIds != nil {
+		permitted = make(map[int]bool, len(warehouseIds))
+		for _, id := range warehouseIds {
+			permitted[id] = true
+		}
+	}
+
+	db := config.GetDB()
+	var points []*LowStockResponse
+	if err := db.WithContext(ctx).Raw(reorderPointsSql, map[string]interface{}{
+		"businessId": businessId,
+	}).Scan(&points).Error; err != nil {
+		return nil, err
+	}
+
+	// stock on hand per warehouse, read once for each warehouse with reorder points
+	stock := make(map[int]map[string]InventorySnapshot)
+	now := time.Now()
+	results := make([]*LowStockResponse, 0)
+	for _, point := range points {
+		if permitted != nil && !permitted[point.WarehouseId] {
+			continue
+		}
+		if supplierId != nil && *supplierId > 0 && point.SupplierId != *supplierId {
+			continue
+		}
+		snapshots, ok := stock[point.WarehouseId]
+		if !ok {
+			id := point.WarehouseId
+			rows, err := computeLedgerSnapshots(ctx, now, &id, nil, nil, nil)
+			if err != nil {
+				return nil, err
+			}
+			snapshots = SumSnapshot(rows)
+			stock[point.WarehouseId] = snapshots
+		}
+		point.StockOnHand = snapshots[snapshotKey(point.ProductId, point.ProductType)].StockOnHand
+		point.SuggestedQty = SuggestedReorderQty(point.StockOnHand, point.OnOrderQty, point.ReorderLevel, point.ReorderQty)
+		if point.SuggestedQty.IsPositive() {
+			results = append(results, point)
+		}
+	}
+	return results, nil
+}
+
+// GeneratePurchaseOrders drafts a purchase order per supplier and warehouse for the low stock
+// lines, which GetLowStockLedger limits to the user's branches. Lines without a supplier are
+// left out, and each order is created on its own so the orders already drafted stay when a
+// later one fails.
+func GeneratePurchaseOrders(ctx context.Context, warehouseId *int, supplierId *int) ([]*PurchaseOrder, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	business, err := GetBusinessById(ctx, businessId)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := GetLowStockLedger(ctx, warehouseId, supplierId)
+	if err != nil {
+		return nil, err
+	}
+
+	type orderKey struct{ supplierId, warehouseId int }
+	var keys []orderKey
+	grouped := make(map[orderKey][]*LowStockResponse)
+	for _, line := range lines {
+		if line.SupplierId <= 0 {
+			continue
+		}
+		key := orderKey{line.SupplierId, line.WarehouseId}
+		if _, ok := grouped[key]; !ok {
+			keys = append(keys, key)
+		}
+		grouped[key] = append(grouped[key], line)
+	}
+
+	now := time.Now()
+	today := MyDateString(now)
+	orders := make([]*PurchaseOrder, 0, len(keys))
+	for _, key := range keys {
+		supplier, err := GetSupplier(ctx, key.supplierId)
+		if err != nil {
+			return orders, err
+		}
+		warehouse, err := GetWarehouse(ctx, key.warehouseId)
+		if err != nil {
+			return orders, err
+		}
+		input := NewPurchaseOrder{
+			SupplierId:                  supplier.ID,
+			BranchId:                    warehouse.BranchId,
+			OrderDate:                   now,
+			OrderPaymentTerms:           supplier.SupplierPaymentTerms,
+			OrderPaymentTermsCustomDays: supplier.SupplierPaymentTermsCustomDays,
+			Notes:                       "Generated from reorder points",
+			CurrencyId:                  supplier.CurrencyId,
+			CurrentStatus:               PurchaseOrderStatusDraft,
+			IsTaxInclusive:              utils.NewFalse(),
+			WarehouseId:                 warehouse.ID,
+		}
+		if supplier.CurrencyId != business.BaseCurrencyId {
+			if rate, err := GetExchangeRateAsOf(ctx, businessId, supplier.CurrencyId, now); err == nil {
+				input.ExchangeRate = rate
+			}
+		}
+		for _, line := range grouped[key] {
+			product, err := GetProductOrVariant(ctx, string(line.ProductType), line.ProductId)
+			if err != nil {
+				return orders, err
+			}
+			detail := NewPurchaseOrderDetail{
+				ProductId:       line.ProductId,
+				ProductType:     line.ProductType,
+				Name:            line.ProductName,
+				DetailAccountId: product.GetInventoryAccountID(),
+				DetailQty:       line.SuggestedQty,
+			}
+			// lines the supplier's price list does not price keep the product's purchase price
+			price, err := GetEffectivePrice(ctx, PriceListTypePurchase, supplier.ID, line.ProductType, line.ProductId, nil, line.SuggestedQty, today, &supplier.CurrencyId)
+			if err == nil {
+				detail.DetailUnitRate = price.Price
+			}
+			input.Details = append(input.Details, detail)
+		}
+		order, err := CreatePurchaseOrder(ctx, &input)
+		if err != nil {
+			return orders, fmt.Errorf("%s: %w", supplier.Name, err)
+		}
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
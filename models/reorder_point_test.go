@@ -0,0 +1,29 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/shopspring/decimal"
+)
+
+func TestSuggestedReorderQty(t *testing.T) {
+	d := decimal.RequireFromString
+	for _, tc := range []struct {
+		name                       string
+		stock, onOrder, level, qty string
+		want                       string
+	}{
+		{"above level", "30", "0", "20", "50", "0"},
+		{"at level", "20", "0", "20", "50", "50"},
+		{"open orders cover the level", "5", "20", "20", "50", "0"},
+		{"open orders count towards stock", "5", "10", "20", "50", "50"},
+		{"reorder qty short of the level", "-10", "0", "20", "25", "30"},
+		{"no reorder qty", "5", "0", "20", "0", "15"},
+	} {
+		got := models.SuggestedReorderQty(d(tc.stock), d(tc.onOrder), d(tc.level), d(tc.qty))
+		if !got.Equal(d(tc.want)) {
+			t.Errorf("%s: suggested = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
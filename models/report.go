@@ -137,3 +137,57 @@ type AccountGroupItem struct {
 	AccountID   int
 	Amount      decimal.Decimal
 }
+
+type BudgetVsActualResponse struct {
+	BudgetId   int
+	BudgetName string
+	FiscalYear int
+	PeriodType BudgetPeriodType
+	Periods    []BudgetPeriod
+	Groups     []BudgetVsActualGroup
+	Summaries  []BudgetVsActualRow
+}
+
+type BudgetVsActualGroup struct {
+	GroupType string
+	Accounts  []BudgetVsActualAccount
+	Amounts   []BudgetVsActualAmount
+	Total     BudgetVsActualAmount
+}
+
+type BudgetVsActualAccount struct {
+	MainType    string
+	DetailType  string
+	AccountName string
+	AccountID   int
+	Amounts     []BudgetVsActualAmount
+	Total       BudgetVsActualAmount
+}
+
+type BudgetVsActualRow struct {
+	Name    string
+	Amounts []BudgetVsActualAmount
+	Total   BudgetVsActualAmount
+}
+
+// BudgetVsActualAmount compares the actual figure of a period with its budget.
+// VariancePercent is nil when nothing was budgeted.
+type BudgetVsActualAmount struct {
+	Actual          decimal.Decimal
+	Budget          decimal.Decimal
+	Variance        decimal.Decimal
+	VariancePercent *decimal.Decimal
+}
+
+func NewBudgetVsActualAmount(actual decimal.Decimal, budget decimal.Decimal) BudgetVsActualAmount {
+	amount := BudgetVsActualAmount{
+		Actual:   actual,
+		Budget:   budget,
+		Variance: actual.Sub(budget),
+	}
+	if !budget.IsZero() {
+		percent := amount.Variance.Div(budget.Abs()).Mul(decimal.NewFromInt(100)).Round(2)
+		amount.VariancePercent = &percent
+	}
+	return amount
+}
@@ -20,6 +20,10 @@ type AccountTransactionReportEdge struct {
 }
 
 func PaginateAccountTransactionReport(ctx context.Context, limit *int, after *string, fromDate models.MyDateString, toDate models.MyDateString, reportType string, branchID *int, accountIds []int) (*AccountTransactionReportConnection, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
 	businessID, ok := utils.GetBusinessIdFromContext(ctx)
 	if !ok || businessID == "" {
 		return nil, errors.New("business ID is required")
@@ -43,8 +47,8 @@ func PaginateAccountTransactionReport(ctx context.Context, limit *int, after *st
 	db := config.GetDB()
 	dbCtx := db.WithContext(ctx).Where("business_id = ?", businessID)
 
-	if branchID != nil && *branchID > 0 {
-		dbCtx = dbCtx.Where("branch_id = ?", *branchID)
+	if len(branchIds) > 0 {
+		dbCtx = dbCtx.Where("branch_id IN ?", branchIds)
 	}
 
 	// temp
@@ -103,6 +107,10 @@ func PaginateAccountTransactionReport(ctx context.Context, limit *int, after *st
 }
 
 func GetAllAccountTransactionReport(ctx context.Context, fromDate models.MyDateString, toDate models.MyDateString, reportType string, branchID *int, accountIds []int) ([]*models.AccountTransaction, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
 
 	businessID, ok := utils.GetBusinessIdFromContext(ctx)
 	if !ok || businessID == "" {
@@ -122,8 +130,8 @@ func GetAllAccountTransactionReport(ctx context.Context, fromDate models.MyDateS
 	db := config.GetDB()
 	dbCtx := db.WithContext(ctx).Where("business_id = ?", businessID)
 
-	if branchID != nil && *branchID > 0 {
-		dbCtx = dbCtx.Where("branch_id = ?", *branchID)
+	if len(branchIds) > 0 {
+		dbCtx = dbCtx.Where("branch_id IN ?", branchIds)
 	}
 
 	// temp
@@ -11,6 +11,10 @@ import (
 )
 
 func GetAccountTypeSummaryReport(ctx context.Context, fromDate models.MyDateString, toDate models.MyDateString, reportType string, branchID *int) ([]*models.AccountSummaryGroup, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
 	businessID, ok := utils.GetBusinessIdFromContext(ctx)
 	if !ok || businessID == "" {
 		return nil, errors.New("business ID is required")
@@ -29,11 +33,7 @@ func GetAccountTypeSummaryReport(ctx context.Context, fromDate models.MyDateStri
 
 	db := config.GetDB()
 
-	// Initialize branchID to 0 if it's nil
-	if branchID == nil {
-		branchID = new(int)
-		*branchID = 0
-	}
+	balanceBranchIds := models.BalanceBranchIds(branchIds)
 
 	query := `
         SELECT 
@@ -46,47 +46,43 @@ func GetAccountTypeSummaryReport(ctx context.Context, fromDate models.MyDateStri
             ABS(SUM(acb.debit) - SUM(acb.credit)) AS balance,
             ABS((
                 SELECT 
-                    running_balance 
-                FROM 
-                    account_currency_daily_balances AS acb_first
-                WHERE 
-                    acb_first.account_id = acc.id
-                    AND acb_first.branch_id = ?
-                    AND acb_first.currency_id = ?
-                    AND acb_first.transaction_date BETWEEN ? AND ?
-                LIMIT 1
-            ) - 
-            (
-                SELECT 
-                    balance 
+                    SUM(acb_first.running_balance - acb_first.balance)
                 FROM 
                     account_currency_daily_balances AS acb_first
                 WHERE 
                     acb_first.account_id = acc.id
-                    AND acb_first.branch_id = ?
-                    AND acb_first.currency_id = ?
-                    AND acb_first.transaction_date BETWEEN ? AND ?
-                LIMIT 1
+                    AND (acb_first.branch_id, acb_first.currency_id, acb_first.transaction_date) IN (
+                        SELECT branch_id, currency_id, MIN(transaction_date)
+                        FROM account_currency_daily_balances
+                        WHERE account_id = acc.id
+                            AND branch_id IN ?
+                            AND currency_id = ?
+                            AND transaction_date BETWEEN ? AND ?
+                        GROUP BY branch_id, currency_id
+                    )
             )) AS opening_balance,
-            (
+            ABS((
                 SELECT 
-                    ABS(running_balance)
+                    SUM(acb_inner.running_balance)
                 FROM 
                     account_currency_daily_balances AS acb_inner
                 WHERE 
                     acb_inner.account_id = acc.id
-                    AND acb_inner.branch_id = ?
-                    AND acb_inner.currency_id = ?
-                    AND acb_inner.transaction_date BETWEEN ? AND ?
-                ORDER BY 
-                    id DESC 
-                LIMIT 1
-            ) AS closing_balance
+                    AND (acb_inner.branch_id, acb_inner.currency_id, acb_inner.transaction_date) IN (
+                        SELECT branch_id, currency_id, MAX(transaction_date)
+                        FROM account_currency_daily_balances
+                        WHERE account_id = acc.id
+                            AND branch_id IN ?
+                            AND currency_id = ?
+                            AND transaction_date BETWEEN ? AND ?
+                        GROUP BY branch_id, currency_id
+                    )
+            )) AS closing_balance
         FROM 
             accounts AS acc
         LEFT JOIN
             account_currency_daily_balances AS acb ON acb.account_id = acc.id
-            AND acb.branch_id = ?
+            AND acb.branch_id IN ?
             AND acb.currency_id = ?
             AND acb.transaction_date BETWEEN ? AND ?
         WHERE 
@@ -104,10 +100,9 @@ func GetAccountTypeSummaryReport(ctx context.Context, fromDate models.MyDateStri
 	var accountGroupSummaries []*models.AccountSummaryGroup
 
 	rows, err := db.Raw(query,
-		*branchID, business.BaseCurrencyId, fromDate, toDate,
-		*branchID, business.BaseCurrencyId, fromDate, toDate,
-		*branchID, business.BaseCurrencyId, fromDate, toDate,
-		*branchID, business.BaseCurrencyId, fromDate, toDate,
+		balanceBranchIds, business.BaseCurrencyId, fromDate, toDate,
+		balanceBranchIds, business.BaseCurrencyId, fromDate, toDate,
+		balanceBranchIds, business.BaseCurrencyId, fromDate, toDate,
 		businessID,
 	).Rows()
 
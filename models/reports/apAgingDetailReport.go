@@ -36,6 +36,10 @@ type APAgingDetailResponse struct {
 }
 
 func GetAPAgingDetailReport(ctx context.Context, currentDate models.MyDateString, branchID *int, warehouseID *int) ([]*APAgingDetailResponse, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
 
 	sqlTemplate := `
 WITH LatestBillOutbox AS (
@@ -98,7 +102,7 @@ BillAging AS (
         AND bill_date < @currentDate
         AND (b_outbox.processing_status IS NULL OR b_outbox.processing_status <> 'DEAD')
 		{{- if .warehouseId }} AND bills.warehouse_id = @warehouseId {{- end }}
-		{{- if .branchId }} AND bills.branch_id = @branchId {{- end }}
+		{{- if .branchIds }} AND bills.branch_id IN @branchIds {{- end }}
 )
 SELECT
     BillAging.id as bill_id,
@@ -157,7 +161,7 @@ order by
 	}
 
 	sql, err := utils.ExecTemplate(sqlTemplate, map[string]interface{}{
-		"branchId":    utils.DereferencePtr(branchID, 0),
+		"branchIds":   len(branchIds) > 0,
 		"warehouseId": utils.DereferencePtr(warehouseID, 0),
 	})
 	if err != nil {
@@ -168,7 +172,7 @@ order by
 		"businessId":     businessId,
 		"baseCurrencyId": business.BaseCurrencyId,
 		"currentDate":    currentDate,
-		"branchId":       branchID,
+		"branchIds":      branchIds,
 		"warehouseId":    warehouseID,
 	}).Scan(&agingDetails).Error; err != nil {
 		return nil, err
@@ -26,6 +26,10 @@ type APAgingSummaryResponse struct {
 }
 
 func GetAPAgingSummaryReport(ctx context.Context, currentDate models.MyDateString, branchID *int, warehouseID *int) ([]*APAgingSummaryResponse, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
 
 	var results []*APAgingSummaryResponse
 	sqlTemplate := `
@@ -71,7 +75,7 @@ BillAging AS (
         AND bill_date < @currentDate
         AND b.current_status IN ('Confirmed', 'Partial Paid')
         AND (b_outbox.processing_status IS NULL OR b_outbox.processing_status <> 'DEAD')
-        {{- if .branchId }} AND branch_id = @branchId {{- end}}
+        {{- if .branchIds }} AND branch_id IN @branchIds {{- end }}
         {{- if .warehouseId }} AND warehouse_id = @warehouseId {{- end}}
 )
 SELECT
@@ -141,7 +145,7 @@ ORDER BY
 
 	db := config.GetDB()
 	sql, err := utils.ExecTemplate(sqlTemplate, map[string]interface{}{
-		"branchId":    utils.DereferencePtr(branchID, 0),
+		"branchIds":   len(branchIds) > 0,
 		"warehouseId": utils.DereferencePtr(warehouseID, 0),
 	})
 	if err != nil {
@@ -152,7 +156,7 @@ ORDER BY
 		"currentDate":    currentDate,
 		"businessId":     businessId,
 		"baseCurrencyId": business.BaseCurrencyId,
-		"branchId":       branchID,
+		"branchIds":      branchIds,
 		"warehouseId":    warehouseID,
 	}).Scan(&results).Error; err != nil {
 		return nil, err
@@ -61,6 +61,10 @@ type ARAgingDetail struct {
 // }
 
 func GetARAgingDetailReport(ctx context.Context, currentDate models.MyDateString, branchID *int, warehouseID *int) ([]*ARAgingDetailResponse, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
 
 	sqlTemplate := `
 WITH LatestInvoiceOutbox AS (
@@ -123,7 +127,7 @@ InvoiceAging AS (
         AND invoice_date < @currentDate
         AND (iv_outbox.processing_status IS NULL OR iv_outbox.processing_status <> 'DEAD')
 		{{- if .warehouseId }} AND sales_invoices.warehouse_id = @warehouseId {{- end }}
-		{{- if .branchId }} AND sales_invoices.branch_id = @branchId {{- end }}
+		{{- if .branchIds }} AND sales_invoices.branch_id IN @branchIds {{- end }}
 )
 SELECT
     InvoiceAging.id as invoice_id,
@@ -182,7 +186,7 @@ FROM
 
 	sql, err := utils.ExecTemplate(sqlTemplate, map[string]interface{}{
 		"warehouseId": utils.DereferencePtr(warehouseID, 0),
-		"branchId":    utils.DereferencePtr(branchID, 0),
+		"branchIds":   len(branchIds) > 0,
 	})
 	if err != nil {
 		return nil, err
@@ -194,7 +198,7 @@ FROM
 		"baseCurrencyId": business.BaseCurrencyId,
 		"currentDate":    currentDate,
 		"warehouseId":    warehouseID,
-		"branchId":       branchID,
+		"branchIds":      branchIds,
 	}).Scan(&agingDetails).Error; err != nil {
 		return nil, err
 	}
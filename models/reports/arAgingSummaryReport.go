@@ -26,6 +26,10 @@ type ARAgingSummaryResponse struct {
 }
 
 func GetARAgingSummaryReport(ctx context.Context, currentDate models.MyDateString, branchId *int, warehouseId *int) ([]*ARAgingSummaryResponse, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchId)
+	if err != nil {
+		return nil, err
+	}
 
 	var results []*ARAgingSummaryResponse
 	sqlTemplate := `
@@ -71,7 +75,7 @@ func GetARAgingSummaryReport(ctx context.Context, currentDate models.MyDateStrin
         AND invoice_date < @currentDate
         AND si.current_status IN ('Confirmed', 'Partial Paid')
         AND (iv_outbox.processing_status IS NULL OR iv_outbox.processing_status <> 'DEAD')
-        {{- if .branchId }} AND branch_id = @branchId {{- end}}
+        {{- if .branchIds }} AND branch_id IN @branchIds {{- end }}
         {{- if .warehouseId }} AND warehouse_id = @warehouseId {{- end}}
 )
 SELECT
@@ -142,7 +146,7 @@ ORDER BY
 
 	db := config.GetDB()
 	sql, err := utils.ExecTemplate(sqlTemplate, map[string]interface{}{
-		"branchId":    utils.DereferencePtr(branchId, 0),
+		"branchIds":   len(branchIds) > 0,
 		"warehouseId": utils.DereferencePtr(warehouseId, 0),
 	})
 	if err != nil {
@@ -153,7 +157,7 @@ ORDER BY
 		"currentDate":    currentDate,
 		"businessId":     businessId,
 		"baseCurrencyId": business.BaseCurrencyId,
-		"branchId":       branchId,
+		"branchIds":      branchIds,
 		"warehouseId":    warehouseId,
 	}).Scan(&results).Error; err != nil {
 		return nil, err
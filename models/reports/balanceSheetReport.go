@@ -313,6 +313,10 @@ import (
 // }
 
 func GetBalanceSheetReport(ctx context.Context, toDate models.MyDateString, reportType string, branchID *int) ([]*models.BalanceSheetResponse, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
 	businessId, ok := utils.GetBusinessIdFromContext(ctx)
 	if !ok || businessId == "" {
 		return nil, errors.New("business id is required")
@@ -336,16 +340,12 @@ func GetBalanceSheetReport(ctx context.Context, toDate models.MyDateString, repo
 		return nil, err
 	}
 
-	// Initialize branchID to 0 if it's nil
-	if branchID == nil {
-		branchID = new(int)
-		*branchID = 0
-	}
+	balanceBranchIds := models.BalanceBranchIds(branchIds)
 
 	var balances []models.BalanceSheet
 
 	rows, err := db.Raw(`
-        WITH LastRows AS (
+        WITH BranchLastRows AS (
             SELECT
                 -- acb.id,
                 ac.main_type AS main_type,
@@ -354,14 +354,14 @@ func GetBalanceSheetReport(ctx context.Context, toDate models.MyDateString, repo
                 acb.account_id AS account_id,
                 acb.running_balance AS amount,
 				ac.parent_account_id AS parent_account_id,
-                ROW_NUMBER() OVER (PARTITION BY acb.account_id ORDER BY acb.transaction_date DESC) AS row_num
+                ROW_NUMBER() OVER (PARTITION BY acb.account_id, acb.branch_id ORDER BY acb.transaction_date DESC) AS row_num
             FROM
                 account_currency_daily_balances AS acb
             JOIN
                 accounts AS ac ON acb.account_id = ac.id
             WHERE
                 acb.business_id= ?
-                AND acb.branch_id = ?
+                AND acb.branch_id IN ?
 				AND acb.currency_id = ?
                 AND acb.transaction_date <= ?
                 AND acb.account_id IN (
@@ -370,6 +370,24 @@ func GetBalanceSheetReport(ctx context.Context, toDate models.MyDateString, repo
 
         ),
 
+		-- balances of the branches summed per account
+		LastRows AS (
+			SELECT
+				main_type,
+				detail_type,
+				account_name,
+				account_id,
+				SUM(amount) AS amount,
+				parent_account_id,
+				1 AS row_num
+			FROM
+				BranchLastRows
+			WHERE
+				row_num = 1
+			GROUP BY
+				main_type, detail_type, account_name, account_id, parent_account_id
+		),
+
 		-- CTE to include parent accounts if missing in the main result
 		ParentAccounts AS (
 			SELECT DISTINCT
@@ -401,7 +419,7 @@ func GetBalanceSheetReport(ctx context.Context, toDate models.MyDateString, repo
                 FROM account_currency_daily_balances
                 WHERE
                     business_id= ?
-                    AND branch_id = ?
+                    AND branch_id IN ?
 					AND currency_id = ?
                     AND transaction_date < ?
                     AND account_id IN (
@@ -424,7 +442,7 @@ func GetBalanceSheetReport(ctx context.Context, toDate models.MyDateString, repo
                 FROM account_currency_daily_balances
                 WHERE
                     business_id= ?
-                    AND branch_id = ?
+                    AND branch_id IN ?
 					AND currency_id = ?
                     AND transaction_date >= ?
                     AND transaction_date <= ?
@@ -555,9 +573,9 @@ func GetBalanceSheetReport(ctx context.Context, toDate models.MyDateString, repo
                 ELSE 10
             END;
 
-    `, businessId, *branchID, business.BaseCurrencyId, toDate,
-		businessId, *branchID, business.BaseCurrencyId, fromDate,
-		businessId, *branchID, business.BaseCurrencyId, fromDate, toDate).Rows()
+    `, businessId, balanceBranchIds, business.BaseCurrencyId, toDate,
+		businessId, balanceBranchIds, business.BaseCurrencyId, fromDate,
+		businessId, balanceBranchIds, business.BaseCurrencyId, fromDate, toDate).Rows()
 
 	if err != nil {
 		return nil, err
@@ -30,6 +30,10 @@ type BillDetailResponse struct {
 }
 
 func GetBillDetailReport(ctx context.Context, fromDate models.MyDateString, toDate models.MyDateString, branchID *int, warehouseID *int) ([]*BillDetailResponse, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
 
 	sqlTemplate := `
 SELECT
@@ -95,7 +99,7 @@ LEFT JOIN suppliers ON suppliers.id = bill.supplier_id
     AND bill.current_status NOT IN ('Draft', 'Void')
     AND (bill_outbox.processing_status IS NULL OR bill_outbox.processing_status <> 'DEAD')
 	{{- if .warehouseId }} AND bill.warehouse_id = @warehouseId {{- end }}
-	{{- if .branchId }} AND bill.branch_id = @branchId {{- end }}
+	{{- if .branchIds }} AND bill.branch_id IN @branchIds {{- end }}
 `
 	var results []*BillDetailResponse
 
@@ -115,7 +119,7 @@ LEFT JOIN suppliers ON suppliers.id = bill.supplier_id
 	}
 
 	sql, err := utils.ExecTemplate(sqlTemplate, map[string]interface{}{
-		"branchId":    utils.DereferencePtr(branchID, 0),
+		"branchIds":   len(branchIds) > 0,
 		"warehouseId": utils.DereferencePtr(warehouseID, 0),
 	})
 	if err != nil {
@@ -127,7 +131,7 @@ LEFT JOIN suppliers ON suppliers.id = bill.supplier_id
 		"baseCurrencyId": business.BaseCurrencyId,
 		"fromDate":       fromDate,
 		"toDate":         toDate,
-		"branchId":       branchID,
+		"branchIds":      branchIds,
 		"warehouseId":    warehouseID,
 	}).Scan(&results).Error; err != nil {
 		return nil, err
@@ -0,0 +1,480 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+)
+
+var budgetPlGroupOrder = []string{
+	"Operating Income",
+	"Cost Of Goods Sold",
+	"Operating Expense",
+	"Non Operating Income",
+	"Non Operating Expense",
+}
+
+var budgetBsGroupOrder = []string{
+	"Current Asset",
+	"Fixed Asset",
+	"Other Asset",
+	"Liabilities",
+	"Equities",
+}
+
+// budgetReportAccount is an account row while the report is being built,
+// with actual and budget amounts per period.
+type budgetReportAccount struct {
+	account   models.Account
+	groupType string
+	actuals   []decimal.Decimal
+	budgets   []decimal.Decimal
+}
+
+type budgetReportContext struct {
+	businessId string
+	business   *models.Business
+	budget     *models.Budget
+	periods    []models.BudgetPeriod
+	budgets    map[int][]decimal.Decimal
+}
+
+func loadBudgetReportContext(ctx context.Context, budgetId int, periodType *models.BudgetPeriodType) (*budgetReportContext, models.BudgetPeriodType, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, "", errors.New("business id is required")
+	}
+	business, err := models.GetBusiness(ctx)
+	if err != nil {
+		return nil, "", errors.New("business id is required")
+	}
+	budget, err := models.GetBudget(ctx, budgetId)
+	if err != nil {
+		return nil, "", err
+	}
+	budgetAccounts, err := models.GetBudgetAccounts(ctx, budget.ID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	pt := models.BudgetPeriodTypeMonth
+	if periodType != nil && periodType.IsValid() {
+		pt = *periodType
+	}
+
+	budgets := make(map[int][]decimal.Decimal, len(budgetAccounts))
+	for _, budgetAccount := range budgetAccounts {
+		budgets[budgetAccount.AccountId] = budgetAccount.Amounts
+	}
+
+	return &budgetReportContext{
+		businessId: businessId,
+		business:   business,
+		budget:     budget,
+		periods:    models.BudgetPeriods(budget.StartDate, pt),
+		budgets:    budgets,
+	}, pt, nil
+}
+
+// periodRange converts a period's local calendar dates to the UTC range used by daily balances.
+func (rc *budgetReportContext) periodRange(period models.BudgetPeriod) (models.MyDateString, models.MyDateString, error) {
+	fromDate := models.MyDateString(period.StartDate)
+	toDate := models.MyDateString(period.EndDate)
+	if err := fromDate.StartOfDayUTCTime(rc.business.Timezone); err != nil {
+		return fromDate, toDate, err
+	}
+	if err := toDate.EndOfDayUTCTime(rc.business.Timezone); err != nil {
+		return fromDate, toDate, err
+	}
+	return fromDate, toDate, nil
+}
+
+func (rc *budgetReportContext) accounts(ctx context.Context, mainTypes []string) (map[int]models.Account, error) {
+	var accounts []models.Account
+	db := config.GetDB()
+	if err := db.WithContext(ctx).
+		Where("business_id = ? AND main_type IN ?", rc.businessId, mainTypes).
+		Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	results := make(map[int]models.Account, len(accounts))
+	for _, account := range accounts {
+		results[account.ID] = account
+	}
+	return results, nil
+}
+
+// GetBudgetVsActualProfitAndLossReport compares income and expense movements of each
+// period with the budgeted amounts of the months it covers.
+func GetBudgetVsActualProfitAndLossReport(ctx context.Context, budgetId int, periodType *models.BudgetPeriodType) (*models.BudgetVsActualResponse, error) {
+	rc, pt, err := loadBudgetReportContext(ctx, budgetId, periodType)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := rc.accounts(ctx, []string{"Income", "Expense"})
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	rows := make(map[int]*budgetReportAccount)
+	row := func(accountId int) *budgetReportAccount {
+		r, ok := rows[accountId]
+		if !ok {
+			r = newBudgetReportAccount(accounts[accountId], len(rc.periods))
+			r.groupType = budgetPlGroupType(r.account)
+			rows[accountId] = r
+		}
+		return r
+	}
+
+	for i, period := range rc.periods {
+		fromDate, toDate, err := rc.periodRange(period)
+		if err != nil {
+			return nil, err
+		}
+		var actuals []struct {
+			AccountId int
+			Amount    decimal.Decimal
+		}
+		if err := db.WithContext(ctx).Raw(`
+			SELECT
+				acb.account_id AS account_id,
+				SUM(acb.balance) AS amount
+			FROM
+				account_currency_daily_balances AS acb
+			JOIN
+				accounts AS ac ON acb.account_id = ac.id
+			WHERE
+				acb.business_id = ?
+				AND acb.branch_id = ?
+				AND acb.currency_id = ?
+				AND acb.transaction_date >= ?
+				AND acb.transaction_date <= ?
+				AND ac.main_type IN ('Income', 'Expense')
+			GROUP BY
+				acb.account_id
+		`, rc.businessId, rc.budget.BranchId, rc.business.BaseCurrencyId, fromDate, toDate).Scan(&actuals).Error; err != nil {
+			return nil, err
+		}
+		for _, actual := range actuals {
+			if _, ok := accounts[actual.AccountId]; !ok {
+				continue
+			}
+			r := row(actual.AccountId)
+			r.actuals[i] = budgetPlPresentationAmount(r.account, actual.Amount)
+		}
+	}
+
+	for accountId, amounts := range rc.budgets {
+		if _, ok := accounts[accountId]; !ok {
+			continue
+		}
+		r := row(accountId)
+		for i, period := range rc.periods {
+			for _, month := range period.Months {
+				r.budgets[i] = r.budgets[i].Add(amounts[month-1])
+			}
+		}
+	}
+
+	response := rc.response(pt, rows, budgetPlGroupOrder, false)
+
+	groupTotals := make(map[string]models.BudgetVsActualGroup)
+	for _, group := range response.Groups {
+		groupTotals[group.GroupType] = group
+	}
+	groupValues := func(groupType string, i int) (decimal.Decimal, decimal.Decimal) {
+		group, ok := groupTotals[groupType]
+		if !ok {
+			return decimal.Zero, decimal.Zero
+		}
+		if i < 0 {
+			return group.Total.Actual, group.Total.Budget
+		}
+		return group.Amounts[i].Actual, group.Amounts[i].Budget
+	}
+	summary := func(i int) (gross, operating, net [2]decimal.Decimal) {
+		for k := 0; k < 2; k++ {
+			values := make(map[string]decimal.Decimal)
+			for _, groupType := range budgetPlGroupOrder {
+				actual, budget := groupValues(groupType, i)
+				if k == 0 {
+					values[groupType] = actual
+				} else {
+					values[groupType] = budget
+				}
+			}
+			gross[k] = values["Operating Income"].Sub(values["Cost Of Goods Sold"])
+			operating[k] = gross[k].Sub(values["Operating Expense"])
+			net[k] = operating[k].Add(values["Non Operating Income"]).Sub(values["Non Operating Expense"])
+		}
+		return
+	}
+
+	summaries := []models.BudgetVsActualRow{{Name: "Gross Profit"}, {Name: "Operating Profit"}, {Name: "Net Profit"}}
+	for i := -1; i < len(rc.periods); i++ {
+		gross, operating, net := summary(i)
+		for s, values := range [][2]decimal.Decimal{gross, operating, net} {
+			amount := models.NewBudgetVsActualAmount(values[0], values[1])
+			if i < 0 {
+				summaries[s].Total = amount
+			} else {
+				summaries[s].Amounts = append(summaries[s].Amounts, amount)
+			}
+		}
+	}
+	response.Summaries = summaries
+
+	return response, nil
+}
+
+// GetBudgetVsActualBalanceSheetReport compares account balances at the end of each
+// period with the balance budgeted for the last month of the period.
+// Retained and current year earnings are not part of the comparison.
+func GetBudgetVsActualBalanceSheetReport(ctx context.Context, budgetId int, periodType *models.BudgetPeriodType) (*models.BudgetVsActualResponse, error) {
+	rc, pt, err := loadBudgetReportContext(ctx, budgetId, periodType)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := rc.accounts(ctx, []string{"Asset", "Liability", "Equity"})
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	rows := make(map[int]*budgetReportAccount)
+	row := func(accountId int) *budgetReportAccount {
+		r, ok := rows[accountId]
+		if !ok {
+			r = newBudgetReportAccount(accounts[accountId], len(rc.periods))
+			r.groupType = budgetBsGroupType(r.account)
+			rows[accountId] = r
+		}
+		return r
+	}
+
+	for i, period := range rc.periods {
+		_, toDate, err := rc.periodRange(period)
+		if err != nil {
+			return nil, err
+		}
+		var actuals []struct {
+			AccountId int
+			Amount    decimal.Decimal
+		}
+		if err := db.WithContext(ctx).Raw(`
+			SELECT account_id, amount
+			FROM (
+				SELECT
+					acb.account_id AS account_id,
+					acb.running_balance AS amount,
+					ROW_NUMBER() OVER (PARTITION BY acb.account_id ORDER BY acb.transaction_date DESC) AS row_num
+				FROM
+					account_currency_daily_balances AS acb
+				JOIN
+					accounts AS ac ON acb.account_id = ac.id
+				WHERE
+					acb.business_id = ?
+					AND acb.branch_id = ?
+					AND acb.currency_id = ?
+					AND acb.transaction_date <= ?
+					AND ac.main_type IN ('Asset', 'Liability', 'Equity')
+			) AS latest
+			WHERE row_num = 1
+		`, rc.businessId, rc.budget.BranchId, rc.business.BaseCurrencyId, toDate).Scan(&actuals).Error; err != nil {
+			return nil, err
+		}
+		for _, actual := range actuals {
+			if _, ok := accounts[actual.AccountId]; !ok || actual.Amount.IsZero() {
+				continue
+			}
+			r := row(actual.AccountId)
+			amount := actual.Amount
+			if r.account.MainType == models.AccountMainTypeLiability || r.account.MainType == models.AccountMainTypeEquity {
+				amount = amount.Neg()
+			}
+			r.actuals[i] = amount
+		}
+	}
+
+	for accountId, amounts := range rc.budgets {
+		if _, ok := accounts[accountId]; !ok {
+			continue
+		}
+		r := row(accountId)
+		for i, period := range rc.periods {
+			r.budgets[i] = amounts[period.Months[len(period.Months)-1]-1]
+		}
+	}
+
+	response := rc.response(pt, rows, budgetBsGroupOrder, true)
+
+	totalAssets := models.BudgetVsActualRow{Name: "Total Assets"}
+	totalLiabilities := models.BudgetVsActualRow{Name: "Total Liabilities"}
+	totalEquities := models.BudgetVsActualRow{Name: "Total Equities"}
+	for i := -1; i < len(rc.periods); i++ {
+		var assets, liabilities, equities [2]decimal.Decimal
+		for _, group := range response.Groups {
+			amount := group.Total
+			if i >= 0 {
+				amount = group.Amounts[i]
+			}
+			target := &assets
+			switch group.GroupType {
+			case "Liabilities":
+				target = &liabilities
+			case "Equities":
+				target = &equities
+			}
+			target[0] = target[0].Add(amount.Actual)
+			target[1] = target[1].Add(amount.Budget)
+		}
+		for _, pair := range []struct {
+			row    *models.BudgetVsActualRow
+			values [2]decimal.Decimal
+		}{{&totalAssets, assets}, {&totalLiabilities, liabilities}, {&totalEquities, equities}} {
+			amount := models.NewBudgetVsActualAmount(pair.values[0], pair.values[1])
+			if i < 0 {
+				pair.row.Total = amount
+			} else {
+				pair.row.Amounts = append(pair.row.Amounts, amount)
+			}
+		}
+	}
+	response.Summaries = []models.BudgetVsActualRow{totalAssets, totalLiabilities, totalEquities}
+
+	return response, nil
+}
+
+func newBudgetReportAccount(account models.Account, periods int) *budgetReportAccount {
+	return &budgetReportAccount{
+		account: account,
+		actuals: make([]decimal.Decimal, periods),
+		budgets: make([]decimal.Decimal, periods),
+	}
+}
+
+// response groups the account rows in groupOrder. For balances the total column is
+// the last period, otherwise it is the sum of all periods.
+func (rc *budgetReportContext) response(pt models.BudgetPeriodType, rows map[int]*budgetReportAccount, groupOrder []string, isBalance bool) *models.BudgetVsActualResponse {
+	sorted := make([]*budgetReportAccount, 0, len(rows))
+	for _, r := range rows {
+		sorted = append(sorted, r)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].account.Name != sorted[j].account.Name {
+			return sorted[i].account.Name < sorted[j].account.Name
+		}
+		return sorted[i].account.ID < sorted[j].account.ID
+	})
+
+	periods := len(rc.periods)
+	total := func(values []decimal.Decimal) decimal.Decimal {
+		if isBalance {
+			return values[periods-1]
+		}
+		sum := decimal.Zero
+		for _, value := range values {
+			sum = sum.Add(value)
+		}
+		return sum
+	}
+
+	response := &models.BudgetVsActualResponse{
+		BudgetId:   rc.budget.ID,
+		BudgetName: rc.budget.Name,
+		FiscalYear: rc.budget.FiscalYear,
+		PeriodType: pt,
+		Periods:    rc.periods,
+		Groups:     make([]models.BudgetVsActualGroup, 0),
+	}
+	for _, groupType := range groupOrder {
+		group := models.BudgetVsActualGroup{
+			GroupType: groupType,
+			Accounts:  make([]models.BudgetVsActualAccount, 0),
+		}
+		groupActuals := make([]decimal.Decimal, periods)
+		groupBudgets := make([]decimal.Decimal, periods)
+		for _, r := range sorted {
+			if r.groupType != groupType {
+				continue
+			}
+			item := models.BudgetVsActualAccount{
+				MainType:    string(r.account.MainType),
+				DetailType:  string(r.account.DetailType),
+				AccountName: r.account.Name,
+				AccountID:   r.account.ID,
+				Total:       models.NewBudgetVsActualAmount(total(r.actuals), total(r.budgets)),
+			}
+			for i := 0; i < periods; i++ {
+				item.Amounts = append(item.Amounts, models.NewBudgetVsActualAmount(r.actuals[i], r.budgets[i]))
+				groupActuals[i] = groupActuals[i].Add(r.actuals[i])
+				groupBudgets[i] = groupBudgets[i].Add(r.budgets[i])
+			}
+			group.Accounts = append(group.Accounts, item)
+		}
+		if len(group.Accounts) == 0 {
+			continue
+		}
+		for i := 0; i < periods; i++ {
+			group.Amounts = append(group.Amounts, models.NewBudgetVsActualAmount(groupActuals[i], groupBudgets[i]))
+		}
+		group.Total = models.NewBudgetVsActualAmount(total(groupActuals), total(groupBudgets))
+		response.Groups = append(response.Groups, group)
+	}
+	return response
+}
+
+// budgetPlGroupType follows the grouping of the profit and loss report,
+// including the sales and purchase discount reclassification.
+func budgetPlGroupType(account models.Account) string {
+	switch account.SystemDefaultCode {
+	case "507":
+		return "Operating Income"
+	case "405":
+		return "Operating Expense"
+	}
+	switch account.DetailType {
+	case models.AccountDetailTypeIncome:
+		return "Operating Income"
+	case models.AccountDetailTypeCostOfGoodsSold:
+		return "Cost Of Goods Sold"
+	case models.AccountDetailTypeOtherIncome:
+		return "Non Operating Income"
+	case models.AccountDetailTypeOtherExpense:
+		return "Non Operating Expense"
+	}
+	return "Operating Expense"
+}
+
+// budgetPlPresentationAmount signs a period's balance movement the way the profit
+// and loss report shows it.
+func budgetPlPresentationAmount(account models.Account, amount decimal.Decimal) decimal.Decimal {
+	if account.MainType == models.AccountMainTypeIncome {
+		amount = amount.Neg()
+	}
+	if account.SystemDefaultCode == "507" || account.SystemDefaultCode == "405" {
+		amount = amount.Neg()
+	}
+	return amount
+}
+
+func budgetBsGroupType(account models.Account) string {
+	switch {
+	case account.DetailType == models.AccountDetailTypeFixedAsset:
+		return "Fixed Asset"
+	case account.DetailType == models.AccountDetailTypeOtherAsset:
+		return "Other Asset"
+	case account.MainType == models.AccountMainTypeLiability:
+		return "Liabilities"
+	case account.MainType == models.AccountMainTypeEquity:
+		return "Equities"
+	}
+	return "Current Asset"
+}
@@ -29,6 +29,10 @@ type GroupItem struct {
 }
 
 func GetCashFlowReport(ctx context.Context, fromDate models.MyDateString, toDate models.MyDateString, reportType string, branchID *int) ([]*CashFlowResponse, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
 	businessID, ok := utils.GetBusinessIdFromContext(ctx)
 	if !ok || businessID == "" {
 		return nil, errors.New("business ID is required")
@@ -46,11 +50,7 @@ func GetCashFlowReport(ctx context.Context, fromDate models.MyDateString, toDate
 
 	db := config.GetDB()
 
-	// Initialize branchID to 0 if it's nil
-	if branchID == nil {
-		branchID = new(int)
-		*branchID = 0
-	}
+	balanceBranchIds := models.BalanceBranchIds(branchIds)
 
 	accountCashTypes := []string{
 		string(models.AccountDetailTypeCash),
@@ -82,7 +82,7 @@ func GetCashFlowReport(ctx context.Context, fromDate models.MyDateString, toDate
 				accounts AS ac ON acb.account_id = ac.id
 			WHERE 
 				acb.business_id = ? 
-				AND acb.branch_id = ?
+				AND acb.branch_id IN ?
 				AND acb.currency_id = ?
 				AND acb.transaction_date < ?
 				AND acb.account_id IN (
@@ -101,7 +101,7 @@ func GetCashFlowReport(ctx context.Context, fromDate models.MyDateString, toDate
 				accounts AS ac ON acb.account_id = ac.id
 			WHERE 
 				acb.business_id=? 
-				AND acb.branch_id = ?
+				AND acb.branch_id IN ?
 				AND acb.currency_id = ?
 				AND acb.transaction_date BETWEEN ? AND ?
 				AND acb.account_id IN (
@@ -117,7 +117,7 @@ func GetCashFlowReport(ctx context.Context, fromDate models.MyDateString, toDate
 				accounts AS ac ON acb.account_id = ac.id
 			WHERE 
 				acb.business_id = ? 
-				AND acb.branch_id = ?
+				AND acb.branch_id IN ?
 				AND acb.currency_id = ?
 				AND acb.transaction_date BETWEEN ? AND ?
 				AND acb.account_id IN (
@@ -143,7 +143,7 @@ func GetCashFlowReport(ctx context.Context, fromDate models.MyDateString, toDate
 				accounts AS ac ON acb.account_id = ac.id
 			WHERE 
 				acb.business_id = ? 
-				AND acb.branch_id = ?
+				AND acb.branch_id IN ?
 				AND acb.currency_id = ?
 				AND acb.transaction_date BETWEEN ? AND ?
 				AND acb.account_id IN (
@@ -236,10 +236,10 @@ func GetCashFlowReport(ctx context.Context, fromDate models.MyDateString, toDate
     `
 
 	rows, err := db.Raw(query,
-		businessID, *branchID, business.BaseCurrencyId, fromDate, accountCashTypes,
-		businessID, *branchID, business.BaseCurrencyId, fromDate, toDate, accountIncomeExpenseTypes,
-		businessID, *branchID, business.BaseCurrencyId, fromDate, toDate, accountMainTypes, accountCashTypes,
-		businessID, *branchID, business.BaseCurrencyId, fromDate, toDate, accountMainTypes, accountCashTypes,
+		businessID, balanceBranchIds, business.BaseCurrencyId, fromDate, accountCashTypes,
+		businessID, balanceBranchIds, business.BaseCurrencyId, fromDate, toDate, accountIncomeExpenseTypes,
+		businessID, balanceBranchIds, business.BaseCurrencyId, fromDate, toDate, accountMainTypes, accountCashTypes,
+		businessID, balanceBranchIds, business.BaseCurrencyId, fromDate, toDate, accountMainTypes, accountCashTypes,
 	).Rows()
 
 	if err != nil {
@@ -24,6 +24,10 @@ type CreditNoteDetailResponse struct {
 }
 
 func GetCreditNoteDetailsReport(ctx context.Context, fromDate models.MyDateString, toDate models.MyDateString, branchID *int, warehouseID *int) ([]*CreditNoteDetailResponse, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
 
 	sqlT := `
 SELECT
@@ -77,7 +81,7 @@ FROM
 	AND cn.credit_note_date BETWEEN @fromDate AND @toDate
     AND cn.current_status NOT IN ('Draft', 'Void')
     AND (cn_outbox.processing_status IS NULL OR cn_outbox.processing_status <> 'DEAD')
-	{{- if .branchId }} AND cn.branch_id = @branchId {{- end }}
+	{{- if .branchIds }} AND cn.branch_id IN @branchIds {{- end }}
 	{{- if .warehouseId }} AND cn.warehouse_id = @warehouseId {{- end }}
 ORDER BY cn.credit_note_date;
 	`
@@ -96,7 +100,7 @@ ORDER BY cn.credit_note_date;
 	}
 
 	sql, err := utils.ExecTemplate(sqlT, map[string]interface{}{
-		"branchId":    utils.DereferencePtr(branchID, 0) > 0,
+		"branchIds":   len(branchIds) > 0,
 		"warehouseId": utils.DereferencePtr(warehouseID, 0) > 0,
 	})
 	if err != nil {
@@ -108,7 +112,7 @@ ORDER BY cn.credit_note_date;
 		"baseCurrencyId": business.BaseCurrencyId,
 		"fromDate":       fromDate,
 		"toDate":         toDate,
-		"branchId":       branchID,
+		"branchIds":      branchIds,
 		"warehouseId":    warehouseID,
 	}).Scan(&results).Error; err != nil {
 		return nil, err
@@ -33,6 +33,10 @@ type CustomerBalance struct {
 }
 
 func GetCustomerBalanceReport(ctx context.Context, toDate *models.MyDateString, branchId *int) ([]*CustomerBalance, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchId)
+	if err != nil {
+		return nil, err
+	}
 	sql := `
 WITH AvailableAdvance AS (
     select
@@ -44,7 +48,7 @@ WITH AvailableAdvance AS (
         customer_credit_advances cca
     where
         business_id = @businessId
-        {{- if .branchId }} AND branch_id = @branchId {{- end }}
+        {{- if .branchIds }} AND branch_id IN @branchIds {{- end }}
         AND NOT cca.current_status IN ('Draft', 'Void')  AND cca.date <= @toDate
     group by
         cca.customer_id,
@@ -74,7 +78,7 @@ AvailableCredit AS (
         LEFT JOIN pub_sub_message_records cn_outbox ON cn_outbox.id = lcn.max_id
     where
         cn.business_id = @businessId
-        {{- if .branchId }} AND cn.branch_id = @branchId {{- end }}
+        {{- if .branchIds }} AND cn.branch_id IN @branchIds {{- end }}
         AND NOT cn.current_status IN ('Draft', 'Void') AND cn.credit_note_date <= @toDate
         AND (cn_outbox.processing_status IS NULL OR cn_outbox.processing_status <> 'DEAD')
     group by
@@ -107,7 +111,7 @@ SalesInvoice AS (
         LEFT JOIN pub_sub_message_records outbox ON outbox.id = lio.max_id
     where
         iv.business_id = @businessId
-        {{- if .branchId }} AND iv.branch_id = @branchId {{- end }}
+        {{- if .branchIds }} AND iv.branch_id IN @branchIds {{- end }}
         AND NOT iv.current_status IN ('Draft', 'Void')
         AND iv.invoice_date <= @toDate
         AND (outbox.processing_status IS NULL OR outbox.processing_status <> 'DEAD')
@@ -228,7 +232,7 @@ ORDER by customer_name, currency_id
 	var results []*CustomerBalance
 	db := config.GetDB()
 	sql, err = utils.ExecTemplate(sql, map[string]interface{}{
-		"branchId": utils.DereferencePtr(branchId, 0),
+		"branchIds": len(branchIds) > 0,
 	})
 	if err != nil {
 		return nil, err
@@ -237,7 +241,7 @@ ORDER by customer_name, currency_id
 		"businessId":     business.ID,
 		"baseCurrencyId": business.BaseCurrencyId,
 		"toDate":         date,
-		"branchId":       branchId,
+		"branchIds":      branchIds,
 	}).Scan(&results).Error; err != nil {
 		return nil, err
 	}
@@ -250,6 +254,10 @@ ORDER by customer_name, currency_id
 }
 
 func GetCustomerBalanceSummaryReport(ctx context.Context, toDate *models.MyDateString, branchId *int) ([]*CustomerBalance, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchId)
+	if err != nil {
+		return nil, err
+	}
 
 	sqlT := `
 WITH AccTransactionSummary AS
@@ -307,7 +315,7 @@ WITH AccTransactionSummary AS
         AND aj.reversed_by_journal_id IS NULL
         AND at.account_id = @receivableAccId
         {{- if .toDate }} AND aj.transaction_date_time <= @transactionDate {{- end }}
-        {{- if .branchId }} AND aj.branch_id = @branchId {{- end }}
+        {{- if .branchIds }} AND aj.branch_id IN @branchIds {{- end }}
     GROUP BY
         aj.customer_id,
         currency_id
@@ -337,7 +345,7 @@ CreditNoteIssued AS (
         LEFT JOIN pub_sub_message_records cn_outbox ON cn_outbox.id = lcn.max_id
     WHERE
         cn.business_id = @businessId
-        {{- if .branchId }} AND cn.branch_id = @branchId {{- end }}
+        {{- if .branchIds }} AND cn.branch_id IN @branchIds {{- end }}
         AND NOT cn.current_status IN ('Draft', 'Void')
         AND (cn_outbox.processing_status IS NULL OR cn_outbox.processing_status <> 'DEAD')
         {{- if .toDate }} AND cn.credit_note_date <= @transactionDate {{- end }}
@@ -358,7 +366,7 @@ CreditNoteAppliedByNote AS (
     WHERE
         cci.business_id = @businessId
         AND cci.reference_type = 'Credit'
-        {{- if .branchId }} AND cci.branch_id = @branchId {{- end }}
+        {{- if .branchIds }} AND cci.branch_id IN @branchIds {{- end }}
         {{- if .toDate }} AND cci.created_at <= @transactionDate {{- end }}
     GROUP BY
         cci.reference_id
@@ -454,8 +462,8 @@ ORDER BY customer_name, currency_symbol
 	var results []*CustomerBalance
 	db := config.GetDB()
 	sql, err := utils.ExecTemplate(sqlT, map[string]interface{}{
-		"toDate":   toDate != nil,
-		"branchId": utils.DereferencePtr(branchId, 0) > 0,
+		"toDate":    toDate != nil,
+		"branchIds": len(branchIds) > 0,
 	})
 	if err != nil {
 		return nil, err
@@ -469,7 +477,7 @@ ORDER BY customer_name, currency_symbol
 		"businessId":      business.ID,
 		"baseCurrencyId":  business.BaseCurrencyId,
 		"transactionDate": transactionDate,
-		"branchId":        branchId,
+		"branchIds":       branchIds,
 		"receivableAccId": accs[models.AccountCodeAccountsReceivable],
 	}).Scan(&results).Error; err != nil {
 		return nil, err
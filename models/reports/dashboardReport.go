@@ -74,6 +74,8 @@ func GetTotalPayableReceivable(ctx context.Context) ([]*PayableReceivableRespons
 	if err != nil {
 		return nil, err
 	}
+	// branch 0 means every branch
+	branchIds := dashboardBranchIds(ctx)
 
 	currency, err := models.GetCurrency(ctx, business.BaseCurrencyId)
 	if err != nil {
@@ -128,6 +130,7 @@ func GetTotalPayableReceivable(ctx context.Context) ([]*PayableReceivableRespons
             b.business_id = ?
             AND b.bill_date < ?
             AND b.current_status IN ?
+            AND (0 IN ? OR b.branch_id IN ?)
 			AND b.remaining_balance > 0
             AND (b_outbox.processing_status IS NULL OR b_outbox.processing_status <> 'DEAD')
     )
@@ -197,6 +200,7 @@ func GetTotalPayableReceivable(ctx context.Context) ([]*PayableReceivableRespons
             inv.business_id = ?
             AND inv.invoice_date < ?
             AND inv.current_status IN ?
+            AND (0 IN ? OR inv.branch_id IN ?)
 			AND inv.remaining_balance > 0
             AND (iv_outbox.processing_status IS NULL OR iv_outbox.processing_status <> 'DEAD')
     )
@@ -237,14 +241,14 @@ func GetTotalPayableReceivable(ctx context.Context) ([]*PayableReceivableRespons
 
 	//  payable query
 	if err := db.Raw(payableQuery,
-		businessId, business.BaseCurrencyId, currentDate, businessId, currentDate, billStatus).
+		businessId, business.BaseCurrencyId, currentDate, businessId, currentDate, billStatus, branchIds, branchIds).
 		Scan(&payableReceivableResponse.TotalPayable).Error; err != nil {
 		return nil, err
 	}
 
 	// receivable query
 	if err := db.Raw(receivableQuery,
-		businessId, business.BaseCurrencyId, currentDate, businessId, currentDate, invoiceStatus).
+		businessId, business.BaseCurrencyId, currentDate, businessId, currentDate, invoiceStatus, branchIds, branchIds).
 		Scan(&payableReceivableResponse.TotalReceivable).Error; err != nil {
 		return nil, err
 	}
@@ -267,6 +271,7 @@ func GetTotalIncomeExpense(ctx context.Context, filterType string) (*IncomeExpen
 	if err != nil {
 		return nil, err
 	}
+	branchIds := dashboardBranchIds(ctx)
 
 	fiscalYearStartMonth, err := utils.GetFiscalYearStartMonth(string(business.FiscalYear))
 	if err != nil {
@@ -299,7 +304,7 @@ func GetTotalIncomeExpense(ctx context.Context, filterType string) (*IncomeExpen
 						transaction_date >= ?
 						AND transaction_date <= ?
 						AND business_id = ?
-						AND branch_id = 0
+						AND branch_id IN ?
 						AND currency_id = ?
 					GROUP BY DATE_FORMAT(transaction_date, '%Y-%m')
 				)
@@ -317,7 +322,7 @@ func GetTotalIncomeExpense(ctx context.Context, filterType string) (*IncomeExpen
 
 	rows, err := db.Raw(query,
 		startDate, endDate,
-		startDate, endDate, businessId, business.BaseCurrencyId).Rows()
+		startDate, endDate, businessId, branchIds, business.BaseCurrencyId).Rows()
 
 	// Rollout safety: if daily_summaries isn't available yet, fall back to the legacy
 	// calculation from account_currency_daily_balances so the dashboard keeps working.
@@ -342,7 +347,7 @@ func GetTotalIncomeExpense(ctx context.Context, filterType string) (*IncomeExpen
 						acb.transaction_date >= ?
 						AND acb.transaction_date <= ?
 						AND acb.business_id = ?
-						AND acb.branch_id = 0
+						AND acb.branch_id IN ?
 						AND acb.currency_id = ?
 						AND a.main_type IN ('Income', 'Expense')
 					GROUP BY DATE_FORMAT(acb.transaction_date, '%Y-%m')
@@ -360,7 +365,7 @@ func GetTotalIncomeExpense(ctx context.Context, filterType string) (*IncomeExpen
                 `
 		rows, err = db.Raw(legacyQuery,
 			startDate, endDate,
-			startDate, endDate, businessId, business.BaseCurrencyId).Rows()
+			startDate, endDate, businessId, branchIds, business.BaseCurrencyId).Rows()
 	}
 
 	if err != nil {
@@ -417,6 +422,7 @@ func GetTopExpense(ctx context.Context, filterType string) ([]*TopExpensesRespon
 	if err != nil {
 		return nil, err
 	}
+	branchIds := dashboardBranchIds(ctx)
 
 	expense := string(models.AccountMainTypeExpense)
 
@@ -441,7 +447,7 @@ func GetTopExpense(ctx context.Context, filterType string) ([]*TopExpensesRespon
                     ac.name AS account_name,
                     acb.account_id AS account_id,
                     acb.running_balance AS amount,
-                    ROW_NUMBER() OVER (PARTITION BY acb.account_id ORDER BY acb.transaction_date DESC) AS row_num
+                    ROW_NUMBER() OVER (PARTITION BY acb.account_id, acb.branch_id ORDER BY acb.transaction_date DESC) AS row_num
                 FROM 
                     account_currency_daily_balances AS acb
                 JOIN
@@ -450,7 +456,7 @@ func GetTopExpense(ctx context.Context, filterType string) ([]*TopExpensesRespon
                     acb.transaction_date >= ?
                     AND acb.transaction_date <= ?
                     AND acb.business_id = ?
-                    AND acb.branch_id = 0
+                    AND acb.branch_id IN ?
                     AND acb.currency_id = ?
                     AND acb.account_id IN (
                         SELECT id FROM accounts WHERE accounts.main_type = ? AND accounts.business_id = ?
@@ -458,16 +464,18 @@ func GetTopExpense(ctx context.Context, filterType string) ([]*TopExpensesRespon
             )
             SELECT 
                 account_name,
-                amount
+                SUM(amount) AS amount
             FROM 
                 TopExpenses
             WHERE 
                 row_num = 1
+            GROUP BY
+                account_id, account_name
             ORDER BY 
                 amount DESC`
 
 	if err := db.Raw(query,
-		startDate, endDate, businessId, business.BaseCurrencyId, expense, businessId).
+		startDate, endDate, businessId, branchIds, business.BaseCurrencyId, expense, businessId).
 		Scan(&topExpenses).Error; err != nil {
 		return nil, err
 	}
@@ -487,6 +495,7 @@ func GetTotalCashFlow(ctx context.Context, filterType string) (*CashFlowReponse,
 	if err != nil {
 		return nil, err
 	}
+	branchIds := dashboardBranchIds(ctx)
 
 	accountTypes := []string{
 		string(models.AccountDetailTypeCash),
@@ -528,7 +537,7 @@ func GetTotalCashFlow(ctx context.Context, filterType string) (*CashFlowReponse,
 					LEFT JOIN account_currency_daily_balances AS acb
 						ON acb.transaction_date < ml.month_date
 						AND acb.business_id = ?
-						AND acb.branch_id = 0
+						AND acb.branch_id IN ?
 						AND acb.currency_id = ?
 						AND acb.account_id IN (
 							SELECT id FROM accounts WHERE accounts.detail_type IN ? AND accounts.business_id = ?
@@ -548,7 +557,7 @@ func GetTotalCashFlow(ctx context.Context, filterType string) (*CashFlowReponse,
 						acb.transaction_date >= ?
 						AND acb.transaction_date <= ?
 						AND acb.business_id = ?
-						AND acb.branch_id = 0
+						AND acb.branch_id IN ?
 						AND acb.currency_id = ?
 						AND acb.account_id IN (
 							SELECT id FROM accounts WHERE accounts.detail_type IN ? AND accounts.business_id = ?
@@ -576,8 +585,8 @@ func GetTotalCashFlow(ctx context.Context, filterType string) (*CashFlowReponse,
                 `
 
 	rows, err := db.Raw(query,
-		startDate, endDate, businessId, business.BaseCurrencyId, accountTypes, businessId,
-		startDate, endDate, businessId, business.BaseCurrencyId, accountTypes, businessId,
+		startDate, endDate, businessId, branchIds, business.BaseCurrencyId, accountTypes, businessId,
+		startDate, endDate, businessId, branchIds, business.BaseCurrencyId, accountTypes, businessId,
 	).Rows()
 
 	if err != nil {
@@ -634,3 +643,13 @@ func GetTotalCashFlow(ctx context.Context, filterType string) (*CashFlowReponse,
 
 	return response, nil
 }
+
+// dashboardBranchIds returns the branches the dashboard totals are summed over.
+// Branch 0 holds the totals of every branch; users limited to some branches only
+// see theirs.
+func dashboardBranchIds(ctx context.Context) []int {
+	if branchIds, ok := utils.GetBranchIdsFromContext(ctx); ok {
+		return branchIds
+	}
+	return []int{0}
+}
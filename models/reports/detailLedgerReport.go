@@ -11,6 +11,7 @@ import (
 	"github.com/mmdatafocus/books_backend/models"
 	"github.com/mmdatafocus/books_backend/utils"
 	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
 type DetailedGeneralLedgerReportConnection struct {
@@ -24,6 +25,10 @@ type DetailedGeneralLedgerReportEdge struct {
 }
 
 func PaginateDetailedGeneralLedgerReport(ctx context.Context, limit *int, after *string, fromDate models.MyDateString, toDate models.MyDateString, reportType string, branchID *int) (*DetailedGeneralLedgerReportConnection, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
 
 	businessId, ok := utils.GetBusinessIdFromContext(ctx)
 	if !ok || businessId == "" {
@@ -45,11 +50,6 @@ func PaginateDetailedGeneralLedgerReport(ctx context.Context, limit *int, after
 	// db = db.Session(&gorm.Session{
 	// 	Logger: config.WriteGormLog(), // Apply the custom logger
 	// })
-	// Initialize branchID to 0 if it's nil
-	if branchID == nil {
-		branchID = new(int)
-		*branchID = 0
-	}
 
 	var results []*models.DetailLedgerTransaction
 	// query := db.Raw(`
@@ -145,15 +145,15 @@ func PaginateDetailedGeneralLedgerReport(ctx context.Context, limit *int, after
             AND account_journals.is_reversal = 0
             AND account_journals.reversed_by_journal_id IS NULL
             AND account_transactions.transaction_date_time BETWEEN @fromDate AND @toDate
-			 {{- if not .AllBranch }}
-                AND account_transactions.branch_id = @branchId
+			 {{- if .branchIds }}
+                AND account_transactions.branch_id IN @branchIds
             {{- end }}
         ORDER BY 
             accounts.name, account_transactions.transaction_date_time ASC
 	`
 
 	sql, err := utils.ExecTemplate(sqlT, map[string]interface{}{
-		"AllBranch": *branchID == 0,
+		"branchIds": len(branchIds) > 0,
 	})
 	if err != nil {
 		return nil, err
@@ -162,7 +162,7 @@ func PaginateDetailedGeneralLedgerReport(ctx context.Context, limit *int, after
 		"businessId": businessId,
 		"fromDate":   fromDate,
 		"toDate":     toDate,
-		"branchId":   branchID,
+		"branchIds":  branchIds,
 	}).Scan(&results).Error; err != nil {
 		return nil, err
 	}
@@ -228,31 +228,10 @@ func PaginateDetailedGeneralLedgerReport(ctx context.Context, limit *int, after
 			account.Transactions[i] = detailTransaction
 		}
 
-		var dailyBalances []*models.AccountCurrencyDailyBalance
-		query := db.Raw(`
-            (SELECT * FROM account_currency_daily_balances
-            WHERE business_id = ? AND account_id = ? AND branch_id = ? AND currency_id = ? AND transaction_date BETWEEN ? AND ?
-            ORDER BY transaction_date ASC LIMIT 1)
-            UNION ALL
-            (SELECT * FROM account_currency_daily_balances
-            WHERE business_id = ? AND account_id = ? AND branch_id = ? AND currency_id = ? AND transaction_date BETWEEN ? AND ?
-            ORDER BY transaction_date DESC LIMIT 1)
-        `, businessId, transactions[0].AccountId, branchID, transactions[0].BaseCurrencyId, fromDate, toDate,
-			businessId, transactions[0].AccountId, branchID, transactions[0].BaseCurrencyId, fromDate, toDate)
-
-		err := query.Scan(&dailyBalances).Error
-
+		account.OpeningBalance, account.ClosingBalance, err = detailLedgerBalances(db, businessId, transactions[0].AccountId, transactions[0].BaseCurrencyId, branchIds, fromDate, toDate)
 		if err != nil {
 			return nil, err
 		}
-
-		if len(dailyBalances) > 0 {
-			account.OpeningBalance = dailyBalances[0].RunningBalance.Sub(dailyBalances[0].Balance)
-			account.ClosingBalance = dailyBalances[len(dailyBalances)-1].RunningBalance
-		} else {
-			account.OpeningBalance = decimal.NewFromInt(0)
-			account.ClosingBalance = decimal.NewFromInt(0)
-		}
 		cursor := fmt.Sprintf("%s|%s", transactions[0].TransactionDateTime.String(), accountName)
 		// Create the edge for the current account
 		edge := &DetailedGeneralLedgerReportEdge{
@@ -440,6 +419,10 @@ func paginateResults(results []*DetailedGeneralLedgerReportEdge, limit *int, aft
 // }
 
 func GetAllDetailedGeneralLedgerReport(ctx context.Context, fromDate models.MyDateString, toDate models.MyDateString, reportType string, branchID *int) ([]*models.DetailedGeneralLedger, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
 
 	businessId, ok := utils.GetBusinessIdFromContext(ctx)
 	if !ok || businessId == "" {
@@ -459,12 +442,6 @@ func GetAllDetailedGeneralLedgerReport(ctx context.Context, fromDate models.MyDa
 
 	db := config.GetDB()
 
-	// Initialize branchID to 0 if it's nil
-	if branchID == nil {
-		branchID = new(int)
-		*branchID = 0
-	}
-
 	var detailedLedgerTransactions []*models.DetailLedgerTransaction
 	query := db.Raw(`
         SELECT 
@@ -509,13 +486,10 @@ func GetAllDetailedGeneralLedgerReport(ctx context.Context, fromDate models.MyDa
             AND account_journals.is_reversal = 0
             AND account_journals.reversed_by_journal_id IS NULL
             AND account_transactions.transaction_date_time BETWEEN ? AND ?
+            AND (? OR account_transactions.branch_id IN ?)
         ORDER BY 
             accounts.name, account_transactions.transaction_date_time ASC
-    `, businessId, fromDate, toDate)
-
-	if branchID != nil && *branchID > 0 {
-		query.Where("branch_id = ?", branchID)
-	}
+    `, businessId, fromDate, toDate, len(branchIds) == 0, models.BalanceBranchIds(branchIds))
 
 	err = query.Scan(&detailedLedgerTransactions).Error
 
@@ -583,34 +557,53 @@ func GetAllDetailedGeneralLedgerReport(ctx context.Context, fromDate models.MyDa
 			account.Transactions[i] = detailTransaction
 		}
 
-		var dailyBalances []*models.AccountCurrencyDailyBalance
-		query := db.Raw(`
-            (SELECT * FROM account_currency_daily_balances
-            WHERE business_id = ? AND account_id = ? AND branch_id = ? AND currency_id = ? AND transaction_date BETWEEN ? AND ?
-            ORDER BY transaction_date ASC LIMIT 1)
-            UNION ALL
-            (SELECT * FROM account_currency_daily_balances
-            WHERE business_id = ? AND account_id = ? AND branch_id = ? AND currency_id = ? AND transaction_date BETWEEN ? AND ?
-            ORDER BY transaction_date DESC LIMIT 1)
-        `, businessId, transactions[0].AccountId, branchID, transactions[0].BaseCurrencyId, fromDate, toDate,
-			businessId, transactions[0].AccountId, branchID, transactions[0].BaseCurrencyId, fromDate, toDate)
-
-		err := query.Scan(&dailyBalances).Error
-
+		account.OpeningBalance, account.ClosingBalance, err = detailLedgerBalances(db, businessId, transactions[0].AccountId, transactions[0].BaseCurrencyId, branchIds, fromDate, toDate)
 		if err != nil {
 			return nil, err
 		}
 
-		if len(dailyBalances) > 0 {
-			account.OpeningBalance = dailyBalances[0].RunningBalance.Sub(dailyBalances[0].Balance)
-			account.ClosingBalance = dailyBalances[len(dailyBalances)-1].RunningBalance
-		} else {
-			account.OpeningBalance = decimal.NewFromInt(0)
-			account.ClosingBalance = decimal.NewFromInt(0)
-		}
-
 		results = append(results, account)
 	}
 
 	return results, nil
 }
+
+// detailLedgerBalances sums the opening and closing balances of the branches: the
+// balance before the first day and the running balance of the last day with movements.
+func detailLedgerBalances(db *gorm.DB, businessId string, accountId int, currencyId int, branchIds []int, fromDate models.MyDateString, toDate models.MyDateString) (decimal.Decimal, decimal.Decimal, error) {
+	var balances struct {
+		OpeningBalance decimal.Decimal
+		ClosingBalance decimal.Decimal
+	}
+	err := db.Raw(`
+        SELECT
+            COALESCE((
+                SELECT SUM(running_balance - balance) FROM account_currency_daily_balances
+                WHERE business_id = @businessId AND account_id = @accountId AND currency_id = @currencyId
+                AND (branch_id, transaction_date) IN (
+                    SELECT branch_id, MIN(transaction_date) FROM account_currency_daily_balances
+                    WHERE business_id = @businessId AND account_id = @accountId AND branch_id IN @branchIds AND currency_id = @currencyId
+                    AND transaction_date BETWEEN @fromDate AND @toDate
+                    GROUP BY branch_id
+                )
+            ), 0) AS opening_balance,
+            COALESCE((
+                SELECT SUM(running_balance) FROM account_currency_daily_balances
+                WHERE business_id = @businessId AND account_id = @accountId AND currency_id = @currencyId
+                AND (branch_id, transaction_date) IN (
+                    SELECT branch_id, MAX(transaction_date) FROM account_currency_daily_balances
+                    WHERE business_id = @businessId AND account_id = @accountId AND branch_id IN @branchIds AND currency_id = @currencyId
+                    AND transaction_date BETWEEN @fromDate AND @toDate
+                    GROUP BY branch_id
+                )
+            ), 0) AS closing_balance
+    `, map[string]interface{}{
+		"businessId": businessId,
+		"accountId":  accountId,
+		"currencyId": currencyId,
+		"branchIds":  models.BalanceBranchIds(branchIds),
+		"fromDate":   fromDate,
+		"toDate":     toDate,
+	}).Scan(&balances).Error
+	return balances.OpeningBalance, balances.ClosingBalance, err
+}
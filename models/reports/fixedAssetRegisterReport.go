@@ -0,0 +1,125 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+)
+
+type FixedAssetRegisterResponse struct {
+	FixedAssetID            int                                 `json:"fixedAssetId"`
+	AssetNumber             string                              `json:"assetNumber"`
+	Name                    string                              `json:"name"`
+	CategoryID              int                                 `json:"categoryId"`
+	CategoryName            string                              `json:"categoryName"`
+	BranchID                int                                 `json:"branchId"`
+	AcquisitionDate         time.Time                           `json:"acquisitionDate"`
+	DepreciationMethod      models.FixedAssetDepreciationMethod `json:"depreciationMethod"`
+	Status                  models.FixedAssetStatus             `json:"status"`
+	DisposalDate            *time.Time                          `json:"disposalDate"`
+	AcquisitionCost         decimal.Decimal                     `json:"acquisitionCost"`
+	AccumulatedDepreciation decimal.Decimal                     `json:"accumulatedDepreciation"`
+	BookValue               decimal.Decimal                     `json:"bookValue"`
+}
+
+// GetFixedAssetRegisterReport lists assets acquired by asOfDate with their cost, depreciation
+// posted up to that date and book value. Assets disposed before asOfDate are left out.
+func GetFixedAssetRegisterReport(ctx context.Context, asOfDate models.MyDateString, categoryID *int, branchID *int) ([]*FixedAssetRegisterResponse, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlTemplate := `
+SELECT
+	fa.id AS fixed_asset_id,
+	fa.asset_number,
+	fa.name,
+	fa.category_id,
+	cat.name AS category_name,
+	fa.branch_id,
+	fa.acquisition_date,
+	fa.depreciation_method,
+	(
+		CASE
+			WHEN fa.disposal_date IS NOT NULL AND fa.disposal_date <= @asOfDate THEN fa.status
+			WHEN fa.status IN ('DISPOSED', 'WRITTEN_OFF') THEN 'ACTIVE'
+			ELSE fa.status
+		END
+	) AS status,
+	(
+		CASE
+			WHEN fa.disposal_date <= @asOfDate THEN fa.disposal_date
+			ELSE NULL
+		END
+	) AS disposal_date,
+	fa.acquisition_cost,
+	COALESCE(dep.accumulated_depreciation, 0) AS accumulated_depreciation
+FROM
+	fixed_assets fa
+	LEFT JOIN fixed_asset_categories cat ON cat.id = fa.category_id
+	LEFT JOIN (
+		SELECT
+			fixed_asset_id,
+			SUM(amount) AS accumulated_depreciation
+		FROM
+			fixed_asset_depreciations
+		WHERE
+			business_id = @businessId
+			AND period_date <= @asOfDate
+		GROUP BY
+			fixed_asset_id
+	) dep ON dep.fixed_asset_id = fa.id
+WHERE fa.business_id = @businessId
+	AND fa.acquisition_date <= @asOfDate
+	AND (fa.disposal_date IS NULL OR fa.disposal_date >= @fromDate)
+	{{- if .categoryId }} AND fa.category_id = @categoryId {{- end }}
+	{{- if .branchIds }} AND fa.branch_id IN @branchIds {{- end }}
+ORDER BY
+	fa.asset_number
+`
+	var results []*FixedAssetRegisterResponse
+
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	business, err := models.GetBusiness(ctx)
+	if err != nil {
+		return nil, errors.New("business id is required")
+	}
+	fromDate := asOfDate
+	if err := fromDate.StartOfDayUTCTime(business.Timezone); err != nil {
+		return nil, err
+	}
+	if err := asOfDate.EndOfDayUTCTime(business.Timezone); err != nil {
+		return nil, err
+	}
+
+	sql, err := utils.ExecTemplate(sqlTemplate, map[string]interface{}{
+		"categoryId": utils.DereferencePtr(categoryID, 0),
+		"branchIds":  len(branchIds) > 0,
+	})
+	if err != nil {
+		return nil, err
+	}
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Raw(sql, map[string]interface{}{
+		"businessId": businessId,
+		"fromDate":   fromDate,
+		"asOfDate":   asOfDate,
+		"categoryId": categoryID,
+		"branchIds":  branchIds,
+	}).Scan(&results).Error; err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		result.BookValue = result.AcquisitionCost.Sub(result.AccumulatedDepreciation)
+	}
+	return results, nil
+}
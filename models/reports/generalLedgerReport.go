@@ -10,6 +10,10 @@ import (
 )
 
 func GetGeneralLedgerReport(ctx context.Context, fromDate models.MyDateString, toDate models.MyDateString, reportType string, branchID *int) ([]*models.AccountSummary, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
 	businessId, ok := utils.GetBusinessIdFromContext(ctx)
 	if !ok || businessId == "" {
 		return nil, errors.New("business id is required")
@@ -27,11 +31,7 @@ func GetGeneralLedgerReport(ctx context.Context, fromDate models.MyDateString, t
 
 	db := config.GetDB()
 
-	// Initialize branchID to 0 if it's nil
-	if branchID == nil {
-		branchID = new(int)
-		*branchID = 0
-	}
+	balanceBranchIds := models.BalanceBranchIds(branchIds)
 
 	query := `
         SELECT 
@@ -47,51 +47,43 @@ func GetGeneralLedgerReport(ctx context.Context, fromDate models.MyDateString, t
             -- ABS((acb.running_balance) - (acb.balance)) AS opening_balance,
             (
 				SELECT 
-					CASE WHEN acc.main_type IN ('Asset', 'Expense') THEN running_balance ELSE -running_balance END
-				FROM 
-					account_currency_daily_balances AS acb_first
-				WHERE 
-					acb_first.account_id = acc.id
-					AND acb_first.branch_id = ?
-					AND acb_first.currency_id = ?
-					AND acb_first.transaction_date BETWEEN ? AND ?
-				ORDER BY 
-					transaction_date ASC
-				LIMIT 1
-			) - 
-			(
-				SELECT 
-					CASE WHEN acc.main_type IN ('Asset', 'Expense') THEN balance ELSE -balance END 
+					SUM(CASE WHEN acc.main_type IN ('Asset', 'Expense') THEN running_balance - balance ELSE balance - running_balance END)
 				FROM 
 					account_currency_daily_balances AS acb_first
 				WHERE 
 					acb_first.account_id = acc.id
-					AND acb_first.branch_id = ?
-					AND acb_first.currency_id = ?
-					AND acb_first.transaction_date BETWEEN ? AND ?
-				ORDER BY 
-					transaction_date ASC 
-				LIMIT 1
+					AND (acb_first.branch_id, acb_first.currency_id, acb_first.transaction_date) IN (
+						SELECT branch_id, currency_id, MIN(transaction_date)
+						FROM account_currency_daily_balances
+						WHERE account_id = acc.id
+							AND branch_id IN ?
+							AND currency_id = ?
+							AND transaction_date BETWEEN ? AND ?
+						GROUP BY branch_id, currency_id
+					)
 			) AS opening_balance,
             (
                 SELECT 
-					CASE WHEN acc.main_type IN ('Asset', 'Expense') THEN running_balance ELSE -running_balance END
+					SUM(CASE WHEN acc.main_type IN ('Asset', 'Expense') THEN running_balance ELSE -running_balance END)
                 FROM 
 					account_currency_daily_balances AS acb_inner
                 WHERE 
 					acb_inner.account_id = acc.id
-                	AND acb_inner.branch_id = ?
-					AND acb_inner.currency_id = ?
-                	AND acb_inner.transaction_date BETWEEN ? AND ?
-                ORDER BY 
-					transaction_date DESC 
-                LIMIT 1
+					AND (acb_inner.branch_id, acb_inner.currency_id, acb_inner.transaction_date) IN (
+						SELECT branch_id, currency_id, MAX(transaction_date)
+						FROM account_currency_daily_balances
+						WHERE account_id = acc.id
+							AND branch_id IN ?
+							AND currency_id = ?
+							AND transaction_date BETWEEN ? AND ?
+						GROUP BY branch_id, currency_id
+					)
             ) AS closing_balance
         FROM 
             accounts AS acc
         LEFT JOIN
             account_currency_daily_balances AS acb ON acb.account_id = acc.id
-            AND acb.branch_id = ?
+            AND acb.branch_id IN ?
 			AND acb.currency_id = ?
             AND acb.transaction_date BETWEEN ? AND ?
         WHERE 
@@ -106,10 +98,9 @@ func GetGeneralLedgerReport(ctx context.Context, fromDate models.MyDateString, t
 	`
 
 	rows, err := db.Raw(query,
-		*branchID, business.BaseCurrencyId, fromDate, toDate,
-		*branchID, business.BaseCurrencyId, fromDate, toDate,
-		*branchID, business.BaseCurrencyId, fromDate, toDate,
-		*branchID, business.BaseCurrencyId, fromDate, toDate,
+		balanceBranchIds, business.BaseCurrencyId, fromDate, toDate,
+		balanceBranchIds, business.BaseCurrencyId, fromDate, toDate,
+		balanceBranchIds, business.BaseCurrencyId, fromDate, toDate,
 		businessId,
 	).Rows()
 
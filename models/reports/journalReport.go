@@ -90,6 +90,10 @@ type JournalReportEdge struct {
 // }
 
 func PaginateJournalReport(ctx context.Context, limit *int, after *string, fromDate models.MyDateString, toDate models.MyDateString, reportType string, branchID *int) (*JournalReportConnection, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
 	businessID, ok := utils.GetBusinessIdFromContext(ctx)
 	if !ok || businessID == "" {
 		return nil, errors.New("business ID is required")
@@ -114,8 +118,8 @@ func PaginateJournalReport(ctx context.Context, limit *int, after *string, fromD
 	db := config.GetDB()
 	dbCtx := db.WithContext(ctx).Preload("AccountTransactions").Where("business_id = ?", businessID)
 
-	if branchID != nil && *branchID > 0 {
-		dbCtx = dbCtx.Where("branch_id = ?", *branchID)
+	if len(branchIds) > 0 {
+		dbCtx = dbCtx.Where("branch_id IN ?", branchIds)
 	}
 
 	dbCtx = dbCtx.Where("transaction_date_time BETWEEN ? AND ?", fromDate, toDate)
@@ -170,6 +174,10 @@ func PaginateJournalReport(ctx context.Context, limit *int, after *string, fromD
 }
 
 func GetAllJournalReport(ctx context.Context, fromDate models.MyDateString, toDate models.MyDateString, reportType string, branchID *int) ([]*models.AccountJournal, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
 	businessID, ok := utils.GetBusinessIdFromContext(ctx)
 	if !ok || businessID == "" {
 		return nil, errors.New("business ID is required")
@@ -189,8 +197,8 @@ func GetAllJournalReport(ctx context.Context, fromDate models.MyDateString, toDa
 	db := config.GetDB()
 	dbCtx := db.WithContext(ctx).Preload("AccountTransactions").Where("business_id = ?", businessID)
 
-	if branchID != nil && *branchID > 0 {
-		dbCtx = dbCtx.Where("branch_id = ?", *branchID)
+	if len(branchIds) > 0 {
+		dbCtx = dbCtx.Where("branch_id IN ?", branchIds)
 	}
 
 	dbCtx = dbCtx.Where("transaction_date_time BETWEEN ? AND ?", fromDate, toDate)
@@ -0,0 +1,13 @@
+package reports
+
+import (
+	"context"
+
+	"github.com/mmdatafocus/books_backend/models"
+)
+
+type LowStockResponse = models.LowStockResponse
+
+func GetLowStockReport(ctx context.Context, warehouseId *int, supplierId *int) ([]*LowStockResponse, error) {
+	return models.GetLowStockLedger(ctx, warehouseId, supplierId)
+}
@@ -18,6 +18,10 @@ type MovementOfEquityResponse struct {
 }
 
 func GetMovementOfEquityReport(ctx context.Context, fromDate models.MyDateString, toDate models.MyDateString, reportType string, branchID *int) ([]*MovementOfEquityResponse, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
 	businessID, ok := utils.GetBusinessIdFromContext(ctx)
 	if !ok || businessID == "" {
 		return nil, errors.New("business ID is required")
@@ -35,11 +39,7 @@ func GetMovementOfEquityReport(ctx context.Context, fromDate models.MyDateString
 
 	db := config.GetDB()
 
-	// Initialize branchID to 0 if it's nil
-	if branchID == nil {
-		branchID = new(int)
-		*branchID = 0
-	}
+	balanceBranchIds := models.BalanceBranchIds(branchIds)
 
 	query := `
         WITH OpeningBalance AS (
@@ -55,7 +55,7 @@ func GetMovementOfEquityReport(ctx context.Context, fromDate models.MyDateString
 				accounts AS ac ON acb.account_id = ac.id
 			WHERE 
 				acb.business_id = ? 
-				AND acb.branch_id = ?
+				AND acb.branch_id IN ?
 				AND acb.currency_id = ?
 				AND acb.transaction_date < ?
 				AND acb.account_id IN (
@@ -66,14 +66,14 @@ func GetMovementOfEquityReport(ctx context.Context, fromDate models.MyDateString
 			SELECT 
 				ac.detail_type AS detail_type,
 				acb.running_balance AS amount,
-				ROW_NUMBER() OVER (PARTITION BY acb.account_id ORDER BY acb.transaction_date DESC) AS row_num
+				ROW_NUMBER() OVER (PARTITION BY acb.account_id, acb.branch_id ORDER BY acb.transaction_date DESC) AS row_num
 			FROM 
 				account_currency_daily_balances AS acb
 			JOIN
 				accounts AS ac ON acb.account_id = ac.id
 			WHERE 
 				acb.business_id=? 
-				AND acb.branch_id = ?
+				AND acb.branch_id IN ?
 				AND acb.currency_id = ?
 				AND acb.transaction_date >= ?
 				AND acb.transaction_date <= ?
@@ -90,7 +90,7 @@ func GetMovementOfEquityReport(ctx context.Context, fromDate models.MyDateString
 				accounts AS ac ON acb.account_id = ac.id
 			WHERE 
 				acb.business_id = ? 
-				AND acb.branch_id = ?
+				AND acb.branch_id IN ?
 				AND acb.currency_id = ?
 				AND acb.transaction_date BETWEEN ? AND ?
 				AND acb.account_id IN (
@@ -110,7 +110,7 @@ func GetMovementOfEquityReport(ctx context.Context, fromDate models.MyDateString
 				accounts AS ac ON acb.account_id = ac.id
 			WHERE 
 				acb.business_id = ? 
-				AND acb.branch_id = ?
+				AND acb.branch_id IN ?
 				AND acb.currency_id = ?
 				AND acb.transaction_date BETWEEN ? AND ?
 				AND acb.account_id IN (
@@ -199,10 +199,10 @@ func GetMovementOfEquityReport(ctx context.Context, fromDate models.MyDateString
     `
 
 	rows, err := db.Raw(query,
-		businessID, *branchID, business.BaseCurrencyId, fromDate,
-		businessID, *branchID, business.BaseCurrencyId, fromDate, toDate,
-		businessID, *branchID, business.BaseCurrencyId, fromDate, toDate,
-		businessID, *branchID, business.BaseCurrencyId, fromDate, toDate,
+		businessID, balanceBranchIds, business.BaseCurrencyId, fromDate,
+		businessID, balanceBranchIds, business.BaseCurrencyId, fromDate, toDate,
+		businessID, balanceBranchIds, business.BaseCurrencyId, fromDate, toDate,
+		businessID, balanceBranchIds, business.BaseCurrencyId, fromDate, toDate,
 	).Rows()
 
 	if err != nil {
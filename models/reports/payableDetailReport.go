@@ -28,6 +28,10 @@ type PayableDetailResponse struct {
 }
 
 func GetPayableDetailReport(ctx context.Context, startDate models.MyDateString, endDate models.MyDateString, supplierID *int, branchID *int, warehouseID *int) ([]*PayableDetailResponse, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
 
 	sqlT := `
 WITH LatestBillOutbox AS (
@@ -83,7 +87,7 @@ BillDetail AS (
         AND b.bill_date BETWEEN @fromDate
         AND @toDate
         AND (b_outbox.processing_status IS NULL OR b_outbox.processing_status <> 'DEAD')
-		{{- if .BranchId }} AND b.branch_id = @branchId {{- end }}
+		{{- if .branchIds }} AND b.branch_id IN @branchIds {{- end }}
 		{{- if .WarehouseId }} AND b.warehouse_id = @warehouseId {{- end }}
 		{{- if .SupplierId }} AND b.supplier_id = @supplierId {{- end }}
 ),
@@ -111,7 +115,7 @@ CreditNoteDetail AS (
         AND @toDate
         AND NOT sc.current_status IN ('Draft', 'Void')
         AND (sc_outbox.processing_status IS NULL OR sc_outbox.processing_status <> 'DEAD')
-		{{- if .BranchId }} AND sc.branch_id = @branchId {{- end }}
+		{{- if .branchIds }} AND sc.branch_id IN @branchIds {{- end }}
 		{{- if .WarehouseId }} AND sc.warehouse_id = @warehouseId {{- end }}
 		{{- if .SupplierId }} AND sc.supplier_id = @supplierId {{- end }}
 ),
@@ -182,7 +186,7 @@ ORDER BY
 	}
 
 	sql, err := utils.ExecTemplate(sqlT, map[string]interface{}{
-		"BranchId":    utils.DereferencePtr(branchID, 0) > 0,
+		"branchIds":   len(branchIds) > 0,
 		"WarehouseId": utils.DereferencePtr(warehouseID, 0) > 0,
 		"SupplierId":  utils.DereferencePtr(supplierID, 0) > 0,
 	})
@@ -194,7 +198,7 @@ ORDER BY
 	if err := db.WithContext(ctx).Raw(sql, map[string]interface{}{
 		"businessId":     businessId,
 		"baseCurrencyId": business.BaseCurrencyId,
-		"branchId":       branchID,
+		"branchIds":      branchIds,
 		"warehouseId":    warehouseID,
 		"supplierId":     supplierID,
 		"fromDate":       startDate,
@@ -26,6 +26,10 @@ type PayableSummaryResponse struct {
 }
 
 func GetPayableSummaryReport(ctx context.Context, startDate models.MyDateString, endDate models.MyDateString, supplierID *int, branchID *int, warehouseID *int) ([]*PayableSummaryResponse, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
 	sqlT := `
 WITH LatestBillOutbox AS (
     SELECT
@@ -75,7 +79,7 @@ BillSummary as (
         AND NOT b.current_status IN ('Draft', 'Void')
         AND b.bill_date BETWEEN @fromDate AND @toDate
         AND (b_outbox.processing_status IS NULL OR b_outbox.processing_status <> 'DEAD')
-		{{- if .BranchId }} AND b.branch_id = @branchId {{- end }}
+		{{- if .branchIds }} AND b.branch_id IN @branchIds {{- end }}
 		{{- if .WarehouseId }} AND b.warehouse_id = @warehouseId {{- end }}
 		{{- if .SupplierId }} AND b.supplier_id = @supplierId {{- end }}
 ),
@@ -100,7 +104,7 @@ SupplierCreditSummary as (
         AND NOT sc.current_status IN ('Draft', 'Void')
         AND sc.supplier_credit_date BETWEEN @fromDate AND @toDate
         AND (sc_outbox.processing_status IS NULL OR sc_outbox.processing_status <> 'DEAD')
-		{{- if .BranchId }} AND sc.branch_id = @branchId {{- end }}
+		{{- if .branchIds }} AND sc.branch_id IN @branchIds {{- end }}
 		{{- if .WarehouseId }} AND sc.warehouse_id = @warehouseId {{- end }}
 		{{- if .SupplierId }} AND sc.supplier_id = @supplierId {{- end }}
 ),
@@ -169,7 +173,7 @@ order by pu.payable_date
 	}
 
 	sql, err := utils.ExecTemplate(sqlT, map[string]interface{}{
-		"BranchId":    utils.DereferencePtr(branchID, 0) > 0,
+		"branchIds":   len(branchIds) > 0,
 		"WarehouseId": utils.DereferencePtr(warehouseID, 0) > 0,
 		"SupplierId":  utils.DereferencePtr(supplierID, 0) > 0,
 	})
@@ -181,7 +185,7 @@ order by pu.payable_date
 	if err := db.WithContext(ctx).Raw(sql, map[string]interface{}{
 		"businessId":     businessId,
 		"baseCurrencyId": business.BaseCurrencyId,
-		"branchId":       branchID,
+		"branchIds":      branchIds,
 		"warehouseId":    warehouseID,
 		"supplierId":     supplierID,
 		"fromDate":       startDate,
@@ -23,6 +23,10 @@ type ProductSalesReportResponse struct {
 }
 
 func GetProductSalesReport(ctx context.Context, fromDate models.MyDateString, toDate models.MyDateString, branchId *int) ([]*ProductSalesReportResponse, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchId)
+	if err != nil {
+		return nil, err
+	}
 	sqlTemplate := `
 WITH InvoiceDetails as (
     SELECT
@@ -51,7 +55,7 @@ WITH InvoiceDetails as (
     WHERE
 		iv.business_id = @businessId
         AND iv.invoice_date BETWEEN @fromDate AND @toDate
-        {{- if .branchId }} AND iv.branch_id = @branchId {{- end }}
+        {{- if .branchIds }} AND iv.branch_id IN @branchIds {{- end }}
         AND iv.current_status IN ('Confirmed', 'Partial Paid', 'Paid')
         AND (iv_outbox.processing_status IS NULL OR iv_outbox.processing_status <> 'DEAD')
     group by
@@ -113,7 +117,7 @@ FROM
 
 	// execting sql template to get raw sql
 	sql, err := utils.ExecTemplate(sqlTemplate, map[string]interface{}{
-		"branchId": utils.DereferencePtr(branchId),
+		"branchIds": len(branchIds) > 0,
 	})
 	if err != nil {
 		return nil, err
@@ -124,7 +128,7 @@ FROM
 		"businessId": businessId,
 		"fromDate":   time.Time(fromDate),
 		"toDate":     time.Time(toDate),
-		"branchId":   branchId,
+		"branchIds":  branchIds,
 	}).Scan(&results).Error; err != nil {
 		return nil, err
 	}
@@ -11,6 +11,10 @@ import (
 )
 
 func GetProfitAndLossReport(ctx context.Context, fromDate models.MyDateString, toDate models.MyDateString, reportType string, branchID *int) (*models.ProfitAndLossResponse, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
 	businessId, ok := utils.GetBusinessIdFromContext(ctx)
 	if !ok || businessId == "" {
 		return nil, errors.New("business id is required")
@@ -28,11 +32,7 @@ func GetProfitAndLossReport(ctx context.Context, fromDate models.MyDateString, t
 
 	db := config.GetDB()
 
-	// Initialize branchID to 0 if it's nil
-	if branchID == nil {
-		branchID = new(int)
-		*branchID = 0
-	}
+	balanceBranchIds := models.BalanceBranchIds(branchIds)
 
 	rows, err := db.Raw(`
         WITH LastRows AS (
@@ -49,7 +49,7 @@ func GetProfitAndLossReport(ctx context.Context, fromDate models.MyDateString, t
                 accounts AS ac ON acb.account_id = ac.id
             WHERE 
                 acb.business_id= ? 
-                AND acb.branch_id= ?
+                AND acb.branch_id IN ?
                 AND acb.currency_id = ?
                 AND acb.transaction_date >= ? -- Start of date range
                 AND acb.transaction_date <= ? -- End of date range
@@ -91,7 +91,7 @@ func GetProfitAndLossReport(ctx context.Context, fromDate models.MyDateString, t
                 ELSE 5
             END;
 
-    `, businessId, branchID, business.BaseCurrencyId, fromDate, toDate).Rows()
+    `, businessId, balanceBranchIds, business.BaseCurrencyId, fromDate, toDate).Rows()
 
 	if err != nil {
 		return nil, err
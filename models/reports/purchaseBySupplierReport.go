@@ -18,6 +18,10 @@ type PurchasesBySupplierResponse struct {
 }
 
 func GetPurchasesBySupplierReport(ctx context.Context, fromDate models.MyDateString, toDate models.MyDateString, branchId *int) ([]*PurchasesBySupplierResponse, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchId)
+	if err != nil {
+		return nil, err
+	}
 	sqlT := `
 WITH BillDetails AS (
     select
@@ -59,7 +63,7 @@ WITH BillDetails AS (
         AND b.current_status IN ('Confirmed', 'Paid', 'Partial Paid')
 		AND b.bill_date BETWEEN @fromDate AND @toDate
         AND (b_outbox.processing_status IS NULL OR b_outbox.processing_status <> 'DEAD')
-		{{- if .branchId }} AND branch_id = @branchId {{- end }}
+		{{- if .branchIds }} AND branch_id IN @branchIds {{- end }}
     GROUP BY
         supplier_id
 )
@@ -86,7 +90,7 @@ ORDER BY suppliers.name;
 		return nil, err
 	}
 	sql, err := utils.ExecTemplate(sqlT, map[string]interface{}{
-		"branchId": utils.DereferencePtr(branchId, 0) > 0,
+		"branchIds": len(branchIds) > 0,
 	})
 	if err != nil {
 		return nil, err
@@ -97,7 +101,7 @@ ORDER BY suppliers.name;
 		"baseCurrencyId": business.BaseCurrencyId,
 		"fromDate":       fromDate,
 		"toDate":         toDate,
-		"branchId":       branchId,
+		"branchIds":      branchIds,
 	}).Scan(&results).Error; err != nil {
 		return nil, err
 	}
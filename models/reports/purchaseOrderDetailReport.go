@@ -27,6 +27,10 @@ type PurchaseOrderDetailResponse struct {
 }
 
 func GetPurchaseOrderDetailReport(ctx context.Context, fromDate models.MyDateString, toDate models.MyDateString, branchID *int, warehouseID *int) ([]*PurchaseOrderDetailResponse, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
 
 	sqlTemplate := `
 SELECT
@@ -59,7 +63,7 @@ LEFT JOIN suppliers ON suppliers.id = po.supplier_id
 	WHERE po.business_id = @businessId
 	AND po.order_date BETWEEN @fromDate AND @toDate
     AND po.current_status NOT IN ('Draft', 'Void')
-	{{- if .branchId }} AND po.branch_id = @branchId {{- end }}
+	{{- if .branchIds }} AND po.branch_id IN @branchIds {{- end }}
 	{{- if .warehouseId }} AND po.warehouse_id = @warehouseId {{- end }}
 `
 
@@ -81,7 +85,7 @@ LEFT JOIN suppliers ON suppliers.id = po.supplier_id
 	db := config.GetDB()
 	var results []*PurchaseOrderDetailResponse
 	sql, err := utils.ExecTemplate(sqlTemplate, map[string]interface{}{
-		"branchId":    utils.DereferencePtr(branchID, 0),
+		"branchIds":   len(branchIds) > 0,
 		"warehouseId": utils.DereferencePtr(warehouseID, 0),
 	})
 	if err != nil {
@@ -91,7 +95,7 @@ LEFT JOIN suppliers ON suppliers.id = po.supplier_id
 	if err := db.WithContext(ctx).Raw(sql, map[string]interface{}{
 		"businessId":     businessId,
 		"baseCurrencyId": business.BaseCurrencyId,
-		"branchId":       branchID,
+		"branchIds":      branchIds,
 		"warehouseId":    warehouseID,
 		"fromDate":       fromDate,
 		"toDate":         toDate,
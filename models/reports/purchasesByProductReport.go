@@ -20,6 +20,10 @@ type PurchasesByProductResponse struct {
 }
 
 func GetPurchasesByProductReport(ctx context.Context, fromDate models.MyDateString, toDate models.MyDateString, branchId *int, warehouseId *int) ([]*PurchasesByProductResponse, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchId)
+	if err != nil {
+		return nil, err
+	}
 	sqlT := `
 WITH BillDetails AS (
     SELECT
@@ -72,7 +76,7 @@ WITH BillDetails AS (
 		AND b.bill_date BETWEEN @fromDate AND @toDate
         AND b.current_status IN ('Confirmed', 'Paid', 'Partial Paid')
         AND (b_outbox.processing_status IS NULL OR b_outbox.processing_status <> 'DEAD')
-		{{- if .branchId }} AND branch_id = @branchId {{- end }}
+		{{- if .branchIds }} AND branch_id IN @branchIds {{- end }}
 		{{- if .warehouseId }} AND warehouse_id = @warehouseId {{- end }}
     GROUP BY
         bd.product_id,
@@ -126,7 +130,7 @@ ORDER BY p.product_name
 	}
 
 	sql, err := utils.ExecTemplate(sqlT, map[string]interface{}{
-		"branchId":    utils.DereferencePtr(branchId, 0) > 0,
+		"branchIds":   len(branchIds) > 0,
 		"warehouseId": utils.DereferencePtr(warehouseId, 0) > 0,
 	})
 	if err != nil {
@@ -138,7 +142,7 @@ ORDER BY p.product_name
 		"baseCurrencyId": business.BaseCurrencyId,
 		"fromDate":       fromDate,
 		"toDate":         toDate,
-		"branchId":       branchId,
+		"branchIds":      branchIds,
 		"warehouseId":    warehouseId,
 	}).Scan(&results).Error; err != nil {
 		return nil, err
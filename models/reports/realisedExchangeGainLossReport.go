@@ -22,6 +22,10 @@ type RealisedExchangeGainLossResponse struct {
 }
 
 func GetRealisedExchangeGainLossReport(ctx context.Context, branchId *int, fromDate models.MyDateString, toDate models.MyDateString) ([]*RealisedExchangeGainLossResponse, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchId)
+	if err != nil {
+		return nil, err
+	}
 	sqlTemplate := `
 		SELECT at.transaction_date_time AS date, aj.reference_type AS transaction_type, 
 		c.id AS currency_id, c.name AS currency_name, at.exchange_rate AS exchange_rate, 
@@ -32,7 +36,7 @@ func GetRealisedExchangeGainLossReport(ctx context.Context, branchId *int, fromD
 		INNER JOIN currencies c ON at.foreign_currency_id = c.id
 		WHERE 
 		at.business_id = @businessId
-		{{- if .branchId }} AND at.branch_id = @branchId {{- end }}
+		{{- if .branchIds }} AND at.branch_id IN @branchIds {{- end }}
         AND at.transaction_date_time BETWEEN @fromDate AND @toDate
 		AND aj.is_reversal = 0
 		AND aj.reversed_by_journal_id IS NULL
@@ -65,7 +69,7 @@ func GetRealisedExchangeGainLossReport(ctx context.Context, branchId *int, fromD
 
 	// generating sql from template
 	sql, err := utils.ExecTemplate(sqlTemplate, map[string]interface{}{
-		"branchId": utils.DereferencePtr(branchId),
+		"branchIds": len(branchIds) > 0,
 	})
 	if err != nil {
 		return nil, err
@@ -77,7 +81,7 @@ func GetRealisedExchangeGainLossReport(ctx context.Context, branchId *int, fromD
 		"businessId": businessId,
 		"fromDate":   fromDate,
 		"toDate":     toDate,
-		"branchId":   branchId,
+		"branchIds":  branchIds,
 		"accountId":  systemAccounts[models.AccountCodeExchangeGainOrLoss],
 	}).Scan(&records).Error; err != nil {
 		return nil, err
@@ -27,6 +27,10 @@ type ReceivableDetailResponse struct {
 }
 
 func GetReceivableDetailReport(ctx context.Context, startDate models.MyDateString, endDate models.MyDateString, customerID *int, branchID *int, warehouseID *int) ([]*ReceivableDetailResponse, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
 	sqlT := `
 
 WITH LatestInvoiceOutbox AS (
@@ -81,7 +85,7 @@ InvoiceDetail AS (
         AND @toDate
         AND NOT iv.current_status IN ('Draft', 'Void')
         AND (outbox.processing_status IS NULL OR outbox.processing_status <> 'DEAD')
-		{{- if .BranchId }} AND iv.branch_id = @branchId {{- end }}
+		{{- if .branchIds }} AND iv.branch_id IN @branchIds {{- end }}
 		{{- if .WarehouseId }} AND iv.warehouse_id = @warehouseId {{- end }}
 		{{- if .customerId }} AND iv.customer_id = @customerId {{- end }}
 ),
@@ -109,7 +113,7 @@ CreditNoteDetail AS (
         AND @toDate
         AND NOT cn.current_status IN ('Draft', 'Void')
         AND (cn_outbox.processing_status IS NULL OR cn_outbox.processing_status <> 'DEAD')
-		{{- if .BranchId }} AND cn.branch_id = @branchId {{- end }}
+		{{- if .branchIds }} AND cn.branch_id IN @branchIds {{- end }}
 		{{- if .WarehouseId }} AND cn.warehouse_id = @warehouseId {{- end }}
 		{{- if .customerId }} AND cn.customer_id = @customerId {{- end }}
 ),
@@ -166,7 +170,7 @@ ORDER BY
 	`
 
 	sql, err := utils.ExecTemplate(sqlT, map[string]interface{}{
-		"BranchId":    utils.DereferencePtr(branchID, 0) > 0,
+		"branchIds":   len(branchIds) > 0,
 		"WarehouseId": utils.DereferencePtr(warehouseID, 0) > 0,
 		"CustomerId":  utils.DereferencePtr(customerID, 0) > 0,
 	})
@@ -183,7 +187,7 @@ ORDER BY
 	if err := db.WithContext(ctx).Raw(sql, map[string]interface{}{
 		"businessId":     business.ID,
 		"baseCurrencyId": business.BaseCurrencyId,
-		"branchId":       branchID,
+		"branchIds":      branchIds,
 		"warehouseId":    warehouseID,
 		"customerId":     customerID,
 		"fromDate":       startDate,
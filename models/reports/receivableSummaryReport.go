@@ -26,6 +26,10 @@ type ReceivableSummaryResponse struct {
 }
 
 func GetReceivableSummaryReport(ctx context.Context, startDate models.MyDateString, endDate models.MyDateString, customerID *int, branchID *int, warehouseID *int) ([]*ReceivableSummaryResponse, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
 	sqlT := `
 WITH LatestInvoiceOutbox AS (
     SELECT
@@ -75,7 +79,7 @@ InvoiceSummary as (
         AND iv.invoice_date BETWEEN @fromDate AND @toDate
         AND NOT iv.current_status IN ('Draft', 'Void')
         AND (outbox.processing_status IS NULL OR outbox.processing_status <> 'DEAD')
-		{{- if .BranchId }} AND iv.branch_id = @branchId {{- end }}
+		{{- if .branchIds }} AND iv.branch_id IN @branchIds {{- end }}
 		{{- if .WarehouseId }} AND iv.warehouse_id = @warehouseId {{- end }}
 		{{- if .customerId }} AND iv.customer_id = @customerId {{- end }}
 ),
@@ -100,7 +104,7 @@ CreditNoteSummary as (
         AND cn.credit_note_date BETWEEN @fromDate AND @toDate
 		AND NOT cn.current_status IN ('Draft', 'Void')
         AND (cn_outbox.processing_status IS NULL OR cn_outbox.processing_status <> 'DEAD')
-		{{- if .BranchId }} AND cn.branch_id = @branchId {{- end }}
+		{{- if .branchIds }} AND cn.branch_id IN @branchIds {{- end }}
 		{{- if .WarehouseId }} AND cn.warehouse_id = @warehouseId {{- end }}
 		{{- if .customerId }} AND cn.customer_id = @customerId {{- end }}
 ),
@@ -169,7 +173,7 @@ order by ru.receivable_date
 	}
 
 	sql, err := utils.ExecTemplate(sqlT, map[string]interface{}{
-		"BranchId":    utils.DereferencePtr(branchID, 0) > 0,
+		"branchIds":   len(branchIds) > 0,
 		"WarehouseId": utils.DereferencePtr(warehouseID, 0) > 0,
 		"CustomerId":  utils.DereferencePtr(customerID, 0) > 0,
 	})
@@ -181,7 +185,7 @@ order by ru.receivable_date
 	if err := db.WithContext(ctx).Raw(sql, map[string]interface{}{
 		"businessId":     businessId,
 		"baseCurrencyId": business.BaseCurrencyId,
-		"branchId":       branchID,
+		"branchIds":      branchIds,
 		"warehouseId":    warehouseID,
 		"customerId":     customerID,
 		"fromDate":       startDate,
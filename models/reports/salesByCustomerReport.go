@@ -20,6 +20,10 @@ type SalesByCustomerResponse struct {
 }
 
 func GetSalesByCustomerReport(ctx context.Context, branchId *int, fromDate models.MyDateString, toDate models.MyDateString) ([]*SalesByCustomerResponse, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchId)
+	if err != nil {
+		return nil, err
+	}
 
 	sqlT := `
 SELECT 
@@ -65,7 +69,7 @@ FROM
             AND sales_invoices.invoice_date BETWEEN @fromDate AND @toDate
             AND sales_invoices.current_status IN ('Paid' , 'Partial Paid', 'Confirmed')
             AND (invoice_outbox.processing_status IS NULL OR invoice_outbox.processing_status <> 'DEAD')
-		{{- if .branchId }} AND sales_invoices.branch_id = @branchId {{- end }}
+		{{- if .branchIds }} AND sales_invoices.branch_id IN @branchIds {{- end }}
     GROUP BY customer_id) AS siv
         LEFT JOIN
     customers ON customers.id = siv.customer_id;	
@@ -94,7 +98,7 @@ FROM
 
 	// generating sql from template
 	sql, err := utils.ExecTemplate(sqlT, map[string]interface{}{
-		"branchId": utils.DereferencePtr(branchId),
+		"branchIds": len(branchIds) > 0,
 	})
 	if err != nil {
 		return nil, err
@@ -106,7 +110,7 @@ FROM
 		"businessId":     businessId,
 		"fromDate":       fromDate,
 		"toDate":         toDate,
-		"branchId":       branchId,
+		"branchIds":      branchIds,
 		"baseCurrencyId": business.BaseCurrencyId,
 	}).Scan(&records).Error; err != nil {
 		return nil, err
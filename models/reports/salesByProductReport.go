@@ -20,6 +20,10 @@ type SalesByProductResponse struct {
 }
 
 func GetSalesByProductReport(ctx context.Context, fromDate models.MyDateString, toDate models.MyDateString, branchId *int, warehouseId *int, sku *string, productName *string) ([]*SalesByProductResponse, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchId)
+	if err != nil {
+		return nil, err
+	}
 	sqlT := `
 with InvoiceDetails as (
 SELECT 
@@ -63,7 +67,7 @@ WHERE
         AND iv.invoice_date BETWEEN @fromDate AND @toDate
         AND iv.current_status IN ('Confirmed' , 'Partial Paid', 'Paid')
         AND (iv_outbox.processing_status IS NULL OR iv_outbox.processing_status <> 'DEAD')
-        {{- if .branchId }} AND iv.branch_id = @branchId {{- end }}
+        {{- if .branchIds }} AND iv.branch_id IN @branchIds {{- end }}
         {{- if .warehouseId }} AND iv.warehouse_id = @warehouseId {{- end }}
 GROUP BY iv_dt.product_id , iv_dt.product_type
 ),
@@ -129,7 +133,7 @@ FROM
 
 	// execting sql template to get raw sql
 	sql, err := utils.ExecTemplate(sqlT, map[string]interface{}{
-		"branchId":    utils.DereferencePtr(branchId),
+		"branchIds":   len(branchIds) > 0,
 		"sku":         utils.DereferencePtr(sku),
 		"productName": utils.DereferencePtr(productName),
 		"warehouseId": utils.DereferencePtr(warehouseId),
@@ -144,7 +148,7 @@ FROM
 		"fromDate":       fromDate,
 		"toDate":         toDate,
 		"baseCurrencyId": business.BaseCurrencyId,
-		"branchId":       branchId,
+		"branchIds":      branchIds,
 		"sku":            sku,
 		"productName":    "%" + utils.DereferencePtr(productName) + "%",
 		"warehouseId":    warehouseId,
@@ -29,6 +29,10 @@ type SalesBySalesPersonResponse struct {
 
 // do more checking for reports not filtering businessId
 func GetSalesBySalesPersonReport(ctx context.Context, branchId *int, fromDate models.MyDateString, toDate models.MyDateString) ([]*SalesBySalesPersonResponse, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchId)
+	if err != nil {
+		return nil, err
+	}
 	var records []*SalesBySalesPersonResponse
 	sqlTemplate := `
 WITH LatestInvoiceOutbox AS (
@@ -71,7 +75,7 @@ SalesInvoices AS (
         AND sales_invoices.invoice_date BETWEEN @fromDate AND @toDate
         AND sales_invoices.current_status IN ('Paid', 'Partial Paid', 'Confirmed')
         AND (iv_outbox.processing_status IS NULL OR iv_outbox.processing_status <> 'DEAD')
-       {{- if .branchId }} AND sales_invoices.branch_id = @branchId {{- end }}
+       {{- if .branchIds }} AND sales_invoices.branch_id IN @branchIds {{- end }}
     GROUP BY
         sales_person_id
 ),
@@ -91,7 +95,7 @@ CreditNotes as (
         AND credit_notes.credit_note_date BETWEEN @fromDate AND @toDate
         AND credit_notes.current_status = 'Closed'
         AND (cn_outbox.processing_status IS NULL OR cn_outbox.processing_status <> 'DEAD')
-       {{- if .branchId }} AND credit_notes.branch_id = @branchId {{- end }}
+       {{- if .branchIds }} AND credit_notes.branch_id IN @branchIds {{- end }}
     GROUP BY
         sales_person_id
 )
@@ -135,7 +139,7 @@ FROM
 
 	// generating sql from template
 	sql, err := utils.ExecTemplate(sqlTemplate, map[string]interface{}{
-		"branchId": utils.DereferencePtr(branchId),
+		"branchIds": len(branchIds) > 0,
 	})
 	if err != nil {
 		return nil, err
@@ -146,7 +150,7 @@ FROM
 		"businessId":     businessId,
 		"fromDate":       fromDate,
 		"toDate":         toDate,
-		"branchId":       branchId,
+		"branchIds":      branchIds,
 		"baseCurrencyId": business.BaseCurrencyId,
 	}).Scan(&records).Error; err != nil {
 		return nil, err
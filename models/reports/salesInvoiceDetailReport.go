@@ -30,6 +30,10 @@ type SalesInvoiceDetailResponse struct {
 }
 
 func GetSalesInvoiceDetailReport(ctx context.Context, fromDate models.MyDateString, toDate models.MyDateString, branchID *int, warehouseID *int) ([]*SalesInvoiceDetailResponse, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
 
 	sqlTemplate := `
 SELECT
@@ -97,7 +101,7 @@ WHERE
     AND invoice.invoice_date BETWEEN @fromDate AND @toDate
     AND (invoice_outbox.processing_status IS NULL OR invoice_outbox.processing_status <> 'DEAD')
 	{{- if .warehouseId }} AND invoice.warehouse_id = @warehouseId {{- end }}
-	{{- if .branchId }} AND invoice.branch_id = @branchId {{- end }}
+	{{- if .branchIds }} AND invoice.branch_id IN @branchIds {{- end }}
 `
 	var results []*SalesInvoiceDetailResponse
 
@@ -117,7 +121,7 @@ WHERE
 	}
 
 	sql, err := utils.ExecTemplate(sqlTemplate, map[string]interface{}{
-		"branchId":    utils.DereferencePtr(branchID, 0),
+		"branchIds":   len(branchIds) > 0,
 		"warehouseId": utils.DereferencePtr(warehouseID, 0),
 	})
 	if err != nil {
@@ -127,7 +131,7 @@ WHERE
 	if err := db.WithContext(ctx).Raw(sql, map[string]interface{}{
 		"businessId":     businessId,
 		"baseCurrencyId": business.BaseCurrencyId,
-		"branchId":       branchID,
+		"branchIds":      branchIds,
 		"warehouseId":    warehouseID,
 		"fromDate":       fromDate,
 		"toDate":         toDate,
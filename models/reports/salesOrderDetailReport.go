@@ -27,6 +27,10 @@ type SalesOrderDetailResponse struct {
 }
 
 func GetSalesOrderDetailReport(ctx context.Context, fromDate models.MyDateString, toDate models.MyDateString, branchID *int, warehouseID *int) ([]*SalesOrderDetailResponse, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
 
 	sqlTemplate := `
 SELECT
@@ -60,7 +64,7 @@ WHERE
     so.business_id = @businessId
 	AND so.order_date BETWEEN @fromDate AND @toDate
     AND so.current_status NOT IN ('Draft', 'Void')
-	{{- if .branchId }} AND so.branch_id = @branchId {{- end }}
+	{{- if .branchIds }} AND so.branch_id IN @branchIds {{- end }}
 	{{- if .warehouseId }} AND so.warehouse_id = @warehouseId {{- end }}
 `
 
@@ -82,7 +86,7 @@ WHERE
 	db := config.GetDB()
 	var results []*SalesOrderDetailResponse
 	sql, err := utils.ExecTemplate(sqlTemplate, map[string]interface{}{
-		"branchId":    utils.DereferencePtr(branchID, 0),
+		"branchIds":   len(branchIds) > 0,
 		"warehouseId": utils.DereferencePtr(warehouseID, 0),
 	})
 	if err != nil {
@@ -92,7 +96,7 @@ WHERE
 	if err := db.WithContext(ctx).Raw(sql, map[string]interface{}{
 		"businessId":     businessId,
 		"baseCurrencyId": business.BaseCurrencyId,
-		"branchId":       branchID,
+		"branchIds":      branchIds,
 		"warehouseId":    warehouseID,
 		"fromDate":       fromDate,
 		"toDate":         toDate,
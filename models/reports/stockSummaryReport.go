@@ -33,7 +33,7 @@ WITH Ledger AS (
     WHERE sh.business_id = @businessId
       AND sh.is_reversal = 0
       AND sh.reversed_by_stock_history_id IS NULL
-      {{- if .warehouseId }} AND sh.warehouse_id = @warehouseId {{- end }}
+      {{- if .narrowed }} AND sh.warehouse_id IN @warehouseIds {{- end }}
     GROUP BY sh.product_id, sh.product_type
 ),
 AllProducts AS (
@@ -71,14 +71,14 @@ ORDER BY ap.product_name;
 		return nil, err
 	}
 
-	if warehouseId != nil && *warehouseId > 0 {
-		if err := utils.ValidateResourceId[models.Warehouse](ctx, businessId, *warehouseId); err != nil {
-			return nil, errors.New("warehouse not found")
-		}
+	// raw sql is not branch-scoped, restricted users only see their branches' warehouses
+	warehouseIds, err := models.NarrowReportWarehouse(ctx, warehouseId)
+	if err != nil {
+		return nil, err
 	}
 
 	sql, err := utils.ExecTemplate(sqlT, map[string]interface{}{
-		"warehouseId": utils.DereferencePtr(warehouseId),
+		"narrowed": warehouseIds != nil,
 	})
 	if err != nil {
 		return nil, err
@@ -87,19 +87,19 @@ ORDER BY ap.product_name;
 	var results []*StockSummaryReportResponse
 	db := config.GetDB()
 	// IMPORTANT:
-	// The SQL template conditionally removes the warehouse filter when warehouseId is nil/0.
+	// The SQL template conditionally removes the warehouse filter when every warehouse is reported.
 	// GORM expands named params to positional placeholders per-occurrence. If we pass a named param
-	// that no longer exists in the final SQL (e.g. warehouseId), the driver can error with:
+	// that no longer exists in the final SQL (e.g. warehouseIds), the driver can error with:
 	// "sql: expected N arguments, got N+1".
 	//
-	// Therefore, only include warehouseId when the placeholder is present.
+	// Therefore, only include warehouseIds when the placeholder is present.
 	args := map[string]interface{}{
 		"fromDate":   fromDate,
 		"toDate":     toDate,
 		"businessId": businessId,
 	}
-	if warehouseId != nil && *warehouseId != 0 {
-		args["warehouseId"] = warehouseId
+	if warehouseIds != nil {
+		args["warehouseIds"] = warehouseIds
 	}
 	if err := db.WithContext(ctx).Raw(sql, args).Scan(&results).Error; err != nil {
 		return nil, err
@@ -27,6 +27,10 @@ type SupplierBalance struct {
 }
 
 func GetSupplierBalanceReport(ctx context.Context, toDate *models.MyDateString, branchId *int) ([]*SupplierBalance, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchId)
+	if err != nil {
+		return nil, err
+	}
 	sql := `
 WITH AvailableAdvance AS (
     select
@@ -39,7 +43,7 @@ WITH AvailableAdvance AS (
         supplier_credit_advances sca
     where
         business_id = @businessId
-        {{- if .branchId }} AND branch_id = @branchId {{- end }}
+        {{- if .branchIds }} AND branch_id IN @branchIds {{- end }}
         AND NOT sca.current_status IN ('Draft', 'Void')  AND sca.date <= @toDate
     group by
         sca.supplier_id,
@@ -82,7 +86,7 @@ AvailableCredit AS (
         LEFT JOIN pub_sub_message_records sc_outbox ON sc_outbox.id = lsc.max_id
     where
         sc.business_id = @businessId
-        {{- if .branchId }} AND sc.branch_id = @branchId {{- end }}
+        {{- if .branchIds }} AND sc.branch_id IN @branchIds {{- end }}
         AND NOT sc.current_status IN ('Draft', 'Void')  AND sc.supplier_credit_date <= @toDate
         AND (sc_outbox.processing_status IS NULL OR sc_outbox.processing_status <> 'DEAD')
     group by
@@ -107,7 +111,7 @@ Bill AS (
         LEFT JOIN pub_sub_message_records b_outbox ON b_outbox.id = lbo.max_id
     where
         b.business_id = @businessId
-        {{- if .branchId }} AND b.branch_id = @branchId {{- end }}
+        {{- if .branchIds }} AND b.branch_id IN @branchIds {{- end }}
         AND NOT b.current_status IN ('Draft', 'Void')  AND b.bill_date <= @toDate
         AND (b_outbox.processing_status IS NULL OR b_outbox.processing_status <> 'DEAD')
     group by
@@ -221,7 +225,7 @@ WHERE Bill.supplier_id IS NULL AND AvailableCredit.supplier_id IS NULL
 
 	db := config.GetDB()
 	sql, err = utils.ExecTemplate(sql, map[string]interface{}{
-		"branchId": utils.DereferencePtr(branchId, 0),
+		"branchIds": len(branchIds) > 0,
 	})
 	if err != nil {
 		return nil, err
@@ -230,7 +234,7 @@ WHERE Bill.supplier_id IS NULL AND AvailableCredit.supplier_id IS NULL
 	if err := db.WithContext(ctx).Raw(sql, map[string]interface{}{
 		"baseCurrencyId": business.BaseCurrencyId,
 		"businessId":     business.ID,
-		"branchId":       branchId,
+		"branchIds":      branchIds,
 		"toDate":         date,
 	}).Scan(&results).Error; err != nil {
 		return nil, err
@@ -244,6 +248,10 @@ WHERE Bill.supplier_id IS NULL AND AvailableCredit.supplier_id IS NULL
 }
 
 func GetSupplierBalanceSummaryReport(ctx context.Context, toDate *models.MyDateString, branchId *int) ([]*SupplierBalance, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchId)
+	if err != nil {
+		return nil, err
+	}
 
 	sqlT := `
 WITH AccTransactionSummary AS
@@ -309,7 +317,7 @@ WHERE
     AND aj.reversed_by_journal_id IS NULL
     AND at.account_id = @payableAccId
     {{- if .toDate }} AND aj.transaction_date_time <= @transactionDate {{- end }}
-    {{- if .branchId }} AND aj.branch_id = @branchId {{- end }}
+    {{- if .branchIds }} AND aj.branch_id IN @branchIds {{- end }}
 GROUP by
     aj.supplier_id,
     at.foreign_currency_id
@@ -347,8 +355,8 @@ ORDER BY suppliers.name, ATS.currency_id
 	var results []*SupplierBalance
 	db := config.GetDB()
 	sql, err := utils.ExecTemplate(sqlT, map[string]interface{}{
-		"toDate":   toDate != nil,
-		"branchId": utils.DereferencePtr(branchId, 0) > 0,
+		"toDate":    toDate != nil,
+		"branchIds": len(branchIds) > 0,
 	})
 	if err != nil {
 		return nil, err
@@ -362,7 +370,7 @@ ORDER BY suppliers.name, ATS.currency_id
 		"businessId":      business.ID,
 		"baseCurrencyId":  business.BaseCurrencyId,
 		"transactionDate": transactionDate,
-		"branchId":        branchId,
+		"branchIds":       branchIds,
 		"payableAccId":    accs[models.AccountCodeAccountsPayable],
 	}).Scan(&results).Error; err != nil {
 		return nil, err
@@ -24,6 +24,10 @@ type SupplierCreditDetailResponse struct {
 }
 
 func GetSupplierCreditDetailsReport(ctx context.Context, fromDate models.MyDateString, toDate models.MyDateString, branchID *int, warehouseID *int) ([]*SupplierCreditDetailResponse, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
 
 	sqlT := `
 SELECT
@@ -77,7 +81,7 @@ LEFT JOIN suppliers ON suppliers.id = sc.supplier_id
 	AND sc.supplier_credit_date BETWEEN @fromDate AND @toDate
     AND sc.current_status NOT IN ('Draft', 'Void')
     AND (sc_outbox.processing_status IS NULL OR sc_outbox.processing_status <> 'DEAD')
-	{{- if .branchId }} AND sc.branch_id = @branchId {{- end }}
+	{{- if .branchIds }} AND sc.branch_id IN @branchIds {{- end }}
 	{{- if .warehouseId }} AND sc.warehouse_id = @warehouseId {{- end }}
 ORDER BY sc.supplier_credit_date;
 	`
@@ -96,7 +100,7 @@ ORDER BY sc.supplier_credit_date;
 	}
 
 	sql, err := utils.ExecTemplate(sqlT, map[string]interface{}{
-		"branchId":    utils.DereferencePtr(branchID, 0) > 0,
+		"branchIds":   len(branchIds) > 0,
 		"warehouseId": utils.DereferencePtr(warehouseID, 0) > 0,
 	})
 	if err != nil {
@@ -108,7 +112,7 @@ ORDER BY sc.supplier_credit_date;
 		"baseCurrencyId": business.BaseCurrencyId,
 		"fromDate":       fromDate,
 		"toDate":         toDate,
-		"branchId":       branchID,
+		"branchIds":      branchIds,
 		"warehouseId":    warehouseID,
 	}).Scan(&results).Error; err != nil {
 		return nil, err
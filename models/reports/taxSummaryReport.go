@@ -0,0 +1,79 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+)
+
+type TaxSummaryReportResponse struct {
+	ReportBasis    models.ReportBasis       `json:"reportBasis"`
+	Lines          []*models.TaxSummaryLine `json:"lines"`
+	TotalOutputTax decimal.Decimal          `json:"totalOutputTax"`
+	TotalInputTax  decimal.Decimal          `json:"totalInputTax"`
+	NetPayable     decimal.Decimal          `json:"netPayable"`
+}
+
+// taxReportScope resolves the business, period and basis a tax report runs for;
+// reportBasis defaults to the business's.
+func taxReportScope(ctx context.Context, fromDate *models.MyDateString, toDate *models.MyDateString, branchId *int, reportBasis *models.ReportBasis) (*models.Business, []int, models.ReportBasis, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchId)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	business, err := models.GetBusiness(ctx)
+	if err != nil {
+		return nil, nil, "", errors.New("business id is required")
+	}
+	if err := fromDate.StartOfDayUTCTime(business.Timezone); err != nil {
+		return nil, nil, "", err
+	}
+	if err := toDate.EndOfDayUTCTime(business.Timezone); err != nil {
+		return nil, nil, "", err
+	}
+	if branchId != nil && *branchId != 0 {
+		if err := utils.ValidateResourceId[models.Branch](ctx, business.ID.String(), branchId); err != nil {
+			return nil, nil, "", errors.New("branch not found")
+		}
+	}
+	basis := business.ReportBasis
+	if reportBasis != nil {
+		basis = *reportBasis
+	}
+	return business, branchIds, basis, nil
+}
+
+// GetTaxSummaryReport shows output and input tax per tax and tax group, and what is payable.
+func GetTaxSummaryReport(ctx context.Context, fromDate models.MyDateString, toDate models.MyDateString, branchId *int, reportBasis *models.ReportBasis) (*TaxSummaryReportResponse, error) {
+	business, branchIds, basis, err := taxReportScope(ctx, &fromDate, &toDate, branchId, reportBasis)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := models.GetTaxSummary(ctx, business, time.Time(fromDate), time.Time(toDate), basis, branchIds)
+	if err != nil {
+		return nil, err
+	}
+	response := TaxSummaryReportResponse{
+		ReportBasis: basis,
+		Lines:       lines,
+	}
+	for _, line := range lines {
+		response.TotalOutputTax = response.TotalOutputTax.Add(line.OutputTax)
+		response.TotalInputTax = response.TotalInputTax.Add(line.InputTax)
+	}
+	response.NetPayable = response.TotalOutputTax.Sub(response.TotalInputTax)
+	return &response, nil
+}
+
+// GetTaxSummaryDetailReport lists the documents behind one line of the tax summary.
+func GetTaxSummaryDetailReport(ctx context.Context, fromDate models.MyDateString, toDate models.MyDateString, taxId int, taxType models.TaxType, branchId *int, reportBasis *models.ReportBasis) ([]*models.TaxTransaction, error) {
+	business, branchIds, basis, err := taxReportScope(ctx, &fromDate, &toDate, branchId, reportBasis)
+	if err != nil {
+		return nil, err
+	}
+	return models.GetTaxTransactions(ctx, business, time.Time(fromDate), time.Time(toDate), basis, branchIds, taxId, taxType)
+}
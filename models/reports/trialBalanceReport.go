@@ -11,6 +11,10 @@ import (
 )
 
 func GetTrialBalanceReport(ctx context.Context, toDate models.MyDateString, reportType string, branchID *int) ([]*models.TrialBalance, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
 
 	businessId, ok := utils.GetBusinessIdFromContext(ctx)
 	if !ok || businessId == "" {
@@ -37,38 +41,26 @@ func GetTrialBalanceReport(ctx context.Context, toDate models.MyDateString, repo
 
 	dbCtx := db.WithContext(ctx).Where("business_id = ?", businessId)
 
-	if branchID == nil {
-		branchID = new(int)
-		*branchID = 0
-	}
+	balanceBranchIds := models.BalanceBranchIds(branchIds)
 
 	var balances []*models.TrialBalance
 
 	rows, err := dbCtx.Raw(`
-					WITH LastRows AS (
+					WITH BranchLastRows AS (
 						SELECT 
-							-- acb.id as id,
 							ac.main_type AS main_type,
 							ac.name AS account_name,
 							ac.code AS account_code,
 							acb.account_id AS account_id,
 							acb.running_balance,
-							CASE
-								WHEN acb.running_balance >= 0 THEN acb.running_balance
-								ELSE 0
-							END AS debit,
-							CASE
-								WHEN acb.running_balance < 0 THEN ABS(acb.running_balance)
-								ELSE 0
-							END AS credit,
-							ROW_NUMBER() OVER (PARTITION BY acb.account_id ORDER BY acb.transaction_date DESC) AS row_num
+							ROW_NUMBER() OVER (PARTITION BY acb.account_id, acb.branch_id ORDER BY acb.transaction_date DESC) AS row_num
 						FROM 
 							account_currency_daily_balances AS acb
 						JOIN
 							accounts AS ac ON acb.account_id = ac.id
 						WHERE 
 							acb.business_id = ? 
-							AND acb.branch_id = ?
+							AND acb.branch_id IN ?
 							AND acb.currency_id = ?
 							AND acb.transaction_date <= ?
 							AND acb.transaction_date >= ?
@@ -76,6 +68,28 @@ func GetTrialBalanceReport(ctx context.Context, toDate models.MyDateString, repo
 								SELECT id FROM accounts WHERE main_type IN ('ASSET','LIABILITY','EQUITY')
 							)
 					),
+					LastRows AS (
+						SELECT 
+							main_type,
+							account_name,
+							account_code,
+							account_id,
+							CASE
+								WHEN SUM(running_balance) >= 0 THEN SUM(running_balance)
+								ELSE 0
+							END AS debit,
+							CASE
+								WHEN SUM(running_balance) < 0 THEN ABS(SUM(running_balance))
+								ELSE 0
+							END AS credit,
+							1 AS row_num
+						FROM 
+							BranchLastRows
+						WHERE 
+							row_num = 1
+						GROUP BY 
+							main_type, account_name, account_code, account_id
+					),
 					IncomeExpense AS (
 						SELECT 
 							acb.account_id AS account_id,
@@ -93,7 +107,7 @@ func GetTrialBalanceReport(ctx context.Context, toDate models.MyDateString, repo
 							accounts AS ac ON acb.account_id = ac.id
 						WHERE 
 							acb.business_id = ?
-							AND acb.branch_id = ?
+							AND acb.branch_id IN ?
 							AND acb.currency_id = ?
 							AND acb.transaction_date <= ?
 							AND acb.transaction_date >= ?
@@ -119,7 +133,7 @@ func GetTrialBalanceReport(ctx context.Context, toDate models.MyDateString, repo
 							FROM account_currency_daily_balances 
 							WHERE 
 								business_id= ?
-								AND branch_id= ?
+								AND branch_id IN ?
 								AND currency_id= ?
 								AND transaction_date <= ?
 								AND account_id IN (
@@ -178,9 +192,9 @@ func GetTrialBalanceReport(ctx context.Context, toDate models.MyDateString, repo
     					account_name ASC;
 
 			`,
-		businessId, branchID, business.BaseCurrencyId, toDate, fromDate,
-		businessId, branchID, business.BaseCurrencyId, toDate, fromDate,
-		businessId, branchID, business.BaseCurrencyId, fromDate).Rows()
+		businessId, balanceBranchIds, business.BaseCurrencyId, toDate, fromDate,
+		businessId, balanceBranchIds, business.BaseCurrencyId, toDate, fromDate,
+		businessId, balanceBranchIds, business.BaseCurrencyId, fromDate).Rows()
 
 	if err != nil {
 		return nil, err
@@ -28,27 +28,32 @@ type UnrealisedExchangeGainLossResponse struct {
 }
 
 func GetUnrealisedExchangeGainLossReport(ctx context.Context, branchId *int, toDate models.MyDateString, rates []*UserDefinedExchangeRate) ([]*UnrealisedExchangeGainLossResponse, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchId)
+	if err != nil {
+		return nil, err
+	}
 	sql := `
 		WITH LatestTransactions AS (
 			SELECT *,
-				ROW_NUMBER() OVER (PARTITION BY account_id ORDER BY transaction_date DESC) AS rn
+				ROW_NUMBER() OVER (PARTITION BY account_id, branch_id ORDER BY transaction_date DESC) AS rn
 			FROM account_currency_daily_balances
 			WHERE 
 			business_id = @businessId
-			AND branch_id = @branchId
+			AND branch_id IN @branchIds
 			AND transaction_date <= @toDate
 			AND currency_id != @baseCurrencyId
 		)
 		SELECT
 			a.id AS account_id, a.name AS account_name, 
 			c.id AS currency_id, c.name AS currency_name, 
-			lt.running_balance AS foreign_closing_balance, 
-			lt.running_base_balance AS base_closing_balance
+			SUM(lt.running_balance) AS foreign_closing_balance, 
+			SUM(lt.running_base_balance) AS base_closing_balance
 		FROM LatestTransactions lt
 		INNER JOIN accounts a ON lt.account_id = a.id
 		INNER JOIN currencies c ON lt.currency_id = c.id
 		WHERE lt.rn = 1
 		AND a.detail_type IN ('AccountsReceivable', 'AccountsPayable', 'Bank')
+		GROUP BY a.id, a.name, c.id, c.name
 	`
 
 	businessId, ok := utils.GetBusinessIdFromContext(ctx)
@@ -77,17 +82,13 @@ func GetUnrealisedExchangeGainLossReport(ctx context.Context, branchId *int, toD
 	// 	return nil, err
 	// }
 
-	branch := 0
-	if branchId != nil && *branchId != 0 {
-		branch = *branchId
-	}
 	var records []*UnrealisedExchangeGainLossResponse
 	db := config.GetDB()
 	if err := db.WithContext(ctx).Raw(sql, map[string]interface{}{
 		"businessId":     businessId,
 		"toDate":         toDate,
 		"baseCurrencyId": business.BaseCurrencyId,
-		"branchId":       branch,
+		"branchIds":      models.BalanceBranchIds(branchIds),
 	}).Scan(&records).Error; err != nil {
 		return nil, err
 	}
@@ -0,0 +1,201 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+)
+
+type WithholdingTaxSummary struct {
+	SupplierId         int             `json:"supplierId"`
+	SupplierName       string          `json:"supplierName"`
+	WithholdingTaxId   int             `json:"withholdingTaxId"`
+	WithholdingTaxName string          `json:"withholdingTaxName"`
+	Rate               decimal.Decimal `json:"rate"`
+	PaymentCount       int             `json:"paymentCount"`
+	GrossAmount        decimal.Decimal `json:"grossAmount"`
+	WithheldAmount     decimal.Decimal `json:"withheldAmount"`
+}
+
+type WithholdingTaxCertificateLine struct {
+	PaymentId          int             `json:"paymentId"`
+	PaymentNumber      string          `json:"paymentNumber"`
+	PaymentDate        time.Time       `json:"paymentDate"`
+	ReferenceNumber    string          `json:"referenceNumber"`
+	WithholdingTaxName string          `json:"withholdingTaxName"`
+	Rate               decimal.Decimal `json:"rate"`
+	CurrencyId         int             `json:"currencyId"`
+	GrossAmountFcy     decimal.Decimal `json:"grossAmountFcy"`
+	GrossAmount        decimal.Decimal `json:"grossAmount"`
+	WithheldAmountFcy  decimal.Decimal `json:"withheldAmountFcy"`
+	WithheldAmount     decimal.Decimal `json:"withheldAmount"`
+}
+
+// WithholdingTaxCertificate lists the tax withheld from one supplier over a period,
+// for the certificate handed to the supplier.
+type WithholdingTaxCertificate struct {
+	BusinessName        string                           `json:"businessName"`
+	BusinessTaxId       string                           `json:"businessTaxId"`
+	SupplierId          int                              `json:"supplierId"`
+	SupplierName        string                           `json:"supplierName"`
+	FromDate            time.Time                        `json:"fromDate"`
+	ToDate              time.Time                        `json:"toDate"`
+	Lines               []*WithholdingTaxCertificateLine `json:"lines"`
+	TotalGrossAmount    decimal.Decimal                  `json:"totalGrossAmount"`
+	TotalWithheldAmount decimal.Decimal                  `json:"totalWithheldAmount"`
+}
+
+// payments still waiting for approval have not withheld anything yet
+const withholdingTaxPaymentsSql = `
+FROM
+    supplier_payments sp
+    INNER JOIN withholding_taxes wt ON wt.id = sp.withholding_tax_id
+    LEFT JOIN suppliers ON suppliers.id = sp.supplier_id
+WHERE
+    sp.business_id = @businessId
+    AND sp.withholding_tax_amount <> 0
+    AND sp.payment_date BETWEEN @fromDate AND @toDate
+    AND (sp.approval_status IS NULL OR sp.approval_status = 'APPROVED')
+    {{- if .branchIds }} AND sp.branch_id IN @branchIds {{- end }}
+    {{- if .supplierId }} AND sp.supplier_id = @supplierId {{- end }}
+`
+
+func withholdingTaxScope(ctx context.Context, fromDate *models.MyDateString, toDate *models.MyDateString, branchId *int) (*models.Business, []int, error) {
+	branchIds, err := models.NarrowReportBranch(ctx, branchId)
+	if err != nil {
+		return nil, nil, err
+	}
+	business, err := models.GetBusiness(ctx)
+	if err != nil {
+		return nil, nil, errors.New("business id is required")
+	}
+	if err := fromDate.StartOfDayUTCTime(business.Timezone); err != nil {
+		return nil, nil, err
+	}
+	if err := toDate.EndOfDayUTCTime(business.Timezone); err != nil {
+		return nil, nil, err
+	}
+	if branchId != nil && *branchId != 0 {
+		if err := utils.ValidateResourceId[models.Branch](ctx, business.ID.String(), branchId); err != nil {
+			return nil, nil, errors.New("branch not found")
+		}
+	}
+	return business, branchIds, nil
+}
+
+// GetWithholdingTaxSummaryReport totals, per supplier and withholding tax, what was paid
+// and withheld in the period, in base currency.
+func GetWithholdingTaxSummaryReport(ctx context.Context, fromDate models.MyDateString, toDate models.MyDateString, branchId *int, supplierId *int) ([]*WithholdingTaxSummary, error) {
+	business, branchIds, err := withholdingTaxScope(ctx, &fromDate, &toDate, branchId)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlT := `
+SELECT
+    sp.supplier_id,
+    suppliers.name supplier_name,
+    wt.id withholding_tax_id,
+    wt.name withholding_tax_name,
+    wt.rate,
+    COUNT(sp.id) payment_count,
+    SUM(CASE WHEN sp.currency_id <> @baseCurrencyId THEN sp.amount * sp.exchange_rate ELSE sp.amount END) gross_amount,
+    SUM(CASE WHEN sp.currency_id <> @baseCurrencyId THEN sp.withholding_tax_amount * sp.exchange_rate ELSE sp.withholding_tax_amount END) withheld_amount
+` + withholdingTaxPaymentsSql + `
+GROUP BY
+    sp.supplier_id, suppliers.name, wt.id, wt.name, wt.rate
+ORDER BY
+    suppliers.name, wt.name;
+`
+	sql, err := utils.ExecTemplate(sqlT, map[string]interface{}{
+		"branchIds":  len(branchIds) > 0,
+		"supplierId": supplierId != nil && *supplierId > 0,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	var results []*WithholdingTaxSummary
+	if err := db.WithContext(ctx).Raw(sql, map[string]interface{}{
+		"businessId":     business.ID,
+		"baseCurrencyId": business.BaseCurrencyId,
+		"fromDate":       fromDate,
+		"toDate":         toDate,
+		"branchIds":      branchIds,
+		"supplierId":     utils.DereferencePtr(supplierId),
+	}).Scan(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetWithholdingTaxCertificate lists each payment a supplier had tax withheld from in the period.
+func GetWithholdingTaxCertificate(ctx context.Context, supplierId int, fromDate models.MyDateString, toDate models.MyDateString, branchId *int) (*WithholdingTaxCertificate, error) {
+	business, branchIds, err := withholdingTaxScope(ctx, &fromDate, &toDate, branchId)
+	if err != nil {
+		return nil, err
+	}
+	supplier, err := utils.FetchModel[models.Supplier](ctx, business.ID.String(), supplierId)
+	if err != nil {
+		return nil, errors.New("supplier not found")
+	}
+
+	sqlT := `
+SELECT
+    sp.id payment_id,
+    sp.payment_number,
+    sp.payment_date,
+    sp.reference_number,
+    wt.name withholding_tax_name,
+    wt.rate,
+    sp.currency_id,
+    CASE WHEN sp.currency_id <> @baseCurrencyId THEN sp.amount ELSE 0 END gross_amount_fcy,
+    CASE WHEN sp.currency_id <> @baseCurrencyId THEN sp.amount * sp.exchange_rate ELSE sp.amount END gross_amount,
+    CASE WHEN sp.currency_id <> @baseCurrencyId THEN sp.withholding_tax_amount ELSE 0 END withheld_amount_fcy,
+    CASE WHEN sp.currency_id <> @baseCurrencyId THEN sp.withholding_tax_amount * sp.exchange_rate ELSE sp.withholding_tax_amount END withheld_amount
+` + withholdingTaxPaymentsSql + `
+ORDER BY
+    sp.payment_date, sp.id;
+`
+	sql, err := utils.ExecTemplate(sqlT, map[string]interface{}{
+		"branchIds":  len(branchIds) > 0,
+		"supplierId": true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	var lines []*WithholdingTaxCertificateLine
+	if err := db.WithContext(ctx).Raw(sql, map[string]interface{}{
+		"businessId":     business.ID,
+		"baseCurrencyId": business.BaseCurrencyId,
+		"fromDate":       fromDate,
+		"toDate":         toDate,
+		"branchIds":      branchIds,
+		"supplierId":     supplierId,
+	}).Scan(&lines).Error; err != nil {
+		return nil, err
+	}
+
+	certificate := WithholdingTaxCertificate{
+		BusinessName:  business.Name,
+		BusinessTaxId: business.TaxId,
+		SupplierId:    supplier.ID,
+		SupplierName:  supplier.Name,
+		FromDate:      time.Time(fromDate),
+		ToDate:        time.Time(toDate),
+		Lines:         lines,
+	}
+	for _, line := range lines {
+		certificate.TotalGrossAmount = certificate.TotalGrossAmount.Add(line.GrossAmount)
+		certificate.TotalWithheldAmount = certificate.TotalWithheldAmount.Add(line.WithheldAmount)
+	}
+	return &certificate, nil
+}
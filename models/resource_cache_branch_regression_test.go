@@ -0,0 +1,131 @@
+package models_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/mmdatafocus/books_backend/utils"
+)
+
+// Regression: the resource caches are filled business-wide, so a branch restricted user
+// must not be answered from them, nor fill them with the rows of the user's branches.
+func TestResourceCache_BranchRestrictedUsers(t *testing.T) {
+	if strings.TrimSpace(os.Getenv("INTEGRATION_TESTS")) == "" {
+		t.Skip("set INTEGRATION_TESTS=1 to run integration tests (requires docker)")
+	}
+
+	ctx := context.Background()
+
+	redisName, redisPort := startRedisContainer(t)
+	t.Cleanup(func() { _ = dockerRmForce(redisName) })
+
+	mysqlName, mysqlPort := startMySQLContainer(t)
+	t.Cleanup(func() { _ = dockerRmForce(mysqlName) })
+
+	t.Setenv("REDIS_ADDRESS", fmt.Sprintf("127.0.0.1:%s", redisPort))
+	t.Setenv("DB_USER", "root")
+	t.Setenv("DB_PASSWORD", "testpw")
+	t.Setenv("DB_HOST", "127.0.0.1")
+	t.Setenv("DB_PORT", mysqlPort)
+	t.Setenv("DB_NAME_2", "pitibooks_test")
+	t.Setenv("STOCK_COMMANDS_DOCS", "")
+
+	config.ConnectDatabaseWithRetry()
+	config.ConnectRedisWithRetry()
+	models.MigrateTable()
+
+	ctx = utils.SetUserIdInContext(ctx, 1)
+	ctx = utils.SetUserNameInContext(ctx, "Test")
+	ctx = utils.SetUsernameInContext(ctx, "test@local")
+
+	biz, err := models.CreateBusiness(ctx, &models.NewBusiness{
+		Name:  "Test Biz",
+		Email: "owner@test.local",
+	})
+	if err != nil {
+		t.Fatalf("CreateBusiness: %v", err)
+	}
+	businessID := biz.ID.String()
+	ctx = utils.SetBusinessIdInContext(ctx, businessID)
+
+	primary, err := models.GetBranch(ctx, biz.PrimaryBranchId)
+	if err != nil {
+		t.Fatalf("GetBranch: %v", err)
+	}
+	other, err := models.CreateBranch(ctx, &models.NewBranch{
+		TransactionNumberSeriesId: primary.TransactionNumberSeriesId,
+		Name:                      "Other Branch",
+	})
+	if err != nil {
+		t.Fatalf("CreateBranch: %v", err)
+	}
+	otherWarehouse, err := models.CreateWarehouse(ctx, &models.NewWarehouse{
+		BranchId: other.ID,
+		Name:     "Other Warehouse",
+	})
+	if err != nil {
+		t.Fatalf("CreateWarehouse: %v", err)
+	}
+	clearCaches := func() {
+		t.Helper()
+		for _, err := range []error{
+			utils.RemoveRedisItem[models.Branch](other.ID),
+			utils.RemoveRedisList[models.AllWarehouse](businessID),
+			utils.RemoveRedisMap[models.AllWarehouse](businessID),
+		} {
+			if err != nil {
+				t.Fatalf("clear cache: %v", err)
+			}
+		}
+	}
+	hasOtherWarehouse := func(list []*models.AllWarehouse) bool {
+		for _, w := range list {
+			if w.ID == otherWarehouse.ID {
+				return true
+			}
+		}
+		return false
+	}
+	restrictedCtx := utils.SetBranchIdsInContext(ctx, []int{primary.ID})
+
+	// filled by an unrestricted user, the caches do not answer a restricted one
+	clearCaches()
+	if _, err := models.GetBranch(ctx, other.ID); err != nil {
+		t.Fatalf("GetBranch (owner): %v", err)
+	}
+	if list, err := models.ListAllWarehouse(ctx); err != nil || !hasOtherWarehouse(list) {
+		t.Fatalf("ListAllWarehouse (owner) = %v, %v; want the other branch's warehouse", list, err)
+	}
+	if m, err := models.MapAllWarehouse(ctx); err != nil || m[otherWarehouse.ID] == nil {
+		t.Fatalf("MapAllWarehouse (owner) = %v, %v; want the other branch's warehouse", m, err)
+	}
+	if b, err := models.GetBranch(restrictedCtx, other.ID); err == nil {
+		t.Errorf("restricted user got the other branch from the cache: %+v", b)
+	}
+	if list, err := models.ListAllWarehouse(restrictedCtx); err != nil || hasOtherWarehouse(list) {
+		t.Errorf("restricted ListAllWarehouse = %v, %v; want only its own branch's", list, err)
+	}
+	if m, err := models.MapAllWarehouse(restrictedCtx); err != nil || m[otherWarehouse.ID] != nil {
+		t.Errorf("restricted MapAllWarehouse = %v, %v; want only its own branch's", m, err)
+	}
+
+	// loaded by a restricted user first, the caches still give others every branch
+	clearCaches()
+	if _, err := models.ListAllWarehouse(restrictedCtx); err != nil {
+		t.Fatalf("ListAllWarehouse (restricted): %v", err)
+	}
+	if _, err := models.MapAllWarehouse(restrictedCtx); err != nil {
+		t.Fatalf("MapAllWarehouse (restricted): %v", err)
+	}
+	if list, err := models.ListAllWarehouse(ctx); err != nil || !hasOtherWarehouse(list) {
+		t.Errorf("owner ListAllWarehouse after a restricted load = %v, %v; want every branch's", list, err)
+	}
+	if m, err := models.MapAllWarehouse(ctx); err != nil || m[otherWarehouse.ID] == nil {
+		t.Errorf("owner MapAllWarehouse after a restricted load = %v, %v; want every branch's", m, err)
+	}
+}
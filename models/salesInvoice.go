@@ -35,6 +35,7 @@ type SalesInvoice struct {
 	Notes                         string               `gorm:"type:text;default:null" json:"notes"`
 	TermsAndConditions            string               `gorm:"type:text;default:null" json:"terms_and_conditions"`
 	CurrencyId                    int                  `gorm:"not null" json:"currency_id" binding:"required"`
+	PriceListId                   int                  `gorm:"default:0" json:"price_list_id"`
 	ExchangeRate                  decimal.Decimal      `gorm:"type:decimal(20,4);default:0" json:"exchange_rate"`
 	WarehouseId                   int                  `gorm:"not null" json:"warehouse_id" binding:"required"`
 	InvoiceDiscount               decimal.Decimal      `gorm:"type:decimal(20,4);default:0" json:"invoice_discount"`
@@ -60,6 +61,9 @@ type SalesInvoice struct {
 	RemainingBalance              decimal.Decimal      `gorm:"type:decimal(20,4);default:0" json:"remaining_balance"`
 	WriteOffDate                  *time.Time           `json:"write_off_date"`
 	WriteOffReason                string               `gorm:"type:text;default:null" json:"write_off_reason"`
+	RecurringInvoiceId            int                  `gorm:"index;default:null" json:"recurring_invoice_id"`
+	EstimateId                    int                  `gorm:"index;default:null" json:"estimate_id"`
+	PaymentReminderId             int                  `gorm:"index;default:null" json:"payment_reminder_id"`
 	CreatedAt                     time.Time            `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt                     time.Time            `gorm:"autoUpdateTime" json:"updated_at"`
 }
@@ -78,6 +82,7 @@ type NewSalesInvoice struct {
 	Notes                         string                  `json:"notes"`
 	TermsAndConditions            string                  `json:"terms_and_conditions"`
 	CurrencyId                    int                     `json:"currency_id" binding:"required"`
+	PriceListId                   int                     `json:"price_list_id"`
 	ExchangeRate                  decimal.Decimal         `json:"exchange_rate"`
 	WarehouseId                   int                     `json:"warehouse_id" binding:"required"`
 	InvoiceDiscount               decimal.Decimal         `json:"invoice_discount"`
@@ -90,6 +95,9 @@ type NewSalesInvoice struct {
 	CurrentStatus                 SalesInvoiceStatus      `json:"current_status" binding:"required"`
 	Documents                     []*NewDocument          `json:"documents"`
 	Details                       []NewSalesInvoiceDetail `json:"details"`
+	RecurringInvoiceId            int                     `json:"recurring_invoice_id"`
+	EstimateId                    int                     `json:"estimate_id"`
+	PaymentReminderId             int                     `json:"payment_reminder_id"`
 }
 
 type SalesInvoiceDetail struct {
@@ -98,10 +106,11 @@ type SalesInvoiceDetail struct {
 	ProductId            int             `gorm:"index" json:"product_id"`
 	ProductType          ProductType     `gorm:"type:enum('S','G','C','V','I');default:S" json:"product_type"`
 	BatchNumber          string          `gorm:"size:100" json:"batch_number"`
+	SerialNumbers        []string        `gorm:"type:text;serializer:json" json:"serial_numbers"`
 	Name                 string          `gorm:"size:100" json:"name" binding:"required"`
 	Description          string          `gorm:"size:255;default:null" json:"description"`
 	DetailQty            decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_qty" binding:"required"`
-	DetailUnitRate       decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_unit_rate" binding:"required"`
+	DetailUnitRate       decimal.Decimal `gorm:"type:decimal(20,8);default:0" json:"detail_unit_rate" binding:"required"`
 	DetailTaxId          int             `gorm:"default:null" json:"detail_tax_id"`
 	DetailTaxType        *TaxType        `gorm:"type:enum('I', 'G');default:null" json:"detail_tax_type"`
 	DetailAccountId      int             `gorm:"default:null" json:"detail_account_id"`
@@ -115,6 +124,7 @@ type SalesInvoiceDetail struct {
 	SalesOrderItemId     int             `gorm:"index" json:"sales_order_item_id"`
 	CreatedAt            time.Time       `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt            time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+	LineUnit
 }
 
 type NewSalesInvoiceDetail struct {
@@ -122,10 +132,12 @@ type NewSalesInvoiceDetail struct {
 	ProductId          int             `json:"product_id"`
 	ProductType        ProductType     `json:"product_type"`
 	BatchNumber        string          `json:"batch_number"`
+	SerialNumbers      []string        `json:"serial_numbers"`
 	Name               string          `json:"name" binding:"required"`
 	Description        string          `json:"description"`
 	DetailQty          decimal.Decimal `json:"detail_qty" binding:"required"`
 	DetailUnitRate     decimal.Decimal `json:"detail_unit_rate" binding:"required"`
+	UsePriceListRate   *bool           `json:"use_price_list_rate"`
 	DetailTaxId        int             `json:"detail_tax_id"`
 	DetailTaxType      *TaxType        `json:"detail_tax_type"`
 	DetailDiscount     decimal.Decimal `json:"detail_discount"`
@@ -133,6 +145,7 @@ type NewSalesInvoiceDetail struct {
 	DetailAccountId    int             `json:"detail_account_id"`
 	IsDeletedItem      *bool           `json:"is_deleted_item"`
 	SalesOrderItemId   int             `json:"sales_order_item_id"`
+	LineUnit
 }
 
 type SalesInvoicesConnection struct {
@@ -470,6 +483,33 @@ ledgerOKEdit:
 	return nil
 }
 
+func (input *NewSalesInvoice) applyPriceList(ctx context.Context, businessId string) error {
+	list, err := documentPriceList(ctx, businessId, PriceListTypeSales, &input.PriceListId, input.CustomerId, input.CurrencyId, input.InvoiceDate)
+	if err != nil {
+		return err
+	}
+	for i := range input.Details {
+		d := &input.Details[i]
+		if err := list.fillRate(ctx, businessId, d.ProductType, d.ProductId, d.UnitId, d.DetailQty, d.UsePriceListRate, &d.DetailUnitRate); err != nil {
+			return fmt.Errorf("%s: %w", d.Name, err)
+		}
+	}
+	return nil
+}
+
+func (input *NewSalesInvoice) detailsToBaseUnit(ctx context.Context, businessId string) error {
+	for i := range input.Details {
+		d := &input.Details[i]
+		if d.IsDeletedItem != nil && *d.IsDeletedItem {
+			continue
+		}
+		if err := d.LineUnit.toBaseUnit(ctx, businessId, d.ProductType, d.ProductId, &d.DetailQty, &d.DetailUnitRate); err != nil {
+			return fmt.Errorf("%s: %w", d.Name, err)
+		}
+	}
+	return nil
+}
+
 func CreateSalesInvoice(ctx context.Context, input *NewSalesInvoice) (*SalesInvoice, error) {
 	db := config.GetDB()
 
@@ -494,10 +534,19 @@ func CreateSalesInvoice(ctx context.Context, input *NewSalesInvoice) (*SalesInvo
 	// This ensures stock movements happen through the same status-transition path everywhere.
 	requestedStatus := input.CurrentStatus
 
+	if err := input.applyPriceList(ctx, businessId); err != nil {
+		return nil, err
+	}
+	if err := input.detailsToBaseUnit(ctx, businessId); err != nil {
+		return nil, err
+	}
 	// validate SalesInvoice
 	if err := input.validate(ctx, businessId, 0); err != nil {
 		return nil, err
 	}
+	if err := resolveExchangeRate(ctx, businessId, input.CurrencyId, input.InvoiceDate, &input.ExchangeRate); err != nil {
+		return nil, err
+	}
 
 	var saleOrder SalesOrder
 	saleOrderId := 0
@@ -551,10 +600,12 @@ func CreateSalesInvoice(ctx context.Context, input *NewSalesInvoice) (*SalesInvo
 			ProductId:          item.ProductId,
 			ProductType:        item.ProductType,
 			BatchNumber:        item.BatchNumber,
+			SerialNumbers:      item.SerialNumbers,
 			Name:               item.Name,
 			Description:        item.Description,
 			DetailQty:          item.DetailQty,
 			DetailUnitRate:     item.DetailUnitRate,
+			LineUnit:           item.LineUnit,
 			DetailTaxId:        item.DetailTaxId,
 			DetailTaxType:      item.DetailTaxType,
 			DetailDiscount:     item.DetailDiscount,
@@ -565,7 +616,9 @@ func CreateSalesInvoice(ctx context.Context, input *NewSalesInvoice) (*SalesInvo
 
 		// Keep legacy behavior: if this line is for a non-inventory item, skip stock checks.
 		// For inventory-tracked items, validate using ledger snapshots as-of invoice date.
-		if validateStockOnCreate && item.ProductId > 0 && (item.ProductType == ProductTypeSingle || item.ProductType == ProductTypeVariant) {
+		// Kitted lines are assembled on confirm, which checks their components instead.
+		kitLine := validateStockOnCreate && isInvoiceKitLine(tx, businessId, item.ProductType, item.ProductId)
+		if validateStockOnCreate && !kitLine && item.ProductId > 0 && (item.ProductType == ProductTypeSingle || item.ProductType == ProductTypeVariant) {
 			product, err := GetProductOrVariant(ctx, string(item.ProductType), item.ProductId)
 			if err != nil {
 				tx.Rollback()
@@ -664,7 +717,7 @@ func CreateSalesInvoice(ctx context.Context, input *NewSalesInvoice) (*SalesInvo
 					reservedGlobal[globalKey] = reservedGlobal[globalKey].Add(item.DetailQty)
 				}
 			}
-		} else if validateStockOnCreate {
+		} else if validateStockOnCreate && !kitLine {
 			// Fallback for other product types / legacy behavior.
 			if err := ValidateProductStock(tx, ctx, businessId, input.WarehouseId, item.BatchNumber, item.ProductType, item.ProductId, item.DetailQty); err != nil {
 				tx.Rollback()
@@ -779,6 +832,7 @@ func CreateSalesInvoice(ctx context.Context, input *NewSalesInvoice) (*SalesInvo
 		Notes:                         input.Notes,
 		TermsAndConditions:            input.TermsAndConditions,
 		CurrencyId:                    input.CurrencyId,
+		PriceListId:                   input.PriceListId,
 		ExchangeRate:                  input.ExchangeRate,
 		WarehouseId:                   input.WarehouseId,
 		InvoiceDiscount:               input.InvoiceDiscount,
@@ -798,6 +852,9 @@ func CreateSalesInvoice(ctx context.Context, input *NewSalesInvoice) (*SalesInvo
 		InvoiceSubtotal:               invoiceSubtotal,
 		InvoiceTotalAmount:            invoiceTotalAmount,
 		RemainingBalance:              invoiceTotalAmount,
+		RecurringInvoiceId:            input.RecurringInvoiceId,
+		EstimateId:                    input.EstimateId,
+		PaymentReminderId:             input.PaymentReminderId,
 	}
 
 	// Invoice numbering (Option A UX):
@@ -826,6 +883,12 @@ func CreateSalesInvoice(ctx context.Context, input *NewSalesInvoice) (*SalesInvo
 		tx.Rollback()
 		return nil, err
 	}
+	if saleInvoice.EstimateId > 0 {
+		if err := markEstimateConverted(tx.WithContext(ctx), saleInvoice.EstimateId, "sales_invoice_id", saleInvoice.ID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
 
 	// Reload with Details so stock side-effects can access them.
 	if err := tx.WithContext(ctx).Preload("Details").First(&saleInvoice, saleInvoice.ID).Error; err != nil {
@@ -835,6 +898,11 @@ func CreateSalesInvoice(ctx context.Context, input *NewSalesInvoice) (*SalesInvo
 
 	// If requested "Confirmed", apply the status transition deterministically (Draft -> Confirmed).
 	if requestedStatus == SalesInvoiceStatusConfirmed {
+		if err := checkCustomerCredit(ctx, tx, "SalesInvoice", "sales_invoices", saleInvoice.ID, saleInvoice.CustomerId,
+			saleInvoice.CurrencyId, saleInvoice.ExchangeRate, saleInvoice.InvoiceTotalAmount, saleInvoice.SalesOrderId, saleInvoice.Details); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
 		// Persist new status first.
 		if err := tx.WithContext(ctx).Model(&saleInvoice).Update("CurrentStatus", SalesInvoiceStatusConfirmed).Error; err != nil {
 			tx.Rollback()
@@ -857,6 +925,16 @@ func CreateSalesInvoice(ctx context.Context, input *NewSalesInvoice) (*SalesInvo
 			}
 		}
 
+		if err := assembleInvoiceKits(ctx, tx, &saleInvoice, nil); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		if err := postSerialNumbers(tx.WithContext(ctx), businessId, saleInvoice.serialNumberPosting()); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
 		// Write outbox record (publishing happens after commit via dispatcher).
 		if err := PublishToAccounting(ctx, tx, businessId, saleInvoice.InvoiceDate, saleInvoice.ID, AccountReferenceTypeInvoice, saleInvoice, nil, PubSubMessageActionCreate); err != nil {
 			tx.Rollback()
@@ -864,6 +942,17 @@ func CreateSalesInvoice(ctx context.Context, input *NewSalesInvoice) (*SalesInvo
 		}
 	}
 
+	if err := QueueWebhookEvent(ctx, tx, businessId, WebhookEventInvoiceCreated, string(AccountReferenceTypeInvoice), saleInvoice.ID, saleInvoice); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if saleInvoice.CurrentStatus == SalesInvoiceStatusConfirmed {
+		if err := QueueWebhookEvent(ctx, tx, businessId, WebhookEventInvoiceConfirmed, string(AccountReferenceTypeInvoice), saleInvoice.ID, saleInvoice); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
 	// Commit the transaction
 	if err := tx.Commit().Error; err != nil {
 		return nil, err
@@ -885,6 +974,12 @@ func UpdateSalesInvoice(ctx context.Context, invoiceId int, updatedInvoice *NewS
 		}
 	}
 
+	if err := updatedInvoice.applyPriceList(ctx, businessId); err != nil {
+		return nil, err
+	}
+	if err := updatedInvoice.detailsToBaseUnit(ctx, businessId); err != nil {
+		return nil, err
+	}
 	// validate SalesInvoice
 	if err := updatedInvoice.validate(ctx, businessId, invoiceId); err != nil {
 		return nil, err
@@ -964,6 +1059,7 @@ func UpdateSalesInvoice(ctx context.Context, invoiceId int, updatedInvoice *NewS
 	existingInvoice.Notes = updatedInvoice.Notes
 	existingInvoice.TermsAndConditions = updatedInvoice.TermsAndConditions
 	existingInvoice.CurrencyId = updatedInvoice.CurrencyId
+	existingInvoice.PriceListId = updatedInvoice.PriceListId
 	existingInvoice.ExchangeRate = updatedInvoice.ExchangeRate
 	existingInvoice.WarehouseId = updatedInvoice.WarehouseId
 	existingInvoice.InvoiceDiscount = updatedInvoice.InvoiceDiscount
@@ -1035,7 +1131,8 @@ func UpdateSalesInvoice(ctx context.Context, invoiceId int, updatedInvoice *NewS
 		}
 		if validateStockOnUpdate && updatedItem.ProductId > 0 &&
 			(updatedItem.ProductType == ProductTypeSingle || updatedItem.ProductType == ProductTypeVariant) &&
-			(updatedItem.IsDeletedItem == nil || !*updatedItem.IsDeletedItem) {
+			(updatedItem.IsDeletedItem == nil || !*updatedItem.IsDeletedItem) &&
+			!isInvoiceKitLine(tx, businessId, updatedItem.ProductType, updatedItem.ProductId) {
 			product, err := GetProductOrVariant(ctx, string(updatedItem.ProductType), updatedItem.ProductId)
 			if err != nil {
 				tx.Rollback()
@@ -1074,10 +1171,12 @@ func UpdateSalesInvoice(ctx context.Context, invoiceId int, updatedInvoice *NewS
 				ProductId:          updatedItem.ProductId,
 				ProductType:        updatedItem.ProductType,
 				BatchNumber:        updatedItem.BatchNumber,
+				SerialNumbers:      updatedItem.SerialNumbers,
 				Name:               updatedItem.Name,
 				Description:        updatedItem.Description,
 				DetailQty:          updatedItem.DetailQty,
 				DetailUnitRate:     updatedItem.DetailUnitRate,
+				LineUnit:           updatedItem.LineUnit,
 				DetailTaxId:        updatedItem.DetailTaxId,
 				DetailTaxType:      updatedItem.DetailTaxType,
 				DetailDiscount:     updatedItem.DetailDiscount,
@@ -1217,10 +1316,12 @@ func UpdateSalesInvoice(ctx context.Context, invoiceId int, updatedInvoice *NewS
 				var oldQty decimal.Decimal = existingItem.DetailQty
 
 				existingItem.BatchNumber = updatedItem.BatchNumber
+				existingItem.SerialNumbers = updatedItem.SerialNumbers
 				existingItem.Name = updatedItem.Name
 				existingItem.Description = updatedItem.Description
 				existingItem.DetailQty = updatedItem.DetailQty
 				existingItem.DetailUnitRate = updatedItem.DetailUnitRate
+				existingItem.LineUnit = updatedItem.LineUnit
 				existingItem.DetailTaxId = updatedItem.DetailTaxId
 				existingItem.DetailTaxType = updatedItem.DetailTaxType
 				existingItem.DetailDiscount = updatedItem.DetailDiscount
@@ -1437,14 +1538,65 @@ func UpdateSalesInvoice(ctx context.Context, invoiceId int, updatedInvoice *NewS
 	// 	return nil, err
 	// }
 
+	if existingInvoice.CurrentStatus == SalesInvoiceStatusConfirmed {
+		if err := postSerialNumbers(tx.WithContext(ctx), businessId, existingInvoice.serialNumberPosting()); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
 	if oldStatus == SalesInvoiceStatusDraft && existingInvoice.CurrentStatus == SalesInvoiceStatusConfirmed {
-		err := PublishToAccounting(ctx, tx, businessId, existingInvoice.InvoiceDate, existingInvoice.ID, AccountReferenceTypeInvoice, existingInvoice, nil, PubSubMessageActionCreate)
+		err := checkCustomerCredit(ctx, tx, "SalesInvoice", "sales_invoices", existingInvoice.ID, existingInvoice.CustomerId,
+			existingInvoice.CurrencyId, existingInvoice.ExchangeRate, existingInvoice.InvoiceTotalAmount, existingInvoice.SalesOrderId, existingInvoice.Details)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := assembleInvoiceKits(ctx, tx, &existingInvoice, nil); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		err = PublishToAccounting(ctx, tx, businessId, existingInvoice.InvoiceDate, existingInvoice.ID, AccountReferenceTypeInvoice, existingInvoice, nil, PubSubMessageActionCreate)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		err = QueueWebhookEvent(ctx, tx, businessId, WebhookEventInvoiceConfirmed, string(AccountReferenceTypeInvoice), existingInvoice.ID, existingInvoice)
 		if err != nil {
 			tx.Rollback()
 			return nil, err
 		}
 	} else if oldStatus == SalesInvoiceStatusConfirmed && existingInvoice.CurrentStatus == SalesInvoiceStatusConfirmed {
-		err := PublishToAccounting(ctx, tx, businessId, existingInvoice.InvoiceDate, existingInvoice.ID, AccountReferenceTypeInvoice, existingInvoice, oldInvoice, PubSubMessageActionUpdate)
+		// Kits follow quantity changes in place. A new date or warehouse moves the sale, so
+		// the kits are assembled again for it and the earlier orders are removed afterwards.
+		oldKitInvoice := *oldInvoice
+		oldKitInvoice.Details = existingInvoiceDetails
+		moveKits := oldInvoiceDate != nil || oldWarehouseId != nil
+		var priorKits []*AssemblyOrder
+		if moveKits {
+			priorKits, err = invoiceKitOrders(tx, businessId, existingInvoice.ID)
+			if err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+			err = assembleInvoiceKits(ctx, tx, &existingInvoice, nil)
+		} else {
+			err = assembleInvoiceKits(ctx, tx, &existingInvoice, &oldKitInvoice)
+		}
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		err = PublishToAccounting(ctx, tx, businessId, existingInvoice.InvoiceDate, existingInvoice.ID, AccountReferenceTypeInvoice, existingInvoice, oldInvoice, PubSubMessageActionUpdate)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if moveKits {
+			err = deleteInvoiceKits(ctx, tx, priorKits)
+		} else {
+			err = disassembleInvoiceKits(ctx, tx, &existingInvoice, &oldKitInvoice)
+		}
 		if err != nil {
 			tx.Rollback()
 			return nil, err
@@ -1527,11 +1679,31 @@ func DeleteSalesInvoice(ctx context.Context, id int) (*SalesInvoice, error) {
 	}
 
 	if result.CurrentStatus == SalesInvoiceStatusConfirmed {
+		if err := unpostSerialNumbers(tx.WithContext(ctx), businessId, StockReferenceTypeInvoice, result.ID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
 		err = PublishToAccounting(ctx, tx, businessId, result.InvoiceDate, result.ID, AccountReferenceTypeInvoice, nil, result, PubSubMessageActionDelete)
 		if err != nil {
 			tx.Rollback()
 			return nil, err
 		}
+		kits, err := invoiceKitOrders(tx, businessId, result.ID)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := deleteInvoiceKits(ctx, tx, kits); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if result.EstimateId > 0 {
+		if err := releaseEstimate(tx.WithContext(ctx), result.EstimateId, "sales_invoice_id", result.ID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
 	}
 
 	if err := deleteDocuments(ctx, tx, result.Documents); err != nil {
@@ -1580,6 +1752,9 @@ func UpdateStatusSalesInvoice(ctx context.Context, id int, status string) (*Sale
 			if d.ProductId <= 0 || (d.ProductType != ProductTypeSingle && d.ProductType != ProductTypeVariant) {
 				continue
 			}
+			if isInvoiceKitLine(tx, businessId, d.ProductType, d.ProductId) {
+				continue
+			}
 			product, err := GetProductOrVariant(ctx, string(d.ProductType), d.ProductId)
 			if err != nil {
 				tx.Rollback()
@@ -1752,17 +1927,60 @@ func UpdateStatusSalesInvoice(ctx context.Context, id int, status string) (*Sale
 	}
 
 	if oldStatus == SalesInvoiceStatusDraft && status == string(SalesInvoiceStatusConfirmed) {
-		err := PublishToAccounting(ctx, tx, businessId, saleInvoice.InvoiceDate, saleInvoice.ID, AccountReferenceTypeInvoice, saleInvoice, nil, PubSubMessageActionCreate)
+		err := checkCustomerCredit(ctx, tx, "SalesInvoice", "sales_invoices", saleInvoice.ID, saleInvoice.CustomerId,
+			saleInvoice.CurrencyId, saleInvoice.ExchangeRate, saleInvoice.InvoiceTotalAmount, saleInvoice.SalesOrderId, saleInvoice.Details)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		kitInvoice := *saleInvoice
+		kitInvoice.CurrentStatus = SalesInvoiceStatusConfirmed
+		if err := assembleInvoiceKits(ctx, tx, &kitInvoice, nil); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := postSerialNumbers(tx.WithContext(ctx), businessId, saleInvoice.serialNumberPosting()); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		err = PublishToAccounting(ctx, tx, businessId, saleInvoice.InvoiceDate, saleInvoice.ID, AccountReferenceTypeInvoice, saleInvoice, nil, PubSubMessageActionCreate)
 		if err != nil {
 			tx.Rollback()
 			return nil, err
 		}
 	} else if oldStatus == SalesInvoiceStatusConfirmed && status == string(SalesInvoiceStatusVoid) {
+		if err := unpostSerialNumbers(tx.WithContext(ctx), businessId, StockReferenceTypeInvoice, saleInvoice.ID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
 		err = PublishToAccounting(ctx, tx, businessId, saleInvoice.InvoiceDate, saleInvoice.ID, AccountReferenceTypeInvoice, nil, saleInvoice, PubSubMessageActionDelete)
 		if err != nil {
 			tx.Rollback()
 			return nil, err
 		}
+		kits, err := invoiceKitOrders(tx, businessId, saleInvoice.ID)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := deleteInvoiceKits(ctx, tx, kits); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	var event WebhookEvent
+	switch SalesInvoiceStatus(status) {
+	case SalesInvoiceStatusConfirmed:
+		event = WebhookEventInvoiceConfirmed
+	case SalesInvoiceStatusVoid:
+		event = WebhookEventInvoiceVoided
+	}
+	if event != "" && oldStatus != SalesInvoiceStatus(status) {
+		if err := QueueWebhookEvent(ctx, tx, businessId, event, string(AccountReferenceTypeInvoice), saleInvoice.ID, saleInvoice); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
 	}
 
 	// Commit the transaction
@@ -0,0 +1,147 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// Kitting on sale: invoiced quantities of products whose bill of materials has KitOnSale
+// are assembled from components by assembly orders linked to the invoice. Assemblies are
+// written to the outbox before the invoice's own message so the finished goods are in
+// stock when the sale is costed; disassemblies and deletions follow the invoice message
+// so the sale has released the goods first.
+
+type invoiceKitKey struct {
+	warehouseId int
+	productId   int
+	productType ProductType
+	batchNumber string
+}
+
+func isKitOnSale(tx *gorm.DB, businessId string, productType ProductType, productId int) (bool, error) {
+	if productId <= 0 || (productType != ProductTypeSingle && productType != ProductTypeVariant) {
+		return false, nil
+	}
+	var count int64
+	if err := tx.Model(&BillOfMaterials{}).
+		Where("business_id = ? AND product_id = ? AND product_type = ? AND kit_on_sale = ?", businessId, productId, productType, true).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// invoiceKitQty sums a posted invoice's quantities of kit-on-sale products.
+func invoiceKitQty(tx *gorm.DB, invoice *SalesInvoice) (map[invoiceKitKey]decimal.Decimal, error) {
+	result := make(map[invoiceKitKey]decimal.Decimal)
+	if invoice == nil || invoice.CurrentStatus == SalesInvoiceStatusDraft || invoice.CurrentStatus == SalesInvoiceStatusVoid {
+		return result, nil
+	}
+	for _, d := range invoice.Details {
+		kit, err := isKitOnSale(tx, invoice.BusinessId, d.ProductType, d.ProductId)
+		if err != nil {
+			return nil, err
+		}
+		if !kit {
+			continue
+		}
+		key := invoiceKitKey{invoice.WarehouseId, d.ProductId, d.ProductType, strings.TrimSpace(d.BatchNumber)}
+		result[key] = result[key].Add(d.DetailQty)
+	}
+	return result, nil
+}
+
+// postInvoiceKits creates confirmed orders of orderType for the kit quantities that
+// changed between old and invoice: assemblies for increases, disassemblies for decreases.
+func postInvoiceKits(ctx context.Context, tx *gorm.DB, invoice *SalesInvoice, old *SalesInvoice, orderType AssemblyOrderType) error {
+	newQty, err := invoiceKitQty(tx, invoice)
+	if err != nil {
+		return err
+	}
+	oldQty, err := invoiceKitQty(tx, old)
+	if err != nil {
+		return err
+	}
+	keys := make([]invoiceKitKey, 0, len(newQty)+len(oldQty))
+	for key := range newQty {
+		keys = append(keys, key)
+	}
+	for key := range oldQty {
+		if _, ok := newQty[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+	})
+
+	for _, key := range keys {
+		qty := newQty[key].Sub(oldQty[key])
+		if orderType == AssemblyOrderTypeDisassembly {
+			qty = qty.Neg()
+		}
+		if !qty.IsPositive() {
+			continue
+		}
+		input := NewAssemblyOrder{
+			OrderNumber:   invoice.InvoiceNumber,
+			OrderType:     orderType,
+			OrderDate:     invoice.InvoiceDate,
+			WarehouseId:   key.warehouseId,
+			ProductId:     key.productId,
+			ProductType:   key.productType,
+			BatchNumber:   key.batchNumber,
+			Quantity:      qty,
+			Notes:         "Kitted for sales invoice " + invoice.InvoiceNumber,
+			CurrentStatus: AssemblyOrderStatusConfirmed,
+		}
+		if _, err := createAssemblyOrder(ctx, tx, invoice.BusinessId, &input, invoice.ID); err != nil {
+			return fmt.Errorf("kitting %s: %w", invoice.InvoiceNumber, err)
+		}
+	}
+	return nil
+}
+
+// assembleInvoiceKits runs before the invoice's outbox message is written.
+func assembleInvoiceKits(ctx context.Context, tx *gorm.DB, invoice *SalesInvoice, old *SalesInvoice) error {
+	return postInvoiceKits(ctx, tx, invoice, old, AssemblyOrderTypeAssembly)
+}
+
+// disassembleInvoiceKits runs after the invoice's outbox message is written.
+func disassembleInvoiceKits(ctx context.Context, tx *gorm.DB, invoice *SalesInvoice, old *SalesInvoice) error {
+	return postInvoiceKits(ctx, tx, invoice, old, AssemblyOrderTypeDisassembly)
+}
+
+func invoiceKitOrders(tx *gorm.DB, businessId string, invoiceId int) ([]*AssemblyOrder, error) {
+	var orders []*AssemblyOrder
+	if err := tx.Preload("Details").
+		Where("business_id = ? AND sales_invoice_id = ?", businessId, invoiceId).
+		Order("id DESC").
+		Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// deleteInvoiceKits reverses orders kitted for an invoice, newest first. It runs after the
+// invoice's delete or update message is written.
+func deleteInvoiceKits(ctx context.Context, tx *gorm.DB, orders []*AssemblyOrder) error {
+	for _, ao := range orders {
+		if err := deleteAssemblyOrder(ctx, tx, ao); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isInvoiceKitLine reports whether stock checks should skip an invoice line because its
+// goods are assembled when the invoice posts; the components are checked instead.
+func isInvoiceKitLine(tx *gorm.DB, businessId string, productType ProductType, productId int) bool {
+	kit, err := isKitOnSale(tx, businessId, productType, productId)
+	return err == nil && kit
+}
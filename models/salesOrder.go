@@ -29,6 +29,7 @@ type SalesOrder struct {
 	Notes                       string             `gorm:"type:text" json:"notes"`
 	TermsAndConditions          string             `gorm:"type:text" json:"terms_and_conditions"`
 	CurrencyId                  int                `gorm:"not null" json:"currency_id" binding:"required"`
+	PriceListId                 int                `gorm:"default:0" json:"price_list_id"`
 	ExchangeRate                decimal.Decimal    `gorm:"type:decimal(20,4);default:0" json:"exchange_rate"`
 	OrderDiscount               decimal.Decimal    `gorm:"type:decimal(20,4);default:0" json:"order_discount"`
 	OrderDiscountType           *DiscountType      `gorm:"type:enum('P', 'A');default:null" json:"order_discount_type"`
@@ -46,6 +47,7 @@ type SalesOrder struct {
 	OrderTotalTaxAmount         decimal.Decimal    `gorm:"type:decimal(20,4);default:0" json:"order_total_tax_amount"`
 	OrderTotalAmount            decimal.Decimal    `gorm:"type:decimal(20,4);default:0" json:"order_total_amount"`
 	WarehouseId                 int                `gorm:"not null" json:"warehouse_id"`
+	EstimateId                  int                `gorm:"index;default:null" json:"estimate_id"`
 	CreatedAt                   time.Time          `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt                   time.Time          `gorm:"autoUpdateTime" json:"updated_at"`
 	Details                     []SalesOrderDetail `gorm:"foreignKey:SalesOrderId" json:"details"`
@@ -65,6 +67,7 @@ type NewSalesOrder struct {
 	Notes                       string                `json:"notes"`
 	TermsAndConditions          string                `json:"terms_and_conditions"`
 	CurrencyId                  int                   `json:"currency_id" binding:"required"`
+	PriceListId                 int                   `json:"price_list_id"`
 	ExchangeRate                decimal.Decimal       `json:"exchange_rate"`
 	OrderDiscount               decimal.Decimal       `json:"order_discount"`
 	OrderDiscountType           *DiscountType         `json:"order_discount_type"`
@@ -75,6 +78,7 @@ type NewSalesOrder struct {
 	OrderTaxType                *TaxType              `json:"order_tax_type"`
 	CurrentStatus               SalesOrderStatus      `json:"current_status" binding:"required"`
 	WarehouseId                 int                   `json:"warehouse_id"`
+	EstimateId                  int                   `json:"estimate_id"`
 	Documents                   []*NewDocument        `json:"documents"`
 	Details                     []NewSalesOrderDetail `json:"details"`
 }
@@ -89,7 +93,7 @@ type SalesOrderDetail struct {
 	Description          string          `gorm:"size:255" json:"description"`
 	DetailQty            decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_qty" binding:"required"`
 	DetailInvoicedQty    decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_invoiced_qty"`
-	DetailUnitRate       decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_unit_rate" binding:"required"`
+	DetailUnitRate       decimal.Decimal `gorm:"type:decimal(20,8);default:0" json:"detail_unit_rate" binding:"required"`
 	DetailDiscount       decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_discount"`
 	DetailDiscountType   *DiscountType   `gorm:"type:enum('P', 'A');default:null" json:"detail_discount_type"`
 	DetailTaxId          int             `json:"detail_tax_id"`
@@ -98,6 +102,7 @@ type SalesOrderDetail struct {
 	DetailDiscountAmount decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_discount_amount"`
 	DetailTaxAmount      decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_tax_amount"`
 	DetailTotalAmount    decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_total_amount"`
+	LineUnit
 }
 
 type NewSalesOrderDetail struct {
@@ -109,12 +114,14 @@ type NewSalesOrderDetail struct {
 	Description        string          `json:"description"`
 	DetailQty          decimal.Decimal `json:"detail_qty" binding:"required"`
 	DetailUnitRate     decimal.Decimal `json:"detail_unit_rate" binding:"required"`
+	UsePriceListRate   *bool           `json:"use_price_list_rate"`
 	DetailDiscount     decimal.Decimal `json:"detail_discount"`
 	DetailDiscountType *DiscountType   `json:"detail_discount_type"`
 	DetailTaxId        int             `json:"detail_tax_id"`
 	DetailTaxType      *TaxType        `json:"detail_tax_type"`
 	DetailAccountId    int             `json:"detail_account_id"`
 	IsDeletedItem      *bool           `json:"is_deleted_item"`
+	LineUnit
 }
 
 type SalesOrdersConnection struct {
@@ -134,6 +141,50 @@ func (so SalesOrder) InvoiceId(ctx context.Context) (int, error) {
 	return id, err
 }
 
+// UninvoicedAmount is the share of an open order's total not invoiced yet, weighted
+// by each line's amount and uninvoiced quantity.
+func (so SalesOrder) UninvoicedAmount() decimal.Decimal {
+	return so.UninvoicedAmountAfter(nil)
+}
+
+// UninvoicedAmountAfter is UninvoicedAmount once the given invoice lines are billed too.
+// Lines are matched to the order's lines the way UpdateSaleOrderDetailInvoicedQty does.
+func (so SalesOrder) UninvoicedAmountAfter(invoiceDetails []SalesInvoiceDetail) decimal.Decimal {
+	switch so.CurrentStatus {
+	case SalesOrderStatusConfirmed:
+		if len(invoiceDetails) == 0 {
+			return so.OrderTotalAmount
+		}
+	case SalesOrderStatusPartiallyInvoiced:
+	default:
+		return decimal.Zero
+	}
+	linesTotal := decimal.Zero
+	uninvoiced := decimal.Zero
+	for _, detail := range so.Details {
+		linesTotal = linesTotal.Add(detail.DetailTotalAmount)
+		if !detail.DetailQty.IsPositive() {
+			continue
+		}
+		remainingQty := detail.DetailQty.Sub(detail.DetailInvoicedQty)
+		for _, invoiceDetail := range invoiceDetails {
+			if invoiceDetail.SalesOrderItemId == detail.ID ||
+				(invoiceDetail.SalesOrderItemId == 0 && invoiceDetail.ProductId == detail.ProductId &&
+					invoiceDetail.ProductType == detail.ProductType && invoiceDetail.BatchNumber == detail.BatchNumber) {
+				remainingQty = remainingQty.Sub(invoiceDetail.DetailQty)
+			}
+		}
+		if !remainingQty.IsPositive() {
+			continue
+		}
+		uninvoiced = uninvoiced.Add(detail.DetailTotalAmount.Mul(remainingQty).Div(detail.DetailQty))
+	}
+	if !linesTotal.IsPositive() {
+		return decimal.Zero
+	}
+	return so.OrderTotalAmount.Mul(uninvoiced).Div(linesTotal).Round(4)
+}
+
 func (so SalesOrder) GetCursor() string {
 	return so.CreatedAt.String()
 }
@@ -255,6 +306,33 @@ func updateSaleItemDetailTotal(item *SalesOrderDetail, isTaxInclusive bool, orde
 	return orderSubtotal, totalDetailDiscountAmount, totalDetailTaxAmount, totalExclusiveTaxAmount
 }
 
+func (input *NewSalesOrder) applyPriceList(ctx context.Context, businessId string) error {
+	list, err := documentPriceList(ctx, businessId, PriceListTypeSales, &input.PriceListId, input.CustomerId, input.CurrencyId, input.OrderDate)
+	if err != nil {
+		return err
+	}
+	for i := range input.Details {
+		d := &input.Details[i]
+		if err := list.fillRate(ctx, businessId, d.ProductType, d.ProductId, d.UnitId, d.DetailQty, d.UsePriceListRate, &d.DetailUnitRate); err != nil {
+			return fmt.Errorf("%s: %w", d.Name, err)
+		}
+	}
+	return nil
+}
+
+func (input *NewSalesOrder) detailsToBaseUnit(ctx context.Context, businessId string) error {
+	for i := range input.Details {
+		d := &input.Details[i]
+		if d.IsDeletedItem != nil && *d.IsDeletedItem {
+			continue
+		}
+		if err := d.LineUnit.toBaseUnit(ctx, businessId, d.ProductType, d.ProductId, &d.DetailQty, &d.DetailUnitRate); err != nil {
+			return fmt.Errorf("%s: %w", d.Name, err)
+		}
+	}
+	return nil
+}
+
 func CreateSalesOrder(ctx context.Context, input *NewSalesOrder) (*SalesOrder, error) {
 	db := config.GetDB()
 
@@ -267,6 +345,12 @@ func CreateSalesOrder(ctx context.Context, input *NewSalesOrder) (*SalesOrder, e
 	// and then transition Draft -> Confirmed inside the same DB transaction.
 	requestedStatus := input.CurrentStatus
 
+	if err := input.applyPriceList(ctx, businessId); err != nil {
+		return nil, err
+	}
+	if err := input.detailsToBaseUnit(ctx, businessId); err != nil {
+		return nil, err
+	}
 	// validate SalesOrder
 	if err := input.validate(ctx, businessId, 0); err != nil {
 		return nil, err
@@ -294,6 +378,7 @@ func CreateSalesOrder(ctx context.Context, input *NewSalesOrder) (*SalesOrder, e
 			Description:        item.Description,
 			DetailQty:          item.DetailQty,
 			DetailUnitRate:     item.DetailUnitRate,
+			LineUnit:           item.LineUnit,
 			DetailTaxId:        item.DetailTaxId,
 			DetailTaxType:      item.DetailTaxType,
 			DetailDiscount:     item.DetailDiscount,
@@ -362,6 +447,7 @@ func CreateSalesOrder(ctx context.Context, input *NewSalesOrder) (*SalesOrder, e
 		Notes:                       input.Notes,
 		TermsAndConditions:          input.TermsAndConditions,
 		CurrencyId:                  input.CurrencyId,
+		PriceListId:                 input.PriceListId,
 		ExchangeRate:                input.ExchangeRate,
 		OrderDiscount:               input.OrderDiscount,
 		OrderDiscountType:           input.OrderDiscountType,
@@ -380,6 +466,7 @@ func CreateSalesOrder(ctx context.Context, input *NewSalesOrder) (*SalesOrder, e
 		OrderSubtotal:               orderSubtotal,
 		OrderTotalAmount:            orderTotalAmount,
 		WarehouseId:                 input.WarehouseId,
+		EstimateId:                  input.EstimateId,
 	}
 
 	tx := db.Begin()
@@ -401,9 +488,20 @@ func CreateSalesOrder(ctx context.Context, input *NewSalesOrder) (*SalesOrder, e
 		tx.Rollback()
 		return nil, err
 	}
+	if saleOrder.EstimateId > 0 {
+		if err := markEstimateConverted(tx.WithContext(ctx), saleOrder.EstimateId, "sales_order_id", saleOrder.ID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
 
 	// If requested "Confirmed", apply the status transition deterministically (Draft -> Confirmed).
 	if requestedStatus == SalesOrderStatusConfirmed {
+		if err := checkCustomerCredit(ctx, tx, "SalesOrder", "sales_orders", saleOrder.ID, saleOrder.CustomerId,
+			saleOrder.CurrencyId, saleOrder.ExchangeRate, saleOrder.OrderTotalAmount, saleOrder.ID, nil); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
 		if err := tx.WithContext(ctx).Model(&saleOrder).Update("CurrentStatus", SalesOrderStatusConfirmed).Error; err != nil {
 			tx.Rollback()
 			return nil, err
@@ -439,6 +537,12 @@ func UpdateSalesOrder(ctx context.Context, saleOrderId int, updatedOrder *NewSal
 		return nil, errors.New("business id is required")
 	}
 
+	if err := updatedOrder.applyPriceList(ctx, businessId); err != nil {
+		return nil, err
+	}
+	if err := updatedOrder.detailsToBaseUnit(ctx, businessId); err != nil {
+		return nil, err
+	}
 	// validate SalesOrder
 	if err := updatedOrder.validate(ctx, businessId, saleOrderId); err != nil {
 		return nil, err
@@ -470,6 +574,7 @@ func UpdateSalesOrder(ctx context.Context, saleOrderId int, updatedOrder *NewSal
 	existingOrder.Notes = updatedOrder.Notes
 	existingOrder.TermsAndConditions = updatedOrder.TermsAndConditions
 	existingOrder.CurrencyId = updatedOrder.CurrencyId
+	existingOrder.PriceListId = updatedOrder.PriceListId
 	existingOrder.ExchangeRate = updatedOrder.ExchangeRate
 	existingOrder.OrderDiscount = updatedOrder.OrderDiscount
 	existingOrder.OrderDiscountType = updatedOrder.OrderDiscountType
@@ -538,6 +643,7 @@ func UpdateSalesOrder(ctx context.Context, saleOrderId int, updatedOrder *NewSal
 				Description:        updatedItem.Description,
 				DetailQty:          updatedItem.DetailQty,
 				DetailUnitRate:     updatedItem.DetailUnitRate,
+				LineUnit:           updatedItem.LineUnit,
 				DetailTaxId:        updatedItem.DetailTaxId,
 				DetailTaxType:      updatedItem.DetailTaxType,
 				DetailDiscount:     updatedItem.DetailDiscount,
@@ -570,6 +676,7 @@ func UpdateSalesOrder(ctx context.Context, saleOrderId int, updatedOrder *NewSal
 		item.Description = updatedItem.Description
 		item.DetailQty = updatedItem.DetailQty
 		item.DetailUnitRate = updatedItem.DetailUnitRate
+		item.LineUnit = updatedItem.LineUnit
 		item.DetailTaxId = updatedItem.DetailTaxId
 		item.DetailTaxType = updatedItem.DetailTaxType
 		item.DetailDiscount = updatedItem.DetailDiscount
@@ -638,6 +745,14 @@ func UpdateSalesOrder(ctx context.Context, saleOrderId int, updatedOrder *NewSal
 		return nil, err
 	}
 
+	if oldStatus == SalesOrderStatusDraft && existingOrder.CurrentStatus == SalesOrderStatusConfirmed {
+		if err := checkCustomerCredit(ctx, tx, "SalesOrder", "sales_orders", existingOrder.ID, existingOrder.CustomerId,
+			existingOrder.CurrencyId, existingOrder.ExchangeRate, existingOrder.OrderTotalAmount, existingOrder.ID, nil); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
 	if err := existingOrder.AfterUpdateCurrentStatus(tx.WithContext(ctx), string(oldStatus)); err != nil {
 		tx.Rollback()
 		return nil, err
@@ -700,6 +815,12 @@ func DeleteSalesOrder(ctx context.Context, id int) (*SalesOrder, error) {
 		tx.Rollback()
 		return nil, err
 	}
+	if result.EstimateId > 0 {
+		if err := releaseEstimate(tx.WithContext(ctx), result.EstimateId, "sales_order_id", result.ID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
 	if err := deleteDocuments(ctx, tx, result.Documents); err != nil {
 		tx.Rollback()
 		return nil, err
@@ -745,6 +866,14 @@ func UpdateStatusSalesOrder(ctx context.Context, id int, status string) (*SalesO
 		return nil, err
 	}
 
+	if oldStatus == SalesOrderStatusDraft && status == string(SalesOrderStatusConfirmed) {
+		if err := checkCustomerCredit(ctx, tx, "SalesOrder", "sales_orders", so.ID, so.CustomerId,
+			so.CurrencyId, so.ExchangeRate, so.OrderTotalAmount, so.ID, nil); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
 	// Apply committed stock side-effects deterministically (prefer explicit command handler).
 	if config.UseStockCommandsFor("SALES_ORDER") {
 		so.CurrentStatus = SalesOrderStatus(status)
@@ -0,0 +1,638 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// SerialNumber is one unit of a serial tracked product. Its status, warehouse, last
+// supplier and customer and warranty expiry are derived from its movements.
+type SerialNumber struct {
+	ID                 int                `gorm:"primary_key" json:"id"`
+	BusinessId         string             `gorm:"index;not null" json:"business_id" binding:"required"`
+	ProductId          int                `gorm:"index;not null" json:"product_id"`
+	ProductType        ProductType        `gorm:"type:enum('S','G','C','V','I');default:S" json:"product_type"`
+	SerialNo           string             `gorm:"index;size:100;not null" json:"serial_no"`
+	CurrentStatus      SerialNumberStatus `gorm:"type:enum('InStock','InTransit','Sold','Removed');not null" json:"current_status"`
+	WarehouseId        int                `gorm:"index;not null;default:0" json:"warehouse_id"`
+	SupplierId         int                `gorm:"not null;default:0" json:"supplier_id"`
+	CustomerId         int                `gorm:"not null;default:0" json:"customer_id"`
+	WarrantyExpiryDate *time.Time         `json:"warranty_expiry_date"`
+	CreatedAt          time.Time          `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt          time.Time          `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// SerialNumberMovement is a unit moving on a posted document. Status is the state the
+// movement leaves the unit in. Movements apply in MovementDate order; PostedAt, kept
+// when a document is re-posted, orders those of the same date.
+type SerialNumberMovement struct {
+	ID                int                `gorm:"primary_key" json:"id"`
+	BusinessId        string             `gorm:"index;not null" json:"business_id"`
+	SerialNumberId    int                `gorm:"index;not null" json:"serial_number_id"`
+	ProductId         int                `gorm:"not null" json:"product_id"`
+	ProductType       ProductType        `gorm:"type:enum('S','G','C','V','I');default:S" json:"product_type"`
+	SerialNo          string             `gorm:"size:100;not null" json:"serial_no"`
+	ReferenceType     StockReferenceType `gorm:"type:enum('IV','CN','BL','SC','IVAQ','IVAV','TO','POS','PGOS','PCOS','AO','TOR');index:idx_serial_number_movement_reference,priority:1" json:"reference_type"`
+	ReferenceId       int                `gorm:"index:idx_serial_number_movement_reference,priority:2" json:"reference_id"`
+	ReferenceDetailId int                `json:"reference_detail_id"`
+	ReferenceNumber   string             `gorm:"size:255" json:"reference_number"`
+	MovementDate      time.Time          `gorm:"not null" json:"movement_date"`
+	PostedAt          time.Time          `gorm:"not null" json:"posted_at"`
+	WarehouseId       int                `gorm:"not null;default:0" json:"warehouse_id"`
+	Status            SerialNumberStatus `gorm:"type:enum('InStock','InTransit','Sold','Removed');not null" json:"status"`
+	SupplierId        int                `gorm:"not null;default:0" json:"supplier_id"`
+	CustomerId        int                `gorm:"not null;default:0" json:"customer_id"`
+	CreatedAt         time.Time          `gorm:"autoCreateTime" json:"created_at"`
+}
+
+type SerialNumbersConnection struct {
+	Edges    []*SerialNumbersEdge `json:"edges"`
+	PageInfo *PageInfo            `json:"pageInfo"`
+}
+
+type SerialNumbersEdge Edge[SerialNumber]
+
+func (obj SerialNumber) GetId() int {
+	return obj.ID
+}
+
+func (sn SerialNumber) GetCursor() string {
+	return sn.CreatedAt.String()
+}
+
+// serialNumberLine is a document line's units moving to Status in WarehouseId.
+type serialNumberLine struct {
+	DetailId      int
+	ProductId     int
+	ProductType   ProductType
+	Name          string
+	Qty           decimal.Decimal
+	SerialNumbers []string
+	WarehouseId   int
+	Status        SerialNumberStatus
+}
+
+// serialNumberPosting is everything a posted document moves.
+type serialNumberPosting struct {
+	ReferenceType   StockReferenceType
+	ReferenceId     int
+	ReferenceNumber string
+	Date            time.Time
+	SupplierId      int
+	CustomerId      int
+	Lines           []serialNumberLine
+}
+
+// NormalizeSerialNumbers trims serials and drops blank ones, refusing a serial given
+// twice.
+func NormalizeSerialNumbers(serials []string) ([]string, error) {
+	normalized := make([]string, 0, len(serials))
+	for _, s := range serials {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if slices.Contains(normalized, s) {
+			return nil, fmt.Errorf("serial number %s is given more than once", s)
+		}
+		normalized = append(normalized, s)
+	}
+	return normalized, nil
+}
+
+// checkSerialNumberMovement reports whether m can follow prev, the unit's previous
+// movement. Transfers bring units in from transit; other incoming documents need a unit
+// that is not in stock, and outgoing ones a unit in stock in their warehouse.
+func checkSerialNumberMovement(serialNo string, prev, m *SerialNumberMovement) error {
+	fromTransit := m.ReferenceType == StockReferenceTypeTransferOrderReceipt ||
+		(m.ReferenceType == StockReferenceTypeTransferOrder && m.Status == SerialNumberStatusInStock)
+	switch {
+	case fromTransit:
+		if prev == nil || prev.Status != SerialNumberStatusInTransit {
+			return fmt.Errorf("serial number %s is not in transit", serialNo)
+		}
+	case m.Status == SerialNumberStatusInStock:
+		if prev != nil && (prev.Status == SerialNumberStatusInStock || prev.Status == SerialNumberStatusInTransit) {
+			return fmt.Errorf("serial number %s is already in stock", serialNo)
+		}
+	default:
+		if prev == nil || prev.Status != SerialNumberStatusInStock || prev.WarehouseId != m.WarehouseId {
+			return fmt.Errorf("serial number %s is not in stock in this warehouse", serialNo)
+		}
+	}
+	return nil
+}
+
+// Replay derives the unit's state from its movements, in the order they apply. A sale
+// starts warrantyMonths of warranty; the unit coming back in stock ends it.
+func (sn *SerialNumber) Replay(movements []SerialNumberMovement, warrantyMonths int) error {
+	if len(movements) == 0 {
+		return errors.New("serial number has no movements")
+	}
+	sn.SupplierId, sn.CustomerId, sn.WarrantyExpiryDate = 0, 0, nil
+	var prev *SerialNumberMovement
+	for i := range movements {
+		m := &movements[i]
+		if err := checkSerialNumberMovement(sn.SerialNo, prev, m); err != nil {
+			return err
+		}
+		switch m.Status {
+		case SerialNumberStatusInStock:
+			if m.SupplierId > 0 {
+				sn.SupplierId = m.SupplierId
+			}
+			sn.WarrantyExpiryDate = nil
+		case SerialNumberStatusSold:
+			sn.CustomerId = m.CustomerId
+			if warrantyMonths > 0 {
+				expiry := m.MovementDate.AddDate(0, warrantyMonths, 0)
+				sn.WarrantyExpiryDate = &expiry
+			}
+		}
+		prev = m
+	}
+	sn.CurrentStatus = prev.Status
+	sn.WarehouseId = prev.WarehouseId
+	return nil
+}
+
+type serialTracking struct {
+	IsSerialTracking bool
+	WarrantyMonths   int
+}
+
+// productSerialTracking reads whether a product or variant is serial tracked.
+func productSerialTracking(tx *gorm.DB, productId int, productType ProductType) (serialTracking, error) {
+	var tracking serialTracking
+	var model interface{}
+	switch productType {
+	case ProductTypeSingle:
+		model = &Product{}
+	case ProductTypeVariant:
+		model = &ProductVariant{}
+	default:
+		return tracking, nil
+	}
+	err := tx.Model(model).Select("is_serial_tracking", "warranty_months").Where("id = ?", productId).Scan(&tracking).Error
+	return tracking, err
+}
+
+// deleteSerialNumberMovements removes a document's movements, returning the units they
+// moved and when the document was first posted.
+func deleteSerialNumberMovements(tx *gorm.DB, businessId string, referenceType StockReferenceType, referenceId int) ([]int, time.Time, error) {
+	var movements []SerialNumberMovement
+	if err := tx.Where("business_id = ? AND reference_type = ? AND reference_id = ?", businessId, referenceType, referenceId).
+		Find(&movements).Error; err != nil {
+		return nil, time.Time{}, err
+	}
+	var unitIds []int
+	var postedAt time.Time
+	for _, m := range movements {
+		if !slices.Contains(unitIds, m.SerialNumberId) {
+			unitIds = append(unitIds, m.SerialNumberId)
+		}
+		if postedAt.IsZero() || m.PostedAt.Before(postedAt) {
+			postedAt = m.PostedAt
+		}
+	}
+	if len(movements) > 0 {
+		if err := tx.Where("business_id = ? AND reference_type = ? AND reference_id = ?", businessId, referenceType, referenceId).
+			Delete(&SerialNumberMovement{}).Error; err != nil {
+			return nil, time.Time{}, err
+		}
+	}
+	return unitIds, postedAt, nil
+}
+
+// refreshSerialNumbers replays the units' movements into their current state, failing
+// when the history no longer holds together, and drops units left without movements.
+func refreshSerialNumbers(tx *gorm.DB, unitIds []int) error {
+	slices.Sort(unitIds)
+	for _, id := range unitIds {
+		var unit SerialNumber
+		if err := tx.First(&unit, id).Error; err != nil {
+			return err
+		}
+		var movements []SerialNumberMovement
+		if err := tx.Where("serial_number_id = ?", id).Order("movement_date, posted_at, id").Find(&movements).Error; err != nil {
+			return err
+		}
+		if len(movements) == 0 {
+			if err := tx.Delete(&unit).Error; err != nil {
+				return err
+			}
+			continue
+		}
+		tracking, err := productSerialTracking(tx, unit.ProductId, unit.ProductType)
+		if err != nil {
+			return err
+		}
+		if err := unit.Replay(movements, tracking.WarrantyMonths); err != nil {
+			return err
+		}
+		if err := tx.Model(&unit).Updates(map[string]interface{}{
+			"CurrentStatus":      unit.CurrentStatus,
+			"WarehouseId":        unit.WarehouseId,
+			"SupplierId":         unit.SupplierId,
+			"CustomerId":         unit.CustomerId,
+			"WarrantyExpiryDate": unit.WarrantyExpiryDate,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// postSerialNumbers replaces a document's serial movements with the posting's. Every
+// serial tracked line needs exactly one serial per unit.
+func postSerialNumbers(tx *gorm.DB, businessId string, posting serialNumberPosting) error {
+	unitIds, postedAt, err := deleteSerialNumberMovements(tx, businessId, posting.ReferenceType, posting.ReferenceId)
+	if err != nil {
+		return err
+	}
+	if postedAt.IsZero() {
+		postedAt = time.Now()
+	}
+	for _, line := range posting.Lines {
+		if line.ProductId <= 0 {
+			continue
+		}
+		serials, err := NormalizeSerialNumbers(line.SerialNumbers)
+		if err != nil {
+			return err
+		}
+		tracking, err := productSerialTracking(tx, line.ProductId, line.ProductType)
+		if err != nil {
+			return err
+		}
+		if !tracking.IsSerialTracking {
+			if len(serials) > 0 {
+				return fmt.Errorf("%s is not serial tracked", line.Name)
+			}
+			continue
+		}
+		if !line.Qty.Equal(decimal.NewFromInt(int64(len(serials)))) {
+			return fmt.Errorf("%s needs one serial number per unit: %s units, %d serial numbers", line.Name, line.Qty, len(serials))
+		}
+		for _, serialNo := range serials {
+			unit := SerialNumber{
+				BusinessId:    businessId,
+				ProductId:     line.ProductId,
+				ProductType:   line.ProductType,
+				SerialNo:      serialNo,
+				CurrentStatus: line.Status,
+			}
+			if err := tx.Where("business_id = ? AND product_id = ? AND product_type = ? AND serial_no = ?",
+				businessId, line.ProductId, line.ProductType, serialNo).
+				FirstOrCreate(&unit).Error; err != nil {
+				return err
+			}
+			movement := SerialNumberMovement{
+				BusinessId:        businessId,
+				SerialNumberId:    unit.ID,
+				ProductId:         line.ProductId,
+				ProductType:       line.ProductType,
+				SerialNo:          serialNo,
+				ReferenceType:     posting.ReferenceType,
+				ReferenceId:       posting.ReferenceId,
+				ReferenceDetailId: line.DetailId,
+				ReferenceNumber:   posting.ReferenceNumber,
+				MovementDate:      posting.Date,
+				PostedAt:          postedAt,
+				WarehouseId:       line.WarehouseId,
+				Status:            line.Status,
+				SupplierId:        posting.SupplierId,
+				CustomerId:        posting.CustomerId,
+			}
+			if err := tx.Create(&movement).Error; err != nil {
+				return err
+			}
+			if !slices.Contains(unitIds, unit.ID) {
+				unitIds = append(unitIds, unit.ID)
+			}
+		}
+	}
+	return refreshSerialNumbers(tx, unitIds)
+}
+
+// unpostSerialNumbers takes a voided or deleted document's movements back out.
+func unpostSerialNumbers(tx *gorm.DB, businessId string, referenceType StockReferenceType, referenceId int) error {
+	unitIds, _, err := deleteSerialNumberMovements(tx, businessId, referenceType, referenceId)
+	if err != nil {
+		return err
+	}
+	return refreshSerialNumbers(tx, unitIds)
+}
+
+// serialTrackingUpdate resolves a product update's serial tracking flag, keeping the
+// current one when none is given. It cannot change while the product has stock, whose
+// units would be left without serials or with orphaned ones.
+func serialTrackingUpdate(ctx context.Context, businessId string, productId int, productType ProductType, current *bool, requested *bool) (*bool, error) {
+	isTracking := current != nil && *current
+	if requested == nil || *requested == isTracking {
+		return &isTracking, nil
+	}
+	var count int64
+	if err := config.GetDB().WithContext(ctx).Model(&StockSummary{}).
+		Where("business_id = ? AND product_id = ? AND product_type = ? AND current_qty <> 0", businessId, productId, productType).
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("cannot change serial tracking as stock(s) exist")
+	}
+	return requested, nil
+}
+
+func (bill *Bill) serialNumberPosting() serialNumberPosting {
+	posting := serialNumberPosting{
+		ReferenceType:   StockReferenceTypeBill,
+		ReferenceId:     bill.ID,
+		ReferenceNumber: bill.BillNumber,
+		Date:            bill.BillDate,
+		SupplierId:      bill.SupplierId,
+	}
+	for _, d := range bill.Details {
+		posting.Lines = append(posting.Lines, serialNumberLine{
+			DetailId:      d.ID,
+			ProductId:     d.ProductId,
+			ProductType:   d.ProductType,
+			Name:          d.Name,
+			Qty:           d.DetailQty,
+			SerialNumbers: d.SerialNumbers,
+			WarehouseId:   bill.WarehouseId,
+			Status:        SerialNumberStatusInStock,
+		})
+	}
+	return posting
+}
+
+func (si *SalesInvoice) serialNumberPosting() serialNumberPosting {
+	posting := serialNumberPosting{
+		ReferenceType:   StockReferenceTypeInvoice,
+		ReferenceId:     si.ID,
+		ReferenceNumber: si.InvoiceNumber,
+		Date:            si.InvoiceDate,
+		CustomerId:      si.CustomerId,
+	}
+	for _, d := range si.Details {
+		posting.Lines = append(posting.Lines, serialNumberLine{
+			DetailId:      d.ID,
+			ProductId:     d.ProductId,
+			ProductType:   d.ProductType,
+			Name:          d.Name,
+			Qty:           d.DetailQty,
+			SerialNumbers: d.SerialNumbers,
+			WarehouseId:   si.WarehouseId,
+			Status:        SerialNumberStatusSold,
+		})
+	}
+	return posting
+}
+
+func (cn *CreditNote) serialNumberPosting() serialNumberPosting {
+	posting := serialNumberPosting{
+		ReferenceType:   StockReferenceTypeCreditNote,
+		ReferenceId:     cn.ID,
+		ReferenceNumber: cn.CreditNoteNumber,
+		Date:            cn.CreditNoteDate,
+		CustomerId:      cn.CustomerId,
+	}
+	for _, d := range cn.Details {
+		posting.Lines = append(posting.Lines, serialNumberLine{
+			DetailId:      d.ID,
+			ProductId:     d.ProductId,
+			ProductType:   d.ProductType,
+			Name:          d.Name,
+			Qty:           d.DetailQty,
+			SerialNumbers: d.SerialNumbers,
+			WarehouseId:   cn.WarehouseId,
+			Status:        SerialNumberStatusInStock,
+		})
+	}
+	return posting
+}
+
+// serialNumberPosting takes the units returned to the supplier out of stock.
+func (sc *SupplierCredit) serialNumberPosting() serialNumberPosting {
+	posting := serialNumberPosting{
+		ReferenceType:   StockReferenceTypeSupplierCredit,
+		ReferenceId:     sc.ID,
+		ReferenceNumber: sc.SupplierCreditNumber,
+		Date:            sc.SupplierCreditDate,
+		SupplierId:      sc.SupplierId,
+	}
+	for _, d := range sc.Details {
+		posting.Lines = append(posting.Lines, serialNumberLine{
+			DetailId:      d.ID,
+			ProductId:     d.ProductId,
+			ProductType:   d.ProductType,
+			Name:          d.Name,
+			Qty:           d.DetailQty,
+			SerialNumbers: d.SerialNumbers,
+			WarehouseId:   sc.WarehouseId,
+			Status:        SerialNumberStatusRemoved,
+		})
+	}
+	return posting
+}
+
+// serialNumberPosting moves positive adjustments' units into stock and removes those of
+// negative ones.
+func (invAdj *InventoryAdjustment) serialNumberPosting() serialNumberPosting {
+	posting := serialNumberPosting{
+		ReferenceType:   StockReferenceTypeInventoryAdjustmentQuantity,
+		ReferenceId:     invAdj.ID,
+		ReferenceNumber: invAdj.ReferenceNumber,
+		Date:            invAdj.AdjustmentDate,
+	}
+	for _, d := range invAdj.Details {
+		status := SerialNumberStatusInStock
+		if d.AdjustedValue.IsNegative() {
+			status = SerialNumberStatusRemoved
+		}
+		posting.Lines = append(posting.Lines, serialNumberLine{
+			DetailId:      d.ID,
+			ProductId:     d.ProductId,
+			ProductType:   d.ProductType,
+			Name:          d.Name,
+			Qty:           d.AdjustedValue.Abs(),
+			SerialNumbers: d.SerialNumbers,
+			WarehouseId:   invAdj.WarehouseId,
+			Status:        status,
+		})
+	}
+	return posting
+}
+
+// serialNumberPosting puts the units in transit out of the source warehouse. A transfer
+// that is not received separately brings them straight into the destination.
+func (to *TransferOrder) serialNumberPosting() serialNumberPosting {
+	posting := serialNumberPosting{
+		ReferenceType:   StockReferenceTypeTransferOrder,
+		ReferenceId:     to.ID,
+		ReferenceNumber: to.OrderNumber,
+		Date:            to.TransferDate,
+	}
+	for _, d := range to.Details {
+		line := serialNumberLine{
+			DetailId:      d.ID,
+			ProductId:     d.ProductId,
+			ProductType:   d.ProductType,
+			Name:          d.Name,
+			Qty:           d.TransferQty,
+			SerialNumbers: d.SerialNumbers,
+			WarehouseId:   to.SourceWarehouseId,
+			Status:        SerialNumberStatusInTransit,
+		}
+		posting.Lines = append(posting.Lines, line)
+		if !to.IsInTransitTransfer() {
+			line.WarehouseId = to.DestinationWarehouseId
+			line.Status = SerialNumberStatusInStock
+			posting.Lines = append(posting.Lines, line)
+		}
+	}
+	return posting
+}
+
+// serialNumberPosting brings received units into the destination warehouse and removes
+// the short and damaged ones.
+func (receipt *TransferOrderReceipt) serialNumberPosting(transferOrder *TransferOrder) serialNumberPosting {
+	posting := serialNumberPosting{
+		ReferenceType:   StockReferenceTypeTransferOrderReceipt,
+		ReferenceId:     receipt.ID,
+		ReferenceNumber: receipt.ReceiptNumber,
+		Date:            receipt.ReceiptDate,
+	}
+	for _, d := range receipt.Details {
+		posting.Lines = append(posting.Lines, serialNumberLine{
+			DetailId:      d.ID,
+			ProductId:     d.ProductId,
+			ProductType:   d.ProductType,
+			Name:          d.Name,
+			Qty:           d.ReceivedQty,
+			SerialNumbers: d.SerialNumbers,
+			WarehouseId:   transferOrder.DestinationWarehouseId,
+			Status:        SerialNumberStatusInStock,
+		}, serialNumberLine{
+			DetailId:      d.ID,
+			ProductId:     d.ProductId,
+			ProductType:   d.ProductType,
+			Name:          d.Name,
+			Qty:           d.ShortQty.Add(d.DamagedQty),
+			SerialNumbers: d.WriteOffSerialNumbers,
+			WarehouseId:   transferOrder.DestinationWarehouseId,
+			Status:        SerialNumberStatusRemoved,
+		})
+	}
+	return posting
+}
+
+// validateReceivedSerialNumbers checks that a receipt line only names units shipped on
+// the transfer line.
+func (d TransferOrderDetail) validateReceivedSerialNumbers(received, writtenOff []string) error {
+	shipped := make(map[string]bool, len(d.SerialNumbers))
+	for _, serialNo := range d.SerialNumbers {
+		shipped[serialNo] = true
+	}
+	for _, serialNo := range append(append([]string(nil), received...), writtenOff...) {
+		if !shipped[strings.TrimSpace(serialNo)] {
+			return fmt.Errorf("serial number %s was not shipped on this transfer line", serialNo)
+		}
+	}
+	return nil
+}
+
+func GetSerialNumber(ctx context.Context, id int) (*SerialNumber, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	return utils.FetchModel[SerialNumber](ctx, businessId, id)
+}
+
+// GetSerialNumberHistory lists the movements of the units carrying serialNo, oldest
+// first: which bill received them from which supplier, which invoice sold them to which
+// customer and which credit note brought them back.
+func GetSerialNumberHistory(ctx context.Context, serialNo string, productId *int, productType *ProductType) ([]*SerialNumberMovement, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	serialNo = strings.TrimSpace(serialNo)
+	if serialNo == "" {
+		return nil, errors.New("serial number is required")
+	}
+
+	db := config.GetDB()
+	dbCtx := db.WithContext(ctx).Where("business_id = ? AND serial_no = ?", businessId, serialNo)
+	if productId != nil && *productId > 0 {
+		dbCtx.Where("product_id = ?", *productId)
+	}
+	if productType != nil {
+		dbCtx.Where("product_type = ?", *productType)
+	}
+
+	var results []*SerialNumberMovement
+	if err := dbCtx.Order("serial_number_id, movement_date, posted_at, id").Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func PaginateSerialNumber(
+	ctx context.Context, limit *int, after *string,
+	serialNo *string,
+	productId *int,
+	productType *ProductType,
+	warehouseId *int,
+	currentStatus *SerialNumberStatus,
+) (*SerialNumbersConnection, error) {
+
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	db := config.GetDB()
+	dbCtx := db.WithContext(ctx).Where("business_id = ?", businessId)
+
+	if serialNo != nil && *serialNo != "" {
+		dbCtx.Where("serial_no LIKE ?", "%"+*serialNo+"%")
+	}
+	if productId != nil && *productId > 0 {
+		dbCtx.Where("product_id = ?", *productId)
+	}
+	if productType != nil {
+		dbCtx.Where("product_type = ?", *productType)
+	}
+	if warehouseId != nil && *warehouseId > 0 {
+		dbCtx.Where("warehouse_id = ?", *warehouseId)
+	}
+	if currentStatus != nil {
+		dbCtx.Where("current_status = ?", *currentStatus)
+	}
+
+	edges, pageInfo, err := FetchPageCompositeCursor[SerialNumber](dbCtx, *limit, after, "created_at", "<")
+	if err != nil {
+		return nil, err
+	}
+	var serialNumbersConnection SerialNumbersConnection
+	serialNumbersConnection.PageInfo = pageInfo
+	for _, edge := range edges {
+		serialNumbersEdge := SerialNumbersEdge(edge)
+		serialNumbersConnection.Edges = append(serialNumbersConnection.Edges, &serialNumbersEdge)
+	}
+
+	return &serialNumbersConnection, err
+}
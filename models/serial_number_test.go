@@ -0,0 +1,63 @@
+package models_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/models"
+)
+
+func TestNormalizeSerialNumbers(t *testing.T) {
+	got, err := models.NormalizeSerialNumbers([]string{" SN-1", "", "SN-2 ", "  "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, ",") != "SN-1,SN-2" {
+		t.Fatalf("normalized to %q", got)
+	}
+
+	if _, err := models.NormalizeSerialNumbers([]string{"SN-1", " SN-1"}); err == nil {
+		t.Fatal("a serial number given twice was accepted")
+	}
+}
+
+func TestSerialNumberReplay(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC) }
+	received := models.SerialNumberMovement{ReferenceType: models.StockReferenceTypeBill, MovementDate: day(1), WarehouseId: 1, Status: models.SerialNumberStatusInStock, SupplierId: 7}
+	shipped := models.SerialNumberMovement{ReferenceType: models.StockReferenceTypeTransferOrder, MovementDate: day(2), WarehouseId: 1, Status: models.SerialNumberStatusInTransit}
+	arrived := models.SerialNumberMovement{ReferenceType: models.StockReferenceTypeTransferOrderReceipt, MovementDate: day(3), WarehouseId: 2, Status: models.SerialNumberStatusInStock}
+	sold := models.SerialNumberMovement{ReferenceType: models.StockReferenceTypeInvoice, MovementDate: day(4), WarehouseId: 2, Status: models.SerialNumberStatusSold, CustomerId: 9}
+	returned := models.SerialNumberMovement{ReferenceType: models.StockReferenceTypeCreditNote, MovementDate: day(5), WarehouseId: 2, Status: models.SerialNumberStatusInStock, CustomerId: 9}
+
+	sn := models.SerialNumber{SerialNo: "SN-1"}
+	if err := sn.Replay([]models.SerialNumberMovement{received, shipped, arrived, sold}, 12); err != nil {
+		t.Fatal(err)
+	}
+	if sn.CurrentStatus != models.SerialNumberStatusSold || sn.WarehouseId != 2 || sn.SupplierId != 7 || sn.CustomerId != 9 {
+		t.Fatalf("sold unit replayed to %+v", sn)
+	}
+	if sn.WarrantyExpiryDate == nil || !sn.WarrantyExpiryDate.Equal(time.Date(2027, 1, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("warranty expires %v", sn.WarrantyExpiryDate)
+	}
+
+	if err := sn.Replay([]models.SerialNumberMovement{received, shipped, arrived, sold, returned}, 12); err != nil {
+		t.Fatal(err)
+	}
+	if sn.CurrentStatus != models.SerialNumberStatusInStock || sn.WarrantyExpiryDate != nil {
+		t.Fatalf("returned unit replayed to %+v", sn)
+	}
+
+	for name, movements := range map[string][]models.SerialNumberMovement{
+		"sold before it was received": {sold},
+		"sold from another warehouse": {received, sold},
+		"received twice":              {received, received},
+		"received without shipping":   {received, arrived},
+		"sold while in transit":       {received, shipped, sold},
+		"no movements":                nil,
+	} {
+		if err := (&models.SerialNumber{SerialNo: "SN-1"}).Replay(movements, 0); err == nil {
+			t.Errorf("%s: replay succeeded", name)
+		}
+	}
+}
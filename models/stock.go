@@ -20,9 +20,9 @@ type StockHistory struct {
 	Qty               decimal.Decimal    `gorm:"type:decimal(20,4);default:0" json:"qty"`
 	ClosingQty        decimal.Decimal    `gorm:"type:decimal(20,4);default:0" json:"closing_qty"`
 	Description       string             `gorm:"index;size:100;not null" json:"description"`
-	BaseUnitValue     decimal.Decimal    `gorm:"type:decimal(20,4);default:0" json:"base_unit_value"`
+	BaseUnitValue     decimal.Decimal    `gorm:"type:decimal(20,8);default:0" json:"base_unit_value"`
 	ClosingAssetValue decimal.Decimal    `gorm:"type:decimal(20,4);default:0" json:"closing_asset_value"`
-	ReferenceType     StockReferenceType `gorm:"type:enum('IV','CN','BL','SC','IVAQ','IVAV','TO','POS','PGOS','PCOS')" json:"reference_type"`
+	ReferenceType     StockReferenceType `gorm:"type:enum('IV','CN','BL','SC','IVAQ','IVAV','TO','POS','PGOS','PCOS','AO','TOR')" json:"reference_type"`
 	ReferenceID       int                `json:"reference_id"`
 	ReferenceDetailID int                `json:"reference_detail_id"`
 	IsOutgoing        *bool              `gorm:"not null;default:false" json:"is_outgoing"`
@@ -10,6 +10,7 @@ import (
 // ApplyTransferOrderStockForStatusTransition applies stock changes for a TransferOrder status transition.
 //
 // Draft -> Confirmed : move stock from source to destination
+// Draft -> InTransit : move stock out of source; receipts bring it into destination
 func ApplyTransferOrderStockForStatusTransition(tx *gorm.DB, to *TransferOrder, oldStatus TransferOrderStatus) error {
 	if tx == nil {
 		return fmt.Errorf("tx is nil")
@@ -21,7 +22,7 @@ func ApplyTransferOrderStockForStatusTransition(tx *gorm.DB, to *TransferOrder,
 		return nil
 	}
 
-	apply := oldStatus == TransferOrderStatusDraft && to.CurrentStatus == TransferOrderStatusConfirmed
+	apply := oldStatus == TransferOrderStatusDraft && (to.CurrentStatus == TransferOrderStatusConfirmed || to.CurrentStatus == TransferOrderStatusInTransit)
 	if !apply {
 		return nil
 	}
@@ -49,6 +50,9 @@ func ApplyTransferOrderStockForStatusTransition(tx *gorm.DB, to *TransferOrder,
 			tx.Rollback()
 			return err
 		}
+		if to.CurrentStatus == TransferOrderStatusInTransit {
+			continue
+		}
 		if err := UpdateStockSummaryTransferQtyIn(tx, to.BusinessId, to.DestinationWarehouseId, item.ProductId, string(item.ProductType), item.BatchNumber, item.TransferQty, to.TransferDate); err != nil {
 			tx.Rollback()
 			return err
@@ -2,6 +2,7 @@ package models
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"time"
 
@@ -206,6 +207,10 @@ func UpdateStockSummarySaleQty(tx *gorm.DB, businessId string, warehouseId int,
 			tx.Rollback()
 			return err
 		}
+		if err := queueStockBelowZero(tx, stockSummary, quantity.Neg()); err != nil {
+			tx.Rollback()
+			return err
+		}
 		ProcessStockIntegration(tx, businessId, productType, productId)
 	}
 
@@ -258,6 +263,10 @@ func UpdateStockSummaryAdjustedQtyOut(tx *gorm.DB, businessId string, warehouseI
 			tx.Rollback()
 			return err
 		}
+		if err := queueStockBelowZero(tx, stockSummary, quantity); err != nil {
+			tx.Rollback()
+			return err
+		}
 		ProcessStockIntegration(tx, businessId, productType, productId)
 	}
 
@@ -310,6 +319,10 @@ func UpdateStockSummaryTransferQtyOut(tx *gorm.DB, businessId string, warehouseI
 			tx.Rollback()
 			return err
 		}
+		if err := queueStockBelowZero(tx, stockSummary, quantity); err != nil {
+			tx.Rollback()
+			return err
+		}
 		ProcessStockIntegration(tx, businessId, productType, productId)
 	}
 
@@ -424,6 +437,28 @@ func GetStockInHand(ctx context.Context, productId int, productType string) (dec
 	return total, nil
 }
 
+// queueStockBelowZero raises stock.below_zero when change takes the locked stock summary
+// from zero or above to below zero; further decreases while negative raise nothing.
+func queueStockBelowZero(tx *gorm.DB, stockSummary *StockSummary, change decimal.Decimal) error {
+	currentQty := stockSummary.CurrentQty.Add(change)
+	if stockSummary.CurrentQty.IsNegative() || !currentQty.IsNegative() {
+		return nil
+	}
+	data := map[string]interface{}{
+		"warehouse_id": stockSummary.WarehouseId,
+		"product_id":   stockSummary.ProductId,
+		"product_type": stockSummary.ProductType,
+		"batch_number": stockSummary.BatchNumber,
+		"previous_qty": stockSummary.CurrentQty,
+		"current_qty":  currentQty,
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return QueueWebhookEvent(tx.Statement.Context, tx, stockSummary.BusinessId, WebhookEventStockBelowZero, "Product", stockSummary.ProductId, raw)
+}
+
 func ProcessStockIntegration(tx *gorm.DB, businessId, productType string, productId int) error {
 	if productType == "S" {
 		ctx := tx.Statement.Context
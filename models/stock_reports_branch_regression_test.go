@@ -0,0 +1,148 @@
+package models_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/mmdatafocus/books_backend/models/reports"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+)
+
+// Regression: the stock summary and low stock reads are raw sql, which the branch plugin does
+// not scope, so a restricted user got every branch's warehouses and could ask for any of them.
+func TestStockReports_LimitedToPermittedBranches(t *testing.T) {
+	if strings.TrimSpace(os.Getenv("INTEGRATION_TESTS")) == "" {
+		t.Skip("set INTEGRATION_TESTS=1 to run integration tests (requires docker)")
+	}
+
+	ctx := context.Background()
+
+	redisName, redisPort := startRedisContainer(t)
+	t.Cleanup(func() { _ = dockerRmForce(redisName) })
+
+	mysqlName, mysqlPort := startMySQLContainer(t)
+	t.Cleanup(func() { _ = dockerRmForce(mysqlName) })
+
+	t.Setenv("REDIS_ADDRESS", fmt.Sprintf("127.0.0.1:%s", redisPort))
+	t.Setenv("DB_USER", "root")
+	t.Setenv("DB_PASSWORD", "testpw")
+	t.Setenv("DB_HOST", "127.0.0.1")
+	t.Setenv("DB_PORT", mysqlPort)
+	t.Setenv("DB_NAME_2", "pitibooks_test")
+	t.Setenv("STOCK_COMMANDS_DOCS", "")
+
+	config.ConnectDatabaseWithRetry()
+	config.ConnectRedisWithRetry()
+	models.MigrateTable()
+
+	ctx = utils.SetUserIdInContext(ctx, 1)
+	ctx = utils.SetUserNameInContext(ctx, "Test")
+	ctx = utils.SetUsernameInContext(ctx, "test@local")
+
+	biz, err := models.CreateBusiness(ctx, &models.NewBusiness{
+		Name:  "Test Biz",
+		Email: "owner@test.local",
+	})
+	if err != nil {
+		t.Fatalf("CreateBusiness: %v", err)
+	}
+	businessID := biz.ID.String()
+	ctx = utils.SetBusinessIdInContext(ctx, businessID)
+
+	db := config.GetDB()
+	var primary models.Warehouse
+	if err := db.WithContext(ctx).Where("business_id = ? AND name = ?", businessID, "Primary Warehouse").First(&primary).Error; err != nil {
+		t.Fatalf("fetch primary warehouse: %v", err)
+	}
+	primaryBranch, err := models.GetBranch(ctx, biz.PrimaryBranchId)
+	if err != nil {
+		t.Fatalf("GetBranch: %v", err)
+	}
+	otherBranch, err := models.CreateBranch(ctx, &models.NewBranch{
+		TransactionNumberSeriesId: primaryBranch.TransactionNumberSeriesId,
+		Name:                      "Other Branch",
+	})
+	if err != nil {
+		t.Fatalf("CreateBranch: %v", err)
+	}
+	other, err := models.CreateWarehouse(ctx, &models.NewWarehouse{
+		BranchId: otherBranch.ID,
+		Name:     "Other Warehouse",
+	})
+	if err != nil {
+		t.Fatalf("CreateWarehouse: %v", err)
+	}
+
+	unit, err := models.CreateProductUnit(ctx, &models.NewProductUnit{Name: "Pcs", Abbreviation: "pc", Precision: models.PrecisionZero})
+	if err != nil {
+		t.Fatalf("CreateProductUnit: %v", err)
+	}
+	sysAccounts, err := models.GetSystemAccounts(businessID)
+	if err != nil {
+		t.Fatalf("GetSystemAccounts: %v", err)
+	}
+	product, err := models.CreateProduct(ctx, &models.NewProduct{
+		Name:               "Widget",
+		Sku:                "WIDGET-001",
+		UnitId:             unit.ID,
+		SalesAccountId:     sysAccounts[models.AccountCodeSales],
+		PurchaseAccountId:  sysAccounts[models.AccountCodeCostOfGoodsSold],
+		InventoryAccountId: sysAccounts[models.AccountCodeInventoryAsset],
+		IsBatchTracking:    utils.NewFalse(),
+	})
+	if err != nil {
+		t.Fatalf("CreateProduct: %v", err)
+	}
+	// both warehouses are out of stock below their reorder level
+	if _, err := models.SetReorderPoints(ctx, models.ProductTypeSingle, product.ID, []*models.NewReorderPoint{
+		{WarehouseId: primary.ID, ReorderLevel: decimal.NewFromInt(5), ReorderQty: decimal.NewFromInt(10)},
+		{WarehouseId: other.ID, ReorderLevel: decimal.NewFromInt(5), ReorderQty: decimal.NewFromInt(10)},
+	}); err != nil {
+		t.Fatalf("SetReorderPoints: %v", err)
+	}
+
+	restrictedCtx := utils.SetBranchIdsInContext(ctx, []int{primaryBranch.ID})
+
+	ids, err := models.NarrowReportWarehouse(restrictedCtx, nil)
+	if err != nil || len(ids) != 1 || ids[0] != primary.ID {
+		t.Fatalf("NarrowReportWarehouse(all) = %v, %v; want [%d]", ids, err, primary.ID)
+	}
+	if ids, err := models.NarrowReportWarehouse(ctx, nil); err != nil || ids != nil {
+		t.Fatalf("unrestricted NarrowReportWarehouse(all) = %v, %v; want every warehouse", ids, err)
+	}
+
+	lines, err := models.GetLowStockLedger(restrictedCtx, nil, nil)
+	if err != nil {
+		t.Fatalf("GetLowStockLedger: %v", err)
+	}
+	if len(lines) != 1 || lines[0].WarehouseId != primary.ID {
+		t.Errorf("restricted low stock lines = %+v, want only the primary warehouse", lines)
+	}
+	if lines, err := models.GetLowStockLedger(ctx, nil, nil); err != nil || len(lines) != 2 {
+		t.Errorf("owner low stock lines = %d, %v; want both warehouses", len(lines), err)
+	}
+
+	otherId := other.ID
+	if _, err := models.GetLowStockLedger(restrictedCtx, &otherId, nil); !errors.Is(err, config.ErrBranchNotPermitted) {
+		t.Errorf("low stock of another branch's warehouse err = %v, want %v", err, config.ErrBranchNotPermitted)
+	}
+	if _, err := models.GeneratePurchaseOrders(restrictedCtx, &otherId, nil); !errors.Is(err, config.ErrBranchNotPermitted) {
+		t.Errorf("purchase orders for another branch's warehouse err = %v, want %v", err, config.ErrBranchNotPermitted)
+	}
+
+	today := models.MyDateString(time.Now())
+	if _, err := reports.GetStockSummaryReport(restrictedCtx, today, today, &otherId); !errors.Is(err, config.ErrBranchNotPermitted) {
+		t.Errorf("stock summary of another branch's warehouse err = %v, want %v", err, config.ErrBranchNotPermitted)
+	}
+	if _, err := reports.GetStockSummaryReport(restrictedCtx, today, today, nil); err != nil {
+		t.Errorf("restricted stock summary of every permitted warehouse: %v", err)
+	}
+}
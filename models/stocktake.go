@@ -0,0 +1,810 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type AbcClass string
+
+const (
+	AbcClassA AbcClass = "A"
+	AbcClassB AbcClass = "B"
+	AbcClassC AbcClass = "C"
+)
+
+func (c AbcClass) IsValid() bool {
+	switch c {
+	case AbcClassA, AbcClassB, AbcClassC:
+		return true
+	}
+	return false
+}
+
+// Stocktake is a physical count of a warehouse against the stock on hand frozen at
+// SnapshotDate. Counts add up per line until a recount starts the line over; approving the
+// stocktake posts the variances as a quantity adjustment to AccountId with ReasonId. A
+// stocktake with an AbcClass is a cycle count of that class only. Serial tracked products
+// are left out, since a count cannot tell which of their units are missing or found.
+type Stocktake struct {
+	ID                    int             `gorm:"primary_key" json:"id"`
+	BusinessId            string          `gorm:"index;not null" json:"business_id" binding:"required"`
+	StocktakeNumber       string          `gorm:"size:255;not null" json:"stocktake_number"`
+	WarehouseId           int             `gorm:"index;not null" json:"warehouse_id" binding:"required"`
+	BranchId              int             `gorm:"not null" json:"branch_id"`
+	SnapshotDate          time.Time       `gorm:"not null" json:"snapshot_date" binding:"required"`
+	AbcClass              *AbcClass       `gorm:"size:1;default:null" json:"abc_class"`
+	AccountId             int             `gorm:"not null" json:"account_id" binding:"required"`
+	ReasonId              int             `gorm:"not null" json:"reason_id" binding:"required"`
+	Notes                 string          `gorm:"type:text;default:null" json:"notes"`
+	CurrentStatus         StocktakeStatus `gorm:"type:enum('Counting', 'Approved');not null" json:"current_status"`
+	InventoryAdjustmentId int             `gorm:"default:0" json:"inventory_adjustment_id"`
+	Lines                 []StocktakeLine `gorm:"foreignKey:StocktakeId" json:"lines"`
+	CreatedBy             int             `gorm:"not null" json:"created_by"`
+	ApprovedBy            int             `gorm:"default:0" json:"approved_by"`
+	CreatedAt             time.Time       `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt             time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// StocktakeLine is one product of a stocktake. ExpectedQty and UnitCost are frozen from
+// the ledger; lines found during the count that the snapshot did not have expect nothing
+// and are valued at the product's purchase price.
+type StocktakeLine struct {
+	ID          int             `gorm:"primary_key" json:"id"`
+	StocktakeId int             `gorm:"index;not null" json:"stocktake_id"`
+	ProductId   int             `gorm:"not null" json:"product_id"`
+	ProductType ProductType     `gorm:"type:enum('S','V');default:S;not null" json:"product_type"`
+	Name        string          `gorm:"size:100" json:"name"`
+	ExpectedQty decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"expected_qty"`
+	UnitCost    decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"unit_cost"`
+	CountedQty  decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"counted_qty"`
+	IsCounted   bool            `gorm:"not null;default:false" json:"is_counted"`
+	CountRound  int             `gorm:"not null;default:1" json:"count_round"`
+	CreatedAt   time.Time       `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// StocktakeCount is one submitted count of a line, kept per counter and count round.
+type StocktakeCount struct {
+	ID              int             `gorm:"primary_key" json:"id"`
+	StocktakeId     int             `gorm:"index;not null" json:"stocktake_id"`
+	StocktakeLineId int             `gorm:"index;not null" json:"stocktake_line_id"`
+	CountRound      int             `gorm:"not null" json:"count_round"`
+	Qty             decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"qty"`
+	Barcode         string          `gorm:"size:100;default:null" json:"barcode"`
+	CountedBy       int             `gorm:"not null" json:"counted_by"`
+	CreatedAt       time.Time       `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// NewStocktake limits a cycle count to AbcClass, and to the Limit least recently counted
+// products of it when Limit is set.
+type NewStocktake struct {
+	StocktakeNumber string    `json:"stocktake_number"`
+	WarehouseId     int       `json:"warehouse_id"`
+	SnapshotDate    time.Time `json:"snapshot_date"`
+	AbcClass        *AbcClass `json:"abc_class"`
+	Limit           *int      `json:"limit"`
+	AccountId       int       `json:"account_id"`
+	ReasonId        int       `json:"reason_id"`
+	Notes           string    `json:"notes"`
+}
+
+// NewStocktakeCount identifies the product either by ProductId and ProductType or by a
+// scanned Barcode; a unit barcode counts Qty of that unit.
+type NewStocktakeCount struct {
+	ProductId   int             `json:"product_id"`
+	ProductType ProductType     `json:"product_type"`
+	Barcode     string          `json:"barcode"`
+	Qty         decimal.Decimal `json:"qty"`
+}
+
+// AbcClassificationResponse ranks a product of a warehouse by its share of the stock value.
+type AbcClassificationResponse struct {
+	ProductId       int             `json:"product_id"`
+	ProductType     ProductType     `json:"product_type"`
+	Name            string          `json:"name"`
+	StockOnHand     decimal.Decimal `json:"stock_on_hand"`
+	AssetValue      decimal.Decimal `json:"asset_value"`
+	AbcClass        AbcClass        `json:"abc_class"`
+	LastCountedDate *time.Time      `json:"last_counted_date"`
+}
+
+type StocktakesConnection struct {
+	Edges    []*StocktakesEdge `json:"edges"`
+	PageInfo *PageInfo         `json:"pageInfo"`
+}
+
+type StocktakesEdge Edge[Stocktake]
+
+func (obj Stocktake) GetId() int {
+	return obj.ID
+}
+
+func (s Stocktake) GetCursor() string {
+	return s.CreatedAt.String()
+}
+
+// VarianceQty is counted less expected stock; lines not counted yet have none.
+func (l StocktakeLine) VarianceQty() decimal.Decimal {
+	if !l.IsCounted {
+		return decimal.Zero
+	}
+	return l.CountedQty.Sub(l.ExpectedQty)
+}
+
+// VarianceValue is the value the adjustment will post for the line.
+func (l StocktakeLine) VarianceValue() decimal.Decimal {
+	return l.VarianceQty().Mul(l.UnitCost).Round(4)
+}
+
+// ClassifyAbc ranks stock by value: the products making up the first 80% of the total value
+// are class A, the next 15% class B and the rest class C. Stock without a positive value is
+// class C. The classes are returned in the order of rows.
+func ClassifyAbc(rows []InventorySnapshot) []AbcClass {
+	order := make([]int, len(rows))
+	total := decimal.Zero
+	for i, r := range rows {
+		order[i] = i
+		if r.AssetValue.IsPositive() {
+			total = total.Add(r.AssetValue)
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return rows[order[i]].AssetValue.GreaterThan(rows[order[j]].AssetValue)
+	})
+
+	classes := make([]AbcClass, len(rows))
+	cumulative := decimal.Zero
+	for _, i := range order {
+		value := rows[i].AssetValue
+		if !value.IsPositive() {
+			classes[i] = AbcClassC
+			continue
+		}
+		// a product is placed by where its value starts, so the most valuable one is always A
+		share := cumulative.Div(total)
+		cumulative = cumulative.Add(value)
+		switch {
+		case share.LessThan(decimal.NewFromFloat(0.8)):
+			classes[i] = AbcClassA
+		case share.LessThan(decimal.NewFromFloat(0.95)):
+			classes[i] = AbcClassB
+		default:
+			classes[i] = AbcClassC
+		}
+	}
+	return classes
+}
+
+// names of products and variants, keyed like snapshots
+const stocktakeProductNamesSql = `
+SELECT id AS product_id, 'S' AS product_type, name FROM products WHERE business_id = @businessId
+UNION ALL
+SELECT id AS product_id, 'V' AS product_type, name FROM product_variants WHERE business_id = @businessId
+`
+
+func stocktakeProductNames(ctx context.Context, businessId string) (map[string]string, error) {
+	var rows []struct {
+		ProductId   int
+		ProductType ProductType
+		Name        string
+	}
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Raw(stocktakeProductNamesSql, map[string]interface{}{
+		"businessId": businessId,
+	}).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(rows))
+	for _, r := range rows {
+		names[snapshotKey(r.ProductId, r.ProductType)] = r.Name
+	}
+	return names, nil
+}
+
+// serial tracked products and variants, keyed like snapshots
+const stocktakeSerialTrackedSql = `
+SELECT id AS product_id, 'S' AS product_type FROM products WHERE business_id = @businessId AND is_serial_tracking = true
+UNION ALL
+SELECT id AS product_id, 'V' AS product_type FROM product_variants WHERE business_id = @businessId AND is_serial_tracking = true
+`
+
+func stocktakeSerialTracked(ctx context.Context, businessId string) (map[string]bool, error) {
+	var rows []struct {
+		ProductId   int
+		ProductType ProductType
+	}
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Raw(stocktakeSerialTrackedSql, map[string]interface{}{
+		"businessId": businessId,
+	}).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	tracked := make(map[string]bool, len(rows))
+	for _, r := range rows {
+		tracked[snapshotKey(r.ProductId, r.ProductType)] = true
+	}
+	return tracked, nil
+}
+
+// errStocktakeSerialTracked is returned for counts of a serial tracked product, whose
+// units have to be adjusted by serial number instead.
+func errStocktakeSerialTracked(name string) error {
+	return fmt.Errorf("%s is serial tracked and cannot be counted on a stocktake, adjust it with an inventory adjustment listing its serial numbers", name)
+}
+
+// stocktakeLastCounted returns when each product of the warehouse was last counted by an
+// approved stocktake.
+func stocktakeLastCounted(ctx context.Context, businessId string, warehouseId int) (map[string]time.Time, error) {
+	var rows []struct {
+		ProductId   int
+		ProductType ProductType
+		CountedDate time.Time
+	}
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Raw(`
+		SELECT l.product_id, l.product_type, MAX(s.snapshot_date) AS counted_date
+		FROM stocktake_lines l
+		JOIN stocktakes s ON s.id = l.stocktake_id
+		WHERE s.business_id = ? AND s.warehouse_id = ? AND s.current_status = ? AND l.is_counted = true
+		GROUP BY l.product_id, l.product_type
+	`, businessId, warehouseId, StocktakeStatusApproved).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	counted := make(map[string]time.Time, len(rows))
+	for _, r := range rows {
+		counted[snapshotKey(r.ProductId, r.ProductType)] = r.CountedDate
+	}
+	return counted, nil
+}
+
+// GetAbcClassification classifies the stock of a warehouse for cycle counting, most
+// valuable first.
+func GetAbcClassification(ctx context.Context, warehouseId int) ([]*AbcClassificationResponse, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if err := utils.ValidateResourceId[Warehouse](ctx, businessId, warehouseId); err != nil {
+		return nil, errors.New("warehouse not found")
+	}
+
+	rows, err := computeLedgerSnapshots(ctx, time.Now(), &warehouseId, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	names, err := stocktakeProductNames(ctx, businessId)
+	if err != nil {
+		return nil, err
+	}
+	counted, err := stocktakeLastCounted(ctx, businessId, warehouseId)
+	if err != nil {
+		return nil, err
+	}
+	classes := ClassifyAbc(rows)
+
+	results := make([]*AbcClassificationResponse, 0, len(rows))
+	for i, r := range rows {
+		if r.StockOnHand.IsZero() {
+			continue
+		}
+		key := snapshotKey(r.ProductId, r.ProductType)
+		result := &AbcClassificationResponse{
+			ProductId:   r.ProductId,
+			ProductType: r.ProductType,
+			Name:        names[key],
+			StockOnHand: r.StockOnHand,
+			AssetValue:  r.AssetValue,
+			AbcClass:    classes[i],
+		}
+		if date, ok := counted[key]; ok {
+			result.LastCountedDate = &date
+		}
+		results = append(results, result)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].AssetValue.GreaterThan(results[j].AssetValue)
+	})
+	return results, nil
+}
+
+func (input *NewStocktake) validate(ctx context.Context, businessId string) error {
+	if err := utils.ValidateResourceId[Warehouse](ctx, businessId, input.WarehouseId); err != nil {
+		return errors.New("warehouse not found")
+	}
+	if err := utils.ValidateResourceId[Account](ctx, businessId, input.AccountId); err != nil {
+		return errors.New("account not found")
+	}
+	if err := utils.ValidateResourceId[Reason](ctx, businessId, input.ReasonId); err != nil {
+		return errors.New("reason not found")
+	}
+	if input.AbcClass != nil && !input.AbcClass.IsValid() {
+		return errors.New("invalid abc class")
+	}
+	if input.Limit != nil && *input.Limit <= 0 {
+		return errors.New("limit must be greater than zero")
+	}
+	return ValidateTransactionLock(ctx, input.SnapshotDate, businessId, AccountantTransactionLock)
+}
+
+func CreateStocktake(ctx context.Context, input *NewStocktake) (*Stocktake, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	userId, ok := utils.GetUserIdFromContext(ctx)
+	if !ok || userId == 0 {
+		return nil, errors.New("user id is required")
+	}
+	if err := input.validate(ctx, businessId); err != nil {
+		return nil, err
+	}
+	warehouse, err := GetWarehouse(ctx, input.WarehouseId)
+	if err != nil {
+		return nil, err
+	}
+	business, err := GetBusinessById(ctx, businessId)
+	if err != nil {
+		return nil, err
+	}
+	snapshotDate, err := utils.ConvertToDate(input.SnapshotDate, business.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := InventorySnapshotByProductWarehouse(ctx, MyDateString(snapshotDate), &input.WarehouseId, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	names, err := stocktakeProductNames(ctx, businessId)
+	if err != nil {
+		return nil, err
+	}
+	serialTracked, err := stocktakeSerialTracked(ctx, businessId)
+	if err != nil {
+		return nil, err
+	}
+	inStock := make([]InventorySnapshot, 0, len(rows))
+	for _, r := range rows {
+		if !r.StockOnHand.IsZero() && !serialTracked[snapshotKey(r.ProductId, r.ProductType)] {
+			inStock = append(inStock, r)
+		}
+	}
+	rows = inStock
+	if input.AbcClass != nil {
+		classes := ClassifyAbc(rows)
+		inClass := make([]InventorySnapshot, 0, len(rows))
+		for i, r := range rows {
+			if classes[i] == *input.AbcClass {
+				inClass = append(inClass, r)
+			}
+		}
+		rows = inClass
+	}
+	if input.Limit != nil && len(rows) > *input.Limit {
+		// least recently counted first, never counted before anything else
+		counted, err := stocktakeLastCounted(ctx, businessId, input.WarehouseId)
+		if err != nil {
+			return nil, err
+		}
+		sort.SliceStable(rows, func(i, j int) bool {
+			return counted[snapshotKey(rows[i].ProductId, rows[i].ProductType)].Before(counted[snapshotKey(rows[j].ProductId, rows[j].ProductType)])
+		})
+		rows = rows[:*input.Limit]
+	}
+
+	lines := make([]StocktakeLine, 0, len(rows))
+	for _, r := range rows {
+		lines = append(lines, StocktakeLine{
+			ProductId:   r.ProductId,
+			ProductType: r.ProductType,
+			Name:        names[snapshotKey(r.ProductId, r.ProductType)],
+			ExpectedQty: r.StockOnHand,
+			UnitCost:    r.UnitCostSafe.Round(4),
+			CountRound:  1,
+		})
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].Name < lines[j].Name
+	})
+
+	stocktake := Stocktake{
+		BusinessId:      businessId,
+		StocktakeNumber: input.StocktakeNumber,
+		WarehouseId:     warehouse.ID,
+		BranchId:        warehouse.BranchId,
+		SnapshotDate:    snapshotDate,
+		AbcClass:        input.AbcClass,
+		AccountId:       input.AccountId,
+		ReasonId:        input.ReasonId,
+		Notes:           input.Notes,
+		CurrentStatus:   StocktakeStatusCounting,
+		Lines:           lines,
+		CreatedBy:       userId,
+	}
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Create(&stocktake).Error; err != nil {
+		return nil, err
+	}
+	return &stocktake, nil
+}
+
+// resolveStocktakeCount finds the product a count is for and the quantity in its base unit.
+func resolveStocktakeCount(ctx context.Context, tx *gorm.DB, businessId string, count *NewStocktakeCount) (int, ProductType, decimal.Decimal, error) {
+	barcode := strings.TrimSpace(count.Barcode)
+	if barcode == "" {
+		return count.ProductId, count.ProductType, count.Qty, nil
+	}
+	var found []struct {
+		ProductId   int
+		ProductType ProductType
+	}
+	if err := tx.WithContext(ctx).Raw(`
+		SELECT id AS product_id, 'S' AS product_type FROM products WHERE business_id = ? AND barcode = ?
+		UNION ALL
+		SELECT id AS product_id, 'V' AS product_type FROM product_variants WHERE business_id = ? AND barcode = ?
+	`, businessId, barcode, businessId, barcode).Scan(&found).Error; err != nil {
+		return 0, "", decimal.Zero, err
+	}
+	if len(found) > 0 {
+		return found[0].ProductId, found[0].ProductType, count.Qty, nil
+	}
+	var conversion ProductUnitConversion
+	err := tx.WithContext(ctx).Where("business_id = ? AND barcode = ?", businessId, barcode).First(&conversion).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, "", decimal.Zero, fmt.Errorf("barcode %s not found", barcode)
+	}
+	if err != nil {
+		return 0, "", decimal.Zero, err
+	}
+	return conversion.ProductId, conversion.ProductType, count.Qty.Mul(conversion.Factor), nil
+}
+
+// CountStocktake adds a batch of counts to a stocktake. Counts of the same product add up,
+// so several counters can count different parts of the warehouse; products the snapshot did
+// not expect get a line of their own.
+func CountStocktake(ctx context.Context, id int, counts []*NewStocktakeCount) (*Stocktake, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	userId, ok := utils.GetUserIdFromContext(ctx)
+	if !ok || userId == 0 {
+		return nil, errors.New("user id is required")
+	}
+	stocktake, err := utils.FetchModel[Stocktake](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+	if stocktake.CurrentStatus != StocktakeStatusCounting {
+		return nil, errors.New("stocktake is already approved")
+	}
+	if len(counts) == 0 {
+		return nil, errors.New("no counts to submit")
+	}
+
+	db := config.GetDB()
+	tx := db.Begin()
+	for _, count := range counts {
+		productId, productType, qty, err := resolveStocktakeCount(ctx, tx, businessId, count)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if qty.IsNegative() {
+			tx.Rollback()
+			return nil, errors.New("counted quantity cannot be negative")
+		}
+
+		var line StocktakeLine
+		err = tx.WithContext(ctx).
+			Where("stocktake_id = ? AND product_id = ? AND product_type = ?", stocktake.ID, productId, productType).
+			First(&line).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			line, err = newFoundStocktakeLine(ctx, businessId, stocktake.ID, productId, productType)
+			if err == nil {
+				err = tx.WithContext(ctx).Create(&line).Error
+			}
+		}
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		if err := tx.WithContext(ctx).Create(&StocktakeCount{
+			StocktakeId:     stocktake.ID,
+			StocktakeLineId: line.ID,
+			CountRound:      line.CountRound,
+			Qty:             qty,
+			Barcode:         strings.TrimSpace(count.Barcode),
+			CountedBy:       userId,
+		}).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		// add in the database so concurrent counters do not overwrite each other
+		if err := tx.WithContext(ctx).Model(&StocktakeLine{}).Where("id = ?", line.ID).UpdateColumns(map[string]interface{}{
+			"counted_qty": gorm.Expr("counted_qty + ?", qty),
+			"is_counted":  true,
+		}).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return stocktake, nil
+}
+
+func newFoundStocktakeLine(ctx context.Context, businessId string, stocktakeId int, productId int, productType ProductType) (StocktakeLine, error) {
+	if productType != ProductTypeSingle && productType != ProductTypeVariant {
+		return StocktakeLine{}, errors.New("only products and variants can be counted")
+	}
+	if !IsRealProduct(ctx, businessId, productId, productType) {
+		return StocktakeLine{}, errors.New("product's inventory has not been tracked")
+	}
+	product, err := GetProductOrVariant(ctx, string(productType), productId)
+	if err != nil {
+		return StocktakeLine{}, err
+	}
+	names, err := stocktakeProductNames(ctx, businessId)
+	if err != nil {
+		return StocktakeLine{}, err
+	}
+	tracking, err := productSerialTracking(config.GetDB().WithContext(ctx), productId, productType)
+	if err != nil {
+		return StocktakeLine{}, err
+	}
+	if tracking.IsSerialTracking {
+		return StocktakeLine{}, errStocktakeSerialTracked(names[snapshotKey(productId, productType)])
+	}
+	return StocktakeLine{
+		StocktakeId: stocktakeId,
+		ProductId:   productId,
+		ProductType: productType,
+		Name:        names[snapshotKey(productId, productType)],
+		UnitCost:    product.GetPurchasePrice(),
+		CountRound:  1,
+	}, nil
+}
+
+// RecountStocktake discards the counts of the given lines so they can be counted again.
+// Earlier rounds stay in the count history.
+func RecountStocktake(ctx context.Context, id int, lineIds []int) (*Stocktake, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	stocktake, err := utils.FetchModel[Stocktake](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+	if stocktake.CurrentStatus != StocktakeStatusCounting {
+		return nil, errors.New("stocktake is already approved")
+	}
+	if len(lineIds) == 0 {
+		return nil, errors.New("no lines to recount")
+	}
+
+	db := config.GetDB()
+	result := db.WithContext(ctx).Model(&StocktakeLine{}).
+		Where("stocktake_id = ? AND id IN ?", stocktake.ID, lineIds).
+		UpdateColumns(map[string]interface{}{
+			"counted_qty": decimal.Zero,
+			"is_counted":  false,
+			"count_round": gorm.Expr("count_round + 1"),
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected != int64(len(lineIds)) {
+		return nil, errors.New("stocktake line not found")
+	}
+	return stocktake, nil
+}
+
+// stocktakeAdjustment is the quantity adjustment posting the variances of the counted lines,
+// or nil when the count matched.
+func (s *Stocktake) stocktakeAdjustment() *NewInventoryAdjustment {
+	var details []NewInventoryAdjustmentDetail
+	for _, line := range s.Lines {
+		variance := line.VarianceQty()
+		if variance.IsZero() {
+			continue
+		}
+		details = append(details, NewInventoryAdjustmentDetail{
+			ProductId:     line.ProductId,
+			ProductType:   line.ProductType,
+			Name:          line.Name,
+			AdjustedValue: variance,
+			CostPrice:     line.UnitCost,
+		})
+	}
+	if len(details) == 0 {
+		return nil
+	}
+	return &NewInventoryAdjustment{
+		ReferenceNumber: s.StocktakeNumber,
+		AdjustmentType:  InventoryAdjustmentTypeQuantity,
+		AdjustmentDate:  s.SnapshotDate,
+		AccountId:       s.AccountId,
+		BranchId:        s.BranchId,
+		WarehouseId:     s.WarehouseId,
+		CurrentStatus:   InventoryAdjustmentStatusAdjusted,
+		ReasonId:        s.ReasonId,
+		Description:     "Stocktake " + s.StocktakeNumber,
+		Details:         details,
+	}
+}
+
+// ApproveStocktake closes the count and posts its variances. Lines nobody counted are left
+// as they are.
+func ApproveStocktake(ctx context.Context, id int) (*Stocktake, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	userId, ok := utils.GetUserIdFromContext(ctx)
+	if !ok || userId == 0 {
+		return nil, errors.New("user id is required")
+	}
+
+	db := config.GetDB()
+	tx := db.Begin()
+	// the row stays locked until the adjustment and the new status commit together, so a
+	// second approval waits and then finds the stocktake approved
+	stocktake, err := fetchHeldDocument[Stocktake](ctx, tx, businessId, id, "Lines")
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if stocktake.CurrentStatus != StocktakeStatusCounting {
+		tx.Rollback()
+		return nil, errors.New("stocktake is already approved")
+	}
+	counted := false
+	for _, line := range stocktake.Lines {
+		counted = counted || line.IsCounted
+	}
+	if !counted {
+		tx.Rollback()
+		return nil, errors.New("nothing has been counted yet")
+	}
+	// stocktakes started before serial tracked products were left out may still hold them
+	for _, line := range stocktake.Lines {
+		if line.VarianceQty().IsZero() {
+			continue
+		}
+		tracking, err := productSerialTracking(tx, line.ProductId, line.ProductType)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if tracking.IsSerialTracking {
+			tx.Rollback()
+			return nil, errStocktakeSerialTracked(line.Name)
+		}
+	}
+
+	if input := stocktake.stocktakeAdjustment(); input != nil {
+		adjustment, err := createInventoryAdjustment(ctx, tx, businessId, userId, input)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		stocktake.InventoryAdjustmentId = adjustment.ID
+	}
+	stocktake.CurrentStatus = StocktakeStatusApproved
+	stocktake.ApprovedBy = userId
+
+	if err := tx.WithContext(ctx).Model(stocktake).UpdateColumns(map[string]interface{}{
+		"current_status":          stocktake.CurrentStatus,
+		"inventory_adjustment_id": stocktake.InventoryAdjustmentId,
+		"approved_by":             stocktake.ApprovedBy,
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return stocktake, nil
+}
+
+// DeleteStocktake abandons a stocktake that is still being counted.
+func DeleteStocktake(ctx context.Context, id int) (*Stocktake, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	stocktake, err := utils.FetchModel[Stocktake](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+	if stocktake.CurrentStatus != StocktakeStatusCounting {
+		return nil, errors.New("approved stocktakes cannot be deleted; delete their inventory adjustment instead")
+	}
+
+	db := config.GetDB()
+	tx := db.Begin()
+	if err := tx.WithContext(ctx).Where("stocktake_id = ?", stocktake.ID).Delete(&StocktakeCount{}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.WithContext(ctx).Where("stocktake_id = ?", stocktake.ID).Delete(&StocktakeLine{}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.WithContext(ctx).Delete(stocktake).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return stocktake, nil
+}
+
+func GetStocktake(ctx context.Context, id int) (*Stocktake, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	return utils.FetchModel[Stocktake](ctx, businessId, id)
+}
+
+func GetStocktakeLines(ctx context.Context, stocktakeId int) ([]*StocktakeLine, error) {
+	db := config.GetDB()
+	var lines []*StocktakeLine
+	if err := db.WithContext(ctx).Where("stocktake_id = ?", stocktakeId).Order("name, id").Find(&lines).Error; err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+func PaginateStocktake(
+	ctx context.Context, limit *int, after *string,
+	warehouseId *int,
+	currentStatus *StocktakeStatus,
+) (*StocktakesConnection, error) {
+
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	db := config.GetDB()
+	dbCtx := db.WithContext(ctx).Where("business_id = ?", businessId)
+
+	if warehouseId != nil && *warehouseId > 0 {
+		dbCtx.Where("warehouse_id = ?", *warehouseId)
+	}
+	if currentStatus != nil {
+		dbCtx.Where("current_status = ?", *currentStatus)
+	}
+
+	edges, pageInfo, err := FetchPageCompositeCursor[Stocktake](dbCtx, *limit, after, "created_at", "<")
+	if err != nil {
+		return nil, err
+	}
+	var stocktakesConnection StocktakesConnection
+	stocktakesConnection.PageInfo = pageInfo
+	for _, edge := range edges {
+		stocktakesEdge := StocktakesEdge(edge)
+		stocktakesConnection.Edges = append(stocktakesConnection.Edges, &stocktakesEdge)
+	}
+
+	return &stocktakesConnection, err
+}
@@ -0,0 +1,159 @@
+package models_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/mmdatafocus/books_backend/workflow"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+)
+
+// Regression: a stocktake cannot post a variance of a serial tracked product, whose
+// adjustment needs the serials of the units missing or found. Such products are left out
+// of the snapshot and counting one is refused up front, instead of approval failing.
+func TestStocktake_LeavesOutSerialTrackedProducts(t *testing.T) {
+	if strings.TrimSpace(os.Getenv("INTEGRATION_TESTS")) == "" {
+		t.Skip("set INTEGRATION_TESTS=1 to run integration tests (requires docker)")
+	}
+
+	ctx := context.Background()
+
+	redisName, redisPort := startRedisContainer(t)
+	t.Cleanup(func() { _ = dockerRmForce(redisName) })
+
+	mysqlName, mysqlPort := startMySQLContainer(t)
+	t.Cleanup(func() { _ = dockerRmForce(mysqlName) })
+
+	t.Setenv("REDIS_ADDRESS", fmt.Sprintf("127.0.0.1:%s", redisPort))
+	t.Setenv("DB_USER", "root")
+	t.Setenv("DB_PASSWORD", "testpw")
+	t.Setenv("DB_HOST", "127.0.0.1")
+	t.Setenv("DB_PORT", mysqlPort)
+	t.Setenv("DB_NAME_2", "pitibooks_test")
+	t.Setenv("STOCK_COMMANDS_DOCS", "")
+
+	config.ConnectDatabaseWithRetry()
+	config.ConnectRedisWithRetry()
+	models.MigrateTable()
+
+	ctx = utils.SetUserIdInContext(ctx, 1)
+	ctx = utils.SetUserNameInContext(ctx, "Test")
+	ctx = utils.SetUsernameInContext(ctx, "test@local")
+
+	biz, err := models.CreateBusiness(ctx, &models.NewBusiness{
+		Name:  "Test Biz",
+		Email: "owner@test.local",
+	})
+	if err != nil {
+		t.Fatalf("CreateBusiness: %v", err)
+	}
+	businessID := biz.ID.String()
+	ctx = utils.SetBusinessIdInContext(ctx, businessID)
+
+	db := config.GetDB()
+	var primary models.Warehouse
+	if err := db.WithContext(ctx).Where("business_id = ? AND name = ?", businessID, "Primary Warehouse").First(&primary).Error; err != nil {
+		t.Fatalf("fetch primary warehouse: %v", err)
+	}
+	reason, err := models.CreateReason(ctx, &models.NewReason{Name: "Count"})
+	if err != nil {
+		t.Fatalf("CreateReason: %v", err)
+	}
+	unit, err := models.CreateProductUnit(ctx, &models.NewProductUnit{Name: "Pcs", Abbreviation: "pc", Precision: models.PrecisionZero})
+	if err != nil {
+		t.Fatalf("CreateProductUnit: %v", err)
+	}
+	sysAccounts, err := models.GetSystemAccounts(businessID)
+	if err != nil {
+		t.Fatalf("GetSystemAccounts: %v", err)
+	}
+	invAcc := sysAccounts[models.AccountCodeInventoryAsset]
+	salesAcc := sysAccounts[models.AccountCodeSales]
+	cogsAcc := sysAccounts[models.AccountCodeCostOfGoodsSold]
+
+	phone, err := models.CreateProduct(ctx, &models.NewProduct{
+		Name:               "Phone",
+		Sku:                "PHONE-001",
+		UnitId:             unit.ID,
+		SalesAccountId:     salesAcc,
+		PurchaseAccountId:  cogsAcc,
+		InventoryAccountId: invAcc,
+		IsBatchTracking:    utils.NewFalse(),
+		IsSerialTracking:   utils.NewTrue(),
+	})
+	if err != nil {
+		t.Fatalf("CreateProduct: %v", err)
+	}
+
+	// Two units come in with their serials.
+	stockDate := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	ia, err := models.CreateInventoryAdjustment(ctx, &models.NewInventoryAdjustment{
+		ReferenceNumber: "IVAQ-0001",
+		AdjustmentType:  models.InventoryAdjustmentTypeQuantity,
+		AdjustmentDate:  stockDate,
+		AccountId:       cogsAcc,
+		BranchId:        biz.PrimaryBranchId,
+		WarehouseId:     primary.ID,
+		CurrentStatus:   models.InventoryAdjustmentStatusAdjusted,
+		ReasonId:        reason.ID,
+		Details: []models.NewInventoryAdjustmentDetail{
+			{
+				ProductId:     phone.ID,
+				ProductType:   models.ProductTypeSingle,
+				Name:          "Phone",
+				AdjustedValue: decimal.NewFromInt(2),
+				CostPrice:     decimal.NewFromInt(300),
+				SerialNumbers: []string{"SN-1", "SN-2"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateInventoryAdjustment: %v", err)
+	}
+	var outbox models.PubSubMessageRecord
+	if err := db.WithContext(ctx).
+		Where("business_id = ? AND reference_type = ? AND reference_id = ? AND action = ?",
+			businessID, models.AccountReferenceTypeInventoryAdjustmentQuantity, ia.ID, models.PubSubMessageActionCreate).
+		Order("id DESC").
+		First(&outbox).Error; err != nil {
+		t.Fatalf("expected outbox record for quantity adjustment: %v", err)
+	}
+	wtx := db.Begin()
+	if err := workflow.ProcessInventoryAdjustmentQuantityWorkflow(wtx, logrus.New(), models.ConvertToPubSubMessage(outbox)); err != nil {
+		t.Fatalf("ProcessInventoryAdjustmentQuantityWorkflow: %v", err)
+	}
+	if err := wtx.Commit().Error; err != nil {
+		t.Fatalf("quantity adjustment workflow commit: %v", err)
+	}
+
+	stocktake, err := models.CreateStocktake(ctx, &models.NewStocktake{
+		StocktakeNumber: "ST-0001",
+		WarehouseId:     primary.ID,
+		SnapshotDate:    stockDate.AddDate(0, 0, 1),
+		AccountId:       cogsAcc,
+		ReasonId:        reason.ID,
+	})
+	if err != nil {
+		t.Fatalf("CreateStocktake: %v", err)
+	}
+	for _, line := range stocktake.Lines {
+		if line.ProductId == phone.ID && line.ProductType == models.ProductTypeSingle {
+			t.Fatalf("stocktake snapshot holds the serial tracked product: %+v", line)
+		}
+	}
+
+	_, err = models.CountStocktake(ctx, stocktake.ID, []*models.NewStocktakeCount{
+		{ProductId: phone.ID, ProductType: models.ProductTypeSingle, Qty: decimal.NewFromInt(1)},
+	})
+	if err == nil || !strings.Contains(err.Error(), "serial tracked") {
+		t.Fatalf("CountStocktake of a serial tracked product: err = %v, want a serial tracked error", err)
+	}
+}
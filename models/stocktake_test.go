@@ -0,0 +1,49 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/shopspring/decimal"
+)
+
+func TestClassifyAbc(t *testing.T) {
+	d := decimal.RequireFromString
+	row := func(id int, value string) models.InventorySnapshot {
+		return models.InventorySnapshot{ProductId: id, ProductType: models.ProductTypeSingle, StockOnHand: d("1"), AssetValue: d(value)}
+	}
+	rows := []models.InventorySnapshot{
+		row(1, "50"),
+		row(2, "700"),
+		row(3, "100"),
+		row(4, "0"),
+		row(5, "150"),
+	}
+	want := []models.AbcClass{
+		models.AbcClassC, // starts at 95%
+		models.AbcClassA, // the most valuable product is always A
+		models.AbcClassB, // starts at 85%
+		models.AbcClassC, // no value
+		models.AbcClassA, // starts at 70%
+	}
+	got := models.ClassifyAbc(rows)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("product %d: class %s, want %s", rows[i].ProductId, got[i], want[i])
+		}
+	}
+}
+
+func TestStocktakeLineVariance(t *testing.T) {
+	d := decimal.RequireFromString
+	line := models.StocktakeLine{ExpectedQty: d("10"), UnitCost: d("2.5"), CountedQty: d("0")}
+	if !line.VarianceQty().IsZero() || !line.VarianceValue().IsZero() {
+		t.Fatalf("uncounted line has a variance of %s (%s)", line.VarianceQty(), line.VarianceValue())
+	}
+
+	line.IsCounted = true
+	line.CountedQty = d("7")
+	if !line.VarianceQty().Equal(d("-3")) || !line.VarianceValue().Equal(d("-7.5")) {
+		t.Fatalf("variance = %s (%s), want -3 (-7.5)", line.VarianceQty(), line.VarianceValue())
+	}
+}
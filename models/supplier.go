@@ -24,6 +24,7 @@ type Supplier struct {
 	SupplierPaymentTerms           PaymentTerms     `gorm:"type:enum('Net15', 'Net30', 'Net45', 'Net60', 'DueMonthEnd', 'DueNextMonthEnd', 'DueOnReceipt', 'Custom');not null;default:'DueOnReceipt'" json:"supplier_payment_terms" binding:"required"`
 	SupplierPaymentTermsCustomDays int              `gorm:"default:0" json:"supplier_payment_terms_custom_days"`
 	Notes                          string           `gorm:"type:text" json:"notes"`
+	PriceListId                    int              `gorm:"default:0" json:"price_list_id"`
 	BillingAddress                 BillingAddress   `gorm:"polymorphic:Reference" json:"-"`
 	ShippingAddress                ShippingAddress  `gorm:"polymorphic:Reference" json:"-"`
 	ContactPersons                 []*ContactPerson `gorm:"polymorphic:Reference" json:"-"`
@@ -59,6 +60,7 @@ type NewSupplier struct {
 	SupplierPaymentTerms           PaymentTerms        `json:"supplier_payment_terms" binding:"required"`
 	SupplierPaymentTermsCustomDays int                 `json:"supplier_payment_terms_custom_days"`
 	Notes                          string              `json:"notes"`
+	PriceListId                    int                 `json:"price_list_id"`
 	BillingAddress                 *NewBillingAddress  `json:"billing_address"`
 	ShippingAddress                *NewShippingAddress `json:"shipping_address"`
 	ContactPersons                 []*NewContactPerson `json:"contact_persons"`
@@ -112,6 +114,9 @@ func (input *NewSupplier) validate(ctx context.Context, businessId string, id in
 	if err := utils.ValidateResourceId[Currency](ctx, businessId, input.CurrencyId); err != nil {
 		return errors.New("currency not found")
 	}
+	if err := validatePriceListId(ctx, businessId, input.PriceListId, PriceListTypePurchase); err != nil {
+		return err
+	}
 	// validate tax
 	if input.SupplierTaxType != nil {
 		if err := validateTaxExists(ctx, businessId, input.SupplierTaxId, *input.SupplierTaxType); err != nil {
@@ -155,6 +160,7 @@ func CreateSupplier(ctx context.Context, input *NewSupplier) (*Supplier, error)
 		SupplierPaymentTerms:           input.SupplierPaymentTerms,
 		SupplierPaymentTermsCustomDays: input.SupplierPaymentTermsCustomDays,
 		Notes:                          input.Notes,
+		PriceListId:                    input.PriceListId,
 		IsActive:                       utils.NewTrue(),
 		OpeningBalanceBranchId:         input.OpeningBalanceBranchId,
 		OpeningBalance:                 input.OpeningBalance,
@@ -263,6 +269,7 @@ func UpdateSupplier(ctx context.Context, id int, input *NewSupplier) (*Supplier,
 			"SupplierPaymentTerms":           input.SupplierPaymentTerms,
 			"SupplierPaymentTermsCustomDays": input.SupplierPaymentTermsCustomDays,
 			"Notes":                          input.Notes,
+			"PriceListId":                    input.PriceListId,
 			"OpeningBalanceBranchId":         input.OpeningBalanceBranchId,
 			"OpeningBalance":                 input.OpeningBalance,
 		}).Error
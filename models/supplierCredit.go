@@ -77,9 +77,10 @@ type SupplierCreditDetail struct {
 	ProductType          ProductType     `gorm:"type:enum('S','G','C','V','I');default:S" json:"product_type"`
 	BatchNumber          string          `gorm:"size:100" json:"batch_number"`
 	Name                 string          `gorm:"size:100" json:"name" binding:"required"`
+	SerialNumbers        []string        `gorm:"type:text;serializer:json" json:"serial_numbers"`
 	Description          string          `gorm:"size:255;default:null" json:"description"`
 	DetailQty            decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_qty" binding:"required"`
-	DetailUnitRate       decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"detail_unit_rate" binding:"required"`
+	DetailUnitRate       decimal.Decimal `gorm:"type:decimal(20,8);default:0" json:"detail_unit_rate" binding:"required"`
 	DetailTaxId          int             `gorm:"default:null" json:"detail_tax_id"`
 	DetailTaxType        *TaxType        `gorm:"type:enum('I', 'G');default:null" json:"detail_tax_type"`
 	DetailAccountId      int             `gorm:"default:null" json:"detail_account_id"`
@@ -91,6 +92,7 @@ type SupplierCreditDetail struct {
 	Cogs                 decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"cogs"`
 	CreatedAt            time.Time       `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt            time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+	LineUnit
 }
 
 type NewSupplierCreditDetail struct {
@@ -99,6 +101,7 @@ type NewSupplierCreditDetail struct {
 	ProductType        ProductType     `json:"product_type"`
 	BatchNumber        string          `json:"batch_number"`
 	Name               string          `json:"name" binding:"required"`
+	SerialNumbers      []string        `json:"serial_numbers"`
 	Description        string          `json:"description"`
 	DetailQty          decimal.Decimal `json:"detail_qty" binding:"required"`
 	DetailUnitRate     decimal.Decimal `json:"detail_unit_rate" binding:"required"`
@@ -108,6 +111,7 @@ type NewSupplierCreditDetail struct {
 	DetailDiscountType *DiscountType   `json:"detail_discount_type"`
 	DetailAccountId    int             `json:"detail_account_id"`
 	IsDeletedItem      *bool           `json:"is_deleted_item"`
+	LineUnit
 }
 
 type SupplierCreditsEdge Edge[SupplierCredit]
@@ -372,6 +376,19 @@ func (input NewSupplierCredit) validate(ctx context.Context, businessId string,
 	return nil
 }
 
+func (input *NewSupplierCredit) detailsToBaseUnit(ctx context.Context, businessId string) error {
+	for i := range input.Details {
+		d := &input.Details[i]
+		if d.IsDeletedItem != nil && *d.IsDeletedItem {
+			continue
+		}
+		if err := d.LineUnit.toBaseUnit(ctx, businessId, d.ProductType, d.ProductId, &d.DetailQty, &d.DetailUnitRate); err != nil {
+			return fmt.Errorf("%s: %w", d.Name, err)
+		}
+	}
+	return nil
+}
+
 func CreateSupplierCredit(ctx context.Context, input *NewSupplierCredit) (*SupplierCredit, error) {
 	businessId, ok := utils.GetBusinessIdFromContext(ctx)
 	if !ok || businessId == "" {
@@ -380,10 +397,16 @@ func CreateSupplierCredit(ctx context.Context, input *NewSupplierCredit) (*Suppl
 	// IMPORTANT (correctness): if callers request "Confirmed" on create, we still create as Draft
 	// and then transition Draft -> Confirmed inside the same DB transaction.
 	requestedStatus := input.CurrentStatus
+	if err := input.detailsToBaseUnit(ctx, businessId); err != nil {
+		return nil, err
+	}
 	// validate
 	if err := input.validate(ctx, businessId, 0); err != nil {
 		return nil, err
 	}
+	if err := resolveExchangeRate(ctx, businessId, input.CurrencyId, input.SupplierCreditDate, &input.ExchangeRate); err != nil {
+		return nil, err
+	}
 
 	// construct Images
 	documents, err := mapNewDocuments(input.Documents, "supplier_credits", 0)
@@ -405,10 +428,12 @@ func CreateSupplierCredit(ctx context.Context, input *NewSupplierCredit) (*Suppl
 			ProductType:        item.ProductType,
 			BatchNumber:        item.BatchNumber,
 			Name:               item.Name,
+			SerialNumbers:      item.SerialNumbers,
 			Description:        item.Description,
 			DetailAccountId:    item.DetailAccountId,
 			DetailQty:          item.DetailQty,
 			DetailUnitRate:     item.DetailUnitRate,
+			LineUnit:           item.LineUnit,
 			DetailTaxId:        item.DetailTaxId,
 			DetailTaxType:      item.DetailTaxType,
 			DetailDiscount:     item.DetailDiscount,
@@ -546,6 +571,11 @@ func CreateSupplierCredit(ctx context.Context, input *NewSupplierCredit) (*Suppl
 			}
 		}
 
+		if err := postSerialNumbers(tx.WithContext(ctx), businessId, supplierCredit.serialNumberPosting()); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
 		// Write outbox record (publishing happens after commit via dispatcher).
 		if err := PublishToAccounting(ctx, tx, businessId, supplierCredit.SupplierCreditDate, supplierCredit.ID, AccountReferenceTypeSupplierCredit, supplierCredit, nil, PubSubMessageActionCreate); err != nil {
 			tx.Rollback()
@@ -568,6 +598,9 @@ func UpdateSupplierCredit(ctx context.Context, supplierCreditID int, updatedSupp
 		return nil, errors.New("business id is required")
 	}
 
+	if err := updatedSupplierCredit.detailsToBaseUnit(ctx, businessId); err != nil {
+		return nil, err
+	}
 	if err := updatedSupplierCredit.validate(ctx, businessId, supplierCreditID); err != nil {
 		return nil, err
 	}
@@ -641,10 +674,12 @@ func UpdateSupplierCredit(ctx context.Context, supplierCreditID int, updatedSupp
 				ProductType:        updatedItem.ProductType,
 				BatchNumber:        updatedItem.BatchNumber,
 				Name:               updatedItem.Name,
+				SerialNumbers:      updatedItem.SerialNumbers,
 				Description:        updatedItem.Description,
 				DetailAccountId:    updatedItem.DetailAccountId,
 				DetailQty:          updatedItem.DetailQty,
 				DetailUnitRate:     updatedItem.DetailUnitRate,
+				LineUnit:           updatedItem.LineUnit,
 				DetailTaxId:        updatedItem.DetailTaxId,
 				DetailTaxType:      updatedItem.DetailTaxType,
 				DetailDiscount:     updatedItem.DetailDiscount,
@@ -751,10 +786,12 @@ func UpdateSupplierCredit(ctx context.Context, supplierCreditID int, updatedSupp
 				// existingItem.ProductType = updatedItem.ProductType
 				existingItem.BatchNumber = updatedItem.BatchNumber
 				existingItem.Name = updatedItem.Name
+				existingItem.SerialNumbers = updatedItem.SerialNumbers
 				existingItem.Description = updatedItem.Description
 				existingItem.DetailAccountId = updatedItem.DetailAccountId
 				existingItem.DetailQty = updatedItem.DetailQty
 				existingItem.DetailUnitRate = updatedItem.DetailUnitRate
+				existingItem.LineUnit = updatedItem.LineUnit
 				existingItem.DetailTaxId = updatedItem.DetailTaxId
 				existingItem.DetailTaxType = updatedItem.DetailTaxType
 				existingItem.DetailDiscount = updatedItem.DetailDiscount
@@ -902,6 +939,18 @@ func UpdateSupplierCredit(ctx context.Context, supplierCreditID int, updatedSupp
 		return nil, err
 	}
 
+	if existingSupplierCredit.CurrentStatus == SupplierCreditStatusConfirmed {
+		if err := postSerialNumbers(tx.WithContext(ctx), businessId, existingSupplierCredit.serialNumberPosting()); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	} else if oldStatus == SupplierCreditStatusConfirmed {
+		if err := unpostSerialNumbers(tx.WithContext(ctx), businessId, StockReferenceTypeSupplierCredit, existingSupplierCredit.ID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
 	if oldStatus == SupplierCreditStatusDraft && existingSupplierCredit.CurrentStatus == SupplierCreditStatusConfirmed {
 		err := PublishToAccounting(ctx, tx, businessId, existingSupplierCredit.SupplierCreditDate, existingSupplierCredit.ID, AccountReferenceTypeSupplierCredit, existingSupplierCredit, nil, PubSubMessageActionCreate)
 		if err != nil {
@@ -1034,6 +1083,10 @@ func DeleteSupplierCredit(ctx context.Context, id int) (*SupplierCredit, error)
 	}
 
 	if result.CurrentStatus == SupplierCreditStatusConfirmed {
+		if err := unpostSerialNumbers(tx.WithContext(ctx), businessId, StockReferenceTypeSupplierCredit, result.ID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
 		err = PublishToAccounting(ctx, tx, businessId, oldSupplierCredit.SupplierCreditDate, oldSupplierCredit.ID, AccountReferenceTypeSupplierCredit, nil, oldSupplierCredit, PubSubMessageActionDelete)
 		if err != nil {
 			tx.Rollback()
@@ -1092,12 +1145,20 @@ func UpdateStatusSupplierCredit(ctx context.Context, id int, status string) (*Su
 	}
 
 	if oldStatus == SupplierCreditStatusDraft && status == string(SupplierCreditStatusConfirmed) {
+		if err := postSerialNumbers(tx.WithContext(ctx), businessId, supplierCredit.serialNumberPosting()); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
 		err := PublishToAccounting(ctx, tx, businessId, supplierCredit.SupplierCreditDate, supplierCredit.ID, AccountReferenceTypeSupplierCredit, supplierCredit, nil, PubSubMessageActionCreate)
 		if err != nil {
 			tx.Rollback()
 			return nil, err
 		}
 	} else if oldStatus == SupplierCreditStatusConfirmed && status == string(SupplierCreditStatusVoid) {
+		if err := unpostSerialNumbers(tx.WithContext(ctx), businessId, StockReferenceTypeSupplierCredit, supplierCredit.ID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
 		err = PublishToAccounting(ctx, tx, businessId, supplierCredit.SupplierCreditDate, supplierCredit.ID, AccountReferenceTypeSupplierCredit, nil, supplierCredit, PubSubMessageActionDelete)
 		if err != nil {
 			tx.Rollback()
@@ -13,42 +13,48 @@ import (
 )
 
 type SupplierPayment struct {
-	ID                int                `gorm:"primary_key" json:"id"`
-	BusinessId        string             `gorm:"index;not null" json:"business_id" binding:"required"`
-	SupplierId        int                `gorm:"index;not null" json:"supplier_id" binding:"required"`
-	BranchId          int                `gorm:"index;not null" json:"branch_id"`
-	CurrencyId        int                `gorm:"not null" json:"currency_id" binding:"required"`
-	ExchangeRate      decimal.Decimal    `gorm:"type:decimal(20,4);default:0" json:"exchange_rate"`
-	Amount            decimal.Decimal    `gorm:"type:decimal(20,4);default:0" json:"amount" binding:"required"`
-	BankCharges       decimal.Decimal    `gorm:"type:decimal(20,4);default:0" json:"bank_charges"`
-	PaymentDate       time.Time          `gorm:"not null" json:"payment_date" binding:"required"`
-	PaymentNumber     string             `gorm:"size:255;not null" json:"payment_number" binding:"required"`
-	SequenceNo        decimal.Decimal    `gorm:"type:decimal(15);not null" json:"sequence_no"`
-	PaymentModeId     int                `gorm:"default:null" json:"payment_mode_id"`
-	WithdrawAccountId int                `gorm:"default:null" json:"withdraw_account_id"`
-	ReferenceNumber   string             `gorm:"size:255;default:null" json:"reference_number"`
-	Notes             string             `gorm:"type:text;default:null" json:"notes"`
-	Documents         []*Document        `gorm:"polymorphic:Reference" json:"documents"`
-	PaidBills         []SupplierPaidBill `json:"paid_bills" validate:"required,dive,required"`
-	CreatedAt         time.Time          `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt         time.Time          `gorm:"autoUpdateTime" json:"updated_at"`
+	ID           int             `gorm:"primary_key" json:"id"`
+	BusinessId   string          `gorm:"index;not null" json:"business_id" binding:"required"`
+	SupplierId   int             `gorm:"index;not null" json:"supplier_id" binding:"required"`
+	BranchId     int             `gorm:"index;not null" json:"branch_id"`
+	CurrencyId   int             `gorm:"not null" json:"currency_id" binding:"required"`
+	ExchangeRate decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"exchange_rate"`
+	Amount       decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"amount" binding:"required"`
+	BankCharges  decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"bank_charges"`
+	// Amount settles the bills in full; only Amount less WithholdingTaxAmount leaves the account.
+	WithholdingTaxId     int                `gorm:"default:null" json:"withholding_tax_id"`
+	WithholdingTaxAmount decimal.Decimal    `gorm:"type:decimal(20,4);default:0" json:"withholding_tax_amount"`
+	PaymentDate          time.Time          `gorm:"not null" json:"payment_date" binding:"required"`
+	PaymentNumber        string             `gorm:"size:255;not null" json:"payment_number" binding:"required"`
+	SequenceNo           decimal.Decimal    `gorm:"type:decimal(15);not null" json:"sequence_no"`
+	PaymentModeId        int                `gorm:"default:null" json:"payment_mode_id"`
+	WithdrawAccountId    int                `gorm:"default:null" json:"withdraw_account_id"`
+	ReferenceNumber      string             `gorm:"size:255;default:null" json:"reference_number"`
+	Notes                string             `gorm:"type:text;default:null" json:"notes"`
+	Documents            []*Document        `gorm:"polymorphic:Reference" json:"documents"`
+	PaidBills            []SupplierPaidBill `json:"paid_bills" validate:"required,dive,required"`
+	ApprovalStatus       *ApprovalStatus    `gorm:"size:20;default:null" json:"approval_status"`
+	CreatedAt            time.Time          `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt            time.Time          `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
 type NewSupplierPayment struct {
-	BusinessId        string          `json:"business_id" binding:"required"`
-	SupplierId        int             `json:"supplier_id" binding:"required"`
-	BranchId          int             `json:"branch_id" binding:"required"`
-	CurrencyId        int             `json:"currency_id" binding:"required"`
-	ExchangeRate      decimal.Decimal `json:"exchange_rate"`
-	Amount            decimal.Decimal `json:"amount" binding:"required"`
-	BankCharges       decimal.Decimal `json:"bank_charges"`
-	PaymentDate       time.Time       `json:"payment_date" binding:"required"`
-	PaymentModeId     int             `json:"payment_mode"`
-	WithdrawAccountId int             `json:"withdraw_account_id"`
-	ReferenceNumber   string          `json:"reference_number"`
-	Notes             string          `json:"notes"`
-	PaidBills         []NewPaidBill   `json:"paid_bills"`
-	Documents         []*NewDocument  `json:"documents"`
+	BusinessId           string          `json:"business_id" binding:"required"`
+	SupplierId           int             `json:"supplier_id" binding:"required"`
+	BranchId             int             `json:"branch_id" binding:"required"`
+	CurrencyId           int             `json:"currency_id" binding:"required"`
+	ExchangeRate         decimal.Decimal `json:"exchange_rate"`
+	Amount               decimal.Decimal `json:"amount" binding:"required"`
+	BankCharges          decimal.Decimal `json:"bank_charges"`
+	WithholdingTaxId     int             `json:"withholding_tax_id"`
+	WithholdingTaxAmount decimal.Decimal `json:"withholding_tax_amount"`
+	PaymentDate          time.Time       `json:"payment_date" binding:"required"`
+	PaymentModeId        int             `json:"payment_mode"`
+	WithdrawAccountId    int             `json:"withdraw_account_id"`
+	ReferenceNumber      string          `json:"reference_number"`
+	Notes                string          `json:"notes"`
+	PaidBills            []NewPaidBill   `json:"paid_bills"`
+	Documents            []*NewDocument  `json:"documents"`
 }
 
 type SupplierPaidBill struct {
@@ -101,8 +107,31 @@ func (input NewSupplierPayment) validate(ctx context.Context, businessId string)
 	return nil
 }
 
+// approvalSubject describes the payment for approval policies. The paid bills' balances
+// are reserved as soon as the payment is saved, while it waits for approval, and released
+// again when it is rejected (see supplierPaymentReserved).
+func (sp SupplierPayment) approvalSubject() approvalSubject {
+	return approvalSubject{
+		DocumentType:    ApprovalDocumentTypeSupplierPayment,
+		ReferenceId:     sp.ID,
+		ReferenceNumber: sp.PaymentNumber,
+		BranchId:        sp.BranchId,
+		CurrencyId:      sp.CurrencyId,
+		ExchangeRate:    sp.ExchangeRate,
+		Amount:          sp.Amount,
+	}
+}
+
+// supplierPaymentReserved reports whether the payment still holds its paid bills' balances.
+func supplierPaymentReserved(status *ApprovalStatus) bool {
+	return status == nil || *status != ApprovalStatusRejected
+}
+
 func (sp SupplierPayment) CheckTransactionLock(ctx context.Context) error {
-	return validateTransactionLock(ctx, sp.PaymentDate, sp.BusinessId, PurchaseTransactionLock)
+	if err := validateTransactionLock(ctx, sp.PaymentDate, sp.BusinessId, PurchaseTransactionLock); err != nil {
+		return err
+	}
+	return validateBankReconciliationLock(ctx, sp.BusinessId, 0, BankingTransactionTypeSupplierPayment, sp.ID)
 }
 
 // implements methods for pagination
@@ -140,10 +169,17 @@ func CreateSupplierPayment(ctx context.Context, input *NewSupplierPayment) (*Sup
 	if err := input.validate(ctx, businessId); err != nil {
 		return nil, err
 	}
+	if err := resolveExchangeRate(ctx, businessId, input.CurrencyId, input.PaymentDate, &input.ExchangeRate); err != nil {
+		return nil, err
+	}
+	withheld, err := resolveWithholding(ctx, businessId, input.WithholdingTaxId, input.WithholdingTaxAmount, input.Amount)
+	if err != nil {
+		return nil, err
+	}
 
 	tx := db.Begin()
 	// construct paidBills
-	paidBills, err := mapPaidBillsInput(tx, ctx, businessId, input)
+	paidBills, err := mapPaidBillsInput(tx, ctx, businessId, input, false)
 	if err != nil {
 		tx.Rollback()
 		return nil, err
@@ -170,20 +206,22 @@ func CreateSupplierPayment(ctx context.Context, input *NewSupplierPayment) (*Sup
 	}
 
 	supplierPayment := SupplierPayment{
-		BusinessId:        businessId,
-		SupplierId:        input.SupplierId,
-		BranchId:          input.BranchId,
-		CurrencyId:        input.CurrencyId,
-		ExchangeRate:      input.ExchangeRate,
-		Amount:            input.Amount,
-		BankCharges:       input.BankCharges,
-		PaymentDate:       input.PaymentDate,
-		PaymentModeId:     input.PaymentModeId,
-		WithdrawAccountId: input.WithdrawAccountId,
-		ReferenceNumber:   input.ReferenceNumber,
-		Notes:             input.Notes,
-		Documents:         documents,
-		PaidBills:         paidBills,
+		BusinessId:           businessId,
+		SupplierId:           input.SupplierId,
+		BranchId:             input.BranchId,
+		CurrencyId:           input.CurrencyId,
+		ExchangeRate:         input.ExchangeRate,
+		Amount:               input.Amount,
+		BankCharges:          input.BankCharges,
+		WithholdingTaxId:     input.WithholdingTaxId,
+		WithholdingTaxAmount: withheld,
+		PaymentDate:          input.PaymentDate,
+		PaymentModeId:        input.PaymentModeId,
+		WithdrawAccountId:    input.WithdrawAccountId,
+		ReferenceNumber:      input.ReferenceNumber,
+		Notes:                input.Notes,
+		Documents:            documents,
+		PaidBills:            paidBills,
 	}
 
 	seqNo, err := utils.GetSequence[SupplierPayment](ctx, businessId)
@@ -206,7 +244,12 @@ func CreateSupplierPayment(ctx context.Context, input *NewSupplierPayment) (*Sup
 	}
 
 	supplierPayment.Documents = nil
-	err = PublishToAccounting(ctx, tx, businessId, supplierPayment.PaymentDate, supplierPayment.ID, AccountReferenceTypeSupplierPayment, supplierPayment, nil, PubSubMessageActionCreate)
+	supplierPayment.ApprovalStatus, err = holdForApproval(ctx, tx, supplierPayment.approvalSubject())
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	err = publishApprovable(ctx, tx, businessId, supplierPayment.PaymentDate, supplierPayment.ID, AccountReferenceTypeSupplierPayment, supplierPayment.ApprovalStatus, supplierPayment, nil, false)
 	if err != nil {
 		tx.Rollback()
 		return nil, err
@@ -241,10 +284,16 @@ func UpdateSupplierPayment(ctx context.Context, paymentID int, updatedSupplierPa
 	// copy oldSupplierPayment instead of fetching again
 	var existingSupplierPayment = *oldSupplierPayment
 
+	withheld, err := resolveWithholding(ctx, businessId, updatedSupplierPayment.WithholdingTaxId, updatedSupplierPayment.WithholdingTaxAmount, updatedSupplierPayment.Amount)
+	if err != nil {
+		return nil, err
+	}
+
 	db := config.GetDB()
 	tx := db.Begin()
 	// construct paidBills
-	paidBills, err := mapPaidBillsInput(tx, ctx, businessId, updatedSupplierPayment)
+	// a rejected payment gave its bills' balances back, so the edit reserves them afresh
+	paidBills, err := mapPaidBillsInput(tx, ctx, businessId, updatedSupplierPayment, supplierPaymentReserved(oldSupplierPayment.ApprovalStatus))
 	if err != nil {
 		tx.Rollback()
 		return nil, err
@@ -270,6 +319,8 @@ func UpdateSupplierPayment(ctx context.Context, paymentID int, updatedSupplierPa
 	existingSupplierPayment.ExchangeRate = updatedSupplierPayment.ExchangeRate
 	existingSupplierPayment.Amount = updatedSupplierPayment.Amount
 	existingSupplierPayment.BankCharges = updatedSupplierPayment.BankCharges
+	existingSupplierPayment.WithholdingTaxId = updatedSupplierPayment.WithholdingTaxId
+	existingSupplierPayment.WithholdingTaxAmount = withheld
 	existingSupplierPayment.PaymentDate = updatedSupplierPayment.PaymentDate
 	existingSupplierPayment.PaymentModeId = updatedSupplierPayment.PaymentModeId
 	existingSupplierPayment.WithdrawAccountId = updatedSupplierPayment.WithdrawAccountId
@@ -291,7 +342,14 @@ func UpdateSupplierPayment(ctx context.Context, paymentID int, updatedSupplierPa
 
 	oldSupplierPayment.Documents = nil
 	existingSupplierPayment.Documents = nil
-	err = PublishToAccounting(ctx, tx, businessId, existingSupplierPayment.PaymentDate, existingSupplierPayment.ID, AccountReferenceTypeSupplierPayment, existingSupplierPayment, oldSupplierPayment, PubSubMessageActionUpdate)
+	// an edit an approval policy covers is submitted again
+	existingSupplierPayment.ApprovalStatus, err = holdForApproval(ctx, tx, existingSupplierPayment.approvalSubject())
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	err = publishApprovable(ctx, tx, businessId, existingSupplierPayment.PaymentDate, existingSupplierPayment.ID, AccountReferenceTypeSupplierPayment,
+		existingSupplierPayment.ApprovalStatus, existingSupplierPayment, oldSupplierPayment, approvalPosted(oldSupplierPayment.ApprovalStatus))
 	if err != nil {
 		tx.Rollback()
 		return nil, err
@@ -305,7 +363,7 @@ func UpdateSupplierPayment(ctx context.Context, paymentID int, updatedSupplierPa
 	return &existingSupplierPayment, nil
 }
 
-func mapPaidBillsInput(tx *gorm.DB, ctx context.Context, businessId string, input *NewSupplierPayment) ([]SupplierPaidBill, error) {
+func mapPaidBillsInput(tx *gorm.DB, ctx context.Context, businessId string, input *NewSupplierPayment, reserved bool) ([]SupplierPaidBill, error) {
 
 	var supplierPaidBills []SupplierPaidBill
 	var totalBillPaidAmount decimal.Decimal
@@ -354,8 +412,12 @@ func mapPaidBillsInput(tx *gorm.DB, ctx context.Context, businessId string, inpu
 				return nil, err
 			}
 			// unpay previous amount before paying updated amount
-			bill.BillTotalPaidAmount = bill.BillTotalPaidAmount.Sub(existingPaidBill.PaidAmount).Add(paidBillInput.PaidAmount)
-			bill.RemainingBalance = bill.RemainingBalance.Add(existingPaidBill.PaidAmount).Sub(paidBillInput.PaidAmount)
+			previousAmount := existingPaidBill.PaidAmount
+			if !reserved {
+				previousAmount = decimal.Zero
+			}
+			bill.BillTotalPaidAmount = bill.BillTotalPaidAmount.Sub(previousAmount).Add(paidBillInput.PaidAmount)
+			bill.RemainingBalance = bill.RemainingBalance.Add(previousAmount).Sub(paidBillInput.PaidAmount)
 			existingPaidBill.PaidAmount = paidBillInput.PaidAmount
 			if bill.RemainingBalance.IsNegative() {
 				return nil, errors.New("the amount entered is more than the balance for the selected bill number - " + bill.BillNumber)
@@ -399,34 +461,13 @@ func DeleteSupplierPayment(ctx context.Context, id int) (*SupplierPayment, error
 
 	db := config.GetDB()
 	tx := db.Begin()
-	// Adjust BillTotalPaidAmount for each associated PaidBill
-	for _, paidBill := range result.PaidBills {
-		bill, err := utils.FetchModelForChange[Bill](ctx, businessId, paidBill.BillId)
-		if err != nil {
-			tx.Rollback()
-			return nil, err
-		}
-
-		// remainingPaidAmount := bill.BillTotalPaidAmount.Sub(paidBill.PaidAmount)
-		billPaymentAmount := bill.BillTotalPaidAmount
-		billAdvanceAmount := bill.BillTotalAdvanceUsedAmount
-		billCreditAmount := bill.BillTotalCreditUsedAmount
-		remainingPaidAmount := billPaymentAmount.Add(billAdvanceAmount).Add(billCreditAmount).Sub(paidBill.PaidAmount)
-
-		if remainingPaidAmount.IsNegative() {
-			tx.Rollback()
-			return nil, errors.New("resulting BillTotalPaidAmount cannot be negative")
-		}
-		if remainingPaidAmount.GreaterThan(decimal.Zero) {
-			bill.CurrentStatus = BillStatusPartialPaid
-		} else {
-			bill.CurrentStatus = BillStatusConfirmed
-		}
-		bill.BillTotalPaidAmount = billPaymentAmount.Sub(paidBill.PaidAmount)
-		bill.RemainingBalance = bill.RemainingBalance.Add(paidBill.PaidAmount)
-
-		// Update the bill
-		if err := tx.WithContext(ctx).Save(&bill).Error; err != nil {
+	if err := withdrawApproval(ctx, tx, ApprovalDocumentTypeSupplierPayment, id); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	// a rejected payment already released its bills
+	if supplierPaymentReserved(result.ApprovalStatus) {
+		if err := releaseSupplierPaidBills(ctx, tx, businessId, result.PaidBills); err != nil {
 			tx.Rollback()
 			return nil, err
 		}
@@ -450,10 +491,13 @@ func DeleteSupplierPayment(ctx context.Context, id int) (*SupplierPayment, error
 	}
 
 	result.Documents = nil
-	err = PublishToAccounting(ctx, tx, businessId, result.PaymentDate, result.ID, AccountReferenceTypeSupplierPayment, nil, result, PubSubMessageActionDelete)
-	if err != nil {
-		tx.Rollback()
-		return nil, err
+	// a payment still waiting for approval never reached the books
+	if approvalPosted(result.ApprovalStatus) {
+		err = PublishToAccounting(ctx, tx, businessId, result.PaymentDate, result.ID, AccountReferenceTypeSupplierPayment, nil, result, PubSubMessageActionDelete)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
 	}
 
 	if err = tx.Commit().Error; err != nil {
@@ -464,6 +508,39 @@ func DeleteSupplierPayment(ctx context.Context, id int) (*SupplierPayment, error
 
 }
 
+// releaseSupplierPaidBills gives the paid bills back the balance the payment held on them.
+func releaseSupplierPaidBills(ctx context.Context, tx *gorm.DB, businessId string, paidBills []SupplierPaidBill) error {
+	for _, paidBill := range paidBills {
+		bill, err := utils.FetchModelForChange[Bill](ctx, businessId, paidBill.BillId)
+		if err != nil {
+			return err
+		}
+
+		// remainingPaidAmount := bill.BillTotalPaidAmount.Sub(paidBill.PaidAmount)
+		billPaymentAmount := bill.BillTotalPaidAmount
+		billAdvanceAmount := bill.BillTotalAdvanceUsedAmount
+		billCreditAmount := bill.BillTotalCreditUsedAmount
+		remainingPaidAmount := billPaymentAmount.Add(billAdvanceAmount).Add(billCreditAmount).Sub(paidBill.PaidAmount)
+
+		if remainingPaidAmount.IsNegative() {
+			return errors.New("resulting BillTotalPaidAmount cannot be negative")
+		}
+		if remainingPaidAmount.GreaterThan(decimal.Zero) {
+			bill.CurrentStatus = BillStatusPartialPaid
+		} else {
+			bill.CurrentStatus = BillStatusConfirmed
+		}
+		bill.BillTotalPaidAmount = billPaymentAmount.Sub(paidBill.PaidAmount)
+		bill.RemainingBalance = bill.RemainingBalance.Add(paidBill.PaidAmount)
+
+		// Update the bill
+		if err := tx.WithContext(ctx).Save(&bill).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func GetSupplierPayment(ctx context.Context, id int) (*SupplierPayment, error) {
 	db := config.GetDB()
 
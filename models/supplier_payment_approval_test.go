@@ -0,0 +1,151 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+)
+
+// A supplier payment waiting for approval holds its bills' balances. Rejecting it gives them
+// back, editing the rejected payment holds them again, and deleting it releases them once.
+func TestSupplierPayment_RejectReleasesBillBalance(t *testing.T) {
+	ctx, biz, accs := setupRecurringBusiness(t)
+	businessID := biz.ID.String()
+	db := config.GetDB()
+
+	supplier, err := models.CreateSupplier(ctx, &models.NewSupplier{
+		Name:                 "Supplier A",
+		Email:                "supplier@approval.test",
+		CurrencyId:           biz.BaseCurrencyId,
+		ExchangeRate:         decimal.NewFromInt(1),
+		SupplierPaymentTerms: models.PaymentTermsDueOnReceipt,
+	})
+	if err != nil {
+		t.Fatalf("CreateSupplier: %v", err)
+	}
+	var primary models.Warehouse
+	if err := db.WithContext(ctx).Where("business_id = ? AND name = ?", businessID, "Primary Warehouse").First(&primary).Error; err != nil {
+		t.Fatalf("fetch primary warehouse: %v", err)
+	}
+	bill, err := models.CreateBill(ctx, &models.NewBill{
+		SupplierId:       supplier.ID,
+		BranchId:         biz.PrimaryBranchId,
+		BillDate:         time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC),
+		BillPaymentTerms: models.PaymentTermsDueOnReceipt,
+		CurrencyId:       biz.BaseCurrencyId,
+		ExchangeRate:     decimal.NewFromInt(1),
+		WarehouseId:      primary.ID,
+		IsTaxInclusive:   utils.NewFalse(),
+		CurrentStatus:    models.BillStatusConfirmed,
+		Details: []models.NewBillDetail{{
+			Name:            "Office supplies",
+			DetailAccountId: accs[models.AccountCodeOtherExpenses],
+			DetailQty:       decimal.NewFromInt(1),
+			DetailUnitRate:  decimal.NewFromInt(100000),
+		}},
+	})
+	if err != nil {
+		t.Fatalf("CreateBill: %v", err)
+	}
+
+	role, err := models.CreateRole(ctx, &models.NewRole{Name: "Payment approver"})
+	if err != nil {
+		t.Fatalf("CreateRole: %v", err)
+	}
+	if _, err := models.CreateApprovalPolicy(ctx, &models.NewApprovalPolicy{
+		Name:            "All supplier payments",
+		DocumentType:    models.ApprovalDocumentTypeSupplierPayment,
+		ApproverRoleIds: []int{role.ID},
+	}); err != nil {
+		t.Fatalf("CreateApprovalPolicy: %v", err)
+	}
+	approver := models.User{
+		BusinessId: businessID,
+		Username:   "approver@approval.test",
+		Name:       "Approver",
+		Password:   "x",
+		IsActive:   utils.NewTrue(),
+		Role:       models.UserRoleOwner,
+	}
+	if err := db.WithContext(ctx).Create(&approver).Error; err != nil {
+		t.Fatalf("create approver: %v", err)
+	}
+	approverCtx := utils.SetUserIdInContext(ctx, approver.ID)
+
+	checkBill := func(step string, remaining int64, status models.BillStatus) {
+		t.Helper()
+		var saved models.Bill
+		if err := db.WithContext(ctx).First(&saved, bill.ID).Error; err != nil {
+			t.Fatalf("%s: fetch bill: %v", step, err)
+		}
+		if !saved.RemainingBalance.Equal(decimal.NewFromInt(remaining)) || saved.CurrentStatus != status {
+			t.Fatalf("%s: bill = %s %s, want %d %s", step, saved.RemainingBalance, saved.CurrentStatus, remaining, status)
+		}
+	}
+	input := models.NewSupplierPayment{
+		SupplierId:        supplier.ID,
+		BranchId:          biz.PrimaryBranchId,
+		CurrencyId:        biz.BaseCurrencyId,
+		ExchangeRate:      decimal.NewFromInt(1),
+		Amount:            decimal.NewFromInt(40000),
+		PaymentDate:       time.Date(2026, 1, 20, 12, 0, 0, 0, time.UTC),
+		WithdrawAccountId: accs[models.AccountCodePettyCash],
+		PaidBills:         []models.NewPaidBill{{BillId: bill.ID, PaidAmount: decimal.NewFromInt(40000)}},
+	}
+
+	payment, err := models.CreateSupplierPayment(ctx, &input)
+	if err != nil {
+		t.Fatalf("CreateSupplierPayment: %v", err)
+	}
+	if payment.ApprovalStatus == nil || *payment.ApprovalStatus != models.ApprovalStatusPending {
+		t.Fatalf("payment approval status = %v, want pending", payment.ApprovalStatus)
+	}
+	checkBill("pending", 60000, models.BillStatusPartialPaid)
+
+	requests, err := models.ListApprovalRequest(ctx, models.ApprovalDocumentTypeSupplierPayment, payment.ID)
+	if err != nil || len(requests) != 1 {
+		t.Fatalf("ListApprovalRequest = %d, %v; want 1", len(requests), err)
+	}
+	if _, err := models.RejectApprovalRequest(approverCtx, requests[0].ID, "wrong amount"); err != nil {
+		t.Fatalf("RejectApprovalRequest: %v", err)
+	}
+	checkBill("rejected", 100000, models.BillStatusConfirmed)
+
+	var paidBill models.SupplierPaidBill
+	if err := db.WithContext(ctx).Where("supplier_payment_id = ?", payment.ID).First(&paidBill).Error; err != nil {
+		t.Fatalf("fetch paid bill: %v", err)
+	}
+	input.Amount = decimal.NewFromInt(30000)
+	input.PaidBills = []models.NewPaidBill{{PaidBillId: paidBill.ID, BillId: bill.ID, PaidAmount: decimal.NewFromInt(30000)}}
+	if _, err := models.UpdateSupplierPayment(ctx, payment.ID, &input); err != nil {
+		t.Fatalf("UpdateSupplierPayment: %v", err)
+	}
+	checkBill("resubmitted", 70000, models.BillStatusPartialPaid)
+
+	if _, err := models.DeleteSupplierPayment(ctx, payment.ID); err != nil {
+		t.Fatalf("DeleteSupplierPayment: %v", err)
+	}
+	checkBill("withdrawn", 100000, models.BillStatusConfirmed)
+
+	// a rejected payment that is deleted does not release its bills twice
+	input.PaidBills = []models.NewPaidBill{{BillId: bill.ID, PaidAmount: decimal.NewFromInt(30000)}}
+	payment, err = models.CreateSupplierPayment(ctx, &input)
+	if err != nil {
+		t.Fatalf("CreateSupplierPayment: %v", err)
+	}
+	requests, err = models.ListApprovalRequest(ctx, models.ApprovalDocumentTypeSupplierPayment, payment.ID)
+	if err != nil || len(requests) != 1 {
+		t.Fatalf("ListApprovalRequest = %d, %v; want 1", len(requests), err)
+	}
+	if _, err := models.RejectApprovalRequest(approverCtx, requests[0].ID, "duplicate"); err != nil {
+		t.Fatalf("RejectApprovalRequest: %v", err)
+	}
+	if _, err := models.DeleteSupplierPayment(ctx, payment.ID); err != nil {
+		t.Fatalf("DeleteSupplierPayment: %v", err)
+	}
+	checkBill("rejected and deleted", 100000, models.BillStatusConfirmed)
+}
@@ -0,0 +1,640 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// TaxTransaction is the tax a document carries for one tax or tax group, in base currency.
+// Sales invoices and credit notes are output tax, bills, supplier credits and expenses input
+// tax; credit notes and supplier credits are negative.
+// On cash basis an invoice or bill counts in proportion to each customer or supplier payment
+// made on it, at the payment date. Credits applied to it are not cash and do not count;
+// credit notes, supplier credits and expenses count at their own date on either basis.
+type TaxTransaction struct {
+	IsPurchase      bool                 `json:"isPurchase"`
+	ReferenceType   AccountReferenceType `json:"referenceType"`
+	ReferenceId     int                  `json:"referenceId"`
+	ReferenceNumber string               `json:"referenceNumber"`
+	TransactionDate time.Time            `json:"transactionDate"`
+	BranchId        int                  `json:"branchId"`
+	TaxId           int                  `json:"taxId"`
+	TaxType         TaxType              `json:"taxType"`
+	TaxableAmount   decimal.Decimal      `json:"taxableAmount"`
+	TaxAmount       decimal.Decimal      `json:"taxAmount"`
+}
+
+// TaxSummaryLine totals the transactions of one tax or tax group.
+// NetPayable is output tax less input tax; a negative amount is refundable.
+type TaxSummaryLine struct {
+	TaxId            int             `json:"taxId"`
+	TaxType          TaxType         `json:"taxType"`
+	TaxName          string          `json:"taxName"`
+	TaxRate          decimal.Decimal `json:"taxRate"`
+	TaxableSales     decimal.Decimal `json:"taxableSales"`
+	OutputTax        decimal.Decimal `json:"outputTax"`
+	TaxablePurchases decimal.Decimal `json:"taxablePurchases"`
+	InputTax         decimal.Decimal `json:"inputTax"`
+	NetPayable       decimal.Decimal `json:"netPayable"`
+}
+
+// TaxReturn records a filed tax period. Filing posts the net payable from the tax payable
+// account to ClearingAccountId on FiledDate, and sales and purchase documents dated up to
+// the business's TaxFiledThroughDate can no longer be created, edited or deleted.
+// Returns are filed in order; only the latest one can be deleted, which reopens its period.
+type TaxReturn struct {
+	ID                  int             `gorm:"primary_key" json:"id"`
+	BusinessId          string          `gorm:"index;not null" json:"business_id"`
+	BranchId            int             `gorm:"not null" json:"branch_id"`
+	PeriodStartDate     time.Time       `gorm:"not null" json:"period_start_date"`
+	PeriodEndDate       time.Time       `gorm:"not null;index" json:"period_end_date"`
+	ReportBasis         ReportBasis     `gorm:"type:enum('Accrual', 'Cash');not null" json:"report_basis"`
+	FiledDate           time.Time       `gorm:"not null" json:"filed_date"`
+	ReferenceNumber     string          `gorm:"size:255;default:null" json:"reference_number"`
+	Notes               string          `gorm:"type:text;default:null" json:"notes"`
+	TaxPayableAccountId int             `gorm:"not null" json:"tax_payable_account_id"`
+	ClearingAccountId   int             `gorm:"not null" json:"clearing_account_id"`
+	OutputTax           decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"output_tax"`
+	InputTax            decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"input_tax"`
+	NetPayable          decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"net_payable"`
+	FiledBy             int             `gorm:"not null" json:"filed_by"`
+	FiledByName         string          `gorm:"size:100" json:"filed_by_name"`
+	Lines               []TaxReturnLine `gorm:"foreignKey:TaxReturnId" json:"lines"`
+	CreatedAt           time.Time       `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt           time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TaxReturnLine keeps the filed figures of one tax or tax group.
+type TaxReturnLine struct {
+	ID               int             `gorm:"primary_key" json:"id"`
+	TaxReturnId      int             `gorm:"index;not null" json:"tax_return_id"`
+	TaxId            int             `gorm:"not null" json:"tax_id"`
+	TaxType          TaxType         `gorm:"type:enum('I', 'G');not null" json:"tax_type"`
+	TaxName          string          `gorm:"size:100" json:"tax_name"`
+	TaxRate          decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"tax_rate"`
+	TaxableSales     decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"taxable_sales"`
+	OutputTax        decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"output_tax"`
+	TaxablePurchases decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"taxable_purchases"`
+	InputTax         decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"input_tax"`
+	NetPayable       decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"net_payable"`
+}
+
+// NewTaxReturn files the tax period. FiledDate is the date of the settlement entry and has
+// to be after the period.
+type NewTaxReturn struct {
+	PeriodStartDate   MyDateString `json:"period_start_date" binding:"required"`
+	PeriodEndDate     MyDateString `json:"period_end_date" binding:"required"`
+	FiledDate         time.Time    `json:"filed_date" binding:"required"`
+	ClearingAccountId int          `json:"clearing_account_id" binding:"required"`
+	ReferenceNumber   string       `json:"reference_number"`
+	Notes             string       `json:"notes"`
+}
+
+func (obj TaxReturn) GetId() int {
+	return obj.ID
+}
+
+func (obj TaxReturn) CheckTransactionLock(ctx context.Context) error {
+	return validateTransactionLock(ctx, obj.FiledDate, obj.BusinessId, AccountantTransactionLock)
+}
+
+const taxTransactionsSql = `
+SELECT * FROM (
+SELECT
+    0 AS is_purchase,
+    'IV' AS reference_type,
+    iv.id AS reference_id,
+    iv.invoice_number AS reference_number,
+    {{ if .cashBasis }}cp.payment_date{{ else }}iv.invoice_date{{ end }} AS transaction_date,
+    iv.branch_id,
+    dt.detail_tax_id AS tax_id,
+    COALESCE(dt.detail_tax_type, 'I') AS tax_type,
+    ROUND(SUM((CASE
+        WHEN iv.is_tax_inclusive = 1 THEN dt.detail_total_amount - dt.detail_tax_amount
+        ELSE dt.detail_total_amount
+    END) * (CASE
+        WHEN iv.currency_id = @baseCurrencyId THEN 1
+        ELSE iv.exchange_rate
+    END){{ if .cashBasis }} * pi.paid_amount / iv.invoice_total_amount{{ end }}), 4) AS taxable_amount,
+    ROUND(SUM(dt.detail_tax_amount * (CASE
+        WHEN iv.currency_id = @baseCurrencyId THEN 1
+        ELSE iv.exchange_rate
+    END){{ if .cashBasis }} * pi.paid_amount / iv.invoice_total_amount{{ end }}), 4) AS tax_amount
+FROM
+    sales_invoices AS iv
+        JOIN
+    sales_invoice_details AS dt ON dt.sales_invoice_id = iv.id
+    {{- if .cashBasis }}
+        JOIN
+    paid_invoices AS pi ON pi.invoice_id = iv.id
+        JOIN
+    customer_payments AS cp ON cp.id = pi.customer_payment_id
+    {{- end }}
+WHERE
+    iv.business_id = @businessId
+        AND iv.current_status IN ('Confirmed' , 'Partial Paid', 'Paid')
+        AND dt.detail_tax_id > 0
+        {{- if .cashBasis }}
+        AND iv.invoice_total_amount <> 0
+        AND cp.payment_date BETWEEN @fromDate AND @toDate
+        {{- else }}
+        AND iv.invoice_date BETWEEN @fromDate AND @toDate
+        {{- end }}
+        {{- if .branchIds }} AND iv.branch_id IN @branchIds {{- end }}
+        {{- if .taxId }} AND dt.detail_tax_id = @taxId AND COALESCE(dt.detail_tax_type, 'I') = @taxType {{- end }}
+GROUP BY iv.id{{ if .cashBasis }}, cp.id{{ end }}, dt.detail_tax_id, COALESCE(dt.detail_tax_type, 'I')
+UNION ALL
+SELECT
+    0 AS is_purchase,
+    'CN' AS reference_type,
+    cn.id AS reference_id,
+    cn.credit_note_number AS reference_number,
+    cn.credit_note_date AS transaction_date,
+    cn.branch_id,
+    dt.detail_tax_id AS tax_id,
+    COALESCE(dt.detail_tax_type, 'I') AS tax_type,
+    ROUND(-SUM((CASE
+        WHEN cn.is_tax_inclusive = 1 THEN dt.detail_total_amount - dt.detail_tax_amount
+        ELSE dt.detail_total_amount
+    END) * (CASE
+        WHEN cn.currency_id = @baseCurrencyId THEN 1
+        ELSE cn.exchange_rate
+    END)), 4) AS taxable_amount,
+    ROUND(-SUM(dt.detail_tax_amount * (CASE
+        WHEN cn.currency_id = @baseCurrencyId THEN 1
+        ELSE cn.exchange_rate
+    END)), 4) AS tax_amount
+FROM
+    credit_notes AS cn
+        JOIN
+    credit_note_details AS dt ON dt.credit_note_id = cn.id
+WHERE
+    cn.business_id = @businessId
+        AND cn.current_status IN ('Confirmed' , 'Closed')
+        AND dt.detail_tax_id > 0
+        AND cn.credit_note_date BETWEEN @fromDate AND @toDate
+        {{- if .branchIds }} AND cn.branch_id IN @branchIds {{- end }}
+        {{- if .taxId }} AND dt.detail_tax_id = @taxId AND COALESCE(dt.detail_tax_type, 'I') = @taxType {{- end }}
+GROUP BY cn.id, dt.detail_tax_id, COALESCE(dt.detail_tax_type, 'I')
+UNION ALL
+SELECT
+    1 AS is_purchase,
+    'BL' AS reference_type,
+    bl.id AS reference_id,
+    bl.bill_number AS reference_number,
+    {{ if .cashBasis }}sp.payment_date{{ else }}bl.bill_date{{ end }} AS transaction_date,
+    bl.branch_id,
+    dt.detail_tax_id AS tax_id,
+    COALESCE(dt.detail_tax_type, 'I') AS tax_type,
+    ROUND(SUM((CASE
+        WHEN bl.is_tax_inclusive = 1 THEN dt.detail_total_amount - dt.detail_tax_amount
+        ELSE dt.detail_total_amount
+    END) * (CASE
+        WHEN bl.currency_id = @baseCurrencyId THEN 1
+        ELSE bl.exchange_rate
+    END){{ if .cashBasis }} * spb.paid_amount / bl.bill_total_amount{{ end }}), 4) AS taxable_amount,
+    ROUND(SUM(dt.detail_tax_amount * (CASE
+        WHEN bl.currency_id = @baseCurrencyId THEN 1
+        ELSE bl.exchange_rate
+    END){{ if .cashBasis }} * spb.paid_amount / bl.bill_total_amount{{ end }}), 4) AS tax_amount
+FROM
+    bills AS bl
+        JOIN
+    bill_details AS dt ON dt.bill_id = bl.id
+    {{- if .cashBasis }}
+        JOIN
+    supplier_paid_bills AS spb ON spb.bill_id = bl.id
+        JOIN
+    supplier_payments AS sp ON sp.id = spb.supplier_payment_id
+    {{- end }}
+WHERE
+    bl.business_id = @businessId
+        AND bl.current_status IN ('Confirmed' , 'Partial Paid', 'Paid')
+        AND dt.detail_tax_id > 0
+        {{- if .cashBasis }}
+        AND bl.bill_total_amount <> 0
+        AND (sp.approval_status IS NULL OR sp.approval_status = 'APPROVED')
+        AND sp.payment_date BETWEEN @fromDate AND @toDate
+        {{- else }}
+        AND bl.bill_date BETWEEN @fromDate AND @toDate
+        {{- end }}
+        {{- if .branchIds }} AND bl.branch_id IN @branchIds {{- end }}
+        {{- if .taxId }} AND dt.detail_tax_id = @taxId AND COALESCE(dt.detail_tax_type, 'I') = @taxType {{- end }}
+GROUP BY bl.id{{ if .cashBasis }}, sp.id{{ end }}, dt.detail_tax_id, COALESCE(dt.detail_tax_type, 'I')
+UNION ALL
+SELECT
+    1 AS is_purchase,
+    'SC' AS reference_type,
+    sc.id AS reference_id,
+    sc.supplier_credit_number AS reference_number,
+    sc.supplier_credit_date AS transaction_date,
+    sc.branch_id,
+    dt.detail_tax_id AS tax_id,
+    COALESCE(dt.detail_tax_type, 'I') AS tax_type,
+    ROUND(-SUM((CASE
+        WHEN sc.is_tax_inclusive = 1 THEN dt.detail_total_amount - dt.detail_tax_amount
+        ELSE dt.detail_total_amount
+    END) * (CASE
+        WHEN sc.currency_id = @baseCurrencyId THEN 1
+        ELSE sc.exchange_rate
+    END)), 4) AS taxable_amount,
+    ROUND(-SUM(dt.detail_tax_amount * (CASE
+        WHEN sc.currency_id = @baseCurrencyId THEN 1
+        ELSE sc.exchange_rate
+    END)), 4) AS tax_amount
+FROM
+    supplier_credits AS sc
+        JOIN
+    supplier_credit_details AS dt ON dt.supplier_credit_id = sc.id
+WHERE
+    sc.business_id = @businessId
+        AND sc.current_status IN ('Confirmed' , 'Closed')
+        AND dt.detail_tax_id > 0
+        AND sc.supplier_credit_date BETWEEN @fromDate AND @toDate
+        {{- if .branchIds }} AND sc.branch_id IN @branchIds {{- end }}
+        {{- if .taxId }} AND dt.detail_tax_id = @taxId AND COALESCE(dt.detail_tax_type, 'I') = @taxType {{- end }}
+GROUP BY sc.id, dt.detail_tax_id, COALESCE(dt.detail_tax_type, 'I')
+UNION ALL
+SELECT
+    1 AS is_purchase,
+    'EP' AS reference_type,
+    ep.id AS reference_id,
+    ep.expense_number AS reference_number,
+    ep.expense_date AS transaction_date,
+    ep.branch_id,
+    ep.expense_tax_id AS tax_id,
+    COALESCE(ep.expense_tax_type, 'I') AS tax_type,
+    ROUND((CASE
+        WHEN ep.is_tax_inclusive = 1 THEN ep.amount - ep.tax_amount
+        ELSE ep.amount
+    END) * (CASE
+        WHEN ep.currency_id = @baseCurrencyId THEN 1
+        ELSE ep.exchange_rate
+    END), 4) AS taxable_amount,
+    ROUND(ep.tax_amount * (CASE
+        WHEN ep.currency_id = @baseCurrencyId THEN 1
+        ELSE ep.exchange_rate
+    END), 4) AS tax_amount
+FROM
+    expenses AS ep
+WHERE
+    ep.business_id = @businessId
+        AND ep.expense_tax_id > 0
+        AND (ep.approval_status IS NULL OR ep.approval_status = 'APPROVED')
+        AND ep.expense_date BETWEEN @fromDate AND @toDate
+        {{- if .branchIds }} AND ep.branch_id IN @branchIds {{- end }}
+        {{- if .taxId }} AND ep.expense_tax_id = @taxId AND COALESCE(ep.expense_tax_type, 'I') = @taxType {{- end }}
+) AS tax_transactions
+ORDER BY transaction_date, reference_type, reference_id;
+`
+
+// GetTaxTransactions lists the taxed documents of a period, fromDate and toDate being UTC
+// bounds. No branchIds is every branch; taxId 0 is every tax and tax group.
+func GetTaxTransactions(ctx context.Context, business *Business, fromDate time.Time, toDate time.Time, basis ReportBasis, branchIds []int, taxId int, taxType TaxType) ([]*TaxTransaction, error) {
+	sql, err := utils.ExecTemplate(taxTransactionsSql, map[string]interface{}{
+		"cashBasis": basis == ReportBasisCash,
+		"branchIds": len(branchIds) > 0,
+		"taxId":     taxId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	var results []*TaxTransaction
+	if err := db.WithContext(ctx).Raw(sql, map[string]interface{}{
+		"businessId":     business.ID.String(),
+		"fromDate":       fromDate,
+		"toDate":         toDate,
+		"baseCurrencyId": business.BaseCurrencyId,
+		"branchIds":      branchIds,
+		"taxId":          taxId,
+		"taxType":        taxType,
+	}).Scan(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// SummarizeTaxTransactions totals transactions per tax and tax group, taxes first.
+func SummarizeTaxTransactions(transactions []*TaxTransaction) []*TaxSummaryLine {
+	type key struct {
+		taxType TaxType
+		taxId   int
+	}
+	lines := make(map[key]*TaxSummaryLine)
+	for _, t := range transactions {
+		k := key{t.TaxType, t.TaxId}
+		line, ok := lines[k]
+		if !ok {
+			line = &TaxSummaryLine{TaxId: t.TaxId, TaxType: t.TaxType}
+			lines[k] = line
+		}
+		if t.IsPurchase {
+			line.TaxablePurchases = line.TaxablePurchases.Add(t.TaxableAmount)
+			line.InputTax = line.InputTax.Add(t.TaxAmount)
+		} else {
+			line.TaxableSales = line.TaxableSales.Add(t.TaxableAmount)
+			line.OutputTax = line.OutputTax.Add(t.TaxAmount)
+		}
+		line.NetPayable = line.OutputTax.Sub(line.InputTax)
+	}
+
+	results := make([]*TaxSummaryLine, 0, len(lines))
+	for _, line := range lines {
+		results = append(results, line)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].TaxType != results[j].TaxType {
+			return results[i].TaxType == TaxTypeIndividual
+		}
+		return results[i].TaxId < results[j].TaxId
+	})
+	return results
+}
+
+// GetTaxSummary totals a period's taxed documents per tax and tax group, with their names.
+func GetTaxSummary(ctx context.Context, business *Business, fromDate time.Time, toDate time.Time, basis ReportBasis, branchIds []int) ([]*TaxSummaryLine, error) {
+	transactions, err := GetTaxTransactions(ctx, business, fromDate, toDate, basis, branchIds, 0, "")
+	if err != nil {
+		return nil, err
+	}
+	lines := SummarizeTaxTransactions(transactions)
+
+	db := config.GetDB()
+	businessId := business.ID.String()
+	var taxes []Tax
+	if err := db.WithContext(ctx).Where("business_id = ?", businessId).Find(&taxes).Error; err != nil {
+		return nil, err
+	}
+	var taxGroups []TaxGroup
+	if err := db.WithContext(ctx).Where("business_id = ?", businessId).Find(&taxGroups).Error; err != nil {
+		return nil, err
+	}
+	for _, line := range lines {
+		if line.TaxType == TaxTypeGroup {
+			for _, g := range taxGroups {
+				if g.ID == line.TaxId {
+					line.TaxName, line.TaxRate = g.Name, g.Rate
+				}
+			}
+		} else {
+			for _, t := range taxes {
+				if t.ID == line.TaxId {
+					line.TaxName, line.TaxRate = t.Name, t.Rate
+				}
+			}
+		}
+	}
+	return lines, nil
+}
+
+func (input *NewTaxReturn) validate(ctx context.Context, business *Business, taxPayableAccountId int) error {
+	businessId := business.ID.String()
+	fromDate, toDate := time.Time(input.PeriodStartDate), time.Time(input.PeriodEndDate)
+	if toDate.Before(fromDate) {
+		return errors.New("period end date cannot be before period start date")
+	}
+	if business.TaxFiledThroughDate != nil && !fromDate.After(*business.TaxFiledThroughDate) {
+		return errors.New("tax period overlaps a filed tax return")
+	}
+	if !input.FiledDate.After(toDate) {
+		return errors.New("filed date must be after the tax period")
+	}
+	if err := validateTransactionLock(ctx, input.FiledDate, businessId, AccountantTransactionLock); err != nil {
+		return err
+	}
+	if err := utils.ValidateResourceId[Account](ctx, businessId, input.ClearingAccountId); err != nil {
+		return errors.New("clearing account not found")
+	}
+	if input.ClearingAccountId == taxPayableAccountId {
+		return errors.New("clearing account cannot be the tax payable account")
+	}
+	return nil
+}
+
+// FileTaxReturn files the period on the business's report basis, for all branches.
+func FileTaxReturn(ctx context.Context, input *NewTaxReturn) (*TaxReturn, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if _, restricted := utils.GetBranchIdsFromContext(ctx); restricted {
+		return nil, errors.New("tax returns are filed for the whole business")
+	}
+	userId, ok := utils.GetUserIdFromContext(ctx)
+	if !ok {
+		return nil, errors.New("user id is required")
+	}
+	userName, _ := utils.GetUserNameFromContext(ctx)
+
+	business, err := GetBusinessById(ctx, businessId)
+	if err != nil {
+		return nil, err
+	}
+	if err := input.PeriodStartDate.StartOfDayUTCTime(business.Timezone); err != nil {
+		return nil, err
+	}
+	if err := input.PeriodEndDate.EndOfDayUTCTime(business.Timezone); err != nil {
+		return nil, err
+	}
+	systemAccounts, err := GetSystemAccounts(businessId)
+	if err != nil {
+		return nil, err
+	}
+	taxPayableAccountId := systemAccounts[AccountCodeTaxPayable]
+	if err := input.validate(ctx, business, taxPayableAccountId); err != nil {
+		return nil, err
+	}
+
+	fromDate, toDate := time.Time(input.PeriodStartDate), time.Time(input.PeriodEndDate)
+	summary, err := GetTaxSummary(ctx, business, fromDate, toDate, business.ReportBasis, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	taxReturn := TaxReturn{
+		BusinessId:          businessId,
+		BranchId:            business.PrimaryBranchId,
+		PeriodStartDate:     fromDate,
+		PeriodEndDate:       toDate,
+		ReportBasis:         business.ReportBasis,
+		FiledDate:           input.FiledDate,
+		ReferenceNumber:     input.ReferenceNumber,
+		Notes:               input.Notes,
+		TaxPayableAccountId: taxPayableAccountId,
+		ClearingAccountId:   input.ClearingAccountId,
+		FiledBy:             userId,
+		FiledByName:         userName,
+	}
+	for _, line := range summary {
+		taxReturn.OutputTax = taxReturn.OutputTax.Add(line.OutputTax)
+		taxReturn.InputTax = taxReturn.InputTax.Add(line.InputTax)
+		taxReturn.Lines = append(taxReturn.Lines, TaxReturnLine{
+			TaxId:            line.TaxId,
+			TaxType:          line.TaxType,
+			TaxName:          line.TaxName,
+			TaxRate:          line.TaxRate,
+			TaxableSales:     line.TaxableSales,
+			OutputTax:        line.OutputTax,
+			TaxablePurchases: line.TaxablePurchases,
+			InputTax:         line.InputTax,
+			NetPayable:       line.NetPayable,
+		})
+	}
+	taxReturn.NetPayable = taxReturn.OutputTax.Sub(taxReturn.InputTax)
+
+	db := config.GetDB()
+	tx := db.Begin()
+	if err := tx.WithContext(ctx).Create(&taxReturn).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := setTaxFiledThroughDate(ctx, tx, business, &toDate); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if !taxReturn.NetPayable.IsZero() {
+		err = PublishToAccounting(ctx, tx, businessId, taxReturn.FiledDate, taxReturn.ID, AccountReferenceTypeTaxReturn, taxReturn, nil, PubSubMessageActionCreate)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	if err := clearBusinessCache(business); err != nil {
+		return nil, err
+	}
+	return &taxReturn, nil
+}
+
+// DeleteTaxReturn reverses the latest return's settlement and reopens its period.
+func DeleteTaxReturn(ctx context.Context, id int) (*TaxReturn, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	business, err := GetBusinessById(ctx, businessId)
+	if err != nil {
+		return nil, err
+	}
+	result, err := utils.FetchModelForChange[TaxReturn](ctx, businessId, id, "Lines")
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	var previous TaxReturn
+	var later int64
+	if err := db.WithContext(ctx).Model(&TaxReturn{}).
+		Where("business_id = ? AND period_end_date > ?", businessId, result.PeriodEndDate).
+		Count(&later).Error; err != nil {
+		return nil, err
+	}
+	if later > 0 {
+		return nil, errors.New("only the latest tax return can be deleted")
+	}
+	err = db.WithContext(ctx).Where("business_id = ? AND period_end_date < ?", businessId, result.PeriodEndDate).
+		Order("period_end_date DESC").First(&previous).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	var filedThrough *time.Time
+	if previous.ID > 0 {
+		filedThrough = &previous.PeriodEndDate
+	}
+
+	tx := db.Begin()
+	if err := tx.WithContext(ctx).Where("tax_return_id = ?", id).Delete(&TaxReturnLine{}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.WithContext(ctx).Delete(&result).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := setTaxFiledThroughDate(ctx, tx, business, filedThrough); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if !result.NetPayable.IsZero() {
+		err = PublishToAccounting(ctx, tx, businessId, result.FiledDate, result.ID, AccountReferenceTypeTaxReturn, nil, result, PubSubMessageActionDelete)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	if err := clearBusinessCache(business); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func setTaxFiledThroughDate(ctx context.Context, tx *gorm.DB, business *Business, date *time.Time) error {
+	return tx.WithContext(ctx).Model(&Business{}).Where("id = ?", business.ID).
+		UpdateColumn("tax_filed_through_date", date).Error
+}
+
+func clearBusinessCache(business *Business) error {
+	if err := business.RemoveRedis(); err != nil {
+		return err
+	}
+	return utils.ClearRedisAdmin[Business]()
+}
+
+// validateTaxFilingLock rejects sales and purchase documents dated in a filed tax period.
+func validateTaxFilingLock(ctx context.Context, transactionDate time.Time, businessId string) error {
+	business, err := GetBusinessById(ctx, businessId)
+	if err != nil {
+		return err
+	}
+	if business.TaxFiledThroughDate == nil {
+		return nil
+	}
+	tDate, err := utils.ConvertToDate(transactionDate, business.Timezone)
+	if err != nil {
+		return err
+	}
+	fDate, err := utils.ConvertToDate(*business.TaxFiledThroughDate, business.Timezone)
+	if err != nil {
+		return err
+	}
+	if !tDate.After(fDate) {
+		return errors.New("transaction is in a filed tax period")
+	}
+	return nil
+}
+
+func GetTaxReturn(ctx context.Context, id int) (*TaxReturn, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	return utils.FetchModel[TaxReturn](ctx, businessId, id, "Lines")
+}
+
+func ListTaxReturn(ctx context.Context) ([]*TaxReturn, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	db := config.GetDB()
+	var results []*TaxReturn
+	if err := db.WithContext(ctx).Preload("Lines").Where("business_id = ?", businessId).
+		Order("period_end_date DESC").Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
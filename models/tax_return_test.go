@@ -0,0 +1,61 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/shopspring/decimal"
+)
+
+func TestSummarizeTaxTransactions(t *testing.T) {
+	d := decimal.RequireFromString
+	transactions := []*models.TaxTransaction{
+		{TaxId: 2, TaxType: models.TaxTypeGroup, TaxableAmount: d("100"), TaxAmount: d("15")},
+		{TaxId: 1, TaxType: models.TaxTypeIndividual, TaxableAmount: d("200"), TaxAmount: d("10")},
+		// a credit note reduces output tax
+		{TaxId: 1, TaxType: models.TaxTypeIndividual, TaxableAmount: d("-40"), TaxAmount: d("-2")},
+		{TaxId: 1, TaxType: models.TaxTypeIndividual, IsPurchase: true, TaxableAmount: d("60"), TaxAmount: d("3")},
+		// tax 2 and tax group 2 are different lines
+		{TaxId: 2, TaxType: models.TaxTypeIndividual, IsPurchase: true, TaxableAmount: d("80"), TaxAmount: d("4")},
+	}
+
+	lines := models.SummarizeTaxTransactions(transactions)
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3", len(lines))
+	}
+	want := []struct {
+		taxId      int
+		taxType    models.TaxType
+		sales      string
+		outputTax  string
+		purchases  string
+		inputTax   string
+		netPayable string
+	}{
+		{1, models.TaxTypeIndividual, "160", "8", "60", "3", "5"},
+		{2, models.TaxTypeIndividual, "0", "0", "80", "4", "-4"},
+		{2, models.TaxTypeGroup, "100", "15", "0", "0", "15"},
+	}
+	for i, w := range want {
+		line := lines[i]
+		if line.TaxId != w.taxId || line.TaxType != w.taxType {
+			t.Errorf("line %d is %s%d, want %s%d", i, line.TaxType, line.TaxId, w.taxType, w.taxId)
+			continue
+		}
+		for _, c := range []struct {
+			name string
+			got  decimal.Decimal
+			want string
+		}{
+			{"taxable sales", line.TaxableSales, w.sales},
+			{"output tax", line.OutputTax, w.outputTax},
+			{"taxable purchases", line.TaxablePurchases, w.purchases},
+			{"input tax", line.InputTax, w.inputTax},
+			{"net payable", line.NetPayable, w.netPayable},
+		} {
+			if !c.got.Equal(d(c.want)) {
+				t.Errorf("line %d %s = %s, want %s", i, c.name, c.got, c.want)
+			}
+		}
+	}
+}
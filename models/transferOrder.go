@@ -24,7 +24,7 @@ type TransferOrder struct {
 	SourceWarehouseId      int                   `gorm:"index;not null" json:"source_warehouse_id" binding:"required"`
 	DestinationWarehouseId int                   `gorm:"index;not null" json:"destination_warehouse_id" binding:"required"`
 	TotalTransferQty       decimal.Decimal       `gorm:"type:decimal(20,4);default:0" json:"total_transfer_qty"`
-	CurrentStatus          TransferOrderStatus   `gorm:"type:enum('Draft', 'Confirmed', 'Closed');not null" json:"current_status" binding:"required"`
+	CurrentStatus          TransferOrderStatus   `gorm:"type:enum('Draft', 'Confirmed', 'InTransit', 'Closed');not null" json:"current_status" binding:"required"`
 	Documents              []*Document           `gorm:"polymorphic:Reference" json:"documents"`
 	Details                []TransferOrderDetail `gorm:"foreignKey:TransferOrderId" json:"details"`
 	CreatedAt              time.Time             `gorm:"autoCreateTime" json:"created_at"`
@@ -48,11 +48,16 @@ type TransferOrderDetail struct {
 	ProductId       int             `gorm:"default:null" json:"product_id"`
 	ProductType     ProductType     `gorm:"type:enum('S','G','C','V','I');default:S" json:"product_type"`
 	BatchNumber     string          `gorm:"size:100" json:"batch_number"`
+	SerialNumbers   []string        `gorm:"type:text;serializer:json" json:"serial_numbers"`
 	Name            string          `gorm:"size:100" json:"name" binding:"required"`
 	Description     string          `gorm:"size:255;default:null" json:"description"`
 	TransferQty     decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"transfer_qty" binding:"required"`
+	ReceivedQty     decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"received_qty"`
+	ShortQty        decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"short_qty"`
+	DamagedQty      decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"damaged_qty"`
 	CreatedAt       time.Time       `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt       time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+	LineUnit
 }
 
 type NewTransferOrderDetail struct {
@@ -60,10 +65,12 @@ type NewTransferOrderDetail struct {
 	ProductId     int             `json:"product_id"`
 	ProductType   ProductType     `json:"product_type"`
 	BatchNumber   string          `json:"batch_number"`
+	SerialNumbers []string        `json:"serial_numbers"`
 	Name          string          `json:"name"`
 	Description   string          `json:"description"`
 	TransferQty   decimal.Decimal `json:"transfer_qty"`
 	IsDeletedItem *bool           `json:"is_deleted_item"`
+	LineUnit
 }
 
 type TransferOrdersConnection struct {
@@ -107,7 +114,7 @@ func (input NewTransferOrder) validate(ctx context.Context, businessId string, _
 		}
 	}
 
-	// If this transfer is being confirmed, ensure the source warehouse has enough stock on hand
+	// If this transfer is being confirmed or shipped, ensure the source warehouse has enough stock on hand
 	// in the ledger-of-record (stock_histories) as-of transfer date.
 	//
 	// Without this guard, we can end up with:
@@ -115,7 +122,7 @@ func (input NewTransferOrder) validate(ctx context.Context, businessId string, _
 	// - transfer order workflow failing later (no FIFO layers / no ledger), resulting in:
 	//   - no account journals
 	//   - inventory reports missing the transfer
-	if input.CurrentStatus == TransferOrderStatusConfirmed {
+	if input.CurrentStatus == TransferOrderStatusConfirmed || input.CurrentStatus == TransferOrderStatusInTransit {
 		// Prevent "silent confirms" that later produce no journal/stock movement because the worker
 		// drops the message due to posting gate / period locks.
 		//
@@ -155,6 +162,16 @@ func (input NewTransferOrder) validate(ctx context.Context, businessId string, _
 	return nil
 }
 
+func (input *NewTransferOrder) detailsToBaseUnit(ctx context.Context, businessId string) error {
+	for i := range input.Details {
+		d := &input.Details[i]
+		if err := d.LineUnit.toBaseUnit(ctx, businessId, d.ProductType, d.ProductId, &d.TransferQty, nil); err != nil {
+			return fmt.Errorf("%s: %w", d.Name, err)
+		}
+	}
+	return nil
+}
+
 func CreateTransferOrder(ctx context.Context, input *NewTransferOrder) (*TransferOrder, error) {
 	db := config.GetDB()
 
@@ -164,6 +181,9 @@ func CreateTransferOrder(ctx context.Context, input *NewTransferOrder) (*Transfe
 	}
 	logger := config.GetLogger()
 	debug := strings.EqualFold(strings.TrimSpace(os.Getenv("DEBUG_TRANSFER_ORDER")), "true")
+	if err := input.detailsToBaseUnit(ctx, businessId); err != nil {
+		return nil, err
+	}
 	// validate TransferOrder
 	if err := input.validate(ctx, businessId, 0); err != nil {
 		return nil, err
@@ -185,12 +205,14 @@ func CreateTransferOrder(ctx context.Context, input *NewTransferOrder) (*Transfe
 			return nil, errors.New("transfer quantity cannot be zero")
 		}
 		transferItem := TransferOrderDetail{
-			ProductId:   item.ProductId,
-			ProductType: item.ProductType,
-			BatchNumber: item.BatchNumber,
-			Name:        item.Name,
-			Description: item.Description,
-			TransferQty: item.TransferQty,
+			ProductId:     item.ProductId,
+			ProductType:   item.ProductType,
+			BatchNumber:   item.BatchNumber,
+			SerialNumbers: item.SerialNumbers,
+			Name:          item.Name,
+			Description:   item.Description,
+			TransferQty:   item.TransferQty,
+			LineUnit:      item.LineUnit,
 		}
 		// Add the item to the TransferOrder
 		transferItems = append(transferItems, transferItem)
@@ -299,6 +321,11 @@ func CreateTransferOrder(ctx context.Context, input *NewTransferOrder) (*Transfe
 			return nil, err
 		}
 
+		if err := postSerialNumbers(tx.WithContext(ctx), businessId, transferOrder.serialNumberPosting()); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
 		// Write outbox record only when confirmed.
 		if err := PublishToAccounting(ctx, tx, businessId, transferOrder.TransferDate, transferOrder.ID, AccountReferenceTypeTransferOrder, transferOrder, nil, PubSubMessageActionCreate); err != nil {
 			if debug {
@@ -313,6 +340,11 @@ func CreateTransferOrder(ctx context.Context, input *NewTransferOrder) (*Transfe
 			tx.Rollback()
 			return nil, err
 		}
+	} else if requestedStatus == TransferOrderStatusInTransit {
+		if err := shipTransferOrder(ctx, tx, &transferOrder); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
 	} else {
 		// Draft: do not publish posting.
 	}
@@ -558,11 +590,23 @@ func DeleteTransferOrder(ctx context.Context, id int) (*TransferOrder, error) {
 		oldForMsg.Documents = append([]*Document(nil), result.Documents...)
 	}
 
+	// Receipts have posted into the destination; they must be deleted first.
+	if result.IsInTransitTransfer() {
+		var receipts int64
+		if err := config.GetDB().WithContext(ctx).Model(&TransferOrderReceipt{}).Where("business_id = ? AND transfer_order_id = ?", businessId, result.ID).Count(&receipts).Error; err != nil {
+			return nil, err
+		}
+		if receipts > 0 {
+			return nil, errors.New("transfer order has receipts; delete them first")
+		}
+	}
+
 	db := config.GetDB()
 	tx := db.Begin()
 
 	// Reverse stock summaries if confirmed (cache tables only; ledger is handled by worker delete).
-	if result.CurrentStatus == TransferOrderStatusConfirmed {
+	// A shipped order has only taken the stock out of the source warehouse.
+	if result.CurrentStatus == TransferOrderStatusConfirmed || result.CurrentStatus == TransferOrderStatusInTransit {
 		for _, detailItem := range result.Details {
 			if detailItem.ProductId <= 0 {
 				continue
@@ -578,6 +622,9 @@ func DeleteTransferOrder(ctx context.Context, id int) (*TransferOrder, error) {
 					tx.Rollback()
 					return nil, err
 				}
+				if result.CurrentStatus == TransferOrderStatusInTransit {
+					continue
+				}
 				if err := UpdateStockSummaryTransferQtyIn(tx, result.BusinessId, result.DestinationWarehouseId, detailItem.ProductId, string(detailItem.ProductType), detailItem.BatchNumber, detailItem.TransferQty.Neg(), result.TransferDate); err != nil {
 					tx.Rollback()
 					return nil, err
@@ -595,7 +642,11 @@ func DeleteTransferOrder(ctx context.Context, id int) (*TransferOrder, error) {
 		return nil, err
 	}
 
-	if result.CurrentStatus == TransferOrderStatusConfirmed {
+	if result.CurrentStatus == TransferOrderStatusConfirmed || result.CurrentStatus == TransferOrderStatusInTransit {
+		if err := unpostSerialNumbers(tx.WithContext(ctx), businessId, StockReferenceTypeTransferOrder, result.ID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
 		if err := PublishToAccounting(ctx, tx, businessId, result.TransferDate, result.ID, AccountReferenceTypeTransferOrder, nil, &oldForMsg, PubSubMessageActionDelete); err != nil {
 			tx.Rollback()
 			return nil, err
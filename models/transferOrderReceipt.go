@@ -0,0 +1,566 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// TransferOrderReceipt books goods of an in-transit transfer order into the destination
+// warehouse. Quantities that arrive short or damaged are written off to AccountId.
+type TransferOrderReceipt struct {
+	ID              int                          `gorm:"primary_key" json:"id"`
+	BusinessId      string                       `gorm:"index;not null" json:"business_id" binding:"required"`
+	TransferOrderId int                          `gorm:"index;not null" json:"transfer_order_id" binding:"required"`
+	ReceiptNumber   string                       `gorm:"size:255" json:"receipt_number"`
+	ReceiptDate     time.Time                    `gorm:"not null" json:"receipt_date" binding:"required"`
+	AccountId       int                          `gorm:"default:null" json:"account_id"`
+	Notes           string                       `gorm:"type:text;default:null" json:"notes"`
+	Details         []TransferOrderReceiptDetail `gorm:"foreignKey:TransferOrderReceiptId" json:"details"`
+	CreatedAt       time.Time                    `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time                    `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TransferOrderReceiptDetail is one transfer line arriving. UnitCost is the cost the
+// line was shipped at, kept by the accounting worker as the shipment is revalued.
+type TransferOrderReceiptDetail struct {
+	ID                     int             `gorm:"primary_key" json:"id"`
+	TransferOrderReceiptId int             `gorm:"index;not null" json:"transfer_order_receipt_id"`
+	TransferOrderDetailId  int             `gorm:"index;not null" json:"transfer_order_detail_id"`
+	ProductId              int             `gorm:"not null" json:"product_id"`
+	ProductType            ProductType     `gorm:"type:enum('S','G','C','V','I');default:S" json:"product_type"`
+	BatchNumber            string          `gorm:"size:100" json:"batch_number"`
+	Name                   string          `gorm:"size:100" json:"name"`
+	SerialNumbers          []string        `gorm:"type:text;serializer:json" json:"serial_numbers"`
+	WriteOffSerialNumbers  []string        `gorm:"type:text;serializer:json" json:"write_off_serial_numbers"`
+	ReceivedQty            decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"received_qty"`
+	ShortQty               decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"short_qty"`
+	DamagedQty             decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"damaged_qty"`
+	UnitCost               decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"unit_cost"`
+	CreatedAt              time.Time       `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt              time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type NewTransferOrderReceipt struct {
+	TransferOrderId int                             `json:"transfer_order_id"`
+	ReceiptNumber   string                          `json:"receipt_number"`
+	ReceiptDate     time.Time                       `json:"receipt_date"`
+	AccountId       *int                            `json:"account_id"`
+	Notes           string                          `json:"notes"`
+	Details         []NewTransferOrderReceiptDetail `json:"details"`
+}
+
+type NewTransferOrderReceiptDetail struct {
+	TransferOrderDetailId int             `json:"transfer_order_detail_id"`
+	ReceivedQty           decimal.Decimal `json:"received_qty"`
+	ShortQty              decimal.Decimal `json:"short_qty"`
+	DamagedQty            decimal.Decimal `json:"damaged_qty"`
+	SerialNumbers         []string        `json:"serial_numbers"`
+	WriteOffSerialNumbers []string        `json:"write_off_serial_numbers"`
+}
+
+// InTransitTransferResponse is a shipped transfer line still on the road, valued at the
+// cost it left the source warehouse at.
+type InTransitTransferResponse struct {
+	TransferOrderId        int             `json:"transfer_order_id"`
+	OrderNumber            string          `json:"order_number"`
+	TransferDate           time.Time       `json:"transfer_date"`
+	SourceWarehouseId      int             `json:"source_warehouse_id"`
+	DestinationWarehouseId int             `json:"destination_warehouse_id"`
+	TransferOrderDetailId  int             `json:"transfer_order_detail_id"`
+	ProductId              int             `json:"product_id"`
+	ProductType            ProductType     `json:"product_type"`
+	BatchNumber            string          `json:"batch_number"`
+	Name                   string          `json:"name"`
+	ShippedQty             decimal.Decimal `json:"shipped_qty"`
+	ReceivedQty            decimal.Decimal `json:"received_qty"`
+	ShortQty               decimal.Decimal `json:"short_qty"`
+	DamagedQty             decimal.Decimal `json:"damaged_qty"`
+	InTransitQty           decimal.Decimal `json:"in_transit_qty"`
+	UnitCost               decimal.Decimal `json:"unit_cost"`
+	InTransitValue         decimal.Decimal `json:"in_transit_value"`
+}
+
+// InTransitQty is the part of the line that was shipped and has not yet been received,
+// reported short or reported damaged.
+func (d TransferOrderDetail) InTransitQty() decimal.Decimal {
+	return d.TransferQty.Sub(d.ReceivedQty).Sub(d.ShortQty).Sub(d.DamagedQty)
+}
+
+// Receive books arriving quantities against the line, refusing more than is still in
+// transit.
+func (d *TransferOrderDetail) Receive(received, short, damaged decimal.Decimal) error {
+	if received.IsNegative() || short.IsNegative() || damaged.IsNegative() {
+		return fmt.Errorf("%s: received, short and damaged quantities cannot be negative", d.Name)
+	}
+	total := received.Add(short).Add(damaged)
+	if total.IsZero() {
+		return fmt.Errorf("%s: nothing to receive", d.Name)
+	}
+	if total.GreaterThan(d.InTransitQty()) {
+		return fmt.Errorf("%s: only %s is in transit", d.Name, d.InTransitQty())
+	}
+	d.ReceivedQty = d.ReceivedQty.Add(received)
+	d.ShortQty = d.ShortQty.Add(short)
+	d.DamagedQty = d.DamagedQty.Add(damaged)
+	return nil
+}
+
+func (d *TransferOrderDetail) unreceive(line TransferOrderReceiptDetail) {
+	d.ReceivedQty = d.ReceivedQty.Sub(line.ReceivedQty)
+	d.ShortQty = d.ShortQty.Sub(line.ShortQty)
+	d.DamagedQty = d.DamagedQty.Sub(line.DamagedQty)
+}
+
+// IsInTransitTransfer reports whether the order moves through Goods In Transit with
+// separate receipts rather than straight into the destination warehouse.
+func (to TransferOrder) IsInTransitTransfer() bool {
+	return to.CurrentStatus == TransferOrderStatusInTransit || to.CurrentStatus == TransferOrderStatusClosed
+}
+
+// TransferOrderShippedUnitCosts returns, per transfer order line, the unit cost its active
+// transfer-out rows left the source warehouse at.
+func TransferOrderShippedUnitCosts(tx *gorm.DB, businessId string, transferOrderId int) (map[int]decimal.Decimal, error) {
+	var rows []struct {
+		ReferenceDetailId int
+		Qty               decimal.Decimal
+		AssetValue        decimal.Decimal
+	}
+	err := tx.Raw(`
+		SELECT
+		  reference_detail_id,
+		  SUM(ABS(qty)) AS qty,
+		  SUM(ABS(qty) * base_unit_value) AS asset_value
+		FROM stock_histories
+		WHERE business_id = ?
+		  AND reference_type = ?
+		  AND reference_id = ?
+		  AND is_outgoing = 1
+		  AND is_reversal = 0
+		  AND reversed_by_stock_history_id IS NULL
+		GROUP BY reference_detail_id
+	`, businessId, StockReferenceTypeTransferOrder, transferOrderId).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	costs := make(map[int]decimal.Decimal, len(rows))
+	for _, r := range rows {
+		if r.Qty.IsPositive() {
+			costs[r.ReferenceDetailId] = r.AssetValue.DivRound(r.Qty, 4)
+		}
+	}
+	return costs, nil
+}
+
+// ShipTransferOrder sends a draft transfer order on the road.
+func ShipTransferOrder(ctx context.Context, id int) (*TransferOrder, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	transferOrder, err := utils.FetchModel[TransferOrder](ctx, businessId, id, "Details")
+	if err != nil {
+		return nil, err
+	}
+	if transferOrder.CurrentStatus != TransferOrderStatusDraft {
+		return nil, errors.New("only draft transfer orders can be shipped")
+	}
+
+	input := NewTransferOrder{
+		OrderNumber:            transferOrder.OrderNumber,
+		TransferDate:           transferOrder.TransferDate,
+		ReasonId:               transferOrder.ReasonId,
+		SourceWarehouseId:      transferOrder.SourceWarehouseId,
+		DestinationWarehouseId: transferOrder.DestinationWarehouseId,
+		CurrentStatus:          TransferOrderStatusInTransit,
+	}
+	for _, d := range transferOrder.Details {
+		input.Details = append(input.Details, NewTransferOrderDetail{
+			ProductId:     d.ProductId,
+			ProductType:   d.ProductType,
+			BatchNumber:   d.BatchNumber,
+			SerialNumbers: d.SerialNumbers,
+			Name:          d.Name,
+			TransferQty:   d.TransferQty,
+		})
+	}
+	if err := input.validate(ctx, businessId, transferOrder.ID); err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	tx := db.Begin()
+	if err := shipTransferOrder(ctx, tx, transferOrder); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return transferOrder, nil
+}
+
+// shipTransferOrder takes the goods out of the source warehouse. They stay in Goods In
+// Transit until receipts bring them into the destination.
+func shipTransferOrder(ctx context.Context, tx *gorm.DB, transferOrder *TransferOrder) error {
+	if err := tx.WithContext(ctx).Model(transferOrder).Update("CurrentStatus", TransferOrderStatusInTransit).Error; err != nil {
+		return err
+	}
+	transferOrder.CurrentStatus = TransferOrderStatusInTransit
+	if err := ApplyTransferOrderStockForStatusTransition(tx.WithContext(ctx), transferOrder, TransferOrderStatusDraft); err != nil {
+		return err
+	}
+	if err := postSerialNumbers(tx.WithContext(ctx), transferOrder.BusinessId, transferOrder.serialNumberPosting()); err != nil {
+		return err
+	}
+	return PublishToAccounting(ctx, tx, transferOrder.BusinessId, transferOrder.TransferDate, transferOrder.ID, AccountReferenceTypeTransferOrder, transferOrder, nil, PubSubMessageActionCreate)
+}
+
+func (input *NewTransferOrderReceipt) validate(ctx context.Context, businessId string, transferOrder *TransferOrder) error {
+	if transferOrder.CurrentStatus != TransferOrderStatusInTransit {
+		return errors.New("only in-transit transfer orders can be received")
+	}
+	if input.ReceiptDate.Before(transferOrder.TransferDate) {
+		return errors.New("receipt date cannot be before the transfer date")
+	}
+	if len(input.Details) == 0 {
+		return errors.New("receipt must have at least one line")
+	}
+	if err := ValidateTransactionLock(ctx, input.ReceiptDate, businessId, AccountantTransactionLock); err != nil {
+		return err
+	}
+
+	details := make(map[int]*TransferOrderDetail, len(transferOrder.Details))
+	for i := range transferOrder.Details {
+		details[transferOrder.Details[i].ID] = &transferOrder.Details[i]
+	}
+	writtenOff := false
+	for _, line := range input.Details {
+		detail, ok := details[line.TransferOrderDetailId]
+		if !ok {
+			return errors.New("transfer order line not found")
+		}
+		if err := detail.Receive(line.ReceivedQty, line.ShortQty, line.DamagedQty); err != nil {
+			return err
+		}
+		if line.ShortQty.IsPositive() || line.DamagedQty.IsPositive() {
+			writtenOff = true
+		}
+		if err := detail.validateReceivedSerialNumbers(line.SerialNumbers, line.WriteOffSerialNumbers); err != nil {
+			return err
+		}
+		if detail.ProductId > 0 {
+			if err := ValidateValueAdjustment(ctx, businessId, input.ReceiptDate, detail.ProductType, detail.ProductId, &detail.BatchNumber); err != nil {
+				return err
+			}
+		}
+	}
+	if writtenOff {
+		if input.AccountId == nil || *input.AccountId <= 0 {
+			return errors.New("an adjustment account is required for short or damaged quantities")
+		}
+		if err := utils.ValidateResourceId[Account](ctx, businessId, *input.AccountId); err != nil {
+			return errors.New("adjustment account not found")
+		}
+	}
+	return nil
+}
+
+// applyTransferOrderReceiptStock moves received quantities into the destination
+// warehouse's stock summaries; reverse undoes it.
+func applyTransferOrderReceiptStock(tx *gorm.DB, transferOrder *TransferOrder, receipt *TransferOrderReceipt, reverse bool) error {
+	ctx := tx.Statement.Context
+	if err := utils.BusinessLock(ctx, transferOrder.BusinessId, "stockLock", "transferOrderReceipt.go", "applyTransferOrderReceiptStock"); err != nil {
+		return err
+	}
+	for _, line := range receipt.Details {
+		if line.ProductId <= 0 || line.ReceivedQty.IsZero() {
+			continue
+		}
+		product, err := GetProductOrVariant(ctx, string(line.ProductType), line.ProductId)
+		if err != nil {
+			return err
+		}
+		if product.GetInventoryAccountID() <= 0 {
+			continue
+		}
+		qty := line.ReceivedQty
+		if reverse {
+			qty = qty.Neg()
+		}
+		if err := UpdateStockSummaryTransferQtyIn(tx, transferOrder.BusinessId, transferOrder.DestinationWarehouseId, line.ProductId, string(line.ProductType), line.BatchNumber, qty, receipt.ReceiptDate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveTransferOrderReceiptProgress writes the lines' received quantities and closes the
+// order once nothing is left in transit, or reopens it.
+func saveTransferOrderReceiptProgress(ctx context.Context, tx *gorm.DB, transferOrder *TransferOrder) error {
+	status := TransferOrderStatusClosed
+	for _, d := range transferOrder.Details {
+		// column updates skip the detail hooks, which re-post stock for confirmed orders
+		if err := tx.WithContext(ctx).Model(&TransferOrderDetail{}).Where("id = ?", d.ID).UpdateColumns(map[string]interface{}{
+			"received_qty": d.ReceivedQty,
+			"short_qty":    d.ShortQty,
+			"damaged_qty":  d.DamagedQty,
+		}).Error; err != nil {
+			return err
+		}
+		if d.InTransitQty().IsPositive() {
+			status = TransferOrderStatusInTransit
+		}
+	}
+	if status == transferOrder.CurrentStatus {
+		return nil
+	}
+	if err := tx.WithContext(ctx).Model(transferOrder).Update("CurrentStatus", status).Error; err != nil {
+		return err
+	}
+	transferOrder.CurrentStatus = status
+	return nil
+}
+
+func CreateTransferOrderReceipt(ctx context.Context, input *NewTransferOrderReceipt) (*TransferOrderReceipt, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	transferOrder, err := utils.FetchModel[TransferOrder](ctx, businessId, input.TransferOrderId, "Details")
+	if err != nil {
+		return nil, err
+	}
+	if err := input.validate(ctx, businessId, transferOrder); err != nil {
+		return nil, err
+	}
+
+	details := make(map[int]TransferOrderDetail, len(transferOrder.Details))
+	for _, d := range transferOrder.Details {
+		details[d.ID] = d
+	}
+	receipt := TransferOrderReceipt{
+		BusinessId:      businessId,
+		TransferOrderId: transferOrder.ID,
+		ReceiptNumber:   input.ReceiptNumber,
+		ReceiptDate:     input.ReceiptDate,
+		Notes:           input.Notes,
+	}
+	if input.AccountId != nil {
+		receipt.AccountId = *input.AccountId
+	}
+	for _, line := range input.Details {
+		detail := details[line.TransferOrderDetailId]
+		receipt.Details = append(receipt.Details, TransferOrderReceiptDetail{
+			TransferOrderDetailId: detail.ID,
+			ProductId:             detail.ProductId,
+			ProductType:           detail.ProductType,
+			BatchNumber:           detail.BatchNumber,
+			Name:                  detail.Name,
+			ReceivedQty:           line.ReceivedQty,
+			ShortQty:              line.ShortQty,
+			DamagedQty:            line.DamagedQty,
+			SerialNumbers:         line.SerialNumbers,
+			WriteOffSerialNumbers: line.WriteOffSerialNumbers,
+		})
+	}
+
+	db := config.GetDB()
+	tx := db.Begin()
+	if err := tx.WithContext(ctx).Create(&receipt).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := saveTransferOrderReceiptProgress(ctx, tx, transferOrder); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := applyTransferOrderReceiptStock(tx.WithContext(ctx), transferOrder, &receipt, false); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := postSerialNumbers(tx.WithContext(ctx), businessId, receipt.serialNumberPosting(transferOrder)); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := PublishToAccounting(ctx, tx, businessId, receipt.ReceiptDate, receipt.ID, AccountReferenceTypeTransferOrderReceipt, receipt, nil, PubSubMessageActionCreate); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return &receipt, nil
+}
+
+// DeleteTransferOrderReceipt puts a receipt's goods back in transit.
+func DeleteTransferOrderReceipt(ctx context.Context, id int) (*TransferOrderReceipt, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	receipt, err := utils.FetchModel[TransferOrderReceipt](ctx, businessId, id, "Details")
+	if err != nil {
+		return nil, err
+	}
+	transferOrder, err := utils.FetchModel[TransferOrder](ctx, businessId, receipt.TransferOrderId, "Details")
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateTransactionLock(ctx, receipt.ReceiptDate, businessId, AccountantTransactionLock); err != nil {
+		return nil, err
+	}
+	for _, line := range receipt.Details {
+		for i := range transferOrder.Details {
+			if transferOrder.Details[i].ID == line.TransferOrderDetailId {
+				transferOrder.Details[i].unreceive(line)
+			}
+		}
+	}
+
+	oldForMsg := *receipt
+	oldForMsg.Details = append([]TransferOrderReceiptDetail(nil), receipt.Details...)
+
+	db := config.GetDB()
+	tx := db.Begin()
+	if err := saveTransferOrderReceiptProgress(ctx, tx, transferOrder); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := applyTransferOrderReceiptStock(tx.WithContext(ctx), transferOrder, receipt, true); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := unpostSerialNumbers(tx.WithContext(ctx), businessId, StockReferenceTypeTransferOrderReceipt, receipt.ID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.WithContext(ctx).Where("transfer_order_receipt_id = ?", receipt.ID).Delete(&TransferOrderReceiptDetail{}).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.WithContext(ctx).Delete(receipt).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := PublishToAccounting(ctx, tx, businessId, receipt.ReceiptDate, receipt.ID, AccountReferenceTypeTransferOrderReceipt, nil, &oldForMsg, PubSubMessageActionDelete); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+func ListTransferOrderReceipt(ctx context.Context, transferOrderId int) ([]*TransferOrderReceipt, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	db := config.GetDB()
+	var receipts []*TransferOrderReceipt
+	if err := db.WithContext(ctx).Preload("Details").
+		Where("business_id = ? AND transfer_order_id = ?", businessId, transferOrderId).
+		Order("receipt_date, id").
+		Find(&receipts).Error; err != nil {
+		return nil, err
+	}
+	return receipts, nil
+}
+
+// GetInTransitTransferReport lists the shipped transfer lines not yet received, optionally
+// for the orders leaving or arriving at one warehouse.
+func GetInTransitTransferReport(ctx context.Context, warehouseId *int) ([]*InTransitTransferResponse, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	sqlT := `
+SELECT
+	t.id transfer_order_id,
+	t.order_number,
+	t.transfer_date,
+	t.source_warehouse_id,
+	t.destination_warehouse_id,
+	d.id transfer_order_detail_id,
+	d.product_id,
+	d.product_type,
+	d.batch_number,
+	d.name,
+	d.transfer_qty shipped_qty,
+	d.received_qty,
+	d.short_qty,
+	d.damaged_qty,
+	COALESCE(s.qty, 0) costed_qty,
+	COALESCE(s.asset_value, 0) costed_value
+FROM
+	transfer_order_details d
+		JOIN transfer_orders t ON t.id = d.transfer_order_id
+		LEFT JOIN (
+			SELECT
+				reference_id,
+				reference_detail_id,
+				SUM(ABS(qty)) qty,
+				SUM(ABS(qty) * base_unit_value) asset_value
+			FROM stock_histories
+			WHERE business_id = @businessId
+				AND reference_type = 'TO'
+				AND is_outgoing = 1
+				AND is_reversal = 0
+				AND reversed_by_stock_history_id IS NULL
+			GROUP BY reference_id, reference_detail_id
+		) s ON s.reference_id = t.id AND s.reference_detail_id = d.id
+WHERE
+	t.business_id = @businessId
+	AND t.current_status = 'InTransit'
+	AND d.transfer_qty - d.received_qty - d.short_qty - d.damaged_qty > 0
+{{- if .WarehouseId }}
+	AND (t.source_warehouse_id = @warehouseId OR t.destination_warehouse_id = @warehouseId)
+{{- end }}
+ORDER BY t.transfer_date, t.id, d.id
+`
+	sql, err := utils.ExecTemplate(sqlT, map[string]interface{}{
+		"WarehouseId": warehouseId != nil && *warehouseId > 0,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		InTransitTransferResponse
+		CostedQty   decimal.Decimal
+		CostedValue decimal.Decimal
+	}
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Raw(sql, map[string]interface{}{
+		"businessId":  businessId,
+		"warehouseId": warehouseId,
+	}).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	results := make([]*InTransitTransferResponse, 0, len(rows))
+	for _, r := range rows {
+		line := r.InTransitTransferResponse
+		detail := TransferOrderDetail{TransferQty: line.ShippedQty, ReceivedQty: line.ReceivedQty, ShortQty: line.ShortQty, DamagedQty: line.DamagedQty}
+		line.InTransitQty = detail.InTransitQty()
+		if r.CostedQty.IsPositive() {
+			line.UnitCost = r.CostedValue.DivRound(r.CostedQty, 4)
+		}
+		line.InTransitValue = line.InTransitQty.Mul(line.UnitCost)
+		results = append(results, &line)
+	}
+	return results, nil
+}
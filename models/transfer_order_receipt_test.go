@@ -0,0 +1,59 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/shopspring/decimal"
+)
+
+func TestTransferOrderDetailReceive(t *testing.T) {
+	d := decimal.RequireFromString
+	line := models.TransferOrderDetail{Name: "Widget", TransferQty: d("10")}
+
+	if err := line.Receive(d("6"), d("1"), d("0")); err != nil {
+		t.Fatalf("first receipt: %v", err)
+	}
+	if !line.InTransitQty().Equal(d("3")) {
+		t.Fatalf("in transit after first receipt = %s, want 3", line.InTransitQty())
+	}
+
+	for _, tc := range []struct {
+		name                    string
+		received, short, damage string
+	}{
+		{name: "more than in transit", received: "3", short: "0", damage: "1"},
+		{name: "nothing", received: "0", short: "0", damage: "0"},
+		{name: "negative", received: "4", short: "-1", damage: "0"},
+	} {
+		if err := line.Receive(d(tc.received), d(tc.short), d(tc.damage)); err == nil {
+			t.Errorf("%s: expected an error", tc.name)
+		}
+	}
+	if !line.InTransitQty().Equal(d("3")) {
+		t.Fatalf("rejected receipts changed the line: in transit = %s, want 3", line.InTransitQty())
+	}
+
+	if err := line.Receive(d("2"), d("0"), d("1")); err != nil {
+		t.Fatalf("final receipt: %v", err)
+	}
+	if !line.InTransitQty().IsZero() {
+		t.Fatalf("in transit after final receipt = %s, want 0", line.InTransitQty())
+	}
+	if !line.ReceivedQty.Equal(d("8")) || !line.ShortQty.Equal(d("1")) || !line.DamagedQty.Equal(d("1")) {
+		t.Fatalf("received/short/damaged = %s/%s/%s, want 8/1/1", line.ReceivedQty, line.ShortQty, line.DamagedQty)
+	}
+}
+
+func TestTransferOrderIsInTransitTransfer(t *testing.T) {
+	for status, want := range map[models.TransferOrderStatus]bool{
+		models.TransferOrderStatusDraft:     false,
+		models.TransferOrderStatusConfirmed: false,
+		models.TransferOrderStatusInTransit: true,
+		models.TransferOrderStatusClosed:    true,
+	} {
+		if got := (models.TransferOrder{CurrentStatus: status}).IsInTransitTransfer(); got != want {
+			t.Errorf("%s: IsInTransitTransfer = %v, want %v", status, got, want)
+		}
+	}
+}
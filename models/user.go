@@ -51,6 +51,27 @@ type NewUser struct {
 	Branches   string   `json:"branches"`
 }
 
+// ScopeContextToUserBranches limits ctx to the branches a Custom-role user is given, so the
+// branch guard scopes every query made with it. Every entry point that acts for a session
+// user applies it. A stored list with some invalid values keeps its valid branches; one
+// with none left would mean every branch and is refused.
+func ScopeContextToUserBranches(ctx context.Context, user *User) (context.Context, error) {
+	if user.Role != UserRoleCustom {
+		return ctx, nil
+	}
+	branchIds, err := utils.ParseBranchIds(user.Branches)
+	if err != nil {
+		config.LogError(config.GetLogger(), "user.go", "ScopeContextToUserBranches", "ParseBranchIds", user.Branches, err)
+		if len(branchIds) == 0 {
+			return ctx, errors.New("Unauthorized")
+		}
+	}
+	if len(branchIds) > 0 {
+		ctx = utils.SetBranchIdsInContext(ctx, branchIds)
+	}
+	return ctx, nil
+}
+
 /*
 caches:
 	User:$username
@@ -68,6 +68,11 @@ func (input *NewUserAccount) validate(ctx context.Context, businessId string, id
 			return errors.New("invalid role id")
 		}
 	}
+	branches, err := validateBranches(ctx, businessId, input.Branches)
+	if err != nil {
+		return err
+	}
+	input.Branches = branches
 
 	var count int64
 	dbCtx := db.WithContext(ctx).Model(&User{}).Where("business_id = ?", businessId)
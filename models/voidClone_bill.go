@@ -172,6 +172,11 @@ func VoidAndCloneBill(ctx context.Context, businessId string, billId int) (*Bill
 		}
 	}
 
+	if err := unpostSerialNumbers(tx.WithContext(ctx), businessId, StockReferenceTypeBill, oldBill.ID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	// Publish delete/void posting for old bill (outbox record only).
 	if err := PublishToAccounting(ctx, tx, businessId, oldBill.BillDate, oldBill.ID, AccountReferenceTypeBill, nil, oldBill, PubSubMessageActionDelete); err != nil {
 		tx.Rollback()
@@ -140,6 +140,11 @@ func VoidAndCloneCreditNote(ctx context.Context, businessId string, creditNoteId
 		}
 	}
 
+	if err := unpostSerialNumbers(tx.WithContext(ctx), businessId, StockReferenceTypeCreditNote, oldCN.ID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	// Publish delete/void posting for old credit note (outbox record only).
 	if err := PublishToAccounting(ctx, tx, businessId, oldCN.CreditNoteDate, oldCN.ID, AccountReferenceTypeCreditNote, nil, oldCN, PubSubMessageActionDelete); err != nil {
 		tx.Rollback()
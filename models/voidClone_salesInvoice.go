@@ -162,11 +162,20 @@ func VoidAndCloneSalesInvoice(ctx context.Context, businessId string, invoiceId
 		}
 	}
 
+	if err := unpostSerialNumbers(tx.WithContext(ctx), businessId, StockReferenceTypeInvoice, oldInv.ID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	// Publish delete/void posting for old invoice (outbox record only; publish happens after commit).
 	if err := PublishToAccounting(ctx, tx, businessId, oldInv.InvoiceDate, oldInv.ID, AccountReferenceTypeInvoice, nil, oldInv, PubSubMessageActionDelete); err != nil {
 		tx.Rollback()
 		return nil, err
 	}
+	if err := QueueWebhookEvent(ctx, tx, businessId, WebhookEventInvoiceVoided, string(AccountReferenceTypeInvoice), oldInv.ID, oldForTransition); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
 
 	if err := tx.Commit().Error; err != nil {
 		return nil, err
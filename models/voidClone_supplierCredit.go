@@ -139,6 +139,11 @@ func VoidAndCloneSupplierCredit(ctx context.Context, businessId string, supplier
 		}
 	}
 
+	if err := unpostSerialNumbers(tx.WithContext(ctx), businessId, StockReferenceTypeSupplierCredit, oldSC.ID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	// Publish delete/void posting for old supplier credit (outbox record only).
 	if err := PublishToAccounting(ctx, tx, businessId, oldSC.SupplierCreditDate, oldSC.ID, AccountReferenceTypeSupplierCredit, nil, oldSC, PubSubMessageActionDelete); err != nil {
 		tx.Rollback()
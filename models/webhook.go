@@ -0,0 +1,486 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/mmdatafocus/books_backend/webhook"
+	"gorm.io/gorm"
+)
+
+type WebhookEvent string
+
+const (
+	WebhookEventInvoiceCreated   WebhookEvent = "invoice.created"
+	WebhookEventInvoiceConfirmed WebhookEvent = "invoice.confirmed"
+	WebhookEventInvoiceVoided    WebhookEvent = "invoice.voided"
+	WebhookEventPaymentReceived  WebhookEvent = "payment.received"
+	WebhookEventBillConfirmed    WebhookEvent = "bill.confirmed"
+	WebhookEventStockBelowZero   WebhookEvent = "stock.below_zero"
+	WebhookEventJournalPosted    WebhookEvent = "journal.posted"
+)
+
+var webhookEvents = []WebhookEvent{
+	WebhookEventInvoiceCreated,
+	WebhookEventInvoiceConfirmed,
+	WebhookEventInvoiceVoided,
+	WebhookEventPaymentReceived,
+	WebhookEventBillConfirmed,
+	WebhookEventStockBelowZero,
+	WebhookEventJournalPosted,
+}
+
+func (e WebhookEvent) IsValid() bool {
+	for _, event := range webhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookEndpoint is a URL a business registered to receive events.
+// Events is a comma separated list of subscribed WebhookEvent values.
+type WebhookEndpoint struct {
+	ID          int       `gorm:"primary_key" json:"id"`
+	BusinessId  string    `gorm:"index;not null" json:"business_id"`
+	Url         string    `gorm:"size:500;not null" json:"url"`
+	Description string    `gorm:"size:255" json:"description"`
+	Secret      string    `gorm:"size:100;not null" json:"secret"`
+	Events      string    `gorm:"size:500;not null" json:"events"`
+	IsActive    *bool     `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type NewWebhookEndpoint struct {
+	Url         string   `json:"url"`
+	Description string   `json:"description"`
+	Events      []string `json:"events"`
+}
+
+// WebhookDelivery is one event queued for one endpoint. Rows are posted by the webhook
+// dispatcher with retries, the same way EmailMessage rows are sent, and are kept as the
+// delivery log. Payload is the exact JSON body that is signed and sent.
+type WebhookDelivery struct {
+	ID             int                   `gorm:"primary_key;index:idx_webhook_dispatch,priority:3" json:"id"`
+	BusinessId     string                `gorm:"index;not null" json:"business_id"`
+	EndpointId     int                   `gorm:"index;not null" json:"endpoint_id"`
+	EventId        string                `gorm:"size:36;not null;index" json:"event_id"`
+	EventType      WebhookEvent          `gorm:"size:50;not null" json:"event_type"`
+	ReferenceType  string                `gorm:"size:50" json:"reference_type"`
+	ReferenceId    int                   `json:"reference_id"`
+	Payload        string                `gorm:"type:mediumtext;not null" json:"payload"`
+	Status         WebhookDeliveryStatus `gorm:"size:20;not null;default:'Pending';index:idx_webhook_dispatch,priority:1" json:"status"`
+	Attempts       int                   `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  *time.Time            `gorm:"index:idx_webhook_dispatch,priority:2" json:"next_attempt_at"`
+	LockedAt       *time.Time            `json:"locked_at"`
+	LockedBy       *string               `gorm:"size:100" json:"locked_by"`
+	LastError      *string               `gorm:"type:text" json:"last_error"`
+	ResponseStatus int                   `gorm:"default:0" json:"response_status"`
+	ResponseBody   *string               `gorm:"type:text" json:"response_body"`
+	DurationMs     int                   `gorm:"default:0" json:"duration_ms"`
+	DeliveredAt    *time.Time            `json:"delivered_at"`
+	RedeliveryOfId int                   `gorm:"default:0" json:"redelivery_of_id"`
+	CreatedAt      time.Time             `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time             `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type WebhookDeliveriesConnection struct {
+	Edges    []*WebhookDeliveriesEdge `json:"edges"`
+	PageInfo *PageInfo                `json:"pageInfo"`
+}
+
+type WebhookDeliveriesEdge Edge[WebhookDelivery]
+
+// webhookPayload is the JSON body of every delivery.
+type webhookPayload struct {
+	Id         string          `json:"id"`
+	Type       WebhookEvent    `json:"type"`
+	BusinessId string          `json:"business_id"`
+	CreatedAt  time.Time       `json:"created_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+func (d WebhookDelivery) GetId() int {
+	return d.ID
+}
+
+func (d WebhookDelivery) GetCursor() string {
+	return d.CreatedAt.String()
+}
+
+// EventList returns the subscribed events.
+func (e WebhookEndpoint) EventList() []string {
+	var events []string
+	for _, event := range strings.Split(e.Events, ",") {
+		if event = strings.TrimSpace(event); event != "" {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func (e WebhookEndpoint) subscribes(event WebhookEvent) bool {
+	for _, subscribed := range e.EventList() {
+		if subscribed == string(event) {
+			return true
+		}
+	}
+	return false
+}
+
+func (input *NewWebhookEndpoint) validate(ctx context.Context) error {
+	input.Url = strings.TrimSpace(input.Url)
+	u, err := url.Parse(input.Url)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return errors.New("url must be an absolute http or https url")
+	}
+	if u.User != nil {
+		return errors.New("url must not contain credentials")
+	}
+	if err := webhook.CheckURL(ctx, input.Url); err != nil {
+		return err
+	}
+	if len(input.Events) == 0 {
+		return errors.New("subscribe to at least one event")
+	}
+	seen := make(map[string]bool)
+	var events []string
+	for _, event := range input.Events {
+		event = strings.TrimSpace(event)
+		if !WebhookEvent(event).IsValid() {
+			return fmt.Errorf("unknown event %q", event)
+		}
+		if !seen[event] {
+			seen[event] = true
+			events = append(events, event)
+		}
+	}
+	sort.Strings(events)
+	input.Events = events
+	return nil
+}
+
+func CreateWebhookEndpoint(ctx context.Context, input *NewWebhookEndpoint) (*WebhookEndpoint, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if err := input.validate(ctx); err != nil {
+		return nil, err
+	}
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := WebhookEndpoint{
+		BusinessId:  businessId,
+		Url:         input.Url,
+		Description: strings.TrimSpace(input.Description),
+		Secret:      secret,
+		Events:      strings.Join(input.Events, ","),
+		IsActive:    utils.NewTrue(),
+	}
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Create(&endpoint).Error; err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+func UpdateWebhookEndpoint(ctx context.Context, id int, input *NewWebhookEndpoint) (*WebhookEndpoint, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if err := input.validate(ctx); err != nil {
+		return nil, err
+	}
+	endpoint, err := utils.FetchModel[WebhookEndpoint](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Model(endpoint).Updates(map[string]interface{}{
+		"Url":         input.Url,
+		"Description": strings.TrimSpace(input.Description),
+		"Events":      strings.Join(input.Events, ","),
+	}).Error; err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+// DeleteWebhookEndpoint removes an endpoint; its undelivered events are dropped by the dispatcher.
+func DeleteWebhookEndpoint(ctx context.Context, id int) (*WebhookEndpoint, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	endpoint, err := utils.FetchModel[WebhookEndpoint](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Delete(endpoint).Error; err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+func ToggleActiveWebhookEndpoint(ctx context.Context, id int, isActive bool) (*WebhookEndpoint, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	endpoint, err := utils.FetchModel[WebhookEndpoint](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Model(endpoint).Update("IsActive", isActive).Error; err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+// RotateSecretWebhookEndpoint replaces the signing secret. Deliveries sent afterwards,
+// including retries of earlier events, are signed with the new secret.
+func RotateSecretWebhookEndpoint(ctx context.Context, id int) (*WebhookEndpoint, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	endpoint, err := utils.FetchModel[WebhookEndpoint](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Model(endpoint).Update("Secret", secret).Error; err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+func GetWebhookEndpoint(ctx context.Context, id int) (*WebhookEndpoint, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	return utils.FetchModel[WebhookEndpoint](ctx, businessId, id)
+}
+
+func ListWebhookEndpoint(ctx context.Context) ([]*WebhookEndpoint, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	db := config.GetDB()
+	var results []*WebhookEndpoint
+	if err := db.WithContext(ctx).Where("business_id = ?", businessId).Order("id").Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// QueueWebhookEvent writes a delivery for every active endpoint subscribed to the event.
+// Like PublishToAccounting it runs inside the caller's transaction, so an event is only
+// delivered if the change that raised it commits. data is sent as the payload's "data".
+func QueueWebhookEvent(ctx context.Context, tx *gorm.DB, businessId string, event WebhookEvent, referenceType string, referenceId int, data interface{}) error {
+	endpoints, err := webhookSubscribers(ctx, tx, businessId, event)
+	if err != nil || len(endpoints) == 0 {
+		return err
+	}
+	return queueWebhookDeliveries(ctx, tx, endpoints, businessId, event, referenceType, referenceId, data)
+}
+
+// QueueJournalPostedWebhook raises journal.posted once the worker has posted an outbox
+// message. The payload carries the document's active journal, or none when the message
+// only reversed it.
+func QueueJournalPostedWebhook(ctx context.Context, tx *gorm.DB, msg config.PubSubMessage) error {
+	endpoints, err := webhookSubscribers(ctx, tx, msg.BusinessId, WebhookEventJournalPosted)
+	if err != nil || len(endpoints) == 0 {
+		return err
+	}
+
+	var journals []*AccountJournal
+	if err := tx.WithContext(ctx).
+		Where("business_id = ? AND reference_type = ? AND reference_id = ?", msg.BusinessId, msg.ReferenceType, msg.ReferenceId).
+		Where("is_reversal = ? AND reversed_by_journal_id IS NULL", false).
+		Preload("AccountTransactions").
+		Order("id DESC").
+		Limit(1).
+		Find(&journals).Error; err != nil {
+		return err
+	}
+	data := map[string]interface{}{
+		"message_id":     msg.ID,
+		"reference_type": msg.ReferenceType,
+		"reference_id":   msg.ReferenceId,
+		"action":         msg.Action,
+		"journal":        nil,
+	}
+	if len(journals) > 0 {
+		data["journal"] = journals[0]
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return queueWebhookDeliveries(ctx, tx, endpoints, msg.BusinessId, WebhookEventJournalPosted, msg.ReferenceType, msg.ReferenceId, raw)
+}
+
+func webhookSubscribers(ctx context.Context, tx *gorm.DB, businessId string, event WebhookEvent) ([]*WebhookEndpoint, error) {
+	var endpoints []*WebhookEndpoint
+	if err := tx.WithContext(ctx).
+		Where("business_id = ? AND is_active = ?", businessId, true).
+		Find(&endpoints).Error; err != nil {
+		return nil, err
+	}
+	var subscribed []*WebhookEndpoint
+	for _, endpoint := range endpoints {
+		if endpoint.subscribes(event) {
+			subscribed = append(subscribed, endpoint)
+		}
+	}
+	return subscribed, nil
+}
+
+// queueWebhookDeliveries builds the payload once; data that is already JSON is sent as is.
+func queueWebhookDeliveries(ctx context.Context, tx *gorm.DB, endpoints []*WebhookEndpoint, businessId string, event WebhookEvent, referenceType string, referenceId int, data interface{}) error {
+	var raw []byte
+	var err error
+	if b, ok := data.([]byte); ok {
+		raw = b
+	} else {
+		raw, err = ToJSONWithoutField(data, "Documents")
+		if err != nil {
+			return err
+		}
+	}
+	payload := webhookPayload{
+		Id:         uuid.NewString(),
+		Type:       event,
+		BusinessId: businessId,
+		CreatedAt:  time.Now().UTC(),
+		Data:       raw,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	deliveries := make([]WebhookDelivery, len(endpoints))
+	for i, endpoint := range endpoints {
+		deliveries[i] = WebhookDelivery{
+			BusinessId:    businessId,
+			EndpointId:    endpoint.ID,
+			EventId:       payload.Id,
+			EventType:     event,
+			ReferenceType: referenceType,
+			ReferenceId:   referenceId,
+			Payload:       string(body),
+			Status:        WebhookDeliveryStatusPending,
+		}
+	}
+	return tx.WithContext(ctx).Create(&deliveries).Error
+}
+
+// RedeliverWebhookDelivery queues the same event again as a new delivery, keeping the
+// original in the log. The event id is unchanged so receivers can deduplicate.
+func RedeliverWebhookDelivery(ctx context.Context, id int) (*WebhookDelivery, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	original, err := utils.FetchModel[WebhookDelivery](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+	if original.Status == WebhookDeliveryStatusPending || original.Status == WebhookDeliveryStatusProcessing {
+		return nil, errors.New("delivery is still in progress")
+	}
+	if _, err := utils.FetchModel[WebhookEndpoint](ctx, businessId, original.EndpointId); err != nil {
+		return nil, err
+	}
+
+	delivery := WebhookDelivery{
+		BusinessId:     businessId,
+		EndpointId:     original.EndpointId,
+		EventId:        original.EventId,
+		EventType:      original.EventType,
+		ReferenceType:  original.ReferenceType,
+		ReferenceId:    original.ReferenceId,
+		Payload:        original.Payload,
+		Status:         WebhookDeliveryStatusPending,
+		RedeliveryOfId: original.ID,
+	}
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Create(&delivery).Error; err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func GetWebhookDelivery(ctx context.Context, id int) (*WebhookDelivery, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	return utils.FetchModel[WebhookDelivery](ctx, businessId, id)
+}
+
+func PaginateWebhookDelivery(ctx context.Context, limit *int, after *string,
+	endpointId *int,
+	eventType *string,
+	status *WebhookDeliveryStatus) (*WebhookDeliveriesConnection, error) {
+
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	db := config.GetDB()
+	dbCtx := db.WithContext(ctx).Where("business_id = ?", businessId)
+	if endpointId != nil && *endpointId > 0 {
+		dbCtx.Where("endpoint_id = ?", *endpointId)
+	}
+	if eventType != nil && *eventType != "" {
+		dbCtx.Where("event_type = ?", *eventType)
+	}
+	if status != nil {
+		dbCtx.Where("status = ?", *status)
+	}
+
+	edges, pageInfo, err := FetchPageCompositeCursor[WebhookDelivery](dbCtx, *limit, after, "created_at", "<")
+	if err != nil {
+		return nil, err
+	}
+	var webhookDeliveriesConnection WebhookDeliveriesConnection
+	webhookDeliveriesConnection.PageInfo = pageInfo
+	for _, edge := range edges {
+		webhookDeliveriesEdge := WebhookDeliveriesEdge(edge)
+		webhookDeliveriesConnection.Edges = append(webhookDeliveriesConnection.Edges, &webhookDeliveriesEdge)
+	}
+	return &webhookDeliveriesConnection, err
+}
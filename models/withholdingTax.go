@@ -0,0 +1,191 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+)
+
+// WithholdingTax is a tax code withheld at payment time. Tax withheld from a supplier
+// is owed to the tax office (payable account); tax a customer withheld from us can be
+// claimed back (receivable account).
+type WithholdingTax struct {
+	ID                  int             `gorm:"primary_key" json:"id"`
+	BusinessId          string          `gorm:"index;not null" json:"business_id" binding:"required"`
+	Name                string          `gorm:"size:100;not null" json:"name" binding:"required"`
+	Rate                decimal.Decimal `gorm:"type:decimal(20,4);default:0" json:"rate" binding:"required"`
+	PayableAccountId    int             `gorm:"not null" json:"payable_account_id" binding:"required"`
+	ReceivableAccountId int             `gorm:"not null" json:"receivable_account_id" binding:"required"`
+	CreatedAt           time.Time       `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt           time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type NewWithholdingTax struct {
+	Name                string          `json:"name" binding:"required"`
+	Rate                decimal.Decimal `json:"rate" binding:"required"`
+	PayableAccountId    int             `json:"payable_account_id" binding:"required"`
+	ReceivableAccountId int             `json:"receivable_account_id" binding:"required"`
+}
+
+func (input NewWithholdingTax) validate(ctx context.Context, businessId string, id int) error {
+	if err := utils.ValidateUnique[WithholdingTax](ctx, businessId, "name", input.Name, id); err != nil {
+		return err
+	}
+	if !input.Rate.IsPositive() || input.Rate.GreaterThan(decimal.NewFromInt(100)) {
+		return errors.New("rate must be between 0 and 100")
+	}
+	count, err := utils.ResourceCountWhere[Account](ctx, businessId, "id = ? AND main_type = 'Liability'", input.PayableAccountId)
+	if err != nil {
+		return err
+	}
+	if count <= 0 {
+		return errors.New("payable account must be a liability account")
+	}
+	count, err = utils.ResourceCountWhere[Account](ctx, businessId, "id = ? AND main_type = 'Asset'", input.ReceivableAccountId)
+	if err != nil {
+		return err
+	}
+	if count <= 0 {
+		return errors.New("receivable account must be an asset account")
+	}
+	return nil
+}
+
+func (w *WithholdingTax) assign(input *NewWithholdingTax) {
+	w.Name = input.Name
+	w.Rate = input.Rate
+	w.PayableAccountId = input.PayableAccountId
+	w.ReceivableAccountId = input.ReceivableAccountId
+}
+
+// resolveWithholding validates the tax withheld on a payment of paymentAmount and returns
+// the amount withheld, defaulting to the code's rate when none is entered.
+func resolveWithholding(ctx context.Context, businessId string, withholdingTaxId int, withholdingTaxAmount decimal.Decimal, paymentAmount decimal.Decimal) (decimal.Decimal, error) {
+	if withholdingTaxId == 0 {
+		if !withholdingTaxAmount.IsZero() {
+			return decimal.Zero, errors.New("withholding tax is required for a withheld amount")
+		}
+		return decimal.Zero, nil
+	}
+	withholdingTax, err := utils.FetchModel[WithholdingTax](ctx, businessId, withholdingTaxId)
+	if err != nil {
+		return decimal.Zero, errors.New("withholding tax not found")
+	}
+	if withholdingTaxAmount.IsZero() {
+		withholdingTaxAmount = paymentAmount.Mul(withholdingTax.Rate).DivRound(decimal.NewFromInt(100), 4)
+	}
+	if withholdingTaxAmount.IsNegative() {
+		return decimal.Zero, errors.New("the withheld amount cannot be negative")
+	}
+	if withholdingTaxAmount.GreaterThanOrEqual(paymentAmount) {
+		return decimal.Zero, errors.New("the withheld amount must be less than the payment amount")
+	}
+	return withholdingTaxAmount, nil
+}
+
+func CreateWithholdingTax(ctx context.Context, input *NewWithholdingTax) (*WithholdingTax, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if err := input.validate(ctx, businessId, 0); err != nil {
+		return nil, err
+	}
+
+	withholdingTax := WithholdingTax{BusinessId: businessId}
+	withholdingTax.assign(input)
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Create(&withholdingTax).Error; err != nil {
+		return nil, err
+	}
+	return &withholdingTax, nil
+}
+
+// UpdateWithholdingTax applies to payments saved afterwards; amounts already withheld are kept.
+func UpdateWithholdingTax(ctx context.Context, id int, input *NewWithholdingTax) (*WithholdingTax, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	if err := input.validate(ctx, businessId, id); err != nil {
+		return nil, err
+	}
+
+	existing, err := utils.FetchModel[WithholdingTax](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+	existing.assign(input)
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Save(existing).Error; err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+func DeleteWithholdingTax(ctx context.Context, id int) (*WithholdingTax, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	result, err := utils.FetchModel[WithholdingTax](ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := utils.ResourceCountWhere[SupplierPayment](ctx, businessId, "withholding_tax_id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("used by supplier payment")
+	}
+	count, err = utils.ResourceCountWhere[CustomerPayment](ctx, businessId, "withholding_tax_id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("used by customer payment")
+	}
+
+	db := config.GetDB()
+	if err := db.WithContext(ctx).Delete(result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func GetWithholdingTax(ctx context.Context, id int) (*WithholdingTax, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	return utils.FetchModel[WithholdingTax](ctx, businessId, id)
+}
+
+func ListWithholdingTax(ctx context.Context, name *string) ([]*WithholdingTax, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+
+	db := config.GetDB()
+	dbCtx := db.WithContext(ctx).Where("business_id = ?", businessId)
+	if name != nil && len(*name) > 0 {
+		dbCtx = dbCtx.Where("name LIKE ?", "%"+*name+"%")
+	}
+
+	var results []*WithholdingTax
+	if err := dbCtx.Order("name").Find(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,284 @@
+package pdfrender
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mmdatafocus/books_backend/models"
+)
+
+// Complex scripts
+//
+// fpdf draws one glyph per code point, which is not enough for Myanmar: stacked consonants,
+// medials and pre-base vowel signs need a shaping engine. Documents with Myanmar text are
+// printed by a headless Chrome or Chromium instead, which shapes text with HarfBuzz.
+// PDF_BROWSER_PATH points at the browser binary; without it such documents fail with
+// ErrShapingUnavailable rather than come out garbled.
+
+// ErrShapingUnavailable is returned for a document whose text needs shaping when no
+// browser is configured to render it.
+var ErrShapingUnavailable = errors.New("document has Myanmar text, which needs PDF_BROWSER_PATH set to a headless Chrome or Chromium to render")
+
+// browserTimeout bounds one browser run.
+const browserTimeout = time.Minute
+
+// needsShaping reports whether any text printed for doc is in a script fpdf cannot lay out.
+func needsShaping(doc *Document, opts Options) bool {
+	texts := []string{doc.Title, doc.Number, doc.PartyLabel, doc.LineHeader, doc.Notes, doc.Terms,
+		doc.Issuer.Name, doc.Issuer.TaxId, doc.Party.Name, doc.Party.TaxId, opts.Title, opts.FooterText}
+	texts = append(texts, doc.Issuer.Lines...)
+	texts = append(texts, doc.Party.Lines...)
+	for _, f := range doc.Fields {
+		texts = append(texts, f.Label, f.Value)
+	}
+	for _, l := range doc.Lines {
+		texts = append(texts, l.Name, l.Description)
+	}
+	for _, t := range doc.Totals {
+		texts = append(texts, t.Label)
+	}
+	for _, text := range texts {
+		for _, c := range text {
+			if unicode.Is(unicode.Myanmar, c) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// renderInBrowser prints doc as HTML to PDF with the browser at PDF_BROWSER_PATH.
+func renderInBrowser(doc *Document, opts Options) ([]byte, error) {
+	browser := strings.TrimSpace(os.Getenv("PDF_BROWSER_PATH"))
+	if browser == "" {
+		return nil, ErrShapingUnavailable
+	}
+	page, err := documentHTML(doc, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "pdfrender-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	input := filepath.Join(dir, "document.html")
+	output := filepath.Join(dir, "document.pdf")
+	if err := os.WriteFile(input, page, 0o600); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), browserTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, browser,
+		"--headless",
+		"--no-sandbox",
+		"--disable-gpu",
+		"--no-pdf-header-footer",
+		"--user-data-dir="+filepath.Join(dir, "profile"),
+		"--print-to-pdf="+output,
+		"file://"+input,
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("PDF_BROWSER_PATH: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		return nil, fmt.Errorf("PDF_BROWSER_PATH: no PDF written: %w", err)
+	}
+	return data, nil
+}
+
+type htmlPage struct {
+	Doc      *Document
+	Opts     Options
+	Title    string
+	Accent   string
+	Page     string
+	FontFace template.CSS
+	Issuer   []string
+	Headers  []htmlCell
+	Rows     []htmlRow
+	Totals   []htmlTotal
+	Sections []Field
+}
+
+type htmlCell struct {
+	Text  string
+	Align string
+	Width string
+}
+
+type htmlRow struct {
+	Number      string
+	Name        string
+	Description string
+	Cells       []htmlCell
+}
+
+type htmlTotal struct {
+	Label  string
+	Amount string
+	Strong bool
+}
+
+// documentHTML lays doc out like Render does, as a page for the browser to print.
+func documentHTML(doc *Document, opts Options) ([]byte, error) {
+	p := htmlPage{
+		Doc:      doc,
+		Opts:     opts,
+		Title:    doc.Title,
+		Accent:   fmt.Sprintf("#%02x%02x%02x", opts.AccentColor[0], opts.AccentColor[1], opts.AccentColor[2]),
+		Page:     opts.PaperSize,
+		FontFace: fontFaceCSS(),
+		Issuer:   doc.Issuer.Lines,
+	}
+	if opts.Title != "" {
+		p.Title = opts.Title
+	}
+	if opts.Landscape {
+		p.Page += " landscape"
+	}
+	if opts.ShowBusinessTaxId && doc.Issuer.TaxId != "" {
+		p.Issuer = append(append([]string{}, p.Issuer...), "Tax ID: "+doc.Issuer.TaxId)
+	}
+	columns := documentColumns(doc, opts)
+	for _, c := range columns {
+		p.Headers = append(p.Headers, htmlCell{Text: c.header, Align: htmlAlign(c.align), Width: htmlWidth(c.width)})
+	}
+	for i, line := range doc.Lines {
+		row := htmlRow{Number: strconv.Itoa(i + 1), Name: line.Name, Description: line.Description}
+		for _, c := range columns[2:] {
+			row.Cells = append(row.Cells, htmlCell{Text: c.value(line), Align: htmlAlign(c.align)})
+		}
+		p.Rows = append(p.Rows, row)
+	}
+	for _, t := range doc.Totals {
+		p.Totals = append(p.Totals, htmlTotal{Label: t.Label, Amount: models.FormatAmount(t.Amount, doc.Currency, doc.DecimalPlaces), Strong: t.Strong})
+	}
+	if opts.ShowNotes && strings.TrimSpace(doc.Notes) != "" {
+		p.Sections = append(p.Sections, Field{Label: "Notes", Value: doc.Notes})
+	}
+	if opts.ShowTerms && strings.TrimSpace(doc.Terms) != "" {
+		p.Sections = append(p.Sections, Field{Label: "Terms & Conditions", Value: doc.Terms})
+	}
+
+	var buf bytes.Buffer
+	if err := pageTemplate.Execute(&buf, p); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func htmlAlign(align string) string {
+	if align == "R" {
+		return "right"
+	}
+	return "left"
+}
+
+func htmlWidth(width float64) string {
+	if width == 0 {
+		return "auto"
+	}
+	return strconv.FormatFloat(width, 'f', -1, 64) + "mm"
+}
+
+// fontFaceCSS declares the PDF_FONT_PATH faces so both renderers print with the same font.
+func fontFaceCSS() template.CSS {
+	path := strings.TrimSpace(os.Getenv("PDF_FONT_PATH"))
+	if path == "" {
+		return ""
+	}
+	css := fmt.Sprintf("@font-face { font-family: %q; src: url(%q); }\n", fontFamily, "file://"+path)
+	if boldPath := strings.TrimSpace(os.Getenv("PDF_FONT_BOLD_PATH")); boldPath != "" {
+		css += fmt.Sprintf("@font-face { font-family: %q; font-weight: bold; src: url(%q); }\n", fontFamily, "file://"+boldPath)
+	}
+	return template.CSS(css)
+}
+
+var pageTemplate = template.Must(template.New("document").Funcs(template.FuncMap{"upper": strings.ToUpper}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Doc.Title}} {{.Doc.Number}}</title>
+<style>
+{{.FontFace}}
+@page {
+	size: {{.Page}};
+	margin: 15mm 15mm 20mm 15mm;
+	@bottom-left { content: "{{.Opts.FooterText}}"; font-size: 8pt; color: #787878; }
+	@bottom-right { content: "Page " counter(page) " of " counter(pages); font-size: 8pt; color: #787878; }
+}
+body { font-family: "doc", "Noto Sans Myanmar", "Pyidaungsu", "Myanmar Text", sans-serif; font-size: 9pt; margin: 0; }
+.header { display: flex; justify-content: space-between; border-bottom: 1px solid {{.Accent}}; padding-bottom: 4mm; margin-bottom: 4mm; }
+.header > div { width: 50%; }
+.issuer-name { font-size: 14pt; font-weight: bold; }
+.title { color: {{.Accent}}; font-size: 20pt; font-weight: bold; text-align: right; }
+.number { font-size: 10pt; font-weight: bold; text-align: right; }
+.fields { margin-left: auto; }
+.fields td { padding: 0 0 0 2mm; text-align: right; }
+.fields td + td { font-weight: bold; }
+.party-label { color: #646464; }
+.party-name { font-size: 11pt; font-weight: bold; }
+.party { margin-bottom: 5mm; }
+table.lines { width: 100%; border-collapse: collapse; }
+table.lines thead { display: table-header-group; }
+table.lines th { background: {{.Accent}}; color: #fff; padding: 1.5mm 1mm; }
+table.lines td { padding: 1mm; border-bottom: 1px solid #dcdcdc; vertical-align: top; }
+table.lines tr { break-inside: avoid; }
+.description { font-size: 8pt; color: #646464; }
+table.totals { margin: 3mm 0 4mm auto; font-size: 10pt; }
+table.totals td { padding: 0.5mm 0 0.5mm 4mm; text-align: right; }
+.strong { font-weight: bold; }
+.section { margin-bottom: 3mm; white-space: pre-wrap; }
+.section-label { font-weight: bold; }
+</style>
+</head>
+<body>
+<div class="header">
+	<div>
+		<div class="issuer-name">{{.Doc.Issuer.Name}}</div>
+		{{range .Issuer}}<div>{{.}}</div>{{end}}
+	</div>
+	<div>
+		<div class="title">{{upper .Title}}</div>
+		<div class="number"># {{.Doc.Number}}</div>
+		<table class="fields">{{range .Doc.Fields}}<tr><td>{{.Label}}:</td><td>{{.Value}}</td></tr>{{end}}</table>
+	</div>
+</div>
+<div class="party">
+	<div class="party-label">{{.Doc.PartyLabel}}</div>
+	<div class="party-name">{{.Doc.Party.Name}}</div>
+	{{range .Doc.Party.Lines}}<div>{{.}}</div>{{end}}
+</div>
+<table class="lines">
+	<thead><tr>{{range .Headers}}<th style="width: {{.Width}}; text-align: {{.Align}}">{{.Text}}</th>{{end}}</tr></thead>
+	<tbody>
+	{{- range .Rows}}
+		<tr>
+			<td>{{.Number}}</td>
+			<td>{{.Name}}{{if .Description}}<div class="description">{{.Description}}</div>{{end}}</td>
+			{{- range .Cells}}
+			<td style="text-align: {{.Align}}">{{.Text}}</td>
+			{{- end}}
+		</tr>
+	{{- end}}
+	</tbody>
+</table>
+<table class="totals">{{range .Totals}}<tr{{if .Strong}} class="strong"{{end}}><td>{{.Label}}</td><td>{{.Amount}}</td></tr>{{end}}</table>
+{{range .Sections}}<div class="section"><div class="section-label">{{.Label}}</div>{{.Value}}</div>{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,47 @@
+package pdfrender
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// The page the browser prints carries the Myanmar text as written, and every template
+// value survives html/template's CSS filtering.
+func TestDocumentHTML(t *testing.T) {
+	doc := &Document{
+		Title:       "Invoice",
+		Number:      "INV-0002",
+		Issuer:      Party{Name: "ရွှေမြန်မာ ကုမ္ပဏီ"},
+		PartyLabel:  "Bill To",
+		Party:       Party{Name: "ဒေါ်ခင်မေ"},
+		ItemColumns: true,
+		Lines:       []Line{{Name: "ကော်ဖီ ထုပ်", Qty: decimal.NewFromInt(2), Rate: decimal.NewFromInt(3500), Amount: decimal.NewFromInt(7000)}},
+		Totals:      []Total{{Label: "စုစုပေါင်း", Amount: decimal.NewFromInt(7000), Strong: true}},
+		Currency:    "MMK",
+	}
+	opts := DefaultOptions()
+	opts.Landscape = true
+	opts.FooterText = `Thank you "friend"`
+	if !needsShaping(doc, opts) {
+		t.Fatal("Myanmar document does not need shaping")
+	}
+	if needsShaping(&Document{Title: "Invoice", Party: Party{Name: "Maung Maung"}}, opts) {
+		t.Fatal("Latin document needs shaping")
+	}
+
+	page, err := documentHTML(doc, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(page)
+	for _, want := range []string{"ရွှေမြန်မာ ကုမ္ပဏီ", "ဒေါ်ခင်မေ", "ကော်ဖီ ထုပ်", "စုစုပေါင်း", "size: A4 landscape", "#2563eb"} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("page is missing %q", want)
+		}
+	}
+	if strings.Contains(html, "ZgotmplZ") {
+		t.Fatal("a template value was rejected by html/template")
+	}
+}
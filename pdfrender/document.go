@@ -0,0 +1,416 @@
+package pdfrender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+)
+
+// Document is the renderer's neutral view of a printable transaction.
+type Document struct {
+	Type          string
+	Title         string
+	Number        string
+	Fields        []Field
+	Issuer        Party
+	PartyLabel    string
+	Party         Party
+	ItemColumns   bool
+	Balances      bool
+	LineHeader    string
+	Lines         []Line
+	Totals        []Total
+	Notes         string
+	Terms         string
+	Currency      string
+	DecimalPlaces models.DecimalPlaces
+}
+
+type Field struct {
+	Label string
+	Value string
+}
+
+type Party struct {
+	Name  string
+	TaxId string
+	Lines []string
+}
+
+// Line is an item row. For documents without item columns (receipts) only
+// Name, Description and Amount are printed; statements add Payment and Balance.
+type Line struct {
+	Name        string
+	Description string
+	Qty         decimal.Decimal
+	Rate        decimal.Decimal
+	Discount    decimal.Decimal
+	Tax         decimal.Decimal
+	Amount      decimal.Decimal
+	Payment     decimal.Decimal
+	Balance     decimal.Decimal
+}
+
+type Total struct {
+	Label  string
+	Amount decimal.Decimal
+	Strong bool
+}
+
+// IsSupportedType reports whether documentType can be rendered on the server.
+func IsSupportedType(documentType string) bool {
+	switch documentType {
+	case models.DocumentTypeInvoice, models.DocumentTypeCreditNote, models.DocumentTypePaymentReceipt,
+		models.DocumentTypeBill, models.DocumentTypeEstimate:
+		return true
+	}
+	return false
+}
+
+// LoadDocument builds the view model of one transaction of the business in ctx.
+func LoadDocument(ctx context.Context, documentType string, id int) (*Document, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	business, err := models.GetBusiness(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc *Document
+	var currencyId int
+	switch documentType {
+	case models.DocumentTypeInvoice:
+		doc, currencyId, err = loadSalesInvoice(ctx, business, id)
+	case models.DocumentTypeCreditNote:
+		doc, currencyId, err = loadCreditNote(ctx, business, id)
+	case models.DocumentTypePaymentReceipt:
+		doc, currencyId, err = loadPaymentReceipt(ctx, business, id)
+	case models.DocumentTypeBill:
+		doc, currencyId, err = loadBill(ctx, business, id)
+	case models.DocumentTypeEstimate:
+		doc, currencyId, err = loadEstimate(ctx, business, id)
+	default:
+		return nil, fmt.Errorf("document type %q cannot be rendered", documentType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	doc.Type = documentType
+	doc.Issuer = businessParty(business)
+	currency, err := utils.FetchModel[models.Currency](ctx, businessId, currencyId)
+	if err != nil {
+		return nil, errors.New("currency not found")
+	}
+	doc.Currency = currency.Symbol
+	doc.DecimalPlaces = currency.DecimalPlaces
+	return doc, nil
+}
+
+func loadSalesInvoice(ctx context.Context, business *models.Business, id int) (*Document, int, error) {
+	businessId := business.ID.String()
+	invoice, err := utils.FetchModel[models.SalesInvoice](ctx, businessId, id, "Details")
+	if err != nil {
+		return nil, 0, err
+	}
+	customer, err := customerParty(ctx, businessId, invoice.CustomerId)
+	if err != nil {
+		return nil, 0, err
+	}
+	doc := &Document{
+		Title:       "Invoice",
+		Number:      invoice.InvoiceNumber,
+		PartyLabel:  "Bill To",
+		Party:       customer,
+		ItemColumns: true,
+		Notes:       invoice.Notes,
+		Terms:       invoice.TermsAndConditions,
+	}
+	doc.Fields = appendField(doc.Fields, "Invoice Date", formatDate(invoice.InvoiceDate, business.Timezone))
+	if invoice.InvoiceDueDate != nil {
+		doc.Fields = appendField(doc.Fields, "Due Date", formatDate(*invoice.InvoiceDueDate, business.Timezone))
+	}
+	doc.Fields = appendField(doc.Fields, "Order Number", invoice.OrderNumber)
+	doc.Fields = appendField(doc.Fields, "Reference", invoice.ReferenceNumber)
+	doc.Fields = appendField(doc.Fields, "Subject", invoice.InvoiceSubject)
+
+	for _, d := range invoice.Details {
+		doc.Lines = append(doc.Lines, Line{
+			Name:        d.Name,
+			Description: d.Description,
+			Qty:         d.DetailQty,
+			Rate:        d.DetailUnitRate,
+			Discount:    d.DetailDiscountAmount,
+			Tax:         d.DetailTaxAmount,
+			Amount:      d.DetailTotalAmount,
+		})
+	}
+	doc.Totals = appendTotals(invoice.InvoiceSubtotal, invoice.InvoiceTotalDiscountAmount, invoice.InvoiceTotalTaxAmount,
+		invoice.ShippingCharges, invoice.AdjustmentAmount, invoice.InvoiceTotalAmount)
+	paid := invoice.InvoiceTotalPaidAmount.Add(invoice.InvoiceTotalCreditUsedAmount).Add(invoice.InvoiceTotalAdvanceUsedAmount)
+	if !paid.IsZero() {
+		doc.Totals = append(doc.Totals, Total{Label: "Payments & Credits", Amount: paid.Neg()})
+	}
+	if !invoice.InvoiceTotalWriteOffAmount.IsZero() {
+		doc.Totals = append(doc.Totals, Total{Label: "Written Off", Amount: invoice.InvoiceTotalWriteOffAmount.Neg()})
+	}
+	doc.Totals = append(doc.Totals, Total{Label: "Balance Due", Amount: invoice.RemainingBalance, Strong: true})
+	return doc, invoice.CurrencyId, nil
+}
+
+func loadCreditNote(ctx context.Context, business *models.Business, id int) (*Document, int, error) {
+	businessId := business.ID.String()
+	creditNote, err := utils.FetchModel[models.CreditNote](ctx, businessId, id, "Details")
+	if err != nil {
+		return nil, 0, err
+	}
+	customer, err := customerParty(ctx, businessId, creditNote.CustomerId)
+	if err != nil {
+		return nil, 0, err
+	}
+	doc := &Document{
+		Title:       "Credit Note",
+		Number:      creditNote.CreditNoteNumber,
+		PartyLabel:  "Credit To",
+		Party:       customer,
+		ItemColumns: true,
+		Notes:       creditNote.Notes,
+		Terms:       creditNote.TermsAndConditions,
+	}
+	doc.Fields = appendField(doc.Fields, "Credit Note Date", formatDate(creditNote.CreditNoteDate, business.Timezone))
+	doc.Fields = appendField(doc.Fields, "Reference", creditNote.ReferenceNumber)
+
+	for _, d := range creditNote.Details {
+		doc.Lines = append(doc.Lines, Line{
+			Name:        d.Name,
+			Description: d.Description,
+			Qty:         d.DetailQty,
+			Rate:        d.DetailUnitRate,
+			Discount:    d.DetailDiscountAmount,
+			Tax:         d.DetailTaxAmount,
+			Amount:      d.DetailTotalAmount,
+		})
+	}
+	doc.Totals = appendTotals(creditNote.CreditNoteSubtotal, creditNote.CreditNoteTotalDiscountAmount, creditNote.CreditNoteTotalTaxAmount,
+		creditNote.ShippingCharges, creditNote.AdjustmentAmount, creditNote.CreditNoteTotalAmount)
+	used := creditNote.CreditNoteTotalUsedAmount.Add(creditNote.CreditNoteTotalRefundAmount)
+	if !used.IsZero() {
+		doc.Totals = append(doc.Totals, Total{Label: "Credits Used & Refunded", Amount: used.Neg()})
+		doc.Totals = append(doc.Totals, Total{Label: "Credits Remaining", Amount: creditNote.CreditNoteTotalAmount.Sub(used), Strong: true})
+	}
+	return doc, creditNote.CurrencyId, nil
+}
+
+func loadBill(ctx context.Context, business *models.Business, id int) (*Document, int, error) {
+	businessId := business.ID.String()
+	bill, err := utils.FetchModel[models.Bill](ctx, businessId, id, "Details")
+	if err != nil {
+		return nil, 0, err
+	}
+	supplier, err := utils.FetchModel[models.Supplier](ctx, businessId, bill.SupplierId)
+	if err != nil {
+		return nil, 0, errors.New("supplier not found")
+	}
+	doc := &Document{
+		Title:       "Bill",
+		Number:      bill.BillNumber,
+		PartyLabel:  "Supplier",
+		Party:       Party{Name: supplier.Name, Lines: contactLines(supplier.Email, supplier.Phone, supplier.Mobile)},
+		ItemColumns: true,
+		Notes:       bill.Notes,
+	}
+	doc.Fields = appendField(doc.Fields, "Bill Date", formatDate(bill.BillDate, business.Timezone))
+	if bill.BillDueDate != nil {
+		doc.Fields = appendField(doc.Fields, "Due Date", formatDate(*bill.BillDueDate, business.Timezone))
+	}
+	doc.Fields = appendField(doc.Fields, "Purchase Order", bill.PurchaseOrderNumber)
+	doc.Fields = appendField(doc.Fields, "Reference", bill.ReferenceNumber)
+
+	for _, d := range bill.Details {
+		doc.Lines = append(doc.Lines, Line{
+			Name:        d.Name,
+			Description: d.Description,
+			Qty:         d.DetailQty,
+			Rate:        d.DetailUnitRate,
+			Discount:    d.DetailDiscountAmount,
+			Tax:         d.DetailTaxAmount,
+			Amount:      d.DetailTotalAmount,
+		})
+	}
+	doc.Totals = appendTotals(bill.BillSubtotal, bill.BillTotalDiscountAmount, bill.BillTotalTaxAmount,
+		decimal.Zero, bill.AdjustmentAmount, bill.BillTotalAmount)
+	paid := bill.BillTotalPaidAmount.Add(bill.BillTotalCreditUsedAmount).Add(bill.BillTotalAdvanceUsedAmount)
+	if !paid.IsZero() {
+		doc.Totals = append(doc.Totals, Total{Label: "Payments & Credits", Amount: paid.Neg()})
+	}
+	doc.Totals = append(doc.Totals, Total{Label: "Balance Due", Amount: bill.BillTotalAmount.Sub(paid), Strong: true})
+	return doc, bill.CurrencyId, nil
+}
+
+func loadEstimate(ctx context.Context, business *models.Business, id int) (*Document, int, error) {
+	businessId := business.ID.String()
+	estimate, err := utils.FetchModel[models.Estimate](ctx, businessId, id, "Details")
+	if err != nil {
+		return nil, 0, err
+	}
+	customer, err := customerParty(ctx, businessId, estimate.CustomerId)
+	if err != nil {
+		return nil, 0, err
+	}
+	doc := &Document{
+		Title:       "Estimate",
+		Number:      estimate.EstimateNumber,
+		PartyLabel:  "Prepared For",
+		Party:       customer,
+		ItemColumns: true,
+		Notes:       estimate.Notes,
+		Terms:       estimate.TermsAndConditions,
+	}
+	doc.Fields = appendField(doc.Fields, "Estimate Date", formatDate(estimate.EstimateDate, business.Timezone))
+	if estimate.ExpiryDate != nil {
+		doc.Fields = appendField(doc.Fields, "Valid Until", formatDate(*estimate.ExpiryDate, business.Timezone))
+	}
+	doc.Fields = appendField(doc.Fields, "Reference", estimate.ReferenceNumber)
+	doc.Fields = appendField(doc.Fields, "Subject", estimate.Subject)
+
+	for _, d := range estimate.Details {
+		doc.Lines = append(doc.Lines, Line{
+			Name:        d.Name,
+			Description: d.Description,
+			Qty:         d.DetailQty,
+			Rate:        d.DetailUnitRate,
+			Discount:    d.DetailDiscountAmount,
+			Tax:         d.DetailTaxAmount,
+			Amount:      d.DetailTotalAmount,
+		})
+	}
+	doc.Totals = appendTotals(estimate.EstimateSubtotal, estimate.EstimateTotalDiscountAmount, estimate.EstimateTotalTaxAmount,
+		estimate.ShippingCharges, estimate.AdjustmentAmount, estimate.EstimateTotalAmount)
+	return doc, estimate.CurrencyId, nil
+}
+
+func loadPaymentReceipt(ctx context.Context, business *models.Business, id int) (*Document, int, error) {
+	businessId := business.ID.String()
+	payment, err := utils.FetchModel[models.CustomerPayment](ctx, businessId, id, "PaidInvoices")
+	if err != nil {
+		return nil, 0, err
+	}
+	customer, err := customerParty(ctx, businessId, payment.CustomerId)
+	if err != nil {
+		return nil, 0, err
+	}
+	doc := &Document{
+		Title:      "Payment Receipt",
+		Number:     payment.PaymentNumber,
+		PartyLabel: "Received From",
+		Party:      customer,
+		LineHeader: "Invoice",
+		Notes:      payment.Notes,
+	}
+	doc.Fields = appendField(doc.Fields, "Payment Date", formatDate(payment.PaymentDate, business.Timezone))
+	if payment.PaymentModeId > 0 {
+		if mode, err := utils.FetchModel[models.PaymentMode](ctx, businessId, payment.PaymentModeId); err == nil {
+			doc.Fields = appendField(doc.Fields, "Payment Mode", mode.Name)
+		}
+	}
+	doc.Fields = appendField(doc.Fields, "Reference", payment.ReferenceNumber)
+
+	invoiceIds := make([]int, 0, len(payment.PaidInvoices))
+	for _, paid := range payment.PaidInvoices {
+		invoiceIds = append(invoiceIds, paid.InvoiceId)
+	}
+	var invoices []models.SalesInvoice
+	if len(invoiceIds) > 0 {
+		if err := config.GetDB().WithContext(ctx).
+			Select("id", "invoice_number", "invoice_date").
+			Where("business_id = ? AND id IN ?", businessId, invoiceIds).
+			Find(&invoices).Error; err != nil {
+			return nil, 0, err
+		}
+	}
+	invoiceById := make(map[int]models.SalesInvoice, len(invoices))
+	for _, invoice := range invoices {
+		invoiceById[invoice.ID] = invoice
+	}
+	for _, paid := range payment.PaidInvoices {
+		invoice := invoiceById[paid.InvoiceId]
+		doc.Lines = append(doc.Lines, Line{
+			Name:        invoice.InvoiceNumber,
+			Description: formatDate(invoice.InvoiceDate, business.Timezone),
+			Amount:      paid.PaidAmount,
+		})
+	}
+
+	if !payment.BankCharges.IsZero() {
+		doc.Totals = append(doc.Totals, Total{Label: "Bank Charges", Amount: payment.BankCharges})
+	}
+	doc.Totals = append(doc.Totals, Total{Label: "Amount Received", Amount: payment.Amount, Strong: true})
+	return doc, payment.CurrencyId, nil
+}
+
+func businessParty(business *models.Business) Party {
+	party := Party{Name: business.Name, TaxId: business.TaxId}
+	if address := strings.TrimSpace(business.Address); address != "" {
+		party.Lines = append(party.Lines, strings.Split(address, "\n")...)
+	}
+	if place := joinNonEmpty(", ", business.City, business.Country); place != "" {
+		party.Lines = append(party.Lines, place)
+	}
+	party.Lines = append(party.Lines, contactLines(business.Email, business.Phone)...)
+	return party
+}
+
+func customerParty(ctx context.Context, businessId string, customerId int) (Party, error) {
+	customer, err := utils.FetchModel[models.Customer](ctx, businessId, customerId)
+	if err != nil {
+		return Party{}, errors.New("customer not found")
+	}
+	return Party{Name: customer.Name, Lines: contactLines(customer.Email, customer.Phone, customer.Mobile)}, nil
+}
+
+func contactLines(values ...string) []string {
+	lines := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			lines = append(lines, v)
+		}
+	}
+	return lines
+}
+
+func joinNonEmpty(sep string, values ...string) string {
+	return strings.Join(contactLines(values...), sep)
+}
+
+func appendField(fields []Field, label string, value string) []Field {
+	if strings.TrimSpace(value) == "" {
+		return fields
+	}
+	return append(fields, Field{Label: label, Value: value})
+}
+
+// appendTotals returns the usual summary block, leaving out zero optional rows.
+func appendTotals(subtotal, discount, tax, shipping, adjustment, total decimal.Decimal) []Total {
+	totals := []Total{{Label: "Sub Total", Amount: subtotal}}
+	if !discount.IsZero() {
+		totals = append(totals, Total{Label: "Discount", Amount: discount.Neg()})
+	}
+	if !tax.IsZero() {
+		totals = append(totals, Total{Label: "Tax", Amount: tax})
+	}
+	if !shipping.IsZero() {
+		totals = append(totals, Total{Label: "Shipping Charges", Amount: shipping})
+	}
+	if !adjustment.IsZero() {
+		totals = append(totals, Total{Label: "Adjustment", Amount: adjustment})
+	}
+	return append(totals, Total{Label: "Total", Amount: total, Strong: true})
+}
@@ -0,0 +1,33 @@
+package pdfrender
+
+import (
+	"time"
+
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/shopspring/decimal"
+)
+
+const dateLayout = "02 Jan 2006"
+
+// formatQty drops trailing zeros so whole quantities print as "3" rather than "3.0000".
+func formatQty(qty decimal.Decimal) string {
+	return qty.String()
+}
+
+// formatOptionalNumber leaves zero amounts blank, as in the debit/credit columns of a statement.
+func formatOptionalNumber(amount decimal.Decimal, places models.DecimalPlaces) string {
+	if amount.IsZero() {
+		return ""
+	}
+	return models.FormatNumber(amount, places)
+}
+
+func formatDate(t time.Time, timezone string) string {
+	if t.IsZero() {
+		return ""
+	}
+	if loc, err := time.LoadLocation(timezone); err == nil && timezone != "" {
+		t = t.In(loc)
+	}
+	return t.Format(dateLayout)
+}
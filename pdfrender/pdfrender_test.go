@@ -0,0 +1,126 @@
+package pdfrender_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/mmdatafocus/books_backend/pdfrender"
+	"github.com/shopspring/decimal"
+)
+
+func TestParseOptions(t *testing.T) {
+	opts := pdfrender.ParseOptions(`{"title":"Tax Invoice","accentColor":"#0a0","paperSize":"letter","showTaxColumn":false,"unknown":{"x":1}}`)
+	if opts.Title != "Tax Invoice" || opts.PaperSize != "Letter" {
+		t.Fatalf("unexpected options: %+v", opts)
+	}
+	if opts.AccentColor != [3]int{0, 170, 0} {
+		t.Fatalf("accent color: got %v", opts.AccentColor)
+	}
+	if opts.ShowTaxColumn || !opts.ShowDiscountColumn {
+		t.Fatalf("column flags: got tax %v discount %v", opts.ShowTaxColumn, opts.ShowDiscountColumn)
+	}
+
+	if got := pdfrender.ParseOptions(`not json`); got != pdfrender.DefaultOptions() {
+		t.Fatalf("invalid config should fall back to defaults, got %+v", got)
+	}
+}
+
+func TestRender(t *testing.T) {
+	doc := &pdfrender.Document{
+		Title:       "Invoice",
+		Number:      "INV-0001",
+		Fields:      []pdfrender.Field{{Label: "Invoice Date", Value: "01 Mar 2024"}},
+		Issuer:      pdfrender.Party{Name: "Acme Trading", Lines: []string{"Yangon"}},
+		PartyLabel:  "Bill To",
+		Party:       pdfrender.Party{Name: "Maung Maung"},
+		ItemColumns: true,
+		Totals:      []pdfrender.Total{{Label: "Total", Amount: decimal.NewFromInt(15000), Strong: true}},
+		Currency:    "MMK",
+	}
+	for i := 0; i < 60; i++ {
+		doc.Lines = append(doc.Lines, pdfrender.Line{
+			Name:   "Item with a fairly long name that needs to wrap inside the item column",
+			Qty:    decimal.NewFromInt(1),
+			Rate:   decimal.NewFromInt(250),
+			Amount: decimal.NewFromInt(250),
+		})
+	}
+
+	data, err := pdfrender.Render(doc, pdfrender.DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF")) {
+		t.Fatal("output is not a PDF")
+	}
+}
+
+func TestRenderStatement(t *testing.T) {
+	doc := &pdfrender.Document{
+		Title:      "Statement of Account",
+		Number:     "01 Mar 2024 to 31 Mar 2024",
+		Issuer:     pdfrender.Party{Name: "Acme Trading"},
+		PartyLabel: "To",
+		Party:      pdfrender.Party{Name: "Customer"},
+		Balances:   true,
+		LineHeader: "Transaction",
+		Lines: []pdfrender.Line{
+			{Name: "Opening Balance", Balance: decimal.NewFromInt(100)},
+			{Name: "Invoice INV-0001", Amount: decimal.NewFromInt(250), Balance: decimal.NewFromInt(350)},
+			{Name: "Payment PAY-0001", Payment: decimal.NewFromInt(300), Balance: decimal.NewFromInt(50)},
+		},
+		Totals:   []pdfrender.Total{{Label: "Balance Due", Amount: decimal.NewFromInt(50), Strong: true}},
+		Currency: "MMK",
+	}
+	data, err := pdfrender.Render(doc, pdfrender.DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF")) {
+		t.Fatal("output is not a PDF")
+	}
+}
+
+// Myanmar text needs shaping, so it is printed by the browser at PDF_BROWSER_PATH and
+// refused rather than drawn unshaped when none is configured.
+func TestRenderMyanmarText(t *testing.T) {
+	doc := &pdfrender.Document{
+		Title:       "Invoice",
+		Number:      "INV-0002",
+		Issuer:      pdfrender.Party{Name: "ရွှေမြန်မာ ကုမ္ပဏီ", Lines: []string{"ရန်ကုန်မြို့"}},
+		PartyLabel:  "Bill To",
+		Party:       pdfrender.Party{Name: "ဒေါ်ခင်မေ", Lines: []string{"မန္တလေးတိုင်းဒေသကြီး"}},
+		ItemColumns: true,
+		Lines: []pdfrender.Line{{
+			Name:   "ကော်ဖီ ထုပ်",
+			Qty:    decimal.NewFromInt(2),
+			Rate:   decimal.NewFromInt(3500),
+			Amount: decimal.NewFromInt(7000),
+		}},
+		Totals:   []pdfrender.Total{{Label: "စုစုပေါင်း", Amount: decimal.NewFromInt(7000), Strong: true}},
+		Notes:    "ကျေးဇူးတင်ပါသည်",
+		Currency: "MMK",
+	}
+	browser := os.Getenv("PDF_BROWSER_PATH")
+	t.Setenv("PDF_BROWSER_PATH", "")
+	if _, err := pdfrender.Render(doc, pdfrender.DefaultOptions()); !errors.Is(err, pdfrender.ErrShapingUnavailable) {
+		t.Fatalf("Render without a browser = %v, want ErrShapingUnavailable", err)
+	}
+
+	if browser == "" {
+		t.Skip("set PDF_BROWSER_PATH to a headless Chrome or Chromium to render Myanmar text")
+	}
+	t.Setenv("PDF_BROWSER_PATH", browser)
+	data, err := pdfrender.Render(doc, pdfrender.DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF")) {
+		t.Fatal("output is not a PDF")
+	}
+	if !bytes.Contains(data, []byte("/FontFile")) {
+		t.Fatal("the browser output embeds no font for the Myanmar text")
+	}
+}
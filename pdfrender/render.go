@@ -0,0 +1,394 @@
+package pdfrender
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/mmdatafocus/books_backend/models"
+)
+
+// Fonts
+//
+// The PDF core fonts only cover Latin-1, so Myanmar (and any other non-Latin) text needs a
+// TrueType font embedded in the file. PDF_FONT_PATH points at the regular face, e.g.
+// NotoSansMyanmar-Regular.ttf or Pyidaungsu.ttf, and PDF_FONT_BOLD_PATH optionally at its bold
+// face. The font should also cover Latin so that numbers and labels render from the same face.
+//
+// fpdf maps each code point to one glyph and runs no shaping engine, which is fine for
+// Latin but not for Myanmar, so documents with Myanmar text are printed by a headless
+// browser instead (see browser.go), using the same font when PDF_FONT_PATH is set.
+const fontFamily = "doc"
+
+var (
+	fontOnce    sync.Once
+	regularFont []byte
+	boldFont    []byte
+	fontErr     error
+)
+
+func loadFonts() ([]byte, []byte, error) {
+	fontOnce.Do(func() {
+		path := strings.TrimSpace(os.Getenv("PDF_FONT_PATH"))
+		if path == "" {
+			return
+		}
+		if regularFont, fontErr = os.ReadFile(path); fontErr != nil {
+			fontErr = fmt.Errorf("PDF_FONT_PATH: %w", fontErr)
+			return
+		}
+		boldFont = regularFont
+		if boldPath := strings.TrimSpace(os.Getenv("PDF_FONT_BOLD_PATH")); boldPath != "" {
+			if boldFont, fontErr = os.ReadFile(boldPath); fontErr != nil {
+				fontErr = fmt.Errorf("PDF_FONT_BOLD_PATH: %w", fontErr)
+			}
+		}
+	})
+	return regularFont, boldFont, fontErr
+}
+
+type renderer struct {
+	pdf     *fpdf.Fpdf
+	doc     *Document
+	opts    Options
+	family  string
+	utf8    bool
+	tr      func(string) string
+	width   float64
+	left    float64
+	columns []column
+}
+
+type column struct {
+	header string
+	width  float64
+	align  string
+	value  func(Line) string
+}
+
+// Render draws doc with the template options and returns the PDF bytes.
+func Render(doc *Document, opts Options) ([]byte, error) {
+	if needsShaping(doc, opts) {
+		return renderInBrowser(doc, opts)
+	}
+	orientation := "P"
+	if opts.Landscape {
+		orientation = "L"
+	}
+	pdf := fpdf.New(orientation, "mm", opts.PaperSize, "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 20)
+	pdf.AliasNbPages("")
+	pdf.SetTitle(doc.Title+" "+doc.Number, true)
+
+	r := &renderer{pdf: pdf, doc: doc, opts: opts, family: "Helvetica", tr: func(s string) string { return s }}
+	regular, bold, err := loadFonts()
+	if err != nil {
+		return nil, err
+	}
+	if regular != nil {
+		pdf.AddUTF8FontFromBytes(fontFamily, "", regular)
+		pdf.AddUTF8FontFromBytes(fontFamily, "B", bold)
+		r.family = fontFamily
+		r.utf8 = true
+	} else {
+		r.tr = pdf.UnicodeTranslatorFromDescriptor("")
+	}
+
+	pageWidth, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+	r.left = left
+	r.width = pageWidth - left - right
+	r.columns = r.lineColumns()
+
+	pdf.SetFooterFunc(r.footer)
+	pdf.AddPage()
+	r.header()
+	r.parties()
+	r.lines()
+	r.totals()
+	r.notes()
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (r *renderer) font(style string, size float64) {
+	r.pdf.SetFont(r.family, style, size)
+}
+
+func (r *renderer) lineColumns() []column {
+	cols := documentColumns(r.doc, r.opts)
+	fixed := 0.0
+	for _, c := range cols {
+		fixed += c.width
+	}
+	cols[1].width = r.width - fixed
+	return cols
+}
+
+// documentColumns returns the line table columns. Widths are in mm; the item column
+// (index 1) has no width of its own and takes what the others leave.
+func documentColumns(doc *Document, opts Options) []column {
+	places := doc.DecimalPlaces
+	cols := []column{{header: "#", width: 8, align: "L"}}
+	if doc.Balances {
+		cols = append(cols,
+			column{header: doc.LineHeader, align: "L"},
+			column{header: "Amount", width: 28, align: "R", value: func(l Line) string { return formatOptionalNumber(l.Amount, places) }},
+			column{header: "Payment", width: 28, align: "R", value: func(l Line) string { return formatOptionalNumber(l.Payment, places) }},
+			column{header: "Balance", width: 30, align: "R", value: func(l Line) string { return models.FormatNumber(l.Balance, places) }},
+		)
+	} else if !doc.ItemColumns {
+		header := doc.LineHeader
+		if header == "" {
+			header = "Item"
+		}
+		cols = append(cols,
+			column{header: header, align: "L"},
+			column{header: "Amount", width: 35, align: "R", value: func(l Line) string { return models.FormatNumber(l.Amount, places) }},
+		)
+	} else {
+		cols = append(cols,
+			column{header: "Item", align: "L"},
+			column{header: "Qty", width: 18, align: "R", value: func(l Line) string { return formatQty(l.Qty) }},
+			column{header: "Rate", width: 26, align: "R", value: func(l Line) string { return models.FormatNumber(l.Rate, places) }},
+		)
+		if opts.ShowDiscountColumn {
+			cols = append(cols, column{header: "Discount", width: 22, align: "R", value: func(l Line) string { return models.FormatNumber(l.Discount, places) }})
+		}
+		if opts.ShowTaxColumn {
+			cols = append(cols, column{header: "Tax", width: 22, align: "R", value: func(l Line) string { return models.FormatNumber(l.Tax, places) }})
+		}
+		cols = append(cols, column{header: "Amount", width: 30, align: "R", value: func(l Line) string { return models.FormatNumber(l.Amount, places) }})
+	}
+	return cols
+}
+
+// wrap splits text to fit width using the current font.
+func (r *renderer) wrap(text string, width float64) []string {
+	if text == "" {
+		return nil
+	}
+	if r.utf8 {
+		return r.pdf.SplitText(text, width)
+	}
+	var lines []string
+	for _, line := range r.pdf.SplitLines([]byte(r.tr(text)), width) {
+		lines = append(lines, string(line))
+	}
+	return lines
+}
+
+func (r *renderer) cell(w, h float64, text string, align string, fill bool) {
+	r.pdf.CellFormat(w, h, r.tr(text), "", 0, align, fill, 0, "")
+}
+
+// wrappedCell writes a line returned by wrap, which is already in the font's encoding.
+func (r *renderer) wrappedCell(w, h float64, line string, align string) {
+	r.pdf.CellFormat(w, h, line, "", 0, align, false, 0, "")
+}
+
+func (r *renderer) header() {
+	pdf := r.pdf
+	accent := r.opts.AccentColor
+	top := pdf.GetY()
+	half := r.width / 2
+
+	r.font("B", 14)
+	for _, line := range r.wrap(r.doc.Issuer.Name, half) {
+		r.wrappedCell(half, 7, line, "L")
+		pdf.Ln(7)
+	}
+	r.font("", 9)
+	issuerLines := r.doc.Issuer.Lines
+	if r.opts.ShowBusinessTaxId && r.doc.Issuer.TaxId != "" {
+		issuerLines = append(append([]string{}, issuerLines...), "Tax ID: "+r.doc.Issuer.TaxId)
+	}
+	for _, text := range issuerLines {
+		for _, line := range r.wrap(text, half) {
+			r.wrappedCell(half, 4.5, line, "L")
+			pdf.Ln(4.5)
+		}
+	}
+	leftBottom := pdf.GetY()
+
+	title := r.doc.Title
+	if r.opts.Title != "" {
+		title = r.opts.Title
+	}
+	pdf.SetXY(r.left+half, top)
+	pdf.SetTextColor(accent[0], accent[1], accent[2])
+	r.font("B", 20)
+	r.cell(half, 9, strings.ToUpper(title), "R", false)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.Ln(9)
+	pdf.SetX(r.left + half)
+	r.font("B", 10)
+	r.cell(half, 6, "# "+r.doc.Number, "R", false)
+	pdf.Ln(7)
+	for _, f := range r.doc.Fields {
+		pdf.SetX(r.left + half)
+		r.font("", 9)
+		r.cell(half-40, 5, f.Label+":", "R", false)
+		r.font("B", 9)
+		r.cell(40, 5, f.Value, "R", false)
+		pdf.Ln(5)
+	}
+
+	if pdf.GetY() < leftBottom {
+		pdf.SetY(leftBottom)
+	}
+	pdf.Ln(4)
+	pdf.SetDrawColor(accent[0], accent[1], accent[2])
+	pdf.Line(r.left, pdf.GetY(), r.left+r.width, pdf.GetY())
+	pdf.Ln(4)
+}
+
+func (r *renderer) parties() {
+	pdf := r.pdf
+	r.font("", 9)
+	pdf.SetTextColor(100, 100, 100)
+	r.cell(r.width, 5, r.doc.PartyLabel, "L", false)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.Ln(5)
+	r.font("B", 11)
+	for _, line := range r.wrap(r.doc.Party.Name, r.width/2) {
+		r.wrappedCell(r.width/2, 6, line, "L")
+		pdf.Ln(6)
+	}
+	r.font("", 9)
+	for _, text := range r.doc.Party.Lines {
+		for _, line := range r.wrap(text, r.width/2) {
+			r.wrappedCell(r.width/2, 4.5, line, "L")
+			pdf.Ln(4.5)
+		}
+	}
+	pdf.Ln(5)
+}
+
+func (r *renderer) tableHeader() {
+	accent := r.opts.AccentColor
+	r.pdf.SetFillColor(accent[0], accent[1], accent[2])
+	r.pdf.SetTextColor(255, 255, 255)
+	r.font("B", 9)
+	for _, c := range r.columns {
+		r.cell(c.width, 7, c.header, c.align, true)
+	}
+	r.pdf.SetTextColor(0, 0, 0)
+	r.pdf.Ln(7)
+}
+
+func (r *renderer) lines() {
+	pdf := r.pdf
+	_, pageHeight := pdf.GetPageSize()
+	_, _, _, bottom := pdf.GetMargins()
+	itemWidth := r.columns[1].width
+
+	r.tableHeader()
+	for i, line := range r.doc.Lines {
+		r.font("", 9)
+		nameLines := r.wrap(line.Name, itemWidth)
+		r.font("", 8)
+		descLines := r.wrap(line.Description, itemWidth)
+		height := float64(len(nameLines))*5 + float64(len(descLines))*4
+		if height < 5 {
+			height = 5
+		}
+		height += 2
+
+		if pdf.GetY()+height > pageHeight-bottom {
+			pdf.AddPage()
+			r.tableHeader()
+		}
+		top := pdf.GetY()
+		x := r.left
+		for ci, c := range r.columns {
+			pdf.SetXY(x, top+1)
+			switch ci {
+			case 0:
+				r.font("", 9)
+				r.cell(c.width, 5, strconv.Itoa(i+1), c.align, false)
+			case 1:
+				r.font("", 9)
+				for _, l := range nameLines {
+					pdf.SetX(x)
+					r.wrappedCell(c.width, 5, l, "L")
+					pdf.Ln(5)
+				}
+				r.font("", 8)
+				pdf.SetTextColor(100, 100, 100)
+				for _, l := range descLines {
+					pdf.SetX(x)
+					r.wrappedCell(c.width, 4, l, "L")
+					pdf.Ln(4)
+				}
+				pdf.SetTextColor(0, 0, 0)
+			default:
+				r.font("", 9)
+				r.cell(c.width, 5, c.value(line), c.align, false)
+			}
+			x += c.width
+		}
+		pdf.SetY(top + height)
+		pdf.SetDrawColor(220, 220, 220)
+		pdf.Line(r.left, pdf.GetY(), r.left+r.width, pdf.GetY())
+	}
+	pdf.Ln(3)
+}
+
+func (r *renderer) totals() {
+	pdf := r.pdf
+	labelWidth, amountWidth := 50.0, 45.0
+	x := r.left + r.width - labelWidth - amountWidth
+	for _, t := range r.doc.Totals {
+		pdf.SetX(x)
+		style := ""
+		if t.Strong {
+			style = "B"
+		}
+		r.font(style, 10)
+		r.cell(labelWidth, 6, t.Label, "R", false)
+		r.cell(amountWidth, 6, models.FormatAmount(t.Amount, r.doc.Currency, r.doc.DecimalPlaces), "R", false)
+		pdf.Ln(6)
+	}
+	pdf.Ln(4)
+}
+
+func (r *renderer) notes() {
+	sections := []Field{}
+	if r.opts.ShowNotes && strings.TrimSpace(r.doc.Notes) != "" {
+		sections = append(sections, Field{Label: "Notes", Value: r.doc.Notes})
+	}
+	if r.opts.ShowTerms && strings.TrimSpace(r.doc.Terms) != "" {
+		sections = append(sections, Field{Label: "Terms & Conditions", Value: r.doc.Terms})
+	}
+	for _, s := range sections {
+		r.font("B", 9)
+		r.cell(r.width, 5, s.Label, "L", false)
+		r.pdf.Ln(5)
+		r.font("", 9)
+		for _, line := range r.wrap(s.Value, r.width) {
+			r.wrappedCell(r.width, 4.5, line, "L")
+			r.pdf.Ln(4.5)
+		}
+		r.pdf.Ln(3)
+	}
+}
+
+func (r *renderer) footer() {
+	pdf := r.pdf
+	pdf.SetY(-15)
+	r.font("", 8)
+	pdf.SetTextColor(120, 120, 120)
+	r.cell(r.width-30, 5, r.opts.FooterText, "L", false)
+	r.cell(30, 5, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "R", false)
+	pdf.SetTextColor(0, 0, 0)
+}
@@ -0,0 +1,76 @@
+package pdfrender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/mmdatafocus/books_backend/utils"
+)
+
+// signedURLTTL is how long a GraphQL download link stays valid.
+const signedURLTTL = 15 * time.Minute
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// RenderDocument renders one transaction of the business in ctx with the business's
+// default template for its type. It returns the PDF bytes and a download file name.
+func RenderDocument(ctx context.Context, documentType string, id int) ([]byte, string, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, "", errors.New("business id is required")
+	}
+	if !IsSupportedType(documentType) {
+		return nil, "", fmt.Errorf("document type %q cannot be rendered", documentType)
+	}
+	doc, err := LoadDocument(ctx, documentType, id)
+	if err != nil {
+		return nil, "", err
+	}
+	opts, err := LoadOptions(ctx, businessId, documentType)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := Render(doc, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, fileName(doc), nil
+}
+
+// PublishDocument renders the document, stores it under the business's folder and
+// returns a signed download URL.
+func PublishDocument(ctx context.Context, documentType string, id int) (*models.DocumentPdf, error) {
+	data, name, err := RenderDocument(ctx, documentType, id)
+	if err != nil {
+		return nil, err
+	}
+	businessId, _ := utils.GetBusinessIdFromContext(ctx)
+	objectKey := path.Join(businessId, "documents", "pdf", documentType, fmt.Sprintf("%d-%s.pdf", id, uuid.New().String()))
+	if err := utils.UploadBytesToGCS(ctx, objectKey, data, "application/pdf"); err != nil {
+		return nil, err
+	}
+	url, expiresAt, err := utils.SignDownload(ctx, objectKey, signedURLTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &models.DocumentPdf{
+		Url:       url,
+		ObjectKey: objectKey,
+		FileName:  name,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func fileName(doc *Document) string {
+	name := unsafeFileChars.ReplaceAllString(doc.Number, "_")
+	if name == "" {
+		name = doc.Type
+	}
+	return name + ".pdf"
+}
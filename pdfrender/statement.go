@@ -0,0 +1,97 @@
+package pdfrender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/mmdatafocus/books_backend/utils"
+)
+
+// LoadCustomerStatement builds the view model of a customer's account statement.
+// fromDate and toDate are calendar dates in the business's timezone.
+func LoadCustomerStatement(ctx context.Context, customerId int, fromDate time.Time, toDate time.Time) (*Document, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, errors.New("business id is required")
+	}
+	business, err := models.GetBusiness(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statement, err := models.GetCustomerStatement(ctx, customerId, models.MyDateString(fromDate), models.MyDateString(toDate))
+	if err != nil {
+		return nil, err
+	}
+	customer, err := customerParty(ctx, businessId, customerId)
+	if err != nil {
+		return nil, err
+	}
+	currency, err := utils.FetchModel[models.Currency](ctx, businessId, statement.CurrencyId)
+	if err != nil {
+		return nil, errors.New("currency not found")
+	}
+
+	from, to := formatDate(statement.FromDate, business.Timezone), formatDate(statement.ToDate, business.Timezone)
+	doc := &Document{
+		Type:          models.DocumentTypeStatement,
+		Title:         "Statement of Account",
+		Number:        fmt.Sprintf("%s to %s", from, to),
+		Issuer:        businessParty(business),
+		PartyLabel:    "To",
+		Party:         customer,
+		Balances:      true,
+		LineHeader:    "Transaction",
+		Currency:      currency.Symbol,
+		DecimalPlaces: currency.DecimalPlaces,
+	}
+	doc.Fields = appendField(doc.Fields, "From", from)
+	doc.Fields = appendField(doc.Fields, "To", to)
+
+	doc.Lines = append(doc.Lines, Line{
+		Name:        "Opening Balance",
+		Description: from,
+		Balance:     statement.OpeningBalance,
+	})
+	for _, line := range statement.Lines {
+		doc.Lines = append(doc.Lines, Line{
+			Name:        joinNonEmpty(" ", line.TransactionType, line.TransactionNumber),
+			Description: joinNonEmpty(" - ", formatDate(line.Date, business.Timezone), line.Details),
+			Amount:      line.Amount,
+			Payment:     line.Payment,
+			Balance:     line.Balance,
+		})
+	}
+	doc.Totals = []Total{
+		{Label: "Opening Balance", Amount: statement.OpeningBalance},
+		{Label: "Invoiced Amount", Amount: statement.InvoicedAmount},
+		{Label: "Amount Received", Amount: statement.ReceivedAmount},
+		{Label: "Balance Due", Amount: statement.ClosingBalance, Strong: true},
+	}
+	return doc, nil
+}
+
+// RenderCustomerStatement renders a customer statement with the business's default
+// statement template. It returns the PDF bytes and a download file name.
+func RenderCustomerStatement(ctx context.Context, customerId int, fromDate time.Time, toDate time.Time) ([]byte, string, error) {
+	businessId, ok := utils.GetBusinessIdFromContext(ctx)
+	if !ok || businessId == "" {
+		return nil, "", errors.New("business id is required")
+	}
+	doc, err := LoadCustomerStatement(ctx, customerId, fromDate, toDate)
+	if err != nil {
+		return nil, "", err
+	}
+	opts, err := LoadOptions(ctx, businessId, models.DocumentTypeStatement)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := Render(doc, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	name := unsafeFileChars.ReplaceAllString(fmt.Sprintf("statement-%s-%s", doc.Party.Name, toDate.Format("2006-01-02")), "_")
+	return data, name + ".pdf", nil
+}
@@ -0,0 +1,129 @@
+package pdfrender
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/models"
+)
+
+// Options is the subset of DocumentTemplate.ConfigJson the server renderer understands.
+// The browser editor stores more keys than this; anything not listed here is ignored so
+// the two renderers can evolve independently.
+type Options struct {
+	Title              string
+	AccentColor        [3]int
+	PaperSize          string
+	Landscape          bool
+	FooterText         string
+	ShowNotes          bool
+	ShowTerms          bool
+	ShowDiscountColumn bool
+	ShowTaxColumn      bool
+	ShowBusinessTaxId  bool
+}
+
+type templateConfig struct {
+	Title              string `json:"title"`
+	AccentColor        string `json:"accentColor"`
+	PaperSize          string `json:"paperSize"`
+	Orientation        string `json:"orientation"`
+	FooterText         string `json:"footerText"`
+	ShowNotes          *bool  `json:"showNotes"`
+	ShowTerms          *bool  `json:"showTerms"`
+	ShowDiscountColumn *bool  `json:"showDiscountColumn"`
+	ShowTaxColumn      *bool  `json:"showTaxColumn"`
+	ShowBusinessTaxId  *bool  `json:"showBusinessTaxId"`
+}
+
+func DefaultOptions() Options {
+	return Options{
+		AccentColor:        [3]int{37, 99, 235},
+		PaperSize:          "A4",
+		ShowNotes:          true,
+		ShowTerms:          true,
+		ShowDiscountColumn: true,
+		ShowTaxColumn:      true,
+		ShowBusinessTaxId:  true,
+	}
+}
+
+// ParseOptions reads a template's ConfigJson on top of DefaultOptions.
+// Invalid JSON or values yield the defaults rather than an error, since a broken
+// template should not stop a customer from getting their invoice.
+func ParseOptions(configJson string) Options {
+	opts := DefaultOptions()
+	if strings.TrimSpace(configJson) == "" {
+		return opts
+	}
+	var cfg templateConfig
+	if err := json.Unmarshal([]byte(configJson), &cfg); err != nil {
+		return opts
+	}
+
+	opts.Title = strings.TrimSpace(cfg.Title)
+	opts.FooterText = strings.TrimSpace(cfg.FooterText)
+	if rgb, ok := parseHexColor(cfg.AccentColor); ok {
+		opts.AccentColor = rgb
+	}
+	switch strings.ToUpper(strings.TrimSpace(cfg.PaperSize)) {
+	case "A4":
+		opts.PaperSize = "A4"
+	case "A5":
+		opts.PaperSize = "A5"
+	case "LETTER":
+		opts.PaperSize = "Letter"
+	case "LEGAL":
+		opts.PaperSize = "Legal"
+	}
+	opts.Landscape = strings.EqualFold(strings.TrimSpace(cfg.Orientation), "landscape")
+
+	setBool(&opts.ShowNotes, cfg.ShowNotes)
+	setBool(&opts.ShowTerms, cfg.ShowTerms)
+	setBool(&opts.ShowDiscountColumn, cfg.ShowDiscountColumn)
+	setBool(&opts.ShowTaxColumn, cfg.ShowTaxColumn)
+	setBool(&opts.ShowBusinessTaxId, cfg.ShowBusinessTaxId)
+	return opts
+}
+
+// LoadOptions returns the options of the business's default template for documentType,
+// or DefaultOptions when none has been saved.
+func LoadOptions(ctx context.Context, businessId string, documentType string) (Options, error) {
+	db := config.GetDB()
+	var templates []models.DocumentTemplate
+	if err := db.WithContext(ctx).
+		Where("business_id = ? AND document_type = ? AND is_default = true", businessId, documentType).
+		Order("updated_at DESC").
+		Limit(1).
+		Find(&templates).Error; err != nil {
+		return Options{}, err
+	}
+	if len(templates) == 0 {
+		return DefaultOptions(), nil
+	}
+	return ParseOptions(templates[0].ConfigJson), nil
+}
+
+func setBool(dst *bool, v *bool) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+func parseHexColor(s string) ([3]int, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	if len(s) != 6 {
+		return [3]int{}, false
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return [3]int{}, false
+	}
+	return [3]int{int(v >> 16 & 0xff), int(v >> 8 & 0xff), int(v & 0xff)}, true
+}
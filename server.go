@@ -26,8 +26,10 @@ import (
 	"github.com/mmdatafocus/books_backend/config"
 	"github.com/mmdatafocus/books_backend/directives"
 	"github.com/mmdatafocus/books_backend/graph"
+	"github.com/mmdatafocus/books_backend/mailer"
 	"github.com/mmdatafocus/books_backend/middlewares"
 	"github.com/mmdatafocus/books_backend/models"
+	"github.com/mmdatafocus/books_backend/pdfrender"
 	"github.com/mmdatafocus/books_backend/utils"
 	"github.com/mmdatafocus/books_backend/workflow"
 	"github.com/ravilushqa/otelgqlgen"
@@ -305,62 +307,66 @@ func playgroundHandler() gin.HandlerFunc {
 	}
 }
 
-// authorizeInternalBusiness ensures the session user is allowed to act on the provided business_id.
-// - Admin users may act on any business.
-// - Non-admin users may only act on their own business.
-func authorizeInternalBusiness(ctx context.Context, businessId string) error {
+// sessionUser loads the user of the request's session (cache first, DB fallback).
+func sessionUser(ctx context.Context) (*models.User, error) {
 	username, ok := utils.GetUsernameFromContext(ctx)
-	if !ok || username == "" {
-		return errors.New("unauthorized")
-	}
-	if businessId == "" {
-		return errors.New("business_id is required")
+	if !ok || strings.TrimSpace(username) == "" {
+		return nil, errors.New("unauthorized")
 	}
 
 	var user models.User
 	exists, err := config.GetRedisObject("User:"+username, &user)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if !exists {
 		db := config.GetDB()
 		if db == nil {
-			return errors.New("db is nil")
+			return nil, errors.New("db is nil")
 		}
 		if err := db.WithContext(ctx).Model(&models.User{}).Where("username = ?", username).Take(&user).Error; err != nil {
-			return errors.New("unauthorized")
+			return nil, errors.New("unauthorized")
 		}
 	}
+	return &user, nil
+}
 
-	if user.Role == models.UserRoleAdmin {
-		return nil
-	}
-	if user.BusinessId != businessId {
-		return errors.New("unauthorized")
+// businessContext returns ctx acting on businessId for the session user, limited to the
+// user's branches the same way the GraphQL @auth directive limits queries. Every REST
+// handler that touches business data builds its context here.
+func businessContext(ctx context.Context, businessId string) (context.Context, error) {
+	user, err := sessionUser(ctx)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return userBusinessContext(ctx, user, businessId)
 }
 
-func authorizeAdminOnly(ctx context.Context) error {
-	username, ok := utils.GetUsernameFromContext(ctx)
-	if !ok || username == "" {
-		return errors.New("unauthorized")
+// userBusinessContext is businessContext for a user already loaded.
+// - Admin users may act on any business.
+// - Non-admin users may only act on their own business.
+func userBusinessContext(ctx context.Context, user *models.User, businessId string) (context.Context, error) {
+	if businessId == "" {
+		return nil, errors.New("business_id is required")
+	}
+	if user.Role != models.UserRoleAdmin && user.BusinessId != businessId {
+		return nil, errors.New("unauthorized")
 	}
+	ctx = utils.SetBusinessIdInContext(ctx, businessId)
+	return models.ScopeContextToUserBranches(ctx, user)
+}
 
-	var user models.User
-	exists, err := config.GetRedisObject("User:"+username, &user)
+// authorizeInternalBusiness ensures the session user is allowed to act on the provided business_id.
+func authorizeInternalBusiness(ctx context.Context, businessId string) error {
+	_, err := businessContext(ctx, businessId)
+	return err
+}
+
+func authorizeAdminOnly(ctx context.Context) error {
+	user, err := sessionUser(ctx)
 	if err != nil {
 		return err
 	}
-	if !exists {
-		db := config.GetDB()
-		if db == nil {
-			return errors.New("db is nil")
-		}
-		if err := db.WithContext(ctx).Model(&models.User{}).Where("username = ?", username).Take(&user).Error; err != nil {
-			return errors.New("unauthorized")
-		}
-	}
 	if user.Role != models.UserRoleAdmin {
 		return errors.New("unauthorized")
 	}
@@ -445,12 +451,11 @@ func voidCloneSalesInvoiceHandler() gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "business_id and invoice_id are required"})
 			return
 		}
-		if err := authorizeInternalBusiness(c.Request.Context(), req.BusinessId); err != nil {
+		ctx, err := businessContext(c.Request.Context(), req.BusinessId)
+		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 			return
 		}
-
-		ctx := utils.SetBusinessIdInContext(c.Request.Context(), req.BusinessId)
 		newInv, err := models.VoidAndCloneSalesInvoice(ctx, req.BusinessId, req.InvoiceId)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -487,12 +492,11 @@ func voidCloneBillHandler() gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "business_id and bill_id are required"})
 			return
 		}
-		if err := authorizeInternalBusiness(c.Request.Context(), req.BusinessId); err != nil {
+		ctx, err := businessContext(c.Request.Context(), req.BusinessId)
+		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 			return
 		}
-
-		ctx := utils.SetBusinessIdInContext(c.Request.Context(), req.BusinessId)
 		newBill, err := models.VoidAndCloneBill(ctx, req.BusinessId, req.BillId)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -529,12 +533,11 @@ func voidCloneSupplierCreditHandler() gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "business_id and supplier_credit_id are required"})
 			return
 		}
-		if err := authorizeInternalBusiness(c.Request.Context(), req.BusinessId); err != nil {
+		ctx, err := businessContext(c.Request.Context(), req.BusinessId)
+		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 			return
 		}
-
-		ctx := utils.SetBusinessIdInContext(c.Request.Context(), req.BusinessId)
 		newSC, err := models.VoidAndCloneSupplierCredit(ctx, req.BusinessId, req.SupplierCreditId)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -571,12 +574,11 @@ func voidCloneCreditNoteHandler() gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "business_id and credit_note_id are required"})
 			return
 		}
-		if err := authorizeInternalBusiness(c.Request.Context(), req.BusinessId); err != nil {
+		ctx, err := businessContext(c.Request.Context(), req.BusinessId)
+		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 			return
 		}
-
-		ctx := utils.SetBusinessIdInContext(c.Request.Context(), req.BusinessId)
 		newCN, err := models.VoidAndCloneCreditNote(ctx, req.BusinessId, req.CreditNoteId)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -613,12 +615,11 @@ func voidCloneSalesOrderHandler() gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "business_id and sales_order_id are required"})
 			return
 		}
-		if err := authorizeInternalBusiness(c.Request.Context(), req.BusinessId); err != nil {
+		ctx, err := businessContext(c.Request.Context(), req.BusinessId)
+		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 			return
 		}
-
-		ctx := utils.SetBusinessIdInContext(c.Request.Context(), req.BusinessId)
 		newSO, err := models.CancelAndCloneSalesOrder(ctx, req.BusinessId, req.SalesOrderId)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -655,12 +656,11 @@ func voidClonePurchaseOrderHandler() gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "business_id and purchase_order_id are required"})
 			return
 		}
-		if err := authorizeInternalBusiness(c.Request.Context(), req.BusinessId); err != nil {
+		ctx, err := businessContext(c.Request.Context(), req.BusinessId)
+		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 			return
 		}
-
-		ctx := utils.SetBusinessIdInContext(c.Request.Context(), req.BusinessId)
 		newPO, err := models.CancelAndClonePurchaseOrder(ctx, req.BusinessId, req.PurchaseOrderId)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -877,6 +877,7 @@ func main() {
 	r.POST("/api/templates", templatesCreateHandler())
 	r.PUT("/api/templates/:id", templatesUpdateHandler())
 	r.POST("/api/templates/:id/set-default", templatesSetDefaultHandler())
+	r.GET("/api/documents/:type/:id/pdf", documentPdfHandler())
 
 	// go RunAccountingWorkflow()
 	r.NoRoute(customNotFoundHandler)
@@ -930,6 +931,45 @@ func main() {
 		}
 	}
 
+	// Start email dispatcher (delivers queued document emails).
+	if envBoolDefault("EMAIL_RUN_DISPATCHER", true) {
+		transport, err := mailer.NewTransportFromEnv(logger)
+		if err != nil {
+			log.Fatal(err)
+		}
+		go workflow.NewEmailDispatcher(db, logger, transport).Run(dispatcherCtx)
+	} else if logger != nil {
+		logger.WithFields(logrus.Fields{"field": "EmailDispatcher"}).
+			Warn("EMAIL_RUN_DISPATCHER=false; queued emails are not sent from this service")
+	}
+
+	// Start webhook dispatcher (posts queued webhook deliveries to business endpoints).
+	if envBoolDefault("WEBHOOK_RUN_DISPATCHER", true) {
+		go workflow.NewWebhookDispatcher(db, logger).Run(dispatcherCtx)
+	} else if logger != nil {
+		logger.WithFields(logrus.Fields{"field": "WebhookDispatcher"}).
+			Warn("WEBHOOK_RUN_DISPATCHER=false; queued webhooks are not delivered from this service")
+	}
+
+	// Start recurring scheduler (generates documents from recurring profiles).
+	if envBoolDefault("RECURRING_RUN_SCHEDULER", true) {
+		go workflow.NewRecurringScheduler(db, logger).Run(dispatcherCtx)
+	} else if logger != nil {
+		logger.WithFields(logrus.Fields{"field": "RecurringScheduler"}).
+			Warn("RECURRING_RUN_SCHEDULER=false; recurring documents are not generated on this service")
+	}
+
+	// Start daily jobs (depreciation, estimate expiry, payment reminders, credit holds).
+	if envBoolDefault("DAILY_RUN_JOBS", true) {
+		go workflow.NewFixedAssetDepreciationJob(db, logger).Run(dispatcherCtx)
+		go workflow.NewEstimateExpiryJob(db, logger).Run(dispatcherCtx)
+		go workflow.NewPaymentReminderJob(db, logger).Run(dispatcherCtx)
+		go workflow.NewCreditHoldJob(db, logger).Run(dispatcherCtx)
+	} else if logger != nil {
+		logger.WithFields(logrus.Fields{"field": "DailyJob"}).
+			Warn("DAILY_RUN_JOBS=false; daily jobs do not run on this service")
+	}
+
 	// Set the session isolation level to READ COMMITTED
 	for attempt := 1; ; attempt++ {
 		err := db.Exec("SET SESSION TRANSACTION ISOLATION LEVEL READ COMMITTED").Error
@@ -1085,9 +1125,10 @@ func intFromEnv(key string, def int) int {
 
 func customerTransactionsHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Auth check
-		username, ok := utils.GetUsernameFromContext(c.Request.Context())
-		if !ok || username == "" {
+		// SessionMiddleware only sets username/token; model read paths expect business_id
+		// (and the user's branches) in ctx.
+		ctx, _, err := uiBusinessContext(c)
+		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 			return
 		}
@@ -1099,45 +1140,6 @@ func customerTransactionsHandler() gin.HandlerFunc {
 			return
 		}
 
-		// Ensure business_id is set in context for tenant isolation.
-		// SessionMiddleware only sets username/token; most model read paths expect business_id in ctx.
-		businessId := strings.TrimSpace(c.Query("business_id"))
-		if businessId != "" {
-			// Allow admin to act on any business; non-admin only on their own business.
-			if err := authorizeInternalBusiness(c.Request.Context(), businessId); err != nil {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
-				return
-			}
-		} else {
-			// Derive from the authenticated user (default for UI calls).
-			var user models.User
-			exists, err := config.GetRedisObject("User:"+username, &user)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-				return
-			}
-			if !exists {
-				db := config.GetDB()
-				if db == nil {
-					c.JSON(http.StatusServiceUnavailable, gin.H{"error": "db is nil"})
-					return
-				}
-				if err := db.WithContext(c.Request.Context()).
-					Model(&models.User{}).
-					Where("username = ?", username).
-					Take(&user).Error; err != nil {
-					c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
-					return
-				}
-			}
-			businessId = strings.TrimSpace(user.BusinessId)
-			if businessId == "" {
-				// For safety: require explicit business_id rather than guessing for admin/system users.
-				c.JSON(http.StatusBadRequest, gin.H{"error": "business_id is required"})
-				return
-			}
-		}
-
 		// Query params
 		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
@@ -1164,7 +1166,6 @@ func customerTransactionsHandler() gin.HandlerFunc {
 			}
 		}
 
-		ctx := utils.SetBusinessIdInContext(c.Request.Context(), businessId)
 		resp, err := models.GetCustomerTransactions(ctx, customerId, fromDate, toDate, types, status, search, page, limit)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -1175,45 +1176,25 @@ func customerTransactionsHandler() gin.HandlerFunc {
 	}
 }
 
-func resolveBusinessIdForUI(c *gin.Context) (string, error) {
-	// Ensure request is authenticated (SessionMiddleware must put username in context).
-	username, ok := utils.GetUsernameFromContext(c.Request.Context())
-	if !ok || strings.TrimSpace(username) == "" {
-		return "", errors.New("unauthorized")
+// uiBusinessContext returns the context of a UI request and the business it acts on: the
+// business_id query parameter when given (admins may name any business), otherwise the
+// session user's own business.
+func uiBusinessContext(c *gin.Context) (context.Context, string, error) {
+	ctx := c.Request.Context()
+	user, err := sessionUser(ctx)
+	if err != nil {
+		return nil, "", err
 	}
-
-	// Admin override: allow explicit business_id param; non-admin only for their own business.
 	businessId := strings.TrimSpace(c.Query("business_id"))
-	if businessId != "" {
-		if err := authorizeInternalBusiness(c.Request.Context(), businessId); err != nil {
-			return "", errors.New("unauthorized")
-		}
-		return businessId, nil
+	if businessId == "" {
+		// For safety: require explicit business_id rather than guessing for admin/system users.
+		businessId = strings.TrimSpace(user.BusinessId)
 	}
-
-	// Default: derive from authenticated user (cache first, DB fallback).
-	var user models.User
-	exists, err := config.GetRedisObject("User:"+username, &user)
+	ctx, err = userBusinessContext(ctx, user, businessId)
 	if err != nil {
-		return "", err
-	}
-	if !exists {
-		db := config.GetDB()
-		if db == nil {
-			return "", errors.New("db is nil")
-		}
-		if err := db.WithContext(c.Request.Context()).
-			Model(&models.User{}).
-			Where("username = ?", username).
-			Take(&user).Error; err != nil {
-			return "", errors.New("unauthorized")
-		}
+		return nil, "", err
 	}
-	businessId = strings.TrimSpace(user.BusinessId)
-	if businessId == "" {
-		return "", errors.New("business_id is required")
-	}
-	return businessId, nil
+	return ctx, businessId, nil
 }
 
 type templateUpsertRequest struct {
@@ -1267,7 +1248,7 @@ func validateTemplateUpsert(req *templateUpsertRequest) (string, string, bool, s
 
 func templatesListHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		businessId, err := resolveBusinessIdForUI(c)
+		ctx, businessId, err := uiBusinessContext(c)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 			return
@@ -1285,7 +1266,7 @@ func templatesListHandler() gin.HandlerFunc {
 		}
 
 		var templates []models.DocumentTemplate
-		q := db.WithContext(c.Request.Context()).
+		q := db.WithContext(ctx).
 			Model(&models.DocumentTemplate{}).
 			Where("business_id = ?", businessId)
 		if documentType != "" {
@@ -1302,7 +1283,7 @@ func templatesListHandler() gin.HandlerFunc {
 
 func templatesGetDefaultHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		businessId, err := resolveBusinessIdForUI(c)
+		ctx, businessId, err := uiBusinessContext(c)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 			return
@@ -1320,7 +1301,7 @@ func templatesGetDefaultHandler() gin.HandlerFunc {
 		}
 
 		var tpl models.DocumentTemplate
-		if err := db.WithContext(c.Request.Context()).
+		if err := db.WithContext(ctx).
 			Model(&models.DocumentTemplate{}).
 			Where("business_id = ? AND document_type = ? AND is_default = true", businessId, documentType).
 			Order("updated_at DESC").
@@ -1335,7 +1316,7 @@ func templatesGetDefaultHandler() gin.HandlerFunc {
 
 func templatesGetHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		businessId, err := resolveBusinessIdForUI(c)
+		ctx, businessId, err := uiBusinessContext(c)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 			return
@@ -1353,7 +1334,7 @@ func templatesGetHandler() gin.HandlerFunc {
 		}
 
 		var tpl models.DocumentTemplate
-		if err := db.WithContext(c.Request.Context()).
+		if err := db.WithContext(ctx).
 			Model(&models.DocumentTemplate{}).
 			Where("id = ? AND business_id = ?", id, businessId).
 			First(&tpl).Error; err != nil {
@@ -1366,7 +1347,7 @@ func templatesGetHandler() gin.HandlerFunc {
 
 func templatesCreateHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		businessId, err := resolveBusinessIdForUI(c)
+		ctx, businessId, err := uiBusinessContext(c)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 			return
@@ -1397,7 +1378,7 @@ func templatesCreateHandler() gin.HandlerFunc {
 			ConfigJson:   configStr,
 		}
 
-		tx := db.WithContext(c.Request.Context()).Begin()
+		tx := db.WithContext(ctx).Begin()
 		if tx.Error != nil {
 			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "db error"})
 			return
@@ -1444,7 +1425,7 @@ func templatesCreateHandler() gin.HandlerFunc {
 
 func templatesUpdateHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		businessId, err := resolveBusinessIdForUI(c)
+		ctx, businessId, err := uiBusinessContext(c)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 			return
@@ -1473,7 +1454,7 @@ func templatesUpdateHandler() gin.HandlerFunc {
 		}
 
 		var existing models.DocumentTemplate
-		if err := db.WithContext(c.Request.Context()).
+		if err := db.WithContext(ctx).
 			Model(&models.DocumentTemplate{}).
 			Where("id = ? AND business_id = ?", id, businessId).
 			First(&existing).Error; err != nil {
@@ -1487,7 +1468,7 @@ func templatesUpdateHandler() gin.HandlerFunc {
 			return
 		}
 
-		tx := db.WithContext(c.Request.Context()).Begin()
+		tx := db.WithContext(ctx).Begin()
 		if tx.Error != nil {
 			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "db error"})
 			return
@@ -1521,7 +1502,7 @@ func templatesUpdateHandler() gin.HandlerFunc {
 
 		// Reload the updated record.
 		var tpl models.DocumentTemplate
-		if err := db.WithContext(c.Request.Context()).
+		if err := db.WithContext(ctx).
 			Model(&models.DocumentTemplate{}).
 			Where("id = ? AND business_id = ?", id, businessId).
 			First(&tpl).Error; err != nil {
@@ -1534,7 +1515,7 @@ func templatesUpdateHandler() gin.HandlerFunc {
 
 func templatesSetDefaultHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		businessId, err := resolveBusinessIdForUI(c)
+		ctx, businessId, err := uiBusinessContext(c)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 			return
@@ -1552,7 +1533,7 @@ func templatesSetDefaultHandler() gin.HandlerFunc {
 		}
 
 		var tpl models.DocumentTemplate
-		if err := db.WithContext(c.Request.Context()).
+		if err := db.WithContext(ctx).
 			Model(&models.DocumentTemplate{}).
 			Where("id = ? AND business_id = ?", id, businessId).
 			First(&tpl).Error; err != nil {
@@ -1560,7 +1541,7 @@ func templatesSetDefaultHandler() gin.HandlerFunc {
 			return
 		}
 
-		tx := db.WithContext(c.Request.Context()).Begin()
+		tx := db.WithContext(ctx).Begin()
 		if tx.Error != nil {
 			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "db error"})
 			return
@@ -1587,7 +1568,7 @@ func templatesSetDefaultHandler() gin.HandlerFunc {
 
 		// Return the updated default template.
 		var out models.DocumentTemplate
-		if err := db.WithContext(c.Request.Context()).
+		if err := db.WithContext(ctx).
 			Model(&models.DocumentTemplate{}).
 			Where("id = ? AND business_id = ?", tpl.ID, businessId).
 			First(&out).Error; err != nil {
@@ -1597,3 +1578,42 @@ func templatesSetDefaultHandler() gin.HandlerFunc {
 		c.JSON(http.StatusOK, gin.H{"template": out})
 	}
 }
+
+// documentPdfHandler streams a server-rendered PDF of a transaction using the
+// business's default template for that document type.
+func documentPdfHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, _, err := uiBusinessContext(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		documentType := strings.TrimSpace(c.Param("type"))
+		if !pdfrender.IsSupportedType(documentType) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid document type"})
+			return
+		}
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil || id <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+			return
+		}
+
+		data, fileName, err := pdfrender.RenderDocument(ctx, documentType, id)
+		if err != nil {
+			if errors.Is(err, utils.ErrorRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		disposition := "inline"
+		if c.Query("download") == "1" {
+			disposition = "attachment"
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("%s; filename=%q", disposition, fileName))
+		c.Data(http.StatusOK, "application/pdf", data)
+	}
+}
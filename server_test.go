@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/utils"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const testBusinessId = "6f1c1e0a-8a57-4c36-9d8b-1f0a6f2b7c11"
+
+// stubTable is the rows a table answers with; branch is the rows' branch_id, which a
+// branch_id filter in the query is checked against.
+type stubTable struct {
+	columns []string
+	row     []driver.Value
+	branch  int64
+}
+
+// stubDriver answers queries from fixed rows per table and records every query.
+type stubDriver struct {
+	mu      sync.Mutex
+	tables  map[string]stubTable
+	queries []string
+}
+
+var (
+	stubFromTable = regexp.MustCompile("FROM `(\\w+)`")
+	stubBranchIn  = regexp.MustCompile("`branch_id` (IN \\([?, ]+\\)|= \\?)")
+)
+
+func (d *stubDriver) Open(string) (driver.Conn, error) { return &stubConn{d: d}, nil }
+
+func (d *stubDriver) query(query string, args []driver.NamedValue) (driver.Rows, error) {
+	d.mu.Lock()
+	d.queries = append(d.queries, query)
+	d.mu.Unlock()
+
+	m := stubFromTable.FindStringSubmatch(query)
+	if m == nil {
+		return &stubRows{}, nil
+	}
+	table, ok := d.tables[m[1]]
+	if !ok {
+		return &stubRows{}, nil
+	}
+	if loc := stubBranchIn.FindStringSubmatchIndex(query); loc != nil {
+		// the IN values are the args after every placeholder before the list
+		first := strings.Count(query[:loc[2]], "?")
+		n := strings.Count(query[loc[2]:loc[3]], "?")
+		permitted := false
+		for _, arg := range args[first : first+n] {
+			if v, ok := arg.Value.(int64); ok && v == table.branch {
+				permitted = true
+			}
+		}
+		if !permitted {
+			return &stubRows{columns: table.columns}, nil
+		}
+	}
+	return &stubRows{columns: table.columns, rows: [][]driver.Value{table.row}}, nil
+}
+
+func (d *stubDriver) tableQueries(table string) []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var out []string
+	for _, q := range d.queries {
+		if strings.Contains(q, "FROM `"+table+"`") {
+			out = append(out, q)
+		}
+	}
+	return out
+}
+
+type stubConn struct{ d *stubDriver }
+
+func (c *stubConn) Prepare(query string) (driver.Stmt, error) {
+	return &stubStmt{c: c, query: query}, nil
+}
+func (c *stubConn) Close() error              { return nil }
+func (c *stubConn) Begin() (driver.Tx, error) { return c, nil }
+func (c *stubConn) Commit() error             { return nil }
+func (c *stubConn) Rollback() error           { return nil }
+
+func (c *stubConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.d.query(query, args)
+}
+
+func (c *stubConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+
+type stubStmt struct {
+	c     *stubConn
+	query string
+}
+
+func (s *stubStmt) Close() error  { return nil }
+func (s *stubStmt) NumInput() int { return -1 }
+func (s *stubStmt) Exec([]driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+func (s *stubStmt) Query(args []driver.Value) (driver.Rows, error) {
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return s.c.d.query(s.query, named)
+}
+
+type stubRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *stubRows) Columns() []string { return r.columns }
+func (r *stubRows) Close() error      { return nil }
+func (r *stubRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+var stubDriverSeq = 0
+
+// useStubDB points config's DB at a stub holding one user and one invoice of branch 5.
+func useStubDB(t *testing.T, role string, branches string) *stubDriver {
+	t.Helper()
+	d := &stubDriver{tables: map[string]stubTable{
+		"users": {
+			columns: []string{"id", "business_id", "username", "name", "is_active", "role_id", "role", "branches"},
+			row:     []driver.Value{int64(1), testBusinessId, "clerk", "Clerk", true, int64(2), role, branches},
+		},
+		"businesses": {
+			columns: []string{"id", "name", "timezone"},
+			row:     []driver.Value{testBusinessId, "Test Business", "Asia/Yangon"},
+		},
+		"sales_invoices": {
+			columns: []string{"id", "business_id", "branch_id", "customer_id", "invoice_number"},
+			row:     []driver.Value{int64(9), testBusinessId, int64(5), int64(1), "INV-9"},
+			branch:  5,
+		},
+	}}
+	stubDriverSeq++
+	name := fmt.Sprintf("stub%d", stubDriverSeq)
+	sql.Register(name, d)
+	conn, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: conn, SkipInitializeWithVersion: true}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(config.NewTenantGuardPlugin()); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(config.NewBranchGuardPlugin()); err != nil {
+		t.Fatal(err)
+	}
+	previous := config.GetDB()
+	config.SetDB(db)
+	t.Cleanup(func() { config.SetDB(previous) })
+	return d
+}
+
+func pdfRequest(t *testing.T) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		ctx := context.WithValue(c.Request.Context(), utils.ContextKeyUsername, "clerk")
+		c.Request = c.Request.WithContext(ctx)
+	})
+	r.GET("/api/documents/:type/:id/pdf", documentPdfHandler())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/documents/invoice/9/pdf", nil))
+	return w
+}
+
+func TestDocumentPdfHandler_OtherBranch(t *testing.T) {
+	d := useStubDB(t, "C", "3")
+	w := pdfRequest(t)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404 (body %s)", w.Code, w.Body.String())
+	}
+	queries := d.tableQueries("sales_invoices")
+	if len(queries) == 0 || !stubBranchIn.MatchString(queries[0]) {
+		t.Errorf("invoice query is not limited to the user's branches: %v", queries)
+	}
+}
+
+func TestDocumentPdfHandler_UnrestrictedUserIsNotBranchScoped(t *testing.T) {
+	d := useStubDB(t, "O", "")
+	pdfRequest(t)
+	queries := d.tableQueries("sales_invoices")
+	if len(queries) == 0 {
+		t.Fatal("invoice was not queried")
+	}
+	if stubBranchIn.MatchString(queries[0]) {
+		t.Errorf("owner's invoice query is branch-scoped: %s", queries[0])
+	}
+}
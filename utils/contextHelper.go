@@ -20,6 +20,8 @@ var (
 
 	ContextKeyIsAdmin         = appctx.ContextKeyIsAdmin
 	ContextKeySkipTenantScope = appctx.ContextKeySkipTenantScope
+	ContextKeyBranchIds       = appctx.ContextKeyBranchIds
+	ContextKeySkipBranchScope = appctx.ContextKeySkipBranchScope
 )
 
 func GetTokenFromContext(ctx context.Context) (string, bool) {
@@ -93,3 +95,36 @@ func GetSkipTenantScopeFromContext(ctx context.Context) (bool, bool) {
 func SetSkipTenantScopeInContext(ctx context.Context, skip bool) context.Context {
 	return appctx.Set(ctx, ContextKeySkipTenantScope, skip)
 }
+
+// GetBranchIdsFromContext returns the branches the current user is restricted to.
+// ok is false when the user may access every branch.
+func GetBranchIdsFromContext(ctx context.Context) ([]int, bool) {
+	ids, ok := appctx.GetInts(ctx, ContextKeyBranchIds)
+	return ids, ok && len(ids) > 0
+}
+
+func SetBranchIdsInContext(ctx context.Context, branchIds []int) context.Context {
+	return appctx.Set(ctx, ContextKeyBranchIds, branchIds)
+}
+
+func GetSkipBranchScopeFromContext(ctx context.Context) (bool, bool) {
+	return appctx.GetBool(ctx, ContextKeySkipBranchScope)
+}
+
+func SetSkipBranchScopeInContext(ctx context.Context, skip bool) context.Context {
+	return appctx.Set(ctx, ContextKeySkipBranchScope, skip)
+}
+
+// IsBranchAllowed reports whether the current user may access branchId.
+func IsBranchAllowed(ctx context.Context, branchId int) bool {
+	ids, restricted := GetBranchIdsFromContext(ctx)
+	if !restricted {
+		return true
+	}
+	for _, id := range ids {
+		if id == branchId {
+			return true
+		}
+	}
+	return false
+}
@@ -9,6 +9,7 @@ import (
 	"reflect"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"text/template"
@@ -536,6 +537,42 @@ func AreIntSlicesEqual(slice1, slice2 []int) bool {
 	return true
 }
 
+// ParseBranchIds reads a branch list as stored in User.Branches and MoneyAccount.Branches:
+// comma-separated branch ids, where an empty list means every branch.
+// The result is sorted and without duplicates. Invalid values are reported in the error
+// and left out of the ids, which still holds every valid one.
+func ParseBranchIds(branches string) ([]int, error) {
+	var ids []int
+	var invalid []string
+	for _, part := range strings.Split(branches, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.Atoi(part)
+		if err != nil || id <= 0 {
+			invalid = append(invalid, strconv.Quote(part))
+			continue
+		}
+		ids = append(ids, id)
+	}
+	ids = UniqueSlice(ids)
+	sort.Ints(ids)
+	if len(invalid) > 0 {
+		return ids, fmt.Errorf("invalid branch id %s", strings.Join(invalid, ", "))
+	}
+	return ids, nil
+}
+
+// FormatBranchIds is the stored form of a branch list, e.g. "1,4".
+func FormatBranchIds(ids []int) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.Itoa(id)
+	}
+	return strings.Join(parts, ",")
+}
+
 // OldestDate returns the oldest (earliest) date among the provided dates.
 func FindOldestDate(dates ...*time.Time) *time.Time {
 	var oldest *time.Time
@@ -157,6 +157,8 @@ func ClearRedisAdmin[T any]() error {
 func GetSequence[T any](ctx context.Context, businessId string) (int64, error) {
 	// lock
 	var model T
+	// numbering is business-wide, not per branch
+	ctx = SetSkipBranchScopeInContext(ctx, true)
 	// fmt.Println("waiting for mutex: " + time.Now().Format("01:04:05"))
 	mutex.Lock()
 	defer mutex.Unlock()
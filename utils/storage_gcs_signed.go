@@ -48,21 +48,9 @@ func SignUpload(ctx context.Context, objectKey, contentType string, expires time
 		ContentType: contentType,
 	}
 
-	accessID, privateKey, ok, err := loadSignerFromEnv()
-	if err != nil {
+	if err := applySigner(ctx, opts); err != nil {
 		return nil, err
 	}
-	if ok {
-		opts.GoogleAccessID = accessID
-		opts.PrivateKey = privateKey
-	} else {
-		email, signBytes, err := iamSigner(ctx)
-		if err != nil {
-			return nil, err
-		}
-		opts.GoogleAccessID = email
-		opts.SignBytes = signBytes
-	}
 
 	signedURL, err := storage.SignedURL(bucket, objectKey, opts)
 	if err != nil {
@@ -81,6 +69,52 @@ func SignUpload(ctx context.Context, objectKey, contentType string, expires time
 	}, nil
 }
 
+// SignDownload returns a time-limited GET URL for a private object, e.g. a rendered PDF.
+func SignDownload(ctx context.Context, objectKey string, expires time.Duration) (string, time.Time, error) {
+	if GetStorageProvider() != StorageProviderGCS {
+		return "", time.Time{}, fmt.Errorf("storage provider %q is not supported for signed downloads", GetStorageProvider())
+	}
+
+	bucket := strings.TrimSpace(os.Getenv("GCS_BUCKET"))
+	if bucket == "" {
+		return "", time.Time{}, errors.New("GCS_BUCKET is required")
+	}
+
+	opts := &storage.SignedURLOptions{
+		Scheme:  storage.SigningSchemeV4,
+		Method:  "GET",
+		Expires: time.Now().Add(expires),
+	}
+	if err := applySigner(ctx, opts); err != nil {
+		return "", time.Time{}, err
+	}
+
+	signedURL, err := storage.SignedURL(bucket, objectKey, opts)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signedURL, opts.Expires, nil
+}
+
+func applySigner(ctx context.Context, opts *storage.SignedURLOptions) error {
+	accessID, privateKey, ok, err := loadSignerFromEnv()
+	if err != nil {
+		return err
+	}
+	if ok {
+		opts.GoogleAccessID = accessID
+		opts.PrivateKey = privateKey
+		return nil
+	}
+	email, signBytes, err := iamSigner(ctx)
+	if err != nil {
+		return err
+	}
+	opts.GoogleAccessID = email
+	opts.SignBytes = signBytes
+	return nil
+}
+
 func loadSignerFromEnv() (string, []byte, bool, error) {
 	credJSON := strings.TrimSpace(os.Getenv("GCS_CREDENTIALS_JSON"))
 	if credJSON != "" {
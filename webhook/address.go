@@ -0,0 +1,72 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"syscall"
+)
+
+// ErrBlockedAddress is returned for endpoints that resolve to an address on the server's own
+// network: loopback, private, link-local (which includes cloud metadata services), multicast
+// and unspecified addresses. Deliveries must only reach the public internet.
+var ErrBlockedAddress = errors.New("webhook url must resolve to a public address")
+
+// carrierGradeNAT is the shared address space of RFC 6598, internal like the private ranges.
+var carrierGradeNAT = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func blockedIP(ip net.IP) bool {
+	return ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified() ||
+		carrierGradeNAT.Contains(ip) ||
+		ip.Equal(net.IPv4bcast)
+}
+
+// CheckURL resolves the url's host and rejects it when any of its addresses is blocked.
+func CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	host := u.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if blockedIP(ip) {
+			return ErrBlockedAddress
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("cannot resolve %s", host)
+	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("cannot resolve %s", host)
+	}
+	for _, addr := range addrs {
+		if blockedIP(addr.IP) {
+			return ErrBlockedAddress
+		}
+	}
+	return nil
+}
+
+// dialControl checks the address actually dialed, after name resolution, so a host that
+// passed CheckURL cannot be rebound to an internal address later.
+func dialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || blockedIP(ip) {
+		return ErrBlockedAddress
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// maxResponseRead is how much of the receiver's response is read at all.
+	maxResponseRead = 1024
+	// maxResponseSnippet is how many characters of it are kept in the delivery log.
+	maxResponseSnippet = 200
+)
+
+// Request is one signed delivery of an event to an endpoint.
+type Request struct {
+	URL        string
+	Secret     string
+	Event      string
+	DeliveryId string
+	Payload    []byte
+}
+
+// Response is what the receiver answered. StatusCode is 0 when no response arrived.
+// Body is a short printable snippet of the answer, not the raw body.
+type Response struct {
+	StatusCode int
+	Body       string
+	Duration   time.Duration
+}
+
+// Client posts signed JSON deliveries. Any 2xx answer counts as delivered;
+// redirects are not followed so a delivery cannot be bounced to another host.
+// NewClient's transport refuses to connect to blocked addresses and uses no proxy,
+// so the check holds for whatever the endpoint's host resolves to when it is dialed.
+type Client struct {
+	HTTPClient *http.Client
+	UserAgent  string
+}
+
+func NewClient(timeout time.Duration) *Client {
+	dialer := &net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: 30 * time.Second,
+		Control:   dialControl,
+	}
+	return &Client{
+		HTTPClient: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				Proxy:                 nil,
+				DialContext:           dialer.DialContext,
+				ForceAttemptHTTP2:     true,
+				MaxIdleConns:          100,
+				IdleConnTimeout:       90 * time.Second,
+				TLSHandshakeTimeout:   10 * time.Second,
+				ExpectContinueTimeout: time.Second,
+			},
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		UserAgent: "Books-Webhooks/1.0",
+	}
+}
+
+func (c *Client) Deliver(ctx context.Context, r *Request) (*Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.URL, bytes.NewReader(r.Payload))
+	if err != nil {
+		return &Response{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", c.UserAgent)
+	req.Header.Set(HeaderEvent, r.Event)
+	req.Header.Set(HeaderDelivery, r.DeliveryId)
+	req.Header.Set(HeaderSignature, Sign(r.Secret, time.Now(), r.Payload))
+
+	start := time.Now()
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return &Response{Duration: time.Since(start)}, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseRead))
+	out := &Response{StatusCode: resp.StatusCode, Body: responseSnippet(body), Duration: time.Since(start)}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return out, fmt.Errorf("endpoint responded %s", resp.Status)
+	}
+	return out, nil
+}
+
+// responseSnippet keeps the first printable characters of a response, with runs of
+// whitespace and control characters collapsed to single spaces.
+func responseSnippet(body []byte) string {
+	var b strings.Builder
+	n := 0
+	space := false
+	for _, r := range string(body) {
+		if n >= maxResponseSnippet {
+			break
+		}
+		if r == unicode.ReplacementChar || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			space = b.Len() > 0
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			n++
+			space = false
+		}
+		b.WriteRune(r)
+		n++
+	}
+	return b.String()
+}
@@ -0,0 +1,143 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/webhook"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	secret := "whsec_test"
+	payload := []byte(`{"event":"invoice.created"}`)
+	now := time.Unix(1700000000, 0)
+
+	header := webhook.Sign(secret, now, payload)
+	if !strings.HasPrefix(header, "t=1700000000,v1=") {
+		t.Fatalf("unexpected header %q", header)
+	}
+	if err := webhook.Verify(secret, header, payload, now.Add(time.Minute), 5*time.Minute); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if err := webhook.Verify("other", header, payload, now, 5*time.Minute); err == nil {
+		t.Error("a different secret should not verify")
+	}
+	if err := webhook.Verify(secret, header, []byte(`{"event":"bill.confirmed"}`), now, 5*time.Minute); err == nil {
+		t.Error("a modified payload should not verify")
+	}
+	if err := webhook.Verify(secret, header, payload, now.Add(time.Hour), 5*time.Minute); err == nil {
+		t.Error("an old signature should be rejected")
+	}
+}
+
+func TestClientDeliver(t *testing.T) {
+	secret := "whsec_test"
+	var got *http.Request
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		if r.URL.Path == "/fail" {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	client := webhook.NewClient(5 * time.Second)
+	// the test server listens on loopback, which the default transport refuses
+	client.HTTPClient.Transport = srv.Client().Transport
+	req := &webhook.Request{URL: srv.URL, Secret: secret, Event: "invoice.created", DeliveryId: "d-1", Payload: []byte(`{}`)}
+	resp, err := client.Deliver(context.Background(), req)
+	if err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Body != "ok" {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	if got.Header.Get(webhook.HeaderEvent) != "invoice.created" || got.Header.Get(webhook.HeaderDelivery) != "d-1" {
+		t.Errorf("missing event headers: %v", got.Header)
+	}
+	if err := webhook.Verify(secret, got.Header.Get(webhook.HeaderSignature), body, time.Now(), time.Minute); err != nil {
+		t.Errorf("signature does not verify: %v", err)
+	}
+
+	req.URL = srv.URL + "/fail"
+	resp, err = client.Deliver(context.Background(), req)
+	if err == nil {
+		t.Fatal("a 500 response should fail the delivery")
+	}
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("status code = %d, want 500", resp.StatusCode)
+	}
+}
+
+func TestClientRefusesInternalAddresses(t *testing.T) {
+	reached := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+		w.Write([]byte("secret"))
+	}))
+	defer srv.Close()
+
+	client := webhook.NewClient(5 * time.Second)
+	resp, err := client.Deliver(context.Background(), &webhook.Request{URL: srv.URL, Secret: "whsec_test", Event: "invoice.created", DeliveryId: "d-1", Payload: []byte(`{}`)})
+	if !errors.Is(err, webhook.ErrBlockedAddress) {
+		t.Fatalf("deliver to loopback: err = %v, want ErrBlockedAddress", err)
+	}
+	if reached || resp.StatusCode != 0 || resp.Body != "" {
+		t.Errorf("loopback server was reached: %+v", resp)
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	for _, rawURL := range []string{
+		"http://169.254.169.254/latest/meta-data/",
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://10.1.2.3/hook",
+		"http://192.168.0.10/hook",
+		"http://172.16.0.1/hook",
+		"http://100.64.0.1/hook",
+		"http://0.0.0.0/hook",
+		"http://[::1]/hook",
+		"http://[fe80::1]/hook",
+		"http://[::ffff:127.0.0.1]/hook",
+		"http://224.0.0.1/hook",
+	} {
+		if err := webhook.CheckURL(context.Background(), rawURL); !errors.Is(err, webhook.ErrBlockedAddress) {
+			t.Errorf("CheckURL(%s) = %v, want ErrBlockedAddress", rawURL, err)
+		}
+	}
+	if err := webhook.CheckURL(context.Background(), "https://93.184.216.34/hook"); err != nil {
+		t.Errorf("public address rejected: %v", err)
+	}
+}
+
+func TestClientResponseSnippet(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("  bad\r\n\x00request\t" + strings.Repeat("x", 4096)))
+	}))
+	defer srv.Close()
+
+	client := webhook.NewClient(5 * time.Second)
+	client.HTTPClient.Transport = srv.Client().Transport
+	resp, err := client.Deliver(context.Background(), &webhook.Request{URL: srv.URL, Secret: "whsec_test", Event: "invoice.created", DeliveryId: "d-1", Payload: []byte(`{}`)})
+	if err == nil {
+		t.Fatal("a 400 response should fail the delivery")
+	}
+	if !strings.HasPrefix(resp.Body, "bad request x") {
+		t.Errorf("snippet = %q, want control characters collapsed", resp.Body[:20])
+	}
+	if n := len([]rune(resp.Body)); n != 200 {
+		t.Errorf("snippet length = %d, want 200", n)
+	}
+}
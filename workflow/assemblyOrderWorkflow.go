@@ -0,0 +1,553 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+func ProcessAssemblyOrderWorkflow(tx *gorm.DB, logger *logrus.Logger, msg config.PubSubMessage) error {
+
+	var accountJournalId int
+	var accountIds []int
+	var stockHistories []*models.StockHistory
+	business, err := models.GetBusinessById2(tx, msg.BusinessId)
+	if err != nil {
+		config.LogError(logger, "AssemblyOrderWorkflow.go", "ProcessAssemblyOrderWorkflow", "GetBusiness", msg.BusinessId, err)
+		return err
+	}
+	if msg.Action == string(models.PubSubMessageActionCreate) {
+
+		var assemblyOrder models.AssemblyOrder
+		err := json.Unmarshal([]byte(msg.NewObj), &assemblyOrder)
+		if err != nil {
+			config.LogError(logger, "AssemblyOrderWorkflow.go", "ProcessAssemblyOrderWorkflow > Create", "Unmarshal msg.NewObj", msg.NewObj, err)
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		ctx = context.WithValue(ctx, utils.ContextKeyBusinessId, assemblyOrder.BusinessId)
+		warehouse, err := models.GetWarehouse(ctx, assemblyOrder.WarehouseId)
+		if err != nil {
+			config.LogError(logger, "AssemblyOrderWorkflow.go", "ProcessAssemblyOrderWorkflow > Create", "GetWarehouse", assemblyOrder.WarehouseId, err)
+			return err
+		}
+
+		accountJournalId, accountIds, err = CreateAssemblyOrder(tx, logger, msg.BusinessId, *business, assemblyOrder, *warehouse)
+		if err != nil {
+			config.LogError(logger, "AssemblyOrderWorkflow.go", "ProcessAssemblyOrderWorkflow > Create", "CreateAssemblyOrder", nil, err)
+			return err
+		}
+
+		err = UpdateBalances(tx, logger, msg.BusinessId, business.BaseCurrencyId, warehouse.BranchId, accountIds, assemblyOrder.OrderDate, business.BaseCurrencyId)
+		if err != nil {
+			config.LogError(logger, "AssemblyOrderWorkflow.go", "ProcessAssemblyOrderWorkflow > Create", "UpdateBalances", nil, err)
+			return err
+		}
+	} else if msg.Action == string(models.PubSubMessageActionDelete) {
+		var oldAssemblyOrder models.AssemblyOrder
+		err := json.Unmarshal([]byte(msg.OldObj), &oldAssemblyOrder)
+		if err != nil {
+			config.LogError(logger, "AssemblyOrderWorkflow.go", "ProcessAssemblyOrderWorkflow > Delete", "Unmarshal msg.OldObj", msg.OldObj, err)
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		ctx = context.WithValue(ctx, utils.ContextKeyBusinessId, oldAssemblyOrder.BusinessId)
+		warehouse, err := models.GetWarehouse(ctx, oldAssemblyOrder.WarehouseId)
+		if err != nil {
+			config.LogError(logger, "AssemblyOrderWorkflow.go", "ProcessAssemblyOrderWorkflow > Delete", "GetWarehouse", oldAssemblyOrder.WarehouseId, err)
+			return err
+		}
+
+		accountJournalId, accountIds, stockHistories, err = DeleteAssemblyOrder(tx, logger, msg.BusinessId, oldAssemblyOrder)
+		if err != nil {
+			config.LogError(logger, "AssemblyOrderWorkflow.go", "ProcessAssemblyOrderWorkflow > Delete", "DeleteAssemblyOrder", nil, err)
+			return err
+		}
+		valuationAccountIds, err := ProcessStockHistories(tx, logger, stockHistories)
+		if err != nil {
+			if scope, ok := parseFifoInsufficientScope(err); ok {
+				if rerr := rebuildInventoryForScope(tx, logger, msg.BusinessId, scope, oldAssemblyOrder.OrderDate); rerr == nil {
+					valuationAccountIds, err = ProcessStockHistories(tx, logger, stockHistories)
+				}
+			}
+		}
+		if err != nil {
+			config.LogError(logger, "AssemblyOrderWorkflow.go", "ProcessAssemblyOrderWorkflow > Delete", "ProcessStockHistories", stockHistories, err)
+			return err
+		}
+		for _, accId := range valuationAccountIds {
+			if !slices.Contains(accountIds, accId) {
+				accountIds = append(accountIds, accId)
+			}
+		}
+		err = UpdateBalances(tx, logger, msg.BusinessId, business.BaseCurrencyId, warehouse.BranchId, accountIds, oldAssemblyOrder.OrderDate, business.BaseCurrencyId)
+		if err != nil {
+			config.LogError(logger, "AssemblyOrderWorkflow.go", "ProcessAssemblyOrderWorkflow > Delete", "UpdateBalances", nil, err)
+			return err
+		}
+	}
+	err = tx.Model(&models.PubSubMessageRecord{}).Where("id=?", msg.ID).Updates(map[string]interface{}{"account_journal_id": accountJournalId, "is_processed": true}).Error
+	if err != nil {
+		config.LogError(logger, "AssemblyOrderWorkflow.go", "ProcessAssemblyOrderWorkflow", "UpdatePubSubMessageRecord", accountJournalId, err)
+		return err
+	}
+	return nil
+}
+
+// CreateAssemblyOrder posts an assembly order in two journals, mirroring transfer orders:
+//   - consumption (IsTransferIn=false): DR Work In Progress, CR Inventory of consumed lines
+//   - production (IsTransferIn=true):   DR Inventory of produced lines, CR Work In Progress,
+//     CR the order's cost account for labour and overhead
+//
+// The consumption journal is created as a zero placeholder and valued by FIFO through
+// ProcessOutgoingStocks; the produced lines are then costed at the consumed value plus
+// labour and overhead.
+func CreateAssemblyOrder(tx *gorm.DB, logger *logrus.Logger, businessId string, business models.Business, assemblyOrder models.AssemblyOrder, warehouse models.Warehouse) (int, []int, error) {
+
+	systemAccounts, err := models.GetSystemAccounts(businessId)
+	if err != nil {
+		config.LogError(logger, "AssemblyOrderWorkflow.go", "CreateAssemblyOrder", "GetSystemAccounts", businessId, err)
+		return 0, nil, err
+	}
+	wip := systemAccounts[models.AccountCodeWorkInProgress]
+	if wip == 0 {
+		return 0, nil, errors.New("work in progress account not found")
+	}
+
+	transactionTime := assemblyOrder.OrderDate
+	baseCurrencyId := business.BaseCurrencyId
+	stockDate, err := utils.ConvertToDate(assemblyOrder.OrderDate, business.Timezone)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	accountIds := []int{wip}
+	consumedStockHistories := make([]*models.StockHistory, 0)
+	consumedInventoryAccounts := make([]int, 0)
+	for _, line := range assemblyOrder.ConsumedLines() {
+		productDetail, err := GetProductDetail(tx, line.ProductId, line.ProductType)
+		if err != nil {
+			config.LogError(logger, "AssemblyOrderWorkflow.go", "CreateAssemblyOrder", "GetProductDetail", line, err)
+			return 0, nil, err
+		}
+		if !slices.Contains(consumedInventoryAccounts, productDetail.InventoryAccountId) {
+			consumedInventoryAccounts = append(consumedInventoryAccounts, productDetail.InventoryAccountId)
+		}
+
+		stockHistory := models.StockHistory{
+			BusinessId:        businessId,
+			WarehouseId:       warehouse.ID,
+			ProductId:         line.ProductId,
+			ProductType:       line.ProductType,
+			BatchNumber:       line.BatchNumber,
+			StockDate:         stockDate,
+			Qty:               line.Qty.Neg(),
+			BaseUnitValue:     decimal.NewFromInt(0),
+			Description:       "Assembly Consumed",
+			ReferenceType:     models.StockReferenceTypeAssemblyOrder,
+			ReferenceID:       assemblyOrder.ID,
+			ReferenceDetailID: line.DetailId,
+			IsOutgoing:        utils.NewTrue(),
+			IsTransferIn:      utils.NewFalse(),
+		}
+		if err := tx.Create(&stockHistory).Error; err != nil {
+			config.LogError(logger, "AssemblyOrderWorkflow.go", "CreateAssemblyOrder", "CreateConsumedStockHistory", stockHistory, err)
+			return 0, nil, err
+		}
+		consumedStockHistories = append(consumedStockHistories, &stockHistory)
+	}
+
+	// Placeholder consumption journal; the valuation repost in CalculateCogs fills it in.
+	consumptionTransactions := []models.AccountTransaction{
+		assemblyValuationLine(businessId, wip, warehouse.BranchId, transactionTime, baseCurrencyId, decimal.Zero, decimal.Zero, false),
+	}
+	for _, inventoryAccId := range consumedInventoryAccounts {
+		if !slices.Contains(accountIds, inventoryAccId) {
+			accountIds = append(accountIds, inventoryAccId)
+		}
+		consumptionTransactions = append(consumptionTransactions,
+			assemblyValuationLine(businessId, inventoryAccId, warehouse.BranchId, transactionTime, baseCurrencyId, decimal.Zero, decimal.Zero, false))
+	}
+	consumptionJournal := models.AccountJournal{
+		BusinessId:          businessId,
+		BranchId:            warehouse.BranchId,
+		TransactionDateTime: transactionTime,
+		TransactionNumber:   strconv.Itoa(assemblyOrder.ID),
+		ReferenceId:         assemblyOrder.ID,
+		ReferenceType:       models.AccountReferenceTypeAssemblyOrder,
+		AccountTransactions: consumptionTransactions,
+	}
+	if err := tx.Create(&consumptionJournal).Error; err != nil {
+		config.LogError(logger, "AssemblyOrderWorkflow.go", "CreateAssemblyOrder", "CreateConsumptionJournal", consumptionJournal, err)
+		return 0, nil, err
+	}
+
+	valuationAccountIds, err := ProcessOutgoingStocks(tx, logger, consumedStockHistories)
+	if err != nil {
+		if scope, ok := parseFifoInsufficientScope(err); ok {
+			if rerr := rebuildInventoryForScope(tx, logger, businessId, scope, assemblyOrder.OrderDate); rerr == nil {
+				valuationAccountIds, err = ProcessOutgoingStocks(tx, logger, consumedStockHistories)
+			}
+		}
+		if err != nil {
+			config.LogError(logger, "AssemblyOrderWorkflow.go", "CreateAssemblyOrder", "ProcessOutgoingStocks", consumedStockHistories, err)
+			return 0, nil, err
+		}
+	}
+	for _, accId := range valuationAccountIds {
+		if !slices.Contains(accountIds, accId) {
+			accountIds = append(accountIds, accId)
+		}
+	}
+
+	consumedCost, err := assemblyOrderConsumedCost(tx, businessId, assemblyOrder.ID)
+	if err != nil {
+		config.LogError(logger, "AssemblyOrderWorkflow.go", "CreateAssemblyOrder", "GetConsumedCost", assemblyOrder.ID, err)
+		return 0, nil, err
+	}
+	producedStockHistories, producedValues, err := assemblyOrderOutput(tx, assemblyOrder, stockDate, consumedCost)
+	if err != nil {
+		config.LogError(logger, "AssemblyOrderWorkflow.go", "CreateAssemblyOrder", "AssemblyOrderOutput", assemblyOrder.ID, err)
+		return 0, nil, err
+	}
+
+	productionTransactions := make([]models.AccountTransaction, 0)
+	producedTotal := decimal.Zero
+	for _, inventoryAccId := range sortedAccountIds(producedValues) {
+		if !slices.Contains(accountIds, inventoryAccId) {
+			accountIds = append(accountIds, inventoryAccId)
+		}
+		producedTotal = producedTotal.Add(producedValues[inventoryAccId])
+		productionTransactions = append(productionTransactions,
+			assemblyValuationLine(businessId, inventoryAccId, warehouse.BranchId, transactionTime, baseCurrencyId, producedValues[inventoryAccId], decimal.Zero, true))
+	}
+	// Unit costs are rounded to four places, so Work In Progress is credited with what the
+	// produced stock carries less labour and overhead; any rounding residue stays in WIP.
+	addedCost := assemblyOrder.LabourCost.Add(assemblyOrder.OverheadCost)
+	productionTransactions = append(productionTransactions,
+		assemblyValuationLine(businessId, wip, warehouse.BranchId, transactionTime, baseCurrencyId, decimal.Zero, producedTotal.Sub(addedCost), true))
+	if addedCost.IsPositive() {
+		if !slices.Contains(accountIds, assemblyOrder.CostAccountId) {
+			accountIds = append(accountIds, assemblyOrder.CostAccountId)
+		}
+		productionTransactions = append(productionTransactions, models.AccountTransaction{
+			BusinessId:           businessId,
+			AccountId:            assemblyOrder.CostAccountId,
+			BranchId:             warehouse.BranchId,
+			TransactionDateTime:  transactionTime,
+			BaseCurrencyId:       baseCurrencyId,
+			BaseDebit:            decimal.NewFromInt(0),
+			BaseCredit:           addedCost,
+			IsInventoryValuation: utils.NewFalse(),
+			IsTransferIn:         utils.NewTrue(),
+		})
+	}
+	productionJournal := models.AccountJournal{
+		BusinessId:          businessId,
+		BranchId:            warehouse.BranchId,
+		TransactionDateTime: transactionTime,
+		TransactionNumber:   strconv.Itoa(assemblyOrder.ID),
+		ReferenceId:         assemblyOrder.ID,
+		ReferenceType:       models.AccountReferenceTypeAssemblyOrder,
+		AccountTransactions: productionTransactions,
+	}
+	if err := tx.Create(&productionJournal).Error; err != nil {
+		config.LogError(logger, "AssemblyOrderWorkflow.go", "CreateAssemblyOrder", "CreateProductionJournal", productionJournal, err)
+		return 0, nil, err
+	}
+
+	for _, stockHistory := range producedStockHistories {
+		if err := tx.Create(stockHistory).Error; err != nil {
+			config.LogError(logger, "AssemblyOrderWorkflow.go", "CreateAssemblyOrder", "CreateProducedStockHistory", stockHistory, err)
+			return 0, nil, err
+		}
+	}
+	valuationAccountIds, err = ProcessIncomingStocks(tx, logger, producedStockHistories)
+	if err != nil {
+		config.LogError(logger, "AssemblyOrderWorkflow.go", "CreateAssemblyOrder", "ProcessIncomingStocks", producedStockHistories, err)
+		return 0, nil, err
+	}
+	for _, accId := range valuationAccountIds {
+		if !slices.Contains(accountIds, accId) {
+			accountIds = append(accountIds, accId)
+		}
+	}
+
+	return consumptionJournal.ID, accountIds, nil
+}
+
+// DeleteAssemblyOrder reverses both journals and every active stock row of an assembly order.
+func DeleteAssemblyOrder(tx *gorm.DB, logger *logrus.Logger, businessId string, oldAssemblyOrder models.AssemblyOrder) (int, []int, []*models.StockHistory, error) {
+	var journals []models.AccountJournal
+	if err := tx.Preload("AccountTransactions").
+		Where("business_id = ? AND reference_id = ? AND reference_type = ? AND is_reversal = 0 AND reversed_by_journal_id IS NULL", businessId, oldAssemblyOrder.ID, models.AccountReferenceTypeAssemblyOrder).
+		Find(&journals).Error; err != nil {
+		config.LogError(logger, "AssemblyOrderWorkflow.go", "DeleteAssemblyOrder", "FindAccountJournals", oldAssemblyOrder, err)
+		return 0, nil, nil, err
+	}
+	accountIds := make([]int, 0)
+	reversalID := 0
+	for _, j := range journals {
+		for _, t := range j.AccountTransactions {
+			if !slices.Contains(accountIds, t.AccountId) {
+				accountIds = append(accountIds, t.AccountId)
+			}
+		}
+		rid, err := ReverseAccountJournal(tx, &j, ReversalReasonAssemblyOrderDelete)
+		if err != nil {
+			config.LogError(logger, "AssemblyOrderWorkflow.go", "DeleteAssemblyOrder", "ReverseAccountJournal", j, err)
+			return 0, nil, nil, err
+		}
+		if reversalID == 0 {
+			reversalID = rid
+		}
+	}
+
+	var stockHistories []*models.StockHistory
+	if err := tx.
+		Where("business_id = ? AND reference_id = ? AND reference_type = ? AND is_reversal = 0 AND reversed_by_stock_history_id IS NULL", businessId, oldAssemblyOrder.ID, models.StockReferenceTypeAssemblyOrder).
+		Find(&stockHistories).Error; err != nil {
+		config.LogError(logger, "AssemblyOrderWorkflow.go", "DeleteAssemblyOrder", "FindStockHistories", oldAssemblyOrder, err)
+		return 0, nil, nil, err
+	}
+	stockReversals, err := ReverseStockHistories(tx, stockHistories, ReversalReasonAssemblyOrderDelete)
+	if err != nil {
+		config.LogError(logger, "AssemblyOrderWorkflow.go", "DeleteAssemblyOrder", "ReverseStockHistories", oldAssemblyOrder, err)
+		return 0, nil, nil, err
+	}
+
+	return reversalID, accountIds, stockReversals, nil
+}
+
+// SyncAssemblyOrderOutputFromConsumption re-costs the produced stock rows of an assembly
+// order after its consumed rows were repriced, and reposts the production journal by the
+// change in value. It does nothing before the produced rows exist (initial posting) or
+// once the order has been deleted.
+func SyncAssemblyOrderOutputFromConsumption(tx *gorm.DB, logger *logrus.Logger, businessId string, assemblyOrderId int) ([]int, error) {
+	if tx == nil {
+		return nil, fmt.Errorf("assembly order sync: tx is nil")
+	}
+
+	var inRows []*models.StockHistory
+	if err := tx.
+		Where("business_id = ? AND reference_type = ? AND reference_id = ? AND is_reversal = 0 AND reversed_by_stock_history_id IS NULL AND is_outgoing = 0",
+			businessId, models.StockReferenceTypeAssemblyOrder, assemblyOrderId).
+		Order("id").
+		Find(&inRows).Error; err != nil {
+		return nil, err
+	}
+	if len(inRows) == 0 {
+		return nil, nil
+	}
+
+	var assemblyOrder models.AssemblyOrder
+	err := tx.Preload("Details").Where("business_id = ? AND id = ?", businessId, assemblyOrderId).First(&assemblyOrder).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	consumedCost, err := assemblyOrderConsumedCost(tx, businessId, assemblyOrderId)
+	if err != nil {
+		return nil, err
+	}
+	desired, desiredValues, err := assemblyOrderOutput(tx, assemblyOrder, inRows[0].StockDate, consumedCost)
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct {
+		pid int
+		pt  models.ProductType
+		b   string
+		rdi int
+		q   string
+		uv  string
+	}
+	rowKey := func(r *models.StockHistory) key {
+		return key{r.ProductId, r.ProductType, r.BatchNumber, r.ReferenceDetailID, r.Qty.String(), r.BaseUnitValue.String()}
+	}
+	unchanged := len(desired) == len(inRows)
+	if unchanged {
+		existing := make(map[key]int)
+		for _, r := range inRows {
+			existing[rowKey(r)]++
+		}
+		for _, r := range desired {
+			k := rowKey(r)
+			if existing[k] == 0 {
+				unchanged = false
+				break
+			}
+			existing[k]--
+		}
+	}
+	if unchanged {
+		return nil, nil
+	}
+
+	existingValues := make(map[int]decimal.Decimal)
+	for _, r := range inRows {
+		productDetail, err := GetProductDetail(tx, r.ProductId, r.ProductType)
+		if err != nil {
+			return nil, err
+		}
+		existingValues[productDetail.InventoryAccountId] = existingValues[productDetail.InventoryAccountId].Add(r.Qty.Mul(r.BaseUnitValue))
+	}
+
+	if _, err := ReverseStockHistories(tx, inRows, ReversalReasonInventoryValuationReprice); err != nil {
+		return nil, err
+	}
+	for _, r := range desired {
+		if err := tx.Create(r).Error; err != nil {
+			return nil, err
+		}
+	}
+	accountIds, err := ProcessIncomingStocks(tx, logger, desired)
+	if err != nil {
+		return accountIds, err
+	}
+
+	systemAccounts, err := models.GetSystemAccounts(businessId)
+	if err != nil {
+		return accountIds, err
+	}
+	wip := systemAccounts[models.AccountCodeWorkInProgress]
+	deltas := make(map[int]valuationDelta)
+	wipDelta := decimal.Zero
+	for accId := range existingValues {
+		if _, ok := desiredValues[accId]; !ok {
+			desiredValues[accId] = decimal.Zero
+		}
+	}
+	for accId, value := range desiredValues {
+		delta := value.Sub(existingValues[accId])
+		if delta.IsZero() {
+			continue
+		}
+		deltas[accId] = valuationDelta{BaseDebit: delta}
+		wipDelta = wipDelta.Add(delta)
+		if !slices.Contains(accountIds, accId) {
+			accountIds = append(accountIds, accId)
+		}
+	}
+	if len(deltas) == 0 {
+		return accountIds, nil
+	}
+	deltas[wip] = valuationDelta{BaseDebit: deltas[wip].BaseDebit, BaseCredit: wipDelta}
+	if !slices.Contains(accountIds, wip) {
+		accountIds = append(accountIds, wip)
+	}
+	if _, _, err := repostJournalWithValuationDeltas(
+		tx,
+		logger,
+		businessId,
+		models.AccountReferenceTypeAssemblyOrder,
+		assemblyOrderId,
+		deltas,
+		utils.NewTrue(),
+		ReversalReasonInventoryValuationReprice,
+	); err != nil {
+		return accountIds, err
+	}
+	return accountIds, nil
+}
+
+// assemblyOrderConsumedCost is the FIFO value of an order's active consumed rows.
+func assemblyOrderConsumedCost(tx *gorm.DB, businessId string, assemblyOrderId int) (decimal.Decimal, error) {
+	var outRows []*models.StockHistory
+	if err := tx.
+		Where("business_id = ? AND reference_type = ? AND reference_id = ? AND is_reversal = 0 AND reversed_by_stock_history_id IS NULL AND is_outgoing = 1",
+			businessId, models.StockReferenceTypeAssemblyOrder, assemblyOrderId).
+		Find(&outRows).Error; err != nil {
+		return decimal.Zero, err
+	}
+	total := decimal.Zero
+	for _, r := range outRows {
+		total = total.Add(r.Qty.Abs().Mul(r.BaseUnitValue))
+	}
+	return total, nil
+}
+
+// assemblyOrderOutput builds the unsaved produced stock rows, costed at the consumed value
+// plus labour and overhead, and their value per inventory account. Disassembled
+// components share the cost by purchase price.
+func assemblyOrderOutput(tx *gorm.DB, assemblyOrder models.AssemblyOrder, stockDate time.Time, consumedCost decimal.Decimal) ([]*models.StockHistory, map[int]decimal.Decimal, error) {
+	lines := assemblyOrder.ProducedLines()
+	qtys := make([]decimal.Decimal, len(lines))
+	weights := make([]decimal.Decimal, len(lines))
+	inventoryAccounts := make([]int, len(lines))
+	for i, line := range lines {
+		productDetail, err := GetProductDetail(tx, line.ProductId, line.ProductType)
+		if err != nil {
+			return nil, nil, err
+		}
+		qtys[i] = line.Qty
+		weights[i] = productDetail.PurchasePrice.Mul(line.Qty)
+		inventoryAccounts[i] = productDetail.InventoryAccountId
+	}
+	total := consumedCost.Add(assemblyOrder.LabourCost).Add(assemblyOrder.OverheadCost)
+	unitCosts := models.AllocateAssemblyCost(total, qtys, weights)
+
+	rows := make([]*models.StockHistory, len(lines))
+	values := make(map[int]decimal.Decimal)
+	for i, line := range lines {
+		rows[i] = &models.StockHistory{
+			BusinessId:        assemblyOrder.BusinessId,
+			WarehouseId:       assemblyOrder.WarehouseId,
+			ProductId:         line.ProductId,
+			ProductType:       line.ProductType,
+			BatchNumber:       line.BatchNumber,
+			StockDate:         stockDate,
+			Qty:               line.Qty,
+			BaseUnitValue:     unitCosts[i],
+			Description:       "Assembly Produced",
+			ReferenceType:     models.StockReferenceTypeAssemblyOrder,
+			ReferenceID:       assemblyOrder.ID,
+			ReferenceDetailID: line.DetailId,
+			IsOutgoing:        utils.NewFalse(),
+			IsTransferIn:      utils.NewFalse(),
+		}
+		values[inventoryAccounts[i]] = values[inventoryAccounts[i]].Add(line.Qty.Mul(unitCosts[i]))
+	}
+	return rows, values, nil
+}
+
+func assemblyValuationLine(businessId string, accountId int, branchId int, transactionTime time.Time, baseCurrencyId int, debit decimal.Decimal, credit decimal.Decimal, transferIn bool) models.AccountTransaction {
+	return models.AccountTransaction{
+		BusinessId:           businessId,
+		AccountId:            accountId,
+		BranchId:             branchId,
+		TransactionDateTime:  transactionTime,
+		BaseCurrencyId:       baseCurrencyId,
+		BaseDebit:            debit,
+		BaseCredit:           credit,
+		IsInventoryValuation: utils.NewTrue(),
+		IsTransferIn:         &transferIn,
+	}
+}
+
+func sortedAccountIds(values map[int]decimal.Decimal) []int {
+	ids := make([]int, 0, len(values))
+	for id := range values {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
@@ -315,30 +315,10 @@ func CreateBill(tx *gorm.DB, logger *logrus.Logger, recordId int, recordType str
 		return 0, nil, 0, nil, err
 	}
 	for _, billDetail := range bill.Details {
-		// Defensive: Bills sometimes arrive with missing DetailAccountId (0).
-		// Invoices/Credit Notes already guard this; Bills should too, otherwise journal creation can fail
-		// and the UI will show "No journal entries available".
-		detailAccountId := billDetail.DetailAccountId
-		if detailAccountId == 0 {
-			// Prefer product-derived accounts when possible.
-			if billDetail.ProductId > 0 {
-				productDetail, derr := GetProductDetail(tx, billDetail.ProductId, billDetail.ProductType)
-				if derr != nil {
-					config.LogError(logger, "BillWorkflow.go", "CreateBill", "GetProductDetail", billDetail, derr)
-					return 0, nil, 0, nil, derr
-				}
-				// Inventory purchases should debit inventory asset when available.
-				if productDetail.InventoryAccountId > 0 {
-					detailAccountId = productDetail.InventoryAccountId
-				} else if productDetail.PurchaseAccountId > 0 {
-					// Fallback for non-inventory products: use purchase/expense account.
-					detailAccountId = productDetail.PurchaseAccountId
-				}
-			}
-			// Final fallback: Other Expenses (keeps posting possible instead of dropping journal).
-			if detailAccountId == 0 {
-				detailAccountId = systemAccounts[models.AccountCodeOtherExpenses]
-			}
+		detailAccountId, derr := billDetailAccountId(tx, systemAccounts, billDetail)
+		if derr != nil {
+			config.LogError(logger, "BillWorkflow.go", "CreateBill", "GetProductDetail", billDetail, derr)
+			return 0, nil, 0, nil, derr
 		}
 
 		amount, ok := detailAccounts[detailAccountId]
@@ -464,3 +444,32 @@ func DeleteBill(tx *gorm.DB, logger *logrus.Logger, recordId int, recordType str
 
 	return reversalID, accountIds, foreignCurrencyId, stockReversals, nil
 }
+
+// billDetailAccountId is the account a bill line is debited to.
+func billDetailAccountId(tx *gorm.DB, systemAccounts map[string]int, billDetail models.BillDetail) (int, error) {
+	// Defensive: Bills sometimes arrive with missing DetailAccountId (0).
+	// Invoices/Credit Notes already guard this; Bills should too, otherwise journal creation can fail
+	// and the UI will show "No journal entries available".
+	detailAccountId := billDetail.DetailAccountId
+	if detailAccountId == 0 {
+		// Prefer product-derived accounts when possible.
+		if billDetail.ProductId > 0 {
+			productDetail, err := GetProductDetail(tx, billDetail.ProductId, billDetail.ProductType)
+			if err != nil {
+				return 0, err
+			}
+			// Inventory purchases should debit inventory asset when available.
+			if productDetail.InventoryAccountId > 0 {
+				detailAccountId = productDetail.InventoryAccountId
+			} else if productDetail.PurchaseAccountId > 0 {
+				// Fallback for non-inventory products: use purchase/expense account.
+				detailAccountId = productDetail.PurchaseAccountId
+			}
+		}
+		// Final fallback: Other Expenses (keeps posting possible instead of dropping journal).
+		if detailAccountId == 0 {
+			detailAccountId = systemAccounts[models.AccountCodeOtherExpenses]
+		}
+	}
+	return detailAccountId, nil
+}
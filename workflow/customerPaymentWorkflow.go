@@ -180,11 +180,20 @@ func CreateCustomerPayment(tx *gorm.DB, logger *logrus.Logger, recordId int, rec
 		}
 	}
 
+	// tax the customer withheld settles the invoices without reaching the deposit account
+	cashAmount := invoiceAmount.Sub(customerPayment.WithholdingTaxAmount)
+	baseWithheld := customerPayment.WithholdingTaxAmount
+	foreignWithheld := decimal.NewFromInt(0)
+	if baseCurrencyId != paymentCurrencyId {
+		foreignWithheld = customerPayment.WithholdingTaxAmount
+		baseWithheld = foreignWithheld.Mul(exchangeRate)
+	}
+
 	depositAccountCurrencyId := 0
 	if depositAccount.CurrencyId == 0 || depositAccount.CurrencyId == baseCurrencyId { // base-currency account
 		if baseCurrencyId != paymentCurrencyId {
 			// foreignTotalAmount = invoiceAmount.Add(customerPayment.BankCharges)
-			foreignTotalAmount = invoiceAmount.Sub(customerPayment.BankCharges)
+			foreignTotalAmount = cashAmount.Sub(customerPayment.BankCharges)
 			baseTotalAmount = foreignTotalAmount.Mul(exchangeRate)
 			foreignBankCharges = customerPayment.BankCharges
 			baseBankCharges = foreignBankCharges.Mul(exchangeRate)
@@ -192,14 +201,14 @@ func CreateCustomerPayment(tx *gorm.DB, logger *logrus.Logger, recordId int, rec
 			foreignCurrencyId = paymentCurrencyId
 		} else {
 			// baseTotalAmount = invoiceAmount.Add(customerPayment.BankCharges)
-			baseTotalAmount = invoiceAmount.Sub(customerPayment.BankCharges)
+			baseTotalAmount = cashAmount.Sub(customerPayment.BankCharges)
 			baseBankCharges = customerPayment.BankCharges
 			depositAccountCurrencyId = baseCurrencyId
 		}
 	} else { // foreign currency account
 		if baseCurrencyId != paymentCurrencyId {
 			// foreignTotalAmount = invoiceAmount.Add(customerPayment.BankCharges)
-			foreignTotalAmount = invoiceAmount.Sub(customerPayment.BankCharges)
+			foreignTotalAmount = cashAmount.Sub(customerPayment.BankCharges)
 			baseTotalAmount = foreignTotalAmount.Mul(exchangeRate)
 			foreignBankCharges = customerPayment.BankCharges
 			baseBankCharges = foreignBankCharges.Mul(exchangeRate)
@@ -208,7 +217,7 @@ func CreateCustomerPayment(tx *gorm.DB, logger *logrus.Logger, recordId int, rec
 		} else {
 			if exchangeRate.IsZero() {
 				// baseTotalAmount = invoiceAmount.Add(customerPayment.BankCharges)
-				baseTotalAmount = invoiceAmount.Sub(customerPayment.BankCharges)
+				baseTotalAmount = cashAmount.Sub(customerPayment.BankCharges)
 				foreignTotalAmount = decimal.NewFromInt(0)
 				baseBankCharges = customerPayment.BankCharges
 				foreignBankCharges = decimal.NewFromInt(0)
@@ -216,7 +225,7 @@ func CreateCustomerPayment(tx *gorm.DB, logger *logrus.Logger, recordId int, rec
 				foreignCurrencyId = depositAccount.CurrencyId
 			} else {
 				// baseTotalAmount = invoiceAmount.Add(customerPayment.BankCharges)
-				baseTotalAmount = invoiceAmount.Sub(customerPayment.BankCharges)
+				baseTotalAmount = cashAmount.Sub(customerPayment.BankCharges)
 				foreignTotalAmount = baseTotalAmount.DivRound(exchangeRate, 4)
 				baseBankCharges = customerPayment.BankCharges
 				foreignBankCharges = baseBankCharges.DivRound(exchangeRate, 4)
@@ -245,7 +254,7 @@ func CreateCustomerPayment(tx *gorm.DB, logger *logrus.Logger, recordId int, rec
 			FromAccountId:     systemAccounts[models.AccountCodeAccountsReceivable],
 			FromAccountAmount: invoiceAmount,
 			ToAccountId:       customerPayment.DepositAccountId,
-			ToAccountAmount:   invoiceAmount.Sub(customerPayment.BankCharges),
+			ToAccountAmount:   cashAmount.Sub(customerPayment.BankCharges),
 			CustomerId:        customerPayment.CustomerId,
 			TransactionDate:   customerPayment.PaymentDate,
 			TransactionId:     customerPayment.ID,
@@ -300,6 +309,31 @@ func CreateCustomerPayment(tx *gorm.DB, logger *logrus.Logger, recordId int, rec
 	accTransactions = append(accTransactions, depositAccountTransact)
 	accountIds = append(accountIds, customerPayment.DepositAccountId)
 
+	if !baseWithheld.IsZero() {
+		var withholdingTax models.WithholdingTax
+		err = tx.First(&withholdingTax, customerPayment.WithholdingTaxId).Error
+		if err != nil {
+			config.LogError(logger, "CustomerPaymentWorkflow.go", "CreateCustomerPayment", "GetWithholdingTax", customerPayment.WithholdingTaxId, err)
+			return 0, nil, 0, err
+		}
+		withholdingTaxReceivable := models.AccountTransaction{
+			BusinessId:           businessId,
+			AccountId:            withholdingTax.ReceivableAccountId,
+			BranchId:             branchId,
+			TransactionDateTime:  transactionTime,
+			BaseCurrencyId:       baseCurrencyId,
+			BaseCredit:           decimal.NewFromInt(0),
+			BaseDebit:            baseWithheld,
+			ForeignCurrencyId:    paymentCurrencyId,
+			ForeignCredit:        decimal.NewFromInt(0),
+			ForeignDebit:         foreignWithheld,
+			ExchangeRate:         exchangeRate,
+			BankingTransactionId: bankingTransactionId,
+		}
+		accTransactions = append(accTransactions, withholdingTaxReceivable)
+		accountIds = append(accountIds, withholdingTax.ReceivableAccountId)
+	}
+
 	if !baseInvoiceAmount.Equals(baseAmount) {
 		gainLossAmount := baseAmount.Sub(baseInvoiceAmount)
 		if gainLossAmount.IsPositive() {
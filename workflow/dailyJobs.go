@@ -0,0 +1,175 @@
+package workflow
+
+import (
+	"context"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// DailyJob runs one housekeeping task once a day, apart from the recurring scheduler's
+// per-minute document generation. Each job logs its failures under its own name, so a
+// failing job neither hides nor holds up the others. A run works through everything due,
+// a batch at a time; the tasks are idempotent, so a restart simply runs them again.
+type DailyJob struct {
+	Name   string
+	DB     *gorm.DB
+	Logger *logrus.Logger
+
+	BatchSize int
+	Interval  time.Duration
+
+	task func(ctx context.Context, j *DailyJob, now time.Time)
+}
+
+func newDailyJob(name string, db *gorm.DB, logger *logrus.Logger, task func(ctx context.Context, j *DailyJob, now time.Time)) *DailyJob {
+	return &DailyJob{
+		Name:      name,
+		DB:        db,
+		Logger:    logger,
+		BatchSize: 50,
+		Interval:  24 * time.Hour,
+		task:      task,
+	}
+}
+
+// NewFixedAssetDepreciationJob posts monthly depreciation once a month has ended.
+func NewFixedAssetDepreciationJob(db *gorm.DB, logger *logrus.Logger) *DailyJob {
+	return newDailyJob("FixedAssetDepreciation", db, logger, depreciateFixedAssets)
+}
+
+// NewEstimateExpiryJob expires sent estimates past their expiry date.
+func NewEstimateExpiryJob(db *gorm.DB, logger *logrus.Logger) *DailyJob {
+	return newDailyJob("EstimateExpiry", db, logger, expireEstimates)
+}
+
+// NewPaymentReminderJob sends payment reminders for invoices that reached a dunning level.
+func NewPaymentReminderJob(db *gorm.DB, logger *logrus.Logger) *DailyJob {
+	return newDailyJob("PaymentReminder", db, logger, sendPaymentReminders)
+}
+
+// NewCreditHoldJob puts overdue customers on credit hold and releases automatic holds.
+func NewCreditHoldJob(db *gorm.DB, logger *logrus.Logger) *DailyJob {
+	return newDailyJob("CreditHold", db, logger, applyCreditHolds)
+}
+
+func (j *DailyJob) Run(ctx context.Context) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		j.runOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(j.Interval):
+		}
+	}
+}
+
+func (j *DailyJob) runOnce(ctx context.Context) {
+	if j.DB == nil || j.task == nil {
+		return
+	}
+	j.task(ctx, j, time.Now().UTC())
+}
+
+func (j *DailyJob) logError(businessId string, id int, err error) {
+	if j.Logger == nil {
+		return
+	}
+	j.Logger.WithFields(logrus.Fields{
+		"field":       "DailyJob",
+		"job":         j.Name,
+		"business_id": businessId,
+		"id":          id,
+	}).Error(err.Error())
+}
+
+// processDue fetches due items a batch at a time until none are left. Each batch starts
+// after the last item of the one before, so items that stay due after failing are tried
+// once and never hold back the items behind them.
+func processDue[T any](j *DailyJob, fetch func(after *T) ([]*T, error), process func(item *T)) {
+	var after *T
+	for {
+		items, err := fetch(after)
+		if err != nil {
+			j.logError("", 0, err)
+			return
+		}
+		for _, item := range items {
+			process(item)
+		}
+		if len(items) < j.BatchSize {
+			return
+		}
+		after = items[len(items)-1]
+	}
+}
+
+func depreciateFixedAssets(ctx context.Context, j *DailyJob, now time.Time) {
+	processDue(j,
+		func(after *models.FixedAsset) ([]*models.FixedAsset, error) {
+			return models.GetDueFixedAssets(ctx, now, after, j.BatchSize)
+		},
+		func(fixedAsset *models.FixedAsset) {
+			assetCtx := recurringContext(ctx, fixedAsset.BusinessId)
+			if _, err := models.DepreciateFixedAsset(assetCtx, fixedAsset.ID, now); err != nil {
+				j.logError(fixedAsset.BusinessId, fixedAsset.ID, err)
+			}
+		})
+}
+
+func expireEstimates(ctx context.Context, j *DailyJob, now time.Time) {
+	processDue(j,
+		func(after *models.Estimate) ([]*models.Estimate, error) {
+			return models.GetDueExpiredEstimates(ctx, now, after, j.BatchSize)
+		},
+		func(estimate *models.Estimate) {
+			estimateCtx := recurringContext(ctx, estimate.BusinessId)
+			if err := models.ExpireEstimate(estimateCtx, estimate.ID, now); err != nil {
+				j.logError(estimate.BusinessId, estimate.ID, err)
+			}
+		})
+}
+
+func sendPaymentReminders(ctx context.Context, j *DailyJob, now time.Time) {
+	businessIds, err := models.GetPaymentReminderBusinessIds(ctx)
+	if err != nil {
+		j.logError("", 0, err)
+		return
+	}
+	for _, businessId := range businessIds {
+		reminderCtx := recurringContext(ctx, businessId)
+		for {
+			sent, err := models.SendPaymentReminders(reminderCtx, now, j.BatchSize)
+			if err != nil {
+				j.logError(businessId, 0, err)
+				break
+			}
+			if sent < j.BatchSize {
+				break
+			}
+		}
+	}
+}
+
+func applyCreditHolds(ctx context.Context, j *DailyJob, now time.Time) {
+	businessIds, err := models.GetCreditHoldBusinessIds(ctx)
+	if err != nil {
+		j.logError("", 0, err)
+		return
+	}
+	for _, businessId := range businessIds {
+		if _, err := models.ApplyAutoCreditHolds(recurringContext(ctx, businessId), now); err != nil {
+			j.logError(businessId, 0, err)
+		}
+	}
+}
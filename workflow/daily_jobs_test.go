@@ -0,0 +1,74 @@
+package workflow
+
+import "testing"
+
+type dueItem struct{ ID int }
+
+// dueFetch returns up to a batch of the due ids after the cursor, oldest first, the way
+// the due-item queries page through their rows.
+func dueFetch(j *DailyJob, due map[int]bool, last int) func(after *dueItem) ([]*dueItem, error) {
+	return func(after *dueItem) ([]*dueItem, error) {
+		from := 1
+		if after != nil {
+			from = after.ID + 1
+		}
+		var items []*dueItem
+		for id := from; id <= last && len(items) < j.BatchSize; id++ {
+			if due[id] {
+				items = append(items, &dueItem{ID: id})
+			}
+		}
+		return items, nil
+	}
+}
+
+// A daily run drains every due batch, and items that stay due after failing are tried
+// once rather than looping the job.
+func TestProcessDue_DrainsBatchesAndTriesFailuresOnce(t *testing.T) {
+	j := &DailyJob{Name: "Test", BatchSize: 2}
+	due := map[int]bool{1: true, 2: true, 3: true, 4: true, 5: true}
+	failing := map[int]bool{2: true}
+	processed := map[int]int{}
+
+	processDue(j, dueFetch(j, due, 5),
+		func(item *dueItem) {
+			processed[item.ID]++
+			if !failing[item.ID] {
+				delete(due, item.ID)
+			}
+		})
+
+	for id := 1; id <= 5; id++ {
+		if processed[id] != 1 {
+			t.Fatalf("item %d processed %d times, want 1", id, processed[id])
+		}
+	}
+	if len(due) != 1 || !due[2] {
+		t.Fatalf("still due = %v, want only the failing item", due)
+	}
+}
+
+// A whole batch of items that keep failing does not stop the items behind it.
+func TestProcessDue_FullBatchOfFailuresDoesNotBlockLaterItems(t *testing.T) {
+	j := &DailyJob{Name: "Test", BatchSize: 3}
+	due := map[int]bool{1: true, 2: true, 3: true, 4: true}
+	failing := map[int]bool{1: true, 2: true, 3: true}
+	processed := map[int]int{}
+
+	processDue(j, dueFetch(j, due, 4),
+		func(item *dueItem) {
+			processed[item.ID]++
+			if !failing[item.ID] {
+				delete(due, item.ID)
+			}
+		})
+
+	if processed[4] != 1 || due[4] {
+		t.Fatalf("item behind the failing batch processed %d times, still due %v", processed[4], due[4])
+	}
+	for id := 1; id <= 3; id++ {
+		if processed[id] != 1 {
+			t.Errorf("failing item %d processed %d times, want 1", id, processed[id])
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mmdatafocus/books_backend/mailer"
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/mmdatafocus/books_backend/pdfrender"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EmailDispatcher delivers queued models.EmailMessage rows. Claiming, retry backoff and
+// the DEAD state work like OutboxDispatcher; attachments are rendered at send time.
+type EmailDispatcher struct {
+	DB           *gorm.DB
+	Logger       *logrus.Logger
+	Transport    mailer.Transport
+	DispatcherID string
+
+	BatchSize      int
+	PollInterval   time.Duration
+	LockTimeout    time.Duration
+	MaxAttempts    int
+	InitialBackoff time.Duration
+}
+
+func NewEmailDispatcher(db *gorm.DB, logger *logrus.Logger, transport mailer.Transport) *EmailDispatcher {
+	return &EmailDispatcher{
+		DB:             db,
+		Logger:         logger,
+		Transport:      transport,
+		DispatcherID:   uuid.NewString(),
+		BatchSize:      20,
+		PollInterval:   5 * time.Second,
+		LockTimeout:    5 * time.Minute,
+		MaxAttempts:    8,
+		InitialBackoff: time.Minute,
+	}
+}
+
+func (d *EmailDispatcher) Run(ctx context.Context) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		d.dispatchOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(d.PollInterval):
+		}
+	}
+}
+
+func (d *EmailDispatcher) dispatchOnce(ctx context.Context) {
+	if d.DB == nil || d.Transport == nil {
+		return
+	}
+	now := time.Now().UTC()
+	staleBefore := now.Add(-d.LockTimeout)
+
+	var claimed []models.EmailMessage
+	err := d.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Eligible:
+		// - Pending / Failed and ready to retry
+		// - Processing but lock is stale (dispatcher crashed mid-send), reclaim after LockTimeout
+		q := tx.
+			Where(`
+				(
+					status IN ? AND (next_attempt_at IS NULL OR next_attempt_at <= ?)
+				)
+				OR
+				(
+					status = ? AND locked_at IS NOT NULL AND locked_at <= ?
+				)
+			`, []models.EmailMessageStatus{models.EmailMessageStatusPending, models.EmailMessageStatusFailed}, now,
+				models.EmailMessageStatusProcessing, staleBefore).
+			Order("id ASC").
+			Limit(d.BatchSize).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+		if err := q.Find(&claimed).Error; err != nil {
+			return err
+		}
+		for i := range claimed {
+			claimed[i].Status = models.EmailMessageStatusProcessing
+			claimed[i].Attempts++
+			if err := tx.Model(&models.EmailMessage{}).Where("id = ?", claimed[i].ID).Updates(map[string]interface{}{
+				"status":          models.EmailMessageStatusProcessing,
+				"locked_at":       &now,
+				"locked_by":       &d.DispatcherID,
+				"attempts":        gorm.Expr("attempts + 1"),
+				"next_attempt_at": nil,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		d.logError(0, "", err)
+		return
+	}
+
+	for i := range claimed {
+		msg := &claimed[i]
+		msgCtx := emailContext(ctx, msg)
+		sendErr := d.send(msgCtx, msg)
+		if sendErr != nil {
+			d.markFailed(msgCtx, msg, sendErr)
+			continue
+		}
+		d.markSent(msgCtx, msg)
+	}
+}
+
+// emailContext builds the context a message is rendered and logged under: its business
+// and the user who queued it, so History shows who sent the document.
+func emailContext(ctx context.Context, msg *models.EmailMessage) context.Context {
+	ctx = utils.SetBusinessIdInContext(ctx, msg.BusinessId)
+	ctx = utils.SetUserIdInContext(ctx, msg.RequestedById)
+	ctx = utils.SetUserNameInContext(ctx, msg.RequestedByName)
+	return utils.SetCorrelationIdInContext(ctx, uuid.NewString())
+}
+
+func (d *EmailDispatcher) send(ctx context.Context, msg *models.EmailMessage) error {
+	fromEmail := msg.FromEmail
+	if fromEmail == "" {
+		fromEmail = mailer.DefaultFromEmail()
+	}
+	out := &mailer.Message{
+		FromName:  msg.FromName,
+		FromEmail: fromEmail,
+		ReplyTo:   msg.ReplyTo,
+		To:        splitAddresses(msg.ToAddresses),
+		Cc:        splitAddresses(msg.CcAddresses),
+		Bcc:       splitAddresses(msg.BccAddresses),
+		Subject:   msg.Subject,
+		TextBody:  msg.Body,
+	}
+	if msg.AttachPdf {
+		data, name, err := renderAttachment(ctx, msg)
+		if err != nil {
+			return fmt.Errorf("render attachment: %w", err)
+		}
+		out.Attachments = append(out.Attachments, mailer.Attachment{FileName: name, ContentType: "application/pdf", Data: data})
+	}
+	return d.Transport.Send(ctx, out)
+}
+
+func renderAttachment(ctx context.Context, msg *models.EmailMessage) ([]byte, string, error) {
+	if msg.ReferenceType == models.DocumentTypeStatement {
+		if msg.StatementFromDate == nil || msg.StatementToDate == nil {
+			return nil, "", fmt.Errorf("statement period is missing")
+		}
+		return pdfrender.RenderCustomerStatement(ctx, msg.ReferenceId, *msg.StatementFromDate, *msg.StatementToDate)
+	}
+	return pdfrender.RenderDocument(ctx, msg.ReferenceType, msg.ReferenceId)
+}
+
+func splitAddresses(s string) []string {
+	var addrs []string
+	for _, addr := range strings.Split(s, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+func (d *EmailDispatcher) markSent(ctx context.Context, msg *models.EmailMessage) {
+	now := time.Now().UTC()
+	if err := d.DB.WithContext(ctx).Model(&models.EmailMessage{}).
+		Where("id = ?", msg.ID).
+		Updates(map[string]interface{}{
+			"status":          models.EmailMessageStatusSent,
+			"sent_at":         &now,
+			"last_error":      nil,
+			"locked_at":       nil,
+			"locked_by":       nil,
+			"next_attempt_at": nil,
+		}).Error; err != nil {
+		d.logError(msg.ID, msg.BusinessId, err)
+	}
+	if err := models.SaveEmailHistory(ctx, msg, nil); err != nil {
+		d.logError(msg.ID, msg.BusinessId, err)
+	}
+}
+
+func (d *EmailDispatcher) markFailed(ctx context.Context, msg *models.EmailMessage, sendErr error) {
+	db := d.DB.WithContext(ctx)
+	errMsg := sendErr.Error()
+
+	// Terminal after MaxAttempts; the failure is logged against the document once.
+	if d.MaxAttempts > 0 && msg.Attempts >= d.MaxAttempts {
+		if err := db.Model(&models.EmailMessage{}).
+			Where("id = ?", msg.ID).
+			Updates(map[string]interface{}{
+				"status":          models.EmailMessageStatusDead,
+				"last_error":      &errMsg,
+				"next_attempt_at": nil,
+				"locked_at":       nil,
+				"locked_by":       nil,
+			}).Error; err != nil {
+			d.logError(msg.ID, msg.BusinessId, err)
+		}
+		if err := models.SaveEmailHistory(ctx, msg, sendErr); err != nil {
+			d.logError(msg.ID, msg.BusinessId, err)
+		}
+		d.logError(msg.ID, msg.BusinessId, fmt.Errorf("email moved to DEAD after max attempts: %w", sendErr))
+		return
+	}
+
+	backoff := d.InitialBackoff
+	for i := 1; i < msg.Attempts; i++ {
+		backoff *= 2
+		if backoff > time.Hour {
+			backoff = time.Hour
+			break
+		}
+	}
+	next := time.Now().UTC().Add(backoff)
+	if err := db.Model(&models.EmailMessage{}).
+		Where("id = ?", msg.ID).
+		Updates(map[string]interface{}{
+			"status":          models.EmailMessageStatusFailed,
+			"last_error":      &errMsg,
+			"next_attempt_at": &next,
+			"locked_at":       nil,
+			"locked_by":       nil,
+		}).Error; err != nil {
+		d.logError(msg.ID, msg.BusinessId, err)
+	}
+	d.logError(msg.ID, msg.BusinessId, fmt.Errorf("email send failed, retrying at %s: %w", next.Format(time.RFC3339), sendErr))
+}
+
+func (d *EmailDispatcher) logError(messageId int, businessId string, err error) {
+	if d.Logger == nil {
+		return
+	}
+	d.Logger.WithFields(logrus.Fields{
+		"field":       "EmailDispatcher",
+		"business_id": businessId,
+		"message_id":  messageId,
+	}).Error(err.Error())
+}
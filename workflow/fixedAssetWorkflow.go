@@ -0,0 +1,248 @@
+package workflow
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+func ProcessFixedAssetDepreciationWorkflow(tx *gorm.DB, logger *logrus.Logger, msg config.PubSubMessage) error {
+
+	var accountJournalId int
+	var accountIds []int
+	business, err := models.GetBusinessById2(tx, msg.BusinessId)
+	if err != nil {
+		config.LogError(logger, "FixedAssetWorkflow.go", "ProcessFixedAssetDepreciationWorkflow", "GetBusiness", msg.BusinessId, err)
+		return err
+	}
+	if msg.Action == string(models.PubSubMessageActionCreate) {
+
+		var depreciation models.FixedAssetDepreciation
+		err := json.Unmarshal([]byte(msg.NewObj), &depreciation)
+		if err != nil {
+			config.LogError(logger, "FixedAssetWorkflow.go", "ProcessFixedAssetDepreciationWorkflow > Create", "Unmarshal msg.NewObj", msg.NewObj, err)
+			return err
+		}
+		accountJournalId, accountIds, err = CreateFixedAssetDepreciation(tx, logger, msg.BusinessId, *business, depreciation)
+		if err != nil {
+			config.LogError(logger, "FixedAssetWorkflow.go", "ProcessFixedAssetDepreciationWorkflow > Create", "CreateFixedAssetDepreciation", nil, err)
+			return err
+		}
+		err = UpdateBalances(tx, logger, msg.BusinessId, business.BaseCurrencyId, depreciation.BranchId, accountIds, depreciation.PeriodDate, business.BaseCurrencyId)
+		if err != nil {
+			config.LogError(logger, "FixedAssetWorkflow.go", "ProcessFixedAssetDepreciationWorkflow > Create", "UpdateBalances", depreciation, err)
+			return err
+		}
+	} else if msg.Action == string(models.PubSubMessageActionDelete) {
+
+		var oldDepreciation models.FixedAssetDepreciation
+		err = json.Unmarshal([]byte(msg.OldObj), &oldDepreciation)
+		if err != nil {
+			config.LogError(logger, "FixedAssetWorkflow.go", "ProcessFixedAssetDepreciationWorkflow > Delete", "Unmarshal msg.OldObj", msg.OldObj, err)
+			return err
+		}
+		accountJournalId, accountIds, err = reverseFixedAssetJournal(tx, logger, oldDepreciation.ID, models.AccountReferenceTypeFixedAssetDepreciation, ReversalReasonFixedAssetDepreciationReverse)
+		if err != nil {
+			config.LogError(logger, "FixedAssetWorkflow.go", "ProcessFixedAssetDepreciationWorkflow > Delete", "reverseFixedAssetJournal", nil, err)
+			return err
+		}
+		err = UpdateBalances(tx, logger, msg.BusinessId, business.BaseCurrencyId, oldDepreciation.BranchId, accountIds, oldDepreciation.PeriodDate, business.BaseCurrencyId)
+		if err != nil {
+			config.LogError(logger, "FixedAssetWorkflow.go", "ProcessFixedAssetDepreciationWorkflow > Delete", "UpdateBalances", oldDepreciation, err)
+			return err
+		}
+	}
+	err = tx.Model(&models.PubSubMessageRecord{}).Where("id=?", msg.ID).Updates(map[string]interface{}{"account_journal_id": accountJournalId, "is_processed": true}).Error
+	if err != nil {
+		config.LogError(logger, "FixedAssetWorkflow.go", "ProcessFixedAssetDepreciationWorkflow", "UpdatePubSubMessageRecord", accountJournalId, err)
+		return err
+	}
+	return nil
+}
+
+func ProcessFixedAssetDisposalWorkflow(tx *gorm.DB, logger *logrus.Logger, msg config.PubSubMessage) error {
+
+	var accountJournalId int
+	var accountIds []int
+	business, err := models.GetBusinessById2(tx, msg.BusinessId)
+	if err != nil {
+		config.LogError(logger, "FixedAssetWorkflow.go", "ProcessFixedAssetDisposalWorkflow", "GetBusiness", msg.BusinessId, err)
+		return err
+	}
+	if msg.Action == string(models.PubSubMessageActionCreate) {
+
+		var fixedAsset models.FixedAsset
+		err := json.Unmarshal([]byte(msg.NewObj), &fixedAsset)
+		if err != nil {
+			config.LogError(logger, "FixedAssetWorkflow.go", "ProcessFixedAssetDisposalWorkflow > Create", "Unmarshal msg.NewObj", msg.NewObj, err)
+			return err
+		}
+		accountJournalId, accountIds, err = CreateFixedAssetDisposal(tx, logger, msg.BusinessId, *business, fixedAsset)
+		if err != nil {
+			config.LogError(logger, "FixedAssetWorkflow.go", "ProcessFixedAssetDisposalWorkflow > Create", "CreateFixedAssetDisposal", nil, err)
+			return err
+		}
+		err = UpdateBalances(tx, logger, msg.BusinessId, business.BaseCurrencyId, fixedAsset.BranchId, accountIds, *fixedAsset.DisposalDate, business.BaseCurrencyId)
+		if err != nil {
+			config.LogError(logger, "FixedAssetWorkflow.go", "ProcessFixedAssetDisposalWorkflow > Create", "UpdateBalances", fixedAsset, err)
+			return err
+		}
+		err = UpdateBankBalances(tx, business.BaseCurrencyId, fixedAsset.BranchId, accountIds, *fixedAsset.DisposalDate)
+		if err != nil {
+			config.LogError(logger, "FixedAssetWorkflow.go", "ProcessFixedAssetDisposalWorkflow > Create", "UpdateBankBalances", fixedAsset, err)
+			return err
+		}
+	} else if msg.Action == string(models.PubSubMessageActionDelete) {
+
+		var oldFixedAsset models.FixedAsset
+		err = json.Unmarshal([]byte(msg.OldObj), &oldFixedAsset)
+		if err != nil {
+			config.LogError(logger, "FixedAssetWorkflow.go", "ProcessFixedAssetDisposalWorkflow > Delete", "Unmarshal msg.OldObj", msg.OldObj, err)
+			return err
+		}
+		accountJournalId, accountIds, err = reverseFixedAssetJournal(tx, logger, oldFixedAsset.ID, models.AccountReferenceTypeFixedAssetDisposal, ReversalReasonFixedAssetDisposalCancel)
+		if err != nil {
+			config.LogError(logger, "FixedAssetWorkflow.go", "ProcessFixedAssetDisposalWorkflow > Delete", "reverseFixedAssetJournal", nil, err)
+			return err
+		}
+		err = UpdateBalances(tx, logger, msg.BusinessId, business.BaseCurrencyId, oldFixedAsset.BranchId, accountIds, msg.TransactionDateTime, business.BaseCurrencyId)
+		if err != nil {
+			config.LogError(logger, "FixedAssetWorkflow.go", "ProcessFixedAssetDisposalWorkflow > Delete", "UpdateBalances", oldFixedAsset, err)
+			return err
+		}
+		err = UpdateBankBalances(tx, business.BaseCurrencyId, oldFixedAsset.BranchId, accountIds, msg.TransactionDateTime)
+		if err != nil {
+			config.LogError(logger, "FixedAssetWorkflow.go", "ProcessFixedAssetDisposalWorkflow > Delete", "UpdateBankBalances", oldFixedAsset, err)
+			return err
+		}
+	}
+	err = tx.Model(&models.PubSubMessageRecord{}).Where("id=?", msg.ID).Updates(map[string]interface{}{"account_journal_id": accountJournalId, "is_processed": true}).Error
+	if err != nil {
+		config.LogError(logger, "FixedAssetWorkflow.go", "ProcessFixedAssetDisposalWorkflow", "UpdatePubSubMessageRecord", accountJournalId, err)
+		return err
+	}
+	return nil
+}
+
+// baseCurrencyTransaction builds a base-currency ledger line, for postings that are never in a foreign currency.
+func baseCurrencyTransaction(businessId string, business models.Business, branchId int, accountId int, transactionTime time.Time, description string, debit decimal.Decimal, credit decimal.Decimal) models.AccountTransaction {
+	return models.AccountTransaction{
+		BusinessId:          businessId,
+		AccountId:           accountId,
+		BranchId:            branchId,
+		TransactionDateTime: transactionTime,
+		Description:         description,
+		BaseCurrencyId:      business.BaseCurrencyId,
+		BaseDebit:           debit,
+		BaseCredit:          credit,
+		ForeignCurrencyId:   business.BaseCurrencyId,
+		ForeignDebit:        decimal.NewFromInt(0),
+		ForeignCredit:       decimal.NewFromInt(0),
+		ExchangeRate:        decimal.NewFromInt(0),
+	}
+}
+
+// CreateFixedAssetDepreciation posts Dr depreciation expense, Cr accumulated depreciation.
+func CreateFixedAssetDepreciation(tx *gorm.DB, logger *logrus.Logger, businessId string, business models.Business, depreciation models.FixedAssetDepreciation) (int, []int, error) {
+
+	var fixedAsset models.FixedAsset
+	err := tx.Select("id", "asset_number").First(&fixedAsset, depreciation.FixedAssetId).Error
+	if err != nil {
+		config.LogError(logger, "FixedAssetWorkflow.go", "CreateFixedAssetDepreciation", "GetFixedAsset", depreciation.FixedAssetId, err)
+		return 0, nil, err
+	}
+
+	zero := decimal.NewFromInt(0)
+	accTransactions := []models.AccountTransaction{
+		baseCurrencyTransaction(businessId, business, depreciation.BranchId, depreciation.DepreciationExpenseAccountId, depreciation.PeriodDate, depreciation.Description, depreciation.Amount, zero),
+		baseCurrencyTransaction(businessId, business, depreciation.BranchId, depreciation.AccumulatedDepreciationAccountId, depreciation.PeriodDate, depreciation.Description, zero, depreciation.Amount),
+	}
+	accountIds := []int{depreciation.DepreciationExpenseAccountId, depreciation.AccumulatedDepreciationAccountId}
+
+	accJournal := models.AccountJournal{
+		BusinessId:          businessId,
+		BranchId:            depreciation.BranchId,
+		TransactionDateTime: depreciation.PeriodDate,
+		TransactionNumber:   fixedAsset.AssetNumber,
+		TransactionDetails:  depreciation.Description,
+		ReferenceNumber:     fixedAsset.AssetNumber,
+		ReferenceId:         depreciation.ID,
+		ReferenceType:       models.AccountReferenceTypeFixedAssetDepreciation,
+		AccountTransactions: accTransactions,
+	}
+	err = tx.Create(&accJournal).Error
+	if err != nil {
+		config.LogError(logger, "FixedAssetWorkflow.go", "CreateFixedAssetDepreciation", "CreateAccountJournal", accJournal, err)
+		return 0, nil, err
+	}
+	return accJournal.ID, accountIds, nil
+}
+
+// CreateFixedAssetDisposal removes the asset from the books:
+// Dr accumulated depreciation and proceeds, Cr asset cost, with the difference to gain/loss.
+func CreateFixedAssetDisposal(tx *gorm.DB, logger *logrus.Logger, businessId string, business models.Business, fixedAsset models.FixedAsset) (int, []int, error) {
+
+	transactionTime := *fixedAsset.DisposalDate
+	description := "Disposal of " + fixedAsset.AssetNumber + " " + fixedAsset.Name
+	zero := decimal.NewFromInt(0)
+	accountIds := make([]int, 0)
+	accTransactions := make([]models.AccountTransaction, 0)
+	add := func(accountId int, debit decimal.Decimal, credit decimal.Decimal) {
+		accTransactions = append(accTransactions, baseCurrencyTransaction(businessId, business, fixedAsset.BranchId, accountId, transactionTime, description, debit, credit))
+		if !slices.Contains(accountIds, accountId) {
+			accountIds = append(accountIds, accountId)
+		}
+	}
+
+	if fixedAsset.AccumulatedDepreciation.IsPositive() {
+		add(fixedAsset.AccumulatedDepreciationAccountId, fixedAsset.AccumulatedDepreciation, zero)
+	}
+	if fixedAsset.DisposalProceeds.IsPositive() {
+		add(fixedAsset.ProceedsAccountId, fixedAsset.DisposalProceeds, zero)
+	}
+	add(fixedAsset.AssetAccountId, zero, fixedAsset.AcquisitionCost)
+	if fixedAsset.DisposalGainLoss.IsPositive() {
+		add(fixedAsset.DisposalGainLossAccountId, zero, fixedAsset.DisposalGainLoss)
+	} else if fixedAsset.DisposalGainLoss.IsNegative() {
+		add(fixedAsset.DisposalGainLossAccountId, fixedAsset.DisposalGainLoss.Neg(), zero)
+	}
+
+	accJournal := models.AccountJournal{
+		BusinessId:          businessId,
+		BranchId:            fixedAsset.BranchId,
+		TransactionDateTime: transactionTime,
+		TransactionNumber:   fixedAsset.AssetNumber,
+		TransactionDetails:  description,
+		ReferenceNumber:     fixedAsset.AssetNumber,
+		ReferenceId:         fixedAsset.ID,
+		ReferenceType:       models.AccountReferenceTypeFixedAssetDisposal,
+		AccountTransactions: accTransactions,
+	}
+	err := tx.Create(&accJournal).Error
+	if err != nil {
+		config.LogError(logger, "FixedAssetWorkflow.go", "CreateFixedAssetDisposal", "CreateAccountJournal", accJournal, err)
+		return 0, nil, err
+	}
+	return accJournal.ID, accountIds, nil
+}
+
+func reverseFixedAssetJournal(tx *gorm.DB, logger *logrus.Logger, referenceId int, referenceType models.AccountReferenceType, reason string) (int, []int, error) {
+
+	accountJournal, _, accountIds, err := GetExistingAccountJournal(tx, referenceId, referenceType)
+	if err != nil {
+		config.LogError(logger, "FixedAssetWorkflow.go", "reverseFixedAssetJournal", "GetExistingAccountJournal", referenceId, err)
+		return 0, nil, err
+	}
+	reversalID, err := ReverseAccountJournal(tx, accountJournal, reason)
+	if err != nil {
+		config.LogError(logger, "FixedAssetWorkflow.go", "reverseFixedAssetJournal", "ReverseAccountJournal", accountJournal, err)
+		return 0, nil, err
+	}
+	return reversalID, accountIds, nil
+}
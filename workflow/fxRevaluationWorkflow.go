@@ -0,0 +1,162 @@
+package workflow
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+func ProcessFxRevaluationWorkflow(tx *gorm.DB, logger *logrus.Logger, msg config.PubSubMessage) error {
+
+	var accountJournalId int
+	var accountIds []int
+	business, err := models.GetBusinessById2(tx, msg.BusinessId)
+	if err != nil {
+		config.LogError(logger, "FxRevaluationWorkflow.go", "ProcessFxRevaluationWorkflow", "GetBusiness", msg.BusinessId, err)
+		return err
+	}
+	if msg.Action == string(models.PubSubMessageActionCreate) {
+
+		var revaluation models.FxRevaluation
+		err := json.Unmarshal([]byte(msg.NewObj), &revaluation)
+		if err != nil {
+			config.LogError(logger, "FxRevaluationWorkflow.go", "ProcessFxRevaluationWorkflow > Create", "Unmarshal msg.NewObj", msg.NewObj, err)
+			return err
+		}
+		accountJournalId, accountIds, err = CreateFxRevaluationJournals(tx, logger, msg.BusinessId, *business, revaluation)
+		if err != nil {
+			config.LogError(logger, "FxRevaluationWorkflow.go", "ProcessFxRevaluationWorkflow > Create", "CreateFxRevaluationJournals", nil, err)
+			return err
+		}
+		err = UpdateBalances(tx, logger, msg.BusinessId, business.BaseCurrencyId, revaluation.BranchId, accountIds, revaluation.RevaluationDate, business.BaseCurrencyId)
+		if err != nil {
+			config.LogError(logger, "FxRevaluationWorkflow.go", "ProcessFxRevaluationWorkflow > Create", "UpdateBalances", revaluation, err)
+			return err
+		}
+		err = UpdateBankBalances(tx, business.BaseCurrencyId, revaluation.BranchId, accountIds, revaluation.RevaluationDate)
+		if err != nil {
+			config.LogError(logger, "FxRevaluationWorkflow.go", "ProcessFxRevaluationWorkflow > Create", "UpdateBankBalances", revaluation, err)
+			return err
+		}
+	} else if msg.Action == string(models.PubSubMessageActionDelete) {
+
+		var oldRevaluation models.FxRevaluation
+		err = json.Unmarshal([]byte(msg.OldObj), &oldRevaluation)
+		if err != nil {
+			config.LogError(logger, "FxRevaluationWorkflow.go", "ProcessFxRevaluationWorkflow > Delete", "Unmarshal msg.OldObj", msg.OldObj, err)
+			return err
+		}
+		// the revaluation and its next-day reversal are both active journals of the reference
+		for {
+			accountJournal, _, ids, err := GetExistingAccountJournal(tx, oldRevaluation.ID, models.AccountReferenceTypeFxRevaluation)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				break
+			}
+			if err != nil {
+				config.LogError(logger, "FxRevaluationWorkflow.go", "ProcessFxRevaluationWorkflow > Delete", "GetExistingAccountJournal", oldRevaluation.ID, err)
+				return err
+			}
+			accountIds = mergeAccountIds(accountIds, ids)
+			accountJournalId, err = ReverseAccountJournal(tx, accountJournal, ReversalReasonFxRevaluationDelete)
+			if err != nil {
+				config.LogError(logger, "FxRevaluationWorkflow.go", "ProcessFxRevaluationWorkflow > Delete", "ReverseAccountJournal", accountJournal, err)
+				return err
+			}
+		}
+		err = UpdateBalances(tx, logger, msg.BusinessId, business.BaseCurrencyId, oldRevaluation.BranchId, accountIds, oldRevaluation.RevaluationDate, business.BaseCurrencyId)
+		if err != nil {
+			config.LogError(logger, "FxRevaluationWorkflow.go", "ProcessFxRevaluationWorkflow > Delete", "UpdateBalances", oldRevaluation, err)
+			return err
+		}
+		err = UpdateBankBalances(tx, business.BaseCurrencyId, oldRevaluation.BranchId, accountIds, oldRevaluation.RevaluationDate)
+		if err != nil {
+			config.LogError(logger, "FxRevaluationWorkflow.go", "ProcessFxRevaluationWorkflow > Delete", "UpdateBankBalances", oldRevaluation, err)
+			return err
+		}
+	}
+	err = tx.Model(&models.PubSubMessageRecord{}).Where("id=?", msg.ID).Updates(map[string]interface{}{"account_journal_id": accountJournalId, "is_processed": true}).Error
+	if err != nil {
+		config.LogError(logger, "FxRevaluationWorkflow.go", "ProcessFxRevaluationWorkflow", "UpdatePubSubMessageRecord", accountJournalId, err)
+		return err
+	}
+	return nil
+}
+
+// CreateFxRevaluationJournals posts the unrealised gain or loss on the revaluation date and
+// its reversal on the next day. A gain debits the revalued account and credits exchange gain
+// or loss; a loss the other way round. Lines are in base currency only, so the foreign
+// balances keep their document rates.
+func CreateFxRevaluationJournals(tx *gorm.DB, logger *logrus.Logger, businessId string, business models.Business, revaluation models.FxRevaluation) (int, []int, error) {
+
+	zero := decimal.NewFromInt(0)
+	accountIds := []int{revaluation.GainLossAccountId}
+	post := func(description string, reverse bool) (int, error) {
+		var accTransactions []models.AccountTransaction
+		transactionTime := revaluation.RevaluationDate
+		if reverse {
+			transactionTime = revaluation.ReversalDate
+		}
+		for _, line := range revaluation.Lines {
+			debit, credit := line.GainLoss, zero
+			if line.GainLoss.IsNegative() {
+				debit, credit = zero, line.GainLoss.Abs()
+			}
+			if reverse {
+				debit, credit = credit, debit
+			}
+			accTransactions = append(accTransactions, baseCurrencyTransaction(businessId, business, revaluation.BranchId, line.AccountId, transactionTime, description, debit, credit))
+		}
+		debit, credit := zero, revaluation.TotalGainLoss
+		if revaluation.TotalGainLoss.IsNegative() {
+			debit, credit = revaluation.TotalGainLoss.Abs(), zero
+		}
+		if reverse {
+			debit, credit = credit, debit
+		}
+		if !revaluation.TotalGainLoss.IsZero() {
+			accTransactions = append(accTransactions, baseCurrencyTransaction(businessId, business, revaluation.BranchId, revaluation.GainLossAccountId, transactionTime, description, debit, credit))
+		}
+
+		accJournal := models.AccountJournal{
+			BusinessId:          businessId,
+			BranchId:            revaluation.BranchId,
+			TransactionDateTime: transactionTime,
+			TransactionDetails:  description,
+			ReferenceId:         revaluation.ID,
+			ReferenceType:       models.AccountReferenceTypeFxRevaluation,
+			AccountTransactions: accTransactions,
+		}
+		if err := tx.Create(&accJournal).Error; err != nil {
+			config.LogError(logger, "FxRevaluationWorkflow.go", "CreateFxRevaluationJournals", "CreateAccountJournal", accJournal, err)
+			return 0, err
+		}
+		return accJournal.ID, nil
+	}
+
+	for _, line := range revaluation.Lines {
+		accountIds = mergeAccountIds(accountIds, []int{line.AccountId})
+	}
+	accountJournalId, err := post("Unrealised exchange gain/loss", false)
+	if err != nil {
+		return 0, nil, err
+	}
+	if _, err := post("Unrealised exchange gain/loss reversal", true); err != nil {
+		return 0, nil, err
+	}
+	return accountJournalId, accountIds, nil
+}
+
+func mergeAccountIds(accountIds []int, ids []int) []int {
+	for _, id := range ids {
+		if !slices.Contains(accountIds, id) {
+			accountIds = append(accountIds, id)
+		}
+	}
+	return accountIds
+}
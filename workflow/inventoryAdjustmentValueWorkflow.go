@@ -156,9 +156,11 @@ func CreateInventoryAdjustmentValue(tx *gorm.DB, logger *logrus.Logger, recordId
 			// Otherwise, we can:
 			// - pick reversal/inactive rows (closing balances may be 0)
 			// - pick rows from another tenant in rare cases (warehouse IDs are not globally unique assumptions)
+			// Cumulative quantities run per item across batches (no-batch mode), so the last row
+			// is the item's, whichever batch it is in.
 			err = tx.
-				Where("business_id = ? AND warehouse_id = ? AND product_id = ? AND product_type = ? AND COALESCE(batch_number,'') = ? AND stock_date < ? AND is_reversal = 0 AND reversed_by_stock_history_id IS NULL",
-					businessId, inventoryAdjustment.WarehouseId, inventoryAdjustmentDetail.ProductId, inventoryAdjustmentDetail.ProductType, inventoryAdjustmentDetail.BatchNumber, stockDateExclusiveEnd).
+				Where("business_id = ? AND warehouse_id = ? AND product_id = ? AND product_type = ? AND stock_date < ? AND is_reversal = 0 AND reversed_by_stock_history_id IS NULL",
+					businessId, inventoryAdjustment.WarehouseId, inventoryAdjustmentDetail.ProductId, inventoryAdjustmentDetail.ProductType, stockDateExclusiveEnd).
 				Order("stock_date DESC, cumulative_sequence DESC, id DESC").
 				Limit(1).
 				Find(&lastStockHistory).Error
@@ -170,7 +172,18 @@ func CreateInventoryAdjustmentValue(tx *gorm.DB, logger *logrus.Logger, recordId
 				config.LogError(logger, "InventoryAdjustmentValueWorkflow.go", "CreateInventoryAdjustmentValue", "LastStockHistory not found", inventoryAdjustmentDetail, err)
 				return 0, nil, 0, nil, err
 			}
-			remainingIncomingStockHistories, err := GetRemainingStockHistoriesByCumulativeQtyUntilDate(tx, inventoryAdjustment.WarehouseId, inventoryAdjustmentDetail.ProductId, string(inventoryAdjustmentDetail.ProductType), inventoryAdjustmentDetail.BatchNumber, utils.NewFalse(), lastStockHistory.CumulativeOutgoingQty, stockDateInclusiveEnd)
+			// Stock restated by a valuation method switch is removed with the method it was valued with.
+			valuationMethod, err := models.InventoryValuationMethodAt(tx, &business, inventoryAdjustmentDetail.ProductId, inventoryAdjustmentDetail.ProductType, stockDate)
+			if err != nil {
+				config.LogError(logger, "InventoryAdjustmentValueWorkflow.go", "CreateInventoryAdjustmentValue", "GetValuationMethod", inventoryAdjustmentDetail, err)
+				return 0, nil, 0, nil, err
+			}
+			var remainingIncomingStockHistories []*models.StockHistory
+			if valuationMethod == models.InventoryValuationMethodWeightedAverage {
+				remainingIncomingStockHistories, err = weightedAverageOnHandLayer(tx, &lastStockHistory, stockDateExclusiveEnd)
+			} else {
+				remainingIncomingStockHistories, err = GetRemainingStockHistoriesByCumulativeQtyUntilDate(tx, inventoryAdjustment.WarehouseId, inventoryAdjustmentDetail.ProductId, string(inventoryAdjustmentDetail.ProductType), inventoryAdjustmentDetail.BatchNumber, utils.NewFalse(), lastStockHistory.CumulativeOutgoingQty, stockDateInclusiveEnd)
+			}
 			if err != nil {
 				config.LogError(logger, "InventoryAdjustmentValueWorkflow.go", "CreateInventoryAdjustmentValue", "GetRemainingStockHistoriesByCumulativeQty", inventoryAdjustmentDetail, err)
 				return 0, nil, 0, nil, err
@@ -291,6 +291,12 @@ func processOutboxMessage(tx *gorm.DB, logger *logrus.Logger, msg config.PubSubM
 		return ProcessInventoryAdjustmentValueWorkflow(tx, logger, msg)
 	case models.AccountReferenceTypeTransferOrder:
 		return ProcessTransferOrderWorkflow(tx, logger, msg)
+	case models.AccountReferenceTypeAssemblyOrder:
+		return ProcessAssemblyOrderWorkflow(tx, logger, msg)
+	case models.AccountReferenceTypeTransferOrderReceipt:
+		return ProcessTransferOrderReceiptWorkflow(tx, logger, msg)
+	case models.AccountReferenceTypeLandedCost:
+		return ProcessLandedCostWorkflow(tx, logger, msg)
 	case models.AccountReferenceTypeBill:
 		return ProcessBillWorkflow(tx, logger, msg)
 	case models.AccountReferenceTypeInvoice:
@@ -0,0 +1,237 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+func ProcessLandedCostWorkflow(tx *gorm.DB, logger *logrus.Logger, msg config.PubSubMessage) error {
+
+	var accountJournalId int
+	var accountIds []int
+	var landedCost models.LandedCost
+	var transactionTime time.Time
+	business, err := models.GetBusinessById2(tx, msg.BusinessId)
+	if err != nil {
+		config.LogError(logger, "LandedCostWorkflow.go", "ProcessLandedCostWorkflow", "GetBusiness", msg.BusinessId, err)
+		return err
+	}
+	if msg.Action == string(models.PubSubMessageActionCreate) {
+		err := json.Unmarshal([]byte(msg.NewObj), &landedCost)
+		if err != nil {
+			config.LogError(logger, "LandedCostWorkflow.go", "ProcessLandedCostWorkflow > Create", "Unmarshal msg.NewObj", msg.NewObj, err)
+			return err
+		}
+		accountJournalId, accountIds, transactionTime, err = CreateLandedCost(tx, logger, msg.BusinessId, *business, landedCost)
+		if err != nil {
+			config.LogError(logger, "LandedCostWorkflow.go", "ProcessLandedCostWorkflow > Create", "CreateLandedCost", nil, err)
+			return err
+		}
+	} else if msg.Action == string(models.PubSubMessageActionDelete) {
+		err := json.Unmarshal([]byte(msg.OldObj), &landedCost)
+		if err != nil {
+			config.LogError(logger, "LandedCostWorkflow.go", "ProcessLandedCostWorkflow > Delete", "Unmarshal msg.OldObj", msg.OldObj, err)
+			return err
+		}
+		accountJournalId, accountIds, transactionTime, err = DeleteLandedCost(tx, logger, msg.BusinessId, landedCost)
+		if err != nil {
+			config.LogError(logger, "LandedCostWorkflow.go", "ProcessLandedCostWorkflow > Delete", "DeleteLandedCost", nil, err)
+			return err
+		}
+	}
+	err = UpdateBalances(tx, logger, msg.BusinessId, business.BaseCurrencyId, landedCost.BranchId, accountIds, transactionTime, business.BaseCurrencyId)
+	if err != nil {
+		config.LogError(logger, "LandedCostWorkflow.go", "ProcessLandedCostWorkflow", "UpdateBalances", nil, err)
+		return err
+	}
+	err = tx.Model(&models.PubSubMessageRecord{}).Where("id=?", msg.ID).Updates(map[string]interface{}{"account_journal_id": accountJournalId, "is_processed": true}).Error
+	if err != nil {
+		config.LogError(logger, "LandedCostWorkflow.go", "ProcessLandedCostWorkflow", "UpdatePubSubMessageRecord", accountJournalId, err)
+		return err
+	}
+	return nil
+}
+
+// CreateLandedCost raises the receiving bills' stock rows by the allocated unit cost and
+// moves the charges from the cost bills' accounts into inventory. It returns the date
+// balances have to be updated from: the earliest repriced stock row, whose later sales
+// were recosted, or the landed cost's own date.
+func CreateLandedCost(tx *gorm.DB, logger *logrus.Logger, businessId string, business models.Business, landedCost models.LandedCost) (int, []int, time.Time, error) {
+	systemAccounts, err := models.GetSystemAccounts(businessId)
+	if err != nil {
+		config.LogError(logger, "LandedCostWorkflow.go", "CreateLandedCost", "GetSystemAccounts", businessId, err)
+		return 0, nil, time.Time{}, err
+	}
+
+	deltas := make(map[int]valuationDelta)
+	for _, line := range landedCost.Lines {
+		productDetail, err := GetProductDetail(tx, line.ProductId, line.ProductType)
+		if err != nil {
+			config.LogError(logger, "LandedCostWorkflow.go", "CreateLandedCost", "GetProductDetail", line, err)
+			return 0, nil, time.Time{}, err
+		}
+		d := deltas[productDetail.InventoryAccountId]
+		d.BaseDebit = d.BaseDebit.Add(line.AllocatedAmount)
+		deltas[productDetail.InventoryAccountId] = d
+	}
+	for _, charge := range landedCost.Charges {
+		var billDetail models.BillDetail
+		if err := tx.Where("bill_id = ? AND id = ?", charge.BillId, charge.BillDetailId).First(&billDetail).Error; err != nil {
+			config.LogError(logger, "LandedCostWorkflow.go", "CreateLandedCost", "GetBillDetail", charge, err)
+			return 0, nil, time.Time{}, err
+		}
+		accountId, err := billDetailAccountId(tx, systemAccounts, billDetail)
+		if err != nil {
+			config.LogError(logger, "LandedCostWorkflow.go", "CreateLandedCost", "GetBillDetailAccount", billDetail, err)
+			return 0, nil, time.Time{}, err
+		}
+		d := deltas[accountId]
+		d.BaseCredit = d.BaseCredit.Add(charge.Amount)
+		deltas[accountId] = d
+	}
+
+	accountIds := make([]int, 0)
+	transactions := make([]models.AccountTransaction, 0, len(deltas))
+	for _, accId := range sortedDeltaAccountIds(deltas) {
+		accountIds = append(accountIds, accId)
+		transactions = append(transactions, models.AccountTransaction{
+			BusinessId:          businessId,
+			AccountId:           accId,
+			BranchId:            landedCost.BranchId,
+			TransactionDateTime: landedCost.LandedCostDate,
+			BaseCurrencyId:      business.BaseCurrencyId,
+			BaseDebit:           deltas[accId].BaseDebit,
+			BaseCredit:          deltas[accId].BaseCredit,
+		})
+	}
+	journal := models.AccountJournal{
+		BusinessId:          businessId,
+		BranchId:            landedCost.BranchId,
+		TransactionDateTime: landedCost.LandedCostDate,
+		TransactionNumber:   landedCost.LandedCostNumber,
+		ReferenceId:         landedCost.ID,
+		ReferenceType:       models.AccountReferenceTypeLandedCost,
+		AccountTransactions: transactions,
+	}
+	if err := tx.Create(&journal).Error; err != nil {
+		config.LogError(logger, "LandedCostWorkflow.go", "CreateLandedCost", "CreateAccountJournal", journal, err)
+		return 0, nil, time.Time{}, err
+	}
+
+	valuationAccountIds, fromDate, err := repriceLandedCostStock(tx, logger, businessId, landedCost, false)
+	if err != nil {
+		config.LogError(logger, "LandedCostWorkflow.go", "CreateLandedCost", "RepriceLandedCostStock", landedCost.ID, err)
+		return 0, nil, time.Time{}, err
+	}
+	for _, accId := range valuationAccountIds {
+		if !slices.Contains(accountIds, accId) {
+			accountIds = append(accountIds, accId)
+		}
+	}
+	return journal.ID, accountIds, fromDate, nil
+}
+
+// DeleteLandedCost reverses a landed cost's journal and takes the allocated unit cost
+// back off the receiving bills' stock rows that are still active.
+func DeleteLandedCost(tx *gorm.DB, logger *logrus.Logger, businessId string, oldLandedCost models.LandedCost) (int, []int, time.Time, error) {
+	accountJournal, _, accountIds, err := GetExistingAccountJournal(tx, oldLandedCost.ID, models.AccountReferenceTypeLandedCost)
+	if err != nil {
+		config.LogError(logger, "LandedCostWorkflow.go", "DeleteLandedCost", "GetExistingAccountJournal", oldLandedCost, err)
+		return 0, nil, time.Time{}, err
+	}
+	reversalID, err := ReverseAccountJournal(tx, accountJournal, ReversalReasonLandedCostVoid)
+	if err != nil {
+		config.LogError(logger, "LandedCostWorkflow.go", "DeleteLandedCost", "ReverseAccountJournal", accountJournal, err)
+		return 0, nil, time.Time{}, err
+	}
+
+	valuationAccountIds, fromDate, err := repriceLandedCostStock(tx, logger, businessId, oldLandedCost, true)
+	if err != nil {
+		config.LogError(logger, "LandedCostWorkflow.go", "DeleteLandedCost", "RepriceLandedCostStock", oldLandedCost.ID, err)
+		return 0, nil, time.Time{}, err
+	}
+	for _, accId := range valuationAccountIds {
+		if !slices.Contains(accountIds, accId) {
+			accountIds = append(accountIds, accId)
+		}
+	}
+	return reversalID, accountIds, fromDate, nil
+}
+
+// repriceLandedCostStock replaces the active incoming bill rows of each landed cost line
+// with rows at the unit cost plus (or, to reverse, minus) the line's UnitCostDelta, through
+// the same ReplaceStockHistoryByID step valuation reposts use, and recosts everything sold
+// from them; the affected sales journals are reposted by calculateCogs. Bill edits are
+// blocked while a landed cost is confirmed, so the rows carry exactly the deltas of the
+// confirmed landed costs and a void takes off exactly what the landed cost added. The
+// replacements keep their bill reference, so a later void of the bill reverses them like
+// any other bill row.
+func repriceLandedCostStock(tx *gorm.DB, logger *logrus.Logger, businessId string, landedCost models.LandedCost, reverse bool) ([]int, time.Time, error) {
+	reason := ReversalReasonInventoryValuationReprice
+	if reverse {
+		reason = ReversalReasonLandedCostVoid
+	}
+	fromDate := landedCost.LandedCostDate
+	replacements := make([]*models.StockHistory, 0)
+	for _, line := range landedCost.Lines {
+		if line.UnitCostDelta.IsZero() {
+			continue
+		}
+		var rows []*models.StockHistory
+		if err := tx.
+			Where("business_id = ? AND reference_type = ? AND reference_id = ? AND reference_detail_id = ? AND is_outgoing = 0 AND is_reversal = 0 AND reversed_by_stock_history_id IS NULL",
+				businessId, models.StockReferenceTypeBill, line.BillId, line.BillDetailId).
+			Order("id").
+			Find(&rows).Error; err != nil {
+			return nil, fromDate, err
+		}
+		for _, r := range rows {
+			unitValue, err := landedCostUnitValue(r.BaseUnitValue, line.UnitCostDelta, reverse)
+			if err != nil {
+				return nil, fromDate, fmt.Errorf("landed cost %s, bill detail %d: %w", landedCost.LandedCostNumber, line.BillDetailId, err)
+			}
+			replacement, err := ReplaceStockHistoryByID(tx, r.ID, reason, func(newRow *models.StockHistory) {
+				newRow.BaseUnitValue = unitValue
+			})
+			if err != nil {
+				return nil, fromDate, err
+			}
+			replacements = append(replacements, replacement)
+			if r.StockDate.Before(fromDate) {
+				fromDate = r.StockDate
+			}
+		}
+	}
+
+	valuationAccountIds, err := ProcessIncomingStocks(tx, logger, replacements)
+	if err != nil {
+		if scope, ok := parseFifoInsufficientScope(err); ok {
+			if rerr := rebuildInventoryForScope(tx, logger, businessId, scope, fromDate); rerr == nil {
+				valuationAccountIds, err = ProcessIncomingStocks(tx, logger, replacements)
+			}
+		}
+	}
+	return valuationAccountIds, fromDate, err
+}
+
+// landedCostUnitValue is a bill row's unit value with a landed cost's unit delta added or,
+// to reverse, taken off. A row that no longer carries the delta is an error rather than
+// being clamped, so a void never leaves a different value from the one it started with.
+func landedCostUnitValue(unitValue decimal.Decimal, delta decimal.Decimal, reverse bool) (decimal.Decimal, error) {
+	if reverse {
+		delta = delta.Neg()
+	}
+	result := unitValue.Add(delta)
+	if result.IsNegative() {
+		return unitValue, fmt.Errorf("unit value %s does not carry the landed cost of %s", unitValue, delta.Neg())
+	}
+	return result, nil
+}
@@ -0,0 +1,28 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// A landed cost void must take off exactly what confirming it added, and refuse rows that
+// no longer carry it instead of clamping them to zero.
+func TestLandedCostUnitValue_VoidIsExactInverse(t *testing.T) {
+	d := decimal.RequireFromString
+	original := d("12.3456")
+	delta := d("0.3333")
+
+	raised, err := landedCostUnitValue(original, delta, false)
+	if err != nil || !raised.Equal(d("12.6789")) {
+		t.Fatalf("confirm = %s, %v; want 12.6789", raised, err)
+	}
+	restored, err := landedCostUnitValue(raised, delta, true)
+	if err != nil || !restored.Equal(original) {
+		t.Fatalf("void = %s, %v; want %s", restored, err, original)
+	}
+
+	if _, err := landedCostUnitValue(d("0.2"), delta, true); err == nil {
+		t.Fatal("a row without the landed cost was voided")
+	}
+}
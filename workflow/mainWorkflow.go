@@ -778,12 +778,43 @@ func calculateCogs(tx *gorm.DB, logger *logrus.Logger, productDetail ProductDeta
 		processQty = startProcessQty
 	}
 
-	for outIndex, outStock := range outgoingStockHistories {
+	// Rows on either side of a valuation method switch are costed with their own method.
+	// Weighted-average parts are costed here; the FIFO loop below still walks their qty so
+	// later FIFO rows consume the layers left after them, but records only the FIFO rows.
+	segments, err := splitByValuationMethod(tx, outgoingStockHistories)
+	if err != nil {
+		config.LogError(logger, "MainWorkflow.go", "CalculateCogs", "GetValuationMethod", outgoingStockHistories[0], err)
+		return accountIds, err
+	}
+	weightedAverageRows := make(map[int]bool)
+	for _, segment := range segments {
+		if !segment.weightedAverage {
+			continue
+		}
+		if err = weightedAverageCogs(tx, logger, segment.rows, uniqueStocks, uniqueStockDetails); err != nil {
+			return accountIds, err
+		}
+		for _, outStock := range segment.rows {
+			weightedAverageRows[outStock.ID] = true
+		}
+	}
+	fifoOutgoingStockHistories := outgoingStockHistories
+	if len(weightedAverageRows) == len(outgoingStockHistories) {
+		fifoOutgoingStockHistories = nil
+	}
+	walkedStocks := make(map[string]StockHistoryFragment)
+	walkedStockDetails := make(map[string]StockHistoryDetailFragment)
+
+	for outIndex, outStock := range fifoOutgoingStockHistories {
 		// Process ALL outgoingStockHistories in the FIFO loop below for correct inventory
 		// consumption tracking. The existingStocks map was already populated from DB query
 		// above, so we don't need to build it from outgoingStockHistories here.
+		stocks, stockDetails := uniqueStocks, uniqueStockDetails
+		if weightedAverageRows[outStock.ID] {
+			stocks, stockDetails = walkedStocks, walkedStockDetails
+		}
 		if outIndex == 0 && !processQty.Equal(outgoingStockHistories[0].Qty.Abs()) {
-			uniqueStocks[fmt.Sprintf("%d-%d-%s-%s-%d-%s-%d", outStock.WarehouseId, outStock.ProductId, outStock.ProductType, outStock.BatchNumber, outStock.ReferenceID, outStock.ReferenceType, outStock.ReferenceDetailID)] = StockHistoryFragment{
+			stocks[fmt.Sprintf("%d-%d-%s-%s-%d-%s-%d", outStock.WarehouseId, outStock.ProductId, outStock.ProductType, outStock.BatchNumber, outStock.ReferenceID, outStock.ReferenceType, outStock.ReferenceDetailID)] = StockHistoryFragment{
 				BusinessId:        outStock.BusinessId,
 				WarehouseId:       outStock.WarehouseId,
 				ProductId:         outStock.ProductId,
@@ -795,7 +826,7 @@ func calculateCogs(tx *gorm.DB, logger *logrus.Logger, productDetail ProductDeta
 				TotalQty:          outgoingStockHistories[0].Qty.Abs().Sub(processQty),
 				TotalValue:        outgoingStockHistories[0].Qty.Abs().Sub(processQty).Mul(currentStock.BaseUnitValue),
 			}
-			uniqueStockDetails[fmt.Sprintf("%d-%d-%s-%s-%d-%s-%d-%s", outStock.WarehouseId, outStock.ProductId, outStock.ProductType, outStock.BatchNumber, outStock.ReferenceID, outStock.ReferenceType, outStock.ReferenceDetailID, outStock.BaseUnitValue)] = StockHistoryDetailFragment{
+			stockDetails[fmt.Sprintf("%d-%d-%s-%s-%d-%s-%d-%s", outStock.WarehouseId, outStock.ProductId, outStock.ProductType, outStock.BatchNumber, outStock.ReferenceID, outStock.ReferenceType, outStock.ReferenceDetailID, outStock.BaseUnitValue)] = StockHistoryDetailFragment{
 				BusinessId:        outStock.BusinessId,
 				WarehouseId:       outStock.WarehouseId,
 				ProductId:         outStock.ProductId,
@@ -819,12 +850,12 @@ func calculateCogs(tx *gorm.DB, logger *logrus.Logger, productDetail ProductDeta
 			for processQty.GreaterThan(decimal.Zero) && len(incomingStockHistories) > 0 {
 				if currentStock.Qty.GreaterThanOrEqual(processQty) {
 					// if the current stock can fulfil the process qty
-					if existing, found := uniqueStocks[fmt.Sprintf("%d-%d-%s-%s-%d-%s-%d", outStock.WarehouseId, outStock.ProductId, outStock.ProductType, outStock.BatchNumber, outStock.ReferenceID, outStock.ReferenceType, outStock.ReferenceDetailID)]; found {
+					if existing, found := stocks[fmt.Sprintf("%d-%d-%s-%s-%d-%s-%d", outStock.WarehouseId, outStock.ProductId, outStock.ProductType, outStock.BatchNumber, outStock.ReferenceID, outStock.ReferenceType, outStock.ReferenceDetailID)]; found {
 						existing.TotalQty = existing.TotalQty.Add(processQty)
 						existing.TotalValue = existing.TotalValue.Add(processQty.Mul(currentStock.BaseUnitValue))
-						uniqueStocks[fmt.Sprintf("%d-%d-%s-%s-%d-%s-%d", outStock.WarehouseId, outStock.ProductId, outStock.ProductType, outStock.BatchNumber, outStock.ReferenceID, outStock.ReferenceType, outStock.ReferenceDetailID)] = existing
+						stocks[fmt.Sprintf("%d-%d-%s-%s-%d-%s-%d", outStock.WarehouseId, outStock.ProductId, outStock.ProductType, outStock.BatchNumber, outStock.ReferenceID, outStock.ReferenceType, outStock.ReferenceDetailID)] = existing
 					} else {
-						uniqueStocks[fmt.Sprintf("%d-%d-%s-%s-%d-%s-%d", outStock.WarehouseId, outStock.ProductId, outStock.ProductType, outStock.BatchNumber, outStock.ReferenceID, outStock.ReferenceType, outStock.ReferenceDetailID)] = StockHistoryFragment{
+						stocks[fmt.Sprintf("%d-%d-%s-%s-%d-%s-%d", outStock.WarehouseId, outStock.ProductId, outStock.ProductType, outStock.BatchNumber, outStock.ReferenceID, outStock.ReferenceType, outStock.ReferenceDetailID)] = StockHistoryFragment{
 							BusinessId:        outStock.BusinessId,
 							WarehouseId:       outStock.WarehouseId,
 							ProductId:         outStock.ProductId,
@@ -837,11 +868,11 @@ func calculateCogs(tx *gorm.DB, logger *logrus.Logger, productDetail ProductDeta
 							TotalValue:        processQty.Mul(currentStock.BaseUnitValue),
 						}
 					}
-					if existing, found := uniqueStockDetails[fmt.Sprintf("%d-%d-%s-%s-%d-%s-%d-%s", outStock.WarehouseId, outStock.ProductId, outStock.ProductType, outStock.BatchNumber, outStock.ReferenceID, outStock.ReferenceType, outStock.ReferenceDetailID, currentStock.BaseUnitValue)]; found {
+					if existing, found := stockDetails[fmt.Sprintf("%d-%d-%s-%s-%d-%s-%d-%s", outStock.WarehouseId, outStock.ProductId, outStock.ProductType, outStock.BatchNumber, outStock.ReferenceID, outStock.ReferenceType, outStock.ReferenceDetailID, currentStock.BaseUnitValue)]; found {
 						existing.Qty = existing.Qty.Add(processQty)
-						uniqueStockDetails[fmt.Sprintf("%d-%d-%s-%s-%d-%s-%d-%s", outStock.WarehouseId, outStock.ProductId, outStock.ProductType, outStock.BatchNumber, outStock.ReferenceID, outStock.ReferenceType, outStock.ReferenceDetailID, currentStock.BaseUnitValue)] = existing
+						stockDetails[fmt.Sprintf("%d-%d-%s-%s-%d-%s-%d-%s", outStock.WarehouseId, outStock.ProductId, outStock.ProductType, outStock.BatchNumber, outStock.ReferenceID, outStock.ReferenceType, outStock.ReferenceDetailID, currentStock.BaseUnitValue)] = existing
 					} else {
-						uniqueStockDetails[fmt.Sprintf("%d-%d-%s-%s-%d-%s-%d-%s", outStock.WarehouseId, outStock.ProductId, outStock.ProductType, outStock.BatchNumber, outStock.ReferenceID, outStock.ReferenceType, outStock.ReferenceDetailID, currentStock.BaseUnitValue)] = StockHistoryDetailFragment{
+						stockDetails[fmt.Sprintf("%d-%d-%s-%s-%d-%s-%d-%s", outStock.WarehouseId, outStock.ProductId, outStock.ProductType, outStock.BatchNumber, outStock.ReferenceID, outStock.ReferenceType, outStock.ReferenceDetailID, currentStock.BaseUnitValue)] = StockHistoryDetailFragment{
 							BusinessId:        outStock.BusinessId,
 							WarehouseId:       outStock.WarehouseId,
 							ProductId:         outStock.ProductId,
@@ -860,12 +891,12 @@ func calculateCogs(tx *gorm.DB, logger *logrus.Logger, productDetail ProductDeta
 					processQty = decimal.Zero
 				} else {
 					// if the current stock can only partially fulfil the process qty
-					if existing, found := uniqueStocks[fmt.Sprintf("%d-%d-%s-%s-%d-%s-%d", outStock.WarehouseId, outStock.ProductId, outStock.ProductType, outStock.BatchNumber, outStock.ReferenceID, outStock.ReferenceType, outStock.ReferenceDetailID)]; found {
+					if existing, found := stocks[fmt.Sprintf("%d-%d-%s-%s-%d-%s-%d", outStock.WarehouseId, outStock.ProductId, outStock.ProductType, outStock.BatchNumber, outStock.ReferenceID, outStock.ReferenceType, outStock.ReferenceDetailID)]; found {
 						existing.TotalQty = existing.TotalQty.Add(currentStock.Qty)
 						existing.TotalValue = existing.TotalValue.Add(currentStock.Qty.Mul(currentStock.BaseUnitValue))
-						uniqueStocks[fmt.Sprintf("%d-%d-%s-%s-%d-%s-%d", outStock.WarehouseId, outStock.ProductId, outStock.ProductType, outStock.BatchNumber, outStock.ReferenceID, outStock.ReferenceType, outStock.ReferenceDetailID)] = existing
+						stocks[fmt.Sprintf("%d-%d-%s-%s-%d-%s-%d", outStock.WarehouseId, outStock.ProductId, outStock.ProductType, outStock.BatchNumber, outStock.ReferenceID, outStock.ReferenceType, outStock.ReferenceDetailID)] = existing
 					} else {
-						uniqueStocks[fmt.Sprintf("%d-%d-%s-%s-%d-%s-%d", outStock.WarehouseId, outStock.ProductId, outStock.ProductType, outStock.BatchNumber, outStock.ReferenceID, outStock.ReferenceType, outStock.ReferenceDetailID)] = StockHistoryFragment{
+						stocks[fmt.Sprintf("%d-%d-%s-%s-%d-%s-%d", outStock.WarehouseId, outStock.ProductId, outStock.ProductType, outStock.BatchNumber, outStock.ReferenceID, outStock.ReferenceType, outStock.ReferenceDetailID)] = StockHistoryFragment{
 							BusinessId:        outStock.BusinessId,
 							WarehouseId:       outStock.WarehouseId,
 							ProductId:         outStock.ProductId,
@@ -878,11 +909,11 @@ func calculateCogs(tx *gorm.DB, logger *logrus.Logger, productDetail ProductDeta
 							TotalValue:        currentStock.Qty.Mul(currentStock.BaseUnitValue),
 						}
 					}
-					if existing, found := uniqueStockDetails[fmt.Sprintf("%d-%d-%s-%s-%d-%s-%d-%s", outStock.WarehouseId, outStock.ProductId, outStock.ProductType, outStock.BatchNumber, outStock.ReferenceID, outStock.ReferenceType, outStock.ReferenceDetailID, currentStock.BaseUnitValue)]; found {
+					if existing, found := stockDetails[fmt.Sprintf("%d-%d-%s-%s-%d-%s-%d-%s", outStock.WarehouseId, outStock.ProductId, outStock.ProductType, outStock.BatchNumber, outStock.ReferenceID, outStock.ReferenceType, outStock.ReferenceDetailID, currentStock.BaseUnitValue)]; found {
 						existing.Qty = existing.Qty.Add(currentStock.Qty)
-						uniqueStockDetails[fmt.Sprintf("%d-%d-%s-%s-%d-%s-%d-%s", outStock.WarehouseId, outStock.ProductId, outStock.ProductType, outStock.BatchNumber, outStock.ReferenceID, outStock.ReferenceType, outStock.ReferenceDetailID, currentStock.BaseUnitValue)] = existing
+						stockDetails[fmt.Sprintf("%d-%d-%s-%s-%d-%s-%d-%s", outStock.WarehouseId, outStock.ProductId, outStock.ProductType, outStock.BatchNumber, outStock.ReferenceID, outStock.ReferenceType, outStock.ReferenceDetailID, currentStock.BaseUnitValue)] = existing
 					} else {
-						uniqueStockDetails[fmt.Sprintf("%d-%d-%s-%s-%d-%s-%d-%s", outStock.WarehouseId, outStock.ProductId, outStock.ProductType, outStock.BatchNumber, outStock.ReferenceID, outStock.ReferenceType, outStock.ReferenceDetailID, currentStock.BaseUnitValue)] = StockHistoryDetailFragment{
+						stockDetails[fmt.Sprintf("%d-%d-%s-%s-%d-%s-%d-%s", outStock.WarehouseId, outStock.ProductId, outStock.ProductType, outStock.BatchNumber, outStock.ReferenceID, outStock.ReferenceType, outStock.ReferenceDetailID, currentStock.BaseUnitValue)] = StockHistoryDetailFragment{
 							BusinessId:        outStock.BusinessId,
 							WarehouseId:       outStock.WarehouseId,
 							ProductId:         outStock.ProductId,
@@ -913,7 +944,7 @@ func calculateCogs(tx *gorm.DB, logger *logrus.Logger, productDetail ProductDeta
 			}
 		}
 
-		if processQty.GreaterThan(decimal.Zero) {
+		if processQty.GreaterThan(decimal.Zero) && !weightedAverageRows[outStock.ID] {
 			return accountIds, fmt.Errorf("insufficient FIFO layers for product_id=%d product_type=%s warehouse_id=%d batch=%s qty_missing=%s",
 				outStock.ProductId,
 				string(outStock.ProductType),
@@ -972,7 +1003,15 @@ func calculateCogs(tx *gorm.DB, logger *logrus.Logger, productDetail ProductDeta
 				config.LogError(logger, "MainWorkflow.go", "CalculateCogs", "GetSystemAccounts", uStock.BusinessId, err)
 				return accountIds, err
 			}
+			inTransit, err := isInTransitTransferOrder(tx, uStock.BusinessId, uStock.ReferenceId)
+			if err != nil {
+				config.LogError(logger, "MainWorkflow.go", "CalculateCogs", "IsInTransitTransferOrder", uStock.ReferenceId, err)
+				return accountIds, err
+			}
 			git := systemAccounts[models.AccountCodeGoodsInTransfer]
+			if inTransit {
+				git = systemAccounts[models.AccountCodeGoodsInTransit]
+			}
 			inv := productDetail.InventoryAccountId
 
 			// transfer-out deltas
@@ -991,20 +1030,23 @@ func calculateCogs(tx *gorm.DB, logger *logrus.Logger, productDetail ProductDeta
 				BaseCredit: mOut[inv].BaseCredit.Add(delta),
 			}
 
-			// transfer-in deltas (inverse)
-			kIn := journalDeltaKey{businessId: uStock.BusinessId, refType: uStock.ReferenceType, refId: uStock.ReferenceId, transferIn: true}
-			mIn, ok := journalDeltas[kIn]
-			if !ok {
-				mIn = make(map[int]valuationDelta)
-				journalDeltas[kIn] = mIn
-			}
-			mIn[inv] = valuationDelta{
-				BaseDebit:  mIn[inv].BaseDebit.Add(delta),
-				BaseCredit: mIn[inv].BaseCredit,
-			}
-			mIn[git] = valuationDelta{
-				BaseDebit:  mIn[git].BaseDebit,
-				BaseCredit: mIn[git].BaseCredit.Add(delta),
+			// transfer-in deltas (inverse); an in-transit order has no transfer-in journal and its
+			// receipts are reposted by SyncTransferOrderReceiptsFromShipment below.
+			if !inTransit {
+				kIn := journalDeltaKey{businessId: uStock.BusinessId, refType: uStock.ReferenceType, refId: uStock.ReferenceId, transferIn: true}
+				mIn, ok := journalDeltas[kIn]
+				if !ok {
+					mIn = make(map[int]valuationDelta)
+					journalDeltas[kIn] = mIn
+				}
+				mIn[inv] = valuationDelta{
+					BaseDebit:  mIn[inv].BaseDebit.Add(delta),
+					BaseCredit: mIn[inv].BaseCredit,
+				}
+				mIn[git] = valuationDelta{
+					BaseDebit:  mIn[git].BaseDebit,
+					BaseCredit: mIn[git].BaseCredit.Add(delta),
+				}
 			}
 
 			if !slices.Contains(accountIds, git) {
@@ -1013,6 +1055,37 @@ func calculateCogs(tx *gorm.DB, logger *logrus.Logger, productDetail ProductDeta
 			if !slices.Contains(accountIds, inv) {
 				accountIds = append(accountIds, inv)
 			}
+		} else if uStock.ReferenceType == models.StockReferenceTypeAssemblyOrder {
+			// Assembly orders consume into Work In Progress (IsTransferIn=false). The production
+			// journal is reposted by SyncAssemblyOrderOutputFromConsumption below.
+			systemAccounts, err := models.GetSystemAccounts(uStock.BusinessId)
+			if err != nil {
+				config.LogError(logger, "MainWorkflow.go", "CalculateCogs", "GetSystemAccounts", uStock.BusinessId, err)
+				return accountIds, err
+			}
+			wip := systemAccounts[models.AccountCodeWorkInProgress]
+			inv := productDetail.InventoryAccountId
+
+			k := journalDeltaKey{businessId: uStock.BusinessId, refType: uStock.ReferenceType, refId: uStock.ReferenceId, transferIn: false}
+			m, ok := journalDeltas[k]
+			if !ok {
+				m = make(map[int]valuationDelta)
+				journalDeltas[k] = m
+			}
+			m[wip] = valuationDelta{
+				BaseDebit:  m[wip].BaseDebit.Add(delta),
+				BaseCredit: m[wip].BaseCredit,
+			}
+			m[inv] = valuationDelta{
+				BaseDebit:  m[inv].BaseDebit,
+				BaseCredit: m[inv].BaseCredit.Add(delta),
+			}
+			if !slices.Contains(accountIds, wip) {
+				accountIds = append(accountIds, wip)
+			}
+			if !slices.Contains(accountIds, inv) {
+				accountIds = append(accountIds, inv)
+			}
 		} else {
 			k := journalDeltaKey{businessId: uStock.BusinessId, refType: uStock.ReferenceType, refId: uStock.ReferenceId, transferIn: false}
 
@@ -1058,6 +1131,8 @@ func calculateCogs(tx *gorm.DB, logger *logrus.Logger, productDetail ProductDeta
 			refType = models.AccountReferenceTypeSupplierCredit
 		case models.StockReferenceTypeTransferOrder:
 			refType = models.AccountReferenceTypeTransferOrder
+		case models.StockReferenceTypeAssemblyOrder:
+			refType = models.AccountReferenceTypeAssemblyOrder
 		case models.StockReferenceTypeInventoryAdjustmentQuantity:
 			// Inventory Adjustment (Quantity) journals are posted before FIFO valuation splitting.
 			// When FIFO recalculates outgoing unit costs, we must repost the journal so Balance Sheet
@@ -1072,7 +1147,7 @@ func calculateCogs(tx *gorm.DB, logger *logrus.Logger, productDetail ProductDeta
 		}
 
 		var transferInFilter *bool
-		if k.refType == models.StockReferenceTypeTransferOrder {
+		if k.refType == models.StockReferenceTypeTransferOrder || k.refType == models.StockReferenceTypeAssemblyOrder {
 			// Repost correct journal side: transfer-out (false) or transfer-in (true).
 			if k.transferIn {
 				transferInFilter = utils.NewTrue()
@@ -1223,6 +1298,24 @@ func calculateCogs(tx *gorm.DB, logger *logrus.Logger, productDetail ProductDeta
 		if len(transferOrderIDs) > 0 {
 			allowCreate := updatedReferenceId == 0 && updatedReferenceType == ""
 			for transferOrderId := range transferOrderIDs {
+				inTransit, err := isInTransitTransferOrder(tx, outgoingStockHistories[0].BusinessId, transferOrderId)
+				if err != nil {
+					config.LogError(logger, "MainWorkflow.go", "CalculateCogs", "IsInTransitTransferOrder", transferOrderId, err)
+					return accountIds, err
+				}
+				if inTransit {
+					syncedAccountIds, err := SyncTransferOrderReceiptsFromShipment(tx, logger, outgoingStockHistories[0].BusinessId, transferOrderId)
+					if err != nil {
+						config.LogError(logger, "MainWorkflow.go", "CalculateCogs", "SyncTransferOrderReceiptsFromShipment", transferOrderId, err)
+						return accountIds, err
+					}
+					for _, accId := range syncedAccountIds {
+						if !slices.Contains(accountIds, accId) {
+							accountIds = append(accountIds, accId)
+						}
+					}
+					continue
+				}
 				if _, err := SyncTransferOrderTransferInFromOutgoing(tx, logger, outgoingStockHistories[0].BusinessId, transferOrderId, allowCreate); err != nil {
 					config.LogError(logger, "MainWorkflow.go", "CalculateCogs", "SyncTransferOrderTransferInFromOutgoing", transferOrderId, err)
 					return accountIds, err
@@ -1231,6 +1324,28 @@ func calculateCogs(tx *gorm.DB, logger *logrus.Logger, productDetail ProductDeta
 		}
 	}
 
+	// Keep assembly order output layers costed at the repriced consumption.
+	if len(outgoingStockHistories) > 0 {
+		assemblyOrderIDs := make(map[int]struct{})
+		for _, out := range outgoingStockHistories {
+			if out != nil && out.ReferenceType == models.StockReferenceTypeAssemblyOrder && out.ReferenceID > 0 {
+				assemblyOrderIDs[out.ReferenceID] = struct{}{}
+			}
+		}
+		for assemblyOrderId := range assemblyOrderIDs {
+			syncedAccountIds, err := SyncAssemblyOrderOutputFromConsumption(tx, logger, outgoingStockHistories[0].BusinessId, assemblyOrderId)
+			if err != nil {
+				config.LogError(logger, "MainWorkflow.go", "CalculateCogs", "SyncAssemblyOrderOutputFromConsumption", assemblyOrderId, err)
+				return accountIds, err
+			}
+			for _, accId := range syncedAccountIds {
+				if !slices.Contains(accountIds, accId) {
+					accountIds = append(accountIds, accId)
+				}
+			}
+		}
+	}
+
 	return accountIds, err
 }
 
@@ -46,6 +46,13 @@ func LockTypeForReferenceType(referenceType string) (models.TransactionLockType,
 		string(models.AccountReferenceTypeInventoryAdjustmentQuantity),
 		string(models.AccountReferenceTypeInventoryAdjustmentValue),
 		string(models.AccountReferenceTypeTransferOrder),
+		string(models.AccountReferenceTypeAssemblyOrder),
+		string(models.AccountReferenceTypeTransferOrderReceipt),
+		string(models.AccountReferenceTypeLandedCost),
+		string(models.AccountReferenceTypeFixedAssetDepreciation),
+		string(models.AccountReferenceTypeFixedAssetDisposal),
+		string(models.AccountReferenceTypeTaxReturn),
+		string(models.AccountReferenceTypeFxRevaluation),
 		string(models.AccountReferenceTypeProductOpeningStock),
 		string(models.AccountReferenceTypeProductGroupOpeningStock):
 		return models.AccountantTransactionLock, true
@@ -55,6 +55,12 @@ func ProcessReconciliationWorkflow(tx *gorm.DB, logger *logrus.Logger, pubSubMsg
 			err = ProcessInventoryAdjustmentValueWorkflow(tx, logger, msg)
 		case models.AccountReferenceTypeTransferOrder:
 			err = ProcessTransferOrderWorkflow(tx, logger, msg)
+		case models.AccountReferenceTypeAssemblyOrder:
+			err = ProcessAssemblyOrderWorkflow(tx, logger, msg)
+		case models.AccountReferenceTypeTransferOrderReceipt:
+			err = ProcessTransferOrderReceiptWorkflow(tx, logger, msg)
+		case models.AccountReferenceTypeLandedCost:
+			err = ProcessLandedCostWorkflow(tx, logger, msg)
 		case models.AccountReferenceTypeAccountTransfer,
 			models.AccountReferenceTypeAccountDeposit,
 			models.AccountReferenceTypeOwnerContribution,
@@ -96,6 +102,14 @@ func ProcessReconciliationWorkflow(tx *gorm.DB, logger *logrus.Logger, pubSubMsg
 			err = ProcessCustomerAdvanceAppliedWorkflow(tx, logger, msg)
 		case models.AccountReferenceTypePosInvoicePayment:
 			err = ProcessPosInvoicePaymentWorkflow(tx, logger, msg)
+		case models.AccountReferenceTypeFixedAssetDepreciation:
+			err = ProcessFixedAssetDepreciationWorkflow(tx, logger, msg)
+		case models.AccountReferenceTypeFixedAssetDisposal:
+			err = ProcessFixedAssetDisposalWorkflow(tx, logger, msg)
+		case models.AccountReferenceTypeTaxReturn:
+			err = ProcessTaxReturnWorkflow(tx, logger, msg)
+		case models.AccountReferenceTypeFxRevaluation:
+			err = ProcessFxRevaluationWorkflow(tx, logger, msg)
 		}
 		if err != nil {
 			_ = MarkIdempotencyFailed(tx, businessId, handlerName, messageId, err)
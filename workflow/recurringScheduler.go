@@ -0,0 +1,111 @@
+package workflow
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// RecurringScheduler turns recurring profiles into real documents.
+// Each tick picks up profiles whose next occurrence is due and generates them through the
+// normal create paths; per-occurrence claims in models.RecurringRun keep it idempotent.
+// Depreciation, estimate expiry, payment reminders and credit holds run as DailyJobs.
+type RecurringScheduler struct {
+	DB     *gorm.DB
+	Logger *logrus.Logger
+
+	BatchSize    int
+	PollInterval time.Duration
+}
+
+func NewRecurringScheduler(db *gorm.DB, logger *logrus.Logger) *RecurringScheduler {
+	return &RecurringScheduler{
+		DB:           db,
+		Logger:       logger,
+		BatchSize:    50,
+		PollInterval: time.Minute,
+	}
+}
+
+func (s *RecurringScheduler) Run(ctx context.Context) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		s.runOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.PollInterval):
+		}
+	}
+}
+
+func (s *RecurringScheduler) runOnce(ctx context.Context) {
+	if s.DB == nil {
+		return
+	}
+	now := time.Now().UTC()
+
+	bills, err := models.GetDueRecurringBills(ctx, now, s.BatchSize)
+	if err != nil {
+		s.logError("RecurringBill", 0, err)
+	}
+	for _, profile := range bills {
+		profileCtx := recurringContext(ctx, profile.BusinessId)
+		if _, err := models.GenerateRecurringBills(profileCtx, profile.ID, now); err != nil {
+			s.logError("RecurringBill", profile.ID, err)
+		}
+	}
+
+	invoices, err := models.GetDueRecurringInvoices(ctx, now, s.BatchSize)
+	if err != nil {
+		s.logError("RecurringInvoice", 0, err)
+	}
+	for _, profile := range invoices {
+		profileCtx := recurringContext(ctx, profile.BusinessId)
+		if _, err := models.GenerateRecurringInvoices(profileCtx, profile.ID, now); err != nil {
+			s.logError("RecurringInvoice", profile.ID, err)
+		}
+	}
+
+	expenses, err := models.GetDueRecurringExpenses(ctx, now, s.BatchSize)
+	if err != nil {
+		s.logError("RecurringExpense", 0, err)
+	}
+	for _, profile := range expenses {
+		profileCtx := recurringContext(ctx, profile.BusinessId)
+		if _, err := models.GenerateRecurringExpenses(profileCtx, profile.ID, now); err != nil {
+			s.logError("RecurringExpense", profile.ID, err)
+		}
+	}
+}
+
+func (s *RecurringScheduler) logError(profileType string, profileId int, err error) {
+	if s.Logger == nil {
+		return
+	}
+	s.Logger.WithFields(logrus.Fields{
+		"field":        "RecurringScheduler",
+		"profile_type": profileType,
+		"profile_id":   profileId,
+	}).Error(err.Error())
+}
+
+// recurringContext builds the system context documents are generated under.
+func recurringContext(ctx context.Context, businessId string) context.Context {
+	ctx = utils.SetBusinessIdInContext(ctx, businessId)
+	ctx = utils.SetUserIdInContext(ctx, 0)
+	ctx = utils.SetUserNameInContext(ctx, "System")
+	return utils.SetCorrelationIdInContext(ctx, uuid.NewString())
+}
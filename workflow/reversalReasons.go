@@ -23,5 +23,12 @@ const (
 	ReversalReasonInventoryAdjustQtyVoidUpdate     = "Inventory adjustment (qty) void/update"
 	ReversalReasonInventoryAdjustValueVoidUpdate   = "Inventory adjustment (value) void/update"
 	ReversalReasonTransferOrderVoidUpdate          = "Transfer order void/update"
+	ReversalReasonAssemblyOrderDelete              = "Assembly order delete"
+	ReversalReasonTransferOrderReceiptDelete       = "Transfer order receipt delete"
+	ReversalReasonLandedCostVoid                   = "Landed cost void/delete"
 	ReversalReasonInventoryValuationReprice        = "Inventory valuation repricing"
+	ReversalReasonFixedAssetDepreciationReverse    = "Fixed asset depreciation reversal"
+	ReversalReasonFixedAssetDisposalCancel         = "Fixed asset disposal cancel"
+	ReversalReasonTaxReturnDelete                  = "Tax return delete"
+	ReversalReasonFxRevaluationDelete              = "FX revaluation delete"
 )
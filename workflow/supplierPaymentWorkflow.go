@@ -179,23 +179,32 @@ func CreateSupplierPayment(tx *gorm.DB, logger *logrus.Logger, recordId int, rec
 		}
 	}
 
+	// tax withheld settles the bills without leaving the withdraw account
+	cashAmount := billAmount.Sub(supplierPayment.WithholdingTaxAmount)
+	baseWithheld := supplierPayment.WithholdingTaxAmount
+	foreignWithheld := decimal.NewFromInt(0)
+	if baseCurrencyId != paymentCurrencyId {
+		foreignWithheld = supplierPayment.WithholdingTaxAmount
+		baseWithheld = foreignWithheld.Mul(exchangeRate)
+	}
+
 	withdrawAccountCurrencyId := 0
 	if withdrawAccount.CurrencyId == 0 || withdrawAccount.CurrencyId == baseCurrencyId { // base-currency account
 		if baseCurrencyId != paymentCurrencyId {
-			foreignTotalAmount = billAmount.Add(supplierPayment.BankCharges)
+			foreignTotalAmount = cashAmount.Add(supplierPayment.BankCharges)
 			baseTotalAmount = foreignTotalAmount.Mul(exchangeRate)
 			foreignBankCharges = supplierPayment.BankCharges
 			baseBankCharges = foreignBankCharges.Mul(exchangeRate)
 			withdrawAccountCurrencyId = paymentCurrencyId
 			foreignCurrencyId = paymentCurrencyId
 		} else {
-			baseTotalAmount = billAmount.Add(supplierPayment.BankCharges)
+			baseTotalAmount = cashAmount.Add(supplierPayment.BankCharges)
 			baseBankCharges = supplierPayment.BankCharges
 			withdrawAccountCurrencyId = baseCurrencyId
 		}
 	} else { // foreign currency account
 		if baseCurrencyId != paymentCurrencyId {
-			foreignTotalAmount = billAmount.Add(supplierPayment.BankCharges)
+			foreignTotalAmount = cashAmount.Add(supplierPayment.BankCharges)
 			baseTotalAmount = foreignTotalAmount.Mul(exchangeRate)
 			foreignBankCharges = supplierPayment.BankCharges
 			baseBankCharges = foreignBankCharges.Mul(exchangeRate)
@@ -203,14 +212,14 @@ func CreateSupplierPayment(tx *gorm.DB, logger *logrus.Logger, recordId int, rec
 			foreignCurrencyId = paymentCurrencyId
 		} else {
 			if exchangeRate.IsZero() {
-				baseTotalAmount = billAmount.Add(supplierPayment.BankCharges)
+				baseTotalAmount = cashAmount.Add(supplierPayment.BankCharges)
 				foreignTotalAmount = decimal.NewFromInt(0)
 				baseBankCharges = supplierPayment.BankCharges
 				foreignBankCharges = decimal.NewFromInt(0)
 				withdrawAccountCurrencyId = withdrawAccount.CurrencyId
 				foreignCurrencyId = withdrawAccount.CurrencyId
 			} else {
-				baseTotalAmount = billAmount.Add(supplierPayment.BankCharges)
+				baseTotalAmount = cashAmount.Add(supplierPayment.BankCharges)
 				foreignTotalAmount = baseTotalAmount.DivRound(exchangeRate, 4)
 				baseBankCharges = supplierPayment.BankCharges
 				foreignBankCharges = baseBankCharges.DivRound(exchangeRate, 4)
@@ -235,7 +244,7 @@ func CreateSupplierPayment(tx *gorm.DB, logger *logrus.Logger, recordId int, rec
 			BusinessId:        businessId,
 			BranchId:          supplierPayment.BranchId,
 			FromAccountId:     supplierPayment.WithdrawAccountId,
-			FromAccountAmount: billAmount.Add(supplierPayment.BankCharges),
+			FromAccountAmount: cashAmount.Add(supplierPayment.BankCharges),
 			ToAccountId:       systemAccounts[models.AccountCodeAccountsPayable],
 			ToAccountAmount:   billAmount,
 			SupplierId:        supplierPayment.SupplierId,
@@ -295,6 +304,31 @@ func CreateSupplierPayment(tx *gorm.DB, logger *logrus.Logger, recordId int, rec
 	accTransactions = append(accTransactions, withdrawAccountTransact)
 	accountIds = append(accountIds, supplierPayment.WithdrawAccountId)
 
+	if !baseWithheld.IsZero() {
+		var withholdingTax models.WithholdingTax
+		err = tx.First(&withholdingTax, supplierPayment.WithholdingTaxId).Error
+		if err != nil {
+			config.LogError(logger, "SupplierPaymentWorkflow.go", "CreateSupplierPayment", "GetWithholdingTax", supplierPayment.WithholdingTaxId, err)
+			return 0, nil, 0, err
+		}
+		withholdingTaxPayable := models.AccountTransaction{
+			BusinessId:           businessId,
+			AccountId:            withholdingTax.PayableAccountId,
+			BranchId:             branchId,
+			TransactionDateTime:  transactionTime,
+			BaseCurrencyId:       baseCurrencyId,
+			BaseDebit:            decimal.NewFromInt(0),
+			BaseCredit:           baseWithheld,
+			ForeignCurrencyId:    paymentCurrencyId,
+			ForeignDebit:         decimal.NewFromInt(0),
+			ForeignCredit:        foreignWithheld,
+			ExchangeRate:         exchangeRate,
+			BankingTransactionId: bankingTransactionId,
+		}
+		accTransactions = append(accTransactions, withholdingTaxPayable)
+		accountIds = append(accountIds, withholdingTax.PayableAccountId)
+	}
+
 	if !baseBillAmount.Equals(baseAmount) {
 		gainLossAmount := baseAmount.Sub(baseBillAmount)
 		if gainLossAmount.IsPositive() {
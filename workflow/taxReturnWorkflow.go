@@ -0,0 +1,120 @@
+package workflow
+
+import (
+	"encoding/json"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+func ProcessTaxReturnWorkflow(tx *gorm.DB, logger *logrus.Logger, msg config.PubSubMessage) error {
+
+	var accountJournalId int
+	var accountIds []int
+	business, err := models.GetBusinessById2(tx, msg.BusinessId)
+	if err != nil {
+		config.LogError(logger, "TaxReturnWorkflow.go", "ProcessTaxReturnWorkflow", "GetBusiness", msg.BusinessId, err)
+		return err
+	}
+	if msg.Action == string(models.PubSubMessageActionCreate) {
+
+		var taxReturn models.TaxReturn
+		err := json.Unmarshal([]byte(msg.NewObj), &taxReturn)
+		if err != nil {
+			config.LogError(logger, "TaxReturnWorkflow.go", "ProcessTaxReturnWorkflow > Create", "Unmarshal msg.NewObj", msg.NewObj, err)
+			return err
+		}
+		accountJournalId, accountIds, err = CreateTaxReturnSettlement(tx, logger, msg.BusinessId, *business, taxReturn)
+		if err != nil {
+			config.LogError(logger, "TaxReturnWorkflow.go", "ProcessTaxReturnWorkflow > Create", "CreateTaxReturnSettlement", nil, err)
+			return err
+		}
+		err = UpdateBalances(tx, logger, msg.BusinessId, business.BaseCurrencyId, taxReturn.BranchId, accountIds, taxReturn.FiledDate, business.BaseCurrencyId)
+		if err != nil {
+			config.LogError(logger, "TaxReturnWorkflow.go", "ProcessTaxReturnWorkflow > Create", "UpdateBalances", taxReturn, err)
+			return err
+		}
+		err = UpdateBankBalances(tx, business.BaseCurrencyId, taxReturn.BranchId, accountIds, taxReturn.FiledDate)
+		if err != nil {
+			config.LogError(logger, "TaxReturnWorkflow.go", "ProcessTaxReturnWorkflow > Create", "UpdateBankBalances", taxReturn, err)
+			return err
+		}
+	} else if msg.Action == string(models.PubSubMessageActionDelete) {
+
+		var oldTaxReturn models.TaxReturn
+		err = json.Unmarshal([]byte(msg.OldObj), &oldTaxReturn)
+		if err != nil {
+			config.LogError(logger, "TaxReturnWorkflow.go", "ProcessTaxReturnWorkflow > Delete", "Unmarshal msg.OldObj", msg.OldObj, err)
+			return err
+		}
+		accountJournal, _, ids, err := GetExistingAccountJournal(tx, oldTaxReturn.ID, models.AccountReferenceTypeTaxReturn)
+		if err != nil {
+			config.LogError(logger, "TaxReturnWorkflow.go", "ProcessTaxReturnWorkflow > Delete", "GetExistingAccountJournal", oldTaxReturn.ID, err)
+			return err
+		}
+		accountIds = ids
+		accountJournalId, err = ReverseAccountJournal(tx, accountJournal, ReversalReasonTaxReturnDelete)
+		if err != nil {
+			config.LogError(logger, "TaxReturnWorkflow.go", "ProcessTaxReturnWorkflow > Delete", "ReverseAccountJournal", accountJournal, err)
+			return err
+		}
+		err = UpdateBalances(tx, logger, msg.BusinessId, business.BaseCurrencyId, oldTaxReturn.BranchId, accountIds, oldTaxReturn.FiledDate, business.BaseCurrencyId)
+		if err != nil {
+			config.LogError(logger, "TaxReturnWorkflow.go", "ProcessTaxReturnWorkflow > Delete", "UpdateBalances", oldTaxReturn, err)
+			return err
+		}
+		err = UpdateBankBalances(tx, business.BaseCurrencyId, oldTaxReturn.BranchId, accountIds, oldTaxReturn.FiledDate)
+		if err != nil {
+			config.LogError(logger, "TaxReturnWorkflow.go", "ProcessTaxReturnWorkflow > Delete", "UpdateBankBalances", oldTaxReturn, err)
+			return err
+		}
+	}
+	err = tx.Model(&models.PubSubMessageRecord{}).Where("id=?", msg.ID).Updates(map[string]interface{}{"account_journal_id": accountJournalId, "is_processed": true}).Error
+	if err != nil {
+		config.LogError(logger, "TaxReturnWorkflow.go", "ProcessTaxReturnWorkflow", "UpdatePubSubMessageRecord", accountJournalId, err)
+		return err
+	}
+	return nil
+}
+
+// CreateTaxReturnSettlement clears the filed net tax out of tax payable:
+// Dr tax payable, Cr clearing account when tax is payable, the other way round when refundable.
+func CreateTaxReturnSettlement(tx *gorm.DB, logger *logrus.Logger, businessId string, business models.Business, taxReturn models.TaxReturn) (int, []int, error) {
+
+	description := "Tax return settlement"
+	if taxReturn.ReferenceNumber != "" {
+		description += " " + taxReturn.ReferenceNumber
+	}
+	zero := decimal.NewFromInt(0)
+	amount := taxReturn.NetPayable.Abs()
+	payableDebit, clearingDebit := amount, zero
+	if taxReturn.NetPayable.IsNegative() {
+		payableDebit, clearingDebit = zero, amount
+	}
+	accTransactions := []models.AccountTransaction{
+		baseCurrencyTransaction(businessId, business, taxReturn.BranchId, taxReturn.TaxPayableAccountId, taxReturn.FiledDate, description, payableDebit, clearingDebit),
+		baseCurrencyTransaction(businessId, business, taxReturn.BranchId, taxReturn.ClearingAccountId, taxReturn.FiledDate, description, clearingDebit, payableDebit),
+	}
+	accountIds := []int{taxReturn.TaxPayableAccountId, taxReturn.ClearingAccountId}
+
+	accJournal := models.AccountJournal{
+		BusinessId:          businessId,
+		BranchId:            taxReturn.BranchId,
+		TransactionDateTime: taxReturn.FiledDate,
+		TransactionNumber:   taxReturn.ReferenceNumber,
+		TransactionDetails:  description,
+		ReferenceNumber:     taxReturn.ReferenceNumber,
+		ReferenceId:         taxReturn.ID,
+		ReferenceType:       models.AccountReferenceTypeTaxReturn,
+		AccountTransactions: accTransactions,
+	}
+	err := tx.Create(&accJournal).Error
+	if err != nil {
+		config.LogError(logger, "TaxReturnWorkflow.go", "CreateTaxReturnSettlement", "CreateAccountJournal", accJournal, err)
+		return 0, nil, err
+	}
+	return accJournal.ID, accountIds, nil
+}
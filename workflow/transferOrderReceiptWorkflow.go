@@ -0,0 +1,495 @@
+package workflow
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/mmdatafocus/books_backend/utils"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+func ProcessTransferOrderReceiptWorkflow(tx *gorm.DB, logger *logrus.Logger, msg config.PubSubMessage) error {
+
+	var accountJournalId int
+	var accountIds []int
+	var branchIds []int
+	var transactionTime = msg.TransactionDateTime
+	business, err := models.GetBusinessById2(tx, msg.BusinessId)
+	if err != nil {
+		config.LogError(logger, "TransferOrderReceiptWorkflow.go", "ProcessTransferOrderReceiptWorkflow", "GetBusiness", msg.BusinessId, err)
+		return err
+	}
+	if msg.Action == string(models.PubSubMessageActionCreate) {
+
+		var receipt models.TransferOrderReceipt
+		err := json.Unmarshal([]byte(msg.NewObj), &receipt)
+		if err != nil {
+			config.LogError(logger, "TransferOrderReceiptWorkflow.go", "ProcessTransferOrderReceiptWorkflow > Create", "Unmarshal msg.NewObj", msg.NewObj, err)
+			return err
+		}
+		transactionTime = receipt.ReceiptDate
+
+		accountJournalId, accountIds, branchIds, err = CreateTransferOrderReceipt(tx, logger, msg.BusinessId, *business, receipt)
+		if err != nil {
+			config.LogError(logger, "TransferOrderReceiptWorkflow.go", "ProcessTransferOrderReceiptWorkflow > Create", "CreateTransferOrderReceipt", nil, err)
+			return err
+		}
+	} else if msg.Action == string(models.PubSubMessageActionDelete) {
+		var oldReceipt models.TransferOrderReceipt
+		err := json.Unmarshal([]byte(msg.OldObj), &oldReceipt)
+		if err != nil {
+			config.LogError(logger, "TransferOrderReceiptWorkflow.go", "ProcessTransferOrderReceiptWorkflow > Delete", "Unmarshal msg.OldObj", msg.OldObj, err)
+			return err
+		}
+		transactionTime = oldReceipt.ReceiptDate
+
+		var stockHistories []*models.StockHistory
+		accountJournalId, accountIds, branchIds, stockHistories, err = DeleteTransferOrderReceipt(tx, logger, msg.BusinessId, oldReceipt)
+		if err != nil {
+			config.LogError(logger, "TransferOrderReceiptWorkflow.go", "ProcessTransferOrderReceiptWorkflow > Delete", "DeleteTransferOrderReceipt", nil, err)
+			return err
+		}
+		valuationAccountIds, err := ProcessStockHistories(tx, logger, stockHistories)
+		if err != nil {
+			if scope, ok := parseFifoInsufficientScope(err); ok {
+				if rerr := rebuildInventoryForScope(tx, logger, msg.BusinessId, scope, oldReceipt.ReceiptDate); rerr == nil {
+					valuationAccountIds, err = ProcessStockHistories(tx, logger, stockHistories)
+				}
+			}
+		}
+		if err != nil {
+			config.LogError(logger, "TransferOrderReceiptWorkflow.go", "ProcessTransferOrderReceiptWorkflow > Delete", "ProcessStockHistories", stockHistories, err)
+			return err
+		}
+		for _, accId := range valuationAccountIds {
+			if !slices.Contains(accountIds, accId) {
+				accountIds = append(accountIds, accId)
+			}
+		}
+	}
+	for _, branchId := range branchIds {
+		err = UpdateBalances(tx, logger, msg.BusinessId, business.BaseCurrencyId, branchId, accountIds, transactionTime, business.BaseCurrencyId)
+		if err != nil {
+			config.LogError(logger, "TransferOrderReceiptWorkflow.go", "ProcessTransferOrderReceiptWorkflow", "UpdateBalances", branchId, err)
+			return err
+		}
+	}
+	err = tx.Model(&models.PubSubMessageRecord{}).Where("id=?", msg.ID).Updates(map[string]interface{}{"account_journal_id": accountJournalId, "is_processed": true}).Error
+	if err != nil {
+		config.LogError(logger, "TransferOrderReceiptWorkflow.go", "ProcessTransferOrderReceiptWorkflow", "UpdatePubSubMessageRecord", accountJournalId, err)
+		return err
+	}
+	return nil
+}
+
+// transferReceiptAccounts are the accounts and branches a receipt of one transfer order
+// posts to.
+type transferReceiptAccounts struct {
+	transit             int
+	interBranch         int
+	writeOff            int
+	sourceBranchId      int
+	destinationBranchId int
+	destinationWhId     int
+}
+
+func (a transferReceiptAccounts) branchIds() []int {
+	if a.sourceBranchId == a.destinationBranchId {
+		return []int{a.destinationBranchId}
+	}
+	return []int{a.sourceBranchId, a.destinationBranchId}
+}
+
+func transferOrderReceiptAccounts(tx *gorm.DB, businessId string, transferOrder models.TransferOrder, writeOffAccountId int) (transferReceiptAccounts, error) {
+	systemAccounts, err := models.GetSystemAccounts(businessId)
+	if err != nil {
+		return transferReceiptAccounts{}, err
+	}
+	var sourceWarehouse, destinationWarehouse models.Warehouse
+	if err := tx.Where("business_id = ? AND id = ?", businessId, transferOrder.SourceWarehouseId).First(&sourceWarehouse).Error; err != nil {
+		return transferReceiptAccounts{}, err
+	}
+	if err := tx.Where("business_id = ? AND id = ?", businessId, transferOrder.DestinationWarehouseId).First(&destinationWarehouse).Error; err != nil {
+		return transferReceiptAccounts{}, err
+	}
+	accounts := transferReceiptAccounts{
+		transit:             systemAccounts[models.AccountCodeGoodsInTransit],
+		interBranch:         systemAccounts[models.AccountCodeInterBranchAccount],
+		writeOff:            writeOffAccountId,
+		sourceBranchId:      sourceWarehouse.BranchId,
+		destinationBranchId: destinationWarehouse.BranchId,
+		destinationWhId:     destinationWarehouse.ID,
+	}
+	if accounts.transit == 0 {
+		return accounts, errors.New("goods in transit account not found")
+	}
+	if accounts.sourceBranchId != accounts.destinationBranchId && accounts.interBranch == 0 {
+		return accounts, errors.New("inter branch account not found")
+	}
+	return accounts, nil
+}
+
+// transferReceiptValues is what a receipt takes out of Goods In Transit: the received value
+// per inventory account and the value of short and damaged goods.
+type transferReceiptValues struct {
+	inventory  map[int]decimal.Decimal
+	writtenOff decimal.Decimal
+}
+
+func newTransferReceiptValues() transferReceiptValues {
+	return transferReceiptValues{inventory: make(map[int]decimal.Decimal)}
+}
+
+func (v *transferReceiptValues) add(inventoryAccountId int, line models.TransferOrderReceiptDetail, unitCost decimal.Decimal) {
+	v.inventory[inventoryAccountId] = v.inventory[inventoryAccountId].Add(line.ReceivedQty.Mul(unitCost))
+	v.writtenOff = v.writtenOff.Add(line.ShortQty.Add(line.DamagedQty).Mul(unitCost))
+}
+
+// postings splits the values into journal sides keyed by IsTransferIn. Goods In Transit
+// was debited in the source branch when the order shipped; when the destination is another
+// branch, the source clears it against the Inter Branch account (IsTransferIn=false) and
+// the destination takes the goods in against it (IsTransferIn=true).
+func (v transferReceiptValues) postings(accounts transferReceiptAccounts) map[bool]map[int]valuationDelta {
+	total := v.writtenOff
+	destination := make(map[int]valuationDelta)
+	for accId, value := range v.inventory {
+		total = total.Add(value)
+		destination[accId] = valuationDelta{BaseDebit: destination[accId].BaseDebit.Add(value), BaseCredit: destination[accId].BaseCredit}
+	}
+	if !v.writtenOff.IsZero() {
+		destination[accounts.writeOff] = valuationDelta{BaseDebit: destination[accounts.writeOff].BaseDebit.Add(v.writtenOff), BaseCredit: destination[accounts.writeOff].BaseCredit}
+	}
+	if accounts.sourceBranchId == accounts.destinationBranchId {
+		destination[accounts.transit] = valuationDelta{BaseDebit: destination[accounts.transit].BaseDebit, BaseCredit: destination[accounts.transit].BaseCredit.Add(total)}
+		return map[bool]map[int]valuationDelta{true: destination}
+	}
+	destination[accounts.interBranch] = valuationDelta{BaseCredit: total}
+	source := map[int]valuationDelta{
+		accounts.interBranch: {BaseDebit: total},
+		accounts.transit:     {BaseCredit: total},
+	}
+	return map[bool]map[int]valuationDelta{false: source, true: destination}
+}
+
+// transferOrderReceiptStockHistory is the unsaved destination row for a received line.
+func transferOrderReceiptStockHistory(receipt models.TransferOrderReceipt, line models.TransferOrderReceiptDetail, warehouseId int, stockDate time.Time, unitCost decimal.Decimal) *models.StockHistory {
+	return &models.StockHistory{
+		BusinessId:        receipt.BusinessId,
+		WarehouseId:       warehouseId,
+		ProductId:         line.ProductId,
+		ProductType:       line.ProductType,
+		BatchNumber:       line.BatchNumber,
+		StockDate:         stockDate,
+		Qty:               line.ReceivedQty,
+		BaseUnitValue:     unitCost,
+		Description:       "Transfer Received",
+		ReferenceType:     models.StockReferenceTypeTransferOrderReceipt,
+		ReferenceID:       receipt.ID,
+		ReferenceDetailID: line.ID,
+		IsOutgoing:        utils.NewFalse(),
+		IsTransferIn:      utils.NewTrue(),
+	}
+}
+
+// CreateTransferOrderReceipt brings received goods into the destination warehouse at the
+// cost they were shipped at and clears them, with any short or damaged goods, out of
+// Goods In Transit. It returns the branches it posted to.
+func CreateTransferOrderReceipt(tx *gorm.DB, logger *logrus.Logger, businessId string, business models.Business, receipt models.TransferOrderReceipt) (int, []int, []int, error) {
+	var transferOrder models.TransferOrder
+	if err := tx.Where("business_id = ? AND id = ?", businessId, receipt.TransferOrderId).First(&transferOrder).Error; err != nil {
+		config.LogError(logger, "TransferOrderReceiptWorkflow.go", "CreateTransferOrderReceipt", "GetTransferOrder", receipt.TransferOrderId, err)
+		return 0, nil, nil, err
+	}
+	accounts, err := transferOrderReceiptAccounts(tx, businessId, transferOrder, receipt.AccountId)
+	if err != nil {
+		config.LogError(logger, "TransferOrderReceiptWorkflow.go", "CreateTransferOrderReceipt", "GetAccounts", transferOrder.ID, err)
+		return 0, nil, nil, err
+	}
+	unitCosts, err := models.TransferOrderShippedUnitCosts(tx, businessId, transferOrder.ID)
+	if err != nil {
+		config.LogError(logger, "TransferOrderReceiptWorkflow.go", "CreateTransferOrderReceipt", "GetShippedUnitCosts", transferOrder.ID, err)
+		return 0, nil, nil, err
+	}
+	stockDate, err := utils.ConvertToDate(receipt.ReceiptDate, business.Timezone)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	values := newTransferReceiptValues()
+	receivedStockHistories := make([]*models.StockHistory, 0)
+	for _, line := range receipt.Details {
+		if line.ProductId <= 0 {
+			continue
+		}
+		productDetail, err := GetProductDetail(tx, line.ProductId, line.ProductType)
+		if err != nil {
+			config.LogError(logger, "TransferOrderReceiptWorkflow.go", "CreateTransferOrderReceipt", "GetProductDetail", line, err)
+			return 0, nil, nil, err
+		}
+		unitCost := unitCosts[line.TransferOrderDetailId]
+		values.add(productDetail.InventoryAccountId, line, unitCost)
+		if err := tx.Model(&models.TransferOrderReceiptDetail{}).Where("id = ?", line.ID).UpdateColumn("unit_cost", unitCost).Error; err != nil {
+			config.LogError(logger, "TransferOrderReceiptWorkflow.go", "CreateTransferOrderReceipt", "UpdateUnitCost", line, err)
+			return 0, nil, nil, err
+		}
+		if line.ReceivedQty.IsPositive() {
+			receivedStockHistories = append(receivedStockHistories, transferOrderReceiptStockHistory(receipt, line, accounts.destinationWhId, stockDate, unitCost))
+		}
+	}
+
+	accountIds := make([]int, 0)
+	journalId := 0
+	postings := values.postings(accounts)
+	for _, transferIn := range []bool{false, true} {
+		deltas, ok := postings[transferIn]
+		if !ok {
+			continue
+		}
+		branchId := accounts.destinationBranchId
+		if !transferIn {
+			branchId = accounts.sourceBranchId
+		}
+		transactions := make([]models.AccountTransaction, 0, len(deltas))
+		for _, accId := range sortedDeltaAccountIds(deltas) {
+			if !slices.Contains(accountIds, accId) {
+				accountIds = append(accountIds, accId)
+			}
+			transactions = append(transactions,
+				assemblyValuationLine(businessId, accId, branchId, receipt.ReceiptDate, business.BaseCurrencyId, deltas[accId].BaseDebit, deltas[accId].BaseCredit, transferIn))
+		}
+		journal := models.AccountJournal{
+			BusinessId:          businessId,
+			BranchId:            branchId,
+			TransactionDateTime: receipt.ReceiptDate,
+			TransactionNumber:   strconv.Itoa(receipt.ID),
+			ReferenceId:         receipt.ID,
+			ReferenceType:       models.AccountReferenceTypeTransferOrderReceipt,
+			AccountTransactions: transactions,
+		}
+		if err := tx.Create(&journal).Error; err != nil {
+			config.LogError(logger, "TransferOrderReceiptWorkflow.go", "CreateTransferOrderReceipt", "CreateAccountJournal", journal, err)
+			return 0, nil, nil, err
+		}
+		if transferIn {
+			journalId = journal.ID
+		}
+	}
+
+	for _, stockHistory := range receivedStockHistories {
+		if err := tx.Create(stockHistory).Error; err != nil {
+			config.LogError(logger, "TransferOrderReceiptWorkflow.go", "CreateTransferOrderReceipt", "CreateStockHistory", stockHistory, err)
+			return 0, nil, nil, err
+		}
+	}
+	valuationAccountIds, err := ProcessIncomingStocks(tx, logger, receivedStockHistories)
+	if err != nil {
+		config.LogError(logger, "TransferOrderReceiptWorkflow.go", "CreateTransferOrderReceipt", "ProcessIncomingStocks", receivedStockHistories, err)
+		return 0, nil, nil, err
+	}
+	for _, accId := range valuationAccountIds {
+		if !slices.Contains(accountIds, accId) {
+			accountIds = append(accountIds, accId)
+		}
+	}
+
+	return journalId, accountIds, accounts.branchIds(), nil
+}
+
+// DeleteTransferOrderReceipt reverses a receipt's journals and received stock rows and
+// returns the branches its journals were in.
+func DeleteTransferOrderReceipt(tx *gorm.DB, logger *logrus.Logger, businessId string, oldReceipt models.TransferOrderReceipt) (int, []int, []int, []*models.StockHistory, error) {
+	var journals []models.AccountJournal
+	if err := tx.Preload("AccountTransactions").
+		Where("business_id = ? AND reference_id = ? AND reference_type = ? AND is_reversal = 0 AND reversed_by_journal_id IS NULL", businessId, oldReceipt.ID, models.AccountReferenceTypeTransferOrderReceipt).
+		Find(&journals).Error; err != nil {
+		config.LogError(logger, "TransferOrderReceiptWorkflow.go", "DeleteTransferOrderReceipt", "FindAccountJournals", oldReceipt, err)
+		return 0, nil, nil, nil, err
+	}
+	accountIds := make([]int, 0)
+	branchIds := make([]int, 0)
+	reversalID := 0
+	for _, j := range journals {
+		if !slices.Contains(branchIds, j.BranchId) {
+			branchIds = append(branchIds, j.BranchId)
+		}
+		for _, t := range j.AccountTransactions {
+			if !slices.Contains(accountIds, t.AccountId) {
+				accountIds = append(accountIds, t.AccountId)
+			}
+		}
+		rid, err := ReverseAccountJournal(tx, &j, ReversalReasonTransferOrderReceiptDelete)
+		if err != nil {
+			config.LogError(logger, "TransferOrderReceiptWorkflow.go", "DeleteTransferOrderReceipt", "ReverseAccountJournal", j, err)
+			return 0, nil, nil, nil, err
+		}
+		if reversalID == 0 {
+			reversalID = rid
+		}
+	}
+
+	var stockHistories []*models.StockHistory
+	if err := tx.
+		Where("business_id = ? AND reference_id = ? AND reference_type = ? AND is_reversal = 0 AND reversed_by_stock_history_id IS NULL", businessId, oldReceipt.ID, models.StockReferenceTypeTransferOrderReceipt).
+		Find(&stockHistories).Error; err != nil {
+		config.LogError(logger, "TransferOrderReceiptWorkflow.go", "DeleteTransferOrderReceipt", "FindStockHistories", oldReceipt, err)
+		return 0, nil, nil, nil, err
+	}
+	stockReversals, err := ReverseStockHistories(tx, stockHistories, ReversalReasonTransferOrderReceiptDelete)
+	if err != nil {
+		config.LogError(logger, "TransferOrderReceiptWorkflow.go", "DeleteTransferOrderReceipt", "ReverseStockHistories", oldReceipt, err)
+		return 0, nil, nil, nil, err
+	}
+
+	return reversalID, accountIds, branchIds, stockReversals, nil
+}
+
+// SyncTransferOrderReceiptsFromShipment re-costs the receipts of an in-transit transfer
+// order after its transfer-out rows were repriced: received rows are replaced at the new
+// shipped cost and the receipt journals are reposted by the change in value.
+func SyncTransferOrderReceiptsFromShipment(tx *gorm.DB, logger *logrus.Logger, businessId string, transferOrderId int) ([]int, error) {
+	var receipts []models.TransferOrderReceipt
+	if err := tx.Preload("Details").
+		Where("business_id = ? AND transfer_order_id = ?", businessId, transferOrderId).
+		Order("id").
+		Find(&receipts).Error; err != nil {
+		return nil, err
+	}
+	if len(receipts) == 0 {
+		return nil, nil
+	}
+
+	var transferOrder models.TransferOrder
+	err := tx.Where("business_id = ? AND id = ?", businessId, transferOrderId).First(&transferOrder).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	unitCosts, err := models.TransferOrderShippedUnitCosts(tx, businessId, transferOrderId)
+	if err != nil {
+		return nil, err
+	}
+
+	accountIds := make([]int, 0)
+	for _, receipt := range receipts {
+		accounts, err := transferOrderReceiptAccounts(tx, businessId, transferOrder, receipt.AccountId)
+		if err != nil {
+			return accountIds, err
+		}
+
+		delta := newTransferReceiptValues()
+		repriced := make(map[int]decimal.Decimal)
+		for _, line := range receipt.Details {
+			unitCost := unitCosts[line.TransferOrderDetailId]
+			if line.ProductId <= 0 || unitCost.Equal(line.UnitCost) {
+				continue
+			}
+			productDetail, err := GetProductDetail(tx, line.ProductId, line.ProductType)
+			if err != nil {
+				return accountIds, err
+			}
+			delta.add(productDetail.InventoryAccountId, line, unitCost.Sub(line.UnitCost))
+			repriced[line.ID] = unitCost
+			if err := tx.Model(&models.TransferOrderReceiptDetail{}).Where("id = ?", line.ID).UpdateColumn("unit_cost", unitCost).Error; err != nil {
+				return accountIds, err
+			}
+		}
+		if len(repriced) == 0 {
+			continue
+		}
+
+		var inRows []*models.StockHistory
+		if err := tx.
+			Where("business_id = ? AND reference_type = ? AND reference_id = ? AND is_reversal = 0 AND reversed_by_stock_history_id IS NULL",
+				businessId, models.StockReferenceTypeTransferOrderReceipt, receipt.ID).
+			Order("id").
+			Find(&inRows).Error; err != nil {
+			return accountIds, err
+		}
+		toReverse := make([]*models.StockHistory, 0)
+		replacements := make([]*models.StockHistory, 0)
+		for _, r := range inRows {
+			unitCost, ok := repriced[r.ReferenceDetailID]
+			if !ok {
+				continue
+			}
+			toReverse = append(toReverse, r)
+			for _, line := range receipt.Details {
+				if line.ID == r.ReferenceDetailID {
+					replacements = append(replacements, transferOrderReceiptStockHistory(receipt, line, r.WarehouseId, r.StockDate, unitCost))
+				}
+			}
+		}
+		if len(toReverse) > 0 {
+			if _, err := ReverseStockHistories(tx, toReverse, ReversalReasonInventoryValuationReprice); err != nil {
+				return accountIds, err
+			}
+			for _, r := range replacements {
+				if err := tx.Create(r).Error; err != nil {
+					return accountIds, err
+				}
+			}
+			valuationAccountIds, err := ProcessIncomingStocks(tx, logger, replacements)
+			if err != nil {
+				return accountIds, err
+			}
+			for _, accId := range valuationAccountIds {
+				if !slices.Contains(accountIds, accId) {
+					accountIds = append(accountIds, accId)
+				}
+			}
+		}
+
+		for transferIn, deltas := range delta.postings(accounts) {
+			for accId := range deltas {
+				if !slices.Contains(accountIds, accId) {
+					accountIds = append(accountIds, accId)
+				}
+			}
+			if _, _, err := repostJournalWithValuationDeltas(
+				tx,
+				logger,
+				businessId,
+				models.AccountReferenceTypeTransferOrderReceipt,
+				receipt.ID,
+				deltas,
+				&transferIn,
+				ReversalReasonInventoryValuationReprice,
+			); err != nil {
+				return accountIds, err
+			}
+		}
+	}
+	return accountIds, nil
+}
+
+// isInTransitTransferOrder reports whether a transfer order ships through Goods In Transit
+// with separate receipts. Deleted orders are not.
+func isInTransitTransferOrder(tx *gorm.DB, businessId string, transferOrderId int) (bool, error) {
+	var transferOrder models.TransferOrder
+	err := tx.Select("id", "current_status").Where("business_id = ? AND id = ?", businessId, transferOrderId).First(&transferOrder).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return transferOrder.IsInTransitTransfer(), nil
+}
+
+func sortedDeltaAccountIds(deltas map[int]valuationDelta) []int {
+	ids := make([]int, 0, len(deltas))
+	for id := range deltas {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
@@ -147,6 +147,14 @@ func CreateTransferOrder(tx *gorm.DB, logger *logrus.Logger, recordId int, recor
 	sourceBranchId := sourceWarehouse.BranchId
 	destinationBranchId := destinationWarehouse.BranchId
 
+	// An in-transit order only ships here: the goods wait in Goods In Transit and its
+	// receipts post the destination side.
+	inTransit := transferOrder.IsInTransitTransfer()
+	transitAccountId := systemAccounts[models.AccountCodeGoodsInTransfer]
+	if inTransit {
+		transitAccountId = systemAccounts[models.AccountCodeGoodsInTransit]
+	}
+
 	accountIds := make([]int, 0)
 	sourceAccTransactions := make([]models.AccountTransaction, 0)
 	destinationAccTransactions := make([]models.AccountTransaction, 0)
@@ -206,10 +214,10 @@ func CreateTransferOrder(tx *gorm.DB, logger *logrus.Logger, recordId int, recor
 		}
 	}
 
-	accountIds = append(accountIds, systemAccounts[models.AccountCodeGoodsInTransfer])
+	accountIds = append(accountIds, transitAccountId)
 	sourceAccTransactions = append(sourceAccTransactions, models.AccountTransaction{
 		BusinessId:           businessId,
-		AccountId:            systemAccounts[models.AccountCodeGoodsInTransfer],
+		AccountId:            transitAccountId,
 		BranchId:             sourceBranchId,
 		TransactionDateTime:  transactionTime,
 		BaseCurrencyId:       baseCurrencyId,
@@ -253,45 +261,47 @@ func CreateTransferOrder(tx *gorm.DB, logger *logrus.Logger, recordId int, recor
 		return 0, nil, 0, err
 	}
 
-	// IMPORTANT:
-	// Transfer Orders have TWO journals (source transfer-out + destination transfer-in).
-	// Outgoing stock valuation (ProcessOutgoingStocks -> CalculateCogs) may trigger a journal repost
-	// (reverse+replace) for BOTH sides to keep "Goods In Transfer" balanced.
-	//
-	// If the destination journal doesn't exist yet, reposting the transfer-in side will fail with:
-	//   "repost journal: matching active journal not found"
-	//
-	// To avoid that, create a placeholder destination journal now (with IsTransferIn=true lines).
-	// The valuation repost will update both journals once the true COGS is known.
-	for _, srcTx := range sourceAccTransactions {
-		dt := srcTx
-		dt.ID = 0
-		dt.BranchId = destinationBranchId
-		dt.IsTransferIn = utils.NewTrue()
-		// Mirror debit/credit direction (even if 0 at this stage).
-		if dt.AccountId == systemAccounts[models.AccountCodeGoodsInTransfer] {
-			dt.BaseCredit = dt.BaseDebit
-			dt.BaseDebit = decimal.NewFromInt(0)
-		} else {
-			dt.BaseDebit = dt.BaseCredit
-			dt.BaseCredit = decimal.NewFromInt(0)
-		}
-		destinationAccTransactions = append(destinationAccTransactions, dt)
-	}
+	if !inTransit {
+		// IMPORTANT:
+		// Transfer Orders have TWO journals (source transfer-out + destination transfer-in).
+		// Outgoing stock valuation (ProcessOutgoingStocks -> CalculateCogs) may trigger a journal repost
+		// (reverse+replace) for BOTH sides to keep "Goods In Transfer" balanced.
+		//
+		// If the destination journal doesn't exist yet, reposting the transfer-in side will fail with:
+		//   "repost journal: matching active journal not found"
+		//
+		// To avoid that, create a placeholder destination journal now (with IsTransferIn=true lines).
+		// The valuation repost will update both journals once the true COGS is known.
+		for _, srcTx := range sourceAccTransactions {
+			dt := srcTx
+			dt.ID = 0
+			dt.BranchId = destinationBranchId
+			dt.IsTransferIn = utils.NewTrue()
+			// Mirror debit/credit direction (even if 0 at this stage).
+			if dt.AccountId == systemAccounts[models.AccountCodeGoodsInTransfer] {
+				dt.BaseCredit = dt.BaseDebit
+				dt.BaseDebit = decimal.NewFromInt(0)
+			} else {
+				dt.BaseDebit = dt.BaseCredit
+				dt.BaseCredit = decimal.NewFromInt(0)
+			}
+			destinationAccTransactions = append(destinationAccTransactions, dt)
+		}
 
-	destinationAccJournal := models.AccountJournal{
-		BusinessId:          businessId,
-		BranchId:            destinationBranchId,
-		TransactionDateTime: transactionTime,
-		TransactionNumber:   strconv.Itoa(transferOrder.ID),
-		ReferenceId:         transferOrder.ID,
-		ReferenceType:       models.AccountReferenceTypeTransferOrder,
-		AccountTransactions: destinationAccTransactions,
-	}
-	if err := tx.Create(&destinationAccJournal).Error; err != nil {
-		tx.Rollback()
-		config.LogError(logger, "TransferOrderWorkflow.go", "CreateTransferOrder", "CreateDestinationAccountJournal", destinationAccJournal, err)
-		return 0, nil, 0, err
+		destinationAccJournal := models.AccountJournal{
+			BusinessId:          businessId,
+			BranchId:            destinationBranchId,
+			TransactionDateTime: transactionTime,
+			TransactionNumber:   strconv.Itoa(transferOrder.ID),
+			ReferenceId:         transferOrder.ID,
+			ReferenceType:       models.AccountReferenceTypeTransferOrder,
+			AccountTransactions: destinationAccTransactions,
+		}
+		if err := tx.Create(&destinationAccJournal).Error; err != nil {
+			tx.Rollback()
+			config.LogError(logger, "TransferOrderWorkflow.go", "CreateTransferOrder", "CreateDestinationAccountJournal", destinationAccJournal, err)
+			return 0, nil, 0, err
+		}
 	}
 
 	valuationAccountIds, err := ProcessOutgoingStocks(tx, logger, transferOutStockHistories)
@@ -315,6 +325,9 @@ func CreateTransferOrder(tx *gorm.DB, logger *logrus.Logger, recordId int, recor
 			}
 		}
 	}
+	if inTransit {
+		return accJournal.ID, accountIds, foreignCurrencyId, nil
+	}
 
 	var updatedTransferOutStockHistories []*models.StockHistory
 	err = tx.Where("business_id = ? AND reference_id = ? AND reference_type = ?", businessId, transferOrder.ID, models.StockReferenceTypeTransferOrder).Find(&updatedTransferOutStockHistories).Error
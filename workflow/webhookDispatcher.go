@@ -0,0 +1,239 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/mmdatafocus/books_backend/webhook"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WebhookDispatcher posts queued models.WebhookDelivery rows to their endpoints.
+// Claiming, retry backoff and the DEAD state work like EmailDispatcher; every attempt's
+// response is kept on the row as the delivery log.
+type WebhookDispatcher struct {
+	DB           *gorm.DB
+	Logger       *logrus.Logger
+	Client       *webhook.Client
+	DispatcherID string
+
+	BatchSize      int
+	PollInterval   time.Duration
+	LockTimeout    time.Duration
+	MaxAttempts    int
+	InitialBackoff time.Duration
+}
+
+func NewWebhookDispatcher(db *gorm.DB, logger *logrus.Logger) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		DB:             db,
+		Logger:         logger,
+		Client:         webhook.NewClient(15 * time.Second),
+		DispatcherID:   uuid.NewString(),
+		BatchSize:      20,
+		PollInterval:   5 * time.Second,
+		LockTimeout:    5 * time.Minute,
+		MaxAttempts:    10,
+		InitialBackoff: 30 * time.Second,
+	}
+}
+
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		d.dispatchOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(d.PollInterval):
+		}
+	}
+}
+
+func (d *WebhookDispatcher) dispatchOnce(ctx context.Context) {
+	if d.DB == nil || d.Client == nil {
+		return
+	}
+	now := time.Now().UTC()
+	staleBefore := now.Add(-d.LockTimeout)
+
+	var claimed []models.WebhookDelivery
+	err := d.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Eligible:
+		// - Pending / Failed and ready to retry
+		// - Processing but lock is stale (dispatcher crashed mid-send), reclaim after LockTimeout
+		q := tx.
+			Where(`
+				(
+					status IN ? AND (next_attempt_at IS NULL OR next_attempt_at <= ?)
+				)
+				OR
+				(
+					status = ? AND locked_at IS NOT NULL AND locked_at <= ?
+				)
+			`, []models.WebhookDeliveryStatus{models.WebhookDeliveryStatusPending, models.WebhookDeliveryStatusFailed}, now,
+				models.WebhookDeliveryStatusProcessing, staleBefore).
+			Order("id ASC").
+			Limit(d.BatchSize).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+		if err := q.Find(&claimed).Error; err != nil {
+			return err
+		}
+		for i := range claimed {
+			claimed[i].Status = models.WebhookDeliveryStatusProcessing
+			claimed[i].Attempts++
+			if err := tx.Model(&models.WebhookDelivery{}).Where("id = ?", claimed[i].ID).Updates(map[string]interface{}{
+				"status":          models.WebhookDeliveryStatusProcessing,
+				"locked_at":       &now,
+				"locked_by":       &d.DispatcherID,
+				"attempts":        gorm.Expr("attempts + 1"),
+				"next_attempt_at": nil,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		d.logError(0, "", err)
+		return
+	}
+
+	for i := range claimed {
+		delivery := &claimed[i]
+		var endpoint models.WebhookEndpoint
+		err := d.DB.WithContext(ctx).
+			Where("business_id = ? AND id = ?", delivery.BusinessId, delivery.EndpointId).
+			Limit(1).
+			Find(&endpoint).Error
+		if err != nil {
+			d.markFailed(ctx, delivery, nil, err)
+			continue
+		}
+		// Deliveries of removed or disabled endpoints are dropped rather than retried.
+		if endpoint.ID == 0 {
+			d.markDead(ctx, delivery, nil, errors.New("endpoint was deleted"))
+			continue
+		}
+		if endpoint.IsActive != nil && !*endpoint.IsActive {
+			d.markDead(ctx, delivery, nil, errors.New("endpoint is disabled"))
+			continue
+		}
+
+		resp, sendErr := d.Client.Deliver(ctx, &webhook.Request{
+			URL:        endpoint.Url,
+			Secret:     endpoint.Secret,
+			Event:      string(delivery.EventType),
+			DeliveryId: strconv.Itoa(delivery.ID),
+			Payload:    []byte(delivery.Payload),
+		})
+		if sendErr != nil {
+			d.markFailed(ctx, delivery, resp, sendErr)
+			continue
+		}
+		d.markSucceeded(ctx, delivery, resp)
+	}
+}
+
+// responseUpdates records what the endpoint answered on this attempt.
+func responseUpdates(updates map[string]interface{}, resp *webhook.Response) map[string]interface{} {
+	if resp == nil {
+		return updates
+	}
+	updates["response_status"] = resp.StatusCode
+	updates["duration_ms"] = int(resp.Duration / time.Millisecond)
+	if resp.Body != "" {
+		updates["response_body"] = &resp.Body
+	} else {
+		updates["response_body"] = nil
+	}
+	return updates
+}
+
+func (d *WebhookDispatcher) markSucceeded(ctx context.Context, delivery *models.WebhookDelivery, resp *webhook.Response) {
+	now := time.Now().UTC()
+	if err := d.DB.WithContext(ctx).Model(&models.WebhookDelivery{}).
+		Where("id = ?", delivery.ID).
+		Updates(responseUpdates(map[string]interface{}{
+			"status":          models.WebhookDeliveryStatusSucceeded,
+			"delivered_at":    &now,
+			"last_error":      nil,
+			"locked_at":       nil,
+			"locked_by":       nil,
+			"next_attempt_at": nil,
+		}, resp)).Error; err != nil {
+		d.logError(delivery.ID, delivery.BusinessId, err)
+	}
+}
+
+func (d *WebhookDispatcher) markDead(ctx context.Context, delivery *models.WebhookDelivery, resp *webhook.Response, sendErr error) {
+	errMsg := sendErr.Error()
+	if err := d.DB.WithContext(ctx).Model(&models.WebhookDelivery{}).
+		Where("id = ?", delivery.ID).
+		Updates(responseUpdates(map[string]interface{}{
+			"status":          models.WebhookDeliveryStatusDead,
+			"last_error":      &errMsg,
+			"next_attempt_at": nil,
+			"locked_at":       nil,
+			"locked_by":       nil,
+		}, resp)).Error; err != nil {
+		d.logError(delivery.ID, delivery.BusinessId, err)
+	}
+	d.logError(delivery.ID, delivery.BusinessId, fmt.Errorf("webhook delivery moved to DEAD: %w", sendErr))
+}
+
+func (d *WebhookDispatcher) markFailed(ctx context.Context, delivery *models.WebhookDelivery, resp *webhook.Response, sendErr error) {
+	// Terminal after MaxAttempts; it can still be redelivered by hand.
+	if d.MaxAttempts > 0 && delivery.Attempts >= d.MaxAttempts {
+		d.markDead(ctx, delivery, resp, sendErr)
+		return
+	}
+
+	backoff := d.InitialBackoff
+	for i := 1; i < delivery.Attempts; i++ {
+		backoff *= 2
+		if backoff > 6*time.Hour {
+			backoff = 6 * time.Hour
+			break
+		}
+	}
+	next := time.Now().UTC().Add(backoff)
+	errMsg := sendErr.Error()
+	if err := d.DB.WithContext(ctx).Model(&models.WebhookDelivery{}).
+		Where("id = ?", delivery.ID).
+		Updates(responseUpdates(map[string]interface{}{
+			"status":          models.WebhookDeliveryStatusFailed,
+			"last_error":      &errMsg,
+			"next_attempt_at": &next,
+			"locked_at":       nil,
+			"locked_by":       nil,
+		}, resp)).Error; err != nil {
+		d.logError(delivery.ID, delivery.BusinessId, err)
+	}
+	d.logError(delivery.ID, delivery.BusinessId, fmt.Errorf("webhook delivery failed, retrying at %s: %w", next.Format(time.RFC3339), sendErr))
+}
+
+func (d *WebhookDispatcher) logError(deliveryId int, businessId string, err error) {
+	if d.Logger == nil {
+		return
+	}
+	d.Logger.WithFields(logrus.Fields{
+		"field":       "WebhookDispatcher",
+		"business_id": businessId,
+		"delivery_id": deliveryId,
+	}).Error(err.Error())
+}
@@ -0,0 +1,174 @@
+package workflow
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mmdatafocus/books_backend/config"
+	"github.com/mmdatafocus/books_backend/models"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// weightedAverageCogs is the weighted-average counterpart of the FIFO loop in calculateCogs.
+// It fills uniqueStocks/uniqueStockDetails with each outgoing row costed at the moving
+// average of the warehouse's stock at its position in the ledger, so the delta, repost and
+// row replacement steps that follow are shared by both methods. The pool is opened from the
+// ledger before the earliest outgoing row and walked with incoming rows first within a day.
+func weightedAverageCogs(tx *gorm.DB, logger *logrus.Logger, outgoingStockHistories []*models.StockHistory, uniqueStocks map[string]StockHistoryFragment, uniqueStockDetails map[string]StockHistoryDetailFragment) error {
+	first := outgoingStockHistories[0]
+	startDate, endDate := first.StockDate, first.StockDate
+	revalue := make(map[int]bool, len(outgoingStockHistories))
+	for _, outStock := range outgoingStockHistories {
+		if outStock.StockDate.Before(startDate) {
+			startDate = outStock.StockDate
+		}
+		if outStock.StockDate.After(endDate) {
+			endDate = outStock.StockDate
+		}
+		revalue[outStock.ID] = true
+	}
+
+	openingQty, openingValue, err := weightedAveragePool(tx, first.BusinessId, first.WarehouseId, first.ProductId, first.ProductType, startDate)
+	if err != nil {
+		config.LogError(logger, "WeightedAverage.go", "weightedAverageCogs", "QueryOpeningBalance", first, err)
+		return err
+	}
+
+	var rows []*models.StockHistory
+	err = tx.
+		Where("business_id = ? AND warehouse_id = ? AND product_id = ? AND product_type = ? AND stock_date >= ? AND stock_date <= ? AND is_reversal = 0 AND reversed_by_stock_history_id IS NULL",
+			first.BusinessId, first.WarehouseId, first.ProductId, first.ProductType, startDate, endDate).
+		Order("stock_date, is_outgoing, id").
+		Find(&rows).Error
+	if err != nil {
+		config.LogError(logger, "WeightedAverage.go", "weightedAverageCogs", "QueryLedgerRows", first, err)
+		return err
+	}
+
+	costs := models.WeightedAverageCosts(openingQty, openingValue, rows, revalue)
+	for _, outStock := range outgoingStockHistories {
+		unitCost, ok := costs[outStock.ID]
+		if !ok {
+			return fmt.Errorf("weighted average: stock history %d is not in the ledger for product_id=%d warehouse_id=%d", outStock.ID, outStock.ProductId, outStock.WarehouseId)
+		}
+		qty := outStock.Qty.Abs()
+
+		key := fmt.Sprintf("%d-%d-%s-%s-%d-%s-%d", outStock.WarehouseId, outStock.ProductId, outStock.ProductType, outStock.BatchNumber, outStock.ReferenceID, outStock.ReferenceType, outStock.ReferenceDetailID)
+		if existing, found := uniqueStocks[key]; found {
+			existing.TotalQty = existing.TotalQty.Add(qty)
+			existing.TotalValue = existing.TotalValue.Add(qty.Mul(unitCost))
+			uniqueStocks[key] = existing
+		} else {
+			uniqueStocks[key] = StockHistoryFragment{
+				BusinessId:        outStock.BusinessId,
+				WarehouseId:       outStock.WarehouseId,
+				ProductId:         outStock.ProductId,
+				ProductType:       outStock.ProductType,
+				BatchNumber:       outStock.BatchNumber,
+				ReferenceId:       outStock.ReferenceID,
+				ReferenceType:     outStock.ReferenceType,
+				ReferenceDetailId: outStock.ReferenceDetailID,
+				TotalQty:          qty,
+				TotalValue:        qty.Mul(unitCost),
+			}
+		}
+
+		detailKey := fmt.Sprintf("%d-%d-%s-%s-%d-%s-%d-%s", outStock.WarehouseId, outStock.ProductId, outStock.ProductType, outStock.BatchNumber, outStock.ReferenceID, outStock.ReferenceType, outStock.ReferenceDetailID, unitCost)
+		if existing, found := uniqueStockDetails[detailKey]; found {
+			existing.Qty = existing.Qty.Add(qty)
+			uniqueStockDetails[detailKey] = existing
+		} else {
+			uniqueStockDetails[detailKey] = StockHistoryDetailFragment{
+				BusinessId:        outStock.BusinessId,
+				WarehouseId:       outStock.WarehouseId,
+				ProductId:         outStock.ProductId,
+				ProductType:       outStock.ProductType,
+				BatchNumber:       outStock.BatchNumber,
+				StockDate:         outStock.StockDate,
+				Description:       outStock.Description,
+				ReferenceId:       outStock.ReferenceID,
+				ReferenceType:     outStock.ReferenceType,
+				ReferenceDetailId: outStock.ReferenceDetailID,
+				Qty:               qty,
+				BaseUnitValue:     unitCost,
+			}
+		}
+	}
+	return nil
+}
+
+// valuationSegment is a run of outgoing rows valued with the same method.
+type valuationSegment struct {
+	weightedAverage bool
+	rows            []*models.StockHistory
+}
+
+// splitByValuationMethod splits one item's date-ordered outgoing rows at its valuation
+// method switches, so each part is costed with the method that values its dates.
+func splitByValuationMethod(tx *gorm.DB, outgoingStockHistories []*models.StockHistory) ([]valuationSegment, error) {
+	first := outgoingStockHistories[0]
+	business, err := models.GetBusinessById2(tx, first.BusinessId)
+	if err != nil {
+		return nil, err
+	}
+	switches, err := models.InventoryValuationMethodSwitches(tx, first.BusinessId, first.ProductId, first.ProductType, first.StockDate)
+	if err != nil {
+		return nil, err
+	}
+
+	var segments []valuationSegment
+	for _, outStock := range outgoingStockHistories {
+		weightedAverage := models.InventoryValuationMethodOn(business, switches, outStock.StockDate) == models.InventoryValuationMethodWeightedAverage
+		if n := len(segments); n > 0 && segments[n-1].weightedAverage == weightedAverage {
+			segments[n-1].rows = append(segments[n-1].rows, outStock)
+			continue
+		}
+		segments = append(segments, valuationSegment{weightedAverage: weightedAverage, rows: []*models.StockHistory{outStock}})
+	}
+	return segments, nil
+}
+
+// weightedAverageOnHandLayer presents weighted-average stock on hand as the single remaining
+// layer a value adjustment takes out, so the adjustment removes exactly the ledger value.
+// lastStockHistory is the item's last active row on or before the adjustment date.
+func weightedAverageOnHandLayer(tx *gorm.DB, lastStockHistory *models.StockHistory, stockDateExclusiveEnd time.Time) ([]*models.StockHistory, error) {
+	qty, value, err := weightedAveragePool(tx, lastStockHistory.BusinessId, lastStockHistory.WarehouseId, lastStockHistory.ProductId, lastStockHistory.ProductType, stockDateExclusiveEnd)
+	if err != nil {
+		return nil, err
+	}
+	if !qty.IsPositive() {
+		return nil, nil
+	}
+	return []*models.StockHistory{{
+		Qty:                   qty,
+		BaseUnitValue:         models.WeightedAverageUnitCost(qty, value, decimal.Zero),
+		CumulativeIncomingQty: lastStockHistory.CumulativeOutgoingQty.Add(qty),
+	}}, nil
+}
+
+// weightedAveragePool is an item's quantity and value in a warehouse from its ledger rows
+// dated before `before`. Like FIFO, weighted average treats the item's batches as one stock,
+// so the pool that costs outgoing rows and the on-hand layer a value adjustment takes out
+// both span every batch.
+func weightedAveragePool(tx *gorm.DB, businessId string, warehouseId int, productId int, productType models.ProductType, before time.Time) (decimal.Decimal, decimal.Decimal, error) {
+	var pool struct {
+		Qty        decimal.Decimal
+		AssetValue decimal.Decimal
+	}
+	err := tx.Raw(`
+		SELECT
+		  COALESCE(SUM(qty), 0) AS qty,
+		  COALESCE(SUM(qty * base_unit_value), 0) AS asset_value
+		FROM stock_histories
+		WHERE business_id = ?
+		  AND warehouse_id = ?
+		  AND product_id = ?
+		  AND product_type = ?
+		  AND stock_date < ?
+		  AND is_reversal = 0
+		  AND reversed_by_stock_history_id IS NULL
+	`, businessId, warehouseId, productId, productType, before).Scan(&pool).Error
+	return pool.Qty, pool.AssetValue, err
+}